@@ -30,6 +30,7 @@ import (
 	bindcmd "github.com/kcp-dev/kcp/pkg/cliplugins/bind/cmd"
 	claimscmd "github.com/kcp-dev/kcp/pkg/cliplugins/claims/cmd"
 	crdcmd "github.com/kcp-dev/kcp/pkg/cliplugins/crd/cmd"
+	shardcmd "github.com/kcp-dev/kcp/pkg/cliplugins/shard/cmd"
 	workloadcmd "github.com/kcp-dev/kcp/pkg/cliplugins/workload/cmd"
 	workspacecmd "github.com/kcp-dev/kcp/pkg/cliplugins/workspace/cmd"
 	"github.com/kcp-dev/kcp/pkg/cmd/help"
@@ -88,5 +89,8 @@ func KubectlKcpCommand() *cobra.Command {
 	claimsCmd := claimscmd.New(genericclioptions.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr})
 	root.AddCommand(claimsCmd)
 
+	shardCmd := shardcmd.New(genericclioptions.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr})
+	root.AddCommand(shardCmd)
+
 	return root
 }
@@ -18,15 +18,22 @@ package apibinding
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"sort"
 
 	kcpkubernetesclientset "github.com/kcp-dev/client-go/kubernetes"
 	"github.com/kcp-dev/logicalcluster/v2"
 
+	kcpapiextensionsinformers "k8s.io/apiextensions-apiserver/pkg/client/kcp/informers/externalversions"
+	kcpapiextensionsv1informers "k8s.io/apiextensions-apiserver/pkg/client/kcp/informers/externalversions/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apiserver/pkg/admission"
 	"k8s.io/apiserver/pkg/authentication/user"
@@ -38,6 +45,9 @@ import (
 	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
 	"github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1/permissionclaims"
 	"github.com/kcp-dev/kcp/pkg/authorization/delegated"
+	kcpinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions"
+	apisv1alpha1informers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/apis/v1alpha1"
+	apibindingreconciler "github.com/kcp-dev/kcp/pkg/reconciler/apis/apibinding"
 )
 
 const (
@@ -47,10 +57,12 @@ const (
 func Register(plugins *admission.Plugins) {
 	plugins.Register(PluginName,
 		func(_ io.Reader) (admission.Interface, error) {
-			return &apiBindingAdmission{
+			p := &apiBindingAdmission{
 				Handler:          admission.NewHandler(admission.Create, admission.Update),
 				createAuthorizer: delegated.NewDelegatedAuthorizer,
-			}, nil
+			}
+			p.dryRun = p.dryRunWithInformers
+			return p, nil
 		})
 }
 
@@ -58,6 +70,22 @@ type apiBindingAdmission struct {
 	*admission.Handler
 	deepSARClient kcpkubernetesclientset.ClusterInterface
 
+	listAPIExports func(clusterName logicalcluster.Name, selector labels.Selector) ([]*apisv1alpha1.APIExport, error)
+
+	// listCachedAPIExports lists the APIExports replicated to the cache server from all shards. It is nil if the
+	// cache server is disabled.
+	listCachedAPIExports   func(clusterName logicalcluster.Name, selector labels.Selector) ([]*apisv1alpha1.APIExport, error)
+	cachedAPIExportsSynced func() bool
+
+	cachedAPIExportInformer         apisv1alpha1informers.APIExportClusterInformer
+	cachedAPIResourceSchemaInformer apisv1alpha1informers.APIResourceSchemaClusterInformer
+
+	apiBindingInformer        apisv1alpha1informers.APIBindingClusterInformer
+	apiExportInformer         apisv1alpha1informers.APIExportClusterInformer
+	apiResourceSchemaInformer apisv1alpha1informers.APIResourceSchemaClusterInformer
+	crdInformer               kcpapiextensionsv1informers.CustomResourceDefinitionClusterInformer
+	dryRun                    func(ctx context.Context, apiBinding *apisv1alpha1.APIBinding) (*apisv1alpha1.APIBindingDryRunResult, error)
+
 	createAuthorizer delegated.DelegatedAuthorizerFactory
 }
 
@@ -67,6 +95,9 @@ var (
 	_ = admission.MutationInterface(&apiBindingAdmission{})
 	_ = admission.InitializationValidator(&apiBindingAdmission{})
 	_ = kcpinitializers.WantsDeepSARClient(&apiBindingAdmission{})
+	_ = kcpinitializers.WantsKcpInformers(&apiBindingAdmission{})
+	_ = kcpinitializers.WantsKcpCacheInformers(&apiBindingAdmission{})
+	_ = kcpinitializers.WantsApiExtensionsInformers(&apiBindingAdmission{})
 )
 
 func (o *apiBindingAdmission) Admit(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) error {
@@ -84,7 +115,8 @@ func (o *apiBindingAdmission) Admit(ctx context.Context, a admission.Attributes,
 		return fmt.Errorf("failed to convert unstructured to APIBinding: %w", err)
 	}
 
-	if apiBinding.Spec.Reference.Workspace == nil {
+	ref := apiBinding.Spec.Reference
+	if ref.Workspace == nil && ref.Selector == nil {
 		return nil
 	}
 
@@ -93,23 +125,38 @@ func (o *apiBindingAdmission) Admit(ctx context.Context, a admission.Attributes,
 	if err != nil {
 		return admission.NewForbidden(a, fmt.Errorf("error determining workspace: %w", err))
 	}
-	if apiBinding.Spec.Reference.Workspace.Path == "" {
-		apiBinding.Spec.Reference.Workspace.Path = cluster.Name.String()
+	if ref.Workspace != nil && ref.Workspace.Path == "" {
+		ref.Workspace.Path = cluster.Name.String()
+	}
+
+	// resolve the selector to an APIExport, once on creation
+	if ref.Selector != nil {
+		if err := o.selectAPIExport(ctx, a, apiBinding); err != nil {
+			return admission.NewForbidden(a, err)
+		}
 	}
 
 	// set labels
-	if apiBinding.Spec.Reference.Workspace == nil {
+	if exportClusterName, exportName, ok := apisv1alpha1.ReferencedAPIExport(apiBinding); !ok {
 		delete(apiBinding.Labels, apisv1alpha1.InternalAPIBindingExportLabelKey)
 	} else {
 		if apiBinding.Labels == nil {
 			apiBinding.Labels = make(map[string]string)
 		}
 		apiBinding.Labels[apisv1alpha1.InternalAPIBindingExportLabelKey] = permissionclaims.ToAPIBindingExportLabelValue(
-			logicalcluster.New(apiBinding.Spec.Reference.Workspace.Path),
-			apiBinding.Spec.Reference.Workspace.ExportName,
+			exportClusterName,
+			exportName,
 		)
 	}
 
+	// preview the binding on dry-run requests. The result is never persisted.
+	delete(apiBinding.Annotations, apisv1alpha1.AnnotationDryRunResultKey)
+	if a.IsDryRun() {
+		if err := o.recordDryRunResult(ctx, apiBinding); err != nil {
+			return admission.NewForbidden(a, err)
+		}
+	}
+
 	// write back
 	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(apiBinding)
 	if err != nil {
@@ -138,8 +185,9 @@ func (o *apiBindingAdmission) Validate(ctx context.Context, a admission.Attribut
 	}
 
 	// Return early if there's nothing to validate (but this should never happen because it's required via OpenAPI).
-	if apiBinding.Spec.Reference.Workspace == nil {
-		return admission.NewForbidden(a, fmt.Errorf(".spec.reference.workspace is required"))
+	ref := apiBinding.Spec.Reference
+	if ref.Workspace == nil && ref.Selector == nil {
+		return admission.NewForbidden(a, fmt.Errorf("one of .spec.reference.workspace or .spec.reference.selector is required"))
 	}
 
 	// Object validation
@@ -165,23 +213,25 @@ func (o *apiBindingAdmission) Validate(ctx context.Context, a admission.Attribut
 	}
 
 	// Verify the workspace reference.
-	if apiBinding.Spec.Reference.Workspace.Path == "" {
+	if ref.Workspace != nil && ref.Workspace.Path == "" {
 		return admission.NewForbidden(a, fmt.Errorf("workspace reference is missing")) // this should not happen due to validation
 	}
 
+	// Verify the selected APIExport.
+	exportClusterName, exportName, ok := apisv1alpha1.ReferencedAPIExport(apiBinding)
+	if !ok {
+		// this should not happen due to mutation and validation
+		return admission.NewForbidden(a, field.Required(field.NewPath("metadata").Child("annotations").Key(apisv1alpha1.AnnotationSelectedExportKey), "APIExport selector is not resolved"))
+	}
+
 	// Verify the labels
-	value, found := apiBinding.Labels[apisv1alpha1.InternalAPIBindingExportLabelKey]
-	if apiBinding.Spec.Reference.Workspace == nil && found {
-		return admission.NewForbidden(a, field.Invalid(field.NewPath("metadata").Child("labels").Key(apisv1alpha1.InternalAPIBindingExportLabelKey), value, "must not be set"))
-	} else if expected := permissionclaims.ToAPIBindingExportLabelValue(
-		logicalcluster.New(apiBinding.Spec.Reference.Workspace.Path),
-		apiBinding.Spec.Reference.Workspace.ExportName,
-	); value != expected {
+	value := apiBinding.Labels[apisv1alpha1.InternalAPIBindingExportLabelKey]
+	if expected := permissionclaims.ToAPIBindingExportLabelValue(exportClusterName, exportName); value != expected {
 		return admission.NewForbidden(a, field.Invalid(field.NewPath("metadata").Child("labels").Key(apisv1alpha1.InternalAPIBindingExportLabelKey), value, fmt.Sprintf("must be set to %q", expected)))
 	}
 
 	// Access check
-	if err := o.checkAPIExportAccess(ctx, a.GetUserInfo(), logicalcluster.New(apiBinding.Spec.Reference.Workspace.Path), apiBinding.Spec.Reference.Workspace.ExportName); err != nil {
+	if err := o.checkAPIExportAccess(ctx, a.GetUserInfo(), exportClusterName, exportName); err != nil {
 		action := "create"
 		if a.GetOperation() == admission.Update {
 			action = "update"
@@ -221,11 +271,121 @@ func (o *apiBindingAdmission) checkAPIExportAccess(ctx context.Context, user use
 	return nil
 }
 
+// selectAPIExport records the APIExport selected by the selector reference of the APIBinding in the
+// AnnotationSelectedExportKey annotation. Exactly one APIExport in the selector paths must match the label
+// selector and be accessible by the user with the verb `bind`. APIExports are looked up on this shard and, if
+// enabled, on the cache server, such that APIExports on other shards are found too. Once selected, the
+// APIExport of an APIBinding never changes.
+func (o *apiBindingAdmission) selectAPIExport(ctx context.Context, a admission.Attributes, apiBinding *apisv1alpha1.APIBinding) error {
+	if a.GetOperation() == admission.Update {
+		u, ok := a.GetOldObject().(*unstructured.Unstructured)
+		if !ok {
+			return fmt.Errorf("unexpected type %T", a.GetOldObject())
+		}
+		if value, found := u.GetAnnotations()[apisv1alpha1.AnnotationSelectedExportKey]; found {
+			if apiBinding.Annotations == nil {
+				apiBinding.Annotations = map[string]string{}
+			}
+			apiBinding.Annotations[apisv1alpha1.AnnotationSelectedExportKey] = value
+			return nil
+		}
+	}
+
+	if !o.WaitForReady() || (o.cachedAPIExportsSynced != nil && !o.cachedAPIExportsSynced()) {
+		return fmt.Errorf("not yet ready to handle request")
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(&apiBinding.Spec.Reference.Selector.LabelSelector)
+	if err != nil {
+		return field.Invalid(field.NewPath("spec", "reference", "selector", "labelSelector"), apiBinding.Spec.Reference.Selector.LabelSelector, err.Error())
+	}
+
+	var selected []string
+	for _, path := range apiBinding.Spec.Reference.Selector.Paths {
+		clusterName := logicalcluster.New(path)
+		apiExports, err := o.listAPIExports(clusterName, selector)
+		if err != nil {
+			return fmt.Errorf("error listing APIExports in %s: %w", clusterName, err)
+		}
+		if o.listCachedAPIExports != nil {
+			cachedAPIExports, err := o.listCachedAPIExports(clusterName, selector)
+			if err != nil {
+				return fmt.Errorf("error listing cached APIExports in %s: %w", clusterName, err)
+			}
+			apiExports = append(apiExports, cachedAPIExports...)
+		}
+
+		seen := sets.NewString()
+		for _, apiExport := range apiExports {
+			if seen.Has(apiExport.Name) {
+				continue
+			}
+			seen.Insert(apiExport.Name)
+			if err := o.checkAPIExportAccess(ctx, a.GetUserInfo(), clusterName, apiExport.Name); err != nil {
+				continue
+			}
+			selected = append(selected, apisv1alpha1.ToSelectedExport(clusterName, apiExport.Name))
+		}
+	}
+
+	switch len(selected) {
+	case 0:
+		return fmt.Errorf("no APIExport matching %q in %v can be bound", selector.String(), apiBinding.Spec.Reference.Selector.Paths)
+	case 1:
+		if apiBinding.Annotations == nil {
+			apiBinding.Annotations = map[string]string{}
+		}
+		apiBinding.Annotations[apisv1alpha1.AnnotationSelectedExportKey] = selected[0]
+		return nil
+	default:
+		sort.Strings(selected)
+		return fmt.Errorf("multiple APIExports matching %q can be bound: %v", selector.String(), selected)
+	}
+}
+
+// recordDryRunResult records the outcome of binding the APIBinding in the AnnotationDryRunResultKey annotation,
+// such that clients can preview resources, conflicts and permission claims before creating or updating it.
+func (o *apiBindingAdmission) recordDryRunResult(ctx context.Context, apiBinding *apisv1alpha1.APIBinding) error {
+	result, err := o.dryRun(ctx, apiBinding)
+	if err != nil {
+		return fmt.Errorf("dry-run failed: %w", err)
+	}
+
+	bs, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	if apiBinding.Annotations == nil {
+		apiBinding.Annotations = map[string]string{}
+	}
+	apiBinding.Annotations[apisv1alpha1.AnnotationDryRunResultKey] = string(bs)
+
+	return nil
+}
+
+func (o *apiBindingAdmission) dryRunWithInformers(ctx context.Context, apiBinding *apisv1alpha1.APIBinding) (*apisv1alpha1.APIBindingDryRunResult, error) {
+	if !o.WaitForReady() || !o.apiBindingInformer.Informer().HasSynced() || !o.apiResourceSchemaInformer.Informer().HasSynced() || !o.crdInformer.Informer().HasSynced() {
+		return nil, fmt.Errorf("not yet ready to handle request")
+	}
+	if o.cachedAPIExportInformer != nil && (!o.cachedAPIExportInformer.Informer().HasSynced() || !o.cachedAPIResourceSchemaInformer.Informer().HasSynced()) {
+		return nil, fmt.Errorf("not yet ready to handle request")
+	}
+
+	dryRunner := apibindingreconciler.NewDryRunner(o.apiBindingInformer, o.apiExportInformer, o.apiResourceSchemaInformer, o.cachedAPIExportInformer, o.cachedAPIResourceSchemaInformer, o.crdInformer)
+	return dryRunner.DryRun(ctx, apiBinding)
+}
+
 // ValidateInitialization ensures the required injected fields are set.
 func (o *apiBindingAdmission) ValidateInitialization() error {
 	if o.deepSARClient == nil {
 		return fmt.Errorf(PluginName + " plugin needs a Kubernetes ClusterInterface")
 	}
+	if o.listAPIExports == nil {
+		return fmt.Errorf(PluginName + " plugin needs an APIExport lister")
+	}
+	if o.crdInformer == nil {
+		return fmt.Errorf(PluginName + " plugin needs a CustomResourceDefinition informer")
+	}
 
 	return nil
 }
@@ -235,3 +395,41 @@ func (o *apiBindingAdmission) ValidateInitialization() error {
 func (o *apiBindingAdmission) SetDeepSARClient(client kcpkubernetesclientset.ClusterInterface) {
 	o.deepSARClient = client
 }
+
+// SetKcpInformers implements the WantsKcpInformers interface.
+func (o *apiBindingAdmission) SetKcpInformers(informers kcpinformers.SharedInformerFactory) {
+	apiExportsReady := informers.Apis().V1alpha1().APIExports().Informer().HasSynced
+	o.SetReadyFunc(apiExportsReady)
+	apiExportLister := informers.Apis().V1alpha1().APIExports().Lister()
+	o.listAPIExports = func(clusterName logicalcluster.Name, selector labels.Selector) ([]*apisv1alpha1.APIExport, error) {
+		return apiExportLister.Cluster(clusterName).List(selector)
+	}
+
+	o.apiBindingInformer = informers.Apis().V1alpha1().APIBindings()
+	o.apiExportInformer = informers.Apis().V1alpha1().APIExports()
+	o.apiResourceSchemaInformer = informers.Apis().V1alpha1().APIResourceSchemas()
+	// make sure the informers are started
+	_ = o.apiBindingInformer.Informer()
+	_ = o.apiResourceSchemaInformer.Informer()
+}
+
+// SetKcpCacheInformers implements the WantsKcpCacheInformers interface.
+func (o *apiBindingAdmission) SetKcpCacheInformers(informers kcpinformers.SharedInformerFactory) {
+	cachedAPIExports := informers.Apis().V1alpha1().APIExports()
+	o.cachedAPIExportsSynced = cachedAPIExports.Informer().HasSynced
+	o.listCachedAPIExports = func(clusterName logicalcluster.Name, selector labels.Selector) ([]*apisv1alpha1.APIExport, error) {
+		return cachedAPIExports.Lister().Cluster(clusterName).List(selector)
+	}
+
+	o.cachedAPIExportInformer = cachedAPIExports
+	o.cachedAPIResourceSchemaInformer = informers.Apis().V1alpha1().APIResourceSchemas()
+	// make sure the informer is started
+	_ = o.cachedAPIResourceSchemaInformer.Informer()
+}
+
+// SetApiExtensionsInformers implements the WantsApiExtensionsInformers interface.
+func (o *apiBindingAdmission) SetApiExtensionsInformers(informers kcpapiextensionsinformers.SharedInformerFactory) {
+	o.crdInformer = informers.Apiextensions().V1().CustomResourceDefinitions()
+	// make sure the informer is started
+	_ = o.crdInformer.Informer()
+}
@@ -28,7 +28,9 @@ import (
 	"github.com/stretchr/testify/require"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apiserver/pkg/admission"
 	"k8s.io/apiserver/pkg/authentication/user"
 	"k8s.io/apiserver/pkg/authorization/authorizer"
@@ -54,6 +56,22 @@ func createAttr(apiBinding *apisv1alpha1.APIBinding) admission.Attributes {
 	)
 }
 
+func dryRunCreateAttr(apiBinding *apisv1alpha1.APIBinding) admission.Attributes {
+	return admission.NewAttributesRecord(
+		helpers.ToUnstructuredOrDie(apiBinding),
+		nil,
+		apisv1alpha1.Kind("APIBinding").WithVersion("v1alpha1"),
+		"",
+		apiBinding.Name,
+		apisv1alpha1.Resource("apibindings").WithVersion("v1alpha1"),
+		"",
+		admission.Create,
+		&metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}},
+		true,
+		&user.DefaultInfo{},
+	)
+}
+
 func updateAttr(newAPIBinding, oldAPIBinding *apisv1alpha1.APIBinding) admission.Attributes {
 	return admission.NewAttributesRecord(
 		helpers.ToUnstructuredOrDie(newAPIBinding),
@@ -72,12 +90,17 @@ func updateAttr(newAPIBinding, oldAPIBinding *apisv1alpha1.APIBinding) admission
 
 func TestAdmit(t *testing.T) {
 	tests := []struct {
-		name           string
-		attr           admission.Attributes
-		authzDecision  authorizer.Decision
-		authzError     error
-		expectedErrors []string
-		expectedObject runtime.Object
+		name             string
+		attr             admission.Attributes
+		apiExports       map[logicalcluster.Name][]*apisv1alpha1.APIExport
+		cachedAPIExports map[logicalcluster.Name][]*apisv1alpha1.APIExport
+		authzDecision    authorizer.Decision
+		authzDenied      sets.String
+		authzError       error
+		dryRunResult     *apisv1alpha1.APIBindingDryRunResult
+		dryRunError      error
+		expectedErrors   []string
+		expectedObject   runtime.Object
 	}{
 		{
 			name: "Create: passes with no reference",
@@ -134,19 +157,160 @@ func TestAdmit(t *testing.T) {
 			expectedObject: helpers.ToUnstructuredOrDie(newAPIBinding().withAbsoluteWorkspaceReference("root:org:ws", "someExport").
 				withLabel(apisv1alpha1.InternalAPIBindingExportLabelKey, toSha224Base62("root:org:ws:someExport")).APIBinding),
 		},
+		{
+			name: "Create: with selector reference selects the only bindable export",
+			attr: createAttr(
+				newAPIBinding().withName("test").withSelectorReference(map[string]string{"app": "foo"}, "root:aunt", "root:uncle").APIBinding,
+			),
+			apiExports: map[logicalcluster.Name][]*apisv1alpha1.APIExport{
+				logicalcluster.New("root:aunt"):  {newAPIExport("denied", map[string]string{"app": "foo"})},
+				logicalcluster.New("root:uncle"): {newAPIExport("someExport", map[string]string{"app": "foo"}), newAPIExport("other", map[string]string{"app": "bar"})},
+			},
+			authzDecision: authorizer.DecisionAllow,
+			authzDenied:   sets.NewString("denied"),
+			expectedObject: helpers.ToUnstructuredOrDie(newAPIBinding().withName("test").withSelectorReference(map[string]string{"app": "foo"}, "root:aunt", "root:uncle").
+				withAnnotation(apisv1alpha1.AnnotationSelectedExportKey, "root:uncle:someExport").
+				withLabel(apisv1alpha1.InternalAPIBindingExportLabelKey, toSha224Base62("root:uncle:someExport")).APIBinding),
+		},
+		{
+			name: "Create: with selector reference fails without matching export",
+			attr: createAttr(
+				newAPIBinding().withName("test").withSelectorReference(map[string]string{"app": "foo"}, "root:aunt").APIBinding,
+			),
+			apiExports: map[logicalcluster.Name][]*apisv1alpha1.APIExport{
+				logicalcluster.New("root:aunt"): {newAPIExport("other", map[string]string{"app": "bar"})},
+			},
+			authzDecision:  authorizer.DecisionAllow,
+			expectedErrors: []string{`no APIExport matching "app=foo" in [root:aunt] can be bound`},
+		},
+		{
+			name: "Create: with selector reference fails with multiple bindable exports",
+			attr: createAttr(
+				newAPIBinding().withName("test").withSelectorReference(map[string]string{"app": "foo"}, "root:aunt", "root:uncle").APIBinding,
+			),
+			apiExports: map[logicalcluster.Name][]*apisv1alpha1.APIExport{
+				logicalcluster.New("root:aunt"):  {newAPIExport("someExport", map[string]string{"app": "foo"})},
+				logicalcluster.New("root:uncle"): {newAPIExport("someExport", map[string]string{"app": "foo"})},
+			},
+			authzDecision:  authorizer.DecisionAllow,
+			expectedErrors: []string{`multiple APIExports matching "app=foo" can be bound: [root:aunt:someExport root:uncle:someExport]`},
+		},
+		{
+			name: "Create: with selector reference selects exports on other shards from the cache server",
+			attr: createAttr(
+				newAPIBinding().withName("test").withSelectorReference(map[string]string{"app": "foo"}, "root:aunt", "root:uncle").APIBinding,
+			),
+			apiExports: map[logicalcluster.Name][]*apisv1alpha1.APIExport{
+				logicalcluster.New("root:aunt"): {newAPIExport("other", map[string]string{"app": "bar"})},
+			},
+			cachedAPIExports: map[logicalcluster.Name][]*apisv1alpha1.APIExport{
+				logicalcluster.New("root:aunt"):  {newAPIExport("other", map[string]string{"app": "bar"})},
+				logicalcluster.New("root:uncle"): {newAPIExport("someExport", map[string]string{"app": "foo"})},
+			},
+			authzDecision: authorizer.DecisionAllow,
+			expectedObject: helpers.ToUnstructuredOrDie(newAPIBinding().withName("test").withSelectorReference(map[string]string{"app": "foo"}, "root:aunt", "root:uncle").
+				withAnnotation(apisv1alpha1.AnnotationSelectedExportKey, "root:uncle:someExport").
+				withLabel(apisv1alpha1.InternalAPIBindingExportLabelKey, toSha224Base62("root:uncle:someExport")).APIBinding),
+		},
+		{
+			name: "Create: with selector reference counts exports both local and cached once",
+			attr: createAttr(
+				newAPIBinding().withName("test").withSelectorReference(map[string]string{"app": "foo"}, "root:aunt").APIBinding,
+			),
+			apiExports: map[logicalcluster.Name][]*apisv1alpha1.APIExport{
+				logicalcluster.New("root:aunt"): {newAPIExport("someExport", map[string]string{"app": "foo"})},
+			},
+			cachedAPIExports: map[logicalcluster.Name][]*apisv1alpha1.APIExport{
+				logicalcluster.New("root:aunt"): {newAPIExport("someExport", map[string]string{"app": "foo"})},
+			},
+			authzDecision: authorizer.DecisionAllow,
+			expectedObject: helpers.ToUnstructuredOrDie(newAPIBinding().withName("test").withSelectorReference(map[string]string{"app": "foo"}, "root:aunt").
+				withAnnotation(apisv1alpha1.AnnotationSelectedExportKey, "root:aunt:someExport").
+				withLabel(apisv1alpha1.InternalAPIBindingExportLabelKey, toSha224Base62("root:aunt:someExport")).APIBinding),
+		},
+		{
+			name: "Update: with selector reference keeps the selected export",
+			attr: updateAttr(
+				newAPIBinding().withSelectorReference(map[string]string{"app": "foo"}, "root:aunt").
+					withAnnotation(apisv1alpha1.AnnotationSelectedExportKey, "root:aunt:other").APIBinding,
+				newAPIBinding().withSelectorReference(map[string]string{"app": "foo"}, "root:aunt").
+					withAnnotation(apisv1alpha1.AnnotationSelectedExportKey, "root:aunt:someExport").APIBinding,
+			),
+			authzDecision: authorizer.DecisionAllow,
+			expectedObject: helpers.ToUnstructuredOrDie(newAPIBinding().withSelectorReference(map[string]string{"app": "foo"}, "root:aunt").
+				withAnnotation(apisv1alpha1.AnnotationSelectedExportKey, "root:aunt:someExport").
+				withLabel(apisv1alpha1.InternalAPIBindingExportLabelKey, toSha224Base62("root:aunt:someExport")).APIBinding),
+		},
+		{
+			name: "Create: dry-run records the result",
+			attr: dryRunCreateAttr(
+				newAPIBinding().withName("test").withAbsoluteWorkspaceReference("root:aunt", "someExport").APIBinding,
+			),
+			authzDecision: authorizer.DecisionAllow,
+			dryRunResult: &apisv1alpha1.APIBindingDryRunResult{
+				BoundResources: []apisv1alpha1.BoundAPIResource{{Group: "kcp.dev", Resource: "widgets", Schema: apisv1alpha1.BoundAPIResourceSchema{Name: "today.widgets.kcp.dev", UID: "uid", IdentityHash: "hash"}}},
+			},
+			expectedObject: helpers.ToUnstructuredOrDie(newAPIBinding().withName("test").withAbsoluteWorkspaceReference("root:aunt", "someExport").
+				withLabel(apisv1alpha1.InternalAPIBindingExportLabelKey, toSha224Base62("root:aunt:someExport")).
+				withAnnotation(apisv1alpha1.AnnotationDryRunResultKey, `{"boundResources":[{"group":"kcp.dev","resource":"widgets","schema":{"name":"today.widgets.kcp.dev","UID":"uid","identityHash":"hash"}}]}`).APIBinding),
+		},
+		{
+			name: "Create: dry-run fails if the APIExport cannot be bound",
+			attr: dryRunCreateAttr(
+				newAPIBinding().withName("test").withAbsoluteWorkspaceReference("root:aunt", "someExport").APIBinding,
+			),
+			authzDecision:  authorizer.DecisionAllow,
+			dryRunError:    errors.New("APIExport root:aunt|someExport is missing status.identityHash"),
+			expectedErrors: []string{"dry-run failed: APIExport root:aunt|someExport is missing status.identityHash"},
+		},
+		{
+			name: "Create: dry-run result is dropped on regular requests",
+			attr: createAttr(
+				newAPIBinding().withName("test").withAbsoluteWorkspaceReference("root:aunt", "someExport").
+					withAnnotation(apisv1alpha1.AnnotationDryRunResultKey, "{}").APIBinding,
+			),
+			authzDecision: authorizer.DecisionAllow,
+			expectedObject: helpers.ToUnstructuredOrDie(newAPIBinding().withName("test").withAbsoluteWorkspaceReference("root:aunt", "someExport").
+				withLabel(apisv1alpha1.InternalAPIBindingExportLabelKey, toSha224Base62("root:aunt:someExport")).APIBinding),
+		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			o := &apiBindingAdmission{
 				Handler: admission.NewHandler(admission.Create, admission.Update),
+				dryRun: func(ctx context.Context, apiBinding *apisv1alpha1.APIBinding) (*apisv1alpha1.APIBindingDryRunResult, error) {
+					return tc.dryRunResult, tc.dryRunError
+				},
+				listAPIExports: func(clusterName logicalcluster.Name, selector labels.Selector) ([]*apisv1alpha1.APIExport, error) {
+					var ret []*apisv1alpha1.APIExport
+					for _, apiExport := range tc.apiExports[clusterName] {
+						if selector.Matches(labels.Set(apiExport.Labels)) {
+							ret = append(ret, apiExport)
+						}
+					}
+					return ret, nil
+				},
 				createAuthorizer: func(clusterName logicalcluster.Name, client kcpkubernetesclientset.ClusterInterface) (authorizer.Authorizer, error) {
 					return &fakeAuthorizer{
 						tc.authzDecision,
 						tc.authzError,
+						tc.authzDenied,
 					}, nil
 				},
 			}
+			if tc.cachedAPIExports != nil {
+				o.cachedAPIExportsSynced = func() bool { return true }
+				o.listCachedAPIExports = func(clusterName logicalcluster.Name, selector labels.Selector) ([]*apisv1alpha1.APIExport, error) {
+					var ret []*apisv1alpha1.APIExport
+					for _, apiExport := range tc.cachedAPIExports[clusterName] {
+						if selector.Matches(labels.Set(apiExport.Labels)) {
+							ret = append(ret, apiExport)
+						}
+					}
+					return ret, nil
+				}
+			}
 
 			ctx := request.WithCluster(context.Background(), request.Cluster{Name: logicalcluster.From(tc.attr.GetObject().(metav1.Object))})
 
@@ -180,7 +344,7 @@ func TestValidate(t *testing.T) {
 			attr: createAttr(
 				newAPIBinding().withName("test").APIBinding,
 			),
-			expectedErrors: []string{".spec.reference.workspace is required"},
+			expectedErrors: []string{"one of .spec.reference.workspace or .spec.reference.selector is required"},
 		},
 		{
 			name: "Create: missing workspace reference fails",
@@ -359,6 +523,59 @@ func TestValidate(t *testing.T) {
 			authzError:     errors.New("some error here"),
 			expectedErrors: []string{"unable to determine access to apiexports: some error here"},
 		},
+		{
+			name: "Create: selector reference with selected export passes when authorized",
+			attr: createAttr(
+				newAPIBinding().withName("test").withSelectorReference(map[string]string{"app": "foo"}, "root:aunt").
+					withAnnotation(apisv1alpha1.AnnotationSelectedExportKey, "root:aunt:someExport").
+					withLabel(apisv1alpha1.InternalAPIBindingExportLabelKey, toSha224Base62("root:aunt:someExport")).APIBinding,
+			),
+			authzDecision: authorizer.DecisionAllow,
+		},
+		{
+			name: "Create: selector reference without selected export fails",
+			attr: createAttr(
+				newAPIBinding().withName("test").withSelectorReference(map[string]string{"app": "foo"}, "root:aunt").APIBinding,
+			),
+			authzDecision:  authorizer.DecisionAllow,
+			expectedErrors: []string{"metadata.annotations[apis.kcp.dev/selected-export]: Invalid value"},
+		},
+		{
+			name: "Create: selector reference with selected export outside of paths fails",
+			attr: createAttr(
+				newAPIBinding().withName("test").withSelectorReference(map[string]string{"app": "foo"}, "root:aunt").
+					withAnnotation(apisv1alpha1.AnnotationSelectedExportKey, "root:uncle:someExport").
+					withLabel(apisv1alpha1.InternalAPIBindingExportLabelKey, toSha224Base62("root:uncle:someExport")).APIBinding,
+			),
+			authzDecision:  authorizer.DecisionAllow,
+			expectedErrors: []string{"must refer to an APIExport in one of the selector paths"},
+		},
+		{
+			name: "Update: changing the selected export fails",
+			attr: updateAttr(
+				newAPIBinding().withName("test").withSelectorReference(map[string]string{"app": "foo"}, "root:aunt").
+					withAnnotation(apisv1alpha1.AnnotationSelectedExportKey, "root:aunt:other").
+					withLabel(apisv1alpha1.InternalAPIBindingExportLabelKey, toSha224Base62("root:aunt:other")).APIBinding,
+				newAPIBinding().withName("test").withSelectorReference(map[string]string{"app": "foo"}, "root:aunt").
+					withAnnotation(apisv1alpha1.AnnotationSelectedExportKey, "root:aunt:someExport").
+					withLabel(apisv1alpha1.InternalAPIBindingExportLabelKey, toSha224Base62("root:aunt:someExport")).APIBinding,
+			),
+			authzDecision:  authorizer.DecisionAllow,
+			expectedErrors: []string{"metadata.annotations[apis.kcp.dev/selected-export]: Invalid value: \"root:aunt:other\": field is immutable"},
+		},
+		{
+			name: "Update: changing the selector reference fails",
+			attr: updateAttr(
+				newAPIBinding().withName("test").withSelectorReference(map[string]string{"app": "bar"}, "root:aunt").
+					withAnnotation(apisv1alpha1.AnnotationSelectedExportKey, "root:aunt:someExport").
+					withLabel(apisv1alpha1.InternalAPIBindingExportLabelKey, toSha224Base62("root:aunt:someExport")).APIBinding,
+				newAPIBinding().withName("test").withSelectorReference(map[string]string{"app": "foo"}, "root:aunt").
+					withAnnotation(apisv1alpha1.AnnotationSelectedExportKey, "root:aunt:someExport").
+					withLabel(apisv1alpha1.InternalAPIBindingExportLabelKey, toSha224Base62("root:aunt:someExport")).APIBinding,
+			),
+			authzDecision:  authorizer.DecisionAllow,
+			expectedErrors: []string{"spec.reference.selector: Invalid value", "re-create the APIBinding to select another APIExport"},
+		},
 		{
 			name: "Update: transition from '' to binding passes",
 			attr: updateAttr(
@@ -441,6 +658,7 @@ func TestValidate(t *testing.T) {
 					return &fakeAuthorizer{
 						tc.authzDecision,
 						tc.authzError,
+						nil,
 					}, nil
 				},
 			}
@@ -465,9 +683,13 @@ func TestValidate(t *testing.T) {
 type fakeAuthorizer struct {
 	authorized authorizer.Decision
 	err        error
+	denied     sets.String
 }
 
 func (a *fakeAuthorizer) Authorize(ctx context.Context, attr authorizer.Attributes) (authorized authorizer.Decision, reason string, err error) {
+	if a.denied.Has(attr.GetName()) {
+		return authorizer.DecisionDeny, "reason", nil
+	}
 	return a.authorized, "reason", a.err
 }
 
@@ -500,6 +722,19 @@ func (b *bindingBuilder) withAbsoluteWorkspaceReference(path string, exportName
 	return b
 }
 
+func (b *bindingBuilder) withSelectorReference(matchLabels map[string]string, paths ...string) *bindingBuilder {
+	b.Spec.Reference.Selector = &apisv1alpha1.SelectorExportReference{
+		Paths:         paths,
+		LabelSelector: metav1.LabelSelector{MatchLabels: matchLabels},
+	}
+	return b
+}
+
+func (b *bindingBuilder) withAnnotation(k, v string) *bindingBuilder {
+	b.Annotations[k] = v
+	return b
+}
+
 func (b *bindingBuilder) withLabel(k, v string) *bindingBuilder {
 	if b.Labels == nil {
 		b.Labels = make(map[string]string)
@@ -513,6 +748,15 @@ func (b *bindingBuilder) withPhase(phase apisv1alpha1.APIBindingPhaseType) *bind
 	return b
 }
 
+func newAPIExport(name string, labels map[string]string) *apisv1alpha1.APIExport {
+	return &apisv1alpha1.APIExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+	}
+}
+
 func toSha224Base62(s string) string {
 	return toBase62(sha256.Sum224([]byte(s)))
 }
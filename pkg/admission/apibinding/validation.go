@@ -19,6 +19,10 @@ package apibinding
 import (
 	"fmt"
 
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
@@ -30,6 +34,10 @@ func ValidateAPIBinding(apiBinding *apisv1alpha1.APIBinding) field.ErrorList {
 
 	allErrs = append(allErrs, ValidateAPIBindingReference(apiBinding.Spec.Reference, field.NewPath("spec", "reference"))...)
 
+	if selector := apiBinding.Spec.Reference.Selector; selector != nil {
+		allErrs = append(allErrs, validateSelectedExport(apiBinding.Annotations[apisv1alpha1.AnnotationSelectedExportKey], selector, field.NewPath("metadata", "annotations").Key(apisv1alpha1.AnnotationSelectedExportKey))...)
+	}
+
 	return allErrs
 }
 
@@ -48,6 +56,18 @@ func ValidateAPIBindingUpdate(oldBinding, newBinding *apisv1alpha1.APIBinding) f
 		)
 	}
 
+	// selector references are immutable. Workspace references keep being mutable for compatibility.
+	oldRef, newRef := oldBinding.Spec.Reference, newBinding.Spec.Reference
+	if (oldRef.Selector != nil || newRef.Selector != nil) && !equality.Semantic.DeepEqual(oldRef.Selector, newRef.Selector) {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "reference", "selector"), newRef.Selector, "field is immutable, re-create the APIBinding to select another APIExport"))
+	}
+
+	if oldValue, found := oldBinding.Annotations[apisv1alpha1.AnnotationSelectedExportKey]; found && newBinding.Spec.Reference.Selector != nil {
+		if newValue := newBinding.Annotations[apisv1alpha1.AnnotationSelectedExportKey]; newValue != oldValue {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("metadata", "annotations").Key(apisv1alpha1.AnnotationSelectedExportKey), newValue, "field is immutable"))
+		}
+	}
+
 	return allErrs
 }
 
@@ -55,7 +75,17 @@ func ValidateAPIBindingUpdate(oldBinding, newBinding *apisv1alpha1.APIBinding) f
 func ValidateAPIBindingReference(reference apisv1alpha1.ExportReference, path *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
-	// For now, workspace is required via OpenAPI. But just in case...
+	set := 0
+	for _, isSet := range []bool{reference.Workspace != nil, reference.Selector != nil} {
+		if isSet {
+			set++
+		}
+	}
+	if set > 1 {
+		allErrs = append(allErrs, field.Forbidden(path, "only one of workspace or selector may be set"))
+	}
+
+	// For now, one reference is required via OpenAPI. But just in case...
 	if workspace := reference.Workspace; workspace != nil {
 		// These are required by OpenAPI, but just in case...
 		if workspace.Path == "" {
@@ -67,5 +97,33 @@ func ValidateAPIBindingReference(reference apisv1alpha1.ExportReference, path *f
 		}
 	}
 
+	if selector := reference.Selector; selector != nil {
+		if len(selector.Paths) == 0 {
+			allErrs = append(allErrs, field.Required(path.Child("selector").Child("paths"), ""))
+		}
+		for i, p := range selector.Paths {
+			if !logicalcluster.New(p).IsValid() {
+				allErrs = append(allErrs, field.Invalid(path.Child("selector").Child("paths").Index(i), p, "must be an absolute workspace path"))
+			}
+		}
+
+		allErrs = append(allErrs, metav1validation.ValidateLabelSelector(&selector.LabelSelector, path.Child("selector").Child("labelSelector"))...)
+	}
+
 	return allErrs
 }
+
+// validateSelectedExport validates that the selected APIExport annotation value refers to an APIExport in one of
+// the paths of the selector.
+func validateSelectedExport(value string, selector *apisv1alpha1.SelectorExportReference, path *field.Path) field.ErrorList {
+	clusterName, _, ok := apisv1alpha1.ParseSelectedExport(value)
+	if !ok {
+		return field.ErrorList{field.Invalid(path, value, "must be set to the selected APIExport in the format <cluster name>:<export name>")}
+	}
+	for _, p := range selector.Paths {
+		if p == clusterName.String() {
+			return nil
+		}
+	}
+	return field.ErrorList{field.Invalid(path, value, "must refer to an APIExport in one of the selector paths")}
+}
@@ -20,9 +20,12 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strconv"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apiserver/pkg/admission"
 
@@ -58,8 +61,55 @@ func NewAPIExportAdmission(isBuiltIn func(apisv1alpha1.GroupResource) bool) *API
 }
 
 // Ensure that the required admission interfaces are implemented.
+var _ = admission.MutationInterface(&APIExportAdmission{})
 var _ = admission.ValidationInterface(&APIExportAdmission{})
 
+// Admit maintains the schema generation of the APIExport. It starts at 1 and is incremented whenever
+// spec.latestResourceSchemas changes. Values set by the user are overwritten.
+func (e *APIExportAdmission) Admit(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) error {
+	if a.GetResource().GroupResource() != apisv1alpha1.Resource("apiexports") {
+		return nil
+	}
+	if a.GetKind().GroupKind() != apisv1alpha1.Kind("APIExport") || a.GetSubresource() != "" {
+		return nil
+	}
+
+	u, ok := a.GetObject().(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("unexpected type %T", a.GetObject())
+	}
+	ae := &apisv1alpha1.APIExport{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, ae); err != nil {
+		return fmt.Errorf("failed to convert unstructured to APIExport: %w", err)
+	}
+
+	schemaGeneration := int64(1)
+	if a.GetOperation() == admission.Update {
+		oldU, ok := a.GetOldObject().(*unstructured.Unstructured)
+		if !ok {
+			return fmt.Errorf("unexpected type %T", a.GetOldObject())
+		}
+		old := &apisv1alpha1.APIExport{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(oldU.Object, old); err != nil {
+			return fmt.Errorf("failed to convert unstructured to APIExport: %w", err)
+		}
+
+		schemaGeneration = apisv1alpha1.SchemaGeneration(old)
+		if !sets.NewString(old.Spec.LatestResourceSchemas...).Equal(sets.NewString(ae.Spec.LatestResourceSchemas...)) {
+			schemaGeneration++
+		}
+	}
+
+	annotations := u.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[apisv1alpha1.AnnotationSchemaGenerationKey] = strconv.FormatInt(schemaGeneration, 10)
+	u.SetAnnotations(annotations)
+
+	return nil
+}
+
 // Validate ensures that the APIExport is valid.
 func (e *APIExportAdmission) Validate(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) (err error) {
 	if a.GetResource().GroupResource() != apisv1alpha1.Resource("apiexports") {
@@ -90,7 +140,37 @@ func (e *APIExportAdmission) Validate(ctx context.Context, a admission.Attribute
 					"",
 					"identityHash is required for API types that are not built-in"))
 		}
+
+		if errs := validatePermissionClaimScope(pc, field.NewPath("spec").Child("permissionClaims").Index(i)); len(errs) > 0 {
+			return admission.NewForbidden(a, errs.ToAggregate())
+		}
 	}
 
 	return nil
 }
+
+// claimableVerbs are the verbs a permission claim can grant on the claimed objects.
+var claimableVerbs = sets.NewString("get", "list", "watch", "create", "update", "patch", "delete", "deletecollection")
+
+// validatePermissionClaimScope validates the verbs and the label selector of a permission claim.
+func validatePermissionClaimScope(pc apisv1alpha1.PermissionClaim, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	for i, verb := range pc.Verbs {
+		if verb == apisv1alpha1.PermissionClaimAllVerbs {
+			if len(pc.Verbs) > 1 {
+				errs = append(errs, field.Invalid(fldPath.Child("verbs").Index(i), verb, "\"*\" must be the only verb"))
+			}
+			continue
+		}
+		if !claimableVerbs.Has(verb) {
+			errs = append(errs, field.NotSupported(fldPath.Child("verbs").Index(i), verb, append([]string{apisv1alpha1.PermissionClaimAllVerbs}, claimableVerbs.List()...)))
+		}
+	}
+
+	if pc.LabelSelector != nil {
+		errs = append(errs, metav1validation.ValidateLabelSelector(pc.LabelSelector, fldPath.Child("labelSelector"))...)
+	}
+
+	return errs
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apiserver/pkg/admission"
@@ -179,6 +180,67 @@ func TestAdmission(t *testing.T) {
 			hasIdentity: true,
 			isBuiltIn:   false,
 		},
+		"ValidVerbs": {
+			kind:        "APIExport",
+			resource:    "apiexports",
+			hasIdentity: true,
+			modifyPCs: func(pcs []apisv1alpha1.PermissionClaim) []apisv1alpha1.PermissionClaim {
+				pcs[0].Verbs = []string{"get", "list", "watch"}
+				pcs[0].LabelSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "foo"}}
+				return pcs
+			},
+		},
+		"ForbiddenUnknownVerb": {
+			kind:        "APIExport",
+			resource:    "apiexports",
+			hasIdentity: true,
+			modifyPCs: func(pcs []apisv1alpha1.PermissionClaim) []apisv1alpha1.PermissionClaim {
+				pcs[0].Verbs = []string{"get", "escalate"}
+				return pcs
+			},
+			want: field.NotSupported(
+				field.NewPath("spec").
+					Child("permissionClaims").
+					Index(0).
+					Child("verbs").
+					Index(1),
+				"escalate",
+				[]string{"*", "create", "delete", "deletecollection", "get", "list", "patch", "update", "watch"}),
+		},
+		"ForbiddenWildcardVerbWithOthers": {
+			kind:        "APIExport",
+			resource:    "apiexports",
+			hasIdentity: true,
+			modifyPCs: func(pcs []apisv1alpha1.PermissionClaim) []apisv1alpha1.PermissionClaim {
+				pcs[0].Verbs = []string{"get", "*"}
+				return pcs
+			},
+			want: field.Invalid(
+				field.NewPath("spec").
+					Child("permissionClaims").
+					Index(0).
+					Child("verbs").
+					Index(1),
+				"*",
+				"\"*\" must be the only verb"),
+		},
+		"ForbiddenInvalidLabelSelector": {
+			kind:        "APIExport",
+			resource:    "apiexports",
+			hasIdentity: true,
+			modifyPCs: func(pcs []apisv1alpha1.PermissionClaim) []apisv1alpha1.PermissionClaim {
+				pcs[0].LabelSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "not valid"}}
+				return pcs
+			},
+			want: field.Invalid(
+				field.NewPath("spec").
+					Child("permissionClaims").
+					Index(0).
+					Child("labelSelector").
+					Child("matchLabels"),
+				"not valid",
+				"a valid label must be an empty string or consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyValue',  or 'my_value',  or '12345', regex used for validation is '(([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9])?')"),
+		},
 		"ValidNoPermissionClaims": {
 			kind:     "APIExport",
 			resource: "apiexports",
@@ -229,3 +291,84 @@ func TestAdmission(t *testing.T) {
 		})
 	}
 }
+
+func TestAdmitSchemaGeneration(t *testing.T) {
+	export := func(generation int64, schemaGeneration string, schemas ...string) *apisv1alpha1.APIExport {
+		e := &apisv1alpha1.APIExport{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Generation: generation},
+			Spec:       apisv1alpha1.APIExportSpec{LatestResourceSchemas: schemas},
+		}
+		if schemaGeneration != "" {
+			e.Annotations = map[string]string{apisv1alpha1.AnnotationSchemaGenerationKey: schemaGeneration}
+		}
+		return e
+	}
+
+	tests := map[string]struct {
+		old, new *apisv1alpha1.APIExport
+		want     string
+	}{
+		"create": {
+			new:  export(0, "", "today.widgets"),
+			want: "1",
+		},
+		"create overrides the user": {
+			new:  export(0, "42", "today.widgets"),
+			want: "1",
+		},
+		"schemas unchanged": {
+			old:  export(3, "2", "today.widgets"),
+			new:  export(3, "2", "today.widgets"),
+			want: "2",
+		},
+		"schemas unchanged with user change": {
+			old:  export(3, "2", "today.widgets"),
+			new:  export(3, "7", "today.widgets"),
+			want: "2",
+		},
+		"schemas changed": {
+			old:  export(3, "2", "today.widgets"),
+			new:  export(3, "2", "tomorrow.widgets"),
+			want: "3",
+		},
+		"schemas reordered": {
+			old:  export(3, "2", "a.widgets", "b.widgets"),
+			new:  export(3, "2", "b.widgets", "a.widgets"),
+			want: "2",
+		},
+		"missing annotation falls back to the generation": {
+			old:  export(5, "", "today.widgets"),
+			new:  export(5, "", "tomorrow.widgets"),
+			want: "6",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var a admission.Attributes
+			if tc.old == nil {
+				a = createAttr("test", tc.new, "APIExport", "apiexports")
+			} else {
+				a = admission.NewAttributesRecord(
+					helpers.ToUnstructuredOrDie(tc.new),
+					helpers.ToUnstructuredOrDie(tc.old),
+					apisv1alpha1.Kind("APIExport").WithVersion("v1alpha1"),
+					"",
+					"test",
+					apisv1alpha1.Resource("apiexports").WithVersion("v1alpha1"),
+					"",
+					admission.Update,
+					&metav1.UpdateOptions{},
+					false,
+					&user.DefaultInfo{},
+				)
+			}
+
+			plugin := NewAPIExportAdmission(func(apisv1alpha1.GroupResource) bool { return false })
+			require.NoError(t, plugin.Admit(context.Background(), a, nil))
+
+			u, ok := a.GetObject().(*unstructured.Unstructured)
+			require.True(t, ok)
+			require.Equal(t, tc.want, u.GetAnnotations()[apisv1alpha1.AnnotationSchemaGenerationKey])
+		})
+	}
+}
@@ -163,6 +163,75 @@ spec:
 				"spec.group: Invalid value: \"core\": must be empty string for the core group",
 			},
 		},
+		{
+			name: "an APIResourceSchema with a conversion webhook can pass admission",
+			attr: createAttr(unmarshalOrDie(`
+apiVersion: apis.kcp.sh/v1alpha1
+kind: APIResourceSchema
+metadata:
+  name: july.cowboys.wild.west
+spec:
+  group: wild.west
+  names:
+    plural: cowboys
+    singular: cowboy
+    kind: Cowboy
+    listKind: CowboyList
+  scope: Cluster
+  versions:
+  - name: v1alpha1
+    served: true
+    storage: false
+    schema:
+      type: object
+  - name: v1beta1
+    served: true
+    storage: true
+    schema:
+      type: object
+  conversion:
+    strategy: Webhook
+    webhook:
+      clientConfig:
+        url: https://convert.wild.west/cowboys
+      conversionReviewVersions:
+      - v1
+            `)),
+		},
+		{
+			name: "an APIResourceSchema with an invalid conversion webhook fails admission",
+			attr: createAttr(unmarshalOrDie(`
+apiVersion: apis.kcp.sh/v1alpha1
+kind: APIResourceSchema
+metadata:
+  name: july.cowboys.wild.west
+spec:
+  group: wild.west
+  names:
+    plural: cowboys
+    singular: cowboy
+    kind: Cowboy
+    listKind: CowboyList
+  scope: Cluster
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      type: object
+  conversion:
+    strategy: Webhook
+    webhook:
+      clientConfig:
+        url: http://convert.wild.west/cowboys
+      conversionReviewVersions:
+      - v0
+            `)),
+			expectedErrors: []string{
+				"spec.conversion.webhook.clientConfig.url: Invalid value: \"http\": 'https' is the only allowed URL scheme",
+				"spec.conversion.webhook.conversionReviewVersions: Invalid value: []string{\"v0\"}: must include at least one of v1, v1beta1",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -156,12 +156,42 @@ func ValidateAPIResourceSchemaSpec(ctx context.Context, spec *apisv1alpha1.APIRe
 		allErrs = append(allErrs, crdvalidation.ValidateCustomResourceDefinitionNames(&crdNames, fldPath.Child("names"))...)
 	}
 
+	allErrs = append(allErrs, ValidateAPIResourceSchemaConversion(spec.Conversion, fldPath.Child("conversion"))...)
+
 	// TODO(sttts): validate predecessors
-	// TODO(sttts): validate conversions
 
 	return allErrs
 }
 
+// ValidateAPIResourceSchemaConversion validates the conversion of an APIResourceSchema the same way
+// as the conversion of a CRD.
+func ValidateAPIResourceSchemaConversion(conversion *apisv1alpha1.CustomResourceConversion, fldPath *field.Path) field.ErrorList {
+	if conversion == nil {
+		return nil
+	}
+
+	crdConversion := &apiextensionsinternal.CustomResourceConversion{
+		Strategy: apiextensionsinternal.ConversionStrategyType(conversion.Strategy),
+	}
+	if conversion.Webhook != nil {
+		url := conversion.Webhook.ClientConfig.URL
+		crdConversion.WebhookClientConfig = &apiextensionsinternal.WebhookClientConfig{
+			URL:      &url,
+			CABundle: conversion.Webhook.ClientConfig.CABundle,
+		}
+		crdConversion.ConversionReviewVersions = conversion.Webhook.ConversionReviewVersions
+	}
+
+	var allErrs field.ErrorList
+	for _, err := range crdvalidation.ValidateCustomResourceConversion(crdConversion, fldPath) {
+		// map the CRD field names to those of the APIResourceSchema
+		err.Field = strings.Replace(err.Field, fldPath.Child("webhookClientConfig").String(), fldPath.Child("webhook", "clientConfig").String(), 1)
+		err.Field = strings.Replace(err.Field, fldPath.Child("conversionReviewVersions").String(), fldPath.Child("webhook", "conversionReviewVersions").String(), 1)
+		allErrs = append(allErrs, err)
+	}
+	return allErrs
+}
+
 var defaultValidationOpts = crdvalidation.ValidationOptions{
 	AllowDefaults:                            true,
 	RequireRecognizedConversionReviewVersion: true,
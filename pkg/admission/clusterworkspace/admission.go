@@ -22,8 +22,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strings"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster/v2"
 
 	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/validation"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -31,14 +37,25 @@ import (
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apiserver/pkg/admission"
 	kuser "k8s.io/apiserver/pkg/authentication/user"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
 
+	kcpinitializers "github.com/kcp-dev/kcp/pkg/admission/initializers"
+	"github.com/kcp-dev/kcp/pkg/apis/tenancy/finalization"
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	kcpinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions"
+	tenancyv1alpha1listers "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
 )
 
 // Validate ClusterWorkspace creation and updates for
-// - immutability of fields like type
+// - immutability of fields like type and clone
 // - valid phase transitions fulfilling pre-conditions
-// - status.location.current and status.baseURL cannot be unset.
+// - finalizers only being removed after initialization started
+// - status.location.current and status.baseURL cannot be unset
+// - valid move targets, and moved-from annotations only set by the system
+// - quotas limiting only supported resources.
+//
+// Validate ClusterWorkspace deletion for
+// - ready workspaces with a retention period only being deleted through the workspaces API.
 
 // Mutate ClusterWorkspace creation and updates for
 // - initializers are short enough to be put into a label
@@ -52,24 +69,34 @@ func Register(plugins *admission.Plugins) {
 	plugins.Register(PluginName,
 		func(_ io.Reader) (admission.Interface, error) {
 			return &clusterWorkspace{
-				Handler: admission.NewHandler(admission.Create, admission.Update),
+				Handler: admission.NewHandler(admission.Create, admission.Update, admission.Delete),
 			}, nil
 		})
 }
 
 type clusterWorkspace struct {
 	*admission.Handler
+
+	typeLister              tenancyv1alpha1listers.ClusterWorkspaceTypeClusterLister
+	cachedTypeLister        tenancyv1alpha1listers.ClusterWorkspaceTypeClusterLister
+	cachedTypesSynced       func() bool
+	deletionRetentionPeriod time.Duration
 }
 
 // Ensure that the required admission interfaces are implemented.
 var _ admission.MutationInterface = &clusterWorkspace{}
 var _ admission.ValidationInterface = &clusterWorkspace{}
+var _ admission.InitializationValidator = &clusterWorkspace{}
+var _ kcpinitializers.WantsKcpInformers = &clusterWorkspace{}
+var _ kcpinitializers.WantsKcpCacheInformers = &clusterWorkspace{}
+var _ kcpinitializers.WantsDeletionRetentionPeriod = &clusterWorkspace{}
 
 var phaseOrdinal = map[tenancyv1alpha1.ClusterWorkspacePhaseType]int{
 	tenancyv1alpha1.ClusterWorkspacePhaseType(""):     1,
 	tenancyv1alpha1.ClusterWorkspacePhaseScheduling:   2,
 	tenancyv1alpha1.ClusterWorkspacePhaseInitializing: 3,
 	tenancyv1alpha1.ClusterWorkspacePhaseReady:        4,
+	tenancyv1alpha1.ClusterWorkspacePhaseDeleted:      5,
 }
 
 // Admit ensures that
@@ -78,6 +105,9 @@ func (o *clusterWorkspace) Admit(ctx context.Context, a admission.Attributes, _
 	if a.GetResource().GroupResource() != tenancyv1alpha1.Resource("clusterworkspaces") {
 		return nil
 	}
+	if a.GetOperation() == admission.Delete {
+		return nil
+	}
 
 	u, ok := a.GetObject().(*unstructured.Unstructured)
 	if !ok {
@@ -106,13 +136,20 @@ func (o *clusterWorkspace) Admit(ctx context.Context, a admission.Attributes, _
 
 // Validate ensures that
 // - the workspace only does a valid phase transition
+// - status.deletion is set exactly in the Deleted phase
 // - has a valid type
 // - has valid initializers when transitioning to initializing
+// - finalizers are not added after initialization started
 // - the user is recorded in annotations on create
+// - the workspace is not moved into itself
+// - a ready workspace is not deleted directly while deleted workspaces are retained
 func (o *clusterWorkspace) Validate(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) (err error) {
 	if a.GetResource().GroupResource() != tenancyv1alpha1.Resource("clusterworkspaces") {
 		return nil
 	}
+	if a.GetOperation() == admission.Delete {
+		return o.validateDelete(a)
+	}
 
 	u, ok := a.GetObject().(*unstructured.Unstructured)
 	if !ok {
@@ -123,6 +160,9 @@ func (o *clusterWorkspace) Validate(ctx context.Context, a admission.Attributes,
 		return fmt.Errorf("failed to convert unstructured to ClusterWorkspace: %w", err)
 	}
 
+	isSystemMaster := sets.NewString(a.GetUserInfo().GetGroups()...).Has(kuser.SystemPrivilegedGroup)
+
+	var oldAnnotations map[string]string
 	if a.GetOperation() == admission.Update {
 		u, ok = a.GetOldObject().(*unstructured.Unstructured)
 		if !ok {
@@ -140,6 +180,24 @@ func (o *clusterWorkspace) Validate(ctx context.Context, a admission.Attributes,
 			return admission.NewForbidden(a, errors.New("spec.type is immutable"))
 		}
 
+		if errs := validation.ValidateImmutableField(cw.Spec.Clone, old.Spec.Clone, field.NewPath("spec", "clone")); len(errs) > 0 {
+			return admission.NewForbidden(a, errs.ToAggregate())
+		}
+
+		if cw.Spec.MoveTo != nil && !equality.Semantic.DeepEqual(old.Spec.MoveTo, cw.Spec.MoveTo) {
+			if old.Status.Move != nil {
+				return admission.NewForbidden(a, errors.New("spec.moveTo cannot be changed while the workspace is moved"))
+			}
+			clusterName, err := genericapirequest.ClusterNameFrom(ctx)
+			if err != nil {
+				return admission.NewForbidden(a, err)
+			}
+			if strings.HasPrefix(cw.Spec.MoveTo.Path, clusterName.Join(cw.Name).String()+":") {
+				return admission.NewForbidden(a, errors.New("spec.moveTo.path cannot be inside of the workspace"))
+			}
+		}
+		oldAnnotations = old.Annotations
+
 		if old.Status.Location.Current != "" && cw.Status.Location.Current == "" {
 			return admission.NewForbidden(a, errors.New("status.location.current cannot be unset"))
 		}
@@ -148,13 +206,39 @@ func (o *clusterWorkspace) Validate(ctx context.Context, a admission.Attributes,
 			return admission.NewForbidden(a, errors.New("status.baseURL cannot be unset"))
 		}
 
-		if phaseOrdinal[old.Status.Phase] > phaseOrdinal[cw.Status.Phase] {
+		// undeleting a soft-deleted workspace is the only backwards transition
+		isUndelete := old.Status.Phase == tenancyv1alpha1.ClusterWorkspacePhaseDeleted && cw.Status.Phase == tenancyv1alpha1.ClusterWorkspacePhaseReady
+		if phaseOrdinal[old.Status.Phase] > phaseOrdinal[cw.Status.Phase] && !isUndelete {
+			return admission.NewForbidden(a, fmt.Errorf("cannot transition from %q to %q", old.Status.Phase, cw.Status.Phase))
+		}
+		if cw.Status.Phase == tenancyv1alpha1.ClusterWorkspacePhaseDeleted && old.Status.Phase != tenancyv1alpha1.ClusterWorkspacePhaseReady && old.Status.Phase != tenancyv1alpha1.ClusterWorkspacePhaseDeleted {
 			return admission.NewForbidden(a, fmt.Errorf("cannot transition from %q to %q", old.Status.Phase, cw.Status.Phase))
 		}
+
+		// finalizers are only added by the system on the transition to Initializing
+		if phaseOrdinal[old.Status.Phase] >= phaseOrdinal[tenancyv1alpha1.ClusterWorkspacePhaseInitializing] {
+			for _, finalizer := range cw.Status.Finalizers {
+				if !finalization.FinalizerPresent(finalizer, old.Status.Finalizers) {
+					return admission.NewForbidden(a, fmt.Errorf("status.finalizers %q cannot be added in phase %s", finalizer, old.Status.Phase))
+				}
+			}
+		}
+	}
+
+	if errs := validateQuota(cw.Spec.Quota, field.NewPath("spec", "quota")); len(errs) > 0 {
+		return admission.NewForbidden(a, errs.ToAggregate())
+	}
+
+	if !isSystemMaster {
+		for _, key := range []string{tenancyv1alpha1.ClusterWorkspaceMovedFromAnnotationKey, tenancyv1alpha1.ClusterWorkspaceMovedAtAnnotationKey} {
+			if cw.Annotations[key] != oldAnnotations[key] {
+				return admission.NewForbidden(a, fmt.Errorf("annotation %s can only be set by the system", key))
+			}
+		}
 	}
 
 	if a.GetOperation() == admission.Create {
-		if isSystemMaster := sets.NewString(a.GetUserInfo().GetGroups()...).Has(kuser.SystemPrivilegedGroup); !isSystemMaster {
+		if !isSystemMaster {
 			userInfo, err := ClusterWorkspaceOwnerAnnotationValue(a.GetUserInfo())
 			if err != nil {
 				return admission.NewForbidden(a, err)
@@ -168,6 +252,13 @@ func (o *clusterWorkspace) Validate(ctx context.Context, a admission.Attributes,
 		}
 	}
 
+	if cw.Status.Phase == tenancyv1alpha1.ClusterWorkspacePhaseDeleted && cw.Status.Deletion == nil {
+		return admission.NewForbidden(a, fmt.Errorf("status.deletion must be set for phase %s", cw.Status.Phase))
+	}
+	if cw.Status.Phase != tenancyv1alpha1.ClusterWorkspacePhaseDeleted && cw.Status.Deletion != nil {
+		return admission.NewForbidden(a, fmt.Errorf("status.deletion must not be set for phase %s", cw.Status.Phase))
+	}
+
 	if phaseOrdinal[cw.Status.Phase] > phaseOrdinal[tenancyv1alpha1.ClusterWorkspacePhaseInitializing] && len(cw.Status.Initializers) > 0 {
 		return admission.NewForbidden(a, fmt.Errorf("spec.initializers must be empty for phase %s", cw.Status.Phase))
 	}
@@ -184,6 +275,114 @@ func (o *clusterWorkspace) Validate(ctx context.Context, a admission.Attributes,
 	return nil
 }
 
+// validateDelete rejects deleting a ready workspace directly if a deletion retention period
+// applies to it. Such workspaces must be deleted through the workspaces API, which soft-deletes
+// them so that they can be undeleted. Workspaces in the Deleted phase and system:masters are
+// not affected.
+func (o *clusterWorkspace) validateDelete(a admission.Attributes) error {
+	if sets.NewString(a.GetUserInfo().GetGroups()...).Has(kuser.SystemPrivilegedGroup) {
+		return nil
+	}
+
+	u, ok := a.GetOldObject().(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("unexpected type %T", a.GetOldObject())
+	}
+	cw := &tenancyv1alpha1.ClusterWorkspace{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, cw); err != nil {
+		return fmt.Errorf("failed to convert unstructured to ClusterWorkspace: %w", err)
+	}
+
+	if cw.Status.Phase != tenancyv1alpha1.ClusterWorkspacePhaseReady {
+		return nil
+	}
+	retention, err := o.deletionRetentionPeriodOf(cw)
+	if err != nil {
+		return admission.NewForbidden(a, err)
+	}
+	if retention <= 0 {
+		return nil
+	}
+
+	return admission.NewForbidden(a, fmt.Errorf("workspace %s is retained for %s after deletion and must be deleted through the workspaces API, e.g. with \"kubectl delete workspace %s\"", cw.Name, retention.Round(time.Second), cw.Name))
+}
+
+// deletionRetentionPeriodOf returns the retention period of the ClusterWorkspaceType of the
+// workspace, or the server-wide default if the type does not specify one. Like in the
+// workspaces API, a type that does not exist gets the server-wide default.
+func (o *clusterWorkspace) deletionRetentionPeriodOf(cw *tenancyv1alpha1.ClusterWorkspace) (time.Duration, error) {
+	if cw.Spec.Type.Path == "" || cw.Spec.Type.Name == "" {
+		return o.deletionRetentionPeriod, nil
+	}
+	cwt, err := o.getType(logicalcluster.New(cw.Spec.Type.Path), tenancyv1alpha1.ObjectName(cw.Spec.Type.Name))
+	if apierrors.IsNotFound(err) {
+		return o.deletionRetentionPeriod, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get ClusterWorkspaceType %s:%s: %w", cw.Spec.Type.Path, cw.Spec.Type.Name, err)
+	}
+	if cwt.Spec.DeletionRetentionPeriod != nil {
+		return cwt.Spec.DeletionRetentionPeriod.Duration, nil
+	}
+	return o.deletionRetentionPeriod, nil
+}
+
+func (o *clusterWorkspace) ValidateInitialization() error {
+	if o.typeLister == nil {
+		return fmt.Errorf(PluginName + " plugin needs a ClusterWorkspaceType lister")
+	}
+	return nil
+}
+
+func (o *clusterWorkspace) SetKcpInformers(informers kcpinformers.SharedInformerFactory) {
+	o.SetReadyFunc(informers.Tenancy().V1alpha1().ClusterWorkspaceTypes().Informer().HasSynced)
+	o.typeLister = informers.Tenancy().V1alpha1().ClusterWorkspaceTypes().Lister()
+}
+
+func (o *clusterWorkspace) SetKcpCacheInformers(informers kcpinformers.SharedInformerFactory) {
+	o.cachedTypesSynced = informers.Tenancy().V1alpha1().ClusterWorkspaceTypes().Informer().HasSynced
+	o.cachedTypeLister = informers.Tenancy().V1alpha1().ClusterWorkspaceTypes().Lister()
+}
+
+func (o *clusterWorkspace) SetDeletionRetentionPeriod(period time.Duration) {
+	o.deletionRetentionPeriod = period
+}
+
+// getType returns the ClusterWorkspaceType from the local shard, or from the cache server if
+// it is stored on another shard.
+func (o *clusterWorkspace) getType(path logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspaceType, error) {
+	cwt, err := o.typeLister.Cluster(path).Get(name)
+	if apierrors.IsNotFound(err) && o.cachedTypeLister != nil {
+		if o.cachedTypesSynced != nil && !o.cachedTypesSynced() {
+			return nil, fmt.Errorf("not yet ready to handle request")
+		}
+		return o.cachedTypeLister.Cluster(path).Get(name)
+	}
+	return cwt, err
+}
+
+var supportedQuotaResources = sets.NewString(
+	string(tenancyv1alpha1.QuotaResourceWorkspaces),
+	string(tenancyv1alpha1.QuotaResourceObjects),
+	string(tenancyv1alpha1.QuotaResourceStorage),
+)
+
+// validateQuota checks that a quota only limits supported resources with non-negative values.
+func validateQuota(quota *tenancyv1alpha1.ClusterWorkspaceQuota, fldPath *field.Path) field.ErrorList {
+	if quota == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	for name, value := range quota.Hard {
+		if !supportedQuotaResources.Has(string(name)) {
+			errs = append(errs, field.NotSupported(fldPath.Child("hard").Key(string(name)), string(name), supportedQuotaResources.List()))
+		} else if value.Sign() < 0 {
+			errs = append(errs, field.Invalid(fldPath.Child("hard").Key(string(name)), value.String(), "must not be negative"))
+		}
+	}
+	return errs
+}
+
 // updateUnstructured updates the given unstructured object to match the given cluster workspace.
 func updateUnstructured(u *unstructured.Unstructured, cw *tenancyv1alpha1.ClusterWorkspace) error {
 	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cw)
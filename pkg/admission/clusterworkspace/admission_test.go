@@ -19,20 +19,26 @@ package clusterworkspace
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	kcpcache "github.com/kcp-dev/apimachinery/pkg/cache"
 	"github.com/kcp-dev/logicalcluster/v2"
 	"github.com/stretchr/testify/require"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apiserver/pkg/admission"
 	"k8s.io/apiserver/pkg/authentication/user"
 	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/tools/cache"
 
 	"github.com/kcp-dev/kcp/pkg/admission/helpers"
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	tenancyv1alpha1listers "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
 )
 
 func createAttr(ws *tenancyv1alpha1.ClusterWorkspace) admission.Attributes {
@@ -71,6 +77,22 @@ func updateAttr(ws, old *tenancyv1alpha1.ClusterWorkspace) admission.Attributes
 	)
 }
 
+func deleteAttr(old *tenancyv1alpha1.ClusterWorkspace, info user.Info) admission.Attributes {
+	return admission.NewAttributesRecord(
+		nil,
+		helpers.ToUnstructuredOrDie(old),
+		tenancyv1alpha1.Kind("ClusterWorkspace").WithVersion("v1alpha1"),
+		"",
+		old.Name,
+		tenancyv1alpha1.Resource("clusterworkspaces").WithVersion("v1alpha1"),
+		"",
+		admission.Delete,
+		&metav1.DeleteOptions{},
+		false,
+		info,
+	)
+}
+
 func TestAdmit(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -251,6 +273,161 @@ func TestValidate(t *testing.T) {
 				}),
 			expectedErrors: []string{"field is immutable"},
 		},
+		{
+			name: "rejects clone mutations",
+			a: updateAttr(&tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test",
+					Annotations: map[string]string{"experimental.tenancy.kcp.dev/owner": "{}"},
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					Clone: &tenancyv1alpha1.ClusterWorkspaceCloneSource{Path: "root:org:other"},
+				},
+			},
+				&tenancyv1alpha1.ClusterWorkspace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "test",
+						Annotations: map[string]string{"experimental.tenancy.kcp.dev/owner": "{}"},
+					},
+					Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+						Clone: &tenancyv1alpha1.ClusterWorkspaceCloneSource{Path: "root:org:template"},
+					},
+				}),
+			expectedErrors: []string{"field is immutable"},
+		},
+		{
+			name: "allows moving to another parent",
+			a: updateAttr(&tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test",
+					Annotations: map[string]string{"experimental.tenancy.kcp.dev/owner": "{}"},
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					MoveTo: &tenancyv1alpha1.ClusterWorkspaceMoveTarget{Path: "root:other:test"},
+				},
+			},
+				&tenancyv1alpha1.ClusterWorkspace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "test",
+						Annotations: map[string]string{"experimental.tenancy.kcp.dev/owner": "{}"},
+					},
+				}),
+		},
+		{
+			name: "rejects moving into itself",
+			a: updateAttr(&tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test",
+					Annotations: map[string]string{"experimental.tenancy.kcp.dev/owner": "{}"},
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					MoveTo: &tenancyv1alpha1.ClusterWorkspaceMoveTarget{Path: "root:org:test:child"},
+				},
+			},
+				&tenancyv1alpha1.ClusterWorkspace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "test",
+						Annotations: map[string]string{"experimental.tenancy.kcp.dev/owner": "{}"},
+					},
+				}),
+			expectedErrors: []string{"spec.moveTo.path cannot be inside of the workspace"},
+		},
+		{
+			name: "rejects changing the move target during a move",
+			a: updateAttr(&tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test",
+					Annotations: map[string]string{"experimental.tenancy.kcp.dev/owner": "{}"},
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					MoveTo: &tenancyv1alpha1.ClusterWorkspaceMoveTarget{Path: "root:other:test"},
+				},
+				Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+					Move: &tenancyv1alpha1.ClusterWorkspaceMove{Phase: tenancyv1alpha1.ClusterWorkspaceMovePhaseRenaming, From: "root:org:test", To: "root:another:test"},
+				},
+			},
+				&tenancyv1alpha1.ClusterWorkspace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "test",
+						Annotations: map[string]string{"experimental.tenancy.kcp.dev/owner": "{}"},
+					},
+					Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+						MoveTo: &tenancyv1alpha1.ClusterWorkspaceMoveTarget{Path: "root:another:test"},
+					},
+					Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+						Move: &tenancyv1alpha1.ClusterWorkspaceMove{Phase: tenancyv1alpha1.ClusterWorkspaceMovePhaseRenaming, From: "root:org:test", To: "root:another:test"},
+					},
+				}),
+			expectedErrors: []string{"spec.moveTo cannot be changed while the workspace is moved"},
+		},
+		{
+			name: "rejects setting the moved-from annotation",
+			a: updateAttr(&tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+					Annotations: map[string]string{
+						"experimental.tenancy.kcp.dev/owner": "{}",
+						"tenancy.kcp.dev/moved-from":         "root:org:someone-else",
+					},
+				},
+			},
+				&tenancyv1alpha1.ClusterWorkspace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "test",
+						Annotations: map[string]string{"experimental.tenancy.kcp.dev/owner": "{}"},
+					},
+				}),
+			expectedErrors: []string{"annotation tenancy.kcp.dev/moved-from can only be set by the system"},
+		},
+		{
+			name: "allows a quota of supported resources",
+			a: createAttr(&tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test",
+					Annotations: map[string]string{"experimental.tenancy.kcp.dev/owner": "{}"},
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					Quota: &tenancyv1alpha1.ClusterWorkspaceQuota{
+						Hard: corev1.ResourceList{
+							tenancyv1alpha1.QuotaResourceWorkspaces: resource.MustParse("10"),
+							tenancyv1alpha1.QuotaResourceObjects:    resource.MustParse("1000"),
+							tenancyv1alpha1.QuotaResourceStorage:    resource.MustParse("10Mi"),
+						},
+						Inherited: true,
+					},
+				},
+			}),
+		},
+		{
+			name: "rejects a quota of unsupported resources",
+			a: createAttr(&tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test",
+					Annotations: map[string]string{"experimental.tenancy.kcp.dev/owner": "{}"},
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					Quota: &tenancyv1alpha1.ClusterWorkspaceQuota{
+						Hard: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+					},
+				},
+			}),
+			expectedErrors: []string{`spec.quota.hard[cpu]: Unsupported value: "cpu": supported values: "objects", "storage", "workspaces"`},
+		},
+		{
+			name: "rejects a negative quota",
+			a: createAttr(&tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test",
+					Annotations: map[string]string{"experimental.tenancy.kcp.dev/owner": "{}"},
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					Quota: &tenancyv1alpha1.ClusterWorkspaceQuota{
+						Hard: corev1.ResourceList{tenancyv1alpha1.QuotaResourceObjects: resource.MustParse("-1")},
+					},
+				},
+			}),
+			expectedErrors: []string{`spec.quota.hard[objects]: Invalid value: "-1": must not be negative`},
+		},
 		{
 			name: "rejects unsetting location",
 			a: updateAttr(&tenancyv1alpha1.ClusterWorkspace{
@@ -532,6 +709,44 @@ func TestValidate(t *testing.T) {
 				}),
 			expectedErrors: []string{"cannot transition from \"Ready\" to \"Initializing\""},
 		},
+		{
+			name: "allows soft-deleting a ready workspace",
+			a:    updateAttr(workspaceInPhase(tenancyv1alpha1.ClusterWorkspacePhaseDeleted, &tenancyv1alpha1.ClusterWorkspaceDeletion{}), workspaceInPhase(tenancyv1alpha1.ClusterWorkspacePhaseReady, nil)),
+		},
+		{
+			name: "allows undeleting a soft-deleted workspace",
+			a:    updateAttr(workspaceInPhase(tenancyv1alpha1.ClusterWorkspacePhaseReady, nil), workspaceInPhase(tenancyv1alpha1.ClusterWorkspacePhaseDeleted, &tenancyv1alpha1.ClusterWorkspaceDeletion{})),
+		},
+		{
+			name:           "rejects soft-deleting an initializing workspace",
+			a:              updateAttr(workspaceInPhase(tenancyv1alpha1.ClusterWorkspacePhaseDeleted, &tenancyv1alpha1.ClusterWorkspaceDeletion{}), workspaceInPhase(tenancyv1alpha1.ClusterWorkspacePhaseInitializing, nil)),
+			expectedErrors: []string{"cannot transition from \"Initializing\" to \"Deleted\""},
+		},
+		{
+			name:           "rejects the Deleted phase without status.deletion",
+			a:              updateAttr(workspaceInPhase(tenancyv1alpha1.ClusterWorkspacePhaseDeleted, nil), workspaceInPhase(tenancyv1alpha1.ClusterWorkspacePhaseReady, nil)),
+			expectedErrors: []string{"status.deletion must be set for phase Deleted"},
+		},
+		{
+			name:           "rejects status.deletion outside of the Deleted phase",
+			a:              updateAttr(workspaceInPhase(tenancyv1alpha1.ClusterWorkspacePhaseReady, &tenancyv1alpha1.ClusterWorkspaceDeletion{}), workspaceInPhase(tenancyv1alpha1.ClusterWorkspacePhaseReady, nil)),
+			expectedErrors: []string{"status.deletion must not be set for phase Ready"},
+		},
+		{
+			name: "allows removing a finalizer",
+			a: updateAttr(
+				workspaceInPhase(tenancyv1alpha1.ClusterWorkspacePhaseReady, nil),
+				withFinalizers(workspaceInPhase(tenancyv1alpha1.ClusterWorkspacePhaseReady, nil), "root:org:foo"),
+			),
+		},
+		{
+			name: "rejects adding a finalizer after initialization started",
+			a: updateAttr(
+				withFinalizers(workspaceInPhase(tenancyv1alpha1.ClusterWorkspacePhaseReady, nil), "root:org:foo", "root:org:bar"),
+				withFinalizers(workspaceInPhase(tenancyv1alpha1.ClusterWorkspacePhaseReady, nil), "root:org:foo"),
+			),
+			expectedErrors: []string{"status.finalizers \"root:org:bar\" cannot be added in phase Ready"},
+		},
 		{
 			name: "ignores different resources",
 			a: admission.NewAttributesRecord(
@@ -647,6 +862,109 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+func TestValidateDelete(t *testing.T) {
+	local := []*tenancyv1alpha1.ClusterWorkspaceType{
+		withRetention(newType("root:org:foo").ClusterWorkspaceType, &metav1.Duration{Duration: time.Hour}),
+		newType("root:org:bar").ClusterWorkspaceType,
+		withRetention(newType("root:org:baz").ClusterWorkspaceType, &metav1.Duration{}),
+	}
+	cached := []*tenancyv1alpha1.ClusterWorkspaceType{
+		withRetention(newType("root:other:remote").ClusterWorkspaceType, &metav1.Duration{Duration: 2 * time.Hour}),
+	}
+	someone := &user.DefaultInfo{Name: "someone"}
+
+	tests := []struct {
+		name                    string
+		a                       admission.Attributes
+		deletionRetentionPeriod time.Duration
+		expectedErrors          []string
+	}{
+		{
+			name:           "rejects deleting a ready workspace whose type retains deleted workspaces",
+			a:              deleteAttr(workspaceInPhase(tenancyv1alpha1.ClusterWorkspacePhaseReady, nil), someone),
+			expectedErrors: []string{`workspace test is retained for 1h0m0s after deletion and must be deleted through the workspaces API, e.g. with "kubectl delete workspace test"`},
+		},
+		{
+			name:           "rejects deleting a ready workspace whose type on another shard retains deleted workspaces",
+			a:              deleteAttr(withType(workspaceInPhase(tenancyv1alpha1.ClusterWorkspacePhaseReady, nil), "root:other", "remote"), someone),
+			expectedErrors: []string{"workspace test is retained for 2h0m0s after deletion"},
+		},
+		{
+			name:                    "rejects deleting a ready workspace whose type has no retention period with a server-wide default",
+			a:                       deleteAttr(withType(workspaceInPhase(tenancyv1alpha1.ClusterWorkspacePhaseReady, nil), "root:org", "bar"), someone),
+			deletionRetentionPeriod: 30 * time.Minute,
+			expectedErrors:          []string{"workspace test is retained for 30m0s after deletion"},
+		},
+		{
+			name:                    "rejects deleting a ready workspace whose type does not exist with a server-wide default",
+			a:                       deleteAttr(withType(workspaceInPhase(tenancyv1alpha1.ClusterWorkspacePhaseReady, nil), "root:org", "unknown"), someone),
+			deletionRetentionPeriod: 30 * time.Minute,
+			expectedErrors:          []string{"workspace test is retained for 30m0s after deletion"},
+		},
+		{
+			name: "allows system:masters to delete a ready workspace whose type retains deleted workspaces",
+			a:    deleteAttr(workspaceInPhase(tenancyv1alpha1.ClusterWorkspacePhaseReady, nil), &user.DefaultInfo{Name: "admin", Groups: []string{user.SystemPrivilegedGroup}}),
+		},
+		{
+			name: "allows deleting a soft-deleted workspace",
+			a:    deleteAttr(workspaceInPhase(tenancyv1alpha1.ClusterWorkspacePhaseDeleted, &tenancyv1alpha1.ClusterWorkspaceDeletion{}), someone),
+		},
+		{
+			name: "allows deleting an initializing workspace",
+			a:    deleteAttr(workspaceInPhase(tenancyv1alpha1.ClusterWorkspacePhaseInitializing, nil), someone),
+		},
+		{
+			name: "allows deleting a ready workspace whose type has no retention period",
+			a:    deleteAttr(withType(workspaceInPhase(tenancyv1alpha1.ClusterWorkspacePhaseReady, nil), "root:org", "bar"), someone),
+		},
+		{
+			name:                    "allows deleting a ready workspace whose type has a zero retention period with a server-wide default",
+			a:                       deleteAttr(withType(workspaceInPhase(tenancyv1alpha1.ClusterWorkspacePhaseReady, nil), "root:org", "baz"), someone),
+			deletionRetentionPeriod: 30 * time.Minute,
+		},
+		{
+			name: "allows deleting a ready workspace whose type does not exist",
+			a:    deleteAttr(withType(workspaceInPhase(tenancyv1alpha1.ClusterWorkspacePhaseReady, nil), "root:org", "unknown"), someone),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &clusterWorkspace{
+				Handler:                 admission.NewHandler(admission.Create, admission.Update, admission.Delete),
+				typeLister:              newTypeLister(t, local...),
+				cachedTypeLister:        newTypeLister(t, cached...),
+				cachedTypesSynced:       func() bool { return true },
+				deletionRetentionPeriod: tt.deletionRetentionPeriod,
+			}
+			ctx := request.WithCluster(context.Background(), request.Cluster{Name: logicalcluster.New("root:org")})
+			require.NoError(t, o.Admit(ctx, tt.a, nil))
+			err := o.Validate(ctx, tt.a, nil)
+			wantErr := len(tt.expectedErrors) > 0
+			require.Equal(t, wantErr, err != nil, "unexpected error: %v", err)
+
+			if err != nil {
+				t.Logf("Got admission errors: %v", err)
+				for _, expected := range tt.expectedErrors {
+					require.Contains(t, err.Error(), expected)
+				}
+			}
+		})
+	}
+}
+
+func newTypeLister(t *testing.T, types ...*tenancyv1alpha1.ClusterWorkspaceType) tenancyv1alpha1listers.ClusterWorkspaceTypeClusterLister {
+	indexer := cache.NewIndexer(kcpcache.MetaClusterNamespaceKeyFunc, cache.Indexers{kcpcache.ClusterIndexName: kcpcache.ClusterIndexFunc})
+	for _, cwt := range types {
+		require.NoError(t, indexer.Add(cwt))
+	}
+	return tenancyv1alpha1listers.NewClusterWorkspaceTypeClusterLister(indexer)
+}
+
+func withRetention(cwt *tenancyv1alpha1.ClusterWorkspaceType, period *metav1.Duration) *tenancyv1alpha1.ClusterWorkspaceType {
+	cwt.Spec.DeletionRetentionPeriod = period
+	return cwt
+}
+
 type builder struct {
 	*tenancyv1alpha1.ClusterWorkspaceType
 }
@@ -662,3 +980,34 @@ func newType(qualifiedName string) builder {
 		},
 	}}
 }
+
+func workspaceInPhase(phase tenancyv1alpha1.ClusterWorkspacePhaseType, deletion *tenancyv1alpha1.ClusterWorkspaceDeletion) *tenancyv1alpha1.ClusterWorkspace {
+	return &tenancyv1alpha1.ClusterWorkspace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test",
+			Annotations: map[string]string{"experimental.tenancy.kcp.dev/owner": "{}"},
+		},
+		Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+			Type: tenancyv1alpha1.ClusterWorkspaceTypeReference{
+				Name: "foo",
+				Path: "root:org",
+			},
+		},
+		Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+			Phase:    phase,
+			Location: tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+			BaseURL:  "https://kcp.bigcorp.com/clusters/org:test",
+			Deletion: deletion,
+		},
+	}
+}
+
+func withFinalizers(cw *tenancyv1alpha1.ClusterWorkspace, finalizers ...tenancyv1alpha1.ClusterWorkspaceFinalizer) *tenancyv1alpha1.ClusterWorkspace {
+	cw.Status.Finalizers = finalizers
+	return cw
+}
+
+func withType(cw *tenancyv1alpha1.ClusterWorkspace, path string, name tenancyv1alpha1.ClusterWorkspaceTypeName) *tenancyv1alpha1.ClusterWorkspace {
+	cw.Spec.Type = tenancyv1alpha1.ClusterWorkspaceTypeReference{Path: path, Name: name}
+	return cw
+}
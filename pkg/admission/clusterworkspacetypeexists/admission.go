@@ -26,6 +26,7 @@ import (
 	kcpkubernetesclientset "github.com/kcp-dev/client-go/kubernetes"
 	"github.com/kcp-dev/logicalcluster/v2"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -36,6 +37,7 @@ import (
 	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
 
 	kcpinitializers "github.com/kcp-dev/kcp/pkg/admission/initializers"
+	"github.com/kcp-dev/kcp/pkg/apis/tenancy/finalization"
 	"github.com/kcp-dev/kcp/pkg/apis/tenancy/initialization"
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
 	"github.com/kcp-dev/kcp/pkg/authorization/delegated"
@@ -179,9 +181,18 @@ func (o *clusterWorkspaceTypeExists) Admit(ctx context.Context, a admission.Attr
 		if alias.Spec.Initializer {
 			cw.Status.Initializers = initialization.EnsureInitializerPresent(initialization.InitializerForType(alias), cw.Status.Initializers)
 		}
+		if alias.Spec.Finalizer {
+			cw.Status.Finalizers = finalization.EnsureFinalizerPresent(finalization.FinalizerForType(alias), cw.Status.Finalizers)
+		}
 		if len(alias.Spec.DefaultAPIBindings) > 0 {
 			cw.Status.Initializers = initialization.EnsureInitializerPresent(tenancyv1alpha1.ClusterWorkspaceAPIBindingsInitializer, cw.Status.Initializers)
 		}
+		if len(alias.Spec.Templates) > 0 {
+			cw.Status.Initializers = initialization.EnsureInitializerPresent(tenancyv1alpha1.ClusterWorkspaceCloneInitializer, cw.Status.Initializers)
+		}
+	}
+	if cw.Spec.Clone != nil {
+		cw.Status.Initializers = initialization.EnsureInitializerPresent(tenancyv1alpha1.ClusterWorkspaceCloneInitializer, cw.Status.Initializers)
 	}
 
 	return updateUnstructured(u, cw)
@@ -239,6 +250,7 @@ func (o *clusterWorkspaceTypeExists) resolveParentType(parentClusterName logical
 // Validate ensures that
 // - has a valid type
 // - has valid initializers when transitioning to initializing
+// - can be moved into the new parent in spec.moveTo
 func (o *clusterWorkspaceTypeExists) Validate(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) (err error) {
 	clusterName, err := genericapirequest.ClusterNameFrom(ctx)
 	if err != nil {
@@ -319,6 +331,11 @@ func (o *clusterWorkspaceTypeExists) Validate(ctx context.Context, a admission.A
 					return admission.NewForbidden(a, fmt.Errorf("spec.initializers %q does not exist", initializer))
 				}
 			}
+			if alias.Spec.Finalizer {
+				if finalizer := finalization.FinalizerForType(alias); !finalization.FinalizerPresent(finalizer, cw.Status.Finalizers) {
+					return admission.NewForbidden(a, fmt.Errorf("status.finalizers %q does not exist", finalizer))
+				}
+			}
 		}
 	}
 
@@ -350,6 +367,35 @@ func (o *clusterWorkspaceTypeExists) Validate(ctx context.Context, a admission.A
 			}
 		}
 
+		// verify that the clone source can be copied by the given user
+		if cw.Spec.Clone != nil {
+			sourceCluster := logicalcluster.New(cw.Spec.Clone.Path)
+			sourceParent, hasParent := sourceCluster.Parent()
+			if !hasParent {
+				return admission.NewForbidden(a, fmt.Errorf("spec.clone.path %q cannot be cloned", cw.Spec.Clone.Path))
+			}
+			authz, err := o.createAuthorizer(sourceParent, o.deepSARClient)
+			if err != nil {
+				return admission.NewForbidden(a, fmt.Errorf("unable to determine access to clone source %q", cw.Spec.Clone.Path))
+			}
+
+			adminAttr := authorizer.AttributesRecord{
+				User:            a.GetUserInfo(),
+				Verb:            "admin",
+				APIGroup:        tenancyv1alpha1.SchemeGroupVersion.Group,
+				APIVersion:      tenancyv1alpha1.SchemeGroupVersion.Version,
+				Resource:        "workspaces",
+				Subresource:     "content",
+				Name:            sourceCluster.Base(),
+				ResourceRequest: true,
+			}
+			if decision, _, err := authz.Authorize(ctx, adminAttr); err != nil {
+				return admission.NewForbidden(a, fmt.Errorf("unable to determine access to clone source %q: %w", cw.Spec.Clone.Path, err))
+			} else if decision != authorizer.DecisionAllow {
+				return admission.NewForbidden(a, fmt.Errorf("unable to clone workspace %q: missing verb='admin' permission on workspaces/content", cw.Spec.Clone.Path))
+			}
+		}
+
 		// validate whether the workspace type is allowed in its parent, and the workspace type allows that parent
 		parentTypeRef, err := o.resolveParentType(clusterName)
 		if err != nil {
@@ -372,6 +418,58 @@ func (o *clusterWorkspaceTypeExists) Validate(ctx context.Context, a admission.A
 		}
 	}
 
+	// verify that the workspace can be moved into the new parent by the given user
+	if a.GetOperation() == admission.Update && cw.Spec.MoveTo != nil && !equality.Semantic.DeepEqual(old.Spec.MoveTo, cw.Spec.MoveTo) {
+		targetParent, _ := logicalcluster.New(cw.Spec.MoveTo.Path).Parent()
+		authz, err := o.createAuthorizer(targetParent, o.deepSARClient)
+		if err != nil {
+			return admission.NewForbidden(a, fmt.Errorf("unable to determine access to workspace %q", targetParent))
+		}
+
+		createAttr := authorizer.AttributesRecord{
+			User:            a.GetUserInfo(),
+			Verb:            "create",
+			APIGroup:        tenancyv1alpha1.SchemeGroupVersion.Group,
+			APIVersion:      tenancyv1alpha1.SchemeGroupVersion.Version,
+			Resource:        "clusterworkspaces",
+			ResourceRequest: true,
+		}
+		if decision, _, err := authz.Authorize(ctx, createAttr); err != nil {
+			return admission.NewForbidden(a, fmt.Errorf("unable to determine access to workspace %q: %w", targetParent, err))
+		} else if decision != authorizer.DecisionAllow {
+			return admission.NewForbidden(a, fmt.Errorf("unable to move workspace to %q: missing verb='create' permission on clusterworkspaces", targetParent))
+		}
+
+		// validate whether the workspace type is allowed in the new parent, and the workspace type allows that parent
+		cwt, err := o.resolveTypeRef(clusterName, cw.Spec.Type)
+		if err != nil {
+			return admission.NewForbidden(a, err)
+		}
+		cwtAliases, err := o.transitiveTypeResolver.Resolve(cwt)
+		if err != nil {
+			return admission.NewForbidden(a, err)
+		}
+		parentTypeRef, err := o.resolveParentType(targetParent)
+		if err != nil {
+			return admission.NewForbidden(a, err)
+		}
+		parentCwt, err := o.resolveTypeRef(targetParent, parentTypeRef)
+		if err != nil {
+			return admission.NewForbidden(a, err)
+		}
+		parentAliases, err := o.transitiveTypeResolver.Resolve(parentCwt)
+		if err != nil {
+			return admission.NewForbidden(a, err)
+		}
+
+		if err := validateAllowedParents(parentAliases, cwtAliases, parentTypeRef.String(), cw.Spec.Type.String()); err != nil {
+			return admission.NewForbidden(a, err)
+		}
+		if err := validateAllowedChildren(parentAliases, cwtAliases, parentTypeRef.String(), cw.Spec.Type.String()); err != nil {
+			return admission.NewForbidden(a, err)
+		}
+	}
+
 	return nil
 }
 
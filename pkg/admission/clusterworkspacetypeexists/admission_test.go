@@ -110,6 +110,30 @@ func TestAdmit(t *testing.T) {
 				BaseURL:      "https://kcp.bigcorp.com/clusters/org:test",
 			}).ClusterWorkspace,
 		},
+		{
+			name: "adds finalizers during transition to initializing",
+			types: []*tenancyv1alpha1.ClusterWorkspaceType{
+				newType("root:org:other").withFinalizer().ClusterWorkspaceType,
+				newType("root:org:foo").withFinalizer().extending("root:org:other").ClusterWorkspaceType,
+			},
+			clusterName: logicalcluster.New("root:org:ws"),
+			a: updateAttr(
+				newWorkspace("root:org:ws:test").withType("root:org:foo").withStatus(tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase:    tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+					Location: tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+					BaseURL:  "https://kcp.bigcorp.com/clusters/org:test",
+				}).ClusterWorkspace,
+				newWorkspace("root:org:ws:test").withType("root:org:foo").withStatus(tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase: tenancyv1alpha1.ClusterWorkspacePhaseScheduling,
+				}).ClusterWorkspace,
+			),
+			expectedObj: newWorkspace("root:org:ws:test").withType("root:org:foo").withStatus(tenancyv1alpha1.ClusterWorkspaceStatus{
+				Phase:      tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+				Finalizers: []tenancyv1alpha1.ClusterWorkspaceFinalizer{"root:org:other", "root:org:foo"},
+				Location:   tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+				BaseURL:    "https://kcp.bigcorp.com/clusters/org:test",
+			}).ClusterWorkspace,
+		},
 		{
 			name: "does not add initializer during transition to initializing when type has none",
 			types: []*tenancyv1alpha1.ClusterWorkspaceType{
@@ -181,6 +205,54 @@ func TestAdmit(t *testing.T) {
 				BaseURL:      "https://kcp.bigcorp.com/clusters/org:test",
 			}).ClusterWorkspace,
 		},
+		{
+			name: "adds system:clone initializer when templates are on spec",
+			types: []*tenancyv1alpha1.ClusterWorkspaceType{
+				newType("root:org:foo").withTemplates().ClusterWorkspaceType,
+			},
+			clusterName: logicalcluster.New("root:org:ws"),
+			a: updateAttr(
+				newWorkspace("root:org:ws:test").withType("root:org:foo").withStatus(tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase:    tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+					Location: tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+					BaseURL:  "https://kcp.bigcorp.com/clusters/org:test",
+				}).ClusterWorkspace,
+				newWorkspace("root:org:ws:test").withType("root:org:foo").withStatus(tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase:        tenancyv1alpha1.ClusterWorkspacePhaseScheduling,
+					Initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{},
+				}).ClusterWorkspace,
+			),
+			expectedObj: newWorkspace("root:org:ws:test").withType("root:org:foo").withStatus(tenancyv1alpha1.ClusterWorkspaceStatus{
+				Phase:        tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+				Location:     tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+				Initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{tenancyv1alpha1.ClusterWorkspaceCloneInitializer},
+				BaseURL:      "https://kcp.bigcorp.com/clusters/org:test",
+			}).ClusterWorkspace,
+		},
+		{
+			name: "adds system:clone initializer when cloning a workspace",
+			types: []*tenancyv1alpha1.ClusterWorkspaceType{
+				newType("root:org:foo").ClusterWorkspaceType,
+			},
+			clusterName: logicalcluster.New("root:org:ws"),
+			a: updateAttr(
+				newWorkspace("root:org:ws:test").withType("root:org:foo").withClone("root:org:template").withStatus(tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase:    tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+					Location: tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+					BaseURL:  "https://kcp.bigcorp.com/clusters/org:test",
+				}).ClusterWorkspace,
+				newWorkspace("root:org:ws:test").withType("root:org:foo").withClone("root:org:template").withStatus(tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase:        tenancyv1alpha1.ClusterWorkspacePhaseScheduling,
+					Initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{},
+				}).ClusterWorkspace,
+			),
+			expectedObj: newWorkspace("root:org:ws:test").withType("root:org:foo").withClone("root:org:template").withStatus(tenancyv1alpha1.ClusterWorkspaceStatus{
+				Phase:        tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+				Location:     tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+				Initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{tenancyv1alpha1.ClusterWorkspaceCloneInitializer},
+				BaseURL:      "https://kcp.bigcorp.com/clusters/org:test",
+			}).ClusterWorkspace,
+		},
 		{
 			name:        "ignores different resources",
 			clusterName: logicalcluster.New("root:org:ws"),
@@ -340,6 +412,7 @@ func TestValidate(t *testing.T) {
 
 		authzDecision authorizer.Decision
 		authzError    error
+		deniedVerbs   []string
 
 		wantErr bool
 	}{
@@ -526,6 +599,104 @@ func TestValidate(t *testing.T) {
 			authzError: errors.New("authorizer error"),
 			wantErr:    true,
 		},
+		{
+			name: "passes create with clone source if admin of the source",
+			path: logicalcluster.New("root:org:ws"),
+			workspaces: []*tenancyv1alpha1.ClusterWorkspace{
+				newWorkspace("root:org:ws").withType("root:org:parent").ClusterWorkspace,
+			},
+			types: []*tenancyv1alpha1.ClusterWorkspaceType{
+				newType("root:org:parent").allowingChild("root:org:foo").ClusterWorkspaceType,
+				newType("root:org:foo").ClusterWorkspaceType,
+			},
+			attr:          createAttr(newWorkspace("root:org:ws:test").withType("root:org:foo").withClone("root:org:template").ClusterWorkspace),
+			authzDecision: authorizer.DecisionAllow,
+		},
+		{
+			name: "fails create with clone source if not admin of the source",
+			path: logicalcluster.New("root:org:ws"),
+			workspaces: []*tenancyv1alpha1.ClusterWorkspace{
+				newWorkspace("root:org:ws").withType("root:org:parent").ClusterWorkspace,
+			},
+			types: []*tenancyv1alpha1.ClusterWorkspaceType{
+				newType("root:org:parent").allowingChild("root:org:foo").ClusterWorkspaceType,
+				newType("root:org:foo").ClusterWorkspaceType,
+			},
+			attr:          createAttr(newWorkspace("root:org:ws:test").withType("root:org:foo").withClone("root:org:template").ClusterWorkspace),
+			authzDecision: authorizer.DecisionAllow,
+			deniedVerbs:   []string{"admin"},
+			wantErr:       true,
+		},
+		{
+			name: "fails create with root as clone source",
+			path: logicalcluster.New("root:org:ws"),
+			workspaces: []*tenancyv1alpha1.ClusterWorkspace{
+				newWorkspace("root:org:ws").withType("root:org:parent").ClusterWorkspace,
+			},
+			types: []*tenancyv1alpha1.ClusterWorkspaceType{
+				newType("root:org:parent").allowingChild("root:org:foo").ClusterWorkspaceType,
+				newType("root:org:foo").ClusterWorkspaceType,
+			},
+			attr:          createAttr(newWorkspace("root:org:ws:test").withType("root:org:foo").withClone("root").ClusterWorkspace),
+			authzDecision: authorizer.DecisionAllow,
+			wantErr:       true,
+		},
+		{
+			name: "passes move into a parent allowing the type",
+			path: logicalcluster.New("root:org:ws"),
+			workspaces: []*tenancyv1alpha1.ClusterWorkspace{
+				newWorkspace("root:org:ws").withType("root:org:parent").ClusterWorkspace,
+				newWorkspace("root:org:other").withType("root:org:parent").ClusterWorkspace,
+			},
+			types: []*tenancyv1alpha1.ClusterWorkspaceType{
+				newType("root:org:parent").allowingChild("root:org:foo").ClusterWorkspaceType,
+				newType("root:org:foo").ClusterWorkspaceType,
+			},
+			attr: updateAttr(
+				newWorkspace("root:org:ws:test").withType("root:org:foo").withMoveTo("root:org:other:test").ClusterWorkspace,
+				newWorkspace("root:org:ws:test").withType("root:org:foo").ClusterWorkspace,
+			),
+			authzDecision: authorizer.DecisionAllow,
+		},
+		{
+			name: "fails move without create permission in the new parent",
+			path: logicalcluster.New("root:org:ws"),
+			workspaces: []*tenancyv1alpha1.ClusterWorkspace{
+				newWorkspace("root:org:ws").withType("root:org:parent").ClusterWorkspace,
+				newWorkspace("root:org:other").withType("root:org:parent").ClusterWorkspace,
+			},
+			types: []*tenancyv1alpha1.ClusterWorkspaceType{
+				newType("root:org:parent").allowingChild("root:org:foo").ClusterWorkspaceType,
+				newType("root:org:foo").ClusterWorkspaceType,
+			},
+			attr: updateAttr(
+				newWorkspace("root:org:ws:test").withType("root:org:foo").withMoveTo("root:org:other:test").ClusterWorkspace,
+				newWorkspace("root:org:ws:test").withType("root:org:foo").ClusterWorkspace,
+			),
+			authzDecision: authorizer.DecisionAllow,
+			deniedVerbs:   []string{"create"},
+			wantErr:       true,
+		},
+		{
+			name: "fails move into a parent not allowing the type",
+			path: logicalcluster.New("root:org:ws"),
+			workspaces: []*tenancyv1alpha1.ClusterWorkspace{
+				newWorkspace("root:org:ws").withType("root:org:parent").ClusterWorkspace,
+				newWorkspace("root:org:other").withType("root:org:restricted").ClusterWorkspace,
+			},
+			types: []*tenancyv1alpha1.ClusterWorkspaceType{
+				newType("root:org:parent").allowingChild("root:org:foo").ClusterWorkspaceType,
+				newType("root:org:restricted").allowingChild("root:org:bar").ClusterWorkspaceType,
+				newType("root:org:foo").ClusterWorkspaceType,
+				newType("root:org:bar").ClusterWorkspaceType,
+			},
+			attr: updateAttr(
+				newWorkspace("root:org:ws:test").withType("root:org:foo").withMoveTo("root:org:other:test").ClusterWorkspace,
+				newWorkspace("root:org:ws:test").withType("root:org:foo").ClusterWorkspace,
+			),
+			authzDecision: authorizer.DecisionAllow,
+			wantErr:       true,
+		},
 		{
 			name: "validates initializers on phase transition",
 			path: logicalcluster.New("root:org:ws"),
@@ -547,6 +718,27 @@ func TestValidate(t *testing.T) {
 			),
 			wantErr: true,
 		},
+		{
+			name: "validates finalizers on phase transition",
+			path: logicalcluster.New("root:org:ws"),
+			workspaces: []*tenancyv1alpha1.ClusterWorkspace{
+				newWorkspace("root:org:ws").withType("root:org:parent").ClusterWorkspace,
+			},
+			types: []*tenancyv1alpha1.ClusterWorkspaceType{
+				newType("root:org:parent").allowingChild("root:org:foo").ClusterWorkspaceType,
+				newType("root:org:foo").withFinalizer().ClusterWorkspaceType,
+			},
+			attr: updateAttr(
+				newWorkspace("root:org:ws:test").withType("root:org:foo").withStatus(tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase:      tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+					Finalizers: []tenancyv1alpha1.ClusterWorkspaceFinalizer{}, // root:org:foo missing
+				}).ClusterWorkspace,
+				newWorkspace("root:org:ws:test").withType("root:org:foo").withStatus(tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase: tenancyv1alpha1.ClusterWorkspacePhaseScheduling,
+				}).ClusterWorkspace,
+			),
+			wantErr: true,
+		},
 		{
 			name: "passes with all initializers or more on phase transition",
 			path: logicalcluster.New("root:org:ws"),
@@ -618,8 +810,9 @@ func TestValidate(t *testing.T) {
 				workspaceLister: fakeClusterWorkspaceClusterLister(allWorkspaces),
 				createAuthorizer: func(clusterName logicalcluster.Name, client kcpkubernetesclientset.ClusterInterface) (authorizer.Authorizer, error) {
 					return &fakeAuthorizer{
-						tt.authzDecision,
-						tt.authzError,
+						authorized:  tt.authzDecision,
+						err:         tt.authzError,
+						deniedVerbs: sets.NewString(tt.deniedVerbs...),
 					}, nil
 				},
 				transitiveTypeResolver: NewTransitiveTypeResolver(func(cluster logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspaceType, error) {
@@ -693,11 +886,15 @@ func (l fakeClusterWorkspaceLister) Get(name string) (*tenancyv1alpha1.ClusterWo
 }
 
 type fakeAuthorizer struct {
-	authorized authorizer.Decision
-	err        error
+	authorized  authorizer.Decision
+	err         error
+	deniedVerbs sets.String
 }
 
 func (a *fakeAuthorizer) Authorize(ctx context.Context, attr authorizer.Attributes) (authorized authorizer.Decision, reason string, err error) {
+	if a.deniedVerbs.Has(attr.GetVerb()) {
+		return authorizer.DecisionNoOpinion, "reason", nil
+	}
 	return a.authorized, "reason", a.err
 }
 
@@ -992,6 +1189,11 @@ func (b builder) withInitializer() builder {
 	return b
 }
 
+func (b builder) withFinalizer() builder {
+	b.ClusterWorkspaceType.Spec.Finalizer = true
+	return b
+}
+
 func (b builder) withAdditionalLabel(labels map[string]string) builder {
 	b.ClusterWorkspaceType.Spec.AdditionalWorkspaceLabels = labels
 	return b
@@ -1007,6 +1209,16 @@ func (b builder) withAPIBindings() builder {
 	return b
 }
 
+func (b builder) withTemplates() builder {
+	b.ClusterWorkspaceType.Spec.Templates = []tenancyv1alpha1.ClusterWorkspaceTemplateReference{
+		{
+			Namespace: "default",
+			Name:      "manifests",
+		},
+	}
+	return b
+}
+
 type wsBuilder struct {
 	*tenancyv1alpha1.ClusterWorkspace
 }
@@ -1041,3 +1253,13 @@ func (b wsBuilder) withLabels(labels map[string]string) wsBuilder {
 	b.Labels = labels
 	return b
 }
+
+func (b wsBuilder) withClone(path string) wsBuilder {
+	b.Spec.Clone = &tenancyv1alpha1.ClusterWorkspaceCloneSource{Path: path}
+	return b
+}
+
+func (b wsBuilder) withMoveTo(path string) wsBuilder {
+	b.Spec.MoveTo = &tenancyv1alpha1.ClusterWorkspaceMoveTarget{Path: path}
+	return b
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helpers
+
+import (
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	tenancyv1alpha1listers "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
+)
+
+// GetClusterWorkspace returns the ClusterWorkspace of the given logical cluster. It is stored
+// in the parent workspace, which might live on another shard. Hence, it is looked up in the
+// cache server if it is not found locally. The cached lister can be nil.
+func GetClusterWorkspace(local, cached tenancyv1alpha1listers.ClusterWorkspaceClusterLister, clusterName logicalcluster.Name) (*tenancyv1alpha1.ClusterWorkspace, error) {
+	parent, name := clusterName.Split()
+	ws, err := local.Cluster(parent).Get(name)
+	if apierrors.IsNotFound(err) && cached != nil {
+		return cached.Cluster(parent).Get(name)
+	}
+	return ws, err
+}
+
+// ListChildClusterWorkspaces returns the child ClusterWorkspaces of the given logical cluster,
+// both from the local shard and from the cache server. The cached lister can be nil.
+func ListChildClusterWorkspaces(local, cached tenancyv1alpha1listers.ClusterWorkspaceClusterLister, clusterName logicalcluster.Name) ([]*tenancyv1alpha1.ClusterWorkspace, error) {
+	children, err := local.Cluster(clusterName).List(labels.Everything())
+	if err != nil || cached == nil {
+		return children, err
+	}
+	cachedChildren, err := cached.Cluster(clusterName).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(children))
+	for _, child := range children {
+		seen[child.Name] = true
+	}
+	for _, child := range cachedChildren {
+		if !seen[child.Name] {
+			seen[child.Name] = true
+			children = append(children, child)
+		}
+	}
+	return children, nil
+}
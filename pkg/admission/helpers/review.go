@@ -0,0 +1,30 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helpers
+
+import (
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// IsReview returns true for the review resources of the authentication.k8s.io and
+// authorization.k8s.io groups, like TokenReviews and SubjectAccessReviews. They are
+// created to ask the server a question and are never stored.
+func IsReview(gr schema.GroupResource) bool {
+	return gr.Group == authenticationv1.GroupName || gr.Group == authorizationv1.GroupName
+}
@@ -17,8 +17,11 @@ limitations under the License.
 package initializers
 
 import (
+	"time"
+
 	kcpkubernetesclientset "github.com/kcp-dev/client-go/kubernetes"
 
+	kcpapiextensionsinformers "k8s.io/apiextensions-apiserver/pkg/client/kcp/informers/externalversions"
 	"k8s.io/apiserver/pkg/admission"
 	"k8s.io/apiserver/pkg/admission/initializer"
 	quota "k8s.io/apiserver/pkg/quota/v1"
@@ -47,6 +50,50 @@ func (i *kcpInformersInitializer) Initialize(plugin admission.Interface) {
 	}
 }
 
+// NewKcpCacheInformersInitializer returns an admission plugin initializer that injects
+// the kcp shared informer factory of the cache server into admission plugins. The
+// factory is nil if the cache server is disabled.
+func NewKcpCacheInformersInitializer(
+	cacheKcpInformers kcpinformers.SharedInformerFactory,
+) *kcpCacheInformersInitializer {
+	return &kcpCacheInformersInitializer{
+		cacheKcpInformers: cacheKcpInformers,
+	}
+}
+
+type kcpCacheInformersInitializer struct {
+	cacheKcpInformers kcpinformers.SharedInformerFactory
+}
+
+func (i *kcpCacheInformersInitializer) Initialize(plugin admission.Interface) {
+	if i.cacheKcpInformers == nil {
+		return
+	}
+	if wants, ok := plugin.(WantsKcpCacheInformers); ok {
+		wants.SetKcpCacheInformers(i.cacheKcpInformers)
+	}
+}
+
+// NewApiExtensionsInformersInitializer returns an admission plugin initializer that injects
+// the apiextensions shared informer factory into admission plugins.
+func NewApiExtensionsInformersInitializer(
+	apiExtensionsInformers kcpapiextensionsinformers.SharedInformerFactory,
+) *apiExtensionsInformersInitializer {
+	return &apiExtensionsInformersInitializer{
+		apiExtensionsInformers: apiExtensionsInformers,
+	}
+}
+
+type apiExtensionsInformersInitializer struct {
+	apiExtensionsInformers kcpapiextensionsinformers.SharedInformerFactory
+}
+
+func (i *apiExtensionsInformersInitializer) Initialize(plugin admission.Interface) {
+	if wants, ok := plugin.(WantsApiExtensionsInformers); ok {
+		wants.SetApiExtensionsInformers(i.apiExtensionsInformers)
+	}
+}
+
 // NewKubeClusterClientInitializer returns an admission plugin initializer that injects
 // a kube cluster client into admission plugins.
 func NewKubeClusterClientInitializer(
@@ -142,3 +189,21 @@ func (i *serverShutdownChannelInitializer) Initialize(plugin admission.Interface
 		wants.SetServerShutdownChannel(i.ch)
 	}
 }
+
+// NewDeletionRetentionPeriodInitializer returns an admission plugin initializer that injects the
+// server-wide default period deleted workspaces are retained into admission plugins.
+func NewDeletionRetentionPeriodInitializer(period time.Duration) *deletionRetentionPeriodInitializer {
+	return &deletionRetentionPeriodInitializer{
+		period: period,
+	}
+}
+
+type deletionRetentionPeriodInitializer struct {
+	period time.Duration
+}
+
+func (i *deletionRetentionPeriodInitializer) Initialize(plugin admission.Interface) {
+	if wants, ok := plugin.(WantsDeletionRetentionPeriod); ok {
+		wants.SetDeletionRetentionPeriod(i.period)
+	}
+}
@@ -17,8 +17,12 @@ limitations under the License.
 package initializers
 
 import (
+	"time"
+
 	kcpkubernetesclientset "github.com/kcp-dev/client-go/kubernetes"
 
+	kcpapiextensionsinformers "k8s.io/apiextensions-apiserver/pkg/client/kcp/informers/externalversions"
+
 	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/cluster"
 	kcpinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions"
 )
@@ -29,6 +33,19 @@ type WantsKcpInformers interface {
 	SetKcpInformers(kcpinformers.SharedInformerFactory)
 }
 
+// WantsKcpCacheInformers interface should be implemented by admission plugins
+// that want to have the kcp informer factory of the cache server injected. It is
+// not called if the cache server is disabled.
+type WantsKcpCacheInformers interface {
+	SetKcpCacheInformers(kcpinformers.SharedInformerFactory)
+}
+
+// WantsApiExtensionsInformers interface should be implemented by admission plugins
+// that want to have an apiextensions informer factory injected.
+type WantsApiExtensionsInformers interface {
+	SetApiExtensionsInformers(kcpapiextensionsinformers.SharedInformerFactory)
+}
+
 // WantsKubeClusterClient interface should be implemented by admission plugins
 // that want to have a kube cluster client injected.
 type WantsKubeClusterClient interface {
@@ -53,3 +70,9 @@ type WantsDeepSARClient interface {
 type WantsServerShutdownChannel interface {
 	SetServerShutdownChannel(<-chan struct{})
 }
+
+// WantsDeletionRetentionPeriod interface should be implemented by admission plugins
+// that want to know the server-wide default period deleted workspaces are retained.
+type WantsDeletionRetentionPeriod interface {
+	SetDeletionRetentionPeriod(time.Duration)
+}
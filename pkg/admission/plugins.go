@@ -54,16 +54,20 @@ import (
 	kcpmutatingwebhook "github.com/kcp-dev/kcp/pkg/admission/mutatingwebhook"
 	workspacenamespacelifecycle "github.com/kcp-dev/kcp/pkg/admission/namespacelifecycle"
 	"github.com/kcp-dev/kcp/pkg/admission/permissionclaims"
+	"github.com/kcp-dev/kcp/pkg/admission/readonlyworkspace"
 	"github.com/kcp-dev/kcp/pkg/admission/reservedcrdannotations"
 	"github.com/kcp-dev/kcp/pkg/admission/reservedcrdgroups"
 	"github.com/kcp-dev/kcp/pkg/admission/reservedmetadata"
 	"github.com/kcp-dev/kcp/pkg/admission/reservednames"
 	kcpvalidatingwebhook "github.com/kcp-dev/kcp/pkg/admission/validatingwebhook"
+	"github.com/kcp-dev/kcp/pkg/admission/workspacequota"
 )
 
 // AllOrderedPlugins is the list of all the plugins in order.
 var AllOrderedPlugins = beforeWebhooks(kubeapiserveroptions.AllOrderedPlugins,
 	workspacenamespacelifecycle.PluginName,
+	readonlyworkspace.PluginName,
+	workspacequota.PluginName,
 	apiresourceschema.PluginName,
 	clusterworkspace.PluginName,
 	clusterworkspacefinalizer.PluginName,
@@ -100,6 +104,8 @@ func beforeWebhooks(recommended []string, plugins ...string) []string {
 // The order of registration is irrelevant, see AllOrderedPlugins for execution order.
 func RegisterAllKcpAdmissionPlugins(plugins *admission.Plugins) {
 	kubeapiserveroptions.RegisterAllAdmissionPlugins(plugins)
+	readonlyworkspace.Register(plugins)
+	workspacequota.Register(plugins)
 	clusterworkspace.Register(plugins)
 	clusterworkspacefinalizer.Register(plugins)
 	clusterworkspaceshard.Register(plugins)
@@ -130,6 +136,8 @@ var defaultOnPluginsInKcp = sets.NewString(
 	certsubjectrestriction.PluginName,      // CertificateSubjectRestriction
 
 	// KCP
+	readonlyworkspace.PluginName,
+	workspacequota.PluginName,
 	clusterworkspace.PluginName,
 	clusterworkspacefinalizer.PluginName,
 	clusterworkspaceshard.PluginName,
@@ -0,0 +1,126 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package readonlyworkspace
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/admission"
+	kuser "k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+
+	"github.com/kcp-dev/kcp/pkg/admission/helpers"
+	kcpinitializers "github.com/kcp-dev/kcp/pkg/admission/initializers"
+	kcpinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions"
+	tenancyv1alpha1listers "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
+)
+
+const (
+	PluginName = "tenancy.kcp.dev/ReadOnlyClusterWorkspace"
+)
+
+func Register(plugins *admission.Plugins) {
+	plugins.Register(PluginName,
+		func(_ io.Reader) (admission.Interface, error) {
+			return &readOnlyWorkspace{
+				Handler: admission.NewHandler(admission.Create, admission.Update, admission.Delete),
+			}, nil
+		})
+}
+
+// readOnlyWorkspace rejects mutating requests to workspaces whose ClusterWorkspace has
+// spec.readOnly set, or which are migrated to another shard or moved. The ClusterWorkspace
+// is read from the cache server if its parent is stored on another shard.
+//
+// Requests of system:masters, e.g. of the controller deleting the content of a deleted
+// workspace, reviews that are never stored, and all requests to workspaces being deleted
+// are allowed.
+type readOnlyWorkspace struct {
+	*admission.Handler
+
+	clusterWorkspaceLister        tenancyv1alpha1listers.ClusterWorkspaceClusterLister
+	cachedClusterWorkspaceLister  tenancyv1alpha1listers.ClusterWorkspaceClusterLister
+	cachedClusterWorkspacesSynced func() bool
+}
+
+// Ensure that the required admission interfaces are implemented.
+var _ = admission.ValidationInterface(&readOnlyWorkspace{})
+var _ = admission.InitializationValidator(&readOnlyWorkspace{})
+var _ = kcpinitializers.WantsKcpCacheInformers(&readOnlyWorkspace{})
+
+func (o *readOnlyWorkspace) SetKcpInformers(informers kcpinformers.SharedInformerFactory) {
+	o.SetReadyFunc(informers.Tenancy().V1alpha1().ClusterWorkspaces().Informer().HasSynced)
+	o.clusterWorkspaceLister = informers.Tenancy().V1alpha1().ClusterWorkspaces().Lister()
+}
+
+func (o *readOnlyWorkspace) SetKcpCacheInformers(informers kcpinformers.SharedInformerFactory) {
+	o.cachedClusterWorkspacesSynced = informers.Tenancy().V1alpha1().ClusterWorkspaces().Informer().HasSynced
+	o.cachedClusterWorkspaceLister = informers.Tenancy().V1alpha1().ClusterWorkspaces().Lister()
+}
+
+func (o *readOnlyWorkspace) ValidateInitialization() error {
+	if o.clusterWorkspaceLister == nil {
+		return fmt.Errorf(PluginName + " plugin needs a ClusterWorkspace lister")
+	}
+	return nil
+}
+
+// Validate rejects all mutating requests to read-only workspaces.
+func (o *readOnlyWorkspace) Validate(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) error {
+	clusterName, err := request.ClusterNameFrom(ctx)
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+	if parent, _ := clusterName.Split(); parent.Empty() {
+		return nil
+	}
+	if helpers.IsReview(a.GetResource().GroupResource()) {
+		return nil
+	}
+	if sets.NewString(a.GetUserInfo().GetGroups()...).Has(kuser.SystemPrivilegedGroup) {
+		return nil
+	}
+	if o.cachedClusterWorkspacesSynced != nil && !o.cachedClusterWorkspacesSynced() {
+		return fmt.Errorf("not yet ready to handle request")
+	}
+
+	ws, err := helpers.GetClusterWorkspace(o.clusterWorkspaceLister, o.cachedClusterWorkspaceLister, clusterName)
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+	if ws.DeletionTimestamp != nil {
+		return nil
+	}
+
+	// fail closed during migrations and moves, before spec.readOnly is observed and until they are finished
+	if ws.Status.Location.Migration != nil {
+		return admission.NewForbidden(a, fmt.Errorf("workspace %s is read-only while it is migrated to another shard", clusterName))
+	}
+	if ws.Status.Move != nil {
+		return admission.NewForbidden(a, fmt.Errorf("workspace %s is read-only while it is moved", clusterName))
+	}
+	if ws.Spec.ReadOnly {
+		return admission.NewForbidden(a, fmt.Errorf("workspace %s is read-only", clusterName))
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package readonlyworkspace
+
+import (
+	"context"
+	"testing"
+
+	kcpcache "github.com/kcp-dev/apimachinery/pkg/cache"
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/tools/cache"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	tenancyv1alpha1listers "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
+)
+
+func TestValidate(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		clusterName    string
+		initialObjects []runtime.Object
+		cachedObjects  []runtime.Object
+		attr           admission.Attributes
+		wantErr        string
+	}{
+		{
+			name:        "writes to a read-only workspace are forbidden",
+			clusterName: "root:org:ws",
+			initialObjects: []runtime.Object{
+				createClusterWorkspace("root:org", "ws", true, nil),
+			},
+			wantErr: `configmaps "test" is forbidden: workspace root:org:ws is read-only`,
+		},
+		{
+			name:        "writes to a migrating workspace are forbidden",
+			clusterName: "root:org:ws",
+			initialObjects: []runtime.Object{
+				createClusterWorkspace("root:org", "ws", true, &tenancyv1alpha1.ClusterWorkspaceMigration{Phase: tenancyv1alpha1.ClusterWorkspaceMigrationPhaseFreezing, Source: "alpha"}),
+			},
+			wantErr: `configmaps "test" is forbidden: workspace root:org:ws is read-only while it is migrated to another shard`,
+		},
+		{
+			name:        "writes to a migrating workspace are forbidden before it is read-only",
+			clusterName: "root:org:ws",
+			initialObjects: []runtime.Object{
+				createClusterWorkspace("root:org", "ws", false, &tenancyv1alpha1.ClusterWorkspaceMigration{Phase: tenancyv1alpha1.ClusterWorkspaceMigrationPhaseFreezing, Source: "alpha"}),
+			},
+			wantErr: `configmaps "test" is forbidden: workspace root:org:ws is read-only while it is migrated to another shard`,
+		},
+		{
+			name:        "writes to a read-only workspace whose parent is on another shard are forbidden",
+			clusterName: "root:org:ws",
+			cachedObjects: []runtime.Object{
+				createClusterWorkspace("root:org", "ws", true, nil),
+			},
+			wantErr: `configmaps "test" is forbidden: workspace root:org:ws is read-only`,
+		},
+		{
+			name:        "writes to a migrating workspace whose parent is on another shard are forbidden",
+			clusterName: "root:org:ws",
+			cachedObjects: []runtime.Object{
+				createClusterWorkspace("root:org", "ws", false, &tenancyv1alpha1.ClusterWorkspaceMigration{Phase: tenancyv1alpha1.ClusterWorkspaceMigrationPhaseFreezing, Source: "alpha"}),
+			},
+			wantErr: `configmaps "test" is forbidden: workspace root:org:ws is read-only while it is migrated to another shard`,
+		},
+		{
+			name:        "writes to a writable workspace are allowed",
+			clusterName: "root:org:ws",
+			initialObjects: []runtime.Object{
+				createClusterWorkspace("root:org", "ws", false, nil),
+				createClusterWorkspace("root:org", "other", true, nil),
+			},
+		},
+		{
+			name:        "deletes in a read-only workspace being deleted are allowed",
+			clusterName: "root:org:ws",
+			initialObjects: []runtime.Object{
+				withDeletionTimestamp(createClusterWorkspace("root:org", "ws", true, nil)),
+			},
+			attr: deleteAttr(&user.DefaultInfo{}),
+		},
+		{
+			name:        "deletes in a migrating workspace being deleted are allowed",
+			clusterName: "root:org:ws",
+			cachedObjects: []runtime.Object{
+				withDeletionTimestamp(createClusterWorkspace("root:org", "ws", false, &tenancyv1alpha1.ClusterWorkspaceMigration{Phase: tenancyv1alpha1.ClusterWorkspaceMigrationPhaseFreezing, Source: "alpha"})),
+			},
+			attr: deleteAttr(&user.DefaultInfo{}),
+		},
+		{
+			name:        "deletes in a read-only workspace are forbidden",
+			clusterName: "root:org:ws",
+			initialObjects: []runtime.Object{
+				createClusterWorkspace("root:org", "ws", true, nil),
+			},
+			attr:    deleteAttr(&user.DefaultInfo{}),
+			wantErr: `configmaps "test" is forbidden: workspace root:org:ws is read-only`,
+		},
+		{
+			name:        "deletes of system:masters in a read-only workspace are allowed",
+			clusterName: "root:org:ws",
+			initialObjects: []runtime.Object{
+				createClusterWorkspace("root:org", "ws", true, nil),
+			},
+			attr: deleteAttr(&user.DefaultInfo{Name: "system:apiserver", Groups: []string{user.SystemPrivilegedGroup}}),
+		},
+		{
+			name:        "subject access reviews in a read-only workspace are allowed",
+			clusterName: "root:org:ws",
+			initialObjects: []runtime.Object{
+				createClusterWorkspace("root:org", "ws", true, nil),
+			},
+			attr: reviewAttr(authorizationv1.SchemeGroupVersion.WithResource("subjectaccessreviews"), "SubjectAccessReview"),
+		},
+		{
+			name:        "token reviews in a read-only workspace are allowed",
+			clusterName: "root:org:ws",
+			initialObjects: []runtime.Object{
+				createClusterWorkspace("root:org", "ws", true, nil),
+			},
+			attr: reviewAttr(authenticationv1.SchemeGroupVersion.WithResource("tokenreviews"), "TokenReview"),
+		},
+		{
+			name:        "writes to a workspace without ClusterWorkspace are allowed",
+			clusterName: "system:admin",
+		},
+		{
+			name:        "writes to root are allowed",
+			clusterName: "root",
+		},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(kcpcache.MetaClusterNamespaceKeyFunc, cache.Indexers{kcpcache.ClusterIndexName: kcpcache.ClusterIndexFunc})
+			for _, obj := range scenario.initialObjects {
+				if err := indexer.Add(obj); err != nil {
+					t.Error(err)
+				}
+			}
+			cachedIndexer := cache.NewIndexer(kcpcache.MetaClusterNamespaceKeyFunc, cache.Indexers{kcpcache.ClusterIndexName: kcpcache.ClusterIndexFunc})
+			for _, obj := range scenario.cachedObjects {
+				if err := cachedIndexer.Add(obj); err != nil {
+					t.Error(err)
+				}
+			}
+
+			o := &readOnlyWorkspace{
+				Handler:                       admission.NewHandler(admission.Create, admission.Update, admission.Delete),
+				clusterWorkspaceLister:        tenancyv1alpha1listers.NewClusterWorkspaceClusterLister(indexer),
+				cachedClusterWorkspaceLister:  tenancyv1alpha1listers.NewClusterWorkspaceClusterLister(cachedIndexer),
+				cachedClusterWorkspacesSynced: func() bool { return true },
+			}
+			ctx := request.WithCluster(context.Background(), request.Cluster{Name: logicalcluster.New(scenario.clusterName)})
+			attr := scenario.attr
+			if attr == nil {
+				attr = createAttr()
+			}
+			err := o.Validate(ctx, attr, nil)
+			if scenario.wantErr == "" && err != nil {
+				t.Fatalf("Validate() unexpected error = %v", err)
+			} else if scenario.wantErr != "" && (err == nil || err.Error() != scenario.wantErr) {
+				t.Fatalf("Validate() error = %v, want %q", err, scenario.wantErr)
+			}
+		})
+	}
+}
+
+func createAttr() admission.Attributes {
+	return admission.NewAttributesRecord(
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}},
+		nil,
+		corev1.SchemeGroupVersion.WithKind("ConfigMap"),
+		"default",
+		"test",
+		corev1.SchemeGroupVersion.WithResource("configmaps"),
+		"",
+		admission.Create,
+		&metav1.CreateOptions{},
+		false,
+		&user.DefaultInfo{},
+	)
+}
+
+func deleteAttr(info user.Info) admission.Attributes {
+	return admission.NewAttributesRecord(
+		nil,
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}},
+		corev1.SchemeGroupVersion.WithKind("ConfigMap"),
+		"default",
+		"test",
+		corev1.SchemeGroupVersion.WithResource("configmaps"),
+		"",
+		admission.Delete,
+		&metav1.DeleteOptions{},
+		false,
+		info,
+	)
+}
+
+func reviewAttr(gvr schema.GroupVersionResource, kind string) admission.Attributes {
+	return admission.NewAttributesRecord(
+		nil,
+		nil,
+		gvr.GroupVersion().WithKind(kind),
+		"",
+		"",
+		gvr,
+		"",
+		admission.Create,
+		&metav1.CreateOptions{},
+		false,
+		&user.DefaultInfo{},
+	)
+}
+
+func withDeletionTimestamp(ws *tenancyv1alpha1.ClusterWorkspace) *tenancyv1alpha1.ClusterWorkspace {
+	now := metav1.Now()
+	ws.DeletionTimestamp = &now
+	return ws
+}
+
+func createClusterWorkspace(clusterName, name string, readOnly bool, migration *tenancyv1alpha1.ClusterWorkspaceMigration) *tenancyv1alpha1.ClusterWorkspace {
+	return &tenancyv1alpha1.ClusterWorkspace{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				logicalcluster.AnnotationKey: clusterName,
+			},
+			Name: name,
+		},
+		Spec: tenancyv1alpha1.ClusterWorkspaceSpec{ReadOnly: readOnly},
+		Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+			Location: tenancyv1alpha1.ClusterWorkspaceLocation{Migration: migration},
+		},
+	}
+}
@@ -33,6 +33,7 @@ import (
 	"k8s.io/klog/v2"
 
 	"github.com/kcp-dev/kcp/pkg/admission/initializers"
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
 	kcpinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions"
 	apisv1alpha1listers "github.com/kcp-dev/kcp/pkg/client/listers/apis/v1alpha1"
 )
@@ -133,14 +134,14 @@ func (p *WebhookDispatcher) getAPIBindingWorkspace(attr admission.Attributes, cl
 	}
 	for _, apiBinding := range objs {
 		for _, br := range apiBinding.Status.BoundResources {
-			// this can never happen by OpenAPI validation
-			if apiBinding.Spec.Reference.Workspace == nil {
-				// this will never happen today. But as soon as we add other reference types (like exports), this log output will remind out of necessary work here.
-				klog.Errorf("APIBinding %s has no referenced workspace", clusterName, apiBinding.Name)
+			apiExportClusterName, _, ok := apisv1alpha1.ReferencedAPIExport(apiBinding)
+			if !ok {
+				// this can never happen for bound APIBindings due to validation and admission
+				klog.Errorf("APIBinding %s|%s has no resolved APIExport reference", clusterName, apiBinding.Name)
 				continue
 			}
 			if br.Group == attr.GetResource().Group && br.Resource == attr.GetResource().Resource {
-				return logicalcluster.New(apiBinding.Spec.Reference.Workspace.Path), true, nil
+				return apiExportClusterName, true, nil
 			}
 		}
 	}
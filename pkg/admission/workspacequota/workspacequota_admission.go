@@ -0,0 +1,153 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacequota
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+
+	"github.com/kcp-dev/kcp/pkg/admission/helpers"
+	kcpinitializers "github.com/kcp-dev/kcp/pkg/admission/initializers"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	kcpinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions"
+	tenancyv1alpha1listers "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
+)
+
+const (
+	PluginName = "tenancy.kcp.dev/WorkspaceQuota"
+)
+
+func Register(plugins *admission.Plugins) {
+	plugins.Register(PluginName,
+		func(_ io.Reader) (admission.Interface, error) {
+			return &workspaceQuota{
+				Handler: admission.NewHandler(admission.Create),
+			}, nil
+		})
+}
+
+// workspaceQuota rejects the creation of objects in workspaces whose quota in the status of
+// their ClusterWorkspace is used up. The number of child workspaces is counted on every
+// request, while the objects and storage are the usage last measured by the ClusterWorkspace
+// controller. Hence, the latter limits can be exceeded for a short time. The ClusterWorkspaces
+// are read from the cache server if the parent is stored on another shard.
+type workspaceQuota struct {
+	*admission.Handler
+
+	clusterWorkspaceLister        tenancyv1alpha1listers.ClusterWorkspaceClusterLister
+	cachedClusterWorkspaceLister  tenancyv1alpha1listers.ClusterWorkspaceClusterLister
+	cachedClusterWorkspacesSynced func() bool
+}
+
+// Ensure that the required admission interfaces are implemented.
+var _ = admission.ValidationInterface(&workspaceQuota{})
+var _ = admission.InitializationValidator(&workspaceQuota{})
+var _ = kcpinitializers.WantsKcpCacheInformers(&workspaceQuota{})
+
+func (o *workspaceQuota) SetKcpInformers(informers kcpinformers.SharedInformerFactory) {
+	o.SetReadyFunc(informers.Tenancy().V1alpha1().ClusterWorkspaces().Informer().HasSynced)
+	o.clusterWorkspaceLister = informers.Tenancy().V1alpha1().ClusterWorkspaces().Lister()
+}
+
+func (o *workspaceQuota) SetKcpCacheInformers(informers kcpinformers.SharedInformerFactory) {
+	o.cachedClusterWorkspacesSynced = informers.Tenancy().V1alpha1().ClusterWorkspaces().Informer().HasSynced
+	o.cachedClusterWorkspaceLister = informers.Tenancy().V1alpha1().ClusterWorkspaces().Lister()
+}
+
+func (o *workspaceQuota) ValidateInitialization() error {
+	if o.clusterWorkspaceLister == nil {
+		return fmt.Errorf(PluginName + " plugin needs a ClusterWorkspace lister")
+	}
+	return nil
+}
+
+// Validate rejects creations in workspaces exceeding their quota.
+func (o *workspaceQuota) Validate(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) error {
+	if !isMeasured(a) {
+		return nil
+	}
+	if a.GetUserInfo() != nil && sets.NewString(a.GetUserInfo().GetGroups()...).Has(user.SystemPrivilegedGroup) {
+		return nil
+	}
+
+	clusterName, err := request.ClusterNameFrom(ctx)
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+	if parent, _ := clusterName.Split(); parent.Empty() {
+		return nil
+	}
+	if o.cachedClusterWorkspacesSynced != nil && !o.cachedClusterWorkspacesSynced() {
+		return fmt.Errorf("not yet ready to handle request")
+	}
+
+	ws, err := helpers.GetClusterWorkspace(o.clusterWorkspaceLister, o.cachedClusterWorkspaceLister, clusterName)
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+	if ws.Status.Quota == nil {
+		return nil
+	}
+	hard, used := ws.Status.Quota.Hard, ws.Status.Quota.Used
+
+	if limit, found := hard[tenancyv1alpha1.QuotaResourceWorkspaces]; found && a.GetResource().GroupResource() == tenancyv1alpha1.Resource("clusterworkspaces") {
+		children, err := helpers.ListChildClusterWorkspaces(o.clusterWorkspaceLister, o.cachedClusterWorkspaceLister, clusterName)
+		if err != nil {
+			return apierrors.NewInternalError(err)
+		}
+		if count := *resource.NewQuantity(int64(len(children)), resource.DecimalSI); count.Cmp(limit) >= 0 {
+			return exceeded(a, clusterName.String(), tenancyv1alpha1.QuotaResourceWorkspaces, count, limit)
+		}
+	}
+
+	for _, resourceName := range []corev1.ResourceName{tenancyv1alpha1.QuotaResourceObjects, tenancyv1alpha1.QuotaResourceStorage} {
+		limit, found := hard[resourceName]
+		if !found {
+			continue
+		}
+		if usage, found := used[resourceName]; found && usage.Cmp(limit) >= 0 {
+			return exceeded(a, clusterName.String(), resourceName, usage, limit)
+		}
+	}
+
+	return nil
+}
+
+func exceeded(a admission.Attributes, clusterName string, resourceName corev1.ResourceName, used, limit resource.Quantity) error {
+	return admission.NewForbidden(a, fmt.Errorf("exceeded quota of workspace %s, used: %s=%s, limited: %s=%s", clusterName, resourceName, used.String(), resourceName, limit.String()))
+}
+
+// isMeasured returns true if the created object is counted by the storage tally of the
+// shard. Subresources and reviews are never stored, and events are attached to a lease.
+func isMeasured(a admission.Attributes) bool {
+	gr := a.GetResource().GroupResource()
+	if a.GetSubresource() != "" || helpers.IsReview(gr) {
+		return false
+	}
+	return gr.Resource != "events" || (gr.Group != "" && gr.Group != "events.k8s.io")
+}
@@ -0,0 +1,253 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacequota
+
+import (
+	"context"
+	"testing"
+
+	kcpcache "github.com/kcp-dev/apimachinery/pkg/cache"
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/tools/cache"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	tenancyv1alpha1listers "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
+)
+
+func TestValidate(t *testing.T) {
+	quota := &tenancyv1alpha1.ClusterWorkspaceQuotaStatus{
+		Hard: corev1.ResourceList{
+			tenancyv1alpha1.QuotaResourceWorkspaces: resource.MustParse("2"),
+			tenancyv1alpha1.QuotaResourceObjects:    resource.MustParse("100"),
+		},
+		Used: corev1.ResourceList{
+			tenancyv1alpha1.QuotaResourceWorkspaces: resource.MustParse("0"),
+			tenancyv1alpha1.QuotaResourceObjects:    resource.MustParse("99"),
+		},
+	}
+	usedUp := quota.DeepCopy()
+	usedUp.Used[tenancyv1alpha1.QuotaResourceObjects] = resource.MustParse("100")
+
+	scenarios := []struct {
+		name           string
+		clusterName    string
+		initialObjects []runtime.Object
+		cachedObjects  []runtime.Object
+		attr           admission.Attributes
+		wantErr        string
+	}{
+		{
+			name:        "creation below the quota is allowed",
+			clusterName: "root:org:ws",
+			initialObjects: []runtime.Object{
+				createClusterWorkspace("root:org", "ws", quota),
+			},
+			attr: createAttr(configMap(), &user.DefaultInfo{}),
+		},
+		{
+			name:        "creation with used up objects is forbidden",
+			clusterName: "root:org:ws",
+			initialObjects: []runtime.Object{
+				createClusterWorkspace("root:org", "ws", usedUp),
+			},
+			attr:    createAttr(configMap(), &user.DefaultInfo{}),
+			wantErr: `configmaps "test" is forbidden: exceeded quota of workspace root:org:ws, used: objects=100, limited: objects=100`,
+		},
+		{
+			name:        "creation with used up objects is forbidden if the parent is on another shard",
+			clusterName: "root:org:ws",
+			cachedObjects: []runtime.Object{
+				createClusterWorkspace("root:org", "ws", usedUp),
+			},
+			attr:    createAttr(configMap(), &user.DefaultInfo{}),
+			wantErr: `configmaps "test" is forbidden: exceeded quota of workspace root:org:ws, used: objects=100, limited: objects=100`,
+		},
+		{
+			name:        "events are not limited",
+			clusterName: "root:org:ws",
+			initialObjects: []runtime.Object{
+				createClusterWorkspace("root:org", "ws", usedUp),
+			},
+			attr: createAttr(event(), &user.DefaultInfo{}),
+		},
+		{
+			name:        "subject access reviews are not limited",
+			clusterName: "root:org:ws",
+			initialObjects: []runtime.Object{
+				createClusterWorkspace("root:org", "ws", usedUp),
+			},
+			attr: createAttr(&authorizationv1.SubjectAccessReview{}, &user.DefaultInfo{}),
+		},
+		{
+			name:        "token reviews are not limited",
+			clusterName: "root:org:ws",
+			initialObjects: []runtime.Object{
+				createClusterWorkspace("root:org", "ws", usedUp),
+			},
+			attr: createAttr(&authenticationv1.TokenReview{}, &user.DefaultInfo{}),
+		},
+		{
+			name:        "system:masters is not limited",
+			clusterName: "root:org:ws",
+			initialObjects: []runtime.Object{
+				createClusterWorkspace("root:org", "ws", usedUp),
+			},
+			attr: createAttr(configMap(), &user.DefaultInfo{Groups: []string{user.SystemPrivilegedGroup}}),
+		},
+		{
+			name:        "child workspaces below the quota are allowed",
+			clusterName: "root:org:ws",
+			initialObjects: []runtime.Object{
+				createClusterWorkspace("root:org", "ws", quota),
+				createClusterWorkspace("root:org:ws", "a", nil),
+			},
+			attr: createAttr(createClusterWorkspace("root:org:ws", "test", nil), &user.DefaultInfo{}),
+		},
+		{
+			name:        "child workspaces above the quota are forbidden",
+			clusterName: "root:org:ws",
+			initialObjects: []runtime.Object{
+				createClusterWorkspace("root:org", "ws", quota),
+				createClusterWorkspace("root:org:ws", "a", nil),
+				createClusterWorkspace("root:org:ws", "b", nil),
+			},
+			attr:    createAttr(createClusterWorkspace("root:org:ws", "test", nil), &user.DefaultInfo{}),
+			wantErr: `clusterworkspaces.tenancy.kcp.dev "test" is forbidden: exceeded quota of workspace root:org:ws, used: workspaces=2, limited: workspaces=2`,
+		},
+		{
+			name:        "child workspaces are counted once across the local shard and the cache server",
+			clusterName: "root:org:ws",
+			initialObjects: []runtime.Object{
+				createClusterWorkspace("root:org:ws", "a", nil),
+			},
+			cachedObjects: []runtime.Object{
+				createClusterWorkspace("root:org", "ws", quota),
+				createClusterWorkspace("root:org:ws", "a", nil),
+				createClusterWorkspace("root:org:ws", "b", nil),
+			},
+			attr:    createAttr(createClusterWorkspace("root:org:ws", "test", nil), &user.DefaultInfo{}),
+			wantErr: `clusterworkspaces.tenancy.kcp.dev "test" is forbidden: exceeded quota of workspace root:org:ws, used: workspaces=2, limited: workspaces=2`,
+		},
+		{
+			name:        "workspaces without quota are not limited",
+			clusterName: "root:org:ws",
+			initialObjects: []runtime.Object{
+				createClusterWorkspace("root:org", "ws", nil),
+			},
+			attr: createAttr(configMap(), &user.DefaultInfo{}),
+		},
+		{
+			name:        "root is not limited",
+			clusterName: "root",
+			attr:        createAttr(configMap(), &user.DefaultInfo{}),
+		},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(kcpcache.MetaClusterNamespaceKeyFunc, cache.Indexers{kcpcache.ClusterIndexName: kcpcache.ClusterIndexFunc})
+			for _, obj := range scenario.initialObjects {
+				if err := indexer.Add(obj); err != nil {
+					t.Error(err)
+				}
+			}
+
+			cachedIndexer := cache.NewIndexer(kcpcache.MetaClusterNamespaceKeyFunc, cache.Indexers{kcpcache.ClusterIndexName: kcpcache.ClusterIndexFunc})
+			for _, obj := range scenario.cachedObjects {
+				if err := cachedIndexer.Add(obj); err != nil {
+					t.Error(err)
+				}
+			}
+
+			o := &workspaceQuota{
+				Handler:                       admission.NewHandler(admission.Create),
+				clusterWorkspaceLister:        tenancyv1alpha1listers.NewClusterWorkspaceClusterLister(indexer),
+				cachedClusterWorkspaceLister:  tenancyv1alpha1listers.NewClusterWorkspaceClusterLister(cachedIndexer),
+				cachedClusterWorkspacesSynced: func() bool { return true },
+			}
+			ctx := request.WithCluster(context.Background(), request.Cluster{Name: logicalcluster.New(scenario.clusterName)})
+			err := o.Validate(ctx, scenario.attr, nil)
+			if scenario.wantErr == "" && err != nil {
+				t.Fatalf("Validate() unexpected error = %v", err)
+			} else if scenario.wantErr != "" && (err == nil || err.Error() != scenario.wantErr) {
+				t.Fatalf("Validate() error = %v, want %q", err, scenario.wantErr)
+			}
+		})
+	}
+}
+
+func configMap() *corev1.ConfigMap {
+	return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+}
+
+func event() *corev1.Event {
+	return &corev1.Event{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+}
+
+func createAttr(obj runtime.Object, userInfo user.Info) admission.Attributes {
+	var gvk, gvr = corev1.SchemeGroupVersion.WithKind("ConfigMap"), corev1.SchemeGroupVersion.WithResource("configmaps")
+	namespace := "default"
+	switch obj.(type) {
+	case *corev1.Event:
+		gvk, gvr = corev1.SchemeGroupVersion.WithKind("Event"), corev1.SchemeGroupVersion.WithResource("events")
+	case *authorizationv1.SubjectAccessReview:
+		gvk, gvr = authorizationv1.SchemeGroupVersion.WithKind("SubjectAccessReview"), authorizationv1.SchemeGroupVersion.WithResource("subjectaccessreviews")
+		namespace = ""
+	case *authenticationv1.TokenReview:
+		gvk, gvr = authenticationv1.SchemeGroupVersion.WithKind("TokenReview"), authenticationv1.SchemeGroupVersion.WithResource("tokenreviews")
+		namespace = ""
+	case *tenancyv1alpha1.ClusterWorkspace:
+		gvk, gvr = tenancyv1alpha1.SchemeGroupVersion.WithKind("ClusterWorkspace"), tenancyv1alpha1.SchemeGroupVersion.WithResource("clusterworkspaces")
+		namespace = ""
+	}
+	return admission.NewAttributesRecord(
+		obj,
+		nil,
+		gvk,
+		namespace,
+		"test",
+		gvr,
+		"",
+		admission.Create,
+		&metav1.CreateOptions{},
+		false,
+		userInfo,
+	)
+}
+
+func createClusterWorkspace(clusterName, name string, quota *tenancyv1alpha1.ClusterWorkspaceQuotaStatus) *tenancyv1alpha1.ClusterWorkspace {
+	return &tenancyv1alpha1.ClusterWorkspace{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				logicalcluster.AnnotationKey: clusterName,
+			},
+			Name: name,
+		},
+		Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+			Quota: quota,
+		},
+	}
+}
@@ -77,5 +77,40 @@ func CRDToAPIResourceSchema(crd *apiextensionsv1.CustomResourceDefinition, prefi
 		apiResourceSchema.Spec.Versions = append(apiResourceSchema.Spec.Versions, apiResourceVersion)
 	}
 
+	if crd.Spec.Conversion != nil && crd.Spec.Conversion.Strategy == apiextensionsv1.WebhookConverter {
+		conversion, err := webhookConversionFromCRD(crd.Spec.Conversion)
+		if err != nil {
+			return nil, err
+		}
+		apiResourceSchema.Spec.Conversion = conversion
+	}
+
 	return apiResourceSchema, nil
 }
+
+// webhookConversionFromCRD converts the webhook conversion of a CRD. Only URL based webhooks
+// are supported because services cannot be resolved for bound resources.
+func webhookConversionFromCRD(conversion *apiextensionsv1.CustomResourceConversion) (*CustomResourceConversion, error) {
+	fldPath := field.NewPath("spec", "conversion", "webhook")
+	if conversion.Webhook == nil || conversion.Webhook.ClientConfig == nil {
+		return nil, field.Required(fldPath.Child("clientConfig"), "required when strategy is set to Webhook")
+	}
+	clientConfig := conversion.Webhook.ClientConfig
+	if clientConfig.Service != nil {
+		return nil, field.Forbidden(fldPath.Child("clientConfig", "service"), "service references are not supported, use url instead")
+	}
+	if clientConfig.URL == nil {
+		return nil, field.Required(fldPath.Child("clientConfig", "url"), "url is required")
+	}
+
+	return &CustomResourceConversion{
+		Strategy: WebhookConverter,
+		Webhook: &WebhookConversion{
+			ClientConfig: WebhookClientConfig{
+				URL:      *clientConfig.URL,
+				CABundle: clientConfig.CABundle,
+			},
+			ConversionReviewVersions: conversion.Webhook.ConversionReviewVersions,
+		},
+	}, nil
+}
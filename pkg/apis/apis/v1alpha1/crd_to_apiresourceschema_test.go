@@ -0,0 +1,111 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCRDToAPIResourceSchemaConversion(t *testing.T) {
+	url := "https://convert.example.com/widgets"
+
+	tests := map[string]struct {
+		conversion *apiextensionsv1.CustomResourceConversion
+		want       *CustomResourceConversion
+		wantErr    bool
+	}{
+		"no conversion": {},
+		"none strategy is dropped": {
+			conversion: &apiextensionsv1.CustomResourceConversion{Strategy: apiextensionsv1.NoneConverter},
+		},
+		"webhook by url": {
+			conversion: &apiextensionsv1.CustomResourceConversion{
+				Strategy: apiextensionsv1.WebhookConverter,
+				Webhook: &apiextensionsv1.WebhookConversion{
+					ClientConfig: &apiextensionsv1.WebhookClientConfig{
+						URL:      &url,
+						CABundle: []byte("ca"),
+					},
+					ConversionReviewVersions: []string{"v1"},
+				},
+			},
+			want: &CustomResourceConversion{
+				Strategy: WebhookConverter,
+				Webhook: &WebhookConversion{
+					ClientConfig: WebhookClientConfig{
+						URL:      url,
+						CABundle: []byte("ca"),
+					},
+					ConversionReviewVersions: []string{"v1"},
+				},
+			},
+		},
+		"webhook by service": {
+			conversion: &apiextensionsv1.CustomResourceConversion{
+				Strategy: apiextensionsv1.WebhookConverter,
+				Webhook: &apiextensionsv1.WebhookConversion{
+					ClientConfig: &apiextensionsv1.WebhookClientConfig{
+						Service: &apiextensionsv1.ServiceReference{Namespace: "default", Name: "converter"},
+					},
+					ConversionReviewVersions: []string{"v1"},
+				},
+			},
+			wantErr: true,
+		},
+		"webhook without client config": {
+			conversion: &apiextensionsv1.CustomResourceConversion{
+				Strategy: apiextensionsv1.WebhookConverter,
+				Webhook:  &apiextensionsv1.WebhookConversion{ConversionReviewVersions: []string{"v1"}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc // to avoid t.Parallel() races
+
+		t.Run(name, func(t *testing.T) {
+			crd := &apiextensionsv1.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: "widgets.example.com"},
+				Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+					Group: "example.com",
+					Names: apiextensionsv1.CustomResourceDefinitionNames{Plural: "widgets", Kind: "Widget"},
+					Scope: apiextensionsv1.ClusterScoped,
+					Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+						{Name: "v1alpha1", Served: true},
+						{Name: "v1beta1", Served: true, Storage: true},
+					},
+					Conversion: tc.conversion,
+				},
+			}
+
+			schema, err := CRDToAPIResourceSchema(crd, "today")
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, schema.Spec.Versions, 2)
+			require.Equal(t, tc.want, schema.Spec.Conversion)
+		})
+	}
+}
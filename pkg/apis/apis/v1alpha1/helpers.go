@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"strconv"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+)
+
+// ReferencedAPIExport returns the logical cluster and the name of the APIExport an APIBinding refers to.
+// For selector references, the APIExport selected on creation is read from the AnnotationSelectedExportKey
+// annotation. It returns false if the reference is not resolved (yet).
+func ReferencedAPIExport(apiBinding *APIBinding) (logicalcluster.Name, string, bool) {
+	ref := apiBinding.Spec.Reference
+	switch {
+	case ref.Workspace != nil:
+		if ref.Workspace.Path == "" {
+			return logicalcluster.Name{}, "", false
+		}
+		return logicalcluster.New(ref.Workspace.Path), ref.Workspace.ExportName, true
+	case ref.Selector != nil:
+		return ParseSelectedExport(apiBinding.Annotations[AnnotationSelectedExportKey])
+	}
+	return logicalcluster.Name{}, "", false
+}
+
+// ParseSelectedExport parses the value of the AnnotationSelectedExportKey annotation into the logical cluster
+// and the name of the APIExport. It returns false if the value is malformed.
+func ParseSelectedExport(value string) (logicalcluster.Name, string, bool) {
+	if value == "" {
+		return logicalcluster.Name{}, "", false
+	}
+	clusterName, exportName := logicalcluster.New(value).Split()
+	if clusterName.Empty() || exportName == "" {
+		return logicalcluster.Name{}, "", false
+	}
+	return clusterName, exportName, true
+}
+
+// ToSelectedExport returns the value of the AnnotationSelectedExportKey annotation for the given APIExport.
+func ToSelectedExport(clusterName logicalcluster.Name, exportName string) string {
+	return clusterName.Join(exportName).String()
+}
+
+// SchemaGeneration returns the schema generation of the APIExport from the AnnotationSchemaGenerationKey
+// annotation. APIExports without a valid annotation, i.e. not updated since the annotation was introduced,
+// fall back to their generation.
+func SchemaGeneration(apiExport *APIExport) int64 {
+	generation, err := strconv.ParseInt(apiExport.Annotations[AnnotationSchemaGenerationKey], 10, 64)
+	if err != nil || generation < 1 {
+		return apiExport.Generation
+	}
+	return generation
+}
@@ -101,7 +101,7 @@ const (
 )
 
 // ExportReference describes a reference to an APIExport. Exactly one of the
-// fields must be set.
+// fields must be set for an APIBinding.
 type ExportReference struct {
 	// workspace is a reference to an APIExport in the same organization. The creator
 	// of the APIBinding needs to have access to the APIExport with the verb `bind`
@@ -109,6 +109,34 @@ type ExportReference struct {
 	//
 	// +optional
 	Workspace *WorkspaceExportReference `json:"workspace,omitempty"`
+
+	// selector selects an APIExport by its labels in one of a set of workspaces. Exactly one
+	// of the matching APIExports must be accessible with the verb `bind` by the creator of
+	// the APIBinding. The selected APIExport is recorded in the apis.kcp.dev/selected-export
+	// annotation on creation, and stays the same for the lifetime of the APIBinding.
+	// The selector is immutable, the APIBinding has to be re-created to select another
+	// APIExport.
+	//
+	// +optional
+	Selector *SelectorExportReference `json:"selector,omitempty"`
+}
+
+// SelectorExportReference describes an API and backing implementation that are selected by labels among the
+// APIExports of a set of workspaces.
+type SelectorExportReference struct {
+	// paths are absolute references to the workspaces that are searched for matching APIExports,
+	// e.g. root:org:ws.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	Paths []string `json:"paths"`
+
+	// labelSelector selects the APIExport by its labels.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	LabelSelector metav1.LabelSelector `json:"labelSelector"`
 }
 
 // WorkspaceExportReference describes an API and backing implementation that are provided by an actor in the
@@ -171,6 +199,13 @@ type APIBindingStatus struct {
 	// the binding to grant.
 	// +optional
 	ExportPermissionClaims []PermissionClaim `json:"exportPermissionClaims,omitempty"`
+
+	// schemaGeneration is the schema generation of the APIExport whose latestResourceSchemas are
+	// currently bound, see the apis.kcp.dev/schema-generation annotation of APIExports. It lags behind
+	// the schema generation of the APIExport while a schema rollout has not reached this APIBinding.
+	//
+	// +optional
+	SchemaGeneration int64 `json:"schemaGeneration,omitempty"`
 }
 
 // These are valid conditions of APIBinding.
@@ -205,6 +240,14 @@ const (
 	// has a naming conflict with other APIs.
 	NamingConflictsReason = "NamingConflicts"
 
+	// StorageVersionsMissingReason is a reason for the BindingUpToDate condition that an APIResourceSchema does not
+	// define all versions that objects of the bound resource might still be persisted in.
+	StorageVersionsMissingReason = "StorageVersionsMissing"
+
+	// SchemaRolloutPendingReason is a reason for the BindingUpToDate condition that the latest schemas of the APIExport
+	// have not been rolled out to the APIBinding yet, according to the schema rollout policy of the APIExport.
+	SchemaRolloutPendingReason = "SchemaRolloutPending"
+
 	// BindingResourceDeleteSuccess is a condition for APIBinding that indicates the resources relating this binding are deleted
 	// successfully when the APIBinding is deleting
 	BindingResourceDeleteSuccess conditionsv1alpha1.ConditionType = "BindingResourceDeleteSuccess"
@@ -219,6 +262,33 @@ const (
 	// PermissionClaimsApplied is a condition for APIBinding that indicates that all the accepted permission claims
 	// have been applied.
 	PermissionClaimsApplied conditionsv1alpha1.ConditionType = "PermissionClaimsApplied"
+
+	// StorageVersionsMigrated is a condition for APIBinding that indicates that all objects of the bound resources
+	// are persisted in the current storage version, and that stale versions have been trimmed from StorageVersions.
+	StorageVersionsMigrated conditionsv1alpha1.ConditionType = "StorageVersionsMigrated"
+
+	// StorageVersionMigrationInProgressReason is a reason for the StorageVersionsMigrated condition that objects
+	// of some bound resource are being re-written in the current storage version.
+	StorageVersionMigrationInProgressReason = "StorageVersionMigrationInProgress"
+
+	// StorageVersionMigrationFailedReason is a reason for the StorageVersionsMigrated condition that objects of
+	// some bound resource could not be re-written in the current storage version.
+	StorageVersionMigrationFailedReason = "StorageVersionMigrationFailed"
+)
+
+const (
+	// AnnotationSelectedExportKey is the annotation key on an APIBinding with a selector reference recording the
+	// APIExport that has been selected on creation, in the format <cluster name>:<export name>.
+	AnnotationSelectedExportKey = "apis.kcp.dev/selected-export"
+
+	// AnnotationApprovedSchemaGenerationKey is the annotation key on an APIBinding approving the rollout of the
+	// latest schemas of an APIExport with the Manual schema rollout strategy. The APIBinding is updated once the
+	// value is greater or equal to the schema generation of the APIExport, see AnnotationSchemaGenerationKey.
+	AnnotationApprovedSchemaGenerationKey = "apis.kcp.dev/approved-schema-generation"
+
+	// AnnotationDryRunResultKey is the annotation key on an APIBinding returned by a dry-run create or update
+	// request. The value is the JSON encoded APIBindingDryRunResult. The annotation is never persisted.
+	AnnotationDryRunResultKey = "apis.kcp.dev/dry-run-result"
 )
 
 // These are annotations for bound CRDs
@@ -293,6 +363,56 @@ type BoundAPIResourceSchema struct {
 	IdentityHash string `json:"identityHash"`
 }
 
+// APIBindingDryRunResult previews the outcome of binding an APIBinding, without creating any CRD. It is
+// returned in the AnnotationDryRunResultKey annotation of dry-run create and update requests.
+type APIBindingDryRunResult struct {
+	// boundResources are the resources of the APIExport that would be bound.
+	//
+	// +optional
+	BoundResources []BoundAPIResource `json:"boundResources,omitempty"`
+
+	// conflicts are the resources of the APIExport that cannot be bound, e.g. because of naming
+	// conflicts with CRDs or other APIBindings in the workspace.
+	//
+	// +optional
+	Conflicts []APIBindingConflict `json:"conflicts,omitempty"`
+
+	// pendingPermissionClaims are the permission claims of the APIExport that are neither accepted
+	// nor rejected in spec.permissionClaims.
+	//
+	// +optional
+	PendingPermissionClaims []PermissionClaim `json:"pendingPermissionClaims,omitempty"`
+
+	// invalidPermissionClaims are the accepted permission claims that the APIExport does not claim.
+	//
+	// +optional
+	InvalidPermissionClaims []PermissionClaim `json:"invalidPermissionClaims,omitempty"`
+}
+
+// APIBindingConflict describes why a resource of an APIExport cannot be bound.
+type APIBindingConflict struct {
+	// group is the group of the API. Empty string for the core API group.
+	//
+	// +required
+	Group string `json:"group"`
+
+	// resource is the resource of the API.
+	//
+	// +required
+	Resource string `json:"resource"`
+
+	// reason is the reason the resource cannot be bound, one of NamingConflicts, StorageVersionsMissing
+	// or APIResourceSchemaInvalid.
+	//
+	// +required
+	Reason string `json:"reason"`
+
+	// message is a human readable description of the conflict.
+	//
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
 // APIBindingList is a list of APIBinding resources
 //
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
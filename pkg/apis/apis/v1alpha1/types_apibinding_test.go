@@ -78,6 +78,42 @@ func TestAPIBindingPermissionClaimCELValidation(t *testing.T) {
 			},
 			wantErrs: []string{"openAPIV3Schema.properties.spec.properties.reference: Invalid value: \"object\": APIExport reference must not be changed"},
 		},
+		{
+			name: "change cluster name",
+			current: map[string]interface{}{
+				"cluster": map[string]interface{}{
+					"clusterName": "root:foo",
+					"exportName":  "bar",
+				},
+			},
+			old: map[string]interface{}{
+				"cluster": map[string]interface{}{
+					"clusterName": "root:CHANGE",
+					"exportName":  "bar",
+				},
+			},
+			wantErrs: []string{"openAPIV3Schema.properties.spec.properties.reference: Invalid value: \"object\": APIExport reference must not be changed"},
+		},
+		{
+			name: "change selector",
+			current: map[string]interface{}{
+				"selector": map[string]interface{}{
+					"paths": []interface{}{"root:foo"},
+					"labelSelector": map[string]interface{}{
+						"matchLabels": map[string]interface{}{"app": "bar"},
+					},
+				},
+			},
+			old: map[string]interface{}{
+				"selector": map[string]interface{}{
+					"paths": []interface{}{"root:foo"},
+					"labelSelector": map[string]interface{}{
+						"matchLabels": map[string]interface{}{"app": "CHANGE"},
+					},
+				},
+			},
+			wantErrs: []string{"openAPIV3Schema.properties.spec.properties.reference: Invalid value: \"object\": APIExport reference must not be changed"},
+		},
 	}
 
 	validators := apitest.ValidatorsFromFile(t, "../../../../config/crds/apis.kcp.dev_apibindings.yaml")
@@ -97,16 +97,20 @@ type APIExportSpec struct {
 	// latestResourceSchemas records the latest APIResourceSchemas that are exposed
 	// with this APIExport.
 	//
-	// The schemas can be changed in the life-cycle of the APIExport. These changes
-	// have no effect on existing APIBindings, but only on newly bound ones.
-	//
-	// For updating existing APIBindings, use an APIDeployment keeping bound
-	// workspaces up-to-date.
+	// The schemas can be changed in the life-cycle of the APIExport. Newly bound
+	// APIBindings always get the latest schemas. How existing APIBindings are moved
+	// to changed schemas is determined by schemaRolloutPolicy.
 	//
 	// +optional
 	// +listType=set
 	LatestResourceSchemas []string `json:"latestResourceSchemas,omitempty"`
 
+	// schemaRolloutPolicy determines how changes of latestResourceSchemas are rolled
+	// out to existing APIBindings. If unset, all APIBindings are updated immediately.
+	//
+	// +optional
+	SchemaRolloutPolicy *SchemaRolloutPolicy `json:"schemaRolloutPolicy,omitempty"`
+
 	// identity points to a secret that contains the API identity in the 'key' file.
 	// The API identity determines an unique etcd prefix for objects stored via this
 	// APIExport.
@@ -165,6 +169,46 @@ type APIExportSpec struct {
 	PermissionClaims []PermissionClaim `json:"permissionClaims,omitempty"`
 }
 
+// SchemaRolloutStrategy is the strategy used to roll out changes of APIExport.spec.latestResourceSchemas
+// to existing APIBindings.
+type SchemaRolloutStrategy string
+
+const (
+	// SchemaRolloutImmediate updates all existing APIBindings to the latest schemas right away.
+	SchemaRolloutImmediate SchemaRolloutStrategy = "Immediate"
+	// SchemaRolloutManual updates an existing APIBinding only after the latest schema generation
+	// has been approved on the APIBinding via the apis.kcp.dev/approved-schema-generation annotation.
+	SchemaRolloutManual SchemaRolloutStrategy = "Manual"
+	// SchemaRolloutStaged updates only those existing APIBindings whose workspace matches the
+	// selector of the policy. All other APIBindings keep their currently bound schemas.
+	SchemaRolloutStaged SchemaRolloutStrategy = "Staged"
+)
+
+// AnnotationSchemaGenerationKey is the annotation key on an APIExport holding its schema generation. It is
+// maintained by admission and incremented whenever spec.latestResourceSchemas changes, but not on other spec
+// changes like permission claims or identity. The schema generation is what is approved via the
+// apis.kcp.dev/approved-schema-generation annotation on APIBindings.
+const AnnotationSchemaGenerationKey = "apis.kcp.dev/schema-generation"
+
+// SchemaRolloutPolicy determines how changes of the latest resource schemas of an APIExport are
+// rolled out to APIBindings that are already bound.
+//
+// +kubebuilder:validation:XValidation:rule="self.strategy != 'Staged' || has(self.workspaceSelector)",message="workspaceSelector is required for the Staged strategy"
+type SchemaRolloutPolicy struct {
+	// strategy is the rollout strategy, one of Immediate, Manual or Staged.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=Immediate;Manual;Staged
+	Strategy SchemaRolloutStrategy `json:"strategy"`
+
+	// workspaceSelector selects the consumer workspaces, by the labels of their ClusterWorkspace,
+	// whose APIBindings are updated to the latest schemas with the Staged strategy.
+	//
+	// +optional
+	WorkspaceSelector *metav1.LabelSelector `json:"workspaceSelector,omitempty"`
+}
+
 // Identity defines the identity of an APIExport, i.e. determines the etcd prefix
 // data of this APIExport are stored under.
 type Identity struct {
@@ -190,6 +234,9 @@ type LocalAPIExportPolicy struct{}
 
 const (
 	APIExportPermissionClaimLabelPrefix = "claimed.internal.apis.kcp.dev/"
+
+	// PermissionClaimAllVerbs is the verb of a permission claim that claims all verbs.
+	PermissionClaimAllVerbs = "*"
 )
 
 // PermissionClaim identifies an object by GR and identity hash.
@@ -210,6 +257,21 @@ type PermissionClaim struct {
 	// +optional
 	ResourceSelector []ResourceSelector `json:"resourceSelector,omitempty"`
 
+	// verbs is the list of verbs the service provider may use on the claimed objects, e.g.
+	// get, list and watch for read-only access. "*" stands for all verbs. If unset, all verbs
+	// are claimed.
+	//
+	// +optional
+	// +listType=set
+	Verbs []string `json:"verbs,omitempty"`
+
+	// labelSelector restricts the claimed objects to those matching the selector, in addition
+	// to all or resourceSelector. Objects not matching the selector are not visible to the
+	// service provider.
+	//
+	// +optional
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+
 	// This is the identity for a given APIExport that the APIResourceSchema belongs to.
 	// The hash can be found on APIExport and APIResourceSchema's status.
 	// It will be empty for core types.
@@ -253,6 +315,33 @@ func (p PermissionClaim) String() string {
 	return fmt.Sprintf("%s.%s:%s", p.Resource, p.Group, p.IdentityHash)
 }
 
+// AllowsVerb returns true if the claim grants the given verb on the claimed objects.
+func (p PermissionClaim) AllowsVerb(verb string) bool {
+	if len(p.Verbs) == 0 {
+		return true
+	}
+	for _, v := range p.Verbs {
+		if v == verb || v == PermissionClaimAllVerbs {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectsObject returns true if the claim selects the object with the given namespace and name, i.e. if
+// all objects are claimed or one of the resource selectors matches. The label selector is not considered.
+func (p PermissionClaim) SelectsObject(namespace, name string) bool {
+	if p.All {
+		return true
+	}
+	for _, s := range p.ResourceSelector {
+		if (s.Name == "" || s.Name == name) && (s.Namespace == "" || s.Namespace == namespace) {
+			return true
+		}
+	}
+	return false
+}
+
 func (p PermissionClaim) Equal(claim PermissionClaim) bool {
 	return p.Group == claim.Group &&
 		p.Resource == claim.Resource &&
@@ -295,6 +384,96 @@ type APIExportStatus struct {
 	//
 	// +optional
 	VirtualWorkspaces []VirtualWorkspace `json:"virtualWorkspaces,omitempty"`
+
+	// consumers summarizes the APIBindings of all shards that reference this APIExport.
+	// It is only maintained if kcp runs with a cache server.
+	//
+	// +optional
+	Consumers *APIExportConsumers `json:"consumers,omitempty"`
+}
+
+// APIExportConsumers summarizes the APIBindings referencing an APIExport.
+type APIExportConsumers struct {
+	// count is the number of APIBindings referencing the APIExport.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=0
+	Count int32 `json:"count"`
+
+	// boundSchemas lists the APIResourceSchemas bound by APIBindings referencing the APIExport,
+	// together with the number of APIBindings bound to each of them. A schema listed here that
+	// is not part of spec.latestResourceSchemas is still in use by some consumers.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	BoundSchemas []BoundSchemaConsumers `json:"boundSchemas,omitempty"`
+
+	// permissionClaims lists the permission claims of the APIExport together with the number
+	// of APIBindings that accepted or rejected them, or did not decide yet.
+	//
+	// +optional
+	PermissionClaims []PermissionClaimConsumers `json:"permissionClaims,omitempty"`
+}
+
+// BoundSchemaConsumers is the number of APIBindings bound to an APIResourceSchema of an APIExport.
+type BoundSchemaConsumers struct {
+	// name is the name of the APIResourceSchema.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// group is the API group of the bound resource.
+	//
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// resource is the resource name of the bound resource.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	Resource string `json:"resource"`
+
+	// count is the number of APIBindings bound to the APIResourceSchema.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=0
+	Count int32 `json:"count"`
+}
+
+// PermissionClaimConsumers is the number of APIBindings in each state of a permission claim of an APIExport.
+type PermissionClaimConsumers struct {
+	GroupResource `json:","`
+
+	// identityHash is the identity hash of the claimed resource. It is empty for core types.
+	//
+	// +optional
+	IdentityHash string `json:"identityHash,omitempty"`
+
+	// accepted is the number of APIBindings that accepted the claim.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=0
+	Accepted int32 `json:"accepted"`
+
+	// rejected is the number of APIBindings that rejected the claim.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=0
+	Rejected int32 `json:"rejected"`
+
+	// pending is the number of APIBindings that neither accepted nor rejected the claim.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=0
+	Pending int32 `json:"pending"`
 }
 
 type VirtualWorkspace struct {
@@ -282,3 +282,124 @@ func TestAPIExportPermissionClaimPattern(t *testing.T) {
 		})
 	}
 }
+
+func TestSchemaRolloutPolicyCELValidation(t *testing.T) {
+	testCases := []struct {
+		name         string
+		current, old map[string]interface{}
+		wantErrs     []string
+	}{
+		{
+			name: "immediate without selector",
+			current: map[string]interface{}{
+				"strategy": "Immediate",
+			},
+		},
+		{
+			name: "manual without selector",
+			current: map[string]interface{}{
+				"strategy": "Manual",
+			},
+		},
+		{
+			name: "staged with selector",
+			current: map[string]interface{}{
+				"strategy":          "Staged",
+				"workspaceSelector": map[string]interface{}{"matchLabels": map[string]interface{}{"canary": "true"}},
+			},
+		},
+		{
+			name: "staged without selector",
+			current: map[string]interface{}{
+				"strategy": "Staged",
+			},
+			wantErrs: []string{
+				"openAPIV3Schema.properties.spec.properties.schemaRolloutPolicy: Invalid value: \"object\": workspaceSelector is required for the Staged strategy",
+			},
+		},
+	}
+
+	validators := apitest.ValidatorsFromFile(t, "../../../../config/crds/apis.kcp.dev_apiexports.yaml")
+
+	for _, tc := range testCases {
+		pth := "openAPIV3Schema.properties.spec.properties.schemaRolloutPolicy"
+		validator, found := validators["v1alpha1"][pth]
+		require.True(t, found, "failed to find validator for %s", pth)
+
+		t.Run(tc.name, func(t *testing.T) {
+			errs := validator(tc.current, tc.old)
+			t.Log(errs)
+
+			if got := len(errs); got != len(tc.wantErrs) {
+				t.Errorf("expected errors %v, got %v", len(tc.wantErrs), len(errs))
+				return
+			}
+
+			for i := range tc.wantErrs {
+				got := errs[i].Error()
+				if got != tc.wantErrs[i] {
+					t.Errorf("want error %q, got %q", tc.wantErrs[i], got)
+				}
+			}
+		})
+	}
+}
+
+func TestPermissionClaimSelectsObject(t *testing.T) {
+	tests := map[string]struct {
+		claim     PermissionClaim
+		namespace string
+		name      string
+		want      bool
+	}{
+		"all": {
+			claim:     PermissionClaim{All: true},
+			namespace: "default",
+			name:      "foo",
+			want:      true,
+		},
+		"matching name": {
+			claim:     PermissionClaim{ResourceSelector: []ResourceSelector{{Name: "foo"}}},
+			namespace: "default",
+			name:      "foo",
+			want:      true,
+		},
+		"matching namespace": {
+			claim:     PermissionClaim{ResourceSelector: []ResourceSelector{{Namespace: "default"}}},
+			namespace: "default",
+			name:      "foo",
+			want:      true,
+		},
+		"matching name and namespace": {
+			claim:     PermissionClaim{ResourceSelector: []ResourceSelector{{Namespace: "other"}, {Name: "foo", Namespace: "default"}}},
+			namespace: "default",
+			name:      "foo",
+			want:      true,
+		},
+		"matching name in other namespace": {
+			claim:     PermissionClaim{ResourceSelector: []ResourceSelector{{Name: "foo", Namespace: "other"}}},
+			namespace: "default",
+			name:      "foo",
+		},
+		"other name": {
+			claim:     PermissionClaim{ResourceSelector: []ResourceSelector{{Name: "bar"}}},
+			namespace: "default",
+			name:      "foo",
+		},
+		"namespace selector on cluster-scoped object": {
+			claim: PermissionClaim{ResourceSelector: []ResourceSelector{{Namespace: "default"}}},
+			name:  "foo",
+		},
+		"nothing selected": {
+			claim:     PermissionClaim{},
+			namespace: "default",
+			name:      "foo",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.want, tc.claim.SelectsObject(tc.namespace, tc.name))
+		})
+	}
+}
@@ -18,6 +18,8 @@ package v1alpha1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
 )
 
 // +crd
@@ -53,8 +55,9 @@ type APIExportEndpointSliceSpec struct {
 	// +required
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="APIExport reference must not be changed"
+	// +kubebuilder:validation:XValidation:rule="has(self.workspace)",message="only workspace references are supported"
 
-	// apiExport points to the service export.
+	// apiExport points to the service export. Only workspace references are supported.
 	APIExport ExportReference `json:"apiExport"`
 
 	// +optional
@@ -70,6 +73,34 @@ type APIExportEndpointSliceStatus struct {
 
 	// endpoints contains all the URLs of the APIExport service.
 	APIExportEndpoints []APIExportEndpoint `json:"endpoints,omitempty"`
+
+	// +optional
+
+	// conditions is a list of conditions that apply to the APIExportEndpointSlice.
+	Conditions conditionsv1alpha1.Conditions `json:"conditions,omitempty"`
+}
+
+// These are valid conditions of APIExportEndpointSlice in addition to
+// APIExportValid and related reasons defined with the APIBinding type.
+const (
+	// PartitionValid is a condition for APIExportEndpointSlice that reflects the validity of the referenced Partition.
+	PartitionValid conditionsv1alpha1.ConditionType = "PartitionValid"
+
+	// PartitionInvalidReferenceReason is a reason for the PartitionValid condition of APIExportEndpointSlice that the
+	// Partition reference is invalid.
+	PartitionInvalidReferenceReason = "PartitionInvalidReference"
+
+	// APIExportEndpointSliceURLsReady is a condition for APIExportEndpointSlice that reflects whether the endpoint
+	// URLs have been populated from the shards matching the partition.
+	APIExportEndpointSliceURLsReady conditionsv1alpha1.ConditionType = "EndpointURLsReady"
+)
+
+func (in *APIExportEndpointSlice) GetConditions() conditionsv1alpha1.Conditions {
+	return in.Status.Conditions
+}
+
+func (in *APIExportEndpointSlice) SetConditions(conditions conditionsv1alpha1.Conditions) {
+	in.Status.Conditions = conditions
 }
 
 // Using a struct provides an extension point
@@ -67,14 +67,86 @@ type APIResourceSchemaSpec struct {
 
 	// versions is the API version of the defined custom resource.
 	//
-	// Note: the OpenAPI v3 schemas must be equal for all versions until CEL
-	//       version migration is supported.
+	// Note: the OpenAPI v3 schemas must be equal for all versions unless a
+	//       conversion webhook is configured in conversion.
 	//
 	// +required
 	// +listType=map
 	// +listMapKey=name
 	// +kubebuilder:validation:MinItems=1
 	Versions []APIResourceVersion `json:"versions"`
+
+	// conversion defines conversion settings for the defined custom resource.
+	// If unset, the None strategy is used, i.e. only the apiVersion is changed
+	// when converting between versions.
+	//
+	// +optional
+	Conversion *CustomResourceConversion `json:"conversion,omitempty"`
+}
+
+// ConversionStrategyType describes different conversion types.
+type ConversionStrategyType string
+
+const (
+	// NoneConverter is a converter that only sets apiversion of the resource and does not touch any other field.
+	NoneConverter ConversionStrategyType = "None"
+	// WebhookConverter is a converter that calls an external webhook to do the conversion.
+	WebhookConverter ConversionStrategyType = "Webhook"
+)
+
+// CustomResourceConversion describes how to convert different versions of a resource.
+//
+// +kubebuilder:validation:XValidation:rule="self.strategy == 'Webhook' ? has(self.webhook) : !has(self.webhook)",message="webhook must be set if and only if strategy is Webhook"
+type CustomResourceConversion struct {
+	// strategy specifies how custom resources are converted between versions. Allowed values are:
+	// - `"None"`: the converter only changes the apiVersion and does not touch any other field in the custom resource.
+	// - `"Webhook"`: the shards call the webhook in `webhook` to do the conversion.
+	//
+	// +required
+	// +kubebuilder:validation:Enum=None;Webhook
+	Strategy ConversionStrategyType `json:"strategy"`
+
+	// webhook describes how to call the conversion webhook. Required when `strategy` is set to `"Webhook"`.
+	//
+	// +optional
+	Webhook *WebhookConversion `json:"webhook,omitempty"`
+}
+
+// WebhookConversion describes how to call a conversion webhook.
+type WebhookConversion struct {
+	// clientConfig is the instructions for how to call the webhook.
+	//
+	// +required
+	ClientConfig WebhookClientConfig `json:"clientConfig"`
+
+	// conversionReviewVersions is an ordered list of preferred `ConversionReview`
+	// versions the webhook expects. The shards use the first version in the list
+	// which they support. If none of the versions specified in this list are
+	// supported, conversion fails for the custom resource.
+	//
+	// +required
+	// +listType=atomic
+	ConversionReviewVersions []string `json:"conversionReviewVersions"`
+}
+
+// WebhookClientConfig contains the information to make a TLS connection with the webhook.
+//
+// Other than for CustomResourceDefinitions, the webhook cannot be referenced by a service
+// because the bound resources are served by every shard of the workspaces binding the
+// resource. Hence, the URL must be reachable from all these shards.
+type WebhookClientConfig struct {
+	// url gives the location of the webhook, in standard URL form
+	// (`scheme://host:port/path`). The scheme must be "https".
+	//
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	URL string `json:"url"`
+
+	// caBundle is a PEM encoded CA bundle which will be used to validate the webhook's server certificate.
+	// If unspecified, system trust roots on the shards are used.
+	//
+	// +optional
+	CABundle []byte `json:"caBundle,omitempty"`
 }
 
 // APIResourceVersion describes one API version of a resource.
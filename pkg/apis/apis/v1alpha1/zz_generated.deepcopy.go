@@ -24,6 +24,7 @@ package v1alpha1
 import (
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 
 	conditionsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
@@ -57,6 +58,64 @@ func (in *APIBinding) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIBindingConflict) DeepCopyInto(out *APIBindingConflict) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIBindingConflict.
+func (in *APIBindingConflict) DeepCopy() *APIBindingConflict {
+	if in == nil {
+		return nil
+	}
+	out := new(APIBindingConflict)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIBindingDryRunResult) DeepCopyInto(out *APIBindingDryRunResult) {
+	*out = *in
+	if in.BoundResources != nil {
+		in, out := &in.BoundResources, &out.BoundResources
+		*out = make([]BoundAPIResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conflicts != nil {
+		in, out := &in.Conflicts, &out.Conflicts
+		*out = make([]APIBindingConflict, len(*in))
+		copy(*out, *in)
+	}
+	if in.PendingPermissionClaims != nil {
+		in, out := &in.PendingPermissionClaims, &out.PendingPermissionClaims
+		*out = make([]PermissionClaim, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InvalidPermissionClaims != nil {
+		in, out := &in.InvalidPermissionClaims, &out.InvalidPermissionClaims
+		*out = make([]PermissionClaim, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIBindingDryRunResult.
+func (in *APIBindingDryRunResult) DeepCopy() *APIBindingDryRunResult {
+	if in == nil {
+		return nil
+	}
+	out := new(APIBindingDryRunResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *APIBindingList) DeepCopyInto(out *APIBindingList) {
 	*out = *in
@@ -186,6 +245,32 @@ func (in *APIExport) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIExportConsumers) DeepCopyInto(out *APIExportConsumers) {
+	*out = *in
+	if in.BoundSchemas != nil {
+		in, out := &in.BoundSchemas, &out.BoundSchemas
+		*out = make([]BoundSchemaConsumers, len(*in))
+		copy(*out, *in)
+	}
+	if in.PermissionClaims != nil {
+		in, out := &in.PermissionClaims, &out.PermissionClaims
+		*out = make([]PermissionClaimConsumers, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIExportConsumers.
+func (in *APIExportConsumers) DeepCopy() *APIExportConsumers {
+	if in == nil {
+		return nil
+	}
+	out := new(APIExportConsumers)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *APIExportEndpoint) DeepCopyInto(out *APIExportEndpoint) {
 	*out = *in
@@ -288,6 +373,13 @@ func (in *APIExportEndpointSliceStatus) DeepCopyInto(out *APIExportEndpointSlice
 		*out = make([]APIExportEndpoint, len(*in))
 		copy(*out, *in)
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(conditionsv1alpha1.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -342,6 +434,11 @@ func (in *APIExportSpec) DeepCopyInto(out *APIExportSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.SchemaRolloutPolicy != nil {
+		in, out := &in.SchemaRolloutPolicy, &out.SchemaRolloutPolicy
+		*out = new(SchemaRolloutPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Identity != nil {
 		in, out := &in.Identity, &out.Identity
 		*out = new(Identity)
@@ -387,6 +484,11 @@ func (in *APIExportStatus) DeepCopyInto(out *APIExportStatus) {
 		*out = make([]VirtualWorkspace, len(*in))
 		copy(*out, *in)
 	}
+	if in.Consumers != nil {
+		in, out := &in.Consumers, &out.Consumers
+		*out = new(APIExportConsumers)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -471,6 +573,11 @@ func (in *APIResourceSchemaSpec) DeepCopyInto(out *APIResourceSchemaSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Conversion != nil {
+		in, out := &in.Conversion, &out.Conversion
+		*out = new(CustomResourceConversion)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -567,6 +674,43 @@ func (in *BoundAPIResourceSchema) DeepCopy() *BoundAPIResourceSchema {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BoundSchemaConsumers) DeepCopyInto(out *BoundSchemaConsumers) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BoundSchemaConsumers.
+func (in *BoundSchemaConsumers) DeepCopy() *BoundSchemaConsumers {
+	if in == nil {
+		return nil
+	}
+	out := new(BoundSchemaConsumers)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomResourceConversion) DeepCopyInto(out *CustomResourceConversion) {
+	*out = *in
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(WebhookConversion)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomResourceConversion.
+func (in *CustomResourceConversion) DeepCopy() *CustomResourceConversion {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomResourceConversion)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExportReference) DeepCopyInto(out *ExportReference) {
 	*out = *in
@@ -575,6 +719,11 @@ func (in *ExportReference) DeepCopyInto(out *ExportReference) {
 		*out = new(WorkspaceExportReference)
 		**out = **in
 	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(SelectorExportReference)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -671,6 +820,16 @@ func (in *PermissionClaim) DeepCopyInto(out *PermissionClaim) {
 		*out = make([]ResourceSelector, len(*in))
 		copy(*out, *in)
 	}
+	if in.Verbs != nil {
+		in, out := &in.Verbs, &out.Verbs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -684,6 +843,23 @@ func (in *PermissionClaim) DeepCopy() *PermissionClaim {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PermissionClaimConsumers) DeepCopyInto(out *PermissionClaimConsumers) {
+	*out = *in
+	out.GroupResource = in.GroupResource
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PermissionClaimConsumers.
+func (in *PermissionClaimConsumers) DeepCopy() *PermissionClaimConsumers {
+	if in == nil {
+		return nil
+	}
+	out := new(PermissionClaimConsumers)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceSelector) DeepCopyInto(out *ResourceSelector) {
 	*out = *in
@@ -700,6 +876,49 @@ func (in *ResourceSelector) DeepCopy() *ResourceSelector {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchemaRolloutPolicy) DeepCopyInto(out *SchemaRolloutPolicy) {
+	*out = *in
+	if in.WorkspaceSelector != nil {
+		in, out := &in.WorkspaceSelector, &out.WorkspaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchemaRolloutPolicy.
+func (in *SchemaRolloutPolicy) DeepCopy() *SchemaRolloutPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SchemaRolloutPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelectorExportReference) DeepCopyInto(out *SelectorExportReference) {
+	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.LabelSelector.DeepCopyInto(&out.LabelSelector)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelectorExportReference.
+func (in *SelectorExportReference) DeepCopy() *SelectorExportReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SelectorExportReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualWorkspace) DeepCopyInto(out *VirtualWorkspace) {
 	*out = *in
@@ -716,6 +935,49 @@ func (in *VirtualWorkspace) DeepCopy() *VirtualWorkspace {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookClientConfig) DeepCopyInto(out *WebhookClientConfig) {
+	*out = *in
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookClientConfig.
+func (in *WebhookClientConfig) DeepCopy() *WebhookClientConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookClientConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookConversion) DeepCopyInto(out *WebhookConversion) {
+	*out = *in
+	in.ClientConfig.DeepCopyInto(&out.ClientConfig)
+	if in.ConversionReviewVersions != nil {
+		in, out := &in.ConversionReviewVersions, &out.ConversionReviewVersions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookConversion.
+func (in *WebhookConversion) DeepCopy() *WebhookConversion {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookConversion)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkspaceExportReference) DeepCopyInto(out *WorkspaceExportReference) {
 	*out = *in
@@ -82,6 +82,57 @@ type PlacementSpec struct {
 	// +optional
 	// +kubebuilder:validation:Pattern:="^root(:[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$"
 	LocationWorkspace string `json:"locationWorkspace,omitempty"`
+
+	// syncTargetAffinity expresses a preference for SyncTargets in the selected location with
+	// certain labels. The weights of all terms matching a SyncTarget are added to its score
+	// when the label-affinity scorer is enabled.
+	// +optional
+	SyncTargetAffinity []WeightedSyncTargetSelector `json:"syncTargetAffinity,omitempty"`
+
+	// spread places the selected namespaces onto several SyncTargets of the selected location at
+	// the same time, e.g. for active-active deployments. By default, one SyncTarget is chosen.
+	// Spreading is limited to the SyncTargets of the one selected location, spreading across
+	// locations is not supported. Create one Placement per location for that instead.
+	// +optional
+	Spread *SpreadPolicy `json:"spread,omitempty"`
+}
+
+// SpreadPolicy describes how many SyncTargets of the selected location a placement is spread across.
+type SpreadPolicy struct {
+	// replicas is the number of distinct SyncTargets the namespaces are placed onto. If the selected
+	// location has fewer SyncTargets, or fewer distinct values of the topology label, the placement
+	// is not scheduled at all and the Scheduled condition is false with the SpreadExceedsLocation
+	// reason. If only fewer suitable SyncTargets exist, e.g. because some are not ready, the placement
+	// is scheduled to all of them and the Scheduled condition is false.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	Replicas int32 `json:"replicas"`
+
+	// topologyKey is the key of a SyncTarget label. If set, the chosen SyncTargets have distinct
+	// values of this label, e.g. to spread across regions or zones, and SyncTargets without the
+	// label are not considered.
+	//
+	// +optional
+	TopologyKey string `json:"topologyKey,omitempty"`
+}
+
+// WeightedSyncTargetSelector is a label selector for SyncTargets with a weight.
+type WeightedSyncTargetSelector struct {
+	// weight is added to the score of SyncTargets matching the selector.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Weight int32 `json:"weight"`
+
+	// selector is a label selector for SyncTargets.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	Selector metav1.LabelSelector `json:"selector"`
 }
 
 type PlacementStatus struct {
@@ -95,6 +146,11 @@ type PlacementStatus struct {
 	// +optional
 	SelectedLocation *LocationReference `json:"selectedLocation,omitempty"`
 
+	// scheduledSyncTargets are the SyncTargets in the selected location chosen by the scheduler,
+	// with the score and the reason at the time of the decision.
+	// +optional
+	ScheduledSyncTargets []ScheduledSyncTarget `json:"scheduledSyncTargets,omitempty"`
+
 	// Current processing state of the Placement.
 	// +optional
 	Conditions conditionsv1alpha1.Conditions `json:"conditions,omitempty"`
@@ -118,6 +174,32 @@ type LocationReference struct {
 	LocationName string `json:"locationName"`
 }
 
+// ScheduledSyncTarget describes a scheduling decision for a SyncTarget.
+type ScheduledSyncTarget struct {
+	// path is an absolute reference to the workspace of the SyncTarget.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern:="^root(:[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$"
+	Path string `json:"path"`
+
+	// name is the name of the SyncTarget.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// score is the score of the SyncTarget when it was chosen. Higher is better.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	Score int64 `json:"score"`
+
+	// reason is a human readable explanation of the score.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
+
 type PlacementPhase string
 
 const (
@@ -162,6 +244,14 @@ const (
 	// ScheduleNoValidTargetReason is a reason for PlacementScheduled condition that no valid target is scheduled
 	// for this placement.
 	ScheduleNoValidTargetReason = "NoValidTarget"
+
+	// ScheduleNotEnoughTargetsReason is a reason for PlacementScheduled condition that fewer targets than
+	// requested by the spread policy are scheduled for this placement.
+	ScheduleNotEnoughTargetsReason = "NotEnoughTargets"
+
+	// ScheduleSpreadExceedsLocationReason is a reason for PlacementScheduled condition that the spread policy
+	// requests more targets than the selected location has, hence the placement is not scheduled.
+	ScheduleSpreadExceedsLocationReason = "SpreadExceedsLocation"
 )
 
 // PlacementList is a list of locations.
@@ -274,6 +274,18 @@ func (in *PlacementSpec) DeepCopyInto(out *PlacementSpec) {
 		*out = new(v1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.SyncTargetAffinity != nil {
+		in, out := &in.SyncTargetAffinity, &out.SyncTargetAffinity
+		*out = make([]WeightedSyncTargetSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Spread != nil {
+		in, out := &in.Spread, &out.Spread
+		*out = new(SpreadPolicy)
+		**out = **in
+	}
 	return
 }
 
@@ -295,6 +307,11 @@ func (in *PlacementStatus) DeepCopyInto(out *PlacementStatus) {
 		*out = new(LocationReference)
 		**out = **in
 	}
+	if in.ScheduledSyncTargets != nil {
+		in, out := &in.ScheduledSyncTargets, &out.ScheduledSyncTargets
+		*out = make([]ScheduledSyncTarget, len(*in))
+		copy(*out, *in)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make(conditionsv1alpha1.Conditions, len(*in))
@@ -314,3 +331,52 @@ func (in *PlacementStatus) DeepCopy() *PlacementStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledSyncTarget) DeepCopyInto(out *ScheduledSyncTarget) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledSyncTarget.
+func (in *ScheduledSyncTarget) DeepCopy() *ScheduledSyncTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledSyncTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpreadPolicy) DeepCopyInto(out *SpreadPolicy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpreadPolicy.
+func (in *SpreadPolicy) DeepCopy() *SpreadPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SpreadPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WeightedSyncTargetSelector) DeepCopyInto(out *WeightedSyncTargetSelector) {
+	*out = *in
+	in.Selector.DeepCopyInto(&out.Selector)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WeightedSyncTargetSelector.
+func (in *WeightedSyncTargetSelector) DeepCopy() *WeightedSyncTargetSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(WeightedSyncTargetSelector)
+	in.DeepCopyInto(out)
+	return out
+}
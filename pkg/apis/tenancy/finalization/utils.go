@@ -0,0 +1,57 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package finalization
+
+import (
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	"github.com/kcp-dev/kcp/pkg/apis/tenancy/initialization"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+func FinalizerPresent(finalizer tenancyv1alpha1.ClusterWorkspaceFinalizer, finalizers []tenancyv1alpha1.ClusterWorkspaceFinalizer) bool {
+	return initialization.Present(finalizer, finalizers)
+}
+
+func EnsureFinalizerPresent(finalizer tenancyv1alpha1.ClusterWorkspaceFinalizer, finalizers []tenancyv1alpha1.ClusterWorkspaceFinalizer) []tenancyv1alpha1.ClusterWorkspaceFinalizer {
+	return initialization.EnsurePresent(finalizer, finalizers)
+}
+
+func EnsureFinalizerAbsent(finalizer tenancyv1alpha1.ClusterWorkspaceFinalizer, finalizers []tenancyv1alpha1.ClusterWorkspaceFinalizer) []tenancyv1alpha1.ClusterWorkspaceFinalizer {
+	return initialization.EnsureAbsent(finalizer, finalizers)
+}
+
+// FinalizerForType determines the identifier for the implicit finalizer associated with the ClusterWorkspaceType.
+func FinalizerForType(cwt *tenancyv1alpha1.ClusterWorkspaceType) tenancyv1alpha1.ClusterWorkspaceFinalizer {
+	return FinalizerForReference(tenancyv1alpha1.ReferenceFor(cwt))
+}
+
+// FinalizerForReference determines the identifier for the implicit finalizer associated with the
+// ClusterWorkspaceType referred to with the reference.
+func FinalizerForReference(cwtr tenancyv1alpha1.ClusterWorkspaceTypeReference) tenancyv1alpha1.ClusterWorkspaceFinalizer {
+	return tenancyv1alpha1.ClusterWorkspaceFinalizer(initialization.InitializerForReference(cwtr))
+}
+
+// TypeFrom determines the ClusterWorkspaceType workspace and name from a finalizer name.
+func TypeFrom(finalizer tenancyv1alpha1.ClusterWorkspaceFinalizer) (logicalcluster.Name, string, error) {
+	return initialization.TypeFrom(finalizer)
+}
+
+// FinalizerToLabel transforms a finalizer into a key-value pair to add to a label set.
+func FinalizerToLabel(finalizer tenancyv1alpha1.ClusterWorkspaceFinalizer) (string, string) {
+	return initialization.ToLabel(tenancyv1alpha1.ClusterWorkspaceFinalizerLabelPrefix, string(finalizer))
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package finalization
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+func TestFinalizerToLabel(t *testing.T) {
+	for _, testCase := range []tenancyv1alpha1.ClusterWorkspaceFinalizer{
+		"root:org:ws:whatever",
+		"root:org:super-super-super-super-super-super-unnecessarily-long-name-for-a-thing-that-still-works",
+	} {
+		key, value := FinalizerToLabel(testCase)
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			t.Errorf("finalizer %q produces an invalid label key %q: %s", testCase, key, strings.Join(errs, ", "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			t.Errorf("finalizer %q produces an invalid label value %q: %s", testCase, value, strings.Join(errs, ", "))
+		}
+	}
+}
+
+func TestTypeFrom(t *testing.T) {
+	clusterName, name, err := TypeFrom("root:org:team-a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if clusterName.String() != "root:org" || name != "team-a" {
+		t.Errorf("expected root:org and team-a, got %s and %s", clusterName, name)
+	}
+	if _, _, err := TypeFrom("root"); err == nil {
+		t.Errorf("expected an error for a finalizer without a type name")
+	}
+}
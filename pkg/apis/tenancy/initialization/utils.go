@@ -29,36 +29,43 @@ import (
 )
 
 func InitializerPresent(initializer tenancyv1alpha1.ClusterWorkspaceInitializer, initializers []tenancyv1alpha1.ClusterWorkspaceInitializer) bool {
-	for i := range initializers {
-		if initializers[i] == initializer {
+	return Present(initializer, initializers)
+}
+
+func EnsureInitializerPresent(initializer tenancyv1alpha1.ClusterWorkspaceInitializer, initializers []tenancyv1alpha1.ClusterWorkspaceInitializer) []tenancyv1alpha1.ClusterWorkspaceInitializer {
+	return EnsurePresent(initializer, initializers)
+}
+
+func EnsureInitializerAbsent(initializer tenancyv1alpha1.ClusterWorkspaceInitializer, initializers []tenancyv1alpha1.ClusterWorkspaceInitializer) []tenancyv1alpha1.ClusterWorkspaceInitializer {
+	return EnsureAbsent(initializer, initializers)
+}
+
+// Present returns true if the initializer or finalizer is in the list.
+func Present[T ~string](item T, items []T) bool {
+	for i := range items {
+		if items[i] == item {
 			return true
 		}
 	}
 	return false
 }
 
-func EnsureInitializerPresent(initializer tenancyv1alpha1.ClusterWorkspaceInitializer, initializers []tenancyv1alpha1.ClusterWorkspaceInitializer) []tenancyv1alpha1.ClusterWorkspaceInitializer {
-	for i := range initializers {
-		if initializers[i] == initializer {
-			return initializers // already present
-		}
+// EnsurePresent adds the initializer or finalizer to the list, unless it is present already.
+func EnsurePresent[T ~string](item T, items []T) []T {
+	if Present(item, items) {
+		return items
 	}
-	initializers = append(initializers, initializer)
-	return initializers
+	return append(items, item)
 }
 
-func EnsureInitializerAbsent(initializer tenancyv1alpha1.ClusterWorkspaceInitializer, initializers []tenancyv1alpha1.ClusterWorkspaceInitializer) []tenancyv1alpha1.ClusterWorkspaceInitializer {
-	removeAt := -1
-	for i := range initializers {
-		if initializers[i] == initializer {
-			removeAt = i
-			break
+// EnsureAbsent removes the initializer or finalizer from the list.
+func EnsureAbsent[T ~string](item T, items []T) []T {
+	for i := range items {
+		if items[i] == item {
+			return append(items[:i], items[i+1:]...)
 		}
 	}
-	if removeAt != -1 {
-		initializers = append(initializers[:removeAt], initializers[removeAt+1:]...)
-	}
-	return initializers
+	return items
 }
 
 // InitializerForType determines the identifier for the implicit initializer associated with the ClusterWorkspaceType.
@@ -72,22 +79,27 @@ func InitializerForReference(cwtr tenancyv1alpha1.ClusterWorkspaceTypeReference)
 	return tenancyv1alpha1.ClusterWorkspaceInitializer(cwtr.Path + ":" + string(cwtr.Name))
 }
 
-// TypeFrom determines the ClusterWorkspaceType workspace and name from an initializer name.
-func TypeFrom(initializer tenancyv1alpha1.ClusterWorkspaceInitializer) (logicalcluster.Name, string, error) {
-	separatorIndex := strings.LastIndex(string(initializer), ":")
+// TypeFrom determines the ClusterWorkspaceType workspace and name from an initializer or finalizer name.
+func TypeFrom[T ~string](name T) (logicalcluster.Name, string, error) {
+	separatorIndex := strings.LastIndex(string(name), ":")
 	switch separatorIndex {
 	case -1:
-		return logicalcluster.Name{}, "", fmt.Errorf("expected cluster workspace initializer in form workspace:name, not %q", initializer)
+		return logicalcluster.Name{}, "", fmt.Errorf("expected cluster workspace initializer or finalizer in form workspace:name, not %q", name)
 	default:
-		return logicalcluster.New(string(initializer[:separatorIndex])), tenancyv1alpha1.ObjectName(tenancyv1alpha1.ClusterWorkspaceTypeName(initializer[separatorIndex+1:])), nil
+		return logicalcluster.New(string(name[:separatorIndex])), tenancyv1alpha1.ObjectName(tenancyv1alpha1.ClusterWorkspaceTypeName(name[separatorIndex+1:])), nil
 	}
 }
 
-// InitializerToLabel transforms an initializer into a key-value pair to add to a label set. We use a hash
+// InitializerToLabel transforms an initializer into a key-value pair to add to a label set.
+func InitializerToLabel(initializer tenancyv1alpha1.ClusterWorkspaceInitializer) (string, string) {
+	return ToLabel(tenancyv1alpha1.ClusterWorkspaceInitializerLabelPrefix, string(initializer))
+}
+
+// ToLabel transforms an initializer or finalizer into a key-value pair to add to a label set. We use a hash
 // to create a unique identifier from this information, prefixing the hash in order to create a value which
 // is unlikely to collide, and adding the full hash as a value in order to make it difficult to forge the pair.
-func InitializerToLabel(initializer tenancyv1alpha1.ClusterWorkspaceInitializer) (string, string) {
-	hash := fmt.Sprintf("%x", sha256.Sum224([]byte(initializer)))
-	labelKeyHashLength := validation.LabelValueMaxLength - len(tenancyv1alpha1.ClusterWorkspaceInitializerLabelPrefix)
-	return tenancyv1alpha1.ClusterWorkspaceInitializerLabelPrefix + hash[0:labelKeyHashLength], hash
+func ToLabel(prefix, name string) (string, string) {
+	hash := fmt.Sprintf("%x", sha256.Sum224([]byte(name)))
+	labelKeyHashLength := validation.LabelValueMaxLength - len(prefix)
+	return prefix + hash[0:labelKeyHashLength], hash
 }
@@ -53,3 +53,20 @@ func QualifiedObjectName(obj metav1.Object) string {
 func WorkspaceLabelSelector(name string) string {
 	return fmt.Sprintf("%s=%s", v1beta1.WorkspaceNameLabel, name)
 }
+
+// AppendHistory adds an entry to the lifecycle history of a workspace, dropping
+// the oldest entries beyond v1alpha1.ClusterWorkspaceMaxHistory. The entry is not
+// added if the latest entry already records the same phase, reason and message,
+// such that steps that are reconciled repeatedly, like waiting for initializers or
+// finalizers, are only recorded when they progress.
+func AppendHistory(status *v1alpha1.ClusterWorkspaceStatus, entry v1alpha1.ClusterWorkspaceHistoryEntry) {
+	if n := len(status.History); n > 0 {
+		if last := status.History[n-1]; last.Phase == entry.Phase && last.Reason == entry.Reason && last.Message == entry.Message {
+			return
+		}
+	}
+	status.History = append(status.History, entry)
+	if len(status.History) > v1alpha1.ClusterWorkspaceMaxHistory {
+		status.History = status.History[len(status.History)-v1alpha1.ClusterWorkspaceMaxHistory:]
+	}
+}
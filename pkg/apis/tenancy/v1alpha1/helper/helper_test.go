@@ -17,11 +17,14 @@ limitations under the License.
 package helper
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/kcp-dev/logicalcluster/v2"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
 )
 
 func TestIsValidCluster(t *testing.T) {
@@ -111,3 +114,24 @@ func TestWorkspaceLabelSelector(t *testing.T) {
 		})
 	}
 }
+
+func TestAppendHistory(t *testing.T) {
+	var status v1alpha1.ClusterWorkspaceStatus
+	for i := 0; i < v1alpha1.ClusterWorkspaceMaxHistory+3; i++ {
+		AppendHistory(&status, v1alpha1.ClusterWorkspaceHistoryEntry{Reason: fmt.Sprintf("R%d", i)})
+	}
+	if got := len(status.History); got != v1alpha1.ClusterWorkspaceMaxHistory {
+		t.Fatalf("expected %d entries, got %d", v1alpha1.ClusterWorkspaceMaxHistory, got)
+	}
+	if got := status.History[0].Reason; got != "R3" {
+		t.Errorf("expected the oldest entries to be dropped, got first entry %q", got)
+	}
+	if got := status.History[len(status.History)-1].Reason; got != fmt.Sprintf("R%d", v1alpha1.ClusterWorkspaceMaxHistory+2) {
+		t.Errorf("expected the newest entry last, got %q", got)
+	}
+
+	AppendHistory(&status, v1alpha1.ClusterWorkspaceHistoryEntry{Reason: fmt.Sprintf("R%d", v1alpha1.ClusterWorkspaceMaxHistory+2)})
+	if got := status.History[0].Reason; got != "R3" {
+		t.Errorf("expected a repeated entry not to be added, got first entry %q", got)
+	}
+}
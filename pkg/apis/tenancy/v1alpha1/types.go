@@ -86,6 +86,9 @@ var _ conditions.Setter = &ClusterWorkspace{}
 
 // ClusterWorkspaceSpec holds the desired state of the ClusterWorkspace.
 type ClusterWorkspaceSpec struct {
+	// readOnly rejects all mutating requests to the workspace. It is set while the
+	// workspace is migrated to another shard.
+	//
 	// +optional
 	ReadOnly bool `json:"readOnly,omitempty"`
 
@@ -112,8 +115,82 @@ type ClusterWorkspaceSpec struct {
 	//
 	// +optional
 	Shard *ShardConstraints `json:"shard,omitempty"`
+
+	// clone references a workspace whose content is copied into this workspace
+	// during initialization. Child workspaces, events and objects maintained by
+	// the system are not copied. The clone source is immutable after creation.
+	// The use of a clone source is gated via the admin permission on the
+	// workspaces/content resource of the source workspace.
+	//
+	// +optional
+	Clone *ClusterWorkspaceCloneSource `json:"clone,omitempty"`
+
+	// moveTo moves the workspace, including its content and its child workspaces,
+	// to another parent workspace and name. The workspace is read-only while it is
+	// moved. Afterwards, requests to the previous path are redirected to the new one
+	// for a grace period. The new parent workspace must be stored on the same shard
+	// as the current one. Workspaces containing APIExports that are bound by
+	// APIBindings cannot be moved. The use of a move target is gated via the RBAC
+	// clusterworkspaces create permission in the new parent workspace.
+	//
+	// +optional
+	MoveTo *ClusterWorkspaceMoveTarget `json:"moveTo,omitempty"`
+
+	// quota limits the number of child workspaces, the number of objects and the
+	// storage of the workspace. Limits marked as inherited also apply to all
+	// descendant workspaces. A workspace cannot exceed the inherited limits of
+	// its ancestors by setting its own.
+	//
+	// +optional
+	Quota *ClusterWorkspaceQuota `json:"quota,omitempty"`
+}
+
+// ClusterWorkspaceCloneSource references the workspace a ClusterWorkspace is cloned from.
+type ClusterWorkspaceCloneSource struct {
+	// path is the fully-qualified path to the workspace to copy content from.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern:="^root(:[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$"
+	Path string `json:"path"`
+}
+
+// ClusterWorkspaceMoveTarget is the location a ClusterWorkspace is moved to.
+type ClusterWorkspaceMoveTarget struct {
+	// path is the fully-qualified path the workspace is moved to, i.e. the path
+	// of the new parent workspace joined with the new name of the workspace.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern:="^root(:[a-z0-9]([-a-z0-9]*[a-z0-9])?)*(:[a-z0-9][a-z0-9]([-a-z0-9]*[a-z0-9])?)$"
+	Path string `json:"path"`
+}
+
+// ClusterWorkspaceQuota holds the limits of a ClusterWorkspace.
+type ClusterWorkspaceQuota struct {
+	// hard is the set of limits of the workspace. Supported resources are
+	// "workspaces" for the number of direct child workspaces, "objects" for
+	// the number of objects, and "storage" for the size of the stored objects
+	// in bytes. Events are not counted.
+	//
+	// +optional
+	Hard corev1.ResourceList `json:"hard,omitempty"`
+
+	// inherited applies the limits in hard to each descendant workspace too.
+	//
+	// +optional
+	Inherited bool `json:"inherited,omitempty"`
 }
 
+const (
+	// QuotaResourceWorkspaces is the number of direct child workspaces of a workspace.
+	QuotaResourceWorkspaces corev1.ResourceName = "workspaces"
+	// QuotaResourceObjects is the number of objects stored in a workspace.
+	QuotaResourceObjects corev1.ResourceName = "objects"
+	// QuotaResourceStorage is the size of the objects stored in a workspace in bytes.
+	QuotaResourceStorage corev1.ResourceName = "storage"
+)
+
 type ShardConstraints struct {
 	// name is the name of ClusterWorkspaceShard.
 	//
@@ -202,6 +279,18 @@ type ClusterWorkspaceTypeSpec struct {
 	// +optional
 	Initializer bool `json:"initializer,omitempty"`
 
+	// finalizer determines if this ClusterWorkspaceType has an associated finalizing
+	// controller. These controllers are used to clean up external resources of a
+	// ClusterWorkspace, e.g. DNS entries or cloud accounts, when it is deleted; all
+	// controllers must finish their work before the content of the ClusterWorkspace
+	// is deleted.
+	//
+	// One finalizing controller is supported per ClusterWorkspaceType; the identifier
+	// for this finalizer is built like the one of the initializer, e.g. `root:org:example`.
+	//
+	// +optional
+	Finalizer bool `json:"finalizer,omitempty"`
+
 	// extend is a list of other ClusterWorkspaceTypes whose initializers and limitAllowedChildren
 	// and limitAllowedParents this ClusterWorkspaceType is inheriting. By (transitively) extending
 	// another ClusterWorkspaceType, this ClusterWorkspaceType will be considered as that
@@ -252,6 +341,45 @@ type ClusterWorkspaceTypeSpec struct {
 	// +listMapKey=path
 	// +listMapKey=exportName
 	DefaultAPIBindings []APIExportReference `json:"defaultAPIBindings,omitempty"`
+
+	// templates are ConfigMaps in the workspace of this ClusterWorkspaceType holding
+	// manifests that are created during initialization of workspaces created from
+	// this type. Every data key of a ConfigMap holds one or more YAML documents.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=namespace
+	// +listMapKey=name
+	Templates []ClusterWorkspaceTemplateReference `json:"templates,omitempty"`
+
+	// deletionRetentionPeriod is the time a workspace of this type is retained
+	// in the Deleted phase after being deleted through the workspaces API, before
+	// it is really deleted with all its content. During that period the workspace
+	// can be restored through the undelete subresource. A zero value disables the
+	// retention. If unset, the server-wide default applies. While a retention
+	// applies, ready ClusterWorkspaces of this type can only be deleted through
+	// the workspaces API.
+	//
+	// +optional
+	DeletionRetentionPeriod *metav1.Duration `json:"deletionRetentionPeriod,omitempty"`
+}
+
+// ClusterWorkspaceTemplateReference references a ConfigMap with manifests in the
+// workspace of a ClusterWorkspaceType.
+type ClusterWorkspaceTemplateReference struct {
+	// namespace is the namespace of the ConfigMap.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+
+	// name is the name of the ConfigMap.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
 }
 
 // APIExportReference provides the fields necessary to resolve an APIExport.
@@ -375,6 +503,16 @@ type ClusterWorkspaceInitializer string
 // on a ClusterWorkspaceType to be created.
 const ClusterWorkspaceAPIBindingsInitializer ClusterWorkspaceInitializer = "system:apibindings"
 
+// ClusterWorkspaceCloneInitializer is a special-case initializer that copies the content of the
+// clone source and the templates of the ClusterWorkspaceType into a workspace.
+const ClusterWorkspaceCloneInitializer ClusterWorkspaceInitializer = "system:clone"
+
+// ClusterWorkspaceFinalizer is a unique string corresponding to a cluster workspace
+// finalization controller for the given type of workspaces.
+//
+// +kubebuilder:validation:Pattern:="^root(:[a-z0-9]([-a-z0-9]*[a-z0-9])?)*(:[a-z0-9][a-z0-9]([-a-z0-9]*[a-z0-9])?)$"
+type ClusterWorkspaceFinalizer string
+
 // ClusterWorkspacePhaseType is the type of the current phase of the workspace
 type ClusterWorkspacePhaseType string
 
@@ -382,13 +520,26 @@ const (
 	ClusterWorkspacePhaseScheduling   ClusterWorkspacePhaseType = "Scheduling"
 	ClusterWorkspacePhaseInitializing ClusterWorkspacePhaseType = "Initializing"
 	ClusterWorkspacePhaseReady        ClusterWorkspacePhaseType = "Ready"
+	// ClusterWorkspacePhaseDeleted is the terminal phase of a soft-deleted workspace. It is hidden
+	// from listing, and is deleted with all its content when status.deletion.retainUntil has passed,
+	// unless it is undeleted before.
+	ClusterWorkspacePhaseDeleted ClusterWorkspacePhaseType = "Deleted"
 )
 
 const ExperimentalClusterWorkspaceOwnerAnnotationKey string = "experimental.tenancy.kcp.dev/owner"
 
+const (
+	// ClusterWorkspaceMovedFromAnnotationKey is set on a moved ClusterWorkspace to the path it was
+	// moved from. Requests to that path are redirected to the workspace while the annotation exists.
+	ClusterWorkspaceMovedFromAnnotationKey = "tenancy.kcp.dev/moved-from"
+	// ClusterWorkspaceMovedAtAnnotationKey is set on a moved ClusterWorkspace to the RFC3339 time the
+	// move finished. The redirect annotations are removed after a grace period.
+	ClusterWorkspaceMovedAtAnnotationKey = "tenancy.kcp.dev/moved-at"
+)
+
 // ClusterWorkspaceStatus communicates the observed state of the ClusterWorkspace.
 type ClusterWorkspaceStatus struct {
-	// Phase of the workspace  (Scheduling / Initializing / Ready)
+	// Phase of the workspace  (Scheduling / Initializing / Ready / Deleted)
 	Phase ClusterWorkspacePhaseType `json:"phase,omitempty"`
 
 	// Current processing state of the ClusterWorkspace.
@@ -408,6 +559,23 @@ type ClusterWorkspaceStatus struct {
 	// +optional
 	Location ClusterWorkspaceLocation `json:"location,omitempty"`
 
+	// move is the progress of a move to the path in spec.moveTo.
+	//
+	// +optional
+	Move *ClusterWorkspaceMove `json:"move,omitempty"`
+
+	// quota is the effective quota of the workspace, including the inherited
+	// limits, and the usage periodically measured by the system.
+	//
+	// +optional
+	Quota *ClusterWorkspaceQuotaStatus `json:"quota,omitempty"`
+
+	// deletion is set when the workspace has been soft-deleted, i.e. it is in
+	// the Deleted phase.
+	//
+	// +optional
+	Deletion *ClusterWorkspaceDeletion `json:"deletion,omitempty"`
+
 	// initializers are set on creation by the system and must be cleared
 	// by a controller before the workspace can be used. The workspace will
 	// stay in the phase "Initializing" state until all initializers are cleared.
@@ -417,6 +585,52 @@ type ClusterWorkspaceStatus struct {
 	//
 	// +optional
 	Initializers []ClusterWorkspaceInitializer `json:"initializers,omitempty"`
+
+	// finalizers are set on initialization by the system and must be cleared
+	// by a controller when the workspace is deleted. The content of the workspace
+	// is not deleted until all finalizers are cleared.
+	//
+	// Terminating cluster workspaces are gated via the RBAC
+	// clusterworkspacetypes/finalize verb.
+	//
+	// +optional
+	Finalizers []ClusterWorkspaceFinalizer `json:"finalizers,omitempty"`
+
+	// history is a summary of the latest lifecycle transitions of the workspace,
+	// oldest first. Only the last ClusterWorkspaceMaxHistory entries are kept.
+	//
+	// +optional
+	History []ClusterWorkspaceHistoryEntry `json:"history,omitempty"`
+}
+
+// ClusterWorkspaceMaxHistory is the maximal number of entries in status.history.
+const ClusterWorkspaceMaxHistory = 10
+
+// ClusterWorkspaceHistoryEntry is a summarized lifecycle transition of a ClusterWorkspace.
+type ClusterWorkspaceHistoryEntry struct {
+	// time is when the transition happened.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	Time metav1.Time `json:"time"`
+
+	// phase is the phase the workspace transitioned to.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	Phase ClusterWorkspacePhaseType `json:"phase"`
+
+	// reason is a brief CamelCase reason for the transition.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	Reason string `json:"reason"`
+
+	// message is a human readable description of the transition, e.g. with
+	// the shard, the initializers or the user that caused it.
+	//
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // These are valid conditions of workspace.
@@ -439,6 +653,12 @@ const (
 	// WorkspaceShardValidReasonShardNotFound reason in WorkspaceShardValid condition means that the
 	// referenced ClusterWorkspaceShard object got deleted.
 	WorkspaceShardValidReasonShardNotFound = "ShardNotFound"
+	// WorkspaceShardValidReasonShardUnschedulable reason in WorkspaceShardValid condition means that the
+	// referenced ClusterWorkspaceShard is cordoned, i.e. spec.unschedulable is set.
+	WorkspaceShardValidReasonShardUnschedulable = "ShardUnschedulable"
+	// WorkspaceShardValidReasonShardDraining reason in WorkspaceShardValid condition means that the
+	// referenced ClusterWorkspaceShard is drained, i.e. spec.evictAfter is set.
+	WorkspaceShardValidReasonShardDraining = "ShardDraining"
 
 	// WorkspaceDeletionContentSuccess represents the status that all resources in the workspace is deleting
 	WorkspaceDeletionContentSuccess conditionsv1alpha1.ConditionType = "WorkspaceDeletionContentSuccess"
@@ -446,6 +666,12 @@ const (
 	// WorkspaceContentDeleted represents the status that all resources in the workspace is deleted.
 	WorkspaceContentDeleted conditionsv1alpha1.ConditionType = "WorkspaceContentDeleted"
 
+	// WorkspaceFinalized represents the status that finalization of a deleted workspace has finished.
+	WorkspaceFinalized conditionsv1alpha1.ConditionType = "WorkspaceFinalized"
+	// WorkspaceFinalizedFinalizerExists reason in WorkspaceFinalized condition means that there is at least
+	// one finalizer still left.
+	WorkspaceFinalizedFinalizerExists = "FinalizerExists"
+
 	// WorkspaceInitialized represents the status that initialization has finished.
 	WorkspaceInitialized conditionsv1alpha1.ConditionType = "WorkspaceInitialized"
 	// WorkspaceInitializedInitializerExists reason in WorkspaceInitialized condition means that there is at least
@@ -464,6 +690,44 @@ const (
 	// WorkspaceInitializedAPIBindingErrors is a reason for the APIBindingsInitialized condition that indicates there
 	// were errors trying to initialize APIBindings for the workspace.
 	WorkspaceInitializedAPIBindingErrors = "APIBindingErrors"
+
+	// WorkspaceContentCloned represents the status of copying the clone source and the ClusterWorkspaceType
+	// templates into the workspace.
+	WorkspaceContentCloned conditionsv1alpha1.ConditionType = "ContentCloned"
+	// WorkspaceContentClonedWaitingOnAPIs is a reason for the ContentCloned condition that indicates at least
+	// one object could not be created yet because its API is not available in the workspace.
+	WorkspaceContentClonedWaitingOnAPIs = "WaitingOnAPIs"
+	// WorkspaceContentClonedSourceInvalid is a reason for the ContentCloned condition that indicates the clone
+	// source or a template could not be read.
+	WorkspaceContentClonedSourceInvalid = "SourceInvalid"
+	// WorkspaceContentClonedErrors is a reason for the ContentCloned condition that indicates there were errors
+	// trying to create the copied objects.
+	WorkspaceContentClonedErrors = "CloneErrors"
+
+	// WorkspaceMigrated represents the status of the migration of the workspace to the shard in status.location.target.
+	WorkspaceMigrated conditionsv1alpha1.ConditionType = "WorkspaceMigrated"
+	// WorkspaceMigratedReasonMigrating reason in WorkspaceMigrated condition means that the workspace is
+	// read-only while its data is copied to the target shard.
+	WorkspaceMigratedReasonMigrating = "Migrating"
+	// WorkspaceMigratedReasonTargetShardNotFound reason in WorkspaceMigrated condition means that the
+	// target shard does not exist.
+	WorkspaceMigratedReasonTargetShardNotFound = "TargetShardNotFound"
+	// WorkspaceMigratedReasonCopyFailed reason in WorkspaceMigrated condition means that copying the data
+	// of the workspace to the target shard failed. It is retried.
+	WorkspaceMigratedReasonCopyFailed = "CopyFailed"
+
+	// WorkspaceMoved represents the status of the move of the workspace to the path in spec.moveTo.
+	WorkspaceMoved conditionsv1alpha1.ConditionType = "WorkspaceMoved"
+	// WorkspaceMovedReasonMoving reason in WorkspaceMoved condition means that the workspace is
+	// read-only while its storage is renamed to the new path.
+	WorkspaceMovedReasonMoving = "Moving"
+	// WorkspaceMovedReasonTargetInvalid reason in WorkspaceMoved condition means that the workspace
+	// cannot be moved to the path in spec.moveTo, e.g. because the new parent does not exist or
+	// the new path is taken.
+	WorkspaceMovedReasonTargetInvalid = "TargetInvalid"
+	// WorkspaceMovedReasonRenameFailed reason in WorkspaceMoved condition means that renaming the
+	// storage of the workspace failed. It is retried.
+	WorkspaceMovedReasonRenameFailed = "RenameFailed"
 )
 
 // ClusterWorkspaceLocation specifies workspace placement information, including current, desired (target), and
@@ -474,11 +738,149 @@ type ClusterWorkspaceLocation struct {
 	// +optional
 	Current string `json:"current,omitempty"`
 
-	// Target workspace placement (shard).
+	// Target workspace placement (shard). If set to a shard other than the current one,
+	// the workspace is made read-only, its data is copied to the target shard, and
+	// requests are routed to the target shard afterwards.
 	//
 	// +optional
-	// +kubebuilder:validation:Enum=""
 	Target string `json:"target,omitempty"`
+
+	// migration is the progress of a migration to the target shard.
+	//
+	// +optional
+	Migration *ClusterWorkspaceMigration `json:"migration,omitempty"`
+}
+
+// ClusterWorkspaceMigrationPhaseType is the type of the current phase of a workspace migration.
+//
+// +kubebuilder:validation:Enum=Freezing;Unfreezing
+type ClusterWorkspaceMigrationPhaseType string
+
+const (
+	// ClusterWorkspaceMigrationPhaseFreezing means that the workspace is made read-only and its data is
+	// copied to the target shard.
+	ClusterWorkspaceMigrationPhaseFreezing ClusterWorkspaceMigrationPhaseType = "Freezing"
+	// ClusterWorkspaceMigrationPhaseUnfreezing means that requests are routed to the target shard, and the
+	// data on the source shard is removed before the workspace is made writable again.
+	ClusterWorkspaceMigrationPhaseUnfreezing ClusterWorkspaceMigrationPhaseType = "Unfreezing"
+)
+
+// ClusterWorkspaceMigration is the progress of a workspace migration between shards.
+type ClusterWorkspaceMigration struct {
+	// phase is the current phase of the migration.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	Phase ClusterWorkspaceMigrationPhaseType `json:"phase"`
+
+	// source is the shard the workspace is migrated from.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Source string `json:"source"`
+
+	// startTime is the time the migration started.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	StartTime metav1.Time `json:"startTime"`
+
+	// copiedKeys is the number of storage keys copied to the target shard.
+	//
+	// +optional
+	CopiedKeys int64 `json:"copiedKeys,omitempty"`
+
+	// wasReadOnly is the value of spec.readOnly before the migration, restored
+	// when the migration finishes.
+	//
+	// +optional
+	WasReadOnly bool `json:"wasReadOnly,omitempty"`
+}
+
+// ClusterWorkspaceQuotaStatus is the effective quota and the usage of a ClusterWorkspace.
+type ClusterWorkspaceQuotaStatus struct {
+	// hard is the effective set of limits of the workspace, i.e. the lowest of its
+	// own and its inherited limits.
+	//
+	// +optional
+	Hard corev1.ResourceList `json:"hard,omitempty"`
+
+	// used is the usage of the resources in hard.
+	//
+	// +optional
+	Used corev1.ResourceList `json:"used,omitempty"`
+
+	// lastMeasureTime is the time used was measured.
+	//
+	// +optional
+	LastMeasureTime *metav1.Time `json:"lastMeasureTime,omitempty"`
+}
+
+// ClusterWorkspaceDeletion describes the retention of a soft-deleted ClusterWorkspace.
+type ClusterWorkspaceDeletion struct {
+	// deletionTime is the time the workspace was deleted.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	DeletionTime metav1.Time `json:"deletionTime"`
+
+	// retainUntil is the time after which the workspace and its content are
+	// deleted for good. Until then the workspace can be undeleted.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	RetainUntil metav1.Time `json:"retainUntil"`
+}
+
+// ClusterWorkspaceMovePhaseType is the type of the current phase of a workspace move.
+//
+// +kubebuilder:validation:Enum=Freezing;Renaming;Unfreezing
+type ClusterWorkspaceMovePhaseType string
+
+const (
+	// ClusterWorkspaceMovePhaseFreezing means that the workspace is made read-only before it is moved.
+	ClusterWorkspaceMovePhaseFreezing ClusterWorkspaceMovePhaseType = "Freezing"
+	// ClusterWorkspaceMovePhaseRenaming means that the storage of the workspace and of its child
+	// workspaces is renamed to the new path on all shards.
+	ClusterWorkspaceMovePhaseRenaming ClusterWorkspaceMovePhaseType = "Renaming"
+	// ClusterWorkspaceMovePhaseUnfreezing means that the workspace is made writable again.
+	ClusterWorkspaceMovePhaseUnfreezing ClusterWorkspaceMovePhaseType = "Unfreezing"
+)
+
+// ClusterWorkspaceMove is the progress of a workspace move to another parent or name.
+type ClusterWorkspaceMove struct {
+	// phase is the current phase of the move.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	Phase ClusterWorkspaceMovePhaseType `json:"phase"`
+
+	// from is the fully-qualified path the workspace is moved from.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	From string `json:"from"`
+
+	// to is the fully-qualified path the workspace is moved to.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	To string `json:"to"`
+
+	// startTime is the time the move started.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	StartTime metav1.Time `json:"startTime"`
+
+	// wasReadOnly is the value of spec.readOnly before the move, restored
+	// when the move finishes.
+	//
+	// +optional
+	WasReadOnly bool `json:"wasReadOnly,omitempty"`
 }
 
 // ClusterWorkspaceList is a list of ClusterWorkspace resources
@@ -566,19 +968,53 @@ type ClusterWorkspaceShardSpec struct {
 	// +kubebuilder:validation:Format=uri
 	// +kubebuilder:validation:MinLength=1
 	VirtualWorkspaceURL string `json:"virtualWorkspaceURL,omitempty"`
+
+	// unschedulable controls whether new workspaces are scheduled onto this shard.
+	// Existing workspaces are not affected. By default, the shard is schedulable.
+	//
+	// +optional
+	Unschedulable bool `json:"unschedulable,omitempty"`
+
+	// evictAfter starts draining the shard at the given time, i.e. every workspace
+	// scheduled onto the shard is migrated to another shard by setting its
+	// status.location.target. The shard should be unschedulable while draining.
+	// By default, workspaces are not evicted.
+	//
+	// +optional
+	EvictAfter *metav1.Time `json:"evictAfter,omitempty"`
 }
 
 // ClusterWorkspaceShardStatus communicates the observed state of the ClusterWorkspaceShard.
 type ClusterWorkspaceShardStatus struct {
-	// Set of integer resources that workspaces can be scheduled into
+	// Set of integer resources that workspaces can be scheduled into. A shard whose usage
+	// reaches its capacity in any resource does not get new workspaces.
+	//
+	// The shard reports the resources workspaces, objects and database-size.
+	//
 	// +optional
 	Capacity corev1.ResourceList `json:"capacity,omitempty"`
 
+	// usage is the amount of resources in use on the shard, periodically reported by the shard.
+	// It holds the same resources as capacity.
+	//
+	// +optional
+	Usage corev1.ResourceList `json:"usage,omitempty"`
+
 	// Current processing state of the ClusterWorkspaceShard.
 	// +optional
 	Conditions conditionsv1alpha1.Conditions `json:"conditions,omitempty"`
 }
 
+const (
+	// ShardResourceWorkspaces is the number of workspaces stored on a shard, i.e. of the
+	// logical clusters below root. System logical clusters are not counted.
+	ShardResourceWorkspaces corev1.ResourceName = "workspaces"
+	// ShardResourceObjects is the number of etcd keys stored on a shard.
+	ShardResourceObjects corev1.ResourceName = "objects"
+	// ShardResourceDatabaseSize is the size of the etcd database of a shard in bytes.
+	ShardResourceDatabaseSize corev1.ResourceName = "database-size"
+)
+
 // ClusterWorkspaceShardList is a list of workspace shards
 //
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -597,6 +1033,10 @@ const (
 	// and the set of labels with this prefix is enforced to match the set of initializers by a mutating admission
 	// webhook.
 	ClusterWorkspaceInitializerLabelPrefix = "initializer.internal.kcp.dev/"
+	// ClusterWorkspaceFinalizerLabelPrefix is the prefix for labels which match ClusterWorkspace.Status.Finalizers
+	// while the workspace is being deleted, and the set of labels with this prefix is enforced to match the set
+	// of finalizers of a terminating workspace by the workspace controller.
+	ClusterWorkspaceFinalizerLabelPrefix = "finalizer.internal.kcp.dev/"
 )
 
 const (
@@ -73,6 +73,57 @@ func (in *ClusterWorkspace) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterWorkspaceCloneSource) DeepCopyInto(out *ClusterWorkspaceCloneSource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterWorkspaceCloneSource.
+func (in *ClusterWorkspaceCloneSource) DeepCopy() *ClusterWorkspaceCloneSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterWorkspaceCloneSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterWorkspaceDeletion) DeepCopyInto(out *ClusterWorkspaceDeletion) {
+	*out = *in
+	in.DeletionTime.DeepCopyInto(&out.DeletionTime)
+	in.RetainUntil.DeepCopyInto(&out.RetainUntil)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterWorkspaceDeletion.
+func (in *ClusterWorkspaceDeletion) DeepCopy() *ClusterWorkspaceDeletion {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterWorkspaceDeletion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterWorkspaceHistoryEntry) DeepCopyInto(out *ClusterWorkspaceHistoryEntry) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterWorkspaceHistoryEntry.
+func (in *ClusterWorkspaceHistoryEntry) DeepCopy() *ClusterWorkspaceHistoryEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterWorkspaceHistoryEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterWorkspaceList) DeepCopyInto(out *ClusterWorkspaceList) {
 	*out = *in
@@ -109,6 +160,11 @@ func (in *ClusterWorkspaceList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterWorkspaceLocation) DeepCopyInto(out *ClusterWorkspaceLocation) {
 	*out = *in
+	if in.Migration != nil {
+		in, out := &in.Migration, &out.Migration
+		*out = new(ClusterWorkspaceMigration)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -122,12 +178,119 @@ func (in *ClusterWorkspaceLocation) DeepCopy() *ClusterWorkspaceLocation {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterWorkspaceMigration) DeepCopyInto(out *ClusterWorkspaceMigration) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterWorkspaceMigration.
+func (in *ClusterWorkspaceMigration) DeepCopy() *ClusterWorkspaceMigration {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterWorkspaceMigration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterWorkspaceMove) DeepCopyInto(out *ClusterWorkspaceMove) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterWorkspaceMove.
+func (in *ClusterWorkspaceMove) DeepCopy() *ClusterWorkspaceMove {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterWorkspaceMove)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterWorkspaceMoveTarget) DeepCopyInto(out *ClusterWorkspaceMoveTarget) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterWorkspaceMoveTarget.
+func (in *ClusterWorkspaceMoveTarget) DeepCopy() *ClusterWorkspaceMoveTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterWorkspaceMoveTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterWorkspaceQuota) DeepCopyInto(out *ClusterWorkspaceQuota) {
+	*out = *in
+	if in.Hard != nil {
+		in, out := &in.Hard, &out.Hard
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterWorkspaceQuota.
+func (in *ClusterWorkspaceQuota) DeepCopy() *ClusterWorkspaceQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterWorkspaceQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterWorkspaceQuotaStatus) DeepCopyInto(out *ClusterWorkspaceQuotaStatus) {
+	*out = *in
+	if in.Hard != nil {
+		in, out := &in.Hard, &out.Hard
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Used != nil {
+		in, out := &in.Used, &out.Used
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.LastMeasureTime != nil {
+		in, out := &in.LastMeasureTime, &out.LastMeasureTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterWorkspaceQuotaStatus.
+func (in *ClusterWorkspaceQuotaStatus) DeepCopy() *ClusterWorkspaceQuotaStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterWorkspaceQuotaStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterWorkspaceShard) DeepCopyInto(out *ClusterWorkspaceShard) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 	return
 }
@@ -186,6 +349,10 @@ func (in *ClusterWorkspaceShardList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterWorkspaceShardSpec) DeepCopyInto(out *ClusterWorkspaceShardSpec) {
 	*out = *in
+	if in.EvictAfter != nil {
+		in, out := &in.EvictAfter, &out.EvictAfter
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
@@ -209,6 +376,13 @@ func (in *ClusterWorkspaceShardStatus) DeepCopyInto(out *ClusterWorkspaceShardSt
 			(*out)[key] = val.DeepCopy()
 		}
 	}
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make(conditionsv1alpha1.Conditions, len(*in))
@@ -238,6 +412,21 @@ func (in *ClusterWorkspaceSpec) DeepCopyInto(out *ClusterWorkspaceSpec) {
 		*out = new(ShardConstraints)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Clone != nil {
+		in, out := &in.Clone, &out.Clone
+		*out = new(ClusterWorkspaceCloneSource)
+		**out = **in
+	}
+	if in.MoveTo != nil {
+		in, out := &in.MoveTo, &out.MoveTo
+		*out = new(ClusterWorkspaceMoveTarget)
+		**out = **in
+	}
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = new(ClusterWorkspaceQuota)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -261,12 +450,39 @@ func (in *ClusterWorkspaceStatus) DeepCopyInto(out *ClusterWorkspaceStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	out.Location = in.Location
+	in.Location.DeepCopyInto(&out.Location)
+	if in.Move != nil {
+		in, out := &in.Move, &out.Move
+		*out = new(ClusterWorkspaceMove)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = new(ClusterWorkspaceQuotaStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Deletion != nil {
+		in, out := &in.Deletion, &out.Deletion
+		*out = new(ClusterWorkspaceDeletion)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Initializers != nil {
 		in, out := &in.Initializers, &out.Initializers
 		*out = make([]ClusterWorkspaceInitializer, len(*in))
 		copy(*out, *in)
 	}
+	if in.Finalizers != nil {
+		in, out := &in.Finalizers, &out.Finalizers
+		*out = make([]ClusterWorkspaceFinalizer, len(*in))
+		copy(*out, *in)
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]ClusterWorkspaceHistoryEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -280,6 +496,22 @@ func (in *ClusterWorkspaceStatus) DeepCopy() *ClusterWorkspaceStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterWorkspaceTemplateReference) DeepCopyInto(out *ClusterWorkspaceTemplateReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterWorkspaceTemplateReference.
+func (in *ClusterWorkspaceTemplateReference) DeepCopy() *ClusterWorkspaceTemplateReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterWorkspaceTemplateReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterWorkspaceType) DeepCopyInto(out *ClusterWorkspaceType) {
 	*out = *in
@@ -430,6 +662,16 @@ func (in *ClusterWorkspaceTypeSpec) DeepCopyInto(out *ClusterWorkspaceTypeSpec)
 		*out = make([]APIExportReference, len(*in))
 		copy(*out, *in)
 	}
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = make([]ClusterWorkspaceTemplateReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeletionRetentionPeriod != nil {
+		in, out := &in.DeletionRetentionPeriod, &out.DeletionRetentionPeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 	return
 }
 
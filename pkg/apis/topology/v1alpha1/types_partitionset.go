@@ -18,6 +18,8 @@ package v1alpha1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
 )
 
 // +crd
@@ -66,6 +68,35 @@ type PartitionSetSpec struct {
 type PartitionSetStatus struct {
 	// count is the total number of partitions.
 	Count uint16 `json:"count,omitempty"`
+
+	// +optional
+
+	// conditions is a list of conditions that apply to the PartitionSet.
+	Conditions conditionsv1alpha1.Conditions `json:"conditions,omitempty"`
+}
+
+// These are valid conditions of PartitionSet.
+const (
+	// PartitionSetValid reflects the validity of the PartitionSet spec.
+	PartitionSetValid conditionsv1alpha1.ConditionType = "PartitionSetValid"
+
+	// PartitionSetInvalidSelectorReason indicates that the shard selector of the PartitionSet is invalid.
+	PartitionSetInvalidSelectorReason = "PartitionSetInvalidSelector"
+
+	// PartitionsReady indicates that the Partitions of the PartitionSet have been created, updated and deleted
+	// according to the shards matching the dimensions.
+	PartitionsReady conditionsv1alpha1.ConditionType = "PartitionsReady"
+
+	// ErrorGeneratingPartitionsReason indicates that the Partitions could not be reconciled.
+	ErrorGeneratingPartitionsReason = "ErrorGeneratingPartitions"
+)
+
+func (in *PartitionSet) GetConditions() conditionsv1alpha1.Conditions {
+	return in.Status.Conditions
+}
+
+func (in *PartitionSet) SetConditions(conditions conditionsv1alpha1.Conditions) {
+	in.Status.Conditions = conditions
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -19,6 +19,7 @@ package v1alpha1
 import (
 	"crypto/sha256"
 	"math/big"
+	"strings"
 
 	"github.com/kcp-dev/logicalcluster/v2"
 )
@@ -31,6 +32,21 @@ func ToSyncTargetKey(syncTargetWorkspace logicalcluster.Name, syncTargetName str
 	return base62hash
 }
 
+// ParseSyncTargetKeys returns the SyncTarget keys in the comma separated value of the
+// internal.workload.kcp.dev/synctarget annotation of a placement.
+func ParseSyncTargetKeys(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// FormatSyncTargetKeys returns the value of the internal.workload.kcp.dev/synctarget annotation
+// of a placement scheduled to the given SyncTarget keys.
+func FormatSyncTargetKeys(keys []string) string {
+	return strings.Join(keys, ",")
+}
+
 func toBase62(hash [28]byte) string {
 	var i big.Int
 	i.SetBytes(hash[:])
@@ -93,6 +93,11 @@ type SyncTargetStatus struct {
 	// +optional
 	Capacity *corev1.ResourceList `json:"capacity,omitempty"`
 
+	// Requested represents the resources requested by the pods running on the nodes of the cluster.
+	// Allocatable minus Requested is available for new workloads.
+	// +optional
+	Requested *corev1.ResourceList `json:"requested,omitempty"`
+
 	// Current processing state of the SyncTarget.
 	// +optional
 	Conditions conditionsv1alpha1.Conditions `json:"conditions,omitempty"`
@@ -181,8 +186,17 @@ const (
 	// SyncerAuthorized means the syncer is authorized to sync resources to downstream cluster.
 	SyncerAuthorized conditionsv1alpha1.ConditionType = "SyncerAuthorized"
 
+	// FailedOver means the SyncTarget has been evicted because its heartbeat has been missing for longer than
+	// the failover grace period. It is false while no other SyncTarget of its Locations can take over its
+	// workloads, and is removed once the heartbeat is healthy again.
+	FailedOver conditionsv1alpha1.ConditionType = "FailedOver"
+
 	// ErrorHeartbeatMissedReason indicates that a heartbeat update was not received within the configured threshold.
 	ErrorHeartbeatMissedReason = "ErrorHeartbeat"
+
+	// NoAlternativeSyncTargetReason indicates that no other ready, non-evicting SyncTarget of the Locations of the
+	// SyncTarget supports the APIs synced to it.
+	NoAlternativeSyncTargetReason = "NoAlternativeSyncTarget"
 )
 
 func (in *SyncTarget) SetConditions(conditions conditionsv1alpha1.Conditions) {
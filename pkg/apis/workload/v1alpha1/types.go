@@ -148,14 +148,21 @@ const (
 	// has been created already. If the created default resource is deleted, it will not be recreated.
 	AnnotationSkipDefaultObjectCreation = "workload.kcp.dev/skip-default-object-creation"
 
-	// InternalSyncTargetPlacementAnnotationKey is a internal annotation key on placement API to mark the synctarget scheduled
-	// from this placement. The value is a hash of the SyncTarget workspace + SyncTarget name, generated with the ToSyncTargetKey(..) helper func.
+	// InternalSyncTargetPlacementAnnotationKey is a internal annotation key on placement API to mark the synctargets scheduled
+	// from this placement. The value is a comma separated list of hashes of the SyncTarget workspace + SyncTarget name, generated
+	// with the ToSyncTargetKey(..) helper func. Use ParseSyncTargetKeys(..) to read it.
 	InternalSyncTargetPlacementAnnotationKey = "internal.workload.kcp.dev/synctarget"
 
 	// InternalSyncTargetKeyLabel is an internal label set on a SyncTarget resource that contains the full hash of the SyncTargetKey, generated with the ToSyncTargetKey(..)
 	// helper func, this label is used for reverse lookups of a syncTargetKey to SyncTarget.
 	InternalSyncTargetKeyLabel = "internal.workload.kcp.dev/key"
 
+	// InternalSyncTargetFailoverAnnotationKey is an internal annotation key set on a SyncTarget by the failover
+	// controller when it sets spec.evictAfter because the heartbeat of the syncer has been missing for longer than
+	// the failover grace period. The value is the evictAfter timestamp in RFC3339 format. It is removed, together
+	// with evictAfter if unchanged, when the heartbeat is healthy again.
+	InternalSyncTargetFailoverAnnotationKey = "internal.workload.kcp.dev/failover"
+
 	// ComputeAPIExportAnnotationKey is an annotation key set on an APIExport when it will be used for compute,
 	// and its APIs are expected to be synced to a SyncTarget by the Syncer. The annotation will be continuously
 	// synced from the APIExport to all the APIBindings bound to this APIExport. The workload scheduler will
@@ -172,6 +172,17 @@ func (in *SyncTargetStatus) DeepCopyInto(out *SyncTargetStatus) {
 			}
 		}
 	}
+	if in.Requested != nil {
+		in, out := &in.Requested, &out.Requested
+		*out = new(v1.ResourceList)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make(map[v1.ResourceName]resource.Quantity, len(*in))
+			for key, val := range *in {
+				(*out)[key] = val.DeepCopy()
+			}
+		}
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make(conditionsv1alpha1.Conditions, len(*in))
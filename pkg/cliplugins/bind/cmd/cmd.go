@@ -30,6 +30,9 @@ var (
 	bindExampleUses = `
 	# Create an APIBinding named "my-binding" that binds to the APIExport "my-export" in the "root:my-service" workspace.
 	%[1]s bind apiexport root:my-service:my-export --name my-binding
+
+	# Show the resources that would be bound, conflicts and permission claims needing acceptance, without binding.
+	%[1]s bind apiexport root:my-service:my-export --dry-run
 	`
 
 	bindComputeExampleUses = `
@@ -18,8 +18,10 @@ package plugin
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/url"
 	"strings"
 	"time"
@@ -28,8 +30,10 @@ import (
 	"github.com/spf13/cobra"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
 	"k8s.io/client-go/rest"
 
 	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
@@ -48,6 +52,9 @@ type BindOptions struct {
 	APIBindingName string
 	// BindWaitTimeout is how long to wait for the APIBinding to be created and successful.
 	BindWaitTimeout time.Duration
+	// DryRun previews the resources that would be bound, the conflicts and the permission claims
+	// needing acceptance, without creating the APIBinding.
+	DryRun bool
 }
 
 // NewBindOptions returns new BindOptions.
@@ -63,6 +70,7 @@ func (b *BindOptions) BindFlags(cmd *cobra.Command) {
 
 	cmd.Flags().StringVar(&b.APIBindingName, "name", b.APIBindingName, "Name of the APIBinding to create.")
 	cmd.Flags().DurationVar(&b.BindWaitTimeout, "timeout", time.Second*30, "Duration to wait for APIBinding to be created successfully.")
+	cmd.Flags().BoolVar(&b.DryRun, "dry-run", b.DryRun, "Show the resources that would be bound, conflicts and permission claims needing acceptance, without creating the APIBinding.")
 }
 
 // Complete ensures all fields are initialized.
@@ -129,6 +137,22 @@ func (b *BindOptions) Run(ctx context.Context) error {
 		return err
 	}
 
+	if b.DryRun {
+		previewBinding, err := kcpclient.Cluster(currentClusterName).ApisV1alpha1().APIBindings().Create(ctx, binding, metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}})
+		if err != nil {
+			return err
+		}
+		value, found := previewBinding.Annotations[apisv1alpha1.AnnotationDryRunResultKey]
+		if !found {
+			return fmt.Errorf("server did not return a dry-run result for apibinding %s", binding.Name)
+		}
+		var result apisv1alpha1.APIBindingDryRunResult
+		if err := json.Unmarshal([]byte(value), &result); err != nil {
+			return fmt.Errorf("error decoding dry-run result for apibinding %s: %w", binding.Name, err)
+		}
+		return printDryRunResult(b.Out, &result)
+	}
+
 	createdBinding, err := kcpclient.Cluster(currentClusterName).ApisV1alpha1().APIBindings().Create(ctx, binding, metav1.CreateOptions{})
 	if err != nil {
 		return err
@@ -161,6 +185,43 @@ func (b *BindOptions) Run(ctx context.Context) error {
 	return nil
 }
 
+// printDryRunResult prints the resources and permission claims of a dry-run result as tables.
+func printDryRunResult(w io.Writer, result *apisv1alpha1.APIBindingDryRunResult) error {
+	out := printers.GetNewTabWriter(w)
+
+	if _, err := fmt.Fprintf(out, "RESOURCE\tSCHEMA\tRESULT\tMESSAGE\n"); err != nil {
+		return err
+	}
+	for _, r := range result.BoundResources {
+		if _, err := fmt.Fprintf(out, "%s\t%s\t%s\t%s\n", schema.GroupResource{Group: r.Group, Resource: r.Resource}.String(), r.Schema.Name, "OK", ""); err != nil {
+			return err
+		}
+	}
+	for _, c := range result.Conflicts {
+		if _, err := fmt.Fprintf(out, "%s\t%s\t%s\t%s\n", schema.GroupResource{Group: c.Group, Resource: c.Resource}.String(), "", c.Reason, c.Message); err != nil {
+			return err
+		}
+	}
+
+	if len(result.PendingPermissionClaims) > 0 || len(result.InvalidPermissionClaims) > 0 {
+		if _, err := fmt.Fprintf(out, "\nPERMISSION CLAIM\tSTATE\n"); err != nil {
+			return err
+		}
+		for _, c := range result.PendingPermissionClaims {
+			if _, err := fmt.Fprintf(out, "%s\t%s\n", c.String(), "NeedsAcceptance"); err != nil {
+				return err
+			}
+		}
+		for _, c := range result.InvalidPermissionClaims {
+			if _, err := fmt.Fprintf(out, "%s\t%s\n", c.String(), "NotClaimed"); err != nil {
+				return err
+			}
+		}
+	}
+
+	return out.Flush()
+}
+
 func newKCPClusterClient(config *rest.Config) (kcpclientset.ClusterInterface, error) {
 	clusterConfig := rest.CopyConfig(config)
 	u, err := url.Parse(config.Host)
@@ -202,7 +202,8 @@ func bindReady(bindings []*apisv1alpha1.APIBinding, placement *schedulingv1alpha
 			} else if conditions.IsFalse(binding, apisv1alpha1.APIExportValid) {
 				conditionMessage = conditions.GetMessage(binding, apisv1alpha1.APIExportValid)
 			}
-			return false, fmt.Sprintf("not bound to apiexport '%s:%s': %s", binding.Spec.Reference.Workspace.Path, binding.Spec.Reference.Workspace.ExportName, conditionMessage)
+			exportClusterName, exportName, _ := apisv1alpha1.ReferencedAPIExport(binding)
+			return false, fmt.Sprintf("not bound to apiexport '%s:%s': %s", exportClusterName, exportName, conditionMessage)
 		}
 	}
 
@@ -231,10 +232,11 @@ func (o *BindComputeOptions) applyAPIBinding(ctx context.Context, client kcpclie
 
 	existingAPIExports := sets.NewString()
 	for _, binding := range apiBindings.Items {
-		if binding.Spec.Reference.Workspace == nil {
+		exportClusterName, exportName, ok := apisv1alpha1.ReferencedAPIExport(&binding)
+		if !ok {
 			continue
 		}
-		existingAPIExports.Insert(fmt.Sprintf("%s:%s", binding.Spec.Reference.Workspace.Path, binding.Spec.Reference.Workspace.ExportName))
+		existingAPIExports.Insert(fmt.Sprintf("%s:%s", exportClusterName, exportName))
 	}
 
 	var errs []error
@@ -0,0 +1,54 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+)
+
+func TestPrintDryRunResult(t *testing.T) {
+	result := &apisv1alpha1.APIBindingDryRunResult{
+		BoundResources: []apisv1alpha1.BoundAPIResource{
+			{Group: "kcp.dev", Resource: "widgets", Schema: apisv1alpha1.BoundAPIResourceSchema{Name: "today.widgets.kcp.dev"}},
+		},
+		Conflicts: []apisv1alpha1.APIBindingConflict{
+			{Group: "kcp.dev", Resource: "gadgets", Reason: apisv1alpha1.NamingConflictsReason, Message: "naming conflict with a bound API other"},
+		},
+		PendingPermissionClaims: []apisv1alpha1.PermissionClaim{
+			{GroupResource: apisv1alpha1.GroupResource{Resource: "configmaps"}},
+		},
+		InvalidPermissionClaims: []apisv1alpha1.PermissionClaim{
+			{GroupResource: apisv1alpha1.GroupResource{Resource: "secrets"}},
+		},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, printDryRunResult(&out, result))
+	require.Equal(t, `RESOURCE          SCHEMA                  RESULT            MESSAGE
+widgets.kcp.dev   today.widgets.kcp.dev   OK                
+gadgets.kcp.dev                           NamingConflicts   naming conflict with a bound API other
+
+PERMISSION CLAIM   STATE
+configmaps         NeedsAcceptance
+secrets            NotClaimed
+`, out.String())
+}
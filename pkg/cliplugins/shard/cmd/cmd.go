@@ -0,0 +1,147 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/shard/plugin"
+)
+
+var (
+	cordonExample = `
+	# Mark a shard as unschedulable for new workspaces.
+	%[1]s shard cordon <shard-name>
+`
+	uncordonExample = `
+	# Mark a shard as schedulable, stopping a drain.
+	%[1]s shard uncordon <shard-name>
+`
+	drainExample = `
+	# Migrate all workspaces off a shard in preparation for maintenance, and wait until done.
+	%[1]s shard drain <shard-name>
+
+	# Start draining a shard without waiting.
+	%[1]s shard drain <shard-name> --wait=false
+`
+)
+
+// New provides a cobra command for shard operations.
+func New(streams genericclioptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Aliases:          []string{"shards"},
+		Use:              "shard",
+		Short:            "Manages KCP shards",
+		SilenceUsage:     true,
+		TraverseChildren: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	// Cordon command
+	cordonOpts := plugin.NewCordonOptions(streams)
+	cordonOpts.Cordon = true
+
+	cordonCmd := &cobra.Command{
+		Use:          "cordon <shard-name>",
+		Short:        "Mark shard as unschedulable",
+		Example:      fmt.Sprintf(cordonExample, "kubectl kcp"),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return c.Help()
+			}
+
+			if err := cordonOpts.Complete(args); err != nil {
+				return err
+			}
+
+			if err := cordonOpts.Validate(); err != nil {
+				return err
+			}
+
+			return cordonOpts.Run(c.Context())
+		},
+	}
+
+	cordonOpts.BindFlags(cordonCmd)
+	cmd.AddCommand(cordonCmd)
+
+	// Uncordon command
+	uncordonOpts := plugin.NewCordonOptions(streams)
+	uncordonOpts.Cordon = false
+
+	uncordonCmd := &cobra.Command{
+		Use:          "uncordon <shard-name>",
+		Short:        "Mark shard as schedulable",
+		Example:      fmt.Sprintf(uncordonExample, "kubectl kcp"),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return c.Help()
+			}
+
+			if err := uncordonOpts.Complete(args); err != nil {
+				return err
+			}
+
+			if err := uncordonOpts.Validate(); err != nil {
+				return err
+			}
+
+			return uncordonOpts.Run(c.Context())
+		},
+	}
+
+	uncordonOpts.BindFlags(uncordonCmd)
+	cmd.AddCommand(uncordonCmd)
+
+	// Drain command
+	drainOpts := plugin.NewDrainOptions(streams)
+
+	drainCmd := &cobra.Command{
+		Use:          "drain <shard-name>",
+		Short:        "Migrate all workspaces off the shard in preparation for maintenance",
+		Example:      fmt.Sprintf(drainExample, "kubectl kcp"),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return c.Help()
+			}
+
+			if err := drainOpts.Complete(args); err != nil {
+				return err
+			}
+
+			if err := drainOpts.Validate(); err != nil {
+				return err
+			}
+
+			return drainOpts.Run(c.Context())
+		},
+	}
+
+	drainOpts.BindFlags(drainCmd)
+	cmd.AddCommand(drainCmd)
+
+	return cmd
+}
@@ -0,0 +1,308 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/spf13/cobra"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	kcpclusterclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/cluster"
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	pluginhelpers "github.com/kcp-dev/kcp/pkg/cliplugins/helpers"
+)
+
+// CordonOptions contains options for cordoning or uncordoning a ClusterWorkspaceShard.
+type CordonOptions struct {
+	*base.Options
+
+	// Shard is the name of the ClusterWorkspaceShard to cordon or uncordon.
+	Shard string
+	// Cordon indicates if the ClusterWorkspaceShard should be cordoned (true) or uncordoned (false).
+	Cordon bool
+}
+
+// NewCordonOptions returns a new CordonOptions.
+func NewCordonOptions(streams genericclioptions.IOStreams) *CordonOptions {
+	return &CordonOptions{
+		Options: base.NewOptions(streams),
+	}
+}
+
+// Complete ensures all dynamically populated fields are initialized.
+func (o *CordonOptions) Complete(args []string) error {
+	if err := o.Options.Complete(); err != nil {
+		return err
+	}
+
+	if len(args) > 0 {
+		o.Shard = args[0]
+	}
+
+	return nil
+}
+
+// Validate validates the CordonOptions are complete and usable.
+func (o *CordonOptions) Validate() error {
+	if o.Shard == "" {
+		return errors.New("shard name is required")
+	}
+
+	return nil
+}
+
+// Run cordons the shard and marks it as unschedulable, or uncordons it and stops a drain.
+func (o *CordonOptions) Run(ctx context.Context) error {
+	config, err := o.ClientConfig.ClientConfig()
+	if err != nil {
+		return err
+	}
+
+	kcpClient, err := kcpclient.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create kcp client: %w", err)
+	}
+
+	shard, err := kcpClient.TenancyV1alpha1().ClusterWorkspaceShards().Get(ctx, o.Shard, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get ClusterWorkspaceShard %s: %w", o.Shard, err)
+	}
+
+	// See if there is nothing to do
+	if o.Cordon && shard.Spec.Unschedulable {
+		fmt.Fprintln(o.Out, o.Shard, "already cordoned")
+		return nil
+	} else if !o.Cordon && !shard.Spec.Unschedulable && shard.Spec.EvictAfter == nil {
+		fmt.Fprintln(o.Out, o.Shard, "already uncordoned")
+		return nil
+	}
+
+	patchBytes := []byte(`{"spec":{"unschedulable":true}}`)
+	if !o.Cordon {
+		patchBytes = []byte(`{"spec":{"unschedulable":false,"evictAfter":null}}`)
+	}
+
+	_, err = kcpClient.TenancyV1alpha1().ClusterWorkspaceShards().Patch(ctx, o.Shard, types.MergePatchType, patchBytes, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update ClusterWorkspaceShard %s: %w", o.Shard, err)
+	}
+
+	if o.Cordon {
+		fmt.Fprintln(o.Out, o.Shard, "cordoned")
+	} else {
+		fmt.Fprintln(o.Out, o.Shard, "uncordoned")
+	}
+
+	return nil
+}
+
+// DrainOptions contains options for draining a ClusterWorkspaceShard.
+type DrainOptions struct {
+	*base.Options
+
+	// Shard is the name of the ClusterWorkspaceShard to drain.
+	Shard string
+	// Wait indicates if the command should wait until all workspaces have left the shard.
+	Wait bool
+	// Timeout is the maximum time to wait for the drain to finish. Zero means no timeout.
+	Timeout time.Duration
+	// PollInterval is the interval in which the progress of the drain is checked.
+	PollInterval time.Duration
+}
+
+// NewDrainOptions returns a new DrainOptions.
+func NewDrainOptions(streams genericclioptions.IOStreams) *DrainOptions {
+	return &DrainOptions{
+		Options:      base.NewOptions(streams),
+		Wait:         true,
+		PollInterval: 5 * time.Second,
+	}
+}
+
+// BindFlags binds fields to cmd's flagset.
+func (o *DrainOptions) BindFlags(cmd *cobra.Command) {
+	o.Options.BindFlags(cmd)
+	cmd.Flags().BoolVar(&o.Wait, "wait", o.Wait, "Wait until all workspaces have been migrated off the shard, showing the progress.")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, "Maximum time to wait for the drain to finish. Zero means no timeout.")
+	cmd.Flags().DurationVar(&o.PollInterval, "poll-interval", o.PollInterval, "Interval in which the progress of the drain is checked.")
+}
+
+// Complete ensures all dynamically populated fields are initialized.
+func (o *DrainOptions) Complete(args []string) error {
+	if err := o.Options.Complete(); err != nil {
+		return err
+	}
+
+	if len(args) > 0 {
+		o.Shard = args[0]
+	}
+
+	return nil
+}
+
+// Validate validates the DrainOptions are complete and usable.
+func (o *DrainOptions) Validate() error {
+	if o.Shard == "" {
+		return errors.New("shard name is required")
+	}
+	if o.Timeout < 0 {
+		return errors.New("timeout must not be negative")
+	}
+	if o.PollInterval <= 0 {
+		return errors.New("poll interval must be positive")
+	}
+
+	return nil
+}
+
+// Run drains the shard and marks it as unschedulable. Then it waits for the workspaces
+// to be migrated off the shard.
+func (o *DrainOptions) Run(ctx context.Context) error {
+	config, err := o.ClientConfig.ClientConfig()
+	if err != nil {
+		return err
+	}
+
+	kcpClient, err := kcpclient.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create kcp client: %w", err)
+	}
+
+	shard, err := kcpClient.TenancyV1alpha1().ClusterWorkspaceShards().Get(ctx, o.Shard, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get ClusterWorkspaceShard %s: %w", o.Shard, err)
+	}
+
+	if shard.Spec.EvictAfter != nil && shard.Spec.Unschedulable {
+		fmt.Fprintln(o.Out, o.Shard, "already draining")
+	} else {
+		patchBytes := []byte(`{"spec":{"unschedulable":true,"evictAfter":"` + time.Now().UTC().Format(time.RFC3339) + `"}}`)
+		_, err = kcpClient.TenancyV1alpha1().ClusterWorkspaceShards().Patch(ctx, o.Shard, types.MergePatchType, patchBytes, metav1.PatchOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to update ClusterWorkspaceShard %s: %w", o.Shard, err)
+		}
+		fmt.Fprintln(o.Out, o.Shard, "draining")
+	}
+
+	if !o.Wait {
+		return nil
+	}
+
+	_, currentClusterName, err := pluginhelpers.ParseClusterURL(config.Host)
+	if err != nil {
+		return fmt.Errorf("current config context URL %q does not point to workspace", config.Host)
+	}
+	kcpClusterClient, err := newKCPClusterClient(config)
+	if err != nil {
+		return err
+	}
+	list := func(ctx context.Context, cluster logicalcluster.Name) ([]tenancyv1alpha1.ClusterWorkspace, error) {
+		workspaces, err := kcpClusterClient.Cluster(cluster).TenancyV1alpha1().ClusterWorkspaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return workspaces.Items, nil
+	}
+
+	if o.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.Timeout)
+		defer cancel()
+	}
+
+	var last drainProgress
+	err = wait.PollImmediateInfiniteWithContext(ctx, o.PollInterval, func(ctx context.Context) (bool, error) {
+		progress, err := workspacesOnShard(ctx, list, currentClusterName, o.Shard)
+		if err != nil {
+			return false, err
+		}
+		if progress != last {
+			fmt.Fprintf(o.Out, "%s: %d workspaces remaining, %d migrating\n", o.Shard, progress.remaining, progress.migrating)
+			last = progress
+		}
+		return progress.remaining == 0, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to wait for ClusterWorkspaceShard %s to be drained: %w", o.Shard, err)
+	}
+
+	fmt.Fprintln(o.Out, o.Shard, "drained")
+
+	return nil
+}
+
+type drainProgress struct {
+	remaining, migrating int
+}
+
+// workspacesOnShard walks the workspace tree below the given cluster and counts the
+// workspaces scheduled onto the given shard, and how many of them are migrating.
+func workspacesOnShard(ctx context.Context, list func(ctx context.Context, cluster logicalcluster.Name) ([]tenancyv1alpha1.ClusterWorkspace, error), cluster logicalcluster.Name, shard string) (drainProgress, error) {
+	var progress drainProgress
+
+	workspaces, err := list(ctx, cluster)
+	if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+		// not initialized yet or not a workspace we can see into
+		return progress, nil
+	} else if err != nil {
+		return progress, err
+	}
+
+	for _, ws := range workspaces {
+		if ws.Status.Location.Current == shard {
+			progress.remaining++
+			if ws.Status.Location.Target != "" {
+				progress.migrating++
+			}
+		}
+
+		children, err := workspacesOnShard(ctx, list, cluster.Join(ws.Name), shard)
+		if err != nil {
+			return progress, err
+		}
+		progress.remaining += children.remaining
+		progress.migrating += children.migrating
+	}
+
+	return progress, nil
+}
+
+func newKCPClusterClient(config *rest.Config) (kcpclusterclient.ClusterInterface, error) {
+	clusterConfig := rest.CopyConfig(config)
+	u, err := url.Parse(config.Host)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = ""
+	clusterConfig.Host = u.String()
+	clusterConfig.UserAgent = rest.DefaultKubernetesUserAgent()
+	return kcpclusterclient.NewForConfig(clusterConfig)
+}
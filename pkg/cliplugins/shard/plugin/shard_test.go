@@ -0,0 +1,74 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+func TestWorkspacesOnShard(t *testing.T) {
+	tree := map[string][]tenancyv1alpha1.ClusterWorkspace{
+		"root": {
+			workspace("org", "alpha", ""),
+			workspace("other", "beta", ""),
+		},
+		"root:org": {
+			workspace("team-a", "alpha", "beta"),
+			workspace("team-b", "beta", ""),
+			workspace("team-c", "alpha", ""),
+		},
+		"root:other": {
+			workspace("team-d", "alpha", ""),
+		},
+	}
+	list := func(ctx context.Context, cluster logicalcluster.Name) ([]tenancyv1alpha1.ClusterWorkspace, error) {
+		if cluster == logicalcluster.New("root:other:team-d") {
+			return nil, apierrors.NewForbidden(schema.GroupResource{Group: "tenancy.kcp.dev", Resource: "clusterworkspaces"}, "", nil)
+		}
+		return tree[cluster.String()], nil
+	}
+
+	progress, err := workspacesOnShard(context.Background(), list, logicalcluster.New("root"), "alpha")
+	require.NoError(t, err)
+	require.Equal(t, drainProgress{remaining: 4, migrating: 1}, progress)
+
+	progress, err = workspacesOnShard(context.Background(), list, logicalcluster.New("root"), "gamma")
+	require.NoError(t, err)
+	require.Equal(t, drainProgress{}, progress)
+}
+
+func workspace(name, current, target string) tenancyv1alpha1.ClusterWorkspace {
+	return tenancyv1alpha1.ClusterWorkspace{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+			Location: tenancyv1alpha1.ClusterWorkspaceLocation{
+				Current: current,
+				Target:  target,
+			},
+		},
+	}
+}
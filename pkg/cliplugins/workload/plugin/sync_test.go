@@ -60,6 +60,15 @@ rules:
   - "list"
   - "watch"
   - "delete"
+- apiGroups:
+  - ""
+  resources:
+  - nodes
+  - pods
+  verbs:
+  - "get"
+  - "list"
+  - "watch"
 - apiGroups:
   - "apiextensions.k8s.io"
   resources:
@@ -290,6 +299,15 @@ rules:
   - "list"
   - "watch"
   - "delete"
+- apiGroups:
+  - ""
+  resources:
+  - nodes
+  - pods
+  verbs:
+  - "get"
+  - "list"
+  - "watch"
 - apiGroups:
   - "apiextensions.k8s.io"
   resources:
@@ -61,6 +61,12 @@ var (
 
 	# create a context with the current workspace, named context-name
 	%[1]s workspace create-context context-name
+
+	# back up the current workspace and its child workspaces to a file
+	%[1]s workspace backup -o my-workspace.jsonl --recursive
+
+	# restore a backup into the current, empty workspace
+	%[1]s workspace restore -f my-workspace.jsonl
 `
 )
 
@@ -75,7 +81,7 @@ func New(streams genericclioptions.IOStreams) (*cobra.Command, error) {
 
 	cmd := &cobra.Command{
 		Aliases:          []string{"ws", "workspaces"},
-		Use:              "workspace [create|create-context|use|current|<workspace>|..|.|-|~|<root:absolute:workspace>]",
+		Use:              "workspace [create|create-context|use|current|backup|restore|<workspace>|..|.|-|~|<root:absolute:workspace>]",
 		Short:            "Manages KCP workspaces",
 		Example:          fmt.Sprintf(workspaceExample, cliName),
 		SilenceUsage:     true,
@@ -200,10 +206,54 @@ func New(streams genericclioptions.IOStreams) (*cobra.Command, error) {
 	}
 	treeCmdOpts.BindFlags(treeCmd)
 
+	backupOpts := plugin.NewBackupOptions(streams)
+	backupCmd := &cobra.Command{
+		Use:          "backup [-o <file>] [--recursive]",
+		Short:        "Write all objects of the current workspace to an archive",
+		Example:      "kcp workspace backup -o my-workspace.jsonl --recursive",
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return c.Help()
+			}
+			if err := backupOpts.Complete(); err != nil {
+				return err
+			}
+			if err := backupOpts.Validate(); err != nil {
+				return err
+			}
+			return backupOpts.Run(c.Context())
+		},
+	}
+	backupOpts.BindFlags(backupCmd)
+
+	restoreOpts := plugin.NewRestoreOptions(streams)
+	restoreCmd := &cobra.Command{
+		Use:          "restore [-f <file>]",
+		Short:        "Create the objects of an archive in the current workspace, which should be new or empty",
+		Example:      "kcp workspace restore -f my-workspace.jsonl",
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return c.Help()
+			}
+			if err := restoreOpts.Complete(); err != nil {
+				return err
+			}
+			if err := restoreOpts.Validate(); err != nil {
+				return err
+			}
+			return restoreOpts.Run(c.Context())
+		},
+	}
+	restoreOpts.BindFlags(restoreCmd)
+
 	cmd.AddCommand(useCmd)
 	cmd.AddCommand(treeCmd)
 	cmd.AddCommand(currentCmd)
 	cmd.AddCommand(createCmd)
 	cmd.AddCommand(createContextCmd)
+	cmd.AddCommand(backupCmd)
+	cmd.AddCommand(restoreCmd)
 	return cmd, nil
 }
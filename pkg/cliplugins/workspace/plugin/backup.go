@@ -0,0 +1,528 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	kcpdiscovery "github.com/kcp-dev/client-go/discovery"
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/spf13/cobra"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/cluster"
+	"github.com/kcp-dev/kcp/pkg/cliplugins/base"
+	pluginhelpers "github.com/kcp-dev/kcp/pkg/cliplugins/helpers"
+	"github.com/kcp-dev/kcp/pkg/workspacecontent"
+)
+
+// backupEntry is one line of a backup archive. An archive is a sequence of JSON
+// objects separated by newlines, in the order they are restored in.
+type backupEntry struct {
+	// Workspace is the path of the workspace the object belongs to, relative to the
+	// backed up workspace, e.g. "team-a:project-x". It is empty for the backed up
+	// workspace itself.
+	Workspace string `json:"workspace,omitempty"`
+	// Object is the object as returned by the server, without server-populated
+	// metadata.
+	Object *unstructured.Unstructured `json:"object"`
+}
+
+var clusterWorkspacesResource = tenancyv1alpha1.SchemeGroupVersion.WithResource("clusterworkspaces")
+
+// BackupOptions contains options for backing up a workspace.
+type BackupOptions struct {
+	*base.Options
+
+	// Output is the file the archive is written to, or "-" for stdout.
+	Output string
+	// Recursive indicates that child workspaces are backed up as well.
+	Recursive bool
+
+	kcpDiscoveryClient *kcpdiscovery.ClusterClientset
+	kcpDynamicClient   kcpdynamic.ClusterInterface
+}
+
+// NewBackupOptions returns a new BackupOptions.
+func NewBackupOptions(streams genericclioptions.IOStreams) *BackupOptions {
+	return &BackupOptions{
+		Options: base.NewOptions(streams),
+		Output:  "-",
+	}
+}
+
+// BindFlags binds fields to cmd's flagset.
+func (o *BackupOptions) BindFlags(cmd *cobra.Command) {
+	o.Options.BindFlags(cmd)
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "File to write the archive to, or - for stdout.")
+	cmd.Flags().BoolVarP(&o.Recursive, "recursive", "r", o.Recursive, "Back up child workspaces as well.")
+}
+
+// Complete ensures all dynamically populated fields are initialized.
+func (o *BackupOptions) Complete() error {
+	if err := o.Options.Complete(); err != nil {
+		return err
+	}
+
+	var err error
+	o.kcpDiscoveryClient, o.kcpDynamicClient, err = newDiscoveryAndDynamicClusterClients(o.Options)
+	return err
+}
+
+// Validate validates the BackupOptions are complete and usable.
+func (o *BackupOptions) Validate() error {
+	if o.Output == "" {
+		return errors.New("output file is required")
+	}
+	return o.Options.Validate()
+}
+
+// Run writes all objects of the current workspace to the archive.
+func (o *BackupOptions) Run(ctx context.Context) (err error) {
+	config, err := o.ClientConfig.ClientConfig()
+	if err != nil {
+		return err
+	}
+	_, currentClusterName, err := pluginhelpers.ParseClusterURL(config.Host)
+	if err != nil {
+		return fmt.Errorf("current config context URL %q does not point to workspace", config.Host)
+	}
+
+	out := o.Out
+	if o.Output != "-" {
+		f, err := os.Create(o.Output)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+		out = f
+	}
+	w := bufio.NewWriter(out)
+	defer func() {
+		if flushErr := w.Flush(); err == nil {
+			err = flushErr
+		}
+	}()
+
+	return o.backupWorkspace(ctx, json.NewEncoder(w), currentClusterName, "")
+}
+
+func (o *BackupOptions) backupWorkspace(ctx context.Context, enc *json.Encoder, cluster logicalcluster.Name, workspace string) error {
+	resources, err := o.kcpDiscoveryClient.Cluster(cluster).ServerPreferredResources()
+	if err != nil {
+		if !discovery.IsGroupDiscoveryFailedError(err) {
+			return fmt.Errorf("failed to discover resources of workspace %s: %w", cluster, err)
+		}
+		fmt.Fprintf(o.ErrOut, "Warning: skipping API groups of workspace %s: %v\n", cluster, err)
+	}
+	gvrs, err := discovery.GroupVersionResources(discovery.FilteredBy(discovery.SupportsAllVerbs{Verbs: []string{"list", "create"}}, resources))
+	if err != nil {
+		return err
+	}
+
+	sorted := make([]schema.GroupVersionResource, 0, len(gvrs))
+	for gvr := range gvrs {
+		if workspacecontent.SkippedResources[gvr.GroupResource()] {
+			continue
+		}
+		if gvr.GroupResource() == clusterWorkspacesResource.GroupResource() && !o.Recursive {
+			continue
+		}
+		sorted = append(sorted, gvr)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].String() < sorted[j].String()
+	})
+
+	var children []string
+	count := 0
+	for _, gvr := range sorted {
+		list, err := o.kcpDynamicClient.Cluster(cluster).Resource(gvr).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to list %s in workspace %s: %w", gvr.GroupResource(), cluster, err)
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			if workspacecontent.SkipObject(obj) {
+				continue
+			}
+			workspacecontent.Sanitize(obj)
+			if err := enc.Encode(backupEntry{Workspace: workspace, Object: obj}); err != nil {
+				return err
+			}
+			count++
+
+			if gvr.GroupResource() == clusterWorkspacesResource.GroupResource() {
+				children = append(children, obj.GetName())
+			}
+		}
+	}
+	fmt.Fprintf(o.ErrOut, "Backed up %d objects of workspace %s\n", count, cluster)
+
+	sort.Strings(children)
+	for _, child := range children {
+		if err := o.backupWorkspace(ctx, enc, cluster.Join(child), joinWorkspacePath(workspace, child)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RestoreOptions contains options for restoring a workspace from a backup.
+type RestoreOptions struct {
+	*base.Options
+
+	// Input is the archive file to read, or "-" for stdin.
+	Input string
+	// Timeout is the maximum time to wait for APIs, APIBindings and child workspaces
+	// to become ready.
+	Timeout time.Duration
+
+	kcpClusterClient   kcpclientset.ClusterInterface
+	kcpDiscoveryClient *kcpdiscovery.ClusterClientset
+	kcpDynamicClient   kcpdynamic.ClusterInterface
+}
+
+// NewRestoreOptions returns a new RestoreOptions.
+func NewRestoreOptions(streams genericclioptions.IOStreams) *RestoreOptions {
+	return &RestoreOptions{
+		Options: base.NewOptions(streams),
+		Input:   "-",
+		Timeout: time.Minute,
+	}
+}
+
+// BindFlags binds fields to cmd's flagset.
+func (o *RestoreOptions) BindFlags(cmd *cobra.Command) {
+	o.Options.BindFlags(cmd)
+	cmd.Flags().StringVarP(&o.Input, "filename", "f", o.Input, "Archive file to restore from, or - for stdin.")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, "Maximum time to wait for APIs and child workspaces to become ready.")
+}
+
+// Complete ensures all dynamically populated fields are initialized.
+func (o *RestoreOptions) Complete() error {
+	if err := o.Options.Complete(); err != nil {
+		return err
+	}
+
+	var err error
+	o.kcpClusterClient, err = newKCPClusterClient(o.ClientConfig)
+	if err != nil {
+		return err
+	}
+	o.kcpDiscoveryClient, o.kcpDynamicClient, err = newDiscoveryAndDynamicClusterClients(o.Options)
+	return err
+}
+
+// Validate validates the RestoreOptions are complete and usable.
+func (o *RestoreOptions) Validate() error {
+	if o.Input == "" {
+		return errors.New("input file is required")
+	}
+	if o.Timeout <= 0 {
+		return errors.New("timeout must be positive")
+	}
+	return o.Options.Validate()
+}
+
+// Run creates the objects of the archive in the current workspace.
+func (o *RestoreOptions) Run(ctx context.Context) error {
+	config, err := o.ClientConfig.ClientConfig()
+	if err != nil {
+		return err
+	}
+	_, currentClusterName, err := pluginhelpers.ParseClusterURL(config.Host)
+	if err != nil {
+		return fmt.Errorf("current config context URL %q does not point to workspace", config.Host)
+	}
+
+	in := o.In
+	if o.Input != "-" {
+		f, err := os.Open(o.Input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	entries, err := readBackup(in)
+	if err != nil {
+		return err
+	}
+
+	byWorkspace := map[string][]*unstructured.Unstructured{}
+	for _, e := range entries {
+		byWorkspace[e.Workspace] = append(byWorkspace[e.Workspace], e.Object)
+	}
+	workspaces := make([]string, 0, len(byWorkspace))
+	for ws := range byWorkspace {
+		workspaces = append(workspaces, ws)
+	}
+	sortParentsFirst(workspaces)
+
+	for _, ws := range workspaces {
+		cluster := currentClusterName
+		if ws != "" {
+			cluster = logicalcluster.New(currentClusterName.String() + ":" + ws)
+			if err := o.waitForWorkspace(ctx, cluster); err != nil {
+				return err
+			}
+		}
+		if err := o.restoreWorkspace(ctx, cluster, byWorkspace[ws]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (o *RestoreOptions) restoreWorkspace(ctx context.Context, cluster logicalcluster.Name, objs []*unstructured.Unstructured) error {
+	tiers := map[int][]*unstructured.Unstructured{}
+	for _, obj := range objs {
+		tier := restoreTier(obj.GroupVersionKind().GroupKind())
+		tiers[tier] = append(tiers[tier], obj)
+	}
+
+	created, existing := 0, 0
+	for tier := restoreTierAPIs; tier <= restoreTierWorkspaces; tier++ {
+		if len(tiers[tier]) == 0 {
+			continue
+		}
+
+		// rediscover to map the kinds served since the previous tier
+		groupResources, err := restmapper.GetAPIGroupResources(o.kcpDiscoveryClient.Cluster(cluster))
+		if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+			return fmt.Errorf("failed to discover resources of workspace %s: %w", cluster, err)
+		}
+		mapper := restmapper.NewDiscoveryRESTMapper(groupResources)
+
+		for _, obj := range tiers[tier] {
+			gvk := obj.GroupVersionKind()
+			mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+			if err != nil {
+				return fmt.Errorf("failed to restore %s %s in workspace %s: %w", gvk.Kind, objectName(obj), cluster, err)
+			}
+
+			obj = obj.DeepCopy()
+			unstructured.RemoveNestedField(obj.Object, "status")
+			_, err = o.kcpDynamicClient.Cluster(cluster).Resource(mapping.Resource).Namespace(obj.GetNamespace()).Create(ctx, obj, metav1.CreateOptions{})
+			if apierrors.IsAlreadyExists(err) {
+				existing++
+				continue
+			} else if err != nil {
+				return fmt.Errorf("failed to restore %s %s in workspace %s: %w", gvk.Kind, objectName(obj), cluster, err)
+			}
+			created++
+		}
+
+		switch tier {
+		case restoreTierAPIs:
+			if err := o.waitForConditions(ctx, cluster, tiers[tier], "CustomResourceDefinition", string(apiextensionsv1.Established)); err != nil {
+				return err
+			}
+		case restoreTierAPIBindings:
+			if err := o.waitForConditions(ctx, cluster, tiers[tier], "APIBinding", string(apisv1alpha1.InitialBindingCompleted)); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Fprintf(o.Out, "Restored %d objects into workspace %s, %d already existed\n", created, cluster, existing)
+	return nil
+}
+
+// waitForConditions waits for the given objects of the given kind to have the given condition.
+func (o *RestoreOptions) waitForConditions(ctx context.Context, cluster logicalcluster.Name, objs []*unstructured.Unstructured, kind, conditionType string) error {
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		if gvk.Kind != kind {
+			continue
+		}
+		gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+		err := wait.PollImmediateWithContext(ctx, time.Second/2, o.Timeout, func(ctx context.Context) (bool, error) {
+			current, err := o.kcpDynamicClient.Cluster(cluster).Resource(gvr).Get(ctx, obj.GetName(), metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			return hasTrueCondition(current, conditionType), nil
+		})
+		if err != nil {
+			return fmt.Errorf("%s %s in workspace %s did not become %s: %w", kind, obj.GetName(), cluster, conditionType, err)
+		}
+	}
+	return nil
+}
+
+// waitForWorkspace waits for a restored child workspace to become ready.
+func (o *RestoreOptions) waitForWorkspace(ctx context.Context, cluster logicalcluster.Name) error {
+	parent, name := cluster.Split()
+	err := wait.PollImmediateWithContext(ctx, time.Second/2, o.Timeout, func(ctx context.Context) (bool, error) {
+		ws, err := o.kcpClusterClient.Cluster(parent).TenancyV1alpha1().ClusterWorkspaces().Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return false, fmt.Errorf("workspace %s is not part of the backup", cluster)
+		} else if err != nil {
+			return false, err
+		}
+		return ws.Status.Phase == tenancyv1alpha1.ClusterWorkspacePhaseReady, nil
+	})
+	if err != nil {
+		return fmt.Errorf("workspace %s did not become ready: %w", cluster, err)
+	}
+	return nil
+}
+
+// Restore tiers, restored in ascending order. Every tier waits for the APIs it
+// introduces to be served before the next tier is restored.
+const (
+	restoreTierAPIs = iota
+	restoreTierNamespaces
+	restoreTierAPIBindings
+	restoreTierObjects
+	restoreTierWorkspaces
+)
+
+func restoreTier(gk schema.GroupKind) int {
+	switch gk {
+	case schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"},
+		schema.GroupKind{Group: "apis.kcp.dev", Kind: "APIResourceSchema"},
+		schema.GroupKind{Group: "apis.kcp.dev", Kind: "APIExport"}:
+		return restoreTierAPIs
+	case schema.GroupKind{Kind: "Namespace"}:
+		return restoreTierNamespaces
+	case schema.GroupKind{Group: "apis.kcp.dev", Kind: "APIBinding"}:
+		return restoreTierAPIBindings
+	case schema.GroupKind{Group: "tenancy.kcp.dev", Kind: "ClusterWorkspace"}:
+		return restoreTierWorkspaces
+	}
+	return restoreTierObjects
+}
+
+// readBackup reads all entries of an archive.
+func readBackup(r io.Reader) ([]backupEntry, error) {
+	var entries []backupEntry
+	dec := json.NewDecoder(r)
+	for {
+		var e backupEntry
+		if err := dec.Decode(&e); errors.Is(err, io.EOF) {
+			return entries, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid archive entry %d: %w", len(entries)+1, err)
+		}
+		if e.Object == nil || e.Object.GetKind() == "" || e.Object.GetName() == "" {
+			return nil, fmt.Errorf("invalid archive entry %d: object with kind and name expected", len(entries)+1)
+		}
+		entries = append(entries, e)
+	}
+}
+
+// sortParentsFirst sorts workspace paths such that every workspace comes after its parent.
+func sortParentsFirst(workspaces []string) {
+	depth := func(ws string) int {
+		if ws == "" {
+			return 0
+		}
+		return strings.Count(ws, ":") + 1
+	}
+	sort.Slice(workspaces, func(i, j int) bool {
+		if di, dj := depth(workspaces[i]), depth(workspaces[j]); di != dj {
+			return di < dj
+		}
+		return workspaces[i] < workspaces[j]
+	})
+}
+
+func joinWorkspacePath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + ":" + name
+}
+
+func hasTrueCondition(obj *unstructured.Unstructured, conditionType string) bool {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		c, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if c["type"] == conditionType && c["status"] == "True" {
+			return true
+		}
+	}
+	return false
+}
+
+func objectName(obj *unstructured.Unstructured) string {
+	if ns := obj.GetNamespace(); ns != "" {
+		return ns + "/" + obj.GetName()
+	}
+	return obj.GetName()
+}
+
+func newDiscoveryAndDynamicClusterClients(o *base.Options) (*kcpdiscovery.ClusterClientset, kcpdynamic.ClusterInterface, error) {
+	config, err := o.ClientConfig.ClientConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	clusterConfig := rest.CopyConfig(config)
+	u, err := url.Parse(config.Host)
+	if err != nil {
+		return nil, nil, err
+	}
+	u.Path = ""
+	clusterConfig.Host = u.String()
+	clusterConfig.UserAgent = rest.DefaultKubernetesUserAgent()
+
+	discoveryClient, err := kcpdiscovery.NewForConfig(clusterConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	dynamicClient, err := kcpdynamic.NewForConfig(clusterConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	return discoveryClient, dynamicClient, nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestRestoreTier(t *testing.T) {
+	require.Less(t, restoreTier(schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}), restoreTier(schema.GroupKind{Kind: "Namespace"}))
+	require.Less(t, restoreTier(schema.GroupKind{Group: "apis.kcp.dev", Kind: "APIExport"}), restoreTier(schema.GroupKind{Group: "apis.kcp.dev", Kind: "APIBinding"}))
+	require.Less(t, restoreTier(schema.GroupKind{Kind: "Namespace"}), restoreTier(schema.GroupKind{Group: "apis.kcp.dev", Kind: "APIBinding"}))
+	require.Less(t, restoreTier(schema.GroupKind{Group: "apis.kcp.dev", Kind: "APIBinding"}), restoreTier(schema.GroupKind{Group: "example.com", Kind: "Widget"}))
+	require.Less(t, restoreTier(schema.GroupKind{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole"}), restoreTier(schema.GroupKind{Group: "tenancy.kcp.dev", Kind: "ClusterWorkspace"}))
+}
+
+func TestReadBackup(t *testing.T) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range []backupEntry{
+		{Object: &unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": "Namespace", "metadata": map[string]interface{}{"name": "foo"}}}},
+		{Workspace: "team-a", Object: &unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "bar", "namespace": "foo"}}}},
+	} {
+		require.NoError(t, enc.Encode(e))
+	}
+
+	entries, err := readBackup(&buf)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "", entries[0].Workspace)
+	require.Equal(t, "Namespace", entries[0].Object.GetKind())
+	require.Equal(t, "team-a", entries[1].Workspace)
+	require.Equal(t, "foo", entries[1].Object.GetNamespace())
+
+	_, err = readBackup(strings.NewReader(`{"workspace":"a","object":{"apiVersion":"v1","kind":"ConfigMap"}}`))
+	require.Error(t, err)
+	_, err = readBackup(strings.NewReader(`{"workspace":"a"`))
+	require.Error(t, err)
+}
+
+func TestSortParentsFirst(t *testing.T) {
+	workspaces := []string{"b:c", "a", "", "b", "a:b:c", "a:b"}
+	sortParentsFirst(workspaces)
+	require.Equal(t, []string{"", "a", "b", "a:b", "b:c", "a:b:c"}, workspaces)
+}
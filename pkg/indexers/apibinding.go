@@ -100,5 +100,10 @@ func IndexAPIBindingByAPIExport(obj interface{}) ([]string, error) {
 		return []string{}, fmt.Errorf("obj %T is not an APIBinding", obj)
 	}
 
-	return []string{ClusterPathAndAPIExportName(apiBinding.Spec.Reference.Workspace.Path, apiBinding.Spec.Reference.Workspace.ExportName)}, nil
+	apiExportClusterName, apiExportName, ok := apisv1alpha1.ReferencedAPIExport(apiBinding)
+	if !ok {
+		return []string{}, nil
+	}
+
+	return []string{ClusterPathAndAPIExportName(apiExportClusterName.String(), apiExportName)}, nil
 }
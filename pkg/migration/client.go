@@ -0,0 +1,150 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"strings"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	"k8s.io/client-go/rest"
+)
+
+// Client copies, renames, measures and deletes the storage of logical clusters on shards
+// through their migration endpoints.
+type Client struct {
+	httpClient *http.Client
+}
+
+// NewClient returns a Client authenticating with the given config, which must hold
+// system:masters credentials valid on all shards. The host of the config is ignored.
+func NewClient(config *rest.Config) (*Client, error) {
+	httpClient, err := rest.HTTPClientFor(config)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{httpClient: httpClient}, nil
+}
+
+// Copy streams the storage of the logical cluster from the shard at sourceURL to the
+// shard at targetURL, replacing whatever the target stores for it. It returns the
+// number of copied keys.
+func (c *Client) Copy(ctx context.Context, sourceURL, targetURL string, cluster logicalcluster.Name) (int64, error) {
+	get, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint(sourceURL, cluster), nil)
+	if err != nil {
+		return 0, err
+	}
+	source, err := c.httpClient.Do(get)
+	if err != nil {
+		return 0, fmt.Errorf("failed to export logical cluster %s from %s: %w", cluster, sourceURL, err)
+	}
+	defer source.Body.Close()
+	if source.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("failed to export logical cluster %s from %s: %w", cluster, sourceURL, responseError(source))
+	}
+
+	put, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint(targetURL, cluster), source.Body)
+	if err != nil {
+		return 0, err
+	}
+	put.Header.Set("Content-Type", "application/json")
+	n, err := c.do(put)
+	if err != nil {
+		return 0, fmt.Errorf("failed to import logical cluster %s into %s: %w", cluster, targetURL, err)
+	}
+	return n, nil
+}
+
+// Rename moves the storage of the logical cluster from and of its descendants on the
+// shard at url to the logical cluster to. It returns the number of renamed keys.
+func (c *Client) Rename(ctx context.Context, url string, from, to logicalcluster.Name) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint(url, from)+"?"+neturl.Values{RenameToParameter: []string{to.String()}}.Encode(), nil)
+	if err != nil {
+		return 0, err
+	}
+	n, err := c.do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to rename logical cluster %s to %s on %s: %w", from, to, url, err)
+	}
+	return n, nil
+}
+
+// Measure returns the storage used by the logical cluster on the shard at url.
+func (c *Client) Measure(ctx context.Context, url string, cluster logicalcluster.Name) (ClusterUsage, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint(url, cluster)+"?"+UsageParameter, nil)
+	if err != nil {
+		return ClusterUsage{}, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return ClusterUsage{}, fmt.Errorf("failed to measure logical cluster %s on %s: %w", cluster, url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ClusterUsage{}, fmt.Errorf("failed to measure logical cluster %s on %s: %w", cluster, url, responseError(resp))
+	}
+	var usage ClusterUsage
+	if err := json.NewDecoder(resp.Body).Decode(&usage); err != nil {
+		return ClusterUsage{}, err
+	}
+	return usage, nil
+}
+
+// Delete removes the storage of the logical cluster from the shard at url. It returns
+// the number of deleted keys.
+func (c *Client) Delete(ctx context.Context, url string, cluster logicalcluster.Name) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, endpoint(url, cluster), nil)
+	if err != nil {
+		return 0, err
+	}
+	n, err := c.do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete logical cluster %s from %s: %w", cluster, url, err)
+	}
+	return n, nil
+}
+
+func (c *Client) do(req *http.Request) (int64, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, responseError(resp)
+	}
+	var result Result
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, err
+	}
+	return result.Keys, nil
+}
+
+func endpoint(shardURL string, cluster logicalcluster.Name) string {
+	return strings.TrimSuffix(shardURL, "/") + cluster.Path() + Path
+}
+
+func responseError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/klog/v2"
+)
+
+// Path is the path of the migration endpoint below /clusters/<cluster>.
+const Path = "/migration"
+
+// RenameToParameter is the query parameter of a rename request holding the new name of
+// the logical cluster.
+const RenameToParameter = "renameTo"
+
+// UsageParameter is the query parameter of a GET request asking for the ClusterUsage of the
+// logical cluster instead of its export.
+const UsageParameter = "usage"
+
+// Result is the response of an import, rename or delete request.
+type Result struct {
+	// Keys is the number of imported, renamed or deleted keys.
+	Keys int64 `json:"keys"`
+}
+
+// NewHandler returns a handler serving the storage of the logical cluster in the request
+// context: GET exports it, or returns its usage from the tally with the usage parameter, PUT replaces it with the exported records in the request body,
+// POST renames it and its descendants to the logical cluster in the renameTo parameter,
+// and DELETE removes it. Only members of system:masters are allowed, as the raw storage
+// bypasses admission and authorization of the individual objects.
+func NewHandler(kv clientv3.KV, prefix string, tally *Tally) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		logger := klog.FromContext(ctx)
+
+		u, ok := request.UserFrom(ctx)
+		if !ok || !sets.NewString(u.GetGroups()...).Has(user.SystemPrivilegedGroup) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		cluster, err := request.ValidClusterFrom(ctx)
+		if err != nil || cluster.Wildcard {
+			http.Error(w, "a single logical cluster is required", http.StatusBadRequest)
+			return
+		}
+		logger = logger.WithValues("cluster", cluster.Name.String(), "method", req.Method)
+
+		switch req.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			if req.URL.Query().Has(UsageParameter) {
+				usage, err := tally.Cluster(ctx, cluster.Name)
+				if err != nil {
+					http.Error(w, fmt.Sprintf("failed to measure logical cluster %s: %v", cluster.Name, err), http.StatusInternalServerError)
+					return
+				}
+				_ = json.NewEncoder(w).Encode(usage)
+				return
+			}
+			n, err := Export(ctx, kv, prefix, cluster.Name, w)
+			if err != nil {
+				// the status code has been sent already, hence the client sees an error record
+				logger.Error(err, "failed to export logical cluster", "keys", n)
+				return
+			}
+			logger.V(2).Info("exported logical cluster", "keys", n)
+		case http.MethodPut:
+			n, err := Import(ctx, kv, prefix, cluster.Name, req.Body)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("failed to import logical cluster %s after %d keys: %v", cluster.Name, n, err), http.StatusInternalServerError)
+				return
+			}
+			logger.V(2).Info("imported logical cluster", "keys", n)
+			writeResult(w, n)
+		case http.MethodPost:
+			to, valid := logicalcluster.NewValidated(req.URL.Query().Get(RenameToParameter))
+			if !valid || to == logicalcluster.Wildcard {
+				http.Error(w, fmt.Sprintf("a valid logical cluster is required in the %s parameter", RenameToParameter), http.StatusBadRequest)
+				return
+			}
+			n, err := Rename(ctx, kv, prefix, cluster.Name, to)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("failed to rename logical cluster %s to %s after %d keys: %v", cluster.Name, to, n, err), http.StatusInternalServerError)
+				return
+			}
+			logger.V(2).Info("renamed logical cluster", "to", to.String(), "keys", n)
+			writeResult(w, n)
+		case http.MethodDelete:
+			n, err := Delete(ctx, kv, prefix, cluster.Name)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("failed to delete logical cluster %s: %v", cluster.Name, err), http.StatusInternalServerError)
+				return
+			}
+			logger.V(2).Info("deleted logical cluster", "keys", n)
+			writeResult(w, n)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func writeResult(w http.ResponseWriter, n int64) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(Result{Keys: n})
+}
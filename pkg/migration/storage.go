@@ -0,0 +1,422 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package migration moves the storage of a logical cluster between shards. The
+// etcd keys of a logical cluster are exported from the source shard as a stream
+// of records and imported verbatim into the etcd of the target shard. It also
+// measures how many logical clusters and keys a shard stores, and renames logical
+// clusters in place when workspaces are moved to another parent or name.
+package migration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/serializer/protobuf"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+const (
+	// pageSize is the number of keys read from etcd at once.
+	pageSize = 500
+	// txnSize is the number of keys moved in one etcd transaction. Each key takes two
+	// comparisons and two operations, and etcd allows 128 of each by default.
+	txnSize = 64
+)
+
+// record is a single etcd key-value pair of a logical cluster. Key is relative to the
+// storage prefix, such that shards with different prefixes can exchange records.
+//
+// An export is terminated by a record with End set and Count holding the number of
+// exported keys, or by a record with Error set if the export failed. A stream without
+// either has been truncated.
+type record struct {
+	Key   string `json:"key,omitempty"`
+	Value []byte `json:"value,omitempty"`
+
+	End   bool   `json:"end,omitempty"`
+	Count int64  `json:"count,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// clusterOfKey returns the logical cluster a storage key relative to the storage prefix
+// belongs to. Keys have the form <resource prefix>/<cluster>/[<namespace>/]<name> where
+// the resource prefix has a variable number of segments, e.g. configmaps,
+// apis.kcp.dev/apibindings or mygroup.io/widgets/customresources. Resource prefix
+// segments never contain a colon, while all logical cluster names except root do.
+func clusterOfKey(key string) (logicalcluster.Name, bool) {
+	for _, segment := range strings.Split(strings.TrimPrefix(key, "/"), "/") {
+		if segment == tenancyv1alpha1.RootCluster.String() || strings.Contains(segment, ":") {
+			return logicalcluster.New(segment), true
+		}
+	}
+	return logicalcluster.Name{}, false
+}
+
+// forEachKey calls fn for every key of the given logical cluster below prefix, reading
+// all pages at the revision of the first page. Keys attached to a lease, e.g. events,
+// are skipped as their lease does not exist on other shards.
+func forEachKey(ctx context.Context, kv clientv3.KV, prefix string, cluster logicalcluster.Name, fn func(key string, value []byte) error) error {
+	return scan(ctx, kv, prefix, false, func(key string, kv *mvccpb.KeyValue) error {
+		if c, found := clusterOfKey(key); !found || c != cluster || kv.Lease != 0 {
+			return nil
+		}
+		return fn(key, kv.Value)
+	})
+}
+
+// scan calls fn for every key below prefix, reading all pages at the revision of the
+// first page. The key passed to fn is relative to prefix.
+func scan(ctx context.Context, kv clientv3.KV, prefix string, keysOnly bool, fn func(key string, kv *mvccpb.KeyValue) error) error {
+	prefix = strings.TrimSuffix(prefix, "/") + "/"
+	end := clientv3.GetPrefixRangeEnd(prefix)
+
+	var rev int64
+	for from := prefix; ; {
+		opts := []clientv3.OpOption{clientv3.WithRange(end), clientv3.WithLimit(pageSize)}
+		if keysOnly {
+			opts = append(opts, clientv3.WithKeysOnly())
+		}
+		if rev != 0 {
+			opts = append(opts, clientv3.WithRev(rev))
+		}
+		resp, err := kv.Get(ctx, from, opts...)
+		if err != nil {
+			return err
+		}
+		if rev == 0 {
+			rev = resp.Header.GetRevision()
+		}
+
+		for _, kv := range resp.Kvs {
+			if err := fn(strings.TrimPrefix(string(kv.Key), strings.TrimSuffix(prefix, "/")), kv); err != nil {
+				return err
+			}
+		}
+
+		if !resp.More || len(resp.Kvs) == 0 {
+			return nil
+		}
+		from = string(resp.Kvs[len(resp.Kvs)-1].Key) + "\x00"
+	}
+}
+
+// Export writes all keys of the given logical cluster below prefix to w. Values encrypted
+// at rest fail the export, as the target shard might use another encryption configuration.
+// A failure is written to w as an error record. It returns the number of exported keys.
+func Export(ctx context.Context, kv clientv3.KV, prefix string, cluster logicalcluster.Name, w io.Writer) (int64, error) {
+	enc := json.NewEncoder(w)
+	var n int64
+	if err := forEachKey(ctx, kv, prefix, cluster, func(key string, value []byte) error {
+		if bytes.HasPrefix(value, encryptedPrefix) {
+			return fmt.Errorf("key %q: encrypted values cannot be migrated", key)
+		}
+		n++
+		return enc.Encode(record{Key: key, Value: value})
+	}); err != nil {
+		_ = enc.Encode(record{Error: err.Error()})
+		return n, err
+	}
+	return n, enc.Encode(record{End: true, Count: n})
+}
+
+// Import replaces all keys of the given logical cluster below prefix with the records
+// read from r. Records of other logical clusters, encrypted values, failed exports and
+// truncated streams are rejected.
+// It returns the number of imported keys.
+func Import(ctx context.Context, kv clientv3.KV, prefix string, cluster logicalcluster.Name, r io.Reader) (int64, error) {
+	// remove leftovers of a previous, failed import
+	if _, err := Delete(ctx, kv, prefix, cluster); err != nil {
+		return 0, err
+	}
+
+	prefix = strings.TrimSuffix(prefix, "/")
+	dec := json.NewDecoder(r)
+	var n int64
+	for {
+		var rec record
+		if err := dec.Decode(&rec); err == io.EOF {
+			return n, fmt.Errorf("unexpected end of stream after %d keys", n)
+		} else if err != nil {
+			return n, fmt.Errorf("failed to decode record %d: %w", n+1, err)
+		}
+		if rec.Error != "" {
+			return n, fmt.Errorf("export failed: %s", rec.Error)
+		}
+		if rec.End {
+			if rec.Count != n {
+				return n, fmt.Errorf("expected %d keys, got %d", rec.Count, n)
+			}
+			return n, nil
+		}
+		if c, found := clusterOfKey(rec.Key); !found || c != cluster {
+			return n, fmt.Errorf("key %q does not belong to logical cluster %s", rec.Key, cluster)
+		}
+		if bytes.HasPrefix(rec.Value, encryptedPrefix) {
+			return n, fmt.Errorf("key %q: encrypted values cannot be migrated", rec.Key)
+		}
+		if _, err := kv.Put(ctx, prefix+rec.Key, string(rec.Value)); err != nil {
+			return n, err
+		}
+		n++
+	}
+}
+
+// Delete removes all keys of the given logical cluster below prefix. It returns the
+// number of deleted keys.
+func Delete(ctx context.Context, kv clientv3.KV, prefix string, cluster logicalcluster.Name) (int64, error) {
+	var keys []string
+	if err := forEachKey(ctx, kv, prefix, cluster, func(key string, _ []byte) error {
+		keys = append(keys, key)
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	prefix = strings.TrimSuffix(prefix, "/")
+	for _, key := range keys {
+		if _, err := kv.Delete(ctx, prefix+key); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(keys)), nil
+}
+
+// Rename moves all keys of the logical cluster from and of its descendants below prefix
+// to the logical cluster to, e.g. the keys of root:org:ws:team become keys of
+// root:other:ws:team when root:org:ws is renamed to root:other:ws. The ClusterWorkspace
+// of from, which is stored in its parent, is moved to the parent of to and renamed.
+// Logical cluster annotations and the base URLs of ClusterWorkspaces in JSON values are
+// rewritten, and keys attached to a lease keep it.
+//
+// Keys are moved in transactions of up to txnSize keys, each writing the new keys and
+// removing the old ones at once. A transaction fails if one of its keys was modified
+// since it was read, or if a new key exists already. Moved keys are not seen again, such
+// that a failed rename can be retried. It returns the number of moved keys.
+func Rename(ctx context.Context, kv clientv3.KV, prefix string, from, to logicalcluster.Name) (int64, error) {
+	_, fromHasParent := from.Parent()
+	_, toHasParent := to.Parent()
+	if !fromHasParent || !toHasParent || to == from || strings.HasPrefix(to.String(), from.String()+":") {
+		return 0, fmt.Errorf("cannot rename logical cluster %s to %s", from, to)
+	}
+	fromParent, fromName := from.Split()
+	toParent, toName := to.Split()
+
+	prefix = strings.TrimSuffix(prefix, "/")
+	var n int64
+	var cmps []clientv3.Cmp
+	var ops []clientv3.Op
+	commit := func() error {
+		if len(ops) == 0 {
+			return nil
+		}
+		resp, err := kv.Txn(ctx).If(cmps...).Then(ops...).Commit()
+		if err != nil {
+			return err
+		}
+		if !resp.Succeeded {
+			return fmt.Errorf("keys were modified during the rename, or already exist in %s", to)
+		}
+		n += int64(len(ops) / 2)
+		cmps, ops = nil, nil
+		return nil
+	}
+
+	err := scan(ctx, kv, prefix, false, func(key string, stored *mvccpb.KeyValue) error {
+		value := stored.Value
+		cluster, found := clusterOfKey(key)
+		if !found {
+			return nil
+		}
+
+		var newKey string
+		var err error
+		switch {
+		case cluster == from || strings.HasPrefix(cluster.String(), from.String()+":"):
+			newCluster := logicalcluster.New(to.String() + strings.TrimPrefix(cluster.String(), from.String()))
+			newKey = renameKey(key, newCluster, "")
+			value, err = renameValue(value, cluster, newCluster, "")
+		case cluster == fromParent && key[strings.LastIndex(key, "/")+1:] == fromName && isClusterWorkspaceKey(key):
+			newKey = renameKey(key, toParent, toName)
+			value, err = renameValue(value, fromParent, toParent, toName)
+		default:
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to rename key %q: %w", key, err)
+		}
+
+		var opts []clientv3.OpOption
+		if stored.Lease != 0 {
+			opts = append(opts, clientv3.WithLease(clientv3.LeaseID(stored.Lease)))
+		}
+		cmps = append(cmps,
+			clientv3.Compare(clientv3.ModRevision(prefix+key), "=", stored.ModRevision),
+			clientv3.Compare(clientv3.CreateRevision(prefix+newKey), "=", 0),
+		)
+		ops = append(ops,
+			clientv3.OpPut(prefix+newKey, string(value), opts...),
+			clientv3.OpDelete(prefix+key),
+		)
+		if len(ops) < 2*txnSize {
+			return nil
+		}
+		return commit()
+	})
+	if err != nil {
+		return n, err
+	}
+	return n, commit()
+}
+
+// renameKey replaces the logical cluster segment of a storage key relative to the storage
+// prefix, and its last segment if name is not empty.
+func renameKey(key string, cluster logicalcluster.Name, name string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		if segment == tenancyv1alpha1.RootCluster.String() || strings.Contains(segment, ":") {
+			segments[i] = cluster.String()
+			break
+		}
+	}
+	if name != "" {
+		segments[len(segments)-1] = name
+	}
+	return strings.Join(segments, "/")
+}
+
+// renameValue rewrites a JSON value whose key is moved from oldCluster to newCluster. The
+// logical cluster annotation and the base URL of a ClusterWorkspace are adjusted, and the
+// object is renamed if name is not empty. Protobuf encoded values are rewritten by
+// renameProtobufValue. Encrypted values cannot be rewritten and fail the rename. Other
+// values are returned unchanged.
+func renameValue(value []byte, oldCluster, newCluster logicalcluster.Name, name string) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(value, protobufPrefix):
+		return renameProtobufValue(value, newCluster)
+	case bytes.HasPrefix(value, encryptedPrefix):
+		return nil, errors.New("encrypted values cannot be renamed")
+	}
+	if !bytes.HasPrefix(bytes.TrimSpace(value), []byte("{")) {
+		return value, nil
+	}
+	var obj map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(value))
+	dec.UseNumber()
+	if err := dec.Decode(&obj); err != nil {
+		return nil, err
+	}
+	metadata, ok := obj["metadata"].(map[string]interface{})
+	if !ok {
+		return value, nil
+	}
+
+	changed := false
+	if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+		if _, found := annotations[logicalcluster.AnnotationKey]; found {
+			annotations[logicalcluster.AnnotationKey] = newCluster.String()
+			changed = true
+		}
+	}
+	if oldName, ok := metadata["name"].(string); ok && isClusterWorkspaceObject(obj) {
+		newName := oldName
+		if name != "" {
+			newName = name
+			metadata["name"] = name
+			changed = true
+		}
+		if status, ok := obj["status"].(map[string]interface{}); ok {
+			oldPath := oldCluster.Join(oldName).Path()
+			if baseURL, ok := status["baseURL"].(string); ok && strings.HasSuffix(baseURL, oldPath) {
+				status["baseURL"] = strings.TrimSuffix(baseURL, oldPath) + newCluster.Join(newName).Path()
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return value, nil
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(obj); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+var (
+	protobufSerializer = protobuf.NewSerializer(clientgoscheme.Scheme, clientgoscheme.Scheme)
+
+	// protobufPrefix starts every protobuf encoded value in the storage.
+	protobufPrefix = []byte("k8s\x00")
+	// encryptedPrefix starts every value encrypted at rest.
+	encryptedPrefix = []byte("k8s:enc:")
+)
+
+// renameProtobufValue rewrites the logical cluster annotation of a protobuf encoded value.
+// Only the built-in Kubernetes types are stored as protobuf, and types unknown to the
+// client-go scheme fail the rename.
+func renameProtobufValue(value []byte, newCluster logicalcluster.Name) ([]byte, error) {
+	obj, _, err := protobufSerializer.Decode(value, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	annotations := accessor.GetAnnotations()
+	if _, found := annotations[logicalcluster.AnnotationKey]; !found {
+		return value, nil
+	}
+	annotations[logicalcluster.AnnotationKey] = newCluster.String()
+	accessor.SetAnnotations(annotations)
+
+	var buf bytes.Buffer
+	if err := protobufSerializer.Encode(obj, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// clusterWorkspacesPrefix starts the storage keys of ClusterWorkspaces relative to the
+// storage prefix, followed by the logical cluster of the parent and the name.
+var clusterWorkspacesPrefix = "/" + tenancyv1alpha1.SchemeGroupVersion.Group + "/clusterworkspaces/"
+
+// isClusterWorkspaceKey returns true if the storage key relative to the storage prefix is
+// the key of a ClusterWorkspace, independently of how its value is encoded.
+func isClusterWorkspaceKey(key string) bool {
+	return strings.HasPrefix(key, clusterWorkspacesPrefix)
+}
+
+func isClusterWorkspaceObject(obj map[string]interface{}) bool {
+	apiVersion, _ := obj["apiVersion"].(string)
+	return obj["kind"] == "ClusterWorkspace" && strings.HasPrefix(apiVersion, tenancyv1alpha1.SchemeGroupVersion.Group+"/")
+}
@@ -0,0 +1,384 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migration
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// fakeKV is an in-memory clientv3.KV supporting the range, put, delete and transaction
+// operations used by this package. All keys are created at revision 1.
+type fakeKV struct {
+	clientv3.KV
+
+	values    map[string]string
+	leases    map[string]int64
+	revisions map[string]int64
+	revision  int64
+	txns      int
+}
+
+func newFakeKV(values map[string]string) *fakeKV {
+	revisions := map[string]int64{}
+	for k := range values {
+		revisions[k] = 1
+	}
+	return &fakeKV{values: values, leases: map[string]int64{}, revisions: revisions, revision: 1}
+}
+
+func (kv *fakeKV) Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	op := clientv3.OpGet(key, opts...)
+	end := string(op.RangeBytes())
+
+	var keys []string
+	for k := range kv.values {
+		if k >= key && (end == "" && k == key || end != "" && k < end) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	resp := &clientv3.GetResponse{Header: &etcdserverpb.ResponseHeader{Revision: 42}}
+	for _, k := range keys {
+		resp.Kvs = append(resp.Kvs, &mvccpb.KeyValue{Key: []byte(k), Value: []byte(kv.values[k]), Lease: kv.leases[k], ModRevision: kv.revisions[k]})
+	}
+	return resp, nil
+}
+
+func (kv *fakeKV) Put(ctx context.Context, key, val string, opts ...clientv3.OpOption) (*clientv3.PutResponse, error) {
+	kv.revision++
+	kv.values[key] = val
+	kv.revisions[key] = kv.revision
+	return &clientv3.PutResponse{}, nil
+}
+
+func (kv *fakeKV) Delete(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.DeleteResponse, error) {
+	delete(kv.values, key)
+	delete(kv.revisions, key)
+	return &clientv3.DeleteResponse{}, nil
+}
+
+func (kv *fakeKV) Txn(ctx context.Context) clientv3.Txn {
+	return &fakeTxn{kv: kv}
+}
+
+// fakeTxn supports comparisons of the modification or creation revision, which are both
+// the revision of the last write in fakeKV, and put and delete operations.
+type fakeTxn struct {
+	kv   *fakeKV
+	cmps []clientv3.Cmp
+	ops  []clientv3.Op
+}
+
+func (txn *fakeTxn) If(cmps ...clientv3.Cmp) clientv3.Txn {
+	txn.cmps = cmps
+	return txn
+}
+
+func (txn *fakeTxn) Then(ops ...clientv3.Op) clientv3.Txn {
+	txn.ops = ops
+	return txn
+}
+
+func (txn *fakeTxn) Else(ops ...clientv3.Op) clientv3.Txn {
+	panic("not implemented")
+}
+
+func (txn *fakeTxn) Commit() (*clientv3.TxnResponse, error) {
+	for _, cmp := range txn.cmps {
+		var want int64
+		switch target := cmp.TargetUnion.(type) {
+		case *etcdserverpb.Compare_ModRevision:
+			want = target.ModRevision
+		case *etcdserverpb.Compare_CreateRevision:
+			want = target.CreateRevision
+		default:
+			panic("not implemented")
+		}
+		if txn.kv.revisions[string(cmp.KeyBytes())] != want {
+			return &clientv3.TxnResponse{Succeeded: false}, nil
+		}
+	}
+	txn.kv.txns++
+	for _, op := range txn.ops {
+		switch {
+		case op.IsPut():
+			txn.kv.Put(context.Background(), string(op.KeyBytes()), string(op.ValueBytes())) //nolint:errcheck
+		case op.IsDelete():
+			txn.kv.Delete(context.Background(), string(op.KeyBytes())) //nolint:errcheck
+		default:
+			panic("not implemented")
+		}
+	}
+	return &clientv3.TxnResponse{Succeeded: true}, nil
+}
+
+func TestClusterOfKey(t *testing.T) {
+	tests := map[string]struct {
+		key  string
+		want string
+	}{
+		"core resource":                   {key: "/configmaps/root:org:ws/default/foo", want: "root:org:ws"},
+		"cluster-scoped resource":         {key: "/namespaces/root:org/default", want: "root:org"},
+		"root":                            {key: "/apis.kcp.dev/apibindings/root/tenancy.kcp.dev", want: "root"},
+		"custom resource":                 {key: "/mygroup.io/widgets/customresources/root:org/default/foo", want: "root:org"},
+		"bound resource with an identity": {key: "/mygroup.io/widgets/1234abcd/root:org/default/foo", want: "root:org"},
+		"system cluster":                  {key: "/apiextensions.k8s.io/customresourcedefinitions/system:bound-crds/foo", want: "system:bound-crds"},
+		"no cluster":                      {key: "/masterleases/10.0.0.1"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, found := clusterOfKey(tc.key)
+			require.Equal(t, tc.want != "", found)
+			require.Equal(t, tc.want, got.String())
+		})
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+	cluster := logicalcluster.New("root:org:ws")
+
+	source := newFakeKV(map[string]string{
+		"/registry/configmaps/root:org:ws/default/foo":        "foo",
+		"/registry/configmaps/root:org:ws:child/default/foo":  "child",
+		"/registry/configmaps/root:org/default/foo":           "parent",
+		"/registry/events/root:org:ws/default/foo.1234":       "event",
+		"/registry/mygroup.io/widgets/1234/root:org:ws/a/bar": "bar",
+		"/other/configmaps/root:org:ws/default/foo":           "other prefix",
+	})
+	source.leases["/registry/events/root:org:ws/default/foo.1234"] = 1
+
+	var buf bytes.Buffer
+	n, err := Export(ctx, source, "/registry", cluster, &buf)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), n)
+
+	target := newFakeKV(map[string]string{
+		"/kcp/configmaps/root:org:ws/default/stale": "left over from a failed import",
+		"/kcp/configmaps/root:org/default/foo":      "parent",
+	})
+	n, err = Import(ctx, target, "/kcp/", cluster, bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, int64(2), n)
+	require.Equal(t, map[string]string{
+		"/kcp/configmaps/root:org:ws/default/foo":        "foo",
+		"/kcp/mygroup.io/widgets/1234/root:org:ws/a/bar": "bar",
+		"/kcp/configmaps/root:org/default/foo":           "parent",
+	}, target.values)
+
+	n, err = Delete(ctx, source, "/registry", cluster)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), n)
+	require.Equal(t, map[string]string{
+		"/registry/configmaps/root:org:ws:child/default/foo": "child",
+		"/registry/configmaps/root:org/default/foo":          "parent",
+		"/registry/events/root:org:ws/default/foo.1234":      "event",
+		"/other/configmaps/root:org:ws/default/foo":          "other prefix",
+	}, source.values)
+}
+
+func TestImportRejects(t *testing.T) {
+	cluster := logicalcluster.New("root:org:ws")
+
+	tests := map[string]struct {
+		stream  string
+		wantErr string
+	}{
+		"truncated stream": {
+			stream:  `{"key":"/configmaps/root:org:ws/default/foo","value":"Zm9v"}`,
+			wantErr: "unexpected end of stream after 1 keys",
+		},
+		"wrong count": {
+			stream:  `{"key":"/configmaps/root:org:ws/default/foo","value":"Zm9v"}` + "\n" + `{"end":true,"count":2}`,
+			wantErr: "expected 2 keys, got 1",
+		},
+		"key of another logical cluster": {
+			stream:  `{"key":"/configmaps/root:org/default/foo","value":"Zm9v"}`,
+			wantErr: `key "/configmaps/root:org/default/foo" does not belong to logical cluster root:org:ws`,
+		},
+		"encrypted value": {
+			stream:  `{"key":"/configmaps/root:org:ws/default/foo","value":"azhzOmVuYzphZXNjYmM6djE6a2V5MTpzZWNyZXQ="}`,
+			wantErr: `key "/configmaps/root:org:ws/default/foo": encrypted values cannot be migrated`,
+		},
+		"failed export": {
+			stream:  `{"key":"/configmaps/root:org:ws/default/foo","value":"Zm9v"}` + "\n" + `{"error":"etcdserver: request timed out"}`,
+			wantErr: "export failed: etcdserver: request timed out",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Import(context.Background(), newFakeKV(map[string]string{}), "/registry", cluster, strings.NewReader(tc.stream))
+			require.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestRename(t *testing.T) {
+	ctx := context.Background()
+
+	kv := newFakeKV(map[string]string{
+		"/registry/configmaps/root:org:ws/default/foo":                 "foo",
+		"/registry/configmaps/root:org:ws:team/default/foo":            `{"kind":"ConfigMap","metadata":{"name":"foo","annotations":{"kcp.dev/cluster":"root:org:ws:team","a":"b"}},"data":{"n":"12345678901234567890"}}`,
+		"/registry/configmaps/root:org:wsx/default/foo":                "sibling with the same prefix",
+		"/registry/configmaps/root:org/default/ws":                     "not a workspace",
+		"/registry/tenancy.kcp.dev/clusterworkspaces/root:org/ws":      `{"apiVersion":"tenancy.kcp.dev/v1alpha1","kind":"ClusterWorkspace","metadata":{"name":"ws"},"status":{"baseURL":"https://shard/clusters/root:org:ws"}}`,
+		"/registry/tenancy.kcp.dev/clusterworkspaces/root:org:ws/team": `{"apiVersion":"tenancy.kcp.dev/v1alpha1","kind":"ClusterWorkspace","metadata":{"name":"team"},"status":{"baseURL":"https://shard/clusters/root:org:ws:team"}}`,
+		"/registry/tenancy.kcp.dev/clusterworkspaces/root:org/other":   `{"apiVersion":"tenancy.kcp.dev/v1alpha1","kind":"ClusterWorkspace","metadata":{"name":"other"}}`,
+		"/other/configmaps/root:org:ws/default/foo":                    "other prefix",
+	})
+
+	n, err := Rename(ctx, kv, "/registry", logicalcluster.New("root:org:ws"), logicalcluster.New("root:new:renamed"))
+	require.NoError(t, err)
+	require.Equal(t, int64(4), n)
+	require.Equal(t, map[string]string{
+		"/registry/configmaps/root:new:renamed/default/foo":                 "foo",
+		"/registry/configmaps/root:new:renamed:team/default/foo":            `{"data":{"n":"12345678901234567890"},"kind":"ConfigMap","metadata":{"annotations":{"a":"b","kcp.dev/cluster":"root:new:renamed:team"},"name":"foo"}}` + "\n",
+		"/registry/configmaps/root:org:wsx/default/foo":                     "sibling with the same prefix",
+		"/registry/configmaps/root:org/default/ws":                          "not a workspace",
+		"/registry/tenancy.kcp.dev/clusterworkspaces/root:new/renamed":      `{"apiVersion":"tenancy.kcp.dev/v1alpha1","kind":"ClusterWorkspace","metadata":{"name":"renamed"},"status":{"baseURL":"https://shard/clusters/root:new:renamed"}}` + "\n",
+		"/registry/tenancy.kcp.dev/clusterworkspaces/root:new:renamed/team": `{"apiVersion":"tenancy.kcp.dev/v1alpha1","kind":"ClusterWorkspace","metadata":{"name":"team"},"status":{"baseURL":"https://shard/clusters/root:new:renamed:team"}}` + "\n",
+		"/registry/tenancy.kcp.dev/clusterworkspaces/root:org/other":        `{"apiVersion":"tenancy.kcp.dev/v1alpha1","kind":"ClusterWorkspace","metadata":{"name":"other"}}`,
+		"/other/configmaps/root:org:ws/default/foo":                         "other prefix",
+	}, kv.values)
+
+	require.Equal(t, 1, kv.txns)
+
+	// renaming again is a no-op
+	n, err = Rename(ctx, kv, "/registry", logicalcluster.New("root:org:ws"), logicalcluster.New("root:new:renamed"))
+	require.NoError(t, err)
+	require.Equal(t, int64(0), n)
+
+	_, err = Rename(ctx, kv, "/registry", logicalcluster.New("root:new"), logicalcluster.New("root:new:renamed:sub"))
+	require.EqualError(t, err, "cannot rename logical cluster root:new to root:new:renamed:sub")
+}
+
+func TestRenameBatches(t *testing.T) {
+	ctx := context.Background()
+
+	values := map[string]string{}
+	for i := 0; i < 2*txnSize+1; i++ {
+		values[fmt.Sprintf("/registry/configmaps/root:org:ws/default/cm-%03d", i)] = "value"
+	}
+	kv := newFakeKV(values)
+
+	n, err := Rename(ctx, kv, "/registry", logicalcluster.New("root:org:ws"), logicalcluster.New("root:org:renamed"))
+	require.NoError(t, err)
+	require.Equal(t, int64(2*txnSize+1), n)
+	require.Equal(t, 3, kv.txns)
+	for k := range kv.values {
+		require.True(t, strings.HasPrefix(k, "/registry/configmaps/root:org:renamed/default/"), "unexpected key %q", k)
+	}
+}
+
+func TestRenameRejects(t *testing.T) {
+	tests := map[string]struct {
+		values  map[string]string
+		wantErr string
+	}{
+		"existing target": {
+			values: map[string]string{
+				"/registry/configmaps/root:org:ws/default/foo":      "foo",
+				"/registry/configmaps/root:org:renamed/default/foo": "existing",
+			},
+			wantErr: "keys were modified during the rename, or already exist in root:org:renamed",
+		},
+		"unknown protobuf type": {
+			values: map[string]string{
+				"/registry/configmaps/root:org:ws/default/foo": encodeProtobuf(t, &runtime.Unknown{TypeMeta: runtime.TypeMeta{APIVersion: "example.com/v1", Kind: "Widget"}}),
+			},
+			wantErr: `failed to rename key "/configmaps/root:org:ws/default/foo": no kind "Widget" is registered for version "example.com/v1" in scheme`,
+		},
+		"encrypted": {
+			values: map[string]string{
+				"/registry/configmaps/root:org:ws/default/foo": "k8s:enc:aescbc:v1:key1:secret",
+			},
+			wantErr: `failed to rename key "/configmaps/root:org:ws/default/foo": encrypted values cannot be renamed`,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			kv := newFakeKV(tc.values)
+			_, err := Rename(context.Background(), kv, "/registry", logicalcluster.New("root:org:ws"), logicalcluster.New("root:org:renamed"))
+			require.Error(t, err)
+			require.True(t, strings.HasPrefix(err.Error(), tc.wantErr), "unexpected error: %v", err)
+			require.Contains(t, kv.values, "/registry/configmaps/root:org:ws/default/foo", "nothing is moved")
+		})
+	}
+}
+
+func TestExportRejectsEncrypted(t *testing.T) {
+	ctx := context.Background()
+	cluster := logicalcluster.New("root:org:ws")
+	source := newFakeKV(map[string]string{
+		"/registry/configmaps/root:org:ws/default/foo": "k8s:enc:aescbc:v1:key1:secret",
+	})
+
+	var buf bytes.Buffer
+	_, err := Export(ctx, source, "/registry", cluster, &buf)
+	require.EqualError(t, err, `key "/configmaps/root:org:ws/default/foo": encrypted values cannot be migrated`)
+
+	_, err = Import(ctx, newFakeKV(map[string]string{}), "/registry", cluster, bytes.NewReader(buf.Bytes()))
+	require.EqualError(t, err, `export failed: key "/configmaps/root:org:ws/default/foo": encrypted values cannot be migrated`)
+}
+
+func TestRenameProtobuf(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "foo",
+			Namespace:   "default",
+			Annotations: map[string]string{logicalcluster.AnnotationKey: "root:org:ws", "a": "b"},
+		},
+		Data: map[string]string{"key": "value"},
+	}
+	kv := newFakeKV(map[string]string{
+		"/registry/configmaps/root:org:ws/default/foo": encodeProtobuf(t, cm),
+	})
+
+	n, err := Rename(context.Background(), kv, "/registry", logicalcluster.New("root:org:ws"), logicalcluster.New("root:org:renamed"))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	value, found := kv.values["/registry/configmaps/root:org:renamed/default/foo"]
+	require.True(t, found)
+	obj, _, err := protobufSerializer.Decode([]byte(value), nil, nil)
+	require.NoError(t, err)
+	cm.Annotations[logicalcluster.AnnotationKey] = "root:org:renamed"
+	require.Equal(t, cm, obj)
+}
+
+func encodeProtobuf(t *testing.T, obj runtime.Object) string {
+	var buf bytes.Buffer
+	require.NoError(t, protobufSerializer.Encode(obj, &buf))
+	return buf.String()
+}
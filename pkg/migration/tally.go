@@ -0,0 +1,128 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migration
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+// ClusterUsage is the storage used by a single logical cluster.
+type ClusterUsage struct {
+	// Objects is the number of keys of the logical cluster.
+	Objects int64 `json:"objects"`
+	// Bytes is the total size of the values of the logical cluster.
+	Bytes int64 `json:"bytes"`
+	// Workspaces is the number of ClusterWorkspaces in the logical cluster.
+	Workspaces int64 `json:"workspaces"`
+}
+
+// Tally measures the storage of all logical clusters below a prefix in a single pass. The
+// measurement is shared by the usage reports of the shard and the quotas of its workspaces,
+// and it is reused until it is older than maxAge.
+type Tally struct {
+	kv     clientv3.KV
+	prefix string
+	maxAge time.Duration
+	now    func() time.Time
+
+	lock       sync.Mutex
+	measuredAt time.Time
+	keys       int64
+	clusters   map[logicalcluster.Name]ClusterUsage
+}
+
+// NewTally returns a Tally of the keys below prefix, measured at most once per maxAge.
+func NewTally(kv clientv3.KV, prefix string, maxAge time.Duration) *Tally {
+	return &Tally{
+		kv:     kv,
+		prefix: prefix,
+		maxAge: maxAge,
+		now:    time.Now,
+	}
+}
+
+// Cluster returns the storage used by the given logical cluster. Like on export, keys
+// attached to a lease are not counted.
+func (t *Tally) Cluster(ctx context.Context, cluster logicalcluster.Name) (ClusterUsage, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if err := t.measure(ctx); err != nil {
+		return ClusterUsage{}, err
+	}
+	return t.clusters[cluster], nil
+}
+
+// Shard returns the number of workspaces and the number of keys. System logical clusters,
+// i.e. those not below root, are not counted as workspaces, but their keys are counted.
+func (t *Tally) Shard(ctx context.Context) (workspaces, keys int64, err error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if err := t.measure(ctx); err != nil {
+		return 0, 0, err
+	}
+	for cluster := range t.clusters {
+		if cluster.HasPrefix(tenancyv1alpha1.RootCluster) {
+			workspaces++
+		}
+	}
+	return workspaces, t.keys, nil
+}
+
+// measure scans all keys unless the last measurement is younger than maxAge. Concurrent
+// callers wait for a running scan and share its result.
+func (t *Tally) measure(ctx context.Context) error {
+	if !t.measuredAt.IsZero() && t.now().Sub(t.measuredAt) < t.maxAge {
+		return nil
+	}
+
+	clusters := map[logicalcluster.Name]ClusterUsage{}
+	var keys int64
+	if err := scan(ctx, t.kv, t.prefix, false, func(key string, kv *mvccpb.KeyValue) error {
+		keys++
+		cluster, found := clusterOfKey(key)
+		if !found {
+			return nil
+		}
+		usage := clusters[cluster]
+		if kv.Lease == 0 {
+			usage.Objects++
+			usage.Bytes += int64(len(kv.Value))
+			if isClusterWorkspaceKey(key) {
+				usage.Workspaces++
+			}
+		}
+		clusters[cluster] = usage
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	t.measuredAt = t.now()
+	t.keys = keys
+	t.clusters = clusters
+	return nil
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTallyShard(t *testing.T) {
+	kv := newFakeKV(map[string]string{
+		"/registry/configmaps/root:org:ws/default/foo":        "foo",
+		"/registry/configmaps/root:org/default/foo":           "parent",
+		"/registry/mygroup.io/widgets/1234/root:org:ws/a/bar": "bar",
+		"/registry/apis.kcp.dev/apibindings/root/tenancy":     "binding",
+		"/registry/configmaps/system:admin/default/foo":       "system",
+		"/registry/masterleases/10.0.0.1":                     "lease",
+		"/other/configmaps/root:org:ws/default/foo":           "other prefix",
+	})
+
+	clusters, keys, err := NewTally(kv, "/registry", time.Minute).Shard(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(3), clusters, "system:admin is not a workspace")
+	require.Equal(t, int64(6), keys)
+}
+
+func TestTallyCluster(t *testing.T) {
+	kv := newFakeKV(map[string]string{
+		"/registry/configmaps/root:org:ws/default/foo":                 "foo",
+		"/registry/configmaps/root:org:ws/default/event":               "leased",
+		"/registry/configmaps/root:org/default/foo":                    "parent",
+		"/registry/mygroup.io/widgets/1234/root:org:ws/a/bar":          "bar",
+		"/registry/tenancy.kcp.dev/clusterworkspaces/root:org:ws/team": `{"apiVersion":"tenancy.kcp.dev/v1alpha1","kind":"ClusterWorkspace"}`,
+		"/registry/tenancy.kcp.dev/clusterworkspaces/root:org:ws/blue": "k8s\x00protobuf",
+		"/registry/tenancy.kcp.dev/clusterworkspaces/root:org/ws":      `{"apiVersion":"tenancy.kcp.dev/v1alpha1","kind":"ClusterWorkspace"}`,
+		"/registry/configmaps/root:org:ws/default/manifest":            `{"kind":"ClusterWorkspace"}`,
+		"/other/configmaps/root:org:ws/default/foo":                    "other prefix",
+	})
+	kv.leases["/registry/configmaps/root:org:ws/default/event"] = 1
+
+	tally := NewTally(kv, "/registry", time.Minute)
+	usage, err := tally.Cluster(context.Background(), logicalcluster.New("root:org:ws"))
+	require.NoError(t, err)
+	require.Equal(t, ClusterUsage{Objects: 5, Bytes: 112, Workspaces: 2}, usage, "workspaces are counted by key, independently of the encoding")
+
+	usage, err = tally.Cluster(context.Background(), logicalcluster.New("root:missing"))
+	require.NoError(t, err)
+	require.Equal(t, ClusterUsage{}, usage)
+}
+
+func TestTallyMaxAge(t *testing.T) {
+	kv := newFakeKV(map[string]string{
+		"/registry/configmaps/root:org:ws/default/foo": "foo",
+	})
+	now := time.Date(2022, 12, 1, 0, 0, 0, 0, time.UTC)
+	tally := NewTally(kv, "/registry", time.Minute)
+	tally.now = func() time.Time { return now }
+	ctx := context.Background()
+	cluster := logicalcluster.New("root:org:ws")
+
+	usage, err := tally.Cluster(ctx, cluster)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), usage.Objects)
+
+	// the measurement is reused by all callers until it is older than maxAge
+	kv.values["/registry/configmaps/root:org:ws/default/bar"] = "bar"
+	now = now.Add(30 * time.Second)
+	usage, err = tally.Cluster(ctx, cluster)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), usage.Objects)
+	_, keys, err := tally.Shard(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), keys)
+
+	now = now.Add(30 * time.Second)
+	usage, err = tally.Cluster(ctx, cluster)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), usage.Objects)
+}
@@ -19,9 +19,12 @@ package permissionclaim
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/kcp-dev/logicalcluster/v2"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/klog/v2"
 
@@ -53,10 +56,11 @@ func NewLabeler(apiBindingInformer apisv1alpha1informers.APIBindingClusterInform
 	}
 }
 
-// LabelsFor returns all the applicable labels for the cluster-group-resource relating to permission claims. This is
-// the intersection of (1) all APIBindings in the cluster that have accepted claims for the group-resource with (2)
-// associated APIExports that are claiming group-resource.
-func (l *Labeler) LabelsFor(ctx context.Context, cluster logicalcluster.Name, groupResource schema.GroupResource, resourceName string) (map[string]string, error) {
+// LabelsFor returns all the applicable labels for the object of the cluster-group-resource relating to permission
+// claims. This is the intersection of (1) all APIBindings in the cluster that have accepted claims for the
+// group-resource with (2) associated APIExports that are claiming group-resource, restricted to the claims whose
+// resource selectors and label selector match the object.
+func (l *Labeler) LabelsFor(ctx context.Context, cluster logicalcluster.Name, groupResource schema.GroupResource, obj metav1.Object) (map[string]string, error) {
 	labels := map[string]string{}
 
 	bindings, err := l.listAPIBindingsAcceptingClaimedGroupResource(cluster, groupResource)
@@ -69,18 +73,29 @@ func (l *Labeler) LabelsFor(ctx context.Context, cluster logicalcluster.Name, gr
 	for _, binding := range bindings {
 		logger := logging.WithObject(logger, binding)
 
-		if binding.Spec.Reference.Workspace == nil {
-			logger.V(4).Info("skipping APIBinding because it has no workspace set")
+		exportClusterName, exportName, ok := apisv1alpha1.ReferencedAPIExport(binding)
+		if !ok {
+			logger.V(4).Info("skipping APIBinding because its APIExport reference is not resolved")
 			continue
 		}
-		boundAPIExportWorkspace := binding.Spec.Reference.Workspace
 
 		for _, claim := range binding.Spec.PermissionClaims {
 			if claim.State != apisv1alpha1.ClaimAccepted || claim.Group != groupResource.Group || claim.Resource != groupResource.Resource {
 				continue
 			}
 
-			k, v, err := permissionclaims.ToLabelKeyAndValue(logicalcluster.New(boundAPIExportWorkspace.Path), boundAPIExportWorkspace.ExportName, claim.PermissionClaim)
+			if !claim.SelectsObject(obj.GetNamespace(), obj.GetName()) {
+				continue
+			}
+
+			if matches, err := claimSelectsLabels(claim.PermissionClaim, obj.GetLabels()); err != nil {
+				logger.Error(err, "error parsing permission claim label selector", "claim", claim.String())
+				continue
+			} else if !matches {
+				continue
+			}
+
+			k, v, err := permissionclaims.ToLabelKeyAndValue(exportClusterName, exportName, claim.PermissionClaim)
 			if err != nil {
 				// extremely unlikely to get an error here - it means the json marshaling failed
 				logger.Error(err, "error calculating permission claim label key and value",
@@ -95,14 +110,14 @@ func (l *Labeler) LabelsFor(ctx context.Context, cluster logicalcluster.Name, gr
 	// pointing to an APIExport visible to the owner of the export, independently of the permission claim
 	// acceptance of the binding.
 	if groupResource.Group == apis.GroupName && groupResource.Resource == "apibindings" {
-		binding, err := l.getAPIBinding(cluster, resourceName)
+		binding, err := l.getAPIBinding(cluster, obj.GetName())
 		if err != nil {
-			logger.Error(err, "error getting APIBinding", "bindingName", resourceName)
+			logger.Error(err, "error getting APIBinding", "bindingName", obj.GetName())
 			return labels, nil // can only be a NotFound
 		}
 
-		if exportRef := binding.Spec.Reference; exportRef.Workspace != nil {
-			k, v := permissionclaims.ToReflexiveAPIBindingLabelKeyAndValue(logicalcluster.New(exportRef.Workspace.Path), exportRef.Workspace.ExportName)
+		if exportClusterName, exportName, ok := apisv1alpha1.ReferencedAPIExport(binding); ok {
+			k, v := permissionclaims.ToReflexiveAPIBindingLabelKeyAndValue(exportClusterName, exportName)
 			if _, found := labels[k]; !found {
 				labels[k] = v
 			}
@@ -111,3 +126,22 @@ func (l *Labeler) LabelsFor(ctx context.Context, cluster logicalcluster.Name, gr
 
 	return labels, nil
 }
+
+// claimSelectsLabels returns true if the label selector of the claim, if any, matches the given labels.
+// Permission claim labels are ignored, such that claims cannot select objects by the claims of other exports.
+func claimSelectsLabels(claim apisv1alpha1.PermissionClaim, objLabels map[string]string) (bool, error) {
+	if claim.LabelSelector == nil {
+		return true, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(claim.LabelSelector)
+	if err != nil {
+		return false, err
+	}
+	set := labels.Set{}
+	for k, v := range objLabels {
+		if !strings.HasPrefix(k, apisv1alpha1.APIExportPermissionClaimLabelPrefix) {
+			set[k] = v
+		}
+	}
+	return selector.Matches(set), nil
+}
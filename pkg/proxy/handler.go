@@ -58,6 +58,19 @@ func shardHandler(index index.Index, proxy http.Handler) http.HandlerFunc {
 		}
 
 		shardURLString, found := index.Lookup(clusterName)
+		if !found {
+			// the workspace might have been moved recently. Then serve the request from its new name.
+			if movedTo, moved := index.Moved(clusterName); moved {
+				logger.WithValues("clusterName", clusterName, "movedTo", movedTo).V(4).Info("Redirecting moved cluster")
+				req = req.Clone(ctx)
+				req.URL.Path = "/clusters/" + movedTo.String() + "/" + cs[2]
+				if req.URL.RawPath != "" {
+					req.URL.RawPath = strings.Replace(req.URL.RawPath, "/clusters/"+clusterName.String()+"/", "/clusters/"+movedTo.String()+"/", 1)
+				}
+				clusterName = movedTo
+				shardURLString, found = index.Lookup(clusterName)
+			}
+		}
 		if !found {
 			logger.WithValues("clusterName", clusterName).V(4).Info("Unknown cluster")
 			responsewriters.Forbidden(req.Context(), attributes, w, req, kcpauthorization.WorkspaceAccessNotPermittedReason, kubernetesscheme.Codecs)
@@ -46,6 +46,9 @@ const (
 // Index implements a mapping from logical cluster to (shard) URL.
 type Index interface {
 	Lookup(logicalCluster logicalcluster.Name) (string, bool)
+	// Moved returns the current name of a logical cluster that was recently moved
+	// away from the given one, or one of its ancestors was.
+	Moved(logicalCluster logicalcluster.Name) (logicalcluster.Name, bool)
 }
 
 type ClusterWorkspaceClientGetter func(shard *tenancyv1alpha1.ClusterWorkspaceShard) (kcpclientset.ClusterInterface, error)
@@ -72,6 +75,7 @@ func NewController(
 
 		workspaceShardNames: map[logicalcluster.Name]string{},
 		shardBaseURLs:       map[string]string{},
+		movedFrom:           map[logicalcluster.Name]logicalcluster.Name{},
 	}
 
 	c.clusterWorkspaceHandler = cache.ResourceEventHandlerFuncs{
@@ -83,9 +87,11 @@ func NewController(
 
 			if expected := ws.Status.Location.Current; got != expected {
 				c.lock.Lock()
-				defer c.lock.Unlock()
 				c.workspaceShardNames[logicalcluster.From(ws).Join(ws.Name)] = expected
+				c.lock.Unlock()
 			}
+
+			c.updateMovedFrom(ws)
 		},
 		UpdateFunc: func(old, obj interface{}) {
 			ws := obj.(*tenancyv1alpha1.ClusterWorkspace)
@@ -96,9 +102,11 @@ func NewController(
 
 			if expected := ws.Status.Location.Current; got != expected {
 				c.lock.Lock()
-				defer c.lock.Unlock()
 				c.workspaceShardNames[logicalcluster.From(ws).Join(ws.Name)] = expected
+				c.lock.Unlock()
 			}
+
+			c.updateMovedFrom(ws)
 		},
 		DeleteFunc: func(obj interface{}) {
 			if final, ok := obj.(cache.DeletedFinalStateUnknown); ok {
@@ -109,6 +117,11 @@ func NewController(
 			c.lock.Lock()
 			defer c.lock.Unlock()
 			delete(c.workspaceShardNames, logicalcluster.From(ws).Join(ws.Name))
+			for from, to := range c.movedFrom {
+				if to == logicalcluster.From(ws).Join(ws.Name) {
+					delete(c.movedFrom, from)
+				}
+			}
 		},
 	}
 
@@ -179,6 +192,8 @@ type Controller struct {
 	lock                sync.RWMutex
 	workspaceShardNames map[logicalcluster.Name]string
 	shardBaseURLs       map[string]string
+	// movedFrom maps the former name of a recently moved workspace to its current name.
+	movedFrom map[logicalcluster.Name]logicalcluster.Name
 }
 
 // Start the controller. It does not really do anything, but to keep the shape of a normal
@@ -301,3 +316,35 @@ func (c *Controller) Lookup(logicalCluster logicalcluster.Name) (string, bool) {
 	url, found := c.shardBaseURLs[shardName]
 	return url, found
 }
+
+func (c *Controller) Moved(logicalCluster logicalcluster.Name) (logicalcluster.Name, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	// walk up until we find a former name of a moved workspace, and map the rest of the path below it.
+	for cur, suffix := logicalCluster, ""; cur != tenancyv1alpha1.RootCluster && !cur.Empty(); {
+		if to, found := c.movedFrom[cur]; found {
+			return logicalcluster.New(to.String() + suffix), true
+		}
+		parent, name := cur.Split()
+		cur, suffix = parent, ":"+name+suffix
+	}
+	return logicalcluster.Name{}, false
+}
+
+func (c *Controller) updateMovedFrom(ws *tenancyv1alpha1.ClusterWorkspace) {
+	current := logicalcluster.From(ws).Join(ws.Name)
+	from, hasFrom := ws.Annotations[tenancyv1alpha1.ClusterWorkspaceMovedFromAnnotationKey]
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for old, to := range c.movedFrom {
+		if to == current && old.String() != from {
+			delete(c.movedFrom, old)
+		}
+	}
+	if hasFrom && from != "" {
+		c.movedFrom[logicalcluster.New(from)] = current
+	}
+}
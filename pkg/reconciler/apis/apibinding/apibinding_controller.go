@@ -30,6 +30,7 @@ import (
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	kcpapiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/kcp/clientset/versioned"
 	kcpapiextensionsv1informers "k8s.io/apiextensions-apiserver/pkg/client/kcp/informers/externalversions/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -41,10 +42,12 @@ import (
 	"k8s.io/klog/v2"
 
 	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
 	"github.com/kcp-dev/kcp/pkg/client"
 	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/cluster"
 	apisv1alpha1client "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/typed/apis/v1alpha1"
 	apisv1alpha1informers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/apis/v1alpha1"
+	tenancyv1alpha1informers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/tenancy/v1alpha1"
 	apisv1alpha1listers "github.com/kcp-dev/kcp/pkg/client/listers/apis/v1alpha1"
 	"github.com/kcp-dev/kcp/pkg/informer"
 	"github.com/kcp-dev/kcp/pkg/logging"
@@ -71,6 +74,7 @@ func NewController(
 	temporaryRemoteShardApiExportInformer apisv1alpha1informers.APIExportClusterInformer, /*TODO(p0lyn0mial): replace with multi-shard informers*/
 	temporaryRemoteShardApiResourceSchemaInformer apisv1alpha1informers.APIResourceSchemaClusterInformer, /*TODO(p0lyn0mial): replace with multi-shard informers*/
 	crdInformer kcpapiextensionsv1informers.CustomResourceDefinitionClusterInformer,
+	clusterWorkspaceInformer tenancyv1alpha1informers.ClusterWorkspaceClusterInformer,
 ) (*controller, error) {
 	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), ControllerName)
 
@@ -129,6 +133,10 @@ func NewController(
 		listCRDs: func(clusterName logicalcluster.Name) ([]*apiextensionsv1.CustomResourceDefinition, error) {
 			return crdInformer.Lister().Cluster(clusterName).List(labels.Everything())
 		},
+		getClusterWorkspace: func(clusterName logicalcluster.Name) (*tenancyv1alpha1.ClusterWorkspace, error) {
+			parent, workspace := clusterName.Split()
+			return clusterWorkspaceInformer.Lister().Cluster(parent).Get(workspace)
+		},
 		deletedCRDTracker: newLockedStringSet(),
 		commit:            committer.NewCommitter[*APIBinding, Patcher, *APIBindingSpec, *APIBindingStatus](kcpClusterClient.ApisV1alpha1().APIBindings()),
 	}
@@ -197,6 +205,23 @@ func NewController(
 		DeleteFunc: func(obj interface{}) { c.enqueueAPIResourceSchema(obj, logger, "") },
 	})
 
+	clusterWorkspaceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(old, obj interface{}) {
+			oldWorkspace, ok := old.(*tenancyv1alpha1.ClusterWorkspace)
+			if !ok {
+				return
+			}
+			newWorkspace, ok := obj.(*tenancyv1alpha1.ClusterWorkspace)
+			if !ok {
+				return
+			}
+			// workspace labels select APIBindings for staged schema rollouts
+			if !equality.Semantic.DeepEqual(oldWorkspace.Labels, newWorkspace.Labels) {
+				c.enqueueClusterWorkspace(newWorkspace, logger)
+			}
+		},
+	})
+
 	if err := c.apiExportsIndexer.AddIndexers(cache.Indexers{
 		indexAPIExportsByAPIResourceSchema: indexAPIExportsByAPIResourceSchemasFunc,
 	}); err != nil {
@@ -243,6 +268,8 @@ type controller struct {
 	getCRD    func(clusterName logicalcluster.Name, name string) (*apiextensionsv1.CustomResourceDefinition, error)
 	listCRDs  func(clusterName logicalcluster.Name) ([]*apiextensionsv1.CustomResourceDefinition, error)
 
+	getClusterWorkspace func(clusterName logicalcluster.Name) (*tenancyv1alpha1.ClusterWorkspace, error)
+
 	deletedCRDTracker *lockedStringSet
 	commit            CommitFunc
 }
@@ -278,6 +305,20 @@ func (c *controller) enqueueAPIExport(obj interface{}, logger logr.Logger, logSu
 	}
 }
 
+// enqueueClusterWorkspace enqueues all APIBindings in the logical cluster of the given ClusterWorkspace.
+func (c *controller) enqueueClusterWorkspace(clusterWorkspace *tenancyv1alpha1.ClusterWorkspace, logger logr.Logger) {
+	clusterName := logicalcluster.From(clusterWorkspace).Join(clusterWorkspace.Name)
+	apiBindings, err := c.listAPIBindings(clusterName)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+
+	for _, binding := range apiBindings {
+		c.enqueueAPIBinding(binding, logging.WithObject(logger, clusterWorkspace), " because of ClusterWorkspace")
+	}
+}
+
 // enqueueCRD maps a CRD to APIResourceSchema for enqueuing.
 func (c *controller) enqueueCRD(obj interface{}, logger logr.Logger) {
 	crd, ok := obj.(*apiextensionsv1.CustomResourceDefinition)
@@ -0,0 +1,253 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apibinding
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsvalidation "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/validation"
+	kcpapiextensionsv1informers "k8s.io/apiextensions-apiserver/pkg/client/kcp/informers/externalversions/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	apisv1alpha1informers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/apis/v1alpha1"
+)
+
+// DryRunner previews the outcome of binding an APIBinding. It runs the same conflict, schema and permission claim
+// checks as the APIBinding controller, but neither creates CRDs nor changes the APIBinding.
+type DryRunner struct {
+	listAPIBindings      func(clusterName logicalcluster.Name) ([]*apisv1alpha1.APIBinding, error)
+	getAPIExport         func(clusterName logicalcluster.Name, name string) (*apisv1alpha1.APIExport, error)
+	getAPIResourceSchema func(clusterName logicalcluster.Name, name string) (*apisv1alpha1.APIResourceSchema, error)
+	getCRD               func(clusterName logicalcluster.Name, name string) (*apiextensionsv1.CustomResourceDefinition, error)
+	listCRDs             func(clusterName logicalcluster.Name) ([]*apiextensionsv1.CustomResourceDefinition, error)
+}
+
+// NewDryRunner returns a DryRunner reading from the given informers. Like in the APIBinding controller,
+// APIExports and APIResourceSchemas not found locally are read from the cached informers, which hold the
+// objects of other shards. The cached informers are nil if the cache server is disabled.
+func NewDryRunner(
+	apiBindingInformer apisv1alpha1informers.APIBindingClusterInformer,
+	apiExportInformer apisv1alpha1informers.APIExportClusterInformer,
+	apiResourceSchemaInformer apisv1alpha1informers.APIResourceSchemaClusterInformer,
+	cachedAPIExportInformer apisv1alpha1informers.APIExportClusterInformer,
+	cachedAPIResourceSchemaInformer apisv1alpha1informers.APIResourceSchemaClusterInformer,
+	crdInformer kcpapiextensionsv1informers.CustomResourceDefinitionClusterInformer,
+) *DryRunner {
+	apiBindingLister := apiBindingInformer.Lister()
+	apiExportLister := apiExportInformer.Lister()
+	apiResourceSchemaLister := apiResourceSchemaInformer.Lister()
+	crdLister := crdInformer.Lister()
+
+	return &DryRunner{
+		listAPIBindings: func(clusterName logicalcluster.Name) ([]*apisv1alpha1.APIBinding, error) {
+			return apiBindingLister.Cluster(clusterName).List(labels.Everything())
+		},
+		getAPIExport: func(clusterName logicalcluster.Name, name string) (*apisv1alpha1.APIExport, error) {
+			apiExport, err := apiExportLister.Cluster(clusterName).Get(name)
+			if errors.IsNotFound(err) && cachedAPIExportInformer != nil {
+				return cachedAPIExportInformer.Lister().Cluster(clusterName).Get(name)
+			}
+			return apiExport, err
+		},
+		getAPIResourceSchema: func(clusterName logicalcluster.Name, name string) (*apisv1alpha1.APIResourceSchema, error) {
+			apiResourceSchema, err := apiResourceSchemaLister.Cluster(clusterName).Get(name)
+			if errors.IsNotFound(err) && cachedAPIResourceSchemaInformer != nil {
+				return cachedAPIResourceSchemaInformer.Lister().Cluster(clusterName).Get(name)
+			}
+			return apiResourceSchema, err
+		},
+		getCRD: func(clusterName logicalcluster.Name, name string) (*apiextensionsv1.CustomResourceDefinition, error) {
+			return crdLister.Cluster(clusterName).Get(name)
+		},
+		listCRDs: func(clusterName logicalcluster.Name) ([]*apiextensionsv1.CustomResourceDefinition, error) {
+			return crdLister.Cluster(clusterName).List(labels.Everything())
+		},
+	}
+}
+
+// DryRun returns the resources the given APIBinding would bind, the resources that cannot be bound, and the
+// permission claims that need a decision by the user. An error is returned if the referenced APIExport cannot
+// be bound at all.
+func (d *DryRunner) DryRun(ctx context.Context, apiBinding *apisv1alpha1.APIBinding) (*apisv1alpha1.APIBindingDryRunResult, error) {
+	apiExportClusterName, apiExportName, err := getAPIExportClusterAndName(apiBinding)
+	if err != nil {
+		return nil, err
+	}
+
+	apiExport, err := d.getAPIExport(apiExportClusterName, apiExportName)
+	if err != nil {
+		return nil, fmt.Errorf("error getting APIExport %s|%s: %w", apiExportClusterName, apiExportName, err)
+	}
+	if selector := apiBinding.Spec.Reference.Selector; selector != nil {
+		if err := validateSelectedAPIExport(selector, apiExportClusterName, apiExport); err != nil {
+			return nil, fmt.Errorf("APIExport %s|%s: %w", apiExportClusterName, apiExportName, err)
+		}
+	}
+	if apiExport.Status.IdentityHash == "" {
+		return nil, fmt.Errorf("APIExport %s|%s is missing status.identityHash", apiExportClusterName, apiExportName)
+	}
+
+	result := &apisv1alpha1.APIBindingDryRunResult{}
+
+	for _, schemaName := range apiExport.Spec.LatestResourceSchemas {
+		schema, err := d.getAPIResourceSchema(apiExportClusterName, schemaName)
+		if err != nil {
+			return nil, fmt.Errorf("error getting APIResourceSchema %s|%s: %w", apiExportClusterName, schemaName, err)
+		}
+
+		conflict, err := d.checkSchema(ctx, apiBinding, schema)
+		if err != nil {
+			return nil, err
+		}
+		if conflict != nil {
+			result.Conflicts = append(result.Conflicts, *conflict)
+			continue
+		}
+
+		storageVersions := sets.NewString()
+		for _, v := range schema.Spec.Versions {
+			if v.Storage {
+				storageVersions.Insert(v.Name)
+			}
+		}
+		for _, b := range apiBinding.Status.BoundResources {
+			if b.Group == schema.Spec.Group && b.Resource == schema.Spec.Names.Plural {
+				storageVersions.Insert(b.StorageVersions...)
+				break
+			}
+		}
+
+		result.BoundResources = append(result.BoundResources, apisv1alpha1.BoundAPIResource{
+			Group:    schema.Spec.Group,
+			Resource: schema.Spec.Names.Plural,
+			Schema: apisv1alpha1.BoundAPIResourceSchema{
+				Name:         schema.Name,
+				UID:          string(schema.UID),
+				IdentityHash: apiExport.Status.IdentityHash,
+			},
+			StorageVersions: storageVersions.List(),
+		})
+	}
+
+	result.PendingPermissionClaims, result.InvalidPermissionClaims = permissionClaimsNeedingAction(apiExport, apiBinding)
+
+	return result, nil
+}
+
+// checkSchema returns a conflict if the given schema cannot be bound by the APIBinding.
+func (d *DryRunner) checkSchema(ctx context.Context, apiBinding *apisv1alpha1.APIBinding, schema *apisv1alpha1.APIResourceSchema) (*apisv1alpha1.APIBindingConflict, error) {
+	newConflict := func(reason, message string) *apisv1alpha1.APIBindingConflict {
+		return &apisv1alpha1.APIBindingConflict{
+			Group:    schema.Spec.Group,
+			Resource: schema.Spec.Names.Plural,
+			Reason:   reason,
+			Message:  message,
+		}
+	}
+
+	checker := &conflictChecker{
+		listAPIBindings:      d.listAPIBindings,
+		getAPIExport:         d.getAPIExport,
+		getAPIResourceSchema: d.getAPIResourceSchema,
+		getCRD:               d.getCRD,
+		listCRDs:             d.listCRDs,
+	}
+	if err := checker.checkForConflicts(schema, apiBinding); err != nil {
+		return newConflict(apisv1alpha1.NamingConflictsReason, err.Error()), nil
+	}
+
+	if missing := missingStorageVersions(apiBinding, schema); len(missing) > 0 {
+		return newConflict(apisv1alpha1.StorageVersionsMissingReason, fmt.Sprintf("APIResourceSchema %s|%s does not define the stored versions %s",
+			logicalcluster.From(schema), schema.Name, strings.Join(missing, ", "))), nil
+	}
+
+	if errs, err := validateBoundCRD(ctx, schema); err != nil {
+		return nil, err
+	} else if len(errs) > 0 {
+		return newConflict(apisv1alpha1.APIResourceSchemaInvalidReason, fmt.Sprintf("APIResourceSchema %s|%s is invalid: %v",
+			logicalcluster.From(schema), schema.Name, errs.ToAggregate())), nil
+	}
+
+	return nil, nil
+}
+
+// validateBoundCRD validates the CRD generated for the schema like the CRD registry would on creation.
+func validateBoundCRD(ctx context.Context, schema *apisv1alpha1.APIResourceSchema) (errs field.ErrorList, err error) {
+	crd, err := generateCRD(schema)
+	if err != nil {
+		return nil, fmt.Errorf("error generating CRD for APIResourceSchema %s|%s: %w", logicalcluster.From(schema), schema.Name, err)
+	}
+	apiextensionsv1.SetObjectDefaults_CustomResourceDefinition(crd)
+
+	internal := &apiextensions.CustomResourceDefinition{}
+	if err := apiextensionsv1.Convert_v1_CustomResourceDefinition_To_apiextensions_CustomResourceDefinition(crd, internal, nil); err != nil {
+		return nil, fmt.Errorf("error converting CRD for APIResourceSchema %s|%s: %w", logicalcluster.From(schema), schema.Name, err)
+	}
+	// the stored versions are set by the CRD registry before validation
+	for _, v := range internal.Spec.Versions {
+		if v.Storage {
+			internal.Status.StoredVersions = append(internal.Status.StoredVersions, v.Name)
+		}
+	}
+
+	return apiextensionsvalidation.ValidateCustomResourceDefinition(ctx, internal), nil
+}
+
+// permissionClaimsNeedingAction returns the permission claims of the APIExport that are neither accepted nor
+// rejected by the APIBinding, and the accepted claims of the APIBinding that the APIExport does not claim.
+func permissionClaimsNeedingAction(apiExport *apisv1alpha1.APIExport, apiBinding *apisv1alpha1.APIBinding) (pending, invalid []apisv1alpha1.PermissionClaim) {
+	for _, claim := range apiExport.Spec.PermissionClaims {
+		decided := false
+		for _, acceptable := range apiBinding.Spec.PermissionClaims {
+			if acceptable.Equal(claim) {
+				decided = true
+				break
+			}
+		}
+		if !decided {
+			pending = append(pending, claim)
+		}
+	}
+
+	for _, acceptable := range apiBinding.Spec.PermissionClaims {
+		if acceptable.State != apisv1alpha1.ClaimAccepted {
+			continue
+		}
+		exported := false
+		for _, claim := range apiExport.Spec.PermissionClaims {
+			if claim.Equal(acceptable.PermissionClaim) {
+				exported = true
+				break
+			}
+		}
+		if !exported {
+			invalid = append(invalid, acceptable.PermissionClaim)
+		}
+	}
+
+	return pending, invalid
+}
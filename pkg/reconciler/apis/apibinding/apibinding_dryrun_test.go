@@ -0,0 +1,334 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apibinding
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	kcpapiextensionsfakeclient "k8s.io/apiextensions-apiserver/pkg/client/kcp/clientset/versioned/fake"
+	kcpapiextensionsinformers "k8s.io/apiextensions-apiserver/pkg/client/kcp/informers/externalversions"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	kcpfakeclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/cluster/fake"
+	kcpinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions"
+)
+
+func TestDryRun(t *testing.T) {
+	configMapsClaim := apisv1alpha1.PermissionClaim{GroupResource: apisv1alpha1.GroupResource{Resource: "configmaps"}, All: true}
+	secretsClaim := apisv1alpha1.PermissionClaim{GroupResource: apisv1alpha1.GroupResource{Resource: "secrets"}, All: true}
+
+	newAPIExport := func(name string, schemas ...string) *apisv1alpha1.APIExport {
+		return &apisv1alpha1.APIExport{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{logicalcluster.AnnotationKey: "org:some-workspace"},
+				Name:        name,
+			},
+			Spec: apisv1alpha1.APIExportSpec{
+				LatestResourceSchemas: schemas,
+			},
+			Status: apisv1alpha1.APIExportStatus{IdentityHash: "hash"},
+		}
+	}
+
+	// the CRD of a schema is validated, hence the scope must be valid
+	widgetsAPIResourceSchema := todayWidgetsAPIResourceSchema.DeepCopy()
+	widgetsAPIResourceSchema.Spec.Scope = apiextensionsv1.NamespaceScoped
+
+	tomorrowWidgetsAPIResourceSchema := widgetsAPIResourceSchema.DeepCopy()
+	tomorrowWidgetsAPIResourceSchema.Name = "tomorrow.widgets.kcp.dev"
+	tomorrowWidgetsAPIResourceSchema.UID = "tomorrowwidgetsuid"
+
+	invalidWidgetsAPIResourceSchema := widgetsAPIResourceSchema.DeepCopy()
+	invalidWidgetsAPIResourceSchema.Name = "invalid.widgets.kcp.dev"
+	invalidWidgetsAPIResourceSchema.Spec.Versions[0].Schema = runtime.RawExtension{Raw: []byte(`{"type":"object","properties":{"spec":{"type":"unknown"}}}`)}
+
+	tests := map[string]struct {
+		apiBinding  *apisv1alpha1.APIBinding
+		apiExports  map[string]*apisv1alpha1.APIExport
+		apiBindings []*apisv1alpha1.APIBinding
+		crds        map[logicalcluster.Name][]*apiextensionsv1.CustomResourceDefinition
+
+		want    *apisv1alpha1.APIBindingDryRunResult
+		wantErr string
+	}{
+		"resources are bound": {
+			apiBinding: unbound.Build(),
+			apiExports: map[string]*apisv1alpha1.APIExport{
+				"some-export": newAPIExport("some-export", "today.widgets.kcp.dev"),
+			},
+			want: &apisv1alpha1.APIBindingDryRunResult{
+				BoundResources: []apisv1alpha1.BoundAPIResource{
+					{
+						Group:           "kcp.dev",
+						Resource:        "widgets",
+						Schema:          apisv1alpha1.BoundAPIResourceSchema{Name: "today.widgets.kcp.dev", UID: "todaywidgetsuid", IdentityHash: "hash"},
+						StorageVersions: []string{"v1"},
+					},
+				},
+			},
+		},
+		"naming conflict with another binding": {
+			apiBinding: unbound.Build(),
+			apiExports: map[string]*apisv1alpha1.APIExport{
+				"some-export": newAPIExport("some-export", "today.widgets.kcp.dev"),
+				"conflict":    newAPIExport("conflict", "another.widgets.kcp.dev"),
+			},
+			apiBindings: []*apisv1alpha1.APIBinding{conflicting.Build()},
+			crds: map[logicalcluster.Name][]*apiextensionsv1.CustomResourceDefinition{
+				ShadowWorkspaceName: {
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "anotherwidgetsuid"},
+						Spec:       apiextensionsv1.CustomResourceDefinitionSpec{Group: "kcp.dev"},
+						Status: apiextensionsv1.CustomResourceDefinitionStatus{
+							AcceptedNames: apiextensionsv1.CustomResourceDefinitionNames{Plural: "widgets", Kind: "Widget"},
+						},
+					},
+				},
+			},
+			want: &apisv1alpha1.APIBindingDryRunResult{
+				Conflicts: []apisv1alpha1.APIBindingConflict{
+					{
+						Group:    "kcp.dev",
+						Resource: "widgets",
+						Reason:   apisv1alpha1.NamingConflictsReason,
+						Message:  "naming conflict with a bound API conflicting, spec.names.plural=widgets is forbidden",
+					},
+				},
+			},
+		},
+		"conflict with a CRD in the workspace": {
+			apiBinding: unbound.Build(),
+			apiExports: map[string]*apisv1alpha1.APIExport{
+				"some-export": newAPIExport("some-export", "today.widgets.kcp.dev"),
+			},
+			crds: map[logicalcluster.Name][]*apiextensionsv1.CustomResourceDefinition{
+				logicalcluster.New("org:ws"): {
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "widgets.kcp.dev"},
+						Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+							Group: "kcp.dev",
+							Names: apiextensionsv1.CustomResourceDefinitionNames{Plural: "widgets"},
+						},
+					},
+				},
+			},
+			want: &apisv1alpha1.APIBindingDryRunResult{
+				Conflicts: []apisv1alpha1.APIBindingConflict{
+					{
+						Group:    "kcp.dev",
+						Resource: "widgets",
+						Reason:   apisv1alpha1.NamingConflictsReason,
+						Message:  `cannot create CustomResourceDefinition with "kcp.dev" group and "widgets" resource because it overlaps with "widgets.kcp.dev" CustomResourceDefinition in "org:ws" logical cluster`,
+					},
+				},
+			},
+		},
+		"stored versions missing in the new schema": {
+			apiBinding: rebinding.Build(),
+			apiExports: map[string]*apisv1alpha1.APIExport{
+				"some-export": newAPIExport("some-export", "tomorrow.widgets.kcp.dev"),
+			},
+			want: &apisv1alpha1.APIBindingDryRunResult{
+				Conflicts: []apisv1alpha1.APIBindingConflict{
+					{
+						Group:    "kcp.dev",
+						Resource: "widgets",
+						Reason:   apisv1alpha1.StorageVersionsMissingReason,
+						Message:  "APIResourceSchema some-workspace|tomorrow.widgets.kcp.dev does not define the stored versions v0",
+					},
+				},
+			},
+		},
+		"stored versions are kept": {
+			apiBinding: rebinding.Build(),
+			apiExports: map[string]*apisv1alpha1.APIExport{
+				"some-export": newAPIExport("some-export", "today.widgets.kcp.dev"),
+			},
+			want: &apisv1alpha1.APIBindingDryRunResult{
+				BoundResources: []apisv1alpha1.BoundAPIResource{
+					{
+						Group:           "kcp.dev",
+						Resource:        "widgets",
+						Schema:          apisv1alpha1.BoundAPIResourceSchema{Name: "today.widgets.kcp.dev", UID: "todaywidgetsuid", IdentityHash: "hash"},
+						StorageVersions: []string{"v0", "v1"},
+					},
+				},
+			},
+		},
+		"invalid schema": {
+			apiBinding: unbound.Build(),
+			apiExports: map[string]*apisv1alpha1.APIExport{
+				"some-export": newAPIExport("some-export", "invalid.widgets.kcp.dev"),
+			},
+			want: &apisv1alpha1.APIBindingDryRunResult{
+				Conflicts: []apisv1alpha1.APIBindingConflict{
+					{
+						Group:    "kcp.dev",
+						Resource: "widgets",
+						Reason:   apisv1alpha1.APIResourceSchemaInvalidReason,
+					},
+				},
+			},
+		},
+		"permission claims needing action": {
+			apiBinding: func() *apisv1alpha1.APIBinding {
+				b := unbound.Build()
+				b.Spec.PermissionClaims = []apisv1alpha1.AcceptablePermissionClaim{
+					{PermissionClaim: secretsClaim, State: apisv1alpha1.ClaimAccepted},
+				}
+				return b
+			}(),
+			apiExports: map[string]*apisv1alpha1.APIExport{
+				"some-export": func() *apisv1alpha1.APIExport {
+					e := newAPIExport("some-export")
+					e.Spec.PermissionClaims = []apisv1alpha1.PermissionClaim{configMapsClaim}
+					return e
+				}(),
+			},
+			want: &apisv1alpha1.APIBindingDryRunResult{
+				PendingPermissionClaims: []apisv1alpha1.PermissionClaim{configMapsClaim},
+				InvalidPermissionClaims: []apisv1alpha1.PermissionClaim{secretsClaim},
+			},
+		},
+		"APIExport not found": {
+			apiBinding: unbound.Build(),
+			wantErr:    `error getting APIExport org:some-workspace|some-export: apiexports.apis.kcp.dev "some-export" not found`,
+		},
+		"APIExport without identity": {
+			apiBinding: unbound.Build(),
+			apiExports: map[string]*apisv1alpha1.APIExport{
+				"some-export": func() *apisv1alpha1.APIExport {
+					e := newAPIExport("some-export", "today.widgets.kcp.dev")
+					e.Status.IdentityHash = ""
+					return e
+				}(),
+			},
+			wantErr: "APIExport org:some-workspace|some-export is missing status.identityHash",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			d := &DryRunner{
+				listAPIBindings: func(clusterName logicalcluster.Name) ([]*apisv1alpha1.APIBinding, error) {
+					return tc.apiBindings, nil
+				},
+				getAPIExport: func(clusterName logicalcluster.Name, name string) (*apisv1alpha1.APIExport, error) {
+					if e, found := tc.apiExports[name]; found {
+						return e, nil
+					}
+					return nil, apierrors.NewNotFound(apisv1alpha1.Resource("apiexports"), name)
+				},
+				getAPIResourceSchema: func(clusterName logicalcluster.Name, name string) (*apisv1alpha1.APIResourceSchema, error) {
+					for _, s := range []*apisv1alpha1.APIResourceSchema{widgetsAPIResourceSchema, someOtherWidgetsAPIResourceSchema, tomorrowWidgetsAPIResourceSchema, invalidWidgetsAPIResourceSchema} {
+						if s.Name == name {
+							return s, nil
+						}
+					}
+					return nil, apierrors.NewNotFound(apisv1alpha1.Resource("apiresourceschemas"), name)
+				},
+				getCRD: func(clusterName logicalcluster.Name, name string) (*apiextensionsv1.CustomResourceDefinition, error) {
+					for _, crd := range tc.crds[clusterName] {
+						if crd.Name == name {
+							return crd, nil
+						}
+					}
+					return nil, apierrors.NewNotFound(apiextensionsv1.Resource("customresourcedefinitions"), name)
+				},
+				listCRDs: func(clusterName logicalcluster.Name) ([]*apiextensionsv1.CustomResourceDefinition, error) {
+					return tc.crds[clusterName], nil
+				},
+			}
+
+			got, err := d.DryRun(context.Background(), tc.apiBinding)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			// schema validation messages are owned by apiextensions, hence only check that there is one.
+			for i := range got.Conflicts {
+				if got.Conflicts[i].Reason == apisv1alpha1.APIResourceSchemaInvalidReason {
+					require.Contains(t, got.Conflicts[i].Message, "APIResourceSchema some-workspace|invalid.widgets.kcp.dev is invalid")
+					got.Conflicts[i].Message = ""
+				}
+			}
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestNewDryRunnerReadsFromCache(t *testing.T) {
+	apiExport := &apisv1alpha1.APIExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{logicalcluster.AnnotationKey: "org:some-workspace"},
+			Name:        "some-export",
+		},
+		Spec:   apisv1alpha1.APIExportSpec{LatestResourceSchemas: []string{"today.widgets.kcp.dev"}},
+		Status: apisv1alpha1.APIExportStatus{IdentityHash: "hash"},
+	}
+	schema := todayWidgetsAPIResourceSchema.DeepCopy()
+	schema.Annotations[logicalcluster.AnnotationKey] = "org:some-workspace"
+	schema.Spec.Scope = apiextensionsv1.NamespaceScoped
+
+	kcpInformers := kcpinformers.NewSharedInformerFactory(kcpfakeclient.NewSimpleClientset(), 0)
+	cacheKcpInformers := kcpinformers.NewSharedInformerFactory(kcpfakeclient.NewSimpleClientset(), 0)
+	crdInformers := kcpapiextensionsinformers.NewSharedInformerFactory(kcpapiextensionsfakeclient.NewSimpleClientset(), 0)
+	require.NoError(t, cacheKcpInformers.Apis().V1alpha1().APIExports().Informer().GetIndexer().Add(apiExport))
+	require.NoError(t, cacheKcpInformers.Apis().V1alpha1().APIResourceSchemas().Informer().GetIndexer().Add(schema))
+
+	d := NewDryRunner(
+		kcpInformers.Apis().V1alpha1().APIBindings(),
+		kcpInformers.Apis().V1alpha1().APIExports(),
+		kcpInformers.Apis().V1alpha1().APIResourceSchemas(),
+		nil,
+		nil,
+		crdInformers.Apiextensions().V1().CustomResourceDefinitions(),
+	)
+	_, err := d.DryRun(context.Background(), unbound.Build())
+	require.EqualError(t, err, `error getting APIExport org:some-workspace|some-export: APIExport.apis.kcp.dev "some-export" not found`)
+
+	d = NewDryRunner(
+		kcpInformers.Apis().V1alpha1().APIBindings(),
+		kcpInformers.Apis().V1alpha1().APIExports(),
+		kcpInformers.Apis().V1alpha1().APIResourceSchemas(),
+		cacheKcpInformers.Apis().V1alpha1().APIExports(),
+		cacheKcpInformers.Apis().V1alpha1().APIResourceSchemas(),
+		crdInformers.Apiextensions().V1().CustomResourceDefinitions(),
+	)
+	got, err := d.DryRun(context.Background(), unbound.Build())
+	require.NoError(t, err)
+	require.Equal(t, &apisv1alpha1.APIBindingDryRunResult{
+		BoundResources: []apisv1alpha1.BoundAPIResource{
+			{
+				Group:           "kcp.dev",
+				Resource:        "widgets",
+				Schema:          apisv1alpha1.BoundAPIResourceSchema{Name: "today.widgets.kcp.dev", UID: "todaywidgetsuid", IdentityHash: "hash"},
+				StorageVersions: []string{"v1"},
+			},
+		},
+	}, got)
+}
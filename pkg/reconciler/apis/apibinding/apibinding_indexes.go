@@ -28,20 +28,15 @@ import (
 const indexAPIBindingsByWorkspaceExport = "apiBindingsByWorkspaceExport"
 
 // indexAPIBindingsByWorkspaceExportFunc is an index function that maps an APIBinding to the key for its
-// spec.reference.workspace.
+// referenced APIExport.
 func indexAPIBindingsByWorkspaceExportFunc(obj interface{}) ([]string, error) {
 	apiBinding, ok := obj.(*apisv1alpha1.APIBinding)
 	if !ok {
 		return []string{}, fmt.Errorf("obj is supposed to be an APIBinding, but is %T", obj)
 	}
 
-	if apiBinding.Spec.Reference.Workspace != nil {
-		apiExportClusterName := logicalcluster.New(apiBinding.Spec.Reference.Workspace.Path)
-		if !ok {
-			// this will never happen due to validation
-			return []string{}, fmt.Errorf("invalid export reference")
-		}
-		key := client.ToClusterAwareKey(apiExportClusterName, apiBinding.Spec.Reference.Workspace.ExportName)
+	if apiExportClusterName, apiExportName, ok := apisv1alpha1.ReferencedAPIExport(apiBinding); ok {
+		key := client.ToClusterAwareKey(apiExportClusterName, apiExportName)
 		return []string{key}, nil
 	}
 
@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/kcp-dev/logicalcluster/v2"
@@ -30,6 +31,7 @@ import (
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 
@@ -75,23 +77,9 @@ func (c *controller) reconcileBinding(ctx context.Context, apiBinding *apisv1alp
 	logger := klog.FromContext(ctx)
 
 	// Check for valid reference
-	workspaceRef := apiBinding.Spec.Reference.Workspace
-	if workspaceRef == nil {
-		// this should not happen because of OpenAPI
-		conditions.MarkFalse(
-			apiBinding,
-			apisv1alpha1.APIExportValid,
-			apisv1alpha1.APIExportInvalidReferenceReason,
-			conditionsv1alpha1.ConditionSeverityError,
-			"Missing APIExport reference",
-		)
-		return nil
-	}
-
-	// Get APIExport clusterName
-	apiExportClusterName, err := getAPIExportClusterName(apiBinding)
+	apiExportClusterName, apiExportName, err := getAPIExportClusterAndName(apiBinding)
 	if err != nil {
-		// this should not happen because of OpenAPI
+		// this should not happen because of OpenAPI and admission
 		conditions.MarkFalse(
 			apiBinding,
 			apisv1alpha1.APIExportValid,
@@ -103,7 +91,7 @@ func (c *controller) reconcileBinding(ctx context.Context, apiBinding *apisv1alp
 	}
 
 	// Get APIExport
-	apiExport, err := c.getAPIExport(apiExportClusterName, workspaceRef.ExportName)
+	apiExport, err := c.getAPIExport(apiExportClusterName, apiExportName)
 	if apierrors.IsNotFound(err) {
 		conditions.MarkFalse(
 			apiBinding,
@@ -112,7 +100,7 @@ func (c *controller) reconcileBinding(ctx context.Context, apiBinding *apisv1alp
 			conditionsv1alpha1.ConditionSeverityError,
 			"APIExport %s|%s not found",
 			apiExportClusterName,
-			workspaceRef.ExportName,
+			apiExportName,
 		)
 		return nil
 	}
@@ -124,7 +112,7 @@ func (c *controller) reconcileBinding(ctx context.Context, apiBinding *apisv1alp
 			conditionsv1alpha1.ConditionSeverityError,
 			"Error getting APIExport %s|%s: %v",
 			apiExportClusterName,
-			workspaceRef.ExportName,
+			apiExportName,
 			err,
 		)
 		return err
@@ -132,6 +120,23 @@ func (c *controller) reconcileBinding(ctx context.Context, apiBinding *apisv1alp
 
 	logger = logging.WithObject(logger, apiExport)
 
+	// Make sure the APIExport selected on creation is still matched by the selector
+	if selector := apiBinding.Spec.Reference.Selector; selector != nil {
+		if err := validateSelectedAPIExport(selector, apiExportClusterName, apiExport); err != nil {
+			conditions.MarkFalse(
+				apiBinding,
+				apisv1alpha1.APIExportValid,
+				apisv1alpha1.APIExportInvalidReferenceReason,
+				conditionsv1alpha1.ConditionSeverityError,
+				"APIExport %s|%s: %v",
+				apiExportClusterName,
+				apiExportName,
+				err,
+			)
+			return nil
+		}
+	}
+
 	// Record the export's permission claims
 	apiBinding.Status.ExportPermissionClaims = apiExport.Spec.PermissionClaims
 
@@ -144,15 +149,37 @@ func (c *controller) reconcileBinding(ctx context.Context, apiBinding *apisv1alp
 			conditionsv1alpha1.ConditionSeverityWarning,
 			"APIExport %s|%s is missing status.identityHash",
 			apiExportClusterName,
-			workspaceRef.ExportName,
+			apiExportName,
 		)
 		return nil
 	}
 
+	// Determine the schemas to bind according to the schema rollout policy of the APIExport
+	schemaNames := apiExport.Spec.LatestResourceSchemas
+	rolloutPending := false
+	if bound := boundSchemaNames(apiBinding); !sets.NewString(bound...).Equal(sets.NewString(schemaNames...)) {
+		allowed, err := c.schemaRolloutAllowed(apiBinding, apiExport)
+		if err != nil {
+			conditions.MarkFalse(
+				apiBinding,
+				apisv1alpha1.BindingUpToDate,
+				apisv1alpha1.InternalErrorReason,
+				conditionsv1alpha1.ConditionSeverityError,
+				"An internal error prevented the APIBinding process from completing. Please contact your system administrator for assistance",
+			)
+			return err
+		}
+		if !allowed {
+			logger.V(4).Info("schema rollout pending", "schemaGeneration", apisv1alpha1.SchemaGeneration(apiExport))
+			schemaNames = bound
+			rolloutPending = true
+		}
+	}
+
 	var needToWaitForRequeueWhenEstablished []string
 
 	// Process all APIResourceSchemas
-	for _, schemaName := range apiExport.Spec.LatestResourceSchemas {
+	for _, schemaName := range schemaNames {
 		bindingClusterName := logicalcluster.From(apiBinding)
 
 		// Get the schema
@@ -210,6 +237,22 @@ func (c *controller) reconcileBinding(ctx context.Context, apiBinding *apisv1alp
 			return nil
 		}
 
+		// Objects persisted in old versions must stay readable until storage version migration has removed
+		// those versions from the bound resource.
+		if missing := missingStorageVersions(apiBinding, schema); len(missing) > 0 {
+			conditions.MarkFalse(
+				apiBinding,
+				apisv1alpha1.BindingUpToDate,
+				apisv1alpha1.StorageVersionsMissingReason,
+				conditionsv1alpha1.ConditionSeverityError,
+				"APIResourceSchema %s|%s does not define the stored versions %s of %s.%s",
+				apiExportClusterName, schemaName,
+				strings.Join(missing, ", "),
+				schema.Spec.Names.Plural, schema.Spec.Group,
+			)
+			return nil
+		}
+
 		// Try to get the bound CRD
 		existingCRD, err := c.getCRD(ShadowWorkspaceName, boundCRDName(schema))
 		if err != nil && !apierrors.IsNotFound(err) {
@@ -328,20 +371,14 @@ func (c *controller) reconcileBinding(ctx context.Context, apiBinding *apisv1alp
 		}
 
 		// Merge any current storage versions with new ones
-		storageVersions := sets.NewString()
-		if existingCRD != nil {
-			storageVersions.Insert(existingCRD.Status.StoredVersions...)
-		}
-
-		for _, b := range apiBinding.Status.BoundResources {
+		var existingBoundResource *apisv1alpha1.BoundAPIResource
+		for i, b := range apiBinding.Status.BoundResources {
 			if b.Group == schema.Spec.Group && b.Resource == schema.Spec.Names.Plural {
-				storageVersions.Insert(b.StorageVersions...)
+				existingBoundResource = &apiBinding.Status.BoundResources[i]
 				break
 			}
 		}
-
-		sortedStorageVersions := storageVersions.List()
-		sort.Strings(sortedStorageVersions)
+		sortedStorageVersions := MergeStorageVersions(existingBoundResource, string(schema.UID), existingCRD)
 
 		// Upsert the BoundAPIResource for this APIResourceSchema
 		newBoundResource := apisv1alpha1.BoundAPIResource{
@@ -393,13 +430,103 @@ func (c *controller) reconcileBinding(ctx context.Context, apiBinding *apisv1alp
 		}
 	} else {
 		conditions.MarkTrue(apiBinding, apisv1alpha1.InitialBindingCompleted)
-		conditions.MarkTrue(apiBinding, apisv1alpha1.BindingUpToDate)
+		if rolloutPending {
+			conditions.MarkFalse(
+				apiBinding,
+				apisv1alpha1.BindingUpToDate,
+				apisv1alpha1.SchemaRolloutPendingReason,
+				conditionsv1alpha1.ConditionSeverityInfo,
+				"Schema generation %d of APIExport %s|%s is not rolled out to this APIBinding yet by the %s schema rollout policy",
+				apisv1alpha1.SchemaGeneration(apiExport),
+				apiExportClusterName,
+				apiExport.Name,
+				apiExport.Spec.SchemaRolloutPolicy.Strategy,
+			)
+		} else {
+			conditions.MarkTrue(apiBinding, apisv1alpha1.BindingUpToDate)
+			apiBinding.Status.SchemaGeneration = apisv1alpha1.SchemaGeneration(apiExport)
+		}
 		apiBinding.Status.Phase = apisv1alpha1.APIBindingPhaseBound
 	}
 
 	return nil
 }
 
+// schemaRolloutAllowed returns whether the APIBinding may move to the latest resource schemas of the APIExport
+// according to the schema rollout policy of the APIExport. APIBindings that are not bound yet always get the
+// latest schemas.
+func (c *controller) schemaRolloutAllowed(apiBinding *apisv1alpha1.APIBinding, apiExport *apisv1alpha1.APIExport) (bool, error) {
+	policy := apiExport.Spec.SchemaRolloutPolicy
+	if policy == nil || apiBinding.Status.Phase != apisv1alpha1.APIBindingPhaseBound {
+		return true, nil
+	}
+
+	switch policy.Strategy {
+	case apisv1alpha1.SchemaRolloutManual:
+		approved, err := strconv.ParseInt(apiBinding.Annotations[apisv1alpha1.AnnotationApprovedSchemaGenerationKey], 10, 64)
+		if err != nil {
+			// missing or malformed approvals approve nothing
+			return false, nil
+		}
+		return approved >= apisv1alpha1.SchemaGeneration(apiExport), nil
+	case apisv1alpha1.SchemaRolloutStaged:
+		if policy.WorkspaceSelector == nil {
+			return false, nil
+		}
+		selector, err := metav1.LabelSelectorAsSelector(policy.WorkspaceSelector)
+		if err != nil {
+			// an invalid selector selects no workspace
+			return false, nil
+		}
+		clusterWorkspace, err := c.getClusterWorkspace(logicalcluster.From(apiBinding))
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return selector.Matches(labels.Set(clusterWorkspace.Labels)), nil
+	default:
+		return true, nil
+	}
+}
+
+// MergeStorageVersions returns the sorted storage versions objects of a bound resource might be persisted in,
+// given the bound resource as recorded on the APIBinding, nil if not bound yet, and the bound CRD of the
+// schema with the given UID, nil if it does not exist.
+//
+// On a new binding, or when moving to another schema, all versions ever stored by the bound CRD are added.
+// As long as the schema stays the same, objects of the APIBinding are only written in the storage version of
+// the bound CRD, so only that version is added. Other versions stored by the shared bound CRD, e.g. for other
+// APIBindings, are not merged back in after the storage version migration has trimmed them.
+func MergeStorageVersions(boundResource *apisv1alpha1.BoundAPIResource, schemaUID string, crd *apiextensionsv1.CustomResourceDefinition) []string {
+	storageVersions := sets.NewString()
+	if boundResource != nil {
+		storageVersions.Insert(boundResource.StorageVersions...)
+	}
+	if crd != nil {
+		storageVersion, err := apihelpers.GetCRDStorageVersion(crd)
+		if boundResource != nil && boundResource.Schema.UID == schemaUID && err == nil {
+			storageVersions.Insert(storageVersion)
+		} else {
+			storageVersions.Insert(crd.Status.StoredVersions...)
+		}
+	}
+
+	sortedStorageVersions := storageVersions.List()
+	sort.Strings(sortedStorageVersions)
+	return sortedStorageVersions
+}
+
+// boundSchemaNames returns the names of the APIResourceSchemas currently bound by the APIBinding.
+func boundSchemaNames(apiBinding *apisv1alpha1.APIBinding) []string {
+	names := make([]string, 0, len(apiBinding.Status.BoundResources))
+	for _, r := range apiBinding.Status.BoundResources {
+		names = append(names, r.Schema.Name)
+	}
+	return names
+}
+
 func boundCRDName(schema *apisv1alpha1.APIResourceSchema) string {
 	return string(schema.UID)
 }
@@ -451,14 +578,69 @@ func generateCRD(schema *apisv1alpha1.APIResourceSchema) (*apiextensionsv1.Custo
 		crd.Spec.Versions = append(crd.Spec.Versions, crdVersion)
 	}
 
+	if conversion := schema.Spec.Conversion; conversion != nil {
+		crd.Spec.Conversion = &apiextensionsv1.CustomResourceConversion{
+			Strategy: apiextensionsv1.ConversionStrategyType(conversion.Strategy),
+		}
+		if conversion.Webhook != nil {
+			url := conversion.Webhook.ClientConfig.URL
+			crd.Spec.Conversion.Webhook = &apiextensionsv1.WebhookConversion{
+				ClientConfig: &apiextensionsv1.WebhookClientConfig{
+					URL:      &url,
+					CABundle: conversion.Webhook.ClientConfig.CABundle,
+				},
+				ConversionReviewVersions: conversion.Webhook.ConversionReviewVersions,
+			}
+		}
+	}
+
 	return crd, nil
 }
 
-func getAPIExportClusterName(apiBinding *apisv1alpha1.APIBinding) (logicalcluster.Name, error) {
-	if apiBinding.Spec.Reference.Workspace == nil {
-		// cannot happen due to APIBinding validation
-		return logicalcluster.Name{}, fmt.Errorf("APIBinding does not specify an APIExport")
+// missingStorageVersions returns the versions that objects of the resource of the schema might still be persisted in
+// according to the APIBinding, but that the schema does not define. Only a change of the bound schema is checked.
+func missingStorageVersions(apiBinding *apisv1alpha1.APIBinding, schema *apisv1alpha1.APIResourceSchema) []string {
+	for _, r := range apiBinding.Status.BoundResources {
+		if r.Group != schema.Spec.Group || r.Resource != schema.Spec.Names.Plural {
+			continue
+		}
+		if r.Schema.Name == schema.Name {
+			return nil
+		}
+
+		versions := sets.NewString()
+		for _, v := range schema.Spec.Versions {
+			versions.Insert(v.Name)
+		}
+		return sets.NewString(r.StorageVersions...).Difference(versions).List()
+	}
+	return nil
+}
+
+// validateSelectedAPIExport checks that the given APIExport is in one of the workspaces of the selector
+// reference, and that it matches its label selector.
+func validateSelectedAPIExport(selector *apisv1alpha1.SelectorExportReference, apiExportClusterName logicalcluster.Name, apiExport *apisv1alpha1.APIExport) error {
+	if !sets.NewString(selector.Paths...).Has(apiExportClusterName.String()) {
+		return fmt.Errorf("workspace is not one of the selector paths %v", selector.Paths)
+	}
+	labelSelector, err := metav1.LabelSelectorAsSelector(&selector.LabelSelector)
+	if err != nil {
+		return fmt.Errorf("invalid label selector: %w", err)
+	}
+	if !labelSelector.Matches(labels.Set(apiExport.Labels)) {
+		return fmt.Errorf("labels do not match the selector %q", labelSelector.String())
+	}
+	return nil
+}
+
+func getAPIExportClusterAndName(apiBinding *apisv1alpha1.APIBinding) (logicalcluster.Name, string, error) {
+	clusterName, exportName, ok := apisv1alpha1.ReferencedAPIExport(apiBinding)
+	if !ok {
+		if apiBinding.Spec.Reference.Selector != nil {
+			return logicalcluster.Name{}, "", fmt.Errorf("APIBinding has no valid %s annotation for its APIExport selector", apisv1alpha1.AnnotationSelectedExportKey)
+		}
+		return logicalcluster.Name{}, "", fmt.Errorf("APIBinding does not specify an APIExport")
 	}
 
-	return logicalcluster.New(apiBinding.Spec.Reference.Workspace.Path), nil
+	return clusterName, exportName, nil
 }
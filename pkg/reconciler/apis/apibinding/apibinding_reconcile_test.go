@@ -35,6 +35,7 @@ import (
 	"k8s.io/utils/pointer"
 
 	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
 	conditionsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
 	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
 )
@@ -204,11 +205,23 @@ func TestReconcileBinding(t *testing.T) {
 		wantNamingConflict                      bool
 		crdEstablished                          bool
 		crdStorageVersions                      []string
+		crdStorageVersion                       string
 	}{
 		"Update to nil workspace ref reports invalid APIExport": {
 			apiBinding:           binding.DeepCopy().WithoutWorkspaceReference().Build(),
 			wantInvalidReference: true,
 		},
+		"selector ref without selected APIExport reports invalid APIExport": {
+			apiBinding: binding.DeepCopy().WithoutWorkspaceReference().
+				WithSelectorReference(map[string]string{"app": "foo"}, "org:some-workspace").Build(),
+			wantInvalidReference: true,
+		},
+		"selector ref with selected APIExport not matching the selector reports invalid APIExport": {
+			apiBinding: binding.DeepCopy().WithoutWorkspaceReference().
+				WithSelectorReference(map[string]string{"app": "foo"}, "org:some-workspace").
+				WithAnnotation(apisv1alpha1.AnnotationSelectedExportKey, "org:some-workspace:some-export").Build(),
+			wantInvalidReference: true,
+		},
 		"APIExport not found": {
 			apiBinding:            binding.Build(),
 			getAPIExportError:     apierrors.NewNotFound(apisv1alpha1.SchemeGroupVersion.WithResource("apiexports").GroupResource(), "some-export"),
@@ -355,6 +368,37 @@ func TestReconcileBinding(t *testing.T) {
 			wantPhaseBound:             true,
 			wantInitialBindingComplete: true,
 		},
+		"Ensure migrated storage versions are not merged back from the CRD": {
+			apiBinding: binding.DeepCopy().
+				WithBoundResources(
+					new(boundAPIResourceBuilder).
+						WithGroupResource("kcp.dev", "widgets").
+						WithSchema("today.widgets.kcp.dev", "todaywidgetsuid").
+						WithStorageVersions("v1").
+						BoundAPIResource,
+				).Build(),
+			crdExists:          true,
+			crdEstablished:     true,
+			crdStorageVersions: []string{"v0", "v1"},
+			crdStorageVersion:  "v1",
+			wantAPIExportValid: true,
+			wantReady:          true,
+			wantBoundAPIExport: true,
+			wantBoundResources: []apisv1alpha1.BoundAPIResource{
+				{
+					Group:    "kcp.dev",
+					Resource: "widgets",
+					Schema: apisv1alpha1.BoundAPIResourceSchema{
+						Name:         "today.widgets.kcp.dev",
+						UID:          "todaywidgetsuid",
+						IdentityHash: "hash1",
+					},
+					StorageVersions: []string{"v1"},
+				},
+			},
+			wantPhaseBound:             true,
+			wantInitialBindingComplete: true,
+		},
 	}
 
 	for testName, tc := range tests {
@@ -467,6 +511,9 @@ func TestReconcileBinding(t *testing.T) {
 							StoredVersions: tc.crdStorageVersions,
 						},
 					}
+					if tc.crdStorageVersion != "" {
+						crd.Spec.Versions = []apiextensionsv1.CustomResourceDefinitionVersion{{Name: tc.crdStorageVersion, Storage: true}}
+					}
 
 					if name == "anotherwidgetsuid" {
 						crd.Spec.Group = "kcp.dev"
@@ -617,6 +664,206 @@ func TestReconcileBinding(t *testing.T) {
 	}
 }
 
+func TestReconcileBindingSchemaRollout(t *testing.T) {
+	tomorrowWidgetsAPIResourceSchema := todayWidgetsAPIResourceSchema.DeepCopy()
+	tomorrowWidgetsAPIResourceSchema.Name = "tomorrow.widgets.kcp.dev"
+	tomorrowWidgetsAPIResourceSchema.UID = "tomorrowwidgetsuid"
+
+	boundToToday := binding.DeepCopy().
+		WithPhase(apisv1alpha1.APIBindingPhaseBound).
+		WithBoundResources(
+			new(boundAPIResourceBuilder).
+				WithGroupResource("kcp.dev", "widgets").
+				WithSchema("today.widgets.kcp.dev", "todaywidgetsuid").
+				WithStorageVersions("v1").
+				BoundAPIResource,
+		)
+
+	tests := map[string]struct {
+		apiBinding        *apisv1alpha1.APIBinding
+		policy            *apisv1alpha1.SchemaRolloutPolicy
+		workspaceLabels   map[string]string
+		workspaceNotFound bool
+
+		wantSchema     string
+		wantReason     string
+		wantGeneration int64
+	}{
+		"no policy rolls out immediately": {
+			apiBinding:     boundToToday.Build(),
+			wantSchema:     "tomorrow.widgets.kcp.dev",
+			wantGeneration: 2,
+		},
+		"immediate": {
+			apiBinding:     boundToToday.Build(),
+			policy:         &apisv1alpha1.SchemaRolloutPolicy{Strategy: apisv1alpha1.SchemaRolloutImmediate},
+			wantSchema:     "tomorrow.widgets.kcp.dev",
+			wantGeneration: 2,
+		},
+		"manual without approval": {
+			apiBinding:     boundToToday.Build(),
+			policy:         &apisv1alpha1.SchemaRolloutPolicy{Strategy: apisv1alpha1.SchemaRolloutManual},
+			wantSchema:     "today.widgets.kcp.dev",
+			wantReason:     apisv1alpha1.SchemaRolloutPendingReason,
+			wantGeneration: 1,
+		},
+		"manual with approval of an older generation": {
+			apiBinding:     boundToToday.DeepCopy().WithAnnotation(apisv1alpha1.AnnotationApprovedSchemaGenerationKey, "1").Build(),
+			policy:         &apisv1alpha1.SchemaRolloutPolicy{Strategy: apisv1alpha1.SchemaRolloutManual},
+			wantSchema:     "today.widgets.kcp.dev",
+			wantReason:     apisv1alpha1.SchemaRolloutPendingReason,
+			wantGeneration: 1,
+		},
+		"manual with approval": {
+			apiBinding:     boundToToday.DeepCopy().WithAnnotation(apisv1alpha1.AnnotationApprovedSchemaGenerationKey, "2").Build(),
+			policy:         &apisv1alpha1.SchemaRolloutPolicy{Strategy: apisv1alpha1.SchemaRolloutManual},
+			wantSchema:     "tomorrow.widgets.kcp.dev",
+			wantGeneration: 2,
+		},
+		"manual does not hold back the initial binding": {
+			apiBinding:     binding.Build(),
+			policy:         &apisv1alpha1.SchemaRolloutPolicy{Strategy: apisv1alpha1.SchemaRolloutManual},
+			wantSchema:     "tomorrow.widgets.kcp.dev",
+			wantGeneration: 2,
+		},
+		"stored versions missing in the new schema": {
+			apiBinding: binding.DeepCopy().
+				WithPhase(apisv1alpha1.APIBindingPhaseBound).
+				WithBoundResources(
+					new(boundAPIResourceBuilder).
+						WithGroupResource("kcp.dev", "widgets").
+						WithSchema("today.widgets.kcp.dev", "todaywidgetsuid").
+						WithStorageVersions("v0", "v1").
+						BoundAPIResource,
+				).Build(),
+			wantSchema:     "today.widgets.kcp.dev",
+			wantReason:     apisv1alpha1.StorageVersionsMissingReason,
+			wantGeneration: 1,
+		},
+		"staged with matching workspace": {
+			apiBinding: boundToToday.Build(),
+			policy: &apisv1alpha1.SchemaRolloutPolicy{
+				Strategy:          apisv1alpha1.SchemaRolloutStaged,
+				WorkspaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"canary": "true"}},
+			},
+			workspaceLabels: map[string]string{"canary": "true"},
+			wantSchema:      "tomorrow.widgets.kcp.dev",
+			wantGeneration:  2,
+		},
+		"staged with other workspace": {
+			apiBinding: boundToToday.Build(),
+			policy: &apisv1alpha1.SchemaRolloutPolicy{
+				Strategy:          apisv1alpha1.SchemaRolloutStaged,
+				WorkspaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"canary": "true"}},
+			},
+			workspaceLabels: map[string]string{"canary": "false"},
+			wantSchema:      "today.widgets.kcp.dev",
+			wantReason:      apisv1alpha1.SchemaRolloutPendingReason,
+			wantGeneration:  1,
+		},
+		"staged with unknown workspace": {
+			apiBinding: boundToToday.Build(),
+			policy: &apisv1alpha1.SchemaRolloutPolicy{
+				Strategy:          apisv1alpha1.SchemaRolloutStaged,
+				WorkspaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"canary": "true"}},
+			},
+			workspaceNotFound: true,
+			wantSchema:        "today.widgets.kcp.dev",
+			wantReason:        apisv1alpha1.SchemaRolloutPendingReason,
+			wantGeneration:    1,
+		},
+	}
+
+	for testName, tc := range tests {
+		tc := tc // to avoid t.Parallel() races
+
+		t.Run(testName, func(t *testing.T) {
+			if tc.apiBinding.Status.Phase == apisv1alpha1.APIBindingPhaseBound {
+				tc.apiBinding.Status.SchemaGeneration = 1
+				conditions.MarkTrue(tc.apiBinding, apisv1alpha1.InitialBindingCompleted)
+			}
+
+			apiExport := &apisv1alpha1.APIExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						logicalcluster.AnnotationKey:               "org:some-workspace",
+						apisv1alpha1.AnnotationSchemaGenerationKey: "2",
+					},
+					Name: "some-export",
+					// unrelated spec changes bump the generation, but not the schema generation
+					Generation: 7,
+				},
+				Spec: apisv1alpha1.APIExportSpec{
+					LatestResourceSchemas: []string{"tomorrow.widgets.kcp.dev"},
+					SchemaRolloutPolicy:   tc.policy,
+				},
+				Status: apisv1alpha1.APIExportStatus{IdentityHash: "hash1"},
+			}
+			apiResourceSchemas := map[string]*apisv1alpha1.APIResourceSchema{
+				"today.widgets.kcp.dev":    todayWidgetsAPIResourceSchema,
+				"tomorrow.widgets.kcp.dev": tomorrowWidgetsAPIResourceSchema,
+			}
+
+			c := &controller{
+				listAPIBindings: func(clusterName logicalcluster.Name) ([]*apisv1alpha1.APIBinding, error) {
+					return nil, nil
+				},
+				getAPIExport: func(clusterName logicalcluster.Name, name string) (*apisv1alpha1.APIExport, error) {
+					return apiExport, nil
+				},
+				getAPIResourceSchema: func(clusterName logicalcluster.Name, name string) (*apisv1alpha1.APIResourceSchema, error) {
+					return apiResourceSchemas[name], nil
+				},
+				getCRD: func(clusterName logicalcluster.Name, name string) (*apiextensionsv1.CustomResourceDefinition, error) {
+					return &apiextensionsv1.CustomResourceDefinition{
+						Status: apiextensionsv1.CustomResourceDefinitionStatus{
+							Conditions: []apiextensionsv1.CustomResourceDefinitionCondition{
+								{Type: apiextensionsv1.Established, Status: apiextensionsv1.ConditionTrue},
+							},
+						},
+					}, nil
+				},
+				listCRDs: func(clusterName logicalcluster.Name) ([]*apiextensionsv1.CustomResourceDefinition, error) {
+					return nil, nil
+				},
+				getClusterWorkspace: func(clusterName logicalcluster.Name) (*tenancyv1alpha1.ClusterWorkspace, error) {
+					require.Equal(t, "org:ws", clusterName.String())
+					if tc.workspaceNotFound {
+						return nil, apierrors.NewNotFound(tenancyv1alpha1.Resource("clusterworkspaces"), "ws")
+					}
+					return &tenancyv1alpha1.ClusterWorkspace{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{logicalcluster.AnnotationKey: "org"},
+							Name:        "ws",
+							Labels:      tc.workspaceLabels,
+						},
+					}, nil
+				},
+				deletedCRDTracker: &lockedStringSet{},
+			}
+
+			err := c.reconcile(context.Background(), tc.apiBinding)
+			require.NoError(t, err)
+
+			require.Equal(t, apisv1alpha1.APIBindingPhaseBound, tc.apiBinding.Status.Phase)
+			require.Len(t, tc.apiBinding.Status.BoundResources, 1)
+			require.Equal(t, tc.wantSchema, tc.apiBinding.Status.BoundResources[0].Schema.Name)
+			require.Equal(t, tc.wantGeneration, tc.apiBinding.Status.SchemaGeneration)
+
+			if tc.wantReason != "" {
+				requireConditionMatches(t, tc.apiBinding, &conditionsv1alpha1.Condition{
+					Type:   apisv1alpha1.BindingUpToDate,
+					Status: corev1.ConditionFalse,
+					Reason: tc.wantReason,
+				})
+			} else {
+				requireConditionMatches(t, tc.apiBinding, conditions.TrueCondition(apisv1alpha1.BindingUpToDate))
+			}
+			requireConditionMatches(t, tc.apiBinding, conditions.TrueCondition(conditionsv1alpha1.ReadyCondition))
+		})
+	}
+}
+
 func TestCRDFromAPIResourceSchema(t *testing.T) {
 	tests := map[string]struct {
 		schema  *apisv1alpha1.APIResourceSchema
@@ -802,6 +1049,102 @@ func TestCRDFromAPIResourceSchema(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		"conversion webhook": {
+			schema: &apisv1alpha1.APIResourceSchema{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						logicalcluster.AnnotationKey: "my-cluster",
+					},
+					Name: "my-name",
+					UID:  types.UID("my-uuid"),
+				},
+				Spec: apisv1alpha1.APIResourceSchemaSpec{
+					Group: "my-group",
+					Names: apiextensionsv1.CustomResourceDefinitionNames{
+						Plural:   "widgets",
+						Singular: "widget",
+						Kind:     "Widget",
+						ListKind: "WidgetList",
+					},
+					Scope: apiextensionsv1.ClusterScoped,
+					Versions: []apisv1alpha1.APIResourceVersion{
+						{
+							Name:    "v1alpha1",
+							Served:  true,
+							Storage: false,
+							Schema: runtime.RawExtension{
+								Raw: []byte(`{"type":"object"}`),
+							},
+						},
+						{
+							Name:    "v1beta1",
+							Served:  true,
+							Storage: true,
+							Schema: runtime.RawExtension{
+								Raw: []byte(`{"type":"object"}`),
+							},
+						},
+					},
+					Conversion: &apisv1alpha1.CustomResourceConversion{
+						Strategy: apisv1alpha1.WebhookConverter,
+						Webhook: &apisv1alpha1.WebhookConversion{
+							ClientConfig: apisv1alpha1.WebhookClientConfig{
+								URL:      "https://convert.example.com/widgets",
+								CABundle: []byte("ca"),
+							},
+							ConversionReviewVersions: []string{"v1"},
+						},
+					},
+				},
+			},
+			want: &apiextensionsv1.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "my-uuid",
+					Annotations: map[string]string{
+						logicalcluster.AnnotationKey:            ShadowWorkspaceName.String(),
+						apisv1alpha1.AnnotationBoundCRDKey:      "",
+						apisv1alpha1.AnnotationSchemaClusterKey: "my-cluster",
+						apisv1alpha1.AnnotationSchemaNameKey:    "my-name",
+					},
+				},
+				Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+					Group: "my-group",
+					Names: apiextensionsv1.CustomResourceDefinitionNames{
+						Plural:   "widgets",
+						Singular: "widget",
+						Kind:     "Widget",
+						ListKind: "WidgetList",
+					},
+					Scope: apiextensionsv1.ClusterScoped,
+					Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+						{
+							Name:         "v1alpha1",
+							Served:       true,
+							Storage:      false,
+							Schema:       &apiextensionsv1.CustomResourceValidation{OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{Type: "object"}},
+							Subresources: &apiextensionsv1.CustomResourceSubresources{},
+						},
+						{
+							Name:         "v1beta1",
+							Served:       true,
+							Storage:      true,
+							Schema:       &apiextensionsv1.CustomResourceValidation{OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{Type: "object"}},
+							Subresources: &apiextensionsv1.CustomResourceSubresources{},
+						},
+					},
+					Conversion: &apiextensionsv1.CustomResourceConversion{
+						Strategy: apiextensionsv1.WebhookConverter,
+						Webhook: &apiextensionsv1.WebhookConversion{
+							ClientConfig: &apiextensionsv1.WebhookClientConfig{
+								URL:      pointer.String("https://convert.example.com/widgets"),
+								CABundle: []byte("ca"),
+							},
+							ConversionReviewVersions: []string{"v1"},
+						},
+					},
+				},
+			},
+		},
 		"error when schema is invalid": {
 			schema: &apisv1alpha1.APIResourceSchema{
 				Spec: apisv1alpha1.APIResourceSchemaSpec{
@@ -880,6 +1223,22 @@ func (b *bindingBuilder) WithWorkspaceReference(path, exportName string) *bindin
 	return b
 }
 
+func (b *bindingBuilder) WithSelectorReference(matchLabels map[string]string, paths ...string) *bindingBuilder {
+	b.Spec.Reference.Selector = &apisv1alpha1.SelectorExportReference{
+		Paths:         paths,
+		LabelSelector: metav1.LabelSelector{MatchLabels: matchLabels},
+	}
+	return b
+}
+
+func (b *bindingBuilder) WithAnnotation(key, value string) *bindingBuilder {
+	if b.Annotations == nil {
+		b.Annotations = map[string]string{}
+	}
+	b.Annotations[key] = value
+	return b
+}
+
 func (b *bindingBuilder) WithPhase(phase apisv1alpha1.APIBindingPhaseType) *bindingBuilder {
 	b.Status.Phase = phase
 	return b
@@ -61,12 +61,12 @@ func (ncc *conflictChecker) getBoundCRDs(apiBindingToExclude *apisv1alpha1.APIBi
 			continue
 		}
 
-		apiExportClusterName, err := getAPIExportClusterName(apiBinding)
+		apiExportClusterName, apiExportName, err := getAPIExportClusterAndName(apiBinding)
 		if err != nil {
 			return err
 		}
 
-		apiExport, err := ncc.getAPIExport(apiExportClusterName, apiBinding.Spec.Reference.Workspace.ExportName)
+		apiExport, err := ncc.getAPIExport(apiExportClusterName, apiExportName)
 		if err != nil {
 			return err
 		}
@@ -273,11 +273,12 @@ func (c *controller) enqueueFromAPIBinding(obj interface{}) {
 
 	logger := logging.WithObject(logging.WithReconciler(klog.Background(), ControllerName), binding)
 
-	if binding.Spec.Reference.Workspace == nil {
+	apiExportClusterName, apiExportName, ok := apisv1alpha1.ReferencedAPIExport(binding)
+	if !ok {
 		return
 	}
 
-	key := kcpcache.ToClusterAwareKey(binding.Spec.Reference.Workspace.Path, "", binding.Spec.Reference.Workspace.ExportName)
+	key := kcpcache.ToClusterAwareKey(apiExportClusterName.String(), "", apiExportName)
 	logging.WithQueueKey(logger, key).V(2).Info("queueing APIExport via APIBinding")
 	c.queue.Add(key)
 }
@@ -0,0 +1,248 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiexportconsumers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	kcpcache "github.com/kcp-dev/apimachinery/pkg/cache"
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/cluster"
+	apisv1alpha1client "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/typed/apis/v1alpha1"
+	apisv1alpha1informers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/apis/v1alpha1"
+	apisv1alpha1listers "github.com/kcp-dev/kcp/pkg/client/listers/apis/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/indexers"
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/reconciler/committer"
+)
+
+const (
+	ControllerName = "kcp-apiexport-consumers"
+)
+
+// NewController returns a new controller that summarizes the consumers of the APIExports of this shard in
+// their status. The APIBindings are read from the cache server, i.e. they include the bindings of all shards.
+func NewController(
+	kcpClusterClient kcpclientset.ClusterInterface,
+	apiExportInformer apisv1alpha1informers.APIExportClusterInformer,
+	globalAPIBindingInformer apisv1alpha1informers.APIBindingClusterInformer,
+) (*controller, error) {
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), ControllerName)
+
+	c := &controller{
+		queue:           queue,
+		apiExportLister: apiExportInformer.Lister(),
+		listAPIBindings: func(clusterName logicalcluster.Name, apiExportName string) ([]*apisv1alpha1.APIBinding, error) {
+			objs, err := globalAPIBindingInformer.Informer().GetIndexer().ByIndex(indexers.APIBindingsByAPIExport, indexers.ClusterPathAndAPIExportName(clusterName.String(), apiExportName))
+			if err != nil {
+				return nil, err
+			}
+			apiBindings := make([]*apisv1alpha1.APIBinding, 0, len(objs))
+			for _, obj := range objs {
+				apiBindings = append(apiBindings, obj.(*apisv1alpha1.APIBinding))
+			}
+			return apiBindings, nil
+		},
+		commit: committer.NewCommitter[*APIExport, Patcher, *APIExportSpec, *APIExportStatus](kcpClusterClient.ApisV1alpha1().APIExports()),
+	}
+
+	indexers.AddIfNotPresentOrDie(
+		globalAPIBindingInformer.Informer().GetIndexer(),
+		cache.Indexers{
+			indexers.APIBindingsByAPIExport: indexers.IndexAPIBindingByAPIExport,
+		},
+	)
+
+	apiExportInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueueAPIExport(obj)
+		},
+		UpdateFunc: func(_, newObj interface{}) {
+			c.enqueueAPIExport(newObj)
+		},
+	})
+
+	globalAPIBindingInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueueFromAPIBinding(nil, obj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			c.enqueueFromAPIBinding(oldObj, newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.enqueueFromAPIBinding(nil, obj)
+		},
+	})
+
+	return c, nil
+}
+
+type APIExport = apisv1alpha1.APIExport
+type APIExportSpec = apisv1alpha1.APIExportSpec
+type APIExportStatus = apisv1alpha1.APIExportStatus
+type Patcher = apisv1alpha1client.APIExportInterface
+type Resource = committer.Resource[*APIExportSpec, *APIExportStatus]
+type CommitFunc = func(context.Context, *Resource, *Resource) error
+
+// controller reconciles the status.consumers of APIExports.
+type controller struct {
+	queue workqueue.RateLimitingInterface
+
+	apiExportLister apisv1alpha1listers.APIExportClusterLister
+	listAPIBindings func(clusterName logicalcluster.Name, apiExportName string) ([]*apisv1alpha1.APIBinding, error)
+
+	commit CommitFunc
+}
+
+// enqueueAPIExport enqueues an APIExport.
+func (c *controller) enqueueAPIExport(obj interface{}) {
+	key, err := kcpcache.DeletionHandlingMetaClusterNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+
+	logger := logging.WithQueueKey(logging.WithReconciler(klog.Background(), ControllerName), key)
+	logger.V(4).Info("queueing APIExport")
+	c.queue.Add(key)
+}
+
+// enqueueFromAPIBinding enqueues the APIExport referenced by an APIBinding. On updates, the previously referenced
+// APIExport is enqueued as well in case the reference changed. APIExports that are not on this shard are skipped
+// when processed.
+func (c *controller) enqueueFromAPIBinding(oldObj, newObj interface{}) {
+	logger := logging.WithReconciler(klog.Background(), ControllerName)
+	for _, obj := range []interface{}{oldObj, newObj} {
+		if obj == nil {
+			continue
+		}
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		apiBinding, ok := obj.(*apisv1alpha1.APIBinding)
+		if !ok {
+			runtime.HandleError(fmt.Errorf("obj is supposed to be an APIBinding, but is %T", obj))
+			continue
+		}
+
+		clusterName, apiExportName, ok := apisv1alpha1.ReferencedAPIExport(apiBinding)
+		if !ok {
+			continue
+		}
+		key := kcpcache.ToClusterAwareKey(clusterName.String(), "", apiExportName)
+		logging.WithQueueKey(logging.WithObject(logger, apiBinding), key).V(4).Info("queueing APIExport because APIBinding changed")
+		c.queue.Add(key)
+	}
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *controller) Start(ctx context.Context, numThreads int) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	logger := logging.WithReconciler(klog.FromContext(ctx), ControllerName)
+	ctx = klog.NewContext(ctx, logger)
+	logger.Info("Starting controller")
+	defer logger.Info("Shutting down controller")
+
+	for i := 0; i < numThreads; i++ {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	k, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	key := k.(string)
+
+	logger := logging.WithQueueKey(klog.FromContext(ctx), key)
+	ctx = klog.NewContext(ctx, logger)
+	logger.V(4).Info("processing key")
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.process(ctx, key); err != nil {
+		runtime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", ControllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *controller) process(ctx context.Context, key string) error {
+	cluster, _, name, err := kcpcache.SplitMetaClusterNamespaceKey(key)
+	if err != nil {
+		runtime.HandleError(err)
+		return nil
+	}
+
+	obj, err := c.apiExportLister.Cluster(cluster).Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil // object deleted before we handled it, or living on another shard
+		}
+		return err
+	}
+
+	old := obj
+	obj = obj.DeepCopy()
+
+	logger := logging.WithObject(klog.FromContext(ctx), obj)
+	ctx = klog.NewContext(ctx, logger)
+
+	var errs []error
+	if err := c.reconcile(ctx, obj); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Regardless of whether reconcile returned an error or not, always try to patch status if needed. Return the
+	// reconciliation error at the end.
+
+	// If the object being reconciled changed as a result, update it.
+	oldResource := &Resource{ObjectMeta: old.ObjectMeta, Spec: &old.Spec, Status: &old.Status}
+	newResource := &Resource{ObjectMeta: obj.ObjectMeta, Spec: &obj.Spec, Status: &obj.Status}
+	if err := c.commit(ctx, oldResource, newResource); err != nil {
+		errs = append(errs, err)
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
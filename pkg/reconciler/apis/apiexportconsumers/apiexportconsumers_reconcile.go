@@ -0,0 +1,91 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiexportconsumers
+
+import (
+	"context"
+	"sort"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+)
+
+func (c *controller) reconcile(ctx context.Context, apiExport *apisv1alpha1.APIExport) error {
+	apiBindings, err := c.listAPIBindings(logicalcluster.From(apiExport), apiExport.Name)
+	if err != nil {
+		return err
+	}
+
+	apiExport.Status.Consumers = consumersOf(apiExport, apiBindings)
+	return nil
+}
+
+// consumersOf summarizes the given APIBindings referencing the APIExport.
+func consumersOf(apiExport *apisv1alpha1.APIExport, apiBindings []*apisv1alpha1.APIBinding) *apisv1alpha1.APIExportConsumers {
+	consumers := &apisv1alpha1.APIExportConsumers{
+		Count: int32(len(apiBindings)),
+	}
+
+	boundSchemas := map[string]*apisv1alpha1.BoundSchemaConsumers{}
+	for _, apiBinding := range apiBindings {
+		for _, r := range apiBinding.Status.BoundResources {
+			if r.Schema.IdentityHash != apiExport.Status.IdentityHash {
+				continue
+			}
+			s, found := boundSchemas[r.Schema.Name]
+			if !found {
+				s = &apisv1alpha1.BoundSchemaConsumers{Name: r.Schema.Name, Group: r.Group, Resource: r.Resource}
+				boundSchemas[r.Schema.Name] = s
+			}
+			s.Count++
+		}
+	}
+	for _, s := range boundSchemas {
+		consumers.BoundSchemas = append(consumers.BoundSchemas, *s)
+	}
+	sort.Slice(consumers.BoundSchemas, func(i, j int) bool {
+		return consumers.BoundSchemas[i].Name < consumers.BoundSchemas[j].Name
+	})
+
+	for _, claim := range apiExport.Spec.PermissionClaims {
+		claimConsumers := apisv1alpha1.PermissionClaimConsumers{
+			GroupResource: claim.GroupResource,
+			IdentityHash:  claim.IdentityHash,
+		}
+		for _, apiBinding := range apiBindings {
+			var state apisv1alpha1.AcceptablePermissionClaimState
+			for _, acceptable := range apiBinding.Spec.PermissionClaims {
+				if acceptable.Equal(claim) {
+					state = acceptable.State
+					break
+				}
+			}
+			switch state {
+			case apisv1alpha1.ClaimAccepted:
+				claimConsumers.Accepted++
+			case apisv1alpha1.ClaimRejected:
+				claimConsumers.Rejected++
+			default:
+				claimConsumers.Pending++
+			}
+		}
+		consumers.PermissionClaims = append(consumers.PermissionClaims, claimConsumers)
+	}
+
+	return consumers
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiexportconsumers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+)
+
+func TestReconcile(t *testing.T) {
+	configMapsClaim := apisv1alpha1.PermissionClaim{GroupResource: apisv1alpha1.GroupResource{Resource: "configmaps"}, All: true}
+	secretsClaim := apisv1alpha1.PermissionClaim{GroupResource: apisv1alpha1.GroupResource{Resource: "secrets"}, All: true}
+
+	newAPIBinding := func(name string, schemas []string, claims ...apisv1alpha1.AcceptablePermissionClaim) *apisv1alpha1.APIBinding {
+		b := &apisv1alpha1.APIBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{logicalcluster.AnnotationKey: "root:consumer"},
+				Name:        name,
+			},
+			Spec: apisv1alpha1.APIBindingSpec{
+				Reference: apisv1alpha1.ExportReference{
+					Workspace: &apisv1alpha1.WorkspaceExportReference{Path: "root:provider", ExportName: "export"},
+				},
+				PermissionClaims: claims,
+			},
+		}
+		for _, s := range schemas {
+			b.Status.BoundResources = append(b.Status.BoundResources, apisv1alpha1.BoundAPIResource{
+				Group:    "kcp.dev",
+				Resource: "widgets",
+				Schema:   apisv1alpha1.BoundAPIResourceSchema{Name: s, IdentityHash: "hash"},
+			})
+		}
+		return b
+	}
+
+	tests := map[string]struct {
+		apiBindings      []*apisv1alpha1.APIBinding
+		listBindingsErr  error
+		permissionClaims []apisv1alpha1.PermissionClaim
+
+		wantConsumers *apisv1alpha1.APIExportConsumers
+		wantError     bool
+	}{
+		"no consumers": {
+			wantConsumers: &apisv1alpha1.APIExportConsumers{},
+		},
+		"consumers bound to different schemas": {
+			apiBindings: []*apisv1alpha1.APIBinding{
+				newAPIBinding("a", []string{"today.widgets.kcp.dev"}),
+				newAPIBinding("b", []string{"today.widgets.kcp.dev"}),
+				newAPIBinding("c", []string{"yesterday.widgets.kcp.dev"}),
+				newAPIBinding("d", nil),
+			},
+			wantConsumers: &apisv1alpha1.APIExportConsumers{
+				Count: 4,
+				BoundSchemas: []apisv1alpha1.BoundSchemaConsumers{
+					{Name: "today.widgets.kcp.dev", Group: "kcp.dev", Resource: "widgets", Count: 2},
+					{Name: "yesterday.widgets.kcp.dev", Group: "kcp.dev", Resource: "widgets", Count: 1},
+				},
+			},
+		},
+		"schemas of other identities are ignored": {
+			apiBindings: []*apisv1alpha1.APIBinding{
+				func() *apisv1alpha1.APIBinding {
+					b := newAPIBinding("a", []string{"today.widgets.kcp.dev"})
+					b.Status.BoundResources[0].Schema.IdentityHash = "other"
+					return b
+				}(),
+			},
+			wantConsumers: &apisv1alpha1.APIExportConsumers{Count: 1},
+		},
+		"permission claim states": {
+			apiBindings: []*apisv1alpha1.APIBinding{
+				newAPIBinding("a", nil,
+					apisv1alpha1.AcceptablePermissionClaim{PermissionClaim: configMapsClaim, State: apisv1alpha1.ClaimAccepted},
+					apisv1alpha1.AcceptablePermissionClaim{PermissionClaim: secretsClaim, State: apisv1alpha1.ClaimRejected},
+				),
+				newAPIBinding("b", nil,
+					apisv1alpha1.AcceptablePermissionClaim{PermissionClaim: configMapsClaim, State: apisv1alpha1.ClaimAccepted},
+				),
+				newAPIBinding("c", nil),
+			},
+			permissionClaims: []apisv1alpha1.PermissionClaim{configMapsClaim, secretsClaim},
+			wantConsumers: &apisv1alpha1.APIExportConsumers{
+				Count: 3,
+				PermissionClaims: []apisv1alpha1.PermissionClaimConsumers{
+					{GroupResource: apisv1alpha1.GroupResource{Resource: "configmaps"}, Accepted: 2, Pending: 1},
+					{GroupResource: apisv1alpha1.GroupResource{Resource: "secrets"}, Rejected: 1, Pending: 2},
+				},
+			},
+		},
+		"error listing APIBindings": {
+			listBindingsErr: errors.New("foo"),
+			wantError:       true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &controller{
+				listAPIBindings: func(clusterName logicalcluster.Name, apiExportName string) ([]*apisv1alpha1.APIBinding, error) {
+					require.Equal(t, "root:provider", clusterName.String())
+					require.Equal(t, "export", apiExportName)
+					return tc.apiBindings, tc.listBindingsErr
+				},
+			}
+
+			apiExport := &apisv1alpha1.APIExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{logicalcluster.AnnotationKey: "root:provider"},
+					Name:        "export",
+				},
+				Spec: apisv1alpha1.APIExportSpec{
+					PermissionClaims: tc.permissionClaims,
+				},
+				Status: apisv1alpha1.APIExportStatus{IdentityHash: "hash"},
+			}
+
+			err := c.reconcile(context.Background(), apiExport)
+			if tc.wantError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantConsumers, apiExport.Status.Consumers)
+		})
+	}
+}
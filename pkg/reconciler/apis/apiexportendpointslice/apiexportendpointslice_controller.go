@@ -0,0 +1,358 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiexportendpointslice
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	kcpcache "github.com/kcp-dev/apimachinery/pkg/cache"
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	topologyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/topology/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/client"
+	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/cluster"
+	apisv1alpha1client "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/typed/apis/v1alpha1"
+	apisv1alpha1informers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/apis/v1alpha1"
+	tenancyv1alpha1informers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	topologyv1alpha1informers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/topology/v1alpha1"
+	apisv1alpha1listers "github.com/kcp-dev/kcp/pkg/client/listers/apis/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/indexers"
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/reconciler/committer"
+)
+
+const (
+	ControllerName = "kcp-apiexportendpointslice"
+)
+
+// NewController returns a new controller for APIExportEndpointSlices.
+// Shards and APIExports are read from the cache server in addition to the local shard.
+func NewController(
+	kcpClusterClient kcpclientset.ClusterInterface,
+	apiExportEndpointSliceInformer apisv1alpha1informers.APIExportEndpointSliceClusterInformer,
+	apiExportInformer apisv1alpha1informers.APIExportClusterInformer,
+	partitionInformer topologyv1alpha1informers.PartitionClusterInformer,
+	globalAPIExportInformer apisv1alpha1informers.APIExportClusterInformer,
+	globalClusterWorkspaceShardInformer tenancyv1alpha1informers.ClusterWorkspaceShardClusterInformer,
+) (*controller, error) {
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), ControllerName)
+
+	c := &controller{
+		queue:                         queue,
+		apiExportEndpointSliceLister:  apiExportEndpointSliceInformer.Lister(),
+		apiExportEndpointSliceIndexer: apiExportEndpointSliceInformer.Informer().GetIndexer(),
+		getAPIExport: func(clusterName logicalcluster.Name, name string) (*apisv1alpha1.APIExport, error) {
+			apiExport, err := apiExportInformer.Lister().Cluster(clusterName).Get(name)
+			if errors.IsNotFound(err) {
+				return globalAPIExportInformer.Lister().Cluster(clusterName).Get(name)
+			}
+			return apiExport, err
+		},
+		getPartition: func(clusterName logicalcluster.Name, name string) (*topologyv1alpha1.Partition, error) {
+			return partitionInformer.Lister().Cluster(clusterName).Get(name)
+		},
+		listClusterWorkspaceShards: func(selector labels.Selector) ([]*tenancyv1alpha1.ClusterWorkspaceShard, error) {
+			return globalClusterWorkspaceShardInformer.Lister().List(selector)
+		},
+		commit: committer.NewCommitter[*APIExportEndpointSlice, Patcher, *APIExportEndpointSliceSpec, *APIExportEndpointSliceStatus](kcpClusterClient.ApisV1alpha1().APIExportEndpointSlices()),
+	}
+
+	indexers.AddIfNotPresentOrDie(
+		apiExportEndpointSliceInformer.Informer().GetIndexer(),
+		cache.Indexers{
+			indexAPIExportEndpointSlicesByAPIExport: indexAPIExportEndpointSlicesByAPIExportFunc,
+			indexAPIExportEndpointSlicesByPartition: indexAPIExportEndpointSlicesByPartitionFunc,
+		},
+	)
+
+	apiExportEndpointSliceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueueAPIExportEndpointSlice(obj)
+		},
+		UpdateFunc: func(_, newObj interface{}) {
+			c.enqueueAPIExportEndpointSlice(newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.enqueueAPIExportEndpointSlice(obj)
+		},
+	})
+
+	for _, informer := range []apisv1alpha1informers.APIExportClusterInformer{apiExportInformer, globalAPIExportInformer} {
+		informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				c.enqueueFromAPIExport(obj)
+			},
+			UpdateFunc: func(_, newObj interface{}) {
+				c.enqueueFromAPIExport(newObj)
+			},
+			DeleteFunc: func(obj interface{}) {
+				c.enqueueFromAPIExport(obj)
+			},
+		})
+	}
+
+	partitionInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueueFromPartition(obj)
+		},
+		UpdateFunc: func(_, newObj interface{}) {
+			c.enqueueFromPartition(newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.enqueueFromPartition(obj)
+		},
+	})
+
+	globalClusterWorkspaceShardInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueueAllAPIExportEndpointSlices(obj)
+		},
+		UpdateFunc: func(_, newObj interface{}) {
+			c.enqueueAllAPIExportEndpointSlices(newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.enqueueAllAPIExportEndpointSlices(obj)
+		},
+	})
+
+	return c, nil
+}
+
+type APIExportEndpointSlice = apisv1alpha1.APIExportEndpointSlice
+type APIExportEndpointSliceSpec = apisv1alpha1.APIExportEndpointSliceSpec
+type APIExportEndpointSliceStatus = apisv1alpha1.APIExportEndpointSliceStatus
+type Patcher = apisv1alpha1client.APIExportEndpointSliceInterface
+type Resource = committer.Resource[*APIExportEndpointSliceSpec, *APIExportEndpointSliceStatus]
+type CommitFunc = func(context.Context, *Resource, *Resource) error
+
+// controller reconciles APIExportEndpointSlices. It fills the status with one endpoint per
+// ClusterWorkspaceShard selected by the optional Partition of the slice.
+type controller struct {
+	queue workqueue.RateLimitingInterface
+
+	apiExportEndpointSliceLister  apisv1alpha1listers.APIExportEndpointSliceClusterLister
+	apiExportEndpointSliceIndexer cache.Indexer
+
+	getAPIExport               func(clusterName logicalcluster.Name, name string) (*apisv1alpha1.APIExport, error)
+	getPartition               func(clusterName logicalcluster.Name, name string) (*topologyv1alpha1.Partition, error)
+	listClusterWorkspaceShards func(selector labels.Selector) ([]*tenancyv1alpha1.ClusterWorkspaceShard, error)
+
+	commit CommitFunc
+}
+
+// enqueueAPIExportEndpointSlice enqueues an APIExportEndpointSlice.
+func (c *controller) enqueueAPIExportEndpointSlice(obj interface{}) {
+	key, err := kcpcache.DeletionHandlingMetaClusterNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+
+	logger := logging.WithQueueKey(logging.WithReconciler(klog.Background(), ControllerName), key)
+	logger.V(4).Info("queueing APIExportEndpointSlice")
+	c.queue.Add(key)
+}
+
+// enqueueFromAPIExport enqueues all the APIExportEndpointSlices referencing an APIExport.
+func (c *controller) enqueueFromAPIExport(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	apiExport, ok := obj.(*apisv1alpha1.APIExport)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("obj is supposed to be an APIExport, but is %T", obj))
+		return
+	}
+
+	key := client.ToClusterAwareKey(logicalcluster.From(apiExport), apiExport.Name)
+	slices, err := c.apiExportEndpointSliceIndexer.ByIndex(indexAPIExportEndpointSlicesByAPIExport, key)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+
+	logger := logging.WithObject(logging.WithReconciler(klog.Background(), ControllerName), apiExport)
+	for _, slice := range slices {
+		sliceKey, err := kcpcache.MetaClusterNamespaceKeyFunc(slice)
+		if err != nil {
+			runtime.HandleError(err)
+			continue
+		}
+		logging.WithQueueKey(logger, sliceKey).V(2).Info("queueing APIExportEndpointSlice because APIExport changed")
+		c.queue.Add(sliceKey)
+	}
+}
+
+// enqueueFromPartition enqueues all the APIExportEndpointSlices referencing a Partition.
+func (c *controller) enqueueFromPartition(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	partition, ok := obj.(*topologyv1alpha1.Partition)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("obj is supposed to be a Partition, but is %T", obj))
+		return
+	}
+
+	key := client.ToClusterAwareKey(logicalcluster.From(partition), partition.Name)
+	slices, err := c.apiExportEndpointSliceIndexer.ByIndex(indexAPIExportEndpointSlicesByPartition, key)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+
+	logger := logging.WithObject(logging.WithReconciler(klog.Background(), ControllerName), partition)
+	for _, slice := range slices {
+		sliceKey, err := kcpcache.MetaClusterNamespaceKeyFunc(slice)
+		if err != nil {
+			runtime.HandleError(err)
+			continue
+		}
+		logging.WithQueueKey(logger, sliceKey).V(2).Info("queueing APIExportEndpointSlice because Partition changed")
+		c.queue.Add(sliceKey)
+	}
+}
+
+// enqueueAllAPIExportEndpointSlices enqueues all APIExportEndpointSlices when a ClusterWorkspaceShard changed,
+// as any of them could select the shard.
+func (c *controller) enqueueAllAPIExportEndpointSlices(clusterWorkspaceShard interface{}) {
+	list, err := c.apiExportEndpointSliceLister.List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+
+	if tombstone, ok := clusterWorkspaceShard.(cache.DeletedFinalStateUnknown); ok {
+		clusterWorkspaceShard = tombstone.Obj
+	}
+	shard, ok := clusterWorkspaceShard.(*tenancyv1alpha1.ClusterWorkspaceShard)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("obj is supposed to be a ClusterWorkspaceShard, but is %T", clusterWorkspaceShard))
+		return
+	}
+
+	logger := logging.WithObject(logging.WithReconciler(klog.Background(), ControllerName), shard)
+	for i := range list {
+		key, err := kcpcache.MetaClusterNamespaceKeyFunc(list[i])
+		if err != nil {
+			runtime.HandleError(err)
+			continue
+		}
+
+		logging.WithQueueKey(logger, key).V(2).Info("queuing APIExportEndpointSlice because ClusterWorkspaceShard changed")
+		c.queue.Add(key)
+	}
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *controller) Start(ctx context.Context, numThreads int) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	logger := logging.WithReconciler(klog.FromContext(ctx), ControllerName)
+	ctx = klog.NewContext(ctx, logger)
+	logger.Info("Starting controller")
+	defer logger.Info("Shutting down controller")
+
+	for i := 0; i < numThreads; i++ {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	k, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	key := k.(string)
+
+	logger := logging.WithQueueKey(klog.FromContext(ctx), key)
+	ctx = klog.NewContext(ctx, logger)
+	logger.V(4).Info("processing key")
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.process(ctx, key); err != nil {
+		runtime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", ControllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *controller) process(ctx context.Context, key string) error {
+	cluster, _, name, err := kcpcache.SplitMetaClusterNamespaceKey(key)
+	if err != nil {
+		runtime.HandleError(err)
+		return nil
+	}
+
+	obj, err := c.apiExportEndpointSliceLister.Cluster(cluster).Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil // object deleted before we handled it
+		}
+		return err
+	}
+
+	old := obj
+	obj = obj.DeepCopy()
+
+	logger := logging.WithObject(klog.FromContext(ctx), obj)
+	ctx = klog.NewContext(ctx, logger)
+
+	var errs []error
+	if err := c.reconcile(ctx, obj); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Regardless of whether reconcile returned an error or not, always try to patch status if needed. Return the
+	// reconciliation error at the end.
+
+	// If the object being reconciled changed as a result, update it.
+	oldResource := &Resource{ObjectMeta: old.ObjectMeta, Spec: &old.Spec, Status: &old.Status}
+	newResource := &Resource{ObjectMeta: obj.ObjectMeta, Spec: &obj.Spec, Status: &obj.Status}
+	if err := c.commit(ctx, oldResource, newResource); err != nil {
+		errs = append(errs, err)
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
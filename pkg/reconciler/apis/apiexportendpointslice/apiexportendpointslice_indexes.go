@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiexportendpointslice
+
+import (
+	"fmt"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/client"
+)
+
+const indexAPIExportEndpointSlicesByAPIExport = "apiExportEndpointSlicesByAPIExport"
+
+// indexAPIExportEndpointSlicesByAPIExportFunc is an index function that maps an APIExportEndpointSlice to the key
+// for its spec.apiExport.workspace.
+func indexAPIExportEndpointSlicesByAPIExportFunc(obj interface{}) ([]string, error) {
+	slice, ok := obj.(*apisv1alpha1.APIExportEndpointSlice)
+	if !ok {
+		return []string{}, fmt.Errorf("obj is supposed to be an APIExportEndpointSlice, but is %T", obj)
+	}
+
+	if slice.Spec.APIExport.Workspace == nil {
+		return []string{}, nil
+	}
+
+	return []string{client.ToClusterAwareKey(apiExportClusterName(slice), slice.Spec.APIExport.Workspace.ExportName)}, nil
+}
+
+const indexAPIExportEndpointSlicesByPartition = "apiExportEndpointSlicesByPartition"
+
+// indexAPIExportEndpointSlicesByPartitionFunc is an index function that maps an APIExportEndpointSlice to the key
+// for its spec.partition. Partitions are always looked up in the logical cluster of the slice.
+func indexAPIExportEndpointSlicesByPartitionFunc(obj interface{}) ([]string, error) {
+	slice, ok := obj.(*apisv1alpha1.APIExportEndpointSlice)
+	if !ok {
+		return []string{}, fmt.Errorf("obj is supposed to be an APIExportEndpointSlice, but is %T", obj)
+	}
+
+	if slice.Spec.Partition == "" {
+		return []string{}, nil
+	}
+
+	return []string{client.ToClusterAwareKey(logicalcluster.From(slice), slice.Spec.Partition)}, nil
+}
+
+// apiExportClusterName returns the logical cluster of the APIExport referenced by the slice. An empty path
+// refers to the logical cluster of the slice itself.
+func apiExportClusterName(slice *apisv1alpha1.APIExportEndpointSlice) logicalcluster.Name {
+	if slice.Spec.APIExport.Workspace == nil || slice.Spec.APIExport.Workspace.Path == "" {
+		return logicalcluster.From(slice)
+	}
+	return logicalcluster.New(slice.Spec.APIExport.Workspace.Path)
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiexportendpointslice
+
+import (
+	"context"
+	"net/url"
+	"path"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	virtualworkspacesoptions "github.com/kcp-dev/kcp/cmd/virtual-workspaces/options"
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
+	"github.com/kcp-dev/kcp/pkg/logging"
+	apiexportbuilder "github.com/kcp-dev/kcp/pkg/virtual/apiexport/builder"
+)
+
+func (c *controller) reconcile(ctx context.Context, apiExportEndpointSlice *apisv1alpha1.APIExportEndpointSlice) error {
+	workspaceRef := apiExportEndpointSlice.Spec.APIExport.Workspace
+	if workspaceRef == nil {
+		// this should not happen because of OpenAPI
+		conditions.MarkFalse(
+			apiExportEndpointSlice,
+			apisv1alpha1.APIExportValid,
+			apisv1alpha1.APIExportInvalidReferenceReason,
+			conditionsv1alpha1.ConditionSeverityError,
+			"Missing APIExport reference",
+		)
+		return nil
+	}
+
+	exportClusterName := apiExportClusterName(apiExportEndpointSlice)
+	apiExport, err := c.getAPIExport(exportClusterName, workspaceRef.ExportName)
+	if apierrors.IsNotFound(err) {
+		conditions.MarkFalse(
+			apiExportEndpointSlice,
+			apisv1alpha1.APIExportValid,
+			apisv1alpha1.APIExportNotFoundReason,
+			conditionsv1alpha1.ConditionSeverityError,
+			"APIExport %s|%s not found",
+			exportClusterName,
+			workspaceRef.ExportName,
+		)
+		return nil
+	}
+	if err != nil {
+		conditions.MarkFalse(
+			apiExportEndpointSlice,
+			apisv1alpha1.APIExportValid,
+			apisv1alpha1.InternalErrorReason,
+			conditionsv1alpha1.ConditionSeverityError,
+			"Error getting APIExport %s|%s: %v",
+			exportClusterName,
+			workspaceRef.ExportName,
+			err,
+		)
+		return err
+	}
+	conditions.MarkTrue(apiExportEndpointSlice, apisv1alpha1.APIExportValid)
+
+	selector := labels.Everything()
+	if apiExportEndpointSlice.Spec.Partition != "" {
+		clusterName := logicalcluster.From(apiExportEndpointSlice)
+		partition, err := c.getPartition(clusterName, apiExportEndpointSlice.Spec.Partition)
+		if apierrors.IsNotFound(err) {
+			conditions.MarkFalse(
+				apiExportEndpointSlice,
+				apisv1alpha1.PartitionValid,
+				apisv1alpha1.PartitionInvalidReferenceReason,
+				conditionsv1alpha1.ConditionSeverityError,
+				"Partition %s|%s not found",
+				clusterName,
+				apiExportEndpointSlice.Spec.Partition,
+			)
+			return nil
+		}
+		if err != nil {
+			conditions.MarkFalse(
+				apiExportEndpointSlice,
+				apisv1alpha1.PartitionValid,
+				apisv1alpha1.InternalErrorReason,
+				conditionsv1alpha1.ConditionSeverityError,
+				"Error getting Partition %s|%s: %v",
+				clusterName,
+				apiExportEndpointSlice.Spec.Partition,
+				err,
+			)
+			return err
+		}
+
+		// A partition without selector does not filter any shard.
+		if partition.Spec.Selector != nil {
+			selector, err = metav1.LabelSelectorAsSelector(partition.Spec.Selector)
+			if err != nil {
+				conditions.MarkFalse(
+					apiExportEndpointSlice,
+					apisv1alpha1.PartitionValid,
+					apisv1alpha1.PartitionInvalidReferenceReason,
+					conditionsv1alpha1.ConditionSeverityError,
+					"Invalid selector in Partition %s|%s: %v",
+					clusterName,
+					apiExportEndpointSlice.Spec.Partition,
+					err,
+				)
+				return nil
+			}
+		}
+	}
+	conditions.MarkTrue(apiExportEndpointSlice, apisv1alpha1.PartitionValid)
+
+	if err := c.updateEndpoints(ctx, apiExportEndpointSlice, apiExport, selector); err != nil {
+		conditions.MarkFalse(
+			apiExportEndpointSlice,
+			apisv1alpha1.APIExportEndpointSliceURLsReady,
+			apisv1alpha1.ErrorGeneratingURLsReason,
+			conditionsv1alpha1.ConditionSeverityError,
+			err.Error(),
+		)
+		return err
+	}
+
+	return nil
+}
+
+func (c *controller) updateEndpoints(ctx context.Context, apiExportEndpointSlice *apisv1alpha1.APIExportEndpointSlice, apiExport *apisv1alpha1.APIExport, selector labels.Selector) error {
+	logger := klog.FromContext(ctx)
+	clusterWorkspaceShards, err := c.listClusterWorkspaceShards(selector)
+	if err != nil {
+		return err
+	}
+
+	desiredURLs := sets.NewString()
+	for _, clusterWorkspaceShard := range clusterWorkspaceShards {
+		logger := logging.WithObject(logger, clusterWorkspaceShard)
+		if clusterWorkspaceShard.Spec.VirtualWorkspaceURL == "" {
+			continue
+		}
+
+		u, err := url.Parse(clusterWorkspaceShard.Spec.VirtualWorkspaceURL)
+		if err != nil {
+			// Should never happen
+			logger.Error(
+				err, "error parsing ClusterWorkspaceShard.Spec.VirtualWorkspaceURL",
+				"VirtualWorkspaceURL", clusterWorkspaceShard.Spec.VirtualWorkspaceURL,
+			)
+
+			continue
+		}
+
+		u.Path = path.Join(
+			u.Path,
+			virtualworkspacesoptions.DefaultRootPathPrefix,
+			apiexportbuilder.VirtualWorkspaceName,
+			logicalcluster.From(apiExport).String(),
+			apiExport.Name,
+		)
+
+		desiredURLs.Insert(u.String())
+	}
+
+	apiExportEndpointSlice.Status.APIExportEndpoints = nil
+	for _, u := range desiredURLs.List() {
+		apiExportEndpointSlice.Status.APIExportEndpoints = append(apiExportEndpointSlice.Status.APIExportEndpoints, apisv1alpha1.APIExportEndpoint{
+			URL: u,
+		})
+	}
+
+	conditions.MarkTrue(apiExportEndpointSlice, apisv1alpha1.APIExportEndpointSliceURLsReady)
+
+	return nil
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiexportendpointslice
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
+	topologyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/topology/v1alpha1"
+)
+
+func TestReconcile(t *testing.T) {
+	shards := []*tenancyv1alpha1.ClusterWorkspaceShard{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{logicalcluster.AnnotationKey: "root"},
+				Name:        "shard1",
+				Labels:      map[string]string{"region": "eu"},
+			},
+			Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{
+				VirtualWorkspaceURL: "https://server-1.kcp.dev/",
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{logicalcluster.AnnotationKey: "root"},
+				Name:        "shard2",
+				Labels:      map[string]string{"region": "us"},
+			},
+			Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{
+				VirtualWorkspaceURL: "https://server-2.kcp.dev/",
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{logicalcluster.AnnotationKey: "root"},
+				Name:        "shard-without-url",
+				Labels:      map[string]string{"region": "eu"},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		partition                       string
+		partitionSelector               *metav1.LabelSelector
+		apiExportMissing                bool
+		partitionMissing                bool
+		listClusterWorkspaceShardsError error
+
+		wantError              bool
+		wantAPIExportValid     bool
+		wantAPIExportNotFound  bool
+		wantPartitionValid     bool
+		wantPartitionInvalid   bool
+		wantEndpointURLsReady  bool
+		wantEndpointURLsFailed bool
+		wantEndpoints          []string
+	}{
+		"APIExport not found": {
+			apiExportMissing: true,
+
+			wantAPIExportNotFound: true,
+		},
+		"endpoints for all shards without partition": {
+			wantAPIExportValid:    true,
+			wantPartitionValid:    true,
+			wantEndpointURLsReady: true,
+			wantEndpoints: []string{
+				"https://server-1.kcp.dev/services/apiexport/root:org:ws/my-export",
+				"https://server-2.kcp.dev/services/apiexport/root:org:ws/my-export",
+			},
+		},
+		"endpoints filtered by partition": {
+			partition:         "eu",
+			partitionSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"region": "eu"}},
+
+			wantAPIExportValid:    true,
+			wantPartitionValid:    true,
+			wantEndpointURLsReady: true,
+			wantEndpoints: []string{
+				"https://server-1.kcp.dev/services/apiexport/root:org:ws/my-export",
+			},
+		},
+		"partition without selector selects all shards": {
+			partition: "all",
+
+			wantAPIExportValid:    true,
+			wantPartitionValid:    true,
+			wantEndpointURLsReady: true,
+			wantEndpoints: []string{
+				"https://server-1.kcp.dev/services/apiexport/root:org:ws/my-export",
+				"https://server-2.kcp.dev/services/apiexport/root:org:ws/my-export",
+			},
+		},
+		"partition not found": {
+			partition:        "eu",
+			partitionMissing: true,
+
+			wantAPIExportValid:   true,
+			wantPartitionInvalid: true,
+		},
+		"error listing shards": {
+			listClusterWorkspaceShardsError: errors.New("foo"),
+
+			wantError:              true,
+			wantAPIExportValid:     true,
+			wantPartitionValid:     true,
+			wantEndpointURLsFailed: true,
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc // to avoid t.Parallel() races
+
+		t.Run(name, func(t *testing.T) {
+			c := &controller{
+				getAPIExport: func(clusterName logicalcluster.Name, name string) (*apisv1alpha1.APIExport, error) {
+					require.Equal(t, "root:org:ws", clusterName.String())
+					if tc.apiExportMissing {
+						return nil, apierrors.NewNotFound(apisv1alpha1.Resource("apiexports"), name)
+					}
+					return &apisv1alpha1.APIExport{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{logicalcluster.AnnotationKey: clusterName.String()},
+							Name:        name,
+						},
+					}, nil
+				},
+				getPartition: func(clusterName logicalcluster.Name, name string) (*topologyv1alpha1.Partition, error) {
+					require.Equal(t, "root:org:consumer", clusterName.String())
+					if tc.partitionMissing {
+						return nil, apierrors.NewNotFound(topologyv1alpha1.Resource("partitions"), name)
+					}
+					return &topologyv1alpha1.Partition{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{logicalcluster.AnnotationKey: clusterName.String()},
+							Name:        name,
+						},
+						Spec: topologyv1alpha1.PartitionSpec{
+							Selector: tc.partitionSelector,
+						},
+					}, nil
+				},
+				listClusterWorkspaceShards: func(selector labels.Selector) ([]*tenancyv1alpha1.ClusterWorkspaceShard, error) {
+					if tc.listClusterWorkspaceShardsError != nil {
+						return nil, tc.listClusterWorkspaceShardsError
+					}
+					var ret []*tenancyv1alpha1.ClusterWorkspaceShard
+					for _, shard := range shards {
+						if selector.Matches(labels.Set(shard.Labels)) {
+							ret = append(ret, shard)
+						}
+					}
+					return ret, nil
+				},
+			}
+
+			slice := &apisv1alpha1.APIExportEndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{logicalcluster.AnnotationKey: "root:org:consumer"},
+					Name:        "my-slice",
+				},
+				Spec: apisv1alpha1.APIExportEndpointSliceSpec{
+					APIExport: apisv1alpha1.ExportReference{
+						Workspace: &apisv1alpha1.WorkspaceExportReference{
+							Path:       "root:org:ws",
+							ExportName: "my-export",
+						},
+					},
+					Partition: tc.partition,
+				},
+			}
+
+			err := c.reconcile(context.Background(), slice)
+			if tc.wantError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			if tc.wantAPIExportValid {
+				require.True(t, conditions.IsTrue(slice, apisv1alpha1.APIExportValid))
+			}
+			if tc.wantAPIExportNotFound {
+				require.True(t, conditions.IsFalse(slice, apisv1alpha1.APIExportValid))
+				require.Equal(t, apisv1alpha1.APIExportNotFoundReason, conditions.GetReason(slice, apisv1alpha1.APIExportValid))
+			}
+			if tc.wantPartitionValid {
+				require.True(t, conditions.IsTrue(slice, apisv1alpha1.PartitionValid))
+			}
+			if tc.wantPartitionInvalid {
+				require.True(t, conditions.IsFalse(slice, apisv1alpha1.PartitionValid))
+				require.Equal(t, apisv1alpha1.PartitionInvalidReferenceReason, conditions.GetReason(slice, apisv1alpha1.PartitionValid))
+			}
+			if tc.wantEndpointURLsReady {
+				require.True(t, conditions.IsTrue(slice, apisv1alpha1.APIExportEndpointSliceURLsReady))
+			}
+			if tc.wantEndpointURLsFailed {
+				require.True(t, conditions.IsFalse(slice, apisv1alpha1.APIExportEndpointSliceURLsReady))
+				require.Equal(t, apisv1alpha1.ErrorGeneratingURLsReason, conditions.GetReason(slice, apisv1alpha1.APIExportEndpointSliceURLsReady))
+			}
+
+			var got []string
+			for _, endpoint := range slice.Status.APIExportEndpoints {
+				got = append(got, endpoint.URL)
+			}
+			require.Equal(t, tc.wantEndpoints, got)
+		})
+	}
+}
@@ -25,7 +25,6 @@ import (
 
 	"github.com/go-logr/logr"
 	kcpcache "github.com/kcp-dev/apimachinery/pkg/cache"
-	"github.com/kcp-dev/logicalcluster/v2"
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -195,12 +194,12 @@ func (c *controller) process(ctx context.Context, key string) error {
 		return err
 	}
 
-	if apiBinding.Spec.Reference.Workspace == nil {
+	apiExportClusterName, apiExportName, ok := apisv1alpha1.ReferencedAPIExport(apiBinding)
+	if !ok {
 		return nil
 	}
 
-	apiExportClusterName := logicalcluster.New(apiBinding.Spec.Reference.Workspace.Path)
-	apiExport, err := c.apiExportsLister.Cluster(apiExportClusterName).Get(apiBinding.Spec.Reference.Workspace.ExportName)
+	apiExport, err := c.apiExportsLister.Cluster(apiExportClusterName).Get(apiExportName)
 	if errors.IsNotFound(err) {
 		return nil
 	}
@@ -24,6 +24,7 @@ import (
 	kcpkubernetesinformers "github.com/kcp-dev/client-go/informers"
 	"github.com/kcp-dev/logicalcluster/v2"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -50,13 +51,12 @@ func (c *controller) reconcile(ctx context.Context, apiBinding *apisv1alpha1.API
 
 	clusterName := logicalcluster.From(apiBinding)
 
-	if apiBinding.Spec.Reference.Workspace == nil {
+	exportClusterName, exportName, ok := apisv1alpha1.ReferencedAPIExport(apiBinding)
+	if !ok {
 		return nil
 	}
 
-	exportClusterName := apiBinding.Spec.Reference.Workspace.Path
-	exportName := apiBinding.Spec.Reference.Workspace.ExportName
-	apiExport, err := c.getAPIExport(logicalcluster.New(exportClusterName), exportName)
+	apiExport, err := c.getAPIExport(exportClusterName, exportName)
 	if err != nil {
 		logger.Error(err, "error getting APIExport", "apiExportWorkspace", exportClusterName, "apiExportName", exportName)
 		return nil // nothing we can do
@@ -86,7 +86,9 @@ func (c *controller) reconcile(ctx context.Context, apiBinding *apisv1alpha1.API
 
 	expectedClaims := exportedClaims.Intersection(acceptedClaims)
 	unexpectedClaims := acceptedClaims.Difference(expectedClaims)
-	needToApply := expectedClaims.Difference(appliedClaims)
+	// claims whose scope changed after they were applied, e.g. by adding a resource selector, are applied
+	// again such that objects are relabeled.
+	needToApply := expectedClaims.Difference(appliedClaims).Union(expectedClaims.Intersection(changedClaims(apiBinding.Status.AppliedPermissionClaims, acceptedClaimsMap)))
 	needToRemove := appliedClaims.Difference(acceptedClaims)
 	allChanges := needToApply.Union(needToRemove)
 
@@ -227,6 +229,18 @@ func (c *controller) reconcile(ctx context.Context, apiBinding *apisv1alpha1.API
 	return nil
 }
 
+// changedClaims returns the set keys of the applied claims that differ from the accepted claims with the same key.
+func changedClaims(appliedClaims []apisv1alpha1.PermissionClaim, acceptedClaims map[string]apisv1alpha1.PermissionClaim) sets.String {
+	changed := sets.NewString()
+	for _, applied := range appliedClaims {
+		key := setKeyForClaim(applied)
+		if accepted, found := acceptedClaims[key]; found && !equality.Semantic.DeepEqual(applied, accepted) {
+			changed.Insert(key)
+		}
+	}
+	return changed
+}
+
 func setKeyForClaim(claim apisv1alpha1.PermissionClaim) string {
 	return fmt.Sprintf("%s/%s/%s", claim.Resource, claim.Group, claim.IdentityHash)
 }
@@ -72,3 +72,53 @@ func TestClaimSetKeys(t *testing.T) {
 		})
 	}
 }
+
+func TestChangedClaims(t *testing.T) {
+	configMaps := apisv1alpha1.PermissionClaim{
+		GroupResource: apisv1alpha1.GroupResource{Resource: "configmaps"},
+		All:           true,
+	}
+	selectedConfigMaps := apisv1alpha1.PermissionClaim{
+		GroupResource:    apisv1alpha1.GroupResource{Resource: "configmaps"},
+		ResourceSelector: []apisv1alpha1.ResourceSelector{{Namespace: "default"}},
+	}
+	secrets := apisv1alpha1.PermissionClaim{
+		GroupResource: apisv1alpha1.GroupResource{Resource: "secrets"},
+		All:           true,
+	}
+
+	tests := map[string]struct {
+		applied  []apisv1alpha1.PermissionClaim
+		accepted []apisv1alpha1.PermissionClaim
+		want     []string
+	}{
+		"unchanged": {
+			applied:  []apisv1alpha1.PermissionClaim{configMaps, secrets},
+			accepted: []apisv1alpha1.PermissionClaim{configMaps, secrets},
+			want:     []string{},
+		},
+		"selector added": {
+			applied:  []apisv1alpha1.PermissionClaim{configMaps, secrets},
+			accepted: []apisv1alpha1.PermissionClaim{selectedConfigMaps, secrets},
+			want:     []string{"configmaps//"},
+		},
+		"not yet applied": {
+			accepted: []apisv1alpha1.PermissionClaim{selectedConfigMaps},
+			want:     []string{},
+		},
+		"no longer accepted": {
+			applied: []apisv1alpha1.PermissionClaim{configMaps},
+			want:    []string{},
+		},
+	}
+
+	for testName, tc := range tests {
+		t.Run(testName, func(t *testing.T) {
+			accepted := map[string]apisv1alpha1.PermissionClaim{}
+			for _, claim := range tc.accepted {
+				accepted[setKeyForClaim(claim)] = claim
+			}
+			require.Equal(t, tc.want, changedClaims(tc.applied, accepted).List())
+		})
+	}
+}
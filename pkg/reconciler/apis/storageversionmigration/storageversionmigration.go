@@ -0,0 +1,65 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storageversionmigration
+
+import (
+	"context"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// migrationPageSize is the number of objects listed at once while re-writing objects.
+const migrationPageSize = 500
+
+type listResourcesFunc func(ctx context.Context, cluster logicalcluster.Name, gvr schema.GroupVersionResource, opts metav1.ListOptions) (*unstructured.UnstructuredList, error)
+type updateResourceFunc func(ctx context.Context, cluster logicalcluster.Name, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error
+
+// migrateResource re-writes all objects of the given resource in the given logical cluster. The version of gvr
+// must be the current storage version, such that the no-op updates persist every object in that version.
+// It returns the number of re-written objects.
+func migrateResource(ctx context.Context, list listResourcesFunc, update updateResourceFunc, cluster logicalcluster.Name, gvr schema.GroupVersionResource) (int, error) {
+	migrated := 0
+	opts := metav1.ListOptions{Limit: migrationPageSize}
+	for {
+		objs, err := list(ctx, cluster, gvr, opts)
+		if err != nil {
+			return migrated, err
+		}
+
+		for i := range objs.Items {
+			if err := update(ctx, cluster, gvr, &objs.Items[i]); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue // deleted objects don't need migration
+				}
+				// a conflict means the object has been written since we listed it, but we retry anyway to
+				// be sure it's written in the storage version.
+				return migrated, err
+			}
+			migrated++
+		}
+
+		if objs.GetContinue() == "" {
+			return migrated, nil
+		}
+		opts.Continue = objs.GetContinue()
+	}
+}
@@ -0,0 +1,220 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storageversionmigration
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	kcpcache "github.com/kcp-dev/apimachinery/pkg/cache"
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	"k8s.io/apiextensions-apiserver/pkg/apihelpers"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	kcpapiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/kcp/clientset/versioned"
+	kcpapiextensionsv1informers "k8s.io/apiextensions-apiserver/pkg/client/kcp/informers/externalversions/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	apiresourcev1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apiresource/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/reconciler/apis/apibinding"
+)
+
+const (
+	CRDControllerName = "kcp-storageversionmigration-crd"
+)
+
+// NewCRDController returns a new controller that re-writes objects of negotiated APIs in the storage version of
+// their CRD, and trims the stored versions of the CRD status afterwards.
+func NewCRDController(
+	crdClusterClient kcpapiextensionsclientset.ClusterInterface,
+	dynamicClusterClient kcpdynamic.ClusterInterface,
+	crdInformer kcpapiextensionsv1informers.CustomResourceDefinitionClusterInformer,
+) (*crdController, error) {
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), CRDControllerName)
+
+	c := &crdController{
+		queue: queue,
+		getCRD: func(clusterName logicalcluster.Name, name string) (*apiextensionsv1.CustomResourceDefinition, error) {
+			return crdInformer.Lister().Cluster(clusterName).Get(name)
+		},
+		updateCRDStatus: func(ctx context.Context, crd *apiextensionsv1.CustomResourceDefinition) error {
+			_, err := crdClusterClient.Cluster(logicalcluster.From(crd)).ApiextensionsV1().CustomResourceDefinitions().UpdateStatus(ctx, crd, metav1.UpdateOptions{})
+			return err
+		},
+		listResources: func(ctx context.Context, cluster logicalcluster.Name, gvr schema.GroupVersionResource, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+			return dynamicClusterClient.Cluster(cluster).Resource(gvr).Namespace(metav1.NamespaceAll).List(ctx, opts)
+		},
+		updateResource: func(ctx context.Context, cluster logicalcluster.Name, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+			_, err := dynamicClusterClient.Cluster(cluster).Resource(gvr).Namespace(obj.GetNamespace()).Update(ctx, obj, metav1.UpdateOptions{})
+			return err
+		},
+	}
+
+	crdInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: func(obj interface{}) bool {
+			switch obj := obj.(type) {
+			case *apiextensionsv1.CustomResourceDefinition:
+				return isNegotiatedCRD(obj) && len(obj.Status.StoredVersions) > 1
+			default:
+				return false
+			}
+		},
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { c.enqueueCRD(obj) },
+			UpdateFunc: func(_, obj interface{}) { c.enqueueCRD(obj) },
+		},
+	})
+
+	return c, nil
+}
+
+// crdController migrates objects of negotiated APIs whose CRD lists more than one stored version.
+type crdController struct {
+	queue workqueue.RateLimitingInterface
+
+	getCRD          func(clusterName logicalcluster.Name, name string) (*apiextensionsv1.CustomResourceDefinition, error)
+	updateCRDStatus func(ctx context.Context, crd *apiextensionsv1.CustomResourceDefinition) error
+	listResources   listResourcesFunc
+	updateResource  updateResourceFunc
+}
+
+// isNegotiatedCRD returns true if the CRD has been created for a NegotiatedAPIResource. Bound CRDs in the
+// shadow workspace are handled through their APIBindings instead.
+func isNegotiatedCRD(crd *apiextensionsv1.CustomResourceDefinition) bool {
+	if logicalcluster.From(crd) == apibinding.ShadowWorkspaceName || !crd.DeletionTimestamp.IsZero() {
+		return false
+	}
+	for _, reference := range crd.OwnerReferences {
+		if reference.APIVersion == apiresourcev1alpha1.SchemeGroupVersion.String() && reference.Kind == "NegotiatedAPIResource" {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *crdController) enqueueCRD(obj interface{}) {
+	key, err := kcpcache.DeletionHandlingMetaClusterNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	logger := logging.WithQueueKey(logging.WithReconciler(klog.Background(), CRDControllerName), key)
+	logger.V(2).Info("queueing CustomResourceDefinition")
+	c.queue.Add(key)
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *crdController) Start(ctx context.Context, numThreads int) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	logger := logging.WithReconciler(klog.FromContext(ctx), CRDControllerName)
+	ctx = klog.NewContext(ctx, logger)
+	logger.Info("Starting controller")
+	defer logger.Info("Shutting down controller")
+
+	for i := 0; i < numThreads; i++ {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *crdController) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *crdController) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	k, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	key := k.(string)
+
+	logger := logging.WithQueueKey(klog.FromContext(ctx), key)
+	ctx = klog.NewContext(ctx, logger)
+	logger.V(4).Info("processing key")
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.process(ctx, key); err != nil {
+		runtime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", CRDControllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *crdController) process(ctx context.Context, key string) error {
+	clusterName, _, name, err := kcpcache.SplitMetaClusterNamespaceKey(key)
+	if err != nil {
+		runtime.HandleError(err)
+		return nil
+	}
+
+	crd, err := c.getCRD(clusterName, name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil // object deleted before we handled it
+		}
+		return err
+	}
+
+	logger := logging.WithObject(klog.FromContext(ctx), crd)
+	ctx = klog.NewContext(ctx, logger)
+
+	return c.reconcile(ctx, crd.DeepCopy())
+}
+
+func (c *crdController) reconcile(ctx context.Context, crd *apiextensionsv1.CustomResourceDefinition) error {
+	logger := klog.FromContext(ctx)
+
+	if !isNegotiatedCRD(crd) || len(crd.Status.StoredVersions) <= 1 {
+		return nil
+	}
+
+	storageVersion, err := apihelpers.GetCRDStorageVersion(crd)
+	if err != nil {
+		return err
+	}
+	gvr := schema.GroupVersionResource{Group: crd.Spec.Group, Version: storageVersion, Resource: crd.Spec.Names.Plural}
+
+	migrated, err := migrateResource(ctx, c.listResources, c.updateResource, logicalcluster.From(crd), gvr)
+	if err != nil {
+		return fmt.Errorf("failed to migrate %s to storage version %s after %d objects: %w", gvr.GroupResource(), storageVersion, migrated, err)
+	}
+	logger.V(2).Info("migrated objects to storage version", "resource", gvr.GroupResource(), "version", storageVersion, "count", migrated)
+
+	crd.Status.StoredVersions = []string{storageVersion}
+	return c.updateCRDStatus(ctx, crd)
+}
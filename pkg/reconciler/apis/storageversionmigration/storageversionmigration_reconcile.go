@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storageversionmigration
+
+import (
+	"context"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	"k8s.io/apiextensions-apiserver/pkg/apihelpers"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
+	"github.com/kcp-dev/kcp/pkg/projection"
+	"github.com/kcp-dev/kcp/pkg/reconciler/apis/apibinding"
+)
+
+// reconcile migrates the objects of one bound resource per call, such that progress is reported in the
+// StorageVersionsMigrated condition in between. The status update re-queues the APIBinding until all bound
+// resources list only their current storage version.
+func (c *controller) reconcile(ctx context.Context, apiBinding *apisv1alpha1.APIBinding) error {
+	logger := klog.FromContext(ctx)
+
+	if !needsMigration(apiBinding) {
+		return nil
+	}
+
+	var pending []int
+	for i, r := range apiBinding.Status.BoundResources {
+		if len(r.StorageVersions) > 1 {
+			pending = append(pending, i)
+		}
+	}
+
+	boundResource := &apiBinding.Status.BoundResources[pending[0]]
+	gr := schema.GroupResource{Group: boundResource.Group, Resource: boundResource.Resource}
+
+	// the apibinding controller creates the bound CRD if it is missing, and we get re-queued on error.
+	crd, err := c.getCRD(apibinding.ShadowWorkspaceName, boundResource.Schema.UID)
+	if err != nil {
+		return err
+	}
+	storageVersion, err := apihelpers.GetCRDStorageVersion(crd)
+	if err != nil {
+		return err
+	}
+	gvr := gr.WithVersion(storageVersion)
+
+	// Projected resources are not persisted through the bound CRD, hence there is nothing to migrate.
+	migrated := 0
+	if !projection.Includes(gvr) {
+		migrated, err = migrateResource(ctx, c.listResources, c.updateResource, logicalcluster.From(apiBinding), gvr)
+		if err != nil {
+			conditions.MarkFalse(
+				apiBinding,
+				apisv1alpha1.StorageVersionsMigrated,
+				apisv1alpha1.StorageVersionMigrationFailedReason,
+				conditionsv1alpha1.ConditionSeverityWarning,
+				"Failed to migrate %s to storage version %s after %d objects: %v",
+				gr, storageVersion, migrated, err,
+			)
+			return err
+		}
+	}
+
+	logger.V(2).Info("migrated objects to storage version", "resource", gr, "version", storageVersion, "count", migrated)
+	boundResource.StorageVersions = []string{storageVersion}
+
+	if remaining := len(pending) - 1; remaining > 0 {
+		conditions.MarkFalse(
+			apiBinding,
+			apisv1alpha1.StorageVersionsMigrated,
+			apisv1alpha1.StorageVersionMigrationInProgressReason,
+			conditionsv1alpha1.ConditionSeverityInfo,
+			"Migrated %d objects of %s to storage version %s, %d resources remaining",
+			migrated, gr, storageVersion, remaining,
+		)
+		return nil
+	}
+
+	conditions.MarkTrue(apiBinding, apisv1alpha1.StorageVersionsMigrated)
+	return nil
+}
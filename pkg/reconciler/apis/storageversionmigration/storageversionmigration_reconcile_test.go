@@ -0,0 +1,229 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storageversionmigration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
+	"github.com/kcp-dev/kcp/pkg/reconciler/apis/apibinding"
+)
+
+func newBinding(boundResources ...apisv1alpha1.BoundAPIResource) *apisv1alpha1.APIBinding {
+	return &apisv1alpha1.APIBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "binding",
+			Annotations: map[string]string{
+				logicalcluster.AnnotationKey: "root:org:ws",
+			},
+		},
+		Status: apisv1alpha1.APIBindingStatus{
+			Phase:          apisv1alpha1.APIBindingPhaseBound,
+			BoundResources: boundResources,
+		},
+	}
+}
+
+func newBoundResource(resource, uid string, storageVersions ...string) apisv1alpha1.BoundAPIResource {
+	return apisv1alpha1.BoundAPIResource{
+		Group:           "kcp.dev",
+		Resource:        resource,
+		Schema:          apisv1alpha1.BoundAPIResourceSchema{UID: uid},
+		StorageVersions: storageVersions,
+	}
+}
+
+func newBoundCRD(uid string, storageVersion string) *apiextensionsv1.CustomResourceDefinition {
+	return &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: uid,
+			Annotations: map[string]string{
+				logicalcluster.AnnotationKey: apibinding.ShadowWorkspaceName.String(),
+			},
+		},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+				{Name: "v1", Storage: storageVersion == "v1"},
+				{Name: "v2", Storage: storageVersion == "v2"},
+			},
+		},
+	}
+}
+
+func newObject(name string) unstructured.Unstructured {
+	obj := unstructured.Unstructured{}
+	obj.SetName(name)
+	obj.SetNamespace("default")
+	return obj
+}
+
+func TestReconcile(t *testing.T) {
+	tests := map[string]struct {
+		apiBinding  *apisv1alpha1.APIBinding
+		pages       map[string][]unstructured.Unstructured
+		updateError error
+
+		wantUpdated         []string
+		wantStorageVersions map[string][]string
+		wantReason          string
+		wantReady           bool
+		wantError           bool
+	}{
+		"nothing to migrate": {
+			apiBinding:          newBinding(newBoundResource("widgets", "uid-widgets", "v1")),
+			wantStorageVersions: map[string][]string{"widgets": {"v1"}},
+		},
+		"objects are re-written and storage versions trimmed": {
+			apiBinding: newBinding(newBoundResource("widgets", "uid-widgets", "v1", "v2")),
+			pages: map[string][]unstructured.Unstructured{
+				"":      {newObject("a"), newObject("b")},
+				"page2": {newObject("c")},
+			},
+			wantUpdated:         []string{"a", "b", "c"},
+			wantStorageVersions: map[string][]string{"widgets": {"v2"}},
+			wantReady:           true,
+		},
+		"one resource per pass": {
+			apiBinding: newBinding(
+				newBoundResource("widgets", "uid-widgets", "v1", "v2"),
+				newBoundResource("gadgets", "uid-gadgets", "v1", "v2"),
+			),
+			pages: map[string][]unstructured.Unstructured{
+				"": {newObject("a")},
+			},
+			wantUpdated:         []string{"a"},
+			wantStorageVersions: map[string][]string{"widgets": {"v2"}, "gadgets": {"v1", "v2"}},
+			wantReason:          apisv1alpha1.StorageVersionMigrationInProgressReason,
+		},
+		"deleted objects are ignored": {
+			apiBinding: newBinding(newBoundResource("widgets", "uid-widgets", "v1", "v2")),
+			pages: map[string][]unstructured.Unstructured{
+				"": {newObject("a")},
+			},
+			updateError:         apierrors.NewNotFound(schema.GroupResource{Group: "kcp.dev", Resource: "widgets"}, "a"),
+			wantStorageVersions: map[string][]string{"widgets": {"v2"}},
+			wantReady:           true,
+		},
+		"failed updates keep storage versions": {
+			apiBinding: newBinding(newBoundResource("widgets", "uid-widgets", "v1", "v2")),
+			pages: map[string][]unstructured.Unstructured{
+				"": {newObject("a")},
+			},
+			updateError:         errors.New("boom"),
+			wantStorageVersions: map[string][]string{"widgets": {"v1", "v2"}},
+			wantReason:          apisv1alpha1.StorageVersionMigrationFailedReason,
+			wantError:           true,
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc // to avoid t.Parallel() races
+		t.Run(name, func(t *testing.T) {
+			var updated []string
+			c := &controller{
+				getCRD: func(clusterName logicalcluster.Name, name string) (*apiextensionsv1.CustomResourceDefinition, error) {
+					require.Equal(t, apibinding.ShadowWorkspaceName, clusterName)
+					return newBoundCRD(name, "v2"), nil
+				},
+				listResources: func(ctx context.Context, cluster logicalcluster.Name, gvr schema.GroupVersionResource, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+					require.Equal(t, logicalcluster.New("root:org:ws"), cluster)
+					require.Equal(t, "v2", gvr.Version)
+					list := &unstructured.UnstructuredList{Items: tc.pages[opts.Continue]}
+					if opts.Continue == "" && len(tc.pages) > 1 {
+						list.SetContinue("page2")
+					}
+					return list, nil
+				},
+				updateResource: func(ctx context.Context, cluster logicalcluster.Name, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+					if tc.updateError != nil {
+						return tc.updateError
+					}
+					updated = append(updated, obj.GetName())
+					return nil
+				},
+			}
+
+			err := c.reconcile(context.Background(), tc.apiBinding)
+			if tc.wantError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, tc.wantUpdated, updated)
+			for _, r := range tc.apiBinding.Status.BoundResources {
+				require.Equal(t, tc.wantStorageVersions[r.Resource], r.StorageVersions, "unexpected storage versions for %s", r.Resource)
+			}
+
+			cond := conditions.Get(tc.apiBinding, apisv1alpha1.StorageVersionsMigrated)
+			switch {
+			case tc.wantReady:
+				require.True(t, conditions.IsTrue(tc.apiBinding, apisv1alpha1.StorageVersionsMigrated))
+			case tc.wantReason != "":
+				require.NotNil(t, cond)
+				require.Equal(t, tc.wantReason, cond.Reason)
+			default:
+				require.Nil(t, cond)
+			}
+		})
+	}
+}
+
+// TestReconcileConverges alternates the migration with the storage version merge of the APIBinding controller,
+// as happens on resyncs, against a shared bound CRD that keeps listing all versions it ever stored.
+func TestReconcileConverges(t *testing.T) {
+	crd := newBoundCRD("uid-widgets", "v2")
+	crd.Status.StoredVersions = []string{"v1", "v2"}
+
+	apiBinding := newBinding(newBoundResource("widgets", "uid-widgets", "v1", "v2"))
+
+	migrations := 0
+	c := &controller{
+		getCRD: func(clusterName logicalcluster.Name, name string) (*apiextensionsv1.CustomResourceDefinition, error) {
+			return crd, nil
+		},
+		listResources: func(ctx context.Context, cluster logicalcluster.Name, gvr schema.GroupVersionResource, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+			migrations++
+			return &unstructured.UnstructuredList{Items: []unstructured.Unstructured{newObject("a")}}, nil
+		},
+		updateResource: func(ctx context.Context, cluster logicalcluster.Name, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+			return nil
+		},
+	}
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, c.reconcile(context.Background(), apiBinding))
+
+		boundResource := &apiBinding.Status.BoundResources[0]
+		boundResource.StorageVersions = apibinding.MergeStorageVersions(boundResource, boundResource.Schema.UID, crd)
+		require.Equal(t, []string{"v2"}, boundResource.StorageVersions, "storage versions after resync %d", i)
+	}
+	require.Equal(t, 1, migrations, "objects must only be migrated once")
+	require.False(t, needsMigration(apiBinding))
+}
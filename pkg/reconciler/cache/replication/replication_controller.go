@@ -52,6 +52,11 @@ const (
 // The replicated object will be placed under the same cluster as the original object.
 // In addition to that, all replicated objects will be placed under the shard taken from the shardName argument.
 // For example: shards/{shardName}/clusters/{clusterName}/apis/apis.kcp.dev/v1alpha1/apiexports
+//
+// APIBindings are replicated so that APIExports can be related to their consumers on all shards.
+// ClusterWorkspaces are replicated so that the read-only state and the quota of a workspace are known
+// on the shard storing the workspace, independent of the shard storing its parent.
+// ClusterWorkspaceTypes are replicated so that the retention of deleted workspaces is known on all shards.
 func NewController(
 	shardName string,
 	dynamicCacheClient kcpdynamic.ClusterInterface,
@@ -65,11 +70,17 @@ func NewController(
 		dynamicCacheClient:                 dynamicCacheClient,
 		dynamicLocalClient:                 dynamicLocalClient,
 		localAPIExportLister:               localKcpInformers.Apis().V1alpha1().APIExports().Lister(),
+		localAPIBindingLister:              localKcpInformers.Apis().V1alpha1().APIBindings().Lister(),
 		localAPIResourceSchemaLister:       localKcpInformers.Apis().V1alpha1().APIResourceSchemas().Lister(),
 		localClusterWorkspaceShardLister:   localKcpInformers.Tenancy().V1alpha1().ClusterWorkspaceShards().Lister(),
+		localClusterWorkspaceLister:        localKcpInformers.Tenancy().V1alpha1().ClusterWorkspaces().Lister(),
+		localClusterWorkspaceTypeLister:    localKcpInformers.Tenancy().V1alpha1().ClusterWorkspaceTypes().Lister(),
 		globalAPIExportIndexer:             globalKcpInformers.Apis().V1alpha1().APIExports().Informer().GetIndexer(),
+		globalAPIBindingIndexer:            globalKcpInformers.Apis().V1alpha1().APIBindings().Informer().GetIndexer(),
 		globalAPIResourceSchemaIndexer:     globalKcpInformers.Apis().V1alpha1().APIResourceSchemas().Informer().GetIndexer(),
 		globalClusterWorkspaceShardIndexer: globalKcpInformers.Tenancy().V1alpha1().ClusterWorkspaceShards().Informer().GetIndexer(),
+		globalClusterWorkspaceIndexer:      globalKcpInformers.Tenancy().V1alpha1().ClusterWorkspaces().Informer().GetIndexer(),
+		globalClusterWorkspaceTypeIndexer:  globalKcpInformers.Tenancy().V1alpha1().ClusterWorkspaceTypes().Informer().GetIndexer(),
 	}
 
 	indexers.AddIfNotPresentOrDie(
@@ -79,6 +90,13 @@ func NewController(
 		},
 	)
 
+	indexers.AddIfNotPresentOrDie(
+		globalKcpInformers.Apis().V1alpha1().APIBindings().Informer().GetIndexer(),
+		cache.Indexers{
+			ByShardAndLogicalClusterAndNamespaceAndName: IndexByShardAndLogicalClusterAndNamespace,
+		},
+	)
+
 	indexers.AddIfNotPresentOrDie(
 		globalKcpInformers.Apis().V1alpha1().APIResourceSchemas().Informer().GetIndexer(),
 		cache.Indexers{
@@ -93,12 +111,32 @@ func NewController(
 		},
 	)
 
+	indexers.AddIfNotPresentOrDie(
+		globalKcpInformers.Tenancy().V1alpha1().ClusterWorkspaces().Informer().GetIndexer(),
+		cache.Indexers{
+			ByShardAndLogicalClusterAndNamespaceAndName: IndexByShardAndLogicalClusterAndNamespace,
+		},
+	)
+
+	indexers.AddIfNotPresentOrDie(
+		globalKcpInformers.Tenancy().V1alpha1().ClusterWorkspaceTypes().Informer().GetIndexer(),
+		cache.Indexers{
+			ByShardAndLogicalClusterAndNamespaceAndName: IndexByShardAndLogicalClusterAndNamespace,
+		},
+	)
+
 	localKcpInformers.Apis().V1alpha1().APIExports().Informer().AddEventHandler(c.apiExportInformerEventHandler())
+	localKcpInformers.Apis().V1alpha1().APIBindings().Informer().AddEventHandler(c.apiBindingInformerEventHandler())
 	localKcpInformers.Apis().V1alpha1().APIResourceSchemas().Informer().AddEventHandler(c.apiResourceSchemaInformerEventHandler())
 	localKcpInformers.Tenancy().V1alpha1().ClusterWorkspaceShards().Informer().AddEventHandler(c.clusterWorkspaceShardInformerEventHandler())
+	localKcpInformers.Tenancy().V1alpha1().ClusterWorkspaces().Informer().AddEventHandler(c.clusterWorkspaceInformerEventHandler())
+	localKcpInformers.Tenancy().V1alpha1().ClusterWorkspaceTypes().Informer().AddEventHandler(c.clusterWorkspaceTypeInformerEventHandler())
 	globalKcpInformers.Apis().V1alpha1().APIExports().Informer().AddEventHandler(c.apiExportInformerEventHandler())
+	globalKcpInformers.Apis().V1alpha1().APIBindings().Informer().AddEventHandler(c.apiBindingInformerEventHandler())
 	globalKcpInformers.Apis().V1alpha1().APIResourceSchemas().Informer().AddEventHandler(c.apiResourceSchemaInformerEventHandler())
 	globalKcpInformers.Tenancy().V1alpha1().ClusterWorkspaceShards().Informer().AddEventHandler(c.clusterWorkspaceShardInformerEventHandler())
+	globalKcpInformers.Tenancy().V1alpha1().ClusterWorkspaces().Informer().AddEventHandler(c.clusterWorkspaceInformerEventHandler())
+	globalKcpInformers.Tenancy().V1alpha1().ClusterWorkspaceTypes().Informer().AddEventHandler(c.clusterWorkspaceTypeInformerEventHandler())
 
 	return c, nil
 }
@@ -107,6 +145,10 @@ func (c *controller) enqueueAPIExport(obj interface{}) {
 	c.enqueueObject(obj, apisv1alpha1.SchemeGroupVersion.WithResource("apiexports"))
 }
 
+func (c *controller) enqueueAPIBinding(obj interface{}) {
+	c.enqueueObject(obj, apisv1alpha1.SchemeGroupVersion.WithResource("apibindings"))
+}
+
 func (c *controller) enqueueAPIResourceSchema(obj interface{}) {
 	c.enqueueObject(obj, apisv1alpha1.SchemeGroupVersion.WithResource("apiresourceschemas"))
 }
@@ -115,6 +157,14 @@ func (c *controller) enqueueClusterWorkspaceShard(obj interface{}) {
 	c.enqueueObject(obj, tenancyv1alpha1.SchemeGroupVersion.WithResource("clusterworkspaceshards"))
 }
 
+func (c *controller) enqueueClusterWorkspace(obj interface{}) {
+	c.enqueueObject(obj, tenancyv1alpha1.SchemeGroupVersion.WithResource("clusterworkspaces"))
+}
+
+func (c *controller) enqueueClusterWorkspaceType(obj interface{}) {
+	c.enqueueObject(obj, tenancyv1alpha1.SchemeGroupVersion.WithResource("clusterworkspacetypes"))
+}
+
 func (c *controller) enqueueObject(obj interface{}, gvr schema.GroupVersionResource) {
 	key, err := kcpcache.DeletionHandlingMetaClusterNamespaceKeyFunc(obj)
 	if err != nil {
@@ -171,6 +221,10 @@ func (c *controller) apiExportInformerEventHandler() cache.ResourceEventHandler
 	return objectInformerEventHandler(c.enqueueAPIExport)
 }
 
+func (c *controller) apiBindingInformerEventHandler() cache.ResourceEventHandler {
+	return objectInformerEventHandler(c.enqueueAPIBinding)
+}
+
 func (c *controller) apiResourceSchemaInformerEventHandler() cache.ResourceEventHandler {
 	return objectInformerEventHandler(c.enqueueAPIResourceSchema)
 }
@@ -179,6 +233,14 @@ func (c *controller) clusterWorkspaceShardInformerEventHandler() cache.ResourceE
 	return objectInformerEventHandler(c.enqueueClusterWorkspaceShard)
 }
 
+func (c *controller) clusterWorkspaceInformerEventHandler() cache.ResourceEventHandler {
+	return objectInformerEventHandler(c.enqueueClusterWorkspace)
+}
+
+func (c *controller) clusterWorkspaceTypeInformerEventHandler() cache.ResourceEventHandler {
+	return objectInformerEventHandler(c.enqueueClusterWorkspaceType)
+}
+
 func objectInformerEventHandler(enqueueObject func(obj interface{})) cache.ResourceEventHandler {
 	return cache.ResourceEventHandlerFuncs{
 		AddFunc:    func(obj interface{}) { enqueueObject(obj) },
@@ -195,10 +257,16 @@ type controller struct {
 	dynamicLocalClient kcpdynamic.ClusterInterface
 
 	localAPIExportLister             apisv1alpha1listers.APIExportClusterLister
+	localAPIBindingLister            apisv1alpha1listers.APIBindingClusterLister
 	localAPIResourceSchemaLister     apisv1alpha1listers.APIResourceSchemaClusterLister
 	localClusterWorkspaceShardLister tenancyv1alpha1listers.ClusterWorkspaceShardClusterLister
+	localClusterWorkspaceLister      tenancyv1alpha1listers.ClusterWorkspaceClusterLister
+	localClusterWorkspaceTypeLister  tenancyv1alpha1listers.ClusterWorkspaceTypeClusterLister
 
 	globalAPIExportIndexer             cache.Indexer
+	globalAPIBindingIndexer            cache.Indexer
 	globalAPIResourceSchemaIndexer     cache.Indexer
 	globalClusterWorkspaceShardIndexer cache.Indexer
+	globalClusterWorkspaceIndexer      cache.Indexer
+	globalClusterWorkspaceTypeIndexer  cache.Indexer
 }
@@ -53,6 +53,17 @@ func (c *controller) reconcile(ctx context.Context, gvrKey string) error {
 			func(cluster logicalcluster.Name, _, name string) (interface{}, error) {
 				return c.localAPIExportLister.Cluster(cluster).Get(name)
 			})
+	case apisv1alpha1.SchemeGroupVersion.WithResource("apibindings").String():
+		return c.reconcileObject(ctx,
+			keyParts[1],
+			apisv1alpha1.SchemeGroupVersion.WithResource("apibindings"),
+			apisv1alpha1.SchemeGroupVersion.WithKind("APIBinding"),
+			func(gvr schema.GroupVersionResource, cluster logicalcluster.Name, namespace, name string) (interface{}, error) {
+				return retrieveCacheObject(&gvr, c.globalAPIBindingIndexer, c.shardName, cluster, namespace, name)
+			},
+			func(cluster logicalcluster.Name, _, name string) (interface{}, error) {
+				return c.localAPIBindingLister.Cluster(cluster).Get(name)
+			})
 	case apisv1alpha1.SchemeGroupVersion.WithResource("apiresourceschemas").String():
 		return c.reconcileObject(ctx,
 			keyParts[1],
@@ -75,6 +86,28 @@ func (c *controller) reconcile(ctx context.Context, gvrKey string) error {
 			func(cluster logicalcluster.Name, _, name string) (interface{}, error) {
 				return c.localClusterWorkspaceShardLister.Cluster(cluster).Get(name)
 			})
+	case tenancyv1alpha1.SchemeGroupVersion.WithResource("clusterworkspaces").String():
+		return c.reconcileObject(ctx,
+			keyParts[1],
+			tenancyv1alpha1.SchemeGroupVersion.WithResource("clusterworkspaces"),
+			tenancyv1alpha1.SchemeGroupVersion.WithKind("ClusterWorkspace"),
+			func(gvr schema.GroupVersionResource, cluster logicalcluster.Name, namespace, name string) (interface{}, error) {
+				return retrieveCacheObject(&gvr, c.globalClusterWorkspaceIndexer, c.shardName, cluster, namespace, name)
+			},
+			func(cluster logicalcluster.Name, _, name string) (interface{}, error) {
+				return c.localClusterWorkspaceLister.Cluster(cluster).Get(name)
+			})
+	case tenancyv1alpha1.SchemeGroupVersion.WithResource("clusterworkspacetypes").String():
+		return c.reconcileObject(ctx,
+			keyParts[1],
+			tenancyv1alpha1.SchemeGroupVersion.WithResource("clusterworkspacetypes"),
+			tenancyv1alpha1.SchemeGroupVersion.WithKind("ClusterWorkspaceType"),
+			func(gvr schema.GroupVersionResource, cluster logicalcluster.Name, namespace, name string) (interface{}, error) {
+				return retrieveCacheObject(&gvr, c.globalClusterWorkspaceTypeIndexer, c.shardName, cluster, namespace, name)
+			},
+			func(cluster logicalcluster.Name, _, name string) (interface{}, error) {
+				return c.localClusterWorkspaceTypeLister.Cluster(cluster).Get(name)
+			})
 	default:
 		return fmt.Errorf("unsupported resource %v", keyParts[0])
 	}
@@ -0,0 +1,409 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	kcpcache "github.com/kcp-dev/apimachinery/pkg/cache"
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/kcp/pkg/apis/workload/helpers"
+	"github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	workloadinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/indexers"
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/reconciler/committer"
+	"github.com/kcp-dev/kcp/tmc/pkg/coordination"
+)
+
+// Object is a resource coordinated across the SyncTargets it is synced to.
+type Object = coordination.Object
+
+// NewController returns a new controller coordinating the resources described by the strategy
+// between the SyncTargets they are synced to. The SyncTarget informer is only used, and required,
+// by the weighted split strategy.
+func NewController[R Object, Sp any, St any](
+	ctx context.Context,
+	strategy Strategy[R, Sp, St],
+	informer cache.SharedIndexInformer,
+	get func(clusterName logicalcluster.Name, namespace, name string) (R, error),
+	patcher func(clusterName logicalcluster.Name, namespace string) committer.Patcher[R],
+	syncTargetInformer workloadinformers.SyncTargetClusterInformer,
+	splitStrategy SplitStrategy,
+	weightLabel string,
+) (*Controller[R, Sp, St], error) {
+	if splitStrategy == WeightedSplitStrategy && syncTargetInformer == nil {
+		return nil, fmt.Errorf("the %s split strategy requires a SyncTarget informer", splitStrategy)
+	}
+
+	name := ControllerName(strategy.GVR.GroupResource())
+	c := &Controller[R, Sp, St]{
+		name:                name,
+		strategy:            strategy,
+		upstreamViewQueue:   workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), name+"upstream-view"),
+		syncerViewQueue:     workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), name+"syncer-view"),
+		syncerViewRetriever: coordination.NewDefaultSyncerViewManager[R](),
+		get:                 get,
+		patcher:             patcher,
+	}
+
+	logger := logging.WithReconciler(klog.FromContext(ctx), name)
+
+	if splitStrategy == WeightedSplitStrategy && strategy.Split != nil {
+		indexers.AddIfNotPresentOrDie(syncTargetInformer.Informer().GetIndexer(), cache.Indexers{
+			indexers.SyncTargetsBySyncTargetKey: indexers.IndexSyncTargetsBySyncTargetKey,
+		})
+		indexers.AddIfNotPresentOrDie(informer.GetIndexer(), cache.Indexers{
+			indexers.ByClusterResourceStateLabelKey: indexers.IndexByClusterResourceStateLabelKey,
+		})
+
+		c.getSyncTargetWeight = func(syncTargetKey string) (int64, bool) {
+			syncTargets, err := indexers.ByIndex[*v1alpha1.SyncTarget](syncTargetInformer.Informer().GetIndexer(), indexers.SyncTargetsBySyncTargetKey, syncTargetKey)
+			if err != nil || len(syncTargets) != 1 {
+				return 0, false
+			}
+			return syncTargetWeight(syncTargets[0], weightLabel), true
+		}
+
+		syncTargetInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			UpdateFunc: func(old, new interface{}) {
+				oldSyncTarget, ok := old.(*v1alpha1.SyncTarget)
+				if !ok {
+					return
+				}
+				newSyncTarget, ok := new.(*v1alpha1.SyncTarget)
+				if !ok {
+					return
+				}
+				if oldSyncTarget.Labels[weightLabel] == newSyncTarget.Labels[weightLabel] {
+					return
+				}
+
+				syncTargetKey := v1alpha1.ToSyncTargetKey(logicalcluster.From(newSyncTarget), newSyncTarget.Name)
+				objs, err := informer.GetIndexer().ByIndex(indexers.ByClusterResourceStateLabelKey, v1alpha1.ClusterResourceStateLabelPrefix+syncTargetKey)
+				if err != nil {
+					utilruntime.HandleError(err)
+					return
+				}
+				for _, obj := range objs {
+					enqueue(obj, c.upstreamViewQueue, logging.WithObject(logger, newSyncTarget).WithValues("view", "upstream"))
+				}
+			},
+		})
+	}
+
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if strategy.Split != nil {
+				enqueue(obj, c.upstreamViewQueue, logger.WithValues("view", "upstream"))
+			}
+			enqueue(obj, c.syncerViewQueue, logger.WithValues("view", "syncer"))
+		},
+		UpdateFunc: func(old, new interface{}) {
+			oldObj, ok := old.(coordination.Object)
+			if !ok {
+				utilruntime.HandleError(fmt.Errorf("resource should be a coordination.Object, but was %T", oldObj))
+				return
+			}
+			newObj, ok := new.(coordination.Object)
+			if !ok {
+				utilruntime.HandleError(fmt.Errorf("resource should be a coordination.Object, but was %T", newObj))
+				return
+			}
+
+			if coordination.AnySyncerViewChanged(oldObj, newObj) {
+				enqueue(new, c.syncerViewQueue, logger.WithValues("view", "syncer"))
+			}
+			if strategy.Split != nil && coordination.UpstreamViewChanged(oldObj, newObj, c.contentsEqual) {
+				enqueue(new, c.upstreamViewQueue, logger.WithValues("view", "upstream"))
+			}
+		},
+	})
+
+	return c, nil
+}
+
+// ControllerName returns the name of the coordination controller of the given resource.
+func ControllerName(resource fmt.Stringer) string {
+	return "kcp-" + resource.String() + "-coordination"
+}
+
+// Controller watches resources and coordinates them between SyncTargets: it splits their spec
+// across the SyncTargets with the spec-diff annotations, and aggregates the statuses of the syncer
+// views into the upstream status.
+type Controller[R Object, Sp any, St any] struct {
+	name     string
+	strategy Strategy[R, Sp, St]
+
+	upstreamViewQueue workqueue.RateLimitingInterface
+	syncerViewQueue   workqueue.RateLimitingInterface
+
+	get     func(clusterName logicalcluster.Name, namespace, name string) (R, error)
+	patcher func(clusterName logicalcluster.Name, namespace string) committer.Patcher[R]
+
+	// getSyncTargetWeight returns the weight of the SyncTarget with the given key, and false if it is not
+	// found. It is nil for the even split strategy.
+	getSyncTargetWeight func(syncTargetKey string) (int64, bool)
+
+	syncerViewRetriever coordination.SyncerViewRetriever[R]
+}
+
+func (c *Controller[R, Sp, St]) committer(clusterName logicalcluster.Name, namespace string) func(context.Context, *committer.Resource[*Sp, *St], *committer.Resource[*Sp, *St]) error {
+	return committer.NewCommitterScoped[R, committer.Patcher[R], *Sp, *St](c.patcher(clusterName, namespace))
+}
+
+func filter[K comparable, V interface{}](aMap map[K]V, keep func(key K) bool) map[K]V {
+	result := make(map[K]V)
+	for key, val := range aMap {
+		if keep(key) {
+			result[key] = val
+		}
+	}
+	return result
+}
+
+// contentsEqual compares the labels, the annotations other than the spec-diff ones, and the
+// fields read by the Split function of the strategy.
+func (c *Controller[R, Sp, St]) contentsEqual(old, new interface{}) bool {
+	oldObj, ok := old.(R)
+	if !ok {
+		return false
+	}
+	newObj, ok := new.(R)
+	if !ok {
+		return false
+	}
+
+	if !equality.Semantic.DeepEqual(oldObj.GetLabels(), newObj.GetLabels()) {
+		return false
+	}
+
+	oldAnnotations := filter(oldObj.GetAnnotations(), func(key string) bool {
+		return !strings.HasPrefix(key, v1alpha1.ClusterSpecDiffAnnotationPrefix)
+	})
+	newAnnotations := filter(newObj.GetAnnotations(), func(key string) bool {
+		return !strings.HasPrefix(key, v1alpha1.ClusterSpecDiffAnnotationPrefix)
+	})
+	if !equality.Semantic.DeepEqual(oldAnnotations, newAnnotations) {
+		return false
+	}
+
+	return c.strategy.SplitInputsEqual == nil || c.strategy.SplitInputsEqual(oldObj, newObj)
+}
+
+// enqueue adds the logical cluster to the queue.
+func enqueue(obj interface{}, queue workqueue.RateLimitingInterface, logger logr.Logger) {
+	key, err := kcpcache.DeletionHandlingMetaClusterNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	logger = logging.WithQueueKey(logger, key)
+	logger.V(2).Info("queueing resource")
+	queue.Add(key)
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *Controller[R, Sp, St]) Start(ctx context.Context, numThreads int) {
+	defer utilruntime.HandleCrash()
+	defer c.upstreamViewQueue.ShutDown()
+	defer c.syncerViewQueue.ShutDown()
+
+	logger := logging.WithReconciler(klog.FromContext(ctx), c.name)
+	ctx = klog.NewContext(ctx, logger)
+	logger.Info("Starting controller")
+	defer logger.Info("Shutting down controller")
+
+	for i := 0; i < numThreads; i++ {
+		go wait.UntilWithContext(ctx, c.startUpstreamViewWorker, time.Second)
+		go wait.UntilWithContext(ctx, c.startSyncerViewWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *Controller[R, Sp, St]) startUpstreamViewWorker(ctx context.Context) {
+	logger := klog.FromContext(ctx).WithValues("view", "upstream")
+	ctx = klog.NewContext(ctx, logger)
+	for processNextWorkItem(ctx, c.name, c.upstreamViewQueue, c.processUpstreamView) {
+	}
+}
+
+func (c *Controller[R, Sp, St]) startSyncerViewWorker(ctx context.Context) {
+	logger := klog.FromContext(ctx).WithValues("view", "syncer")
+	ctx = klog.NewContext(ctx, logger)
+	for processNextWorkItem(ctx, c.name, c.syncerViewQueue, c.processSyncerView) {
+	}
+}
+
+func processNextWorkItem(ctx context.Context, controllerName string, queue workqueue.RateLimitingInterface, process func(context.Context, string) error) bool {
+	// Wait until there is a new item in the working queue
+	k, quit := queue.Get()
+	if quit {
+		return false
+	}
+	key := k.(string)
+
+	logger := logging.WithQueueKey(klog.FromContext(ctx), key)
+	ctx = klog.NewContext(ctx, logger)
+	logger.V(1).Info("processing key")
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer queue.Done(key)
+
+	if err := process(ctx, key); err != nil {
+		utilruntime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", controllerName, key, err))
+		queue.AddRateLimited(key)
+		return true
+	}
+	queue.Forget(key)
+	return true
+}
+
+func (c *Controller[R, Sp, St]) processUpstreamView(ctx context.Context, key string) error {
+	logger := klog.FromContext(ctx)
+
+	clusterName, namespace, name, err := kcpcache.SplitMetaClusterNamespaceKey(key)
+	if err != nil {
+		logger.Error(err, "failed to split key, dropping")
+		return nil
+	}
+
+	obj, err := c.get(clusterName, namespace, name)
+	if err != nil {
+		return err
+	}
+	logger = logging.WithObject(logger, obj)
+	ctx = klog.NewContext(ctx, logger)
+
+	syncIntents, err := helpers.GetSyncIntents(obj)
+	if err != nil {
+		return err
+	}
+
+	syncerViews := sets.NewString()
+	for syncTarget, syncTargetSyncing := range syncIntents {
+		if syncTargetSyncing.ResourceState == v1alpha1.ResourceStateSync && syncTargetSyncing.DeletionTimestamp == nil {
+			syncerViews.Insert(syncTarget)
+		}
+	}
+
+	var weights map[string]int64
+	if c.getSyncTargetWeight != nil {
+		weights = make(map[string]int64, syncerViews.Len())
+		for _, syncTargetKey := range syncerViews.List() {
+			if weight, found := c.getSyncTargetWeight(syncTargetKey); found {
+				weights[syncTargetKey] = weight
+			}
+		}
+	}
+
+	newSpecDiffAnnotation := make(map[string]string, len(syncerViews))
+	if syncerViews.Len() > 0 {
+		for syncTargetKey, patch := range c.strategy.Split(obj, syncerViews.List(), weights) {
+			newSpecDiffAnnotation[v1alpha1.ClusterSpecDiffAnnotationPrefix+syncTargetKey] = patch
+		}
+	}
+
+	updated := c.strategy.ObjectMeta(obj).DeepCopy()
+	for key := range updated.Annotations {
+		if _, found := newSpecDiffAnnotation[key]; !found &&
+			strings.HasPrefix(key, v1alpha1.ClusterSpecDiffAnnotationPrefix) {
+			delete(updated.Annotations, key)
+		}
+	}
+
+	if updated.Annotations == nil {
+		updated.Annotations = make(map[string]string, len(newSpecDiffAnnotation))
+	}
+	for key, value := range newSpecDiffAnnotation {
+		updated.Annotations[key] = value
+	}
+
+	return c.committer(clusterName, namespace)(ctx,
+		&committer.Resource[*Sp, *St]{
+			ObjectMeta: *c.strategy.ObjectMeta(obj),
+		},
+		&committer.Resource[*Sp, *St]{
+			ObjectMeta: *updated,
+		})
+}
+
+func (c *Controller[R, Sp, St]) processSyncerView(ctx context.Context, key string) error {
+	logger := klog.FromContext(ctx)
+
+	clusterName, namespace, name, err := kcpcache.SplitMetaClusterNamespaceKey(key)
+	if err != nil {
+		logger.Error(err, "failed to split key, dropping")
+		return nil
+	}
+
+	obj, err := c.get(clusterName, namespace, name)
+	if err != nil {
+		return err
+	}
+	logger = logging.WithObject(logger, obj)
+	ctx = klog.NewContext(ctx, logger)
+
+	syncerViews, err := c.syncerViewRetriever.GetAllSyncerViews(ctx, c.strategy.GVR, obj)
+	if err != nil {
+		return err
+	}
+
+	statuses := make(map[string]*St, len(syncerViews))
+	for syncTargetKey, syncerView := range syncerViews {
+		statuses[syncTargetKey] = c.strategy.Status(syncerView)
+	}
+	summarizedStatus := c.strategy.Aggregate(statuses)
+
+	return c.committer(clusterName, namespace)(ctx,
+		&committer.Resource[*Sp, *St]{
+			ObjectMeta: *c.strategy.ObjectMeta(obj),
+			Status:     c.strategy.Status(obj),
+		},
+		&committer.Resource[*Sp, *St]{
+			ObjectMeta: *c.strategy.ObjectMeta(obj),
+			Status:     &summarizedStatus,
+		})
+}
+
+// nonEmpty returns the statuses which are not empty, sorted by SyncTarget key.
+func nonEmpty[St any](statuses map[string]*St) []*St {
+	var empty St
+	result := make([]*St, 0, len(statuses))
+	for _, syncTargetKey := range sets.StringKeySet(statuses).List() {
+		if status := statuses[syncTargetKey]; status != nil && !reflect.DeepEqual(*status, empty) {
+			result = append(result, status)
+		}
+	}
+	return result
+}
@@ -14,7 +14,7 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package deployment
+package coordinator
 
 import (
 	"context"
@@ -42,7 +42,7 @@ type mockedPatcher struct {
 	appliedPatch string
 }
 
-func (p *mockedPatcher) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*Deployment, error) {
+func (p *mockedPatcher) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*appsv1.Deployment, error) {
 	p.appliedPatch = string(data)
 	return nil, nil
 }
@@ -50,6 +50,7 @@ func (p *mockedPatcher) Patch(ctx context.Context, name string, pt types.PatchTy
 func TestUpstreamViewReconciler(t *testing.T) {
 	tests := map[string]struct {
 		input        *appsv1.Deployment
+		weights      map[string]int64
 		appliedPatch string
 		wantError    bool
 	}{
@@ -70,6 +71,45 @@ func TestUpstreamViewReconciler(t *testing.T) {
 			},
 			appliedPatch: `{"metadata":{"annotations":{"experimental.spec-diff.workload.kcp.dev/syncTarget1":"[{ \"op\": \"replace\", \"path\": \"/replicas\", \"value\": 4 }]","experimental.spec-diff.workload.kcp.dev/syncTarget2":"[{ \"op\": \"replace\", \"path\": \"/replicas\", \"value\": 3 }]"},"resourceVersion":"resourceVersion","uid":"uid"}}`,
 		},
+		"spread requested replicas evenly across three synctargets": {
+			input: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "test",
+					UID:             types.UID("uid"),
+					ResourceVersion: "resourceVersion",
+					Labels: map[string]string{
+						"state.workload.kcp.dev/syncTarget1": "Sync",
+						"state.workload.kcp.dev/syncTarget2": "Sync",
+						"state.workload.kcp.dev/syncTarget3": "Sync",
+					},
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: intPtr(8),
+				},
+			},
+			appliedPatch: `{"metadata":{"annotations":{"experimental.spec-diff.workload.kcp.dev/syncTarget1":"[{ \"op\": \"replace\", \"path\": \"/replicas\", \"value\": 3 }]","experimental.spec-diff.workload.kcp.dev/syncTarget2":"[{ \"op\": \"replace\", \"path\": \"/replicas\", \"value\": 3 }]","experimental.spec-diff.workload.kcp.dev/syncTarget3":"[{ \"op\": \"replace\", \"path\": \"/replicas\", \"value\": 2 }]"},"resourceVersion":"resourceVersion","uid":"uid"}}`,
+		},
+		"spread requested replicas by weight": {
+			input: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "test",
+					UID:             types.UID("uid"),
+					ResourceVersion: "resourceVersion",
+					Labels: map[string]string{
+						"state.workload.kcp.dev/syncTarget1": "Sync",
+						"state.workload.kcp.dev/syncTarget2": "Sync",
+					},
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: intPtr(8),
+				},
+			},
+			weights: map[string]int64{
+				"syncTarget1": 1,
+				"syncTarget2": 3,
+			},
+			appliedPatch: `{"metadata":{"annotations":{"experimental.spec-diff.workload.kcp.dev/syncTarget1":"[{ \"op\": \"replace\", \"path\": \"/replicas\", \"value\": 2 }]","experimental.spec-diff.workload.kcp.dev/syncTarget2":"[{ \"op\": \"replace\", \"path\": \"/replicas\", \"value\": 6 }]"},"resourceVersion":"resourceVersion","uid":"uid"}}`,
+		},
 		"remove obsolete spec-diff annotation": {
 			input: &appsv1.Deployment{
 				ObjectMeta: metav1.ObjectMeta{
@@ -133,8 +173,9 @@ func TestUpstreamViewReconciler(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 
 			var patcher *mockedPatcher
-			controller := controller{
-				getDeployment: func(lclusterName logicalcluster.Name, namespace, name string) (*appsv1.Deployment, error) {
+			controller := &Controller[*appsv1.Deployment, appsv1.DeploymentSpec, appsv1.DeploymentStatus]{
+				strategy: DeploymentStrategy,
+				get: func(lclusterName logicalcluster.Name, namespace, name string) (*appsv1.Deployment, error) {
 					return tc.input, nil
 				},
 				patcher: func(clusterName logicalcluster.Name, namespace string) committer.Patcher[*appsv1.Deployment] {
@@ -146,6 +187,12 @@ func TestUpstreamViewReconciler(t *testing.T) {
 				},
 				syncerViewRetriever: coordination.NewDefaultSyncerViewManager[*appsv1.Deployment](),
 			}
+			if tc.weights != nil {
+				controller.getSyncTargetWeight = func(syncTargetKey string) (int64, bool) {
+					weight, found := tc.weights[syncTargetKey]
+					return weight, found
+				}
+			}
 
 			err := controller.processUpstreamView(context.Background(), "")
 			if tc.wantError {
@@ -258,8 +305,9 @@ func TestSyncerViewReconciler(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 
 			var patcher *mockedPatcher
-			controller := controller{
-				getDeployment: func(lclusterName logicalcluster.Name, namespace, name string) (*appsv1.Deployment, error) {
+			controller := &Controller[*appsv1.Deployment, appsv1.DeploymentSpec, appsv1.DeploymentStatus]{
+				strategy: DeploymentStrategy,
+				get: func(lclusterName logicalcluster.Name, namespace, name string) (*appsv1.Deployment, error) {
 					return tc.input, nil
 				},
 				patcher: func(clusterName logicalcluster.Name, namespace string) committer.Patcher[*appsv1.Deployment] {
@@ -286,6 +334,10 @@ func TestSyncerViewReconciler(t *testing.T) {
 }
 
 func TestDeploymentContentsEqual(t *testing.T) {
+	controller := &Controller[*appsv1.Deployment, appsv1.DeploymentSpec, appsv1.DeploymentStatus]{
+		strategy: DeploymentStrategy,
+	}
+
 	tests := map[string]struct {
 		old, new *appsv1.Deployment
 		result   bool
@@ -431,7 +483,7 @@ func TestDeploymentContentsEqual(t *testing.T) {
 	}
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			require.Equal(t, tc.result, deploymentContentsEqual(tc.old, tc.new))
+			require.Equal(t, tc.result, controller.contentsEqual(tc.old, tc.new))
 		})
 	}
 }
@@ -0,0 +1,130 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coordinator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+)
+
+// SplitStrategy defines how the replicas of an upstream resource are divided across the SyncTargets
+// it is synced to.
+type SplitStrategy string
+
+const (
+	// EvenSplitStrategy gives every SyncTarget the same number of replicas.
+	EvenSplitStrategy SplitStrategy = "even"
+	// WeightedSplitStrategy gives every SyncTarget a number of replicas proportional to the integer
+	// value of its weight label. SyncTargets without a valid weight label have a weight of 1.
+	WeightedSplitStrategy SplitStrategy = "weighted"
+
+	// DefaultWeightLabel is the default SyncTarget label holding the weight of the SyncTarget for the
+	// weighted split strategy.
+	DefaultWeightLabel = "coordination.workload.kcp.dev/weight"
+)
+
+// syncTargetWeight returns the weight of the SyncTarget stored in the given label. It defaults to 1 if the
+// label is missing or is not a non-negative integer.
+func syncTargetWeight(syncTarget *v1alpha1.SyncTarget, weightLabel string) int64 {
+	value, found := syncTarget.Labels[weightLabel]
+	if !found {
+		return 1
+	}
+	weight, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || weight < 0 {
+		return 1
+	}
+	return weight
+}
+
+// splitReplicas divides replicas across the given SyncTargets proportionally to their weights, with the
+// largest remainder method. SyncTargets without weight, or all of them if no weights are given or they add
+// up to 0, have a weight of 1. Ties are broken in the order of the SyncTarget keys.
+func splitReplicas(replicas int32, syncTargetKeys []string, weights map[string]int64) map[string]int32 {
+	result := make(map[string]int32, len(syncTargetKeys))
+	if len(syncTargetKeys) == 0 {
+		return result
+	}
+
+	keyWeights := make([]int64, len(syncTargetKeys))
+	var total int64
+	for i, key := range syncTargetKeys {
+		keyWeights[i] = 1
+		if weight, found := weights[key]; found {
+			keyWeights[i] = weight
+		}
+		total += keyWeights[i]
+	}
+	if total == 0 {
+		for i := range keyWeights {
+			keyWeights[i] = 1
+		}
+		total = int64(len(keyWeights))
+	}
+
+	type remainder struct {
+		index int
+		value int64
+	}
+	remainders := make([]remainder, len(syncTargetKeys))
+	assigned := int64(0)
+	for i, key := range syncTargetKeys {
+		share := int64(replicas) * keyWeights[i]
+		result[key] = int32(share / total)
+		assigned += share / total
+		remainders[i] = remainder{index: i, value: share % total}
+	}
+
+	sort.SliceStable(remainders, func(i, j int) bool {
+		return remainders[i].value > remainders[j].value
+	})
+	for i := int64(0); i < int64(replicas)-assigned; i++ {
+		result[syncTargetKeys[remainders[i].index]]++
+	}
+
+	return result
+}
+
+// replicasPatch returns the JSON patch replacing the given integer fields of the spec.
+func replicasPatch(paths []string, values []int32) string {
+	ops := make([]string, 0, len(paths))
+	for i, path := range paths {
+		ops = append(ops, fmt.Sprintf(`{ "op": "replace", "path": "%s", "value": %d }`, path, values[i]))
+	}
+	return "[" + strings.Join(ops, ", ") + "]"
+}
+
+// SplitReplicas returns a Split function dividing the replicas of the spec at the given JSON path, as returned
+// by replicas, across the SyncTargets. Nil replicas default to 1.
+func SplitReplicas[R Object](path string, replicas func(R) *int32) func(upstream R, syncTargetKeys []string, weights map[string]int64) map[string]string {
+	return func(upstream R, syncTargetKeys []string, weights map[string]int64) map[string]string {
+		value := int32(1)
+		if r := replicas(upstream); r != nil {
+			value = *r
+		}
+
+		patches := make(map[string]string, len(syncTargetKeys))
+		for syncTargetKey, share := range splitReplicas(value, syncTargetKeys, weights) {
+			patches[syncTargetKey] = replicasPatch([]string{path}, []int32{share})
+		}
+		return patches
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coordinator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+)
+
+func TestSplitReplicas(t *testing.T) {
+	tests := map[string]struct {
+		replicas       int32
+		syncTargetKeys []string
+		weights        map[string]int64
+		want           map[string]int32
+	}{
+		"no synctarget": {
+			replicas: 3,
+			want:     map[string]int32{},
+		},
+		"even": {
+			replicas:       6,
+			syncTargetKeys: []string{"a", "b", "c"},
+			want:           map[string]int32{"a": 2, "b": 2, "c": 2},
+		},
+		"even with remainder": {
+			replicas:       7,
+			syncTargetKeys: []string{"a", "b", "c"},
+			want:           map[string]int32{"a": 3, "b": 2, "c": 2},
+		},
+		"fewer replicas than synctargets": {
+			replicas:       2,
+			syncTargetKeys: []string{"a", "b", "c"},
+			want:           map[string]int32{"a": 1, "b": 1, "c": 0},
+		},
+		"weighted": {
+			replicas:       10,
+			syncTargetKeys: []string{"a", "b", "c"},
+			weights:        map[string]int64{"a": 1, "b": 2, "c": 2},
+			want:           map[string]int32{"a": 2, "b": 4, "c": 4},
+		},
+		"weighted with largest remainder": {
+			replicas:       7,
+			syncTargetKeys: []string{"a", "b"},
+			weights:        map[string]int64{"a": 1, "b": 2},
+			want:           map[string]int32{"a": 2, "b": 5},
+		},
+		"weight zero": {
+			replicas:       5,
+			syncTargetKeys: []string{"a", "b"},
+			weights:        map[string]int64{"a": 0, "b": 1},
+			want:           map[string]int32{"a": 0, "b": 5},
+		},
+		"missing weight defaults to 1": {
+			replicas:       6,
+			syncTargetKeys: []string{"a", "b"},
+			weights:        map[string]int64{"a": 2},
+			want:           map[string]int32{"a": 4, "b": 2},
+		},
+		"all weights zero": {
+			replicas:       4,
+			syncTargetKeys: []string{"a", "b"},
+			weights:        map[string]int64{"a": 0, "b": 0},
+			want:           map[string]int32{"a": 2, "b": 2},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.want, splitReplicas(tc.replicas, tc.syncTargetKeys, tc.weights))
+		})
+	}
+}
+
+func TestSyncTargetWeight(t *testing.T) {
+	tests := map[string]struct {
+		labels map[string]string
+		want   int64
+	}{
+		"no label":       {want: 1},
+		"valid label":    {labels: map[string]string{DefaultWeightLabel: "3"}, want: 3},
+		"zero":           {labels: map[string]string{DefaultWeightLabel: "0"}, want: 0},
+		"negative label": {labels: map[string]string{DefaultWeightLabel: "-2"}, want: 1},
+		"invalid label":  {labels: map[string]string{DefaultWeightLabel: "heavy"}, want: 1},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			syncTarget := &v1alpha1.SyncTarget{ObjectMeta: metav1.ObjectMeta{Labels: tc.labels}}
+			require.Equal(t, tc.want, syncTargetWeight(syncTarget, DefaultWeightLabel))
+		})
+	}
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coordinator
+
+import (
+	"context"
+	"sort"
+
+	kubernetesinformers "github.com/kcp-dev/client-go/informers"
+	kubernetesclient "github.com/kcp-dev/client-go/kubernetes"
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+
+	workloadinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/reconciler/committer"
+)
+
+// Strategy defines how the resources of a GVR, of type R with a spec of type Sp and a status of
+// type St, are coordinated across the SyncTargets they are synced to.
+type Strategy[R Object, Sp any, St any] struct {
+	GVR schema.GroupVersionResource
+
+	// ObjectMeta and Status return pointers to the object meta and the status of the resource.
+	ObjectMeta func(R) *metav1.ObjectMeta
+	Status     func(R) *St
+
+	// Split returns, for each of the given SyncTargets, the JSON patch applied to the spec of the upstream
+	// resource in the view of its syncer, e.g. to divide the replicas. The weights are nil with the even split
+	// strategy. Split is nil if the spec is the same on every SyncTarget.
+	Split func(upstream R, syncTargetKeys []string, weights map[string]int64) map[string]string
+	// SplitInputsEqual returns true if the fields of the upstream specs read by Split are equal.
+	SplitInputsEqual func(old, new R) bool
+
+	// Aggregate returns the upstream status summarized from the statuses of the syncer views, by SyncTarget key.
+	Aggregate func(statuses map[string]*St) St
+}
+
+// Registration creates the coordination controller of a resource.
+type Registration interface {
+	GroupVersionResource() schema.GroupVersionResource
+	NewController(
+		ctx context.Context,
+		kubeClusterClient kubernetesclient.ClusterInterface,
+		kubeInformerFactory kubernetesinformers.SharedInformerFactory,
+		syncTargetInformer workloadinformers.SyncTargetClusterInformer,
+		splitStrategy SplitStrategy,
+		weightLabel string,
+	) (Runner, error)
+}
+
+// Runner is a started coordination controller.
+type Runner interface {
+	Start(ctx context.Context, numThreads int)
+}
+
+// TypedRegistration is the Registration of a Strategy, with the typed informer and client of the resource.
+type TypedRegistration[R Object, Sp any, St any] struct {
+	Strategy Strategy[R, Sp, St]
+
+	// Informer returns the informer of the resource, and a getter reading from its lister.
+	Informer func(kubernetesinformers.SharedInformerFactory) (cache.SharedIndexInformer, func(clusterName logicalcluster.Name, namespace, name string) (R, error))
+	// Patcher returns the patcher of the resources of a namespace.
+	Patcher func(kubeClusterClient kubernetesclient.ClusterInterface, clusterName logicalcluster.Name, namespace string) committer.Patcher[R]
+}
+
+var _ Registration = &TypedRegistration[Object, any, any]{}
+
+func (r *TypedRegistration[R, Sp, St]) GroupVersionResource() schema.GroupVersionResource {
+	return r.Strategy.GVR
+}
+
+func (r *TypedRegistration[R, Sp, St]) NewController(
+	ctx context.Context,
+	kubeClusterClient kubernetesclient.ClusterInterface,
+	kubeInformerFactory kubernetesinformers.SharedInformerFactory,
+	syncTargetInformer workloadinformers.SyncTargetClusterInformer,
+	splitStrategy SplitStrategy,
+	weightLabel string,
+) (Runner, error) {
+	informer, get := r.Informer(kubeInformerFactory)
+	patcher := func(clusterName logicalcluster.Name, namespace string) committer.Patcher[R] {
+		return r.Patcher(kubeClusterClient, clusterName, namespace)
+	}
+	return NewController[R, Sp, St](ctx, r.Strategy, informer, get, patcher, syncTargetInformer, splitStrategy, weightLabel)
+}
+
+// registry holds the Registrations of the coordinated resources.
+var registry = register(
+	deploymentRegistration,
+	statefulSetRegistration,
+	daemonSetRegistration,
+	jobRegistration,
+)
+
+func register(registrations ...Registration) map[schema.GroupResource]Registration {
+	result := make(map[schema.GroupResource]Registration, len(registrations))
+	for _, r := range registrations {
+		result[r.GroupVersionResource().GroupResource()] = r
+	}
+	return result
+}
+
+// Lookup returns the Registration of the given resource, and false if it cannot be coordinated.
+func Lookup(resource schema.GroupResource) (Registration, bool) {
+	r, found := registry[resource]
+	return r, found
+}
+
+// RegisteredResources returns the resources which can be coordinated, sorted.
+func RegisteredResources() []string {
+	resources := make([]string, 0, len(registry))
+	for resource := range registry {
+		resources = append(resources, resource.String())
+	}
+	sort.Strings(resources)
+	return resources
+}
+
+// consolidateConditions merges the conditions of the syncer views by type, a True condition winning
+// over a False one, winning over an Unknown one. The result is sorted by type.
+func consolidateConditions[C any](conditions [][]C, conditionType func(C) string, status func(C) corev1.ConditionStatus) []C {
+	consolidated := make(map[string]C)
+	for _, viewConditions := range conditions {
+		for _, condition := range viewConditions {
+			existing, found := consolidated[conditionType(condition)]
+			if !found {
+				consolidated[conditionType(condition)] = condition
+				continue
+			}
+			switch status(existing) {
+			case corev1.ConditionUnknown:
+				consolidated[conditionType(condition)] = condition
+			case corev1.ConditionFalse:
+				if status(condition) == corev1.ConditionTrue {
+					consolidated[conditionType(condition)] = condition
+				}
+			}
+		}
+	}
+
+	var result []C
+	for _, t := range sets.StringKeySet(consolidated).List() {
+		result = append(result, consolidated[t])
+	}
+	return result
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coordinator
+
+import (
+	kubernetesinformers "github.com/kcp-dev/client-go/informers"
+	kubernetesclient "github.com/kcp-dev/client-go/kubernetes"
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/kcp-dev/kcp/pkg/reconciler/committer"
+)
+
+// DaemonSetStrategy sums up the node counts of the statuses of DaemonSets. The spec is not split,
+// as a DaemonSet runs on the nodes of every SyncTarget.
+var DaemonSetStrategy = Strategy[*appsv1.DaemonSet, appsv1.DaemonSetSpec, appsv1.DaemonSetStatus]{
+	GVR:        appsv1.SchemeGroupVersion.WithResource("daemonsets"),
+	ObjectMeta: func(daemonSet *appsv1.DaemonSet) *metav1.ObjectMeta { return &daemonSet.ObjectMeta },
+	Status:     func(daemonSet *appsv1.DaemonSet) *appsv1.DaemonSetStatus { return &daemonSet.Status },
+	Aggregate:  aggregateDaemonSetStatuses,
+}
+
+var daemonSetRegistration = &TypedRegistration[*appsv1.DaemonSet, appsv1.DaemonSetSpec, appsv1.DaemonSetStatus]{
+	Strategy: DaemonSetStrategy,
+	Informer: func(f kubernetesinformers.SharedInformerFactory) (cache.SharedIndexInformer, func(logicalcluster.Name, string, string) (*appsv1.DaemonSet, error)) {
+		informer := f.Apps().V1().DaemonSets()
+		return informer.Informer(), func(clusterName logicalcluster.Name, namespace, name string) (*appsv1.DaemonSet, error) {
+			return informer.Lister().Cluster(clusterName).DaemonSets(namespace).Get(name)
+		}
+	},
+	Patcher: func(kubeClusterClient kubernetesclient.ClusterInterface, clusterName logicalcluster.Name, namespace string) committer.Patcher[*appsv1.DaemonSet] {
+		return kubeClusterClient.AppsV1().DaemonSets().Cluster(clusterName).Namespace(namespace)
+	},
+}
+
+func aggregateDaemonSetStatuses(statuses map[string]*appsv1.DaemonSetStatus) appsv1.DaemonSetStatus {
+	summarizedStatus := appsv1.DaemonSetStatus{}
+	var conditions [][]appsv1.DaemonSetCondition
+	for _, status := range nonEmpty(statuses) {
+		summarizedStatus.CurrentNumberScheduled += status.CurrentNumberScheduled
+		summarizedStatus.NumberMisscheduled += status.NumberMisscheduled
+		summarizedStatus.DesiredNumberScheduled += status.DesiredNumberScheduled
+		summarizedStatus.NumberReady += status.NumberReady
+		summarizedStatus.UpdatedNumberScheduled += status.UpdatedNumberScheduled
+		summarizedStatus.NumberAvailable += status.NumberAvailable
+		summarizedStatus.NumberUnavailable += status.NumberUnavailable
+		conditions = append(conditions, status.Conditions)
+	}
+
+	summarizedStatus.Conditions = consolidateConditions(conditions,
+		func(c appsv1.DaemonSetCondition) string { return string(c.Type) },
+		func(c appsv1.DaemonSetCondition) corev1.ConditionStatus { return c.Status },
+	)
+	return summarizedStatus
+}
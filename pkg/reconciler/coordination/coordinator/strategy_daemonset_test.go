@@ -0,0 +1,44 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coordinator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+func TestAggregateDaemonSetStatuses(t *testing.T) {
+	statuses := map[string]*appsv1.DaemonSetStatus{
+		"a": {CurrentNumberScheduled: 3, DesiredNumberScheduled: 3, NumberReady: 3, UpdatedNumberScheduled: 3, NumberAvailable: 3},
+		"b": {CurrentNumberScheduled: 2, NumberMisscheduled: 1, DesiredNumberScheduled: 2, NumberReady: 1, UpdatedNumberScheduled: 1, NumberAvailable: 1, NumberUnavailable: 1},
+		"c": {},
+	}
+	want := appsv1.DaemonSetStatus{
+		CurrentNumberScheduled: 5,
+		NumberMisscheduled:     1,
+		DesiredNumberScheduled: 5,
+		NumberReady:            4,
+		UpdatedNumberScheduled: 4,
+		NumberAvailable:        4,
+		NumberUnavailable:      1,
+	}
+	require.Equal(t, want, aggregateDaemonSetStatuses(statuses))
+	require.Nil(t, DaemonSetStrategy.Split, "DaemonSets run on every SyncTarget and must not be split")
+}
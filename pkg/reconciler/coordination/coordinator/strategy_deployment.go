@@ -0,0 +1,84 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coordinator
+
+import (
+	kubernetesinformers "github.com/kcp-dev/client-go/informers"
+	kubernetesclient "github.com/kcp-dev/client-go/kubernetes"
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/kcp-dev/kcp/pkg/reconciler/committer"
+)
+
+// DeploymentStrategy divides the replicas of Deployments across the SyncTargets, and sums up the
+// replica counts of their statuses.
+var DeploymentStrategy = Strategy[*appsv1.Deployment, appsv1.DeploymentSpec, appsv1.DeploymentStatus]{
+	GVR:        appsv1.SchemeGroupVersion.WithResource("deployments"),
+	ObjectMeta: func(deployment *appsv1.Deployment) *metav1.ObjectMeta { return &deployment.ObjectMeta },
+	Status:     func(deployment *appsv1.Deployment) *appsv1.DeploymentStatus { return &deployment.Status },
+	Split: SplitReplicas("/replicas", func(deployment *appsv1.Deployment) *int32 {
+		return deployment.Spec.Replicas
+	}),
+	SplitInputsEqual: func(old, new *appsv1.Deployment) bool {
+		return int32PtrEqual(old.Spec.Replicas, new.Spec.Replicas)
+	},
+	Aggregate: aggregateDeploymentStatuses,
+}
+
+var deploymentRegistration = &TypedRegistration[*appsv1.Deployment, appsv1.DeploymentSpec, appsv1.DeploymentStatus]{
+	Strategy: DeploymentStrategy,
+	Informer: func(f kubernetesinformers.SharedInformerFactory) (cache.SharedIndexInformer, func(logicalcluster.Name, string, string) (*appsv1.Deployment, error)) {
+		informer := f.Apps().V1().Deployments()
+		return informer.Informer(), func(clusterName logicalcluster.Name, namespace, name string) (*appsv1.Deployment, error) {
+			return informer.Lister().Cluster(clusterName).Deployments(namespace).Get(name)
+		}
+	},
+	Patcher: func(kubeClusterClient kubernetesclient.ClusterInterface, clusterName logicalcluster.Name, namespace string) committer.Patcher[*appsv1.Deployment] {
+		return kubeClusterClient.AppsV1().Deployments().Cluster(clusterName).Namespace(namespace)
+	},
+}
+
+func aggregateDeploymentStatuses(statuses map[string]*appsv1.DeploymentStatus) appsv1.DeploymentStatus {
+	summarizedStatus := appsv1.DeploymentStatus{}
+	var conditions [][]appsv1.DeploymentCondition
+	for _, status := range nonEmpty(statuses) {
+		summarizedStatus.Replicas += status.Replicas
+		summarizedStatus.UpdatedReplicas += status.UpdatedReplicas
+		summarizedStatus.ReadyReplicas += status.ReadyReplicas
+		summarizedStatus.AvailableReplicas += status.AvailableReplicas
+		summarizedStatus.UnavailableReplicas += status.UnavailableReplicas
+		conditions = append(conditions, status.Conditions)
+	}
+
+	summarizedStatus.Conditions = consolidateConditions(conditions,
+		func(c appsv1.DeploymentCondition) string { return string(c.Type) },
+		func(c appsv1.DeploymentCondition) corev1.ConditionStatus { return c.Status },
+	)
+	return summarizedStatus
+}
+
+func int32PtrEqual(old, new *int32) bool {
+	if old == nil || new == nil {
+		return old == new
+	}
+	return *old == *new
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coordinator
+
+import (
+	"strings"
+
+	kubernetesinformers "github.com/kcp-dev/client-go/informers"
+	kubernetesclient "github.com/kcp-dev/client-go/kubernetes"
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/reconciler/committer"
+)
+
+// JobStrategy divides the completions and the parallelism of Jobs across the SyncTargets, and sums up
+// the pod counts of their statuses. A Job is only complete when it is complete on every SyncTarget.
+var JobStrategy = Strategy[*batchv1.Job, batchv1.JobSpec, batchv1.JobStatus]{
+	GVR:        batchv1.SchemeGroupVersion.WithResource("jobs"),
+	ObjectMeta: func(job *batchv1.Job) *metav1.ObjectMeta { return &job.ObjectMeta },
+	Status:     func(job *batchv1.Job) *batchv1.JobStatus { return &job.Status },
+	Split:      splitJob,
+	SplitInputsEqual: func(old, new *batchv1.Job) bool {
+		return int32PtrEqual(old.Spec.Completions, new.Spec.Completions) &&
+			int32PtrEqual(old.Spec.Parallelism, new.Spec.Parallelism) &&
+			completionModeOf(old) == completionModeOf(new)
+	},
+	Aggregate: aggregateJobStatuses,
+}
+
+var jobRegistration = &TypedRegistration[*batchv1.Job, batchv1.JobSpec, batchv1.JobStatus]{
+	Strategy: JobStrategy,
+	Informer: func(f kubernetesinformers.SharedInformerFactory) (cache.SharedIndexInformer, func(logicalcluster.Name, string, string) (*batchv1.Job, error)) {
+		informer := f.Batch().V1().Jobs()
+		return informer.Informer(), func(clusterName logicalcluster.Name, namespace, name string) (*batchv1.Job, error) {
+			return informer.Lister().Cluster(clusterName).Jobs(namespace).Get(name)
+		}
+	},
+	Patcher: func(kubeClusterClient kubernetesclient.ClusterInterface, clusterName logicalcluster.Name, namespace string) committer.Patcher[*batchv1.Job] {
+		return kubeClusterClient.BatchV1().Jobs().Cluster(clusterName).Namespace(namespace)
+	},
+}
+
+func completionModeOf(job *batchv1.Job) batchv1.CompletionMode {
+	if job.Spec.CompletionMode == nil {
+		return batchv1.NonIndexedCompletion
+	}
+	return *job.Spec.CompletionMode
+}
+
+// splitJob divides the completions of a non-indexed Job, and its parallelism, which is capped by the
+// completions of each SyncTarget. Jobs without completions, which run until one of their pods succeeds,
+// and indexed Jobs, whose completion indexes cannot be divided, are run as is on every SyncTarget.
+//
+// The completions of a synced Job are immutable, so a Job is only split once, when it is first synced:
+// SyncTargets keep their existing patch, SyncTargets added later get no completions, and the completions
+// of removed SyncTargets are not redistributed.
+func splitJob(job *batchv1.Job, syncTargetKeys []string, weights map[string]int64) map[string]string {
+	if job.Spec.Completions == nil || completionModeOf(job) == batchv1.IndexedCompletion {
+		return nil
+	}
+
+	if existing := existingSpecDiffs(job.Annotations); len(existing) > 0 {
+		patches := make(map[string]string, len(syncTargetKeys))
+		for _, syncTargetKey := range syncTargetKeys {
+			if patch, found := existing[syncTargetKey]; found {
+				patches[syncTargetKey] = patch
+			} else {
+				patches[syncTargetKey] = replicasPatch([]string{"/completions", "/parallelism"}, []int32{0, 0})
+			}
+		}
+		return patches
+	}
+
+	parallelism := int32(1)
+	if job.Spec.Parallelism != nil {
+		parallelism = *job.Spec.Parallelism
+	}
+	parallelismShares := splitReplicas(parallelism, syncTargetKeys, weights)
+
+	patches := make(map[string]string, len(syncTargetKeys))
+	for syncTargetKey, completions := range splitReplicas(*job.Spec.Completions, syncTargetKeys, weights) {
+		share := parallelismShares[syncTargetKey]
+		if share > completions {
+			share = completions
+		}
+		if share < 1 && completions > 0 && parallelism > 0 {
+			share = 1
+		}
+		patches[syncTargetKey] = replicasPatch([]string{"/completions", "/parallelism"}, []int32{completions, share})
+	}
+	return patches
+}
+
+// existingSpecDiffs returns the spec-diff annotations of an object by SyncTarget key.
+func existingSpecDiffs(annotations map[string]string) map[string]string {
+	specDiffs := map[string]string{}
+	for key, value := range annotations {
+		if strings.HasPrefix(key, workloadv1alpha1.ClusterSpecDiffAnnotationPrefix) {
+			specDiffs[strings.TrimPrefix(key, workloadv1alpha1.ClusterSpecDiffAnnotationPrefix)] = value
+		}
+	}
+	return specDiffs
+}
+
+func aggregateJobStatuses(statuses map[string]*batchv1.JobStatus) batchv1.JobStatus {
+	summarizedStatus := batchv1.JobStatus{}
+	var conditions [][]batchv1.JobCondition
+	for _, status := range nonEmpty(statuses) {
+		summarizedStatus.Active += status.Active
+		summarizedStatus.Succeeded += status.Succeeded
+		summarizedStatus.Failed += status.Failed
+		if status.Ready != nil {
+			ready := *status.Ready
+			if summarizedStatus.Ready != nil {
+				ready += *summarizedStatus.Ready
+			}
+			summarizedStatus.Ready = &ready
+		}
+		if status.StartTime != nil && (summarizedStatus.StartTime == nil || status.StartTime.Before(summarizedStatus.StartTime)) {
+			summarizedStatus.StartTime = status.StartTime.DeepCopy()
+		}
+		if status.CompletionTime != nil && (summarizedStatus.CompletionTime == nil || summarizedStatus.CompletionTime.Before(status.CompletionTime)) {
+			summarizedStatus.CompletionTime = status.CompletionTime.DeepCopy()
+		}
+		conditions = append(conditions, status.Conditions)
+	}
+
+	summarizedStatus.Conditions = consolidateConditions(conditions,
+		func(c batchv1.JobCondition) string { return string(c.Type) },
+		func(c batchv1.JobCondition) corev1.ConditionStatus { return c.Status },
+	)
+
+	// the Job is only complete when it has completed on every SyncTarget, including those which have
+	// not reported any status yet.
+	complete := len(statuses) > 0
+	for _, status := range statuses {
+		if !jobConditionTrue(status.Conditions, batchv1.JobComplete) {
+			complete = false
+			break
+		}
+	}
+	if !complete {
+		summarizedStatus.CompletionTime = nil
+		var remaining []batchv1.JobCondition
+		for _, condition := range summarizedStatus.Conditions {
+			if condition.Type != batchv1.JobComplete {
+				remaining = append(remaining, condition)
+			}
+		}
+		summarizedStatus.Conditions = remaining
+	}
+	return summarizedStatus
+}
+
+func jobConditionTrue(conditions []batchv1.JobCondition, conditionType batchv1.JobConditionType) bool {
+	for _, condition := range conditions {
+		if condition.Type == conditionType {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coordinator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSplitJob(t *testing.T) {
+	indexed := batchv1.IndexedCompletion
+
+	tests := map[string]struct {
+		spec           batchv1.JobSpec
+		annotations    map[string]string
+		syncTargetKeys []string
+		weights        map[string]int64
+		want           map[string]string
+	}{
+		"no completions": {
+			spec:           batchv1.JobSpec{Parallelism: intPtr(2)},
+			syncTargetKeys: []string{"a", "b"},
+		},
+		"indexed completions": {
+			spec:           batchv1.JobSpec{Completions: intPtr(4), CompletionMode: &indexed},
+			syncTargetKeys: []string{"a", "b"},
+		},
+		"even": {
+			spec:           batchv1.JobSpec{Completions: intPtr(5), Parallelism: intPtr(2)},
+			syncTargetKeys: []string{"a", "b"},
+			want: map[string]string{
+				"a": `[{ "op": "replace", "path": "/completions", "value": 3 }, { "op": "replace", "path": "/parallelism", "value": 1 }]`,
+				"b": `[{ "op": "replace", "path": "/completions", "value": 2 }, { "op": "replace", "path": "/parallelism", "value": 1 }]`,
+			},
+		},
+		"parallelism defaults to 1 and is at least 1 with completions": {
+			spec:           batchv1.JobSpec{Completions: intPtr(4)},
+			syncTargetKeys: []string{"a", "b"},
+			want: map[string]string{
+				"a": `[{ "op": "replace", "path": "/completions", "value": 2 }, { "op": "replace", "path": "/parallelism", "value": 1 }]`,
+				"b": `[{ "op": "replace", "path": "/completions", "value": 2 }, { "op": "replace", "path": "/parallelism", "value": 1 }]`,
+			},
+		},
+		"parallelism capped by completions": {
+			spec:           batchv1.JobSpec{Completions: intPtr(2), Parallelism: intPtr(10)},
+			syncTargetKeys: []string{"a", "b"},
+			weights:        map[string]int64{"a": 1, "b": 0},
+			want: map[string]string{
+				"a": `[{ "op": "replace", "path": "/completions", "value": 2 }, { "op": "replace", "path": "/parallelism", "value": 2 }]`,
+				"b": `[{ "op": "replace", "path": "/completions", "value": 0 }, { "op": "replace", "path": "/parallelism", "value": 0 }]`,
+			},
+		},
+		"suspended by zero parallelism": {
+			spec:           batchv1.JobSpec{Completions: intPtr(2), Parallelism: intPtr(0)},
+			syncTargetKeys: []string{"a", "b"},
+			want: map[string]string{
+				"a": `[{ "op": "replace", "path": "/completions", "value": 1 }, { "op": "replace", "path": "/parallelism", "value": 0 }]`,
+				"b": `[{ "op": "replace", "path": "/completions", "value": 1 }, { "op": "replace", "path": "/parallelism", "value": 0 }]`,
+			},
+		},
+		"already split, SyncTargets added and removed": {
+			spec: batchv1.JobSpec{Completions: intPtr(4)},
+			annotations: map[string]string{
+				"experimental.spec-diff.workload.kcp.dev/a": `[{ "op": "replace", "path": "/completions", "value": 2 }, { "op": "replace", "path": "/parallelism", "value": 1 }]`,
+				"experimental.spec-diff.workload.kcp.dev/b": `[{ "op": "replace", "path": "/completions", "value": 2 }, { "op": "replace", "path": "/parallelism", "value": 1 }]`,
+				"other": "value",
+			},
+			syncTargetKeys: []string{"a", "c"},
+			want: map[string]string{
+				"a": `[{ "op": "replace", "path": "/completions", "value": 2 }, { "op": "replace", "path": "/parallelism", "value": 1 }]`,
+				"c": `[{ "op": "replace", "path": "/completions", "value": 0 }, { "op": "replace", "path": "/parallelism", "value": 0 }]`,
+			},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := splitJob(&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}, Spec: tc.spec}, tc.syncTargetKeys, tc.weights)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestAggregateJobStatuses(t *testing.T) {
+	earlier := metav1.NewTime(time.Date(2022, 10, 1, 10, 0, 0, 0, time.UTC))
+	later := metav1.NewTime(earlier.Add(time.Hour))
+	complete := []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+
+	tests := map[string]struct {
+		statuses map[string]*batchv1.JobStatus
+		want     batchv1.JobStatus
+	}{
+		"running": {
+			statuses: map[string]*batchv1.JobStatus{
+				"a": {Active: 1, Succeeded: 2, Ready: intPtr(1), StartTime: &later},
+				"b": {Active: 2, Failed: 1, Ready: intPtr(2), StartTime: &earlier},
+			},
+			want: batchv1.JobStatus{Active: 3, Succeeded: 2, Failed: 1, Ready: intPtr(3), StartTime: &earlier},
+		},
+		"complete on some SyncTargets only": {
+			statuses: map[string]*batchv1.JobStatus{
+				"a": {Succeeded: 2, StartTime: &earlier, CompletionTime: &later, Conditions: complete},
+				"b": {Active: 1, StartTime: &earlier},
+			},
+			want: batchv1.JobStatus{Active: 1, Succeeded: 2, StartTime: &earlier},
+		},
+		"no status yet on a SyncTarget": {
+			statuses: map[string]*batchv1.JobStatus{
+				"a": {Succeeded: 2, StartTime: &earlier, CompletionTime: &later, Conditions: complete},
+				"b": {},
+			},
+			want: batchv1.JobStatus{Succeeded: 2, StartTime: &earlier},
+		},
+		"complete on every SyncTarget": {
+			statuses: map[string]*batchv1.JobStatus{
+				"a": {Succeeded: 2, StartTime: &earlier, CompletionTime: &later, Conditions: complete},
+				"b": {Succeeded: 1, StartTime: &earlier, CompletionTime: &earlier, Conditions: complete},
+			},
+			want: batchv1.JobStatus{Succeeded: 3, StartTime: &earlier, CompletionTime: &later, Conditions: complete},
+		},
+		"failed on one SyncTarget": {
+			statuses: map[string]*batchv1.JobStatus{
+				"a": {Failed: 3, Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}}},
+				"b": {Succeeded: 1, Conditions: complete},
+			},
+			want: batchv1.JobStatus{Failed: 3, Succeeded: 1, Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}}},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.want, aggregateJobStatuses(tc.statuses))
+		})
+	}
+}
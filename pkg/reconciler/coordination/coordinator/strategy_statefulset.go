@@ -0,0 +1,88 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coordinator
+
+import (
+	kubernetesinformers "github.com/kcp-dev/client-go/informers"
+	kubernetesclient "github.com/kcp-dev/client-go/kubernetes"
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/kcp-dev/kcp/pkg/reconciler/committer"
+)
+
+// StatefulSetStrategy divides the replicas of StatefulSets across the SyncTargets, and sums up the
+// replica counts of their statuses. The revisions are kept if they are the same on every SyncTarget.
+var StatefulSetStrategy = Strategy[*appsv1.StatefulSet, appsv1.StatefulSetSpec, appsv1.StatefulSetStatus]{
+	GVR:        appsv1.SchemeGroupVersion.WithResource("statefulsets"),
+	ObjectMeta: func(statefulSet *appsv1.StatefulSet) *metav1.ObjectMeta { return &statefulSet.ObjectMeta },
+	Status:     func(statefulSet *appsv1.StatefulSet) *appsv1.StatefulSetStatus { return &statefulSet.Status },
+	Split: SplitReplicas("/replicas", func(statefulSet *appsv1.StatefulSet) *int32 {
+		return statefulSet.Spec.Replicas
+	}),
+	SplitInputsEqual: func(old, new *appsv1.StatefulSet) bool {
+		return int32PtrEqual(old.Spec.Replicas, new.Spec.Replicas)
+	},
+	Aggregate: aggregateStatefulSetStatuses,
+}
+
+var statefulSetRegistration = &TypedRegistration[*appsv1.StatefulSet, appsv1.StatefulSetSpec, appsv1.StatefulSetStatus]{
+	Strategy: StatefulSetStrategy,
+	Informer: func(f kubernetesinformers.SharedInformerFactory) (cache.SharedIndexInformer, func(logicalcluster.Name, string, string) (*appsv1.StatefulSet, error)) {
+		informer := f.Apps().V1().StatefulSets()
+		return informer.Informer(), func(clusterName logicalcluster.Name, namespace, name string) (*appsv1.StatefulSet, error) {
+			return informer.Lister().Cluster(clusterName).StatefulSets(namespace).Get(name)
+		}
+	},
+	Patcher: func(kubeClusterClient kubernetesclient.ClusterInterface, clusterName logicalcluster.Name, namespace string) committer.Patcher[*appsv1.StatefulSet] {
+		return kubeClusterClient.AppsV1().StatefulSets().Cluster(clusterName).Namespace(namespace)
+	},
+}
+
+func aggregateStatefulSetStatuses(statuses map[string]*appsv1.StatefulSetStatus) appsv1.StatefulSetStatus {
+	summarizedStatus := appsv1.StatefulSetStatus{}
+	var conditions [][]appsv1.StatefulSetCondition
+	for i, status := range nonEmpty(statuses) {
+		summarizedStatus.Replicas += status.Replicas
+		summarizedStatus.ReadyReplicas += status.ReadyReplicas
+		summarizedStatus.CurrentReplicas += status.CurrentReplicas
+		summarizedStatus.UpdatedReplicas += status.UpdatedReplicas
+		summarizedStatus.AvailableReplicas += status.AvailableReplicas
+		conditions = append(conditions, status.Conditions)
+
+		if i == 0 {
+			summarizedStatus.CurrentRevision = status.CurrentRevision
+			summarizedStatus.UpdateRevision = status.UpdateRevision
+		}
+		if summarizedStatus.CurrentRevision != status.CurrentRevision {
+			summarizedStatus.CurrentRevision = ""
+		}
+		if summarizedStatus.UpdateRevision != status.UpdateRevision {
+			summarizedStatus.UpdateRevision = ""
+		}
+	}
+
+	summarizedStatus.Conditions = consolidateConditions(conditions,
+		func(c appsv1.StatefulSetCondition) string { return string(c.Type) },
+		func(c appsv1.StatefulSetCondition) corev1.ConditionStatus { return c.Status },
+	)
+	return summarizedStatus
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coordinator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestAggregateStatefulSetStatuses(t *testing.T) {
+	tests := map[string]struct {
+		statuses map[string]*appsv1.StatefulSetStatus
+		want     appsv1.StatefulSetStatus
+	}{
+		"same revisions": {
+			statuses: map[string]*appsv1.StatefulSetStatus{
+				"a": {Replicas: 2, ReadyReplicas: 2, CurrentReplicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2, CurrentRevision: "r1", UpdateRevision: "r1"},
+				"b": {Replicas: 1, ReadyReplicas: 0, CurrentReplicas: 1, UpdatedReplicas: 1, AvailableReplicas: 0, CurrentRevision: "r1", UpdateRevision: "r1"},
+				"c": {},
+			},
+			want: appsv1.StatefulSetStatus{Replicas: 3, ReadyReplicas: 2, CurrentReplicas: 3, UpdatedReplicas: 3, AvailableReplicas: 2, CurrentRevision: "r1", UpdateRevision: "r1"},
+		},
+		"rolling out on one SyncTarget": {
+			statuses: map[string]*appsv1.StatefulSetStatus{
+				"a": {Replicas: 2, CurrentRevision: "r1", UpdateRevision: "r2"},
+				"b": {Replicas: 2, CurrentRevision: "r1", UpdateRevision: "r1"},
+			},
+			want: appsv1.StatefulSetStatus{Replicas: 4, CurrentRevision: "r1"},
+		},
+		"conditions": {
+			statuses: map[string]*appsv1.StatefulSetStatus{
+				"a": {Replicas: 1, Conditions: []appsv1.StatefulSetCondition{{Type: "B", Status: corev1.ConditionFalse}, {Type: "A", Status: corev1.ConditionUnknown}}},
+				"b": {Replicas: 1, Conditions: []appsv1.StatefulSetCondition{{Type: "A", Status: corev1.ConditionFalse}, {Type: "B", Status: corev1.ConditionTrue}}},
+			},
+			want: appsv1.StatefulSetStatus{Replicas: 2, Conditions: []appsv1.StatefulSetCondition{{Type: "A", Status: corev1.ConditionFalse}, {Type: "B", Status: corev1.ConditionTrue}}},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.want, aggregateStatefulSetStatuses(tc.statuses))
+		})
+	}
+}
@@ -93,6 +93,7 @@ func NewController(
 			oldCluster = oldCluster.DeepCopy()
 			oldCluster.Status.Allocatable = objCluster.Status.Allocatable
 			oldCluster.Status.Capacity = objCluster.Status.Capacity
+			oldCluster.Status.Requested = objCluster.Status.Requested
 			oldCluster.Status.LastSyncerHeartbeatTime = objCluster.Status.LastSyncerHeartbeatTime
 
 			if !equality.Semantic.DeepEqual(oldCluster, objCluster) {
@@ -25,6 +25,7 @@ import (
 	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/google/go-cmp/cmp"
 	kcpcache "github.com/kcp-dev/apimachinery/pkg/cache"
+	kcpkubernetesclientset "github.com/kcp-dev/client-go/kubernetes"
 	"github.com/kcp-dev/logicalcluster/v2"
 
 	"k8s.io/apimachinery/pkg/api/equality"
@@ -46,6 +47,7 @@ import (
 	apisv1alpha1listers "github.com/kcp-dev/kcp/pkg/client/listers/apis/v1alpha1"
 	tenancyv1alpha1listers "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
 	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/migration"
 )
 
 const (
@@ -53,21 +55,35 @@ const (
 )
 
 func NewController(
+	kubeClusterClient kcpkubernetesclientset.ClusterInterface,
 	kcpClusterClient kcpclientset.ClusterInterface,
 	workspaceInformer tenancyv1alpha1informers.ClusterWorkspaceClusterInformer,
 	clusterWorkspaceShardInformer tenancyv1alpha1informers.ClusterWorkspaceShardClusterInformer,
 	apiBindingsInformer apisv1alpha1informers.APIBindingClusterInformer,
+	boundAPIBindingsInformer apisv1alpha1informers.APIBindingClusterInformer,
+	cachedWorkspaceInformer tenancyv1alpha1informers.ClusterWorkspaceClusterInformer,
+	migrationClient *migration.Client,
+	schedulingStrategy SchedulingStrategy,
+	quotaMeasureInterval time.Duration,
 ) (*Controller, error) {
 	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), ControllerName)
 
 	c := &Controller{
 		queue:                        queue,
+		kubeClusterClient:            kubeClusterClient,
 		kcpClusterClient:             kcpClusterClient,
 		workspaceIndexer:             workspaceInformer.Informer().GetIndexer(),
 		workspaceLister:              workspaceInformer.Lister(),
 		clusterWorkspaceShardIndexer: clusterWorkspaceShardInformer.Informer().GetIndexer(),
 		clusterWorkspaceShardLister:  clusterWorkspaceShardInformer.Lister(),
 		apiBindingLister:             apiBindingsInformer.Lister(),
+		boundAPIBindingLister:        boundAPIBindingsInformer.Lister(),
+		migrationClient:              migrationClient,
+		schedulingStrategy:           schedulingStrategy,
+		quotaMeasureInterval:         quotaMeasureInterval,
+	}
+	if cachedWorkspaceInformer != nil {
+		c.cachedWorkspaceLister = cachedWorkspaceInformer.Lister()
 	}
 
 	if err := c.workspaceIndexer.AddIndexers(map[string]cache.IndexFunc{
@@ -103,14 +119,24 @@ func NewController(
 type Controller struct {
 	queue workqueue.RateLimitingInterface
 
-	kcpClusterClient kcpclientset.ClusterInterface
-	workspaceIndexer cache.Indexer
-	workspaceLister  tenancyv1alpha1listers.ClusterWorkspaceClusterLister
+	kubeClusterClient kcpkubernetesclientset.ClusterInterface
+	kcpClusterClient  kcpclientset.ClusterInterface
+	workspaceIndexer  cache.Indexer
+	workspaceLister   tenancyv1alpha1listers.ClusterWorkspaceClusterLister
+	// cachedWorkspaceLister lists the ClusterWorkspaces replicated to the cache server. It is nil
+	// if the cache server is disabled.
+	cachedWorkspaceLister tenancyv1alpha1listers.ClusterWorkspaceClusterLister
 
 	clusterWorkspaceShardIndexer cache.Indexer
 	clusterWorkspaceShardLister  tenancyv1alpha1listers.ClusterWorkspaceShardClusterLister
 
 	apiBindingLister apisv1alpha1listers.APIBindingClusterLister
+	// boundAPIBindingLister lists the APIBindings of all shards when the cache server is enabled.
+	boundAPIBindingLister apisv1alpha1listers.APIBindingClusterLister
+
+	migrationClient      *migration.Client
+	schedulingStrategy   SchedulingStrategy
+	quotaMeasureInterval time.Duration
 }
 
 func (c *Controller) enqueue(obj interface{}) {
@@ -341,6 +367,8 @@ func (c *Controller) process(ctx context.Context, key string) (bool, error) {
 	// If the object being reconciled changed as a result, update it.
 	if err := c.patchIfNeeded(ctx, old, obj); err != nil {
 		errs = append(errs, err)
+	} else {
+		c.recordHistoryEvents(ctx, old, obj)
 	}
 
 	return requeue, utilerrors.NewAggregate(errs)
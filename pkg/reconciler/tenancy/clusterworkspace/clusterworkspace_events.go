@@ -0,0 +1,101 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterworkspace
+
+import (
+	"context"
+	"fmt"
+
+	kcpkubernetesclientset "github.com/kcp-dev/client-go/kubernetes"
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+// recordHistoryEvents emits an Event into the parent workspace for every entry that has been
+// added to the lifecycle history of the workspace by the reconcilers.
+func (c *Controller) recordHistoryEvents(ctx context.Context, old, obj *tenancyv1alpha1.ClusterWorkspace) {
+	RecordHistoryEvents(ctx, c.kubeClusterClient, ControllerName, old, obj)
+}
+
+// RecordHistoryEvents emits an Event into the parent workspace for every entry that has been
+// added to the lifecycle history of the workspace between old and obj, such that tenants can
+// follow the lifecycle of their workspaces without access to the shards. Events are best effort,
+// failures are only logged.
+func RecordHistoryEvents(ctx context.Context, kubeClusterClient kcpkubernetesclientset.ClusterInterface, component string, old, obj *tenancyv1alpha1.ClusterWorkspace) {
+	for _, entry := range newHistoryEntries(old.Status.History, obj.Status.History) {
+		RecordEvent(ctx, kubeClusterClient, component, obj, entry)
+	}
+}
+
+// RecordEvent emits an Event for a lifecycle transition of the workspace into the parent
+// workspace. It is used directly for transitions that cannot be added to the history because
+// the workspace is gone afterwards.
+func RecordEvent(ctx context.Context, kubeClusterClient kcpkubernetesclientset.ClusterInterface, component string, workspace *tenancyv1alpha1.ClusterWorkspace, entry tenancyv1alpha1.ClusterWorkspaceHistoryEntry) {
+	event := historyEvent(workspace, entry, component)
+	if _, err := kubeClusterClient.Cluster(logicalcluster.From(workspace)).CoreV1().Events(event.Namespace).Create(ctx, event, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+		klog.FromContext(ctx).Error(err, "failed to create Event for ClusterWorkspace", "reason", entry.Reason)
+	}
+}
+
+// newHistoryEntries returns the entries of current that are not in previous. Old
+// entries might have been dropped from the front of current.
+func newHistoryEntries(previous, current []tenancyv1alpha1.ClusterWorkspaceHistoryEntry) []tenancyv1alpha1.ClusterWorkspaceHistoryEntry {
+	if len(previous) == 0 {
+		return current
+	}
+	last := previous[len(previous)-1]
+	for i := len(current) - 1; i >= 0; i-- {
+		if equality.Semantic.DeepEqual(current[i], last) {
+			return current[i+1:]
+		}
+	}
+	return current
+}
+
+// historyEvent builds an Event for a history entry of the workspace. ClusterWorkspaces are
+// cluster-scoped, hence the Event goes into the default namespace like for other
+// cluster-scoped objects.
+func historyEvent(workspace *tenancyv1alpha1.ClusterWorkspace, entry tenancyv1alpha1.ClusterWorkspaceHistoryEntry, component string) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", workspace.Name, entry.Time.UnixNano()),
+			Namespace: metav1.NamespaceDefault,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion:      tenancyv1alpha1.SchemeGroupVersion.String(),
+			Kind:            "ClusterWorkspace",
+			Name:            workspace.Name,
+			UID:             workspace.UID,
+			ResourceVersion: workspace.ResourceVersion,
+		},
+		Reason:              entry.Reason,
+		Message:             entry.Message,
+		Type:                corev1.EventTypeNormal,
+		FirstTimestamp:      entry.Time,
+		LastTimestamp:       entry.Time,
+		Count:               1,
+		Source:              corev1.EventSource{Component: component},
+		ReportingController: component,
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterworkspace
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+func TestNewHistoryEntries(t *testing.T) {
+	now := time.Date(2022, 12, 1, 0, 0, 0, 0, time.UTC)
+	entry := func(offset time.Duration, reason string) tenancyv1alpha1.ClusterWorkspaceHistoryEntry {
+		return tenancyv1alpha1.ClusterWorkspaceHistoryEntry{Time: metav1.NewTime(now.Add(offset)), Reason: reason}
+	}
+	created, scheduled, initialized := entry(0, "Created"), entry(time.Second, "Scheduled"), entry(2*time.Second, "Initialized")
+
+	tests := map[string]struct {
+		previous, current []tenancyv1alpha1.ClusterWorkspaceHistoryEntry
+		want              []tenancyv1alpha1.ClusterWorkspaceHistoryEntry
+	}{
+		"no history": {},
+		"first entry": {
+			current: []tenancyv1alpha1.ClusterWorkspaceHistoryEntry{created},
+			want:    []tenancyv1alpha1.ClusterWorkspaceHistoryEntry{created},
+		},
+		"unchanged": {
+			previous: []tenancyv1alpha1.ClusterWorkspaceHistoryEntry{created, scheduled},
+			current:  []tenancyv1alpha1.ClusterWorkspaceHistoryEntry{created, scheduled},
+			want:     []tenancyv1alpha1.ClusterWorkspaceHistoryEntry{},
+		},
+		"appended": {
+			previous: []tenancyv1alpha1.ClusterWorkspaceHistoryEntry{created},
+			current:  []tenancyv1alpha1.ClusterWorkspaceHistoryEntry{created, scheduled, initialized},
+			want:     []tenancyv1alpha1.ClusterWorkspaceHistoryEntry{scheduled, initialized},
+		},
+		"appended and trimmed": {
+			previous: []tenancyv1alpha1.ClusterWorkspaceHistoryEntry{created, scheduled},
+			current:  []tenancyv1alpha1.ClusterWorkspaceHistoryEntry{scheduled, initialized},
+			want:     []tenancyv1alpha1.ClusterWorkspaceHistoryEntry{initialized},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.want, newHistoryEntries(tc.previous, tc.current))
+		})
+	}
+}
+
+func TestHistoryEvent(t *testing.T) {
+	ws := workspace()
+	ws.UID = "uid"
+	entry := tenancyv1alpha1.ClusterWorkspaceHistoryEntry{
+		Time:    metav1.NewTime(time.Unix(0, 255)),
+		Phase:   tenancyv1alpha1.ClusterWorkspacePhaseReady,
+		Reason:  "Initialized",
+		Message: `Initialized on ClusterWorkspaceShard "alpha".`,
+	}
+
+	event := historyEvent(ws, entry, ControllerName)
+	require.Equal(t, "workspace.ff", event.Name)
+	require.Equal(t, metav1.NamespaceDefault, event.Namespace)
+	require.Equal(t, "ClusterWorkspace", event.InvolvedObject.Kind)
+	require.Equal(t, ws.UID, event.InvolvedObject.UID)
+	require.Equal(t, entry.Reason, event.Reason)
+	require.Equal(t, entry.Message, event.Message)
+	require.Equal(t, ControllerName, event.ReportingController)
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterworkspace
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+func DefaultOptions() *Options {
+	return &Options{
+		SchedulingStrategy:   string(LeastLoadedSchedulingStrategy),
+		QuotaMeasureInterval: time.Minute,
+	}
+}
+
+func BindOptions(o *Options, fs *pflag.FlagSet) *Options {
+	fs.StringVar(&o.SchedulingStrategy, "workspace-scheduling-strategy", o.SchedulingStrategy, fmt.Sprintf("Strategy used to pick a ClusterWorkspaceShard for new workspaces among the shards matching their constraints. One of: %s, %s, %s.", LeastLoadedSchedulingStrategy, BinPackingSchedulingStrategy, RandomSchedulingStrategy))
+	fs.DurationVar(&o.QuotaMeasureInterval, "workspace-quota-measure-interval", o.QuotaMeasureInterval, "Interval in which the usage of workspaces with a quota is updated in their status. The usage is measured by the shards storing the workspaces every --shard-usage-report-interval.")
+	return o
+}
+
+type Options struct {
+	SchedulingStrategy   string
+	QuotaMeasureInterval time.Duration
+}
+
+func (o *Options) Validate() error {
+	if _, err := NewSchedulingStrategy(SchedulingStrategyType(o.SchedulingStrategy)); err != nil {
+		return fmt.Errorf("--workspace-scheduling-strategy: %w", err)
+	}
+	if o.QuotaMeasureInterval <= 0 {
+		return fmt.Errorf("--workspace-quota-measure-interval must be >0 (%s)", o.QuotaMeasureInterval)
+	}
+	return nil
+}
@@ -18,6 +18,7 @@ package clusterworkspace
 
 import (
 	"context"
+	"time"
 
 	"github.com/kcp-dev/logicalcluster/v2"
 
@@ -27,6 +28,7 @@ import (
 
 	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/client"
 )
 
 type reconcileStatus int
@@ -41,13 +43,84 @@ type reconciler interface {
 }
 
 func (c *Controller) reconcile(ctx context.Context, ws *tenancyv1alpha1.ClusterWorkspace) (bool, error) {
+	var getReplicatedWorkspace func(clusterName logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspace, error)
+	if c.cachedWorkspaceLister != nil {
+		getReplicatedWorkspace = func(clusterName logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspace, error) {
+			return c.cachedWorkspaceLister.Cluster(clusterName).Get(name)
+		}
+	}
+
 	reconcilers := []reconciler{
 		&metaDataReconciler{},
+		&softDeletionReconciler{
+			deleteWorkspace: func(ctx context.Context, workspace *tenancyv1alpha1.ClusterWorkspace) error {
+				// the preconditions make sure the workspace has not been undeleted in the meantime
+				return c.kcpClusterClient.Cluster(logicalcluster.From(workspace)).TenancyV1alpha1().ClusterWorkspaces().Delete(ctx, workspace.Name, metav1.DeleteOptions{
+					Preconditions: &metav1.Preconditions{UID: &workspace.UID, ResourceVersion: &workspace.ResourceVersion},
+				})
+			},
+			requeueAfter: func(workspace *tenancyv1alpha1.ClusterWorkspace, duration time.Duration) {
+				c.queue.AddAfter(client.ToClusterAwareKey(logicalcluster.From(workspace), workspace.Name), duration)
+			},
+			now: time.Now,
+		},
+		&drainReconciler{
+			getShard: func(name string) (*tenancyv1alpha1.ClusterWorkspaceShard, error) {
+				return c.clusterWorkspaceShardLister.Cluster(tenancyv1alpha1.RootCluster).Get(name)
+			},
+			listShards: c.clusterWorkspaceShardLister.List,
+			strategy:   c.schedulingStrategy,
+			requeueAfter: func(workspace *tenancyv1alpha1.ClusterWorkspace, duration time.Duration) {
+				c.queue.AddAfter(client.ToClusterAwareKey(logicalcluster.From(workspace), workspace.Name), duration)
+			},
+			now: time.Now,
+		},
+		&migrationReconciler{
+			getShard: func(name string) (*tenancyv1alpha1.ClusterWorkspaceShard, error) {
+				return c.clusterWorkspaceShardLister.Cluster(tenancyv1alpha1.RootCluster).Get(name)
+			},
+			copyCluster:   c.migrationClient.Copy,
+			deleteCluster: c.migrationClient.Delete,
+			requeueAfter: func(workspace *tenancyv1alpha1.ClusterWorkspace, duration time.Duration) {
+				c.queue.AddAfter(client.ToClusterAwareKey(logicalcluster.From(workspace), workspace.Name), duration)
+			},
+			now:                    time.Now,
+			getReplicatedWorkspace: getReplicatedWorkspace,
+		},
+		&moveReconciler{
+			getWorkspace: func(cluster logicalcluster.Name) (*tenancyv1alpha1.ClusterWorkspace, error) {
+				parent, name := cluster.Split()
+				return c.workspaceLister.Cluster(parent).Get(name)
+			},
+			listShards:      c.clusterWorkspaceShardLister.List,
+			listAPIBindings: c.boundAPIBindingLister.List,
+			renameCluster:   c.migrationClient.Rename,
+			requeueAfter: func(workspace *tenancyv1alpha1.ClusterWorkspace, duration time.Duration) {
+				c.queue.AddAfter(client.ToClusterAwareKey(logicalcluster.From(workspace), workspace.Name), duration)
+			},
+			now: time.Now,
+		},
 		&schedulingReconciler{
 			getShard: func(name string) (*tenancyv1alpha1.ClusterWorkspaceShard, error) {
 				return c.clusterWorkspaceShardLister.Cluster(tenancyv1alpha1.RootCluster).Get(name)
 			},
 			listShards: c.clusterWorkspaceShardLister.List,
+			strategy:   c.schedulingStrategy,
+		},
+		&quotaReconciler{
+			getWorkspace: func(cluster logicalcluster.Name) (*tenancyv1alpha1.ClusterWorkspace, error) {
+				parent, name := cluster.Split()
+				return c.workspaceLister.Cluster(parent).Get(name)
+			},
+			getShard: func(name string) (*tenancyv1alpha1.ClusterWorkspaceShard, error) {
+				return c.clusterWorkspaceShardLister.Cluster(tenancyv1alpha1.RootCluster).Get(name)
+			},
+			measureCluster:  c.migrationClient.Measure,
+			measureInterval: c.quotaMeasureInterval,
+			requeueAfter: func(workspace *tenancyv1alpha1.ClusterWorkspace, duration time.Duration) {
+				c.queue.AddAfter(client.ToClusterAwareKey(logicalcluster.From(workspace), workspace.Name), duration)
+			},
+			now: time.Now,
 		},
 		&phaseReconciler{
 			getShardWithQuorum: func(ctx context.Context, name string, options metav1.GetOptions) (*tenancyv1alpha1.ClusterWorkspaceShard, error) {
@@ -56,6 +129,7 @@ func (c *Controller) reconcile(ctx context.Context, ws *tenancyv1alpha1.ClusterW
 			getAPIBindings: func(clusterName logicalcluster.Name) ([]*apisv1alpha1.APIBinding, error) {
 				return c.apiBindingLister.Cluster(clusterName).List(labels.Everything())
 			},
+			now: time.Now,
 		},
 	}
 
@@ -0,0 +1,114 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterworkspace
+
+import (
+	"context"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+)
+
+// drainRetryPeriod is the time after which a workspace on a drained shard is retried
+// when no other shard is available to migrate it to.
+const drainRetryPeriod = 30 * time.Second
+
+// drainReconciler evicts workspaces from shards with spec.evictAfter in the past by
+// setting status.location.target to another shard chosen by the scheduling strategy.
+// The migrationReconciler does the actual move.
+type drainReconciler struct {
+	getShard     func(name string) (*tenancyv1alpha1.ClusterWorkspaceShard, error)
+	listShards   func(selector labels.Selector) ([]*tenancyv1alpha1.ClusterWorkspaceShard, error)
+	strategy     SchedulingStrategy
+	requeueAfter func(workspace *tenancyv1alpha1.ClusterWorkspace, duration time.Duration)
+	now          func() time.Time
+}
+
+func (r *drainReconciler) reconcile(ctx context.Context, workspace *tenancyv1alpha1.ClusterWorkspace) (reconcileStatus, error) {
+	switch workspace.Status.Phase {
+	case tenancyv1alpha1.ClusterWorkspacePhaseInitializing, tenancyv1alpha1.ClusterWorkspacePhaseReady:
+	default:
+		return reconcileStatusContinue, nil
+	}
+
+	location := &workspace.Status.Location
+	if !workspace.DeletionTimestamp.IsZero() || location.Current == "" || location.Target != "" || location.Migration != nil {
+		return reconcileStatusContinue, nil
+	}
+
+	shard, err := r.getShard(location.Current)
+	if apierrors.IsNotFound(err) {
+		return reconcileStatusContinue, nil
+	} else if err != nil {
+		return reconcileStatusStopAndRequeue, err
+	}
+	if shard.Spec.EvictAfter == nil {
+		return reconcileStatusContinue, nil
+	}
+	if remaining := shard.Spec.EvictAfter.Sub(r.now()); remaining > 0 {
+		r.requeueAfter(workspace, remaining)
+		return reconcileStatusContinue, nil
+	}
+
+	logger := logging.WithObject(klog.FromContext(ctx), shard)
+
+	selector := labels.Everything()
+	if workspace.Spec.Shard != nil && workspace.Spec.Shard.Selector != nil {
+		selector, err = metav1.LabelSelectorAsSelector(workspace.Spec.Shard.Selector)
+		if err != nil {
+			logger.Info("cannot drain workspace with invalid spec.shard.selector", "err", err)
+			return reconcileStatusContinue, nil // don't retry, cannot do anything useful
+		}
+	}
+	shards, err := r.listShards(selector)
+	if err != nil {
+		return reconcileStatusStopAndRequeue, err
+	}
+
+	candidates := make([]*tenancyv1alpha1.ClusterWorkspaceShard, 0, len(shards))
+	for _, candidate := range shards {
+		if candidate.Name == shard.Name {
+			continue
+		}
+		if workspace.Spec.Shard != nil && workspace.Spec.Shard.Name != "" && workspace.Spec.Shard.Name != candidate.Name {
+			continue
+		}
+		if valid, _, _ := isValidShard(candidate); !valid {
+			continue
+		}
+		if full, _ := isFullShard(candidate); full {
+			continue
+		}
+		candidates = append(candidates, candidate)
+	}
+	if len(candidates) == 0 {
+		logger.Info("no shard available to drain workspace to, retrying later")
+		r.requeueAfter(workspace, drainRetryPeriod)
+		return reconcileStatusContinue, nil
+	}
+
+	location.Target = r.strategy.Choose(candidates).Name
+	logger.Info("draining workspace from shard", "target", location.Target)
+
+	return reconcileStatusContinue, nil
+}
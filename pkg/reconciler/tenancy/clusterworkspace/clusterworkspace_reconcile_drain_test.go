@@ -0,0 +1,150 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterworkspace
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+func TestDrainReconciler(t *testing.T) {
+	now := time.Date(2022, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		workspace *tenancyv1alpha1.ClusterWorkspace
+		shards    []*tenancyv1alpha1.ClusterWorkspaceShard
+
+		wantTarget  string
+		wantRequeue time.Duration
+	}{
+		"shard not drained": {
+			workspace: phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace())),
+			shards: []*tenancyv1alpha1.ClusterWorkspaceShard{
+				cordoned(shard("alpha")),
+				shard("beta"),
+			},
+		},
+		"drained shard": {
+			workspace: phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace())),
+			shards: []*tenancyv1alpha1.ClusterWorkspaceShard{
+				drained(now, shard("alpha")),
+				cordoned(shard("beta")),
+				shard("gamma"),
+			},
+			wantTarget: "gamma",
+		},
+		"drain in the future": {
+			workspace: phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace())),
+			shards: []*tenancyv1alpha1.ClusterWorkspaceShard{
+				drained(now.Add(time.Hour), shard("alpha")),
+				shard("beta"),
+			},
+			wantRequeue: time.Hour,
+		},
+		"drain honours the shard selector": {
+			workspace: constrained(tenancyv1alpha1.ShardConstraints{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"a": "1"}}},
+				phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace()))),
+			shards: []*tenancyv1alpha1.ClusterWorkspaceShard{
+				withLabels(map[string]string{"a": "1"}, drained(now, shard("alpha"))),
+				shard("beta"),
+				withLabels(map[string]string{"a": "1"}, shard("gamma")),
+			},
+			wantTarget: "gamma",
+		},
+		"no shard to drain to": {
+			workspace: phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace())),
+			shards: []*tenancyv1alpha1.ClusterWorkspaceShard{
+				drained(now, shard("alpha")),
+				withUsage("10", "10", shard("beta")),
+			},
+			wantRequeue: drainRetryPeriod,
+		},
+		"migration already in progress": {
+			workspace: withTarget("beta", phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace()))),
+			shards: []*tenancyv1alpha1.ClusterWorkspaceShard{
+				drained(now, shard("alpha")),
+				shard("beta"),
+				shard("gamma"),
+			},
+			wantTarget: "beta",
+		},
+		"not drained while scheduling": {
+			workspace: phase(tenancyv1alpha1.ClusterWorkspacePhaseScheduling, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace())),
+			shards: []*tenancyv1alpha1.ClusterWorkspaceShard{
+				drained(now, shard("alpha")),
+				shard("beta"),
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var requeue time.Duration
+			r := &drainReconciler{
+				getShard: func(name string) (*tenancyv1alpha1.ClusterWorkspaceShard, error) {
+					for _, shard := range tc.shards {
+						if shard.Name == name {
+							return shard, nil
+						}
+					}
+					return nil, apierrors.NewNotFound(tenancyv1alpha1.Resource("clusterworkspaceshard"), name)
+				},
+				listShards: func(selector labels.Selector) ([]*tenancyv1alpha1.ClusterWorkspaceShard, error) {
+					var shards []*tenancyv1alpha1.ClusterWorkspaceShard
+					for _, shard := range tc.shards {
+						if selector.Matches(labels.Set(shard.Labels)) {
+							shards = append(shards, shard)
+						}
+					}
+					return shards, nil
+				},
+				strategy: newScoringStrategy(func(load float64) float64 { return -load }, func(int) int { return 0 }),
+				requeueAfter: func(_ *tenancyv1alpha1.ClusterWorkspace, duration time.Duration) {
+					requeue = duration
+				},
+				now: func() time.Time { return now },
+			}
+
+			ws := tc.workspace.DeepCopy()
+			status, err := r.reconcile(context.Background(), ws)
+			require.NoError(t, err)
+			require.Equal(t, reconcileStatusContinue, status)
+			require.Equal(t, tc.wantTarget, ws.Status.Location.Target)
+			require.Equal(t, tc.wantRequeue, requeue)
+		})
+	}
+}
+
+func cordoned(shard *tenancyv1alpha1.ClusterWorkspaceShard) *tenancyv1alpha1.ClusterWorkspaceShard {
+	shard.Spec.Unschedulable = true
+	return shard
+}
+
+func drained(evictAfter time.Time, shard *tenancyv1alpha1.ClusterWorkspaceShard) *tenancyv1alpha1.ClusterWorkspaceShard {
+	shard.Spec.Unschedulable = true
+	shard.Spec.EvictAfter = &metav1.Time{Time: evictAfter}
+	return shard
+}
@@ -25,6 +25,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 
+	"github.com/kcp-dev/kcp/pkg/apis/tenancy/finalization"
 	"github.com/kcp-dev/kcp/pkg/apis/tenancy/initialization"
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
 )
@@ -62,6 +63,29 @@ func (r *metaDataReconciler) reconcile(ctx context.Context, workspace *tenancyv1
 		}
 	}
 
+	// finalizer labels only exist while the workspace is terminating, such that finalizing
+	// controllers only see the workspaces they have to act upon.
+	finalizerKeys := sets.NewString()
+	if !workspace.DeletionTimestamp.IsZero() {
+		for _, finalizer := range workspace.Status.Finalizers {
+			key, value := finalization.FinalizerToLabel(finalizer)
+			finalizerKeys.Insert(key)
+			if got, expected := workspace.Labels[key], value; got != expected {
+				workspace.Labels[key] = value
+				changed = true
+			}
+		}
+	}
+
+	for key := range workspace.Labels {
+		if strings.HasPrefix(key, tenancyv1alpha1.ClusterWorkspaceFinalizerLabelPrefix) {
+			if !finalizerKeys.Has(key) {
+				delete(workspace.Labels, key)
+				changed = true
+			}
+		}
+	}
+
 	if workspace.Status.Phase == tenancyv1alpha1.ClusterWorkspacePhaseReady {
 		if value, found := workspace.Annotations[tenancyv1alpha1.ExperimentalClusterWorkspaceOwnerAnnotationKey]; found {
 			var info authenticationv1.UserInfo
@@ -29,6 +29,8 @@ import (
 )
 
 func TestReconcileMetadata(t *testing.T) {
+	deletionTimestamp := metav1.Now()
+
 	for _, testCase := range []struct {
 		name       string
 		input      *tenancyv1alpha1.ClusterWorkspace
@@ -135,6 +137,75 @@ func TestReconcileMetadata(t *testing.T) {
 			},
 			wantStatus: reconcileStatusContinue,
 		},
+		{
+			name: "adds finalizer labels when terminating",
+			input: &tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					DeletionTimestamp: &deletionTimestamp,
+					Labels: map[string]string{
+						"internal.kcp.dev/phase": "Ready",
+					},
+				},
+				Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase: tenancyv1alpha1.ClusterWorkspacePhaseReady,
+					Finalizers: []tenancyv1alpha1.ClusterWorkspaceFinalizer{
+						"pluto",
+					},
+				},
+			},
+			expected: metav1.ObjectMeta{
+				DeletionTimestamp: &deletionTimestamp,
+				Labels: map[string]string{
+					"internal.kcp.dev/phase": "Ready",
+					"finalizer.internal.kcp.dev/2eadcbf778956517ec99fd1c1c32a9b13cba": "2eadcbf778956517ec99fd1c1c32a9b13cbae759770fc37c341c7fe8",
+				},
+			},
+			wantStatus: reconcileStatusStopAndRequeue,
+		},
+		{
+			name: "does not add finalizer labels when not terminating",
+			input: &tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"internal.kcp.dev/phase": "Ready",
+					},
+				},
+				Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase: tenancyv1alpha1.ClusterWorkspacePhaseReady,
+					Finalizers: []tenancyv1alpha1.ClusterWorkspaceFinalizer{
+						"pluto",
+					},
+				},
+			},
+			expected: metav1.ObjectMeta{
+				Labels: map[string]string{
+					"internal.kcp.dev/phase": "Ready",
+				},
+			},
+			wantStatus: reconcileStatusContinue,
+		},
+		{
+			name: "removes labels of removed finalizers",
+			input: &tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					DeletionTimestamp: &deletionTimestamp,
+					Labels: map[string]string{
+						"internal.kcp.dev/phase": "Ready",
+						"finalizer.internal.kcp.dev/2eadcbf778956517ec99fd1c1c32a9b13cba": "2eadcbf778956517ec99fd1c1c32a9b13cbae759770fc37c341c7fe8",
+					},
+				},
+				Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase: tenancyv1alpha1.ClusterWorkspacePhaseReady,
+				},
+			},
+			expected: metav1.ObjectMeta{
+				DeletionTimestamp: &deletionTimestamp,
+				Labels: map[string]string{
+					"internal.kcp.dev/phase": "Ready",
+				},
+			},
+			wantStatus: reconcileStatusStopAndRequeue,
+		},
 		{
 			name: "removes everything but owner username when ready",
 			input: &tenancyv1alpha1.ClusterWorkspace{
@@ -0,0 +1,237 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterworkspace
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1/helper"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
+)
+
+// migrationFreezeGracePeriod is the time given to the shards to observe spec.readOnly, and to
+// in-flight requests to finish, before the data of a workspace is copied.
+const migrationFreezeGracePeriod = 5 * time.Second
+
+// migrationReconciler moves a workspace to the shard in status.location.target:
+//
+//  1. it records the migration in status.location.migration,
+//  2. it sets spec.readOnly to reject writes to the workspace, and waits until the cache
+//     server has observed it for the shards not storing the parent workspace,
+//  3. it copies the storage of the workspace to the target shard and switches
+//     status.location.current, which in turn switches the front-proxy,
+//  4. it deletes the storage on the source shard and restores spec.readOnly,
+//  5. it removes status.location.migration.
+//
+// Every step is a separate patch of either spec or status. A migration can be aborted by
+// clearing status.location.target before the data is copied.
+type migrationReconciler struct {
+	getShard      func(name string) (*tenancyv1alpha1.ClusterWorkspaceShard, error)
+	copyCluster   func(ctx context.Context, sourceURL, targetURL string, cluster logicalcluster.Name) (int64, error)
+	deleteCluster func(ctx context.Context, url string, cluster logicalcluster.Name) (int64, error)
+	requeueAfter  func(workspace *tenancyv1alpha1.ClusterWorkspace, duration time.Duration)
+	now           func() time.Time
+
+	// getReplicatedWorkspace returns the ClusterWorkspace as replicated to the cache server.
+	// It is nil if the cache server is disabled.
+	getReplicatedWorkspace func(clusterName logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspace, error)
+}
+
+func (r *migrationReconciler) reconcile(ctx context.Context, workspace *tenancyv1alpha1.ClusterWorkspace) (reconcileStatus, error) {
+	// migration can only happen after scheduling
+	switch workspace.Status.Phase {
+	case tenancyv1alpha1.ClusterWorkspacePhaseInitializing, tenancyv1alpha1.ClusterWorkspacePhaseReady:
+	default:
+		return reconcileStatusContinue, nil
+	}
+
+	logger := klog.FromContext(ctx)
+	location := &workspace.Status.Location
+	migration := location.Migration
+
+	if migration == nil {
+		if location.Target == "" {
+			return reconcileStatusContinue, nil
+		}
+		if location.Target == location.Current {
+			location.Target = ""
+			return reconcileStatusContinue, nil
+		}
+		// don't interfere with a move, both use spec.readOnly
+		if workspace.Status.Move != nil {
+			return reconcileStatusContinue, nil
+		}
+
+		if _, err := r.getShard(location.Target); apierrors.IsNotFound(err) {
+			conditions.MarkFalse(workspace, tenancyv1alpha1.WorkspaceMigrated, tenancyv1alpha1.WorkspaceMigratedReasonTargetShardNotFound, conditionsv1alpha1.ConditionSeverityError, "ClusterWorkspaceShard %q in status.location.target does not exist.", location.Target)
+			return reconcileStatusContinue, nil // retry is automatic when new shards show up
+		} else if err != nil {
+			return reconcileStatusStopAndRequeue, err
+		}
+
+		logger.Info("starting migration of workspace", "from", location.Current, "to", location.Target)
+		location.Migration = &tenancyv1alpha1.ClusterWorkspaceMigration{
+			Phase:       tenancyv1alpha1.ClusterWorkspaceMigrationPhaseFreezing,
+			Source:      location.Current,
+			StartTime:   metav1.NewTime(r.now()),
+			WasReadOnly: workspace.Spec.ReadOnly,
+		}
+		conditions.MarkFalse(workspace, tenancyv1alpha1.WorkspaceMigrated, tenancyv1alpha1.WorkspaceMigratedReasonMigrating, conditionsv1alpha1.ConditionSeverityInfo, "Migrating from ClusterWorkspaceShard %q to %q.", location.Current, location.Target)
+		return reconcileStatusStopAndRequeue, nil
+	}
+
+	switch migration.Phase {
+	case tenancyv1alpha1.ClusterWorkspaceMigrationPhaseFreezing:
+		if location.Target == "" || location.Target == location.Current {
+			logger.Info("aborting migration of workspace")
+			location.Target = ""
+			migration.Phase = tenancyv1alpha1.ClusterWorkspaceMigrationPhaseUnfreezing
+			conditions.Delete(workspace, tenancyv1alpha1.WorkspaceMigrated)
+			return reconcileStatusStopAndRequeue, nil
+		}
+
+		if !workspace.Spec.ReadOnly {
+			workspace.Spec.ReadOnly = true
+			return reconcileStatusStopAndRequeue, nil
+		}
+		if remaining := migration.StartTime.Add(migrationFreezeGracePeriod).Sub(r.now()); remaining > 0 {
+			r.requeueAfter(workspace, remaining)
+			return reconcileStatusContinue, nil
+		}
+		if r.getReplicatedWorkspace != nil {
+			replicated, err := r.getReplicatedWorkspace(logicalcluster.From(workspace), workspace.Name)
+			if err != nil && !apierrors.IsNotFound(err) {
+				return reconcileStatusStopAndRequeue, err
+			}
+			if err != nil || !replicated.Spec.ReadOnly {
+				logger.V(2).Info("waiting for the cache server to observe the read-only workspace")
+				r.requeueAfter(workspace, time.Second)
+				return reconcileStatusContinue, nil
+			}
+		}
+
+		target, err := r.getShard(location.Target)
+		if apierrors.IsNotFound(err) {
+			conditions.MarkFalse(workspace, tenancyv1alpha1.WorkspaceMigrated, tenancyv1alpha1.WorkspaceMigratedReasonTargetShardNotFound, conditionsv1alpha1.ConditionSeverityError, "ClusterWorkspaceShard %q in status.location.target does not exist.", location.Target)
+			return reconcileStatusContinue, nil // retry is automatic when new shards show up
+		} else if err != nil {
+			return reconcileStatusStopAndRequeue, err
+		}
+		source, err := r.getShard(migration.Source)
+		if apierrors.IsNotFound(err) {
+			// without the source shard, the data is lost anyway. Continue on the target.
+			logger.Info("source shard of migration does not exist, not copying any data", "ClusterWorkspaceShard", migration.Source)
+		} else if err != nil {
+			return reconcileStatusStopAndRequeue, err
+		} else {
+			cluster := logicalcluster.From(workspace).Join(workspace.Name)
+			n, err := r.copyCluster(ctx, source.Spec.BaseURL, target.Spec.BaseURL, cluster)
+			if err != nil {
+				conditions.MarkFalse(workspace, tenancyv1alpha1.WorkspaceMigrated, tenancyv1alpha1.WorkspaceMigratedReasonCopyFailed, conditionsv1alpha1.ConditionSeverityWarning, "Copying to ClusterWorkspaceShard %q failed: %v", target.Name, err)
+				return reconcileStatusStopAndRequeue, err
+			}
+			migration.CopiedKeys = n
+		}
+
+		baseURL, err := workspaceBaseURL(target, workspace)
+		if err != nil {
+			conditions.MarkFalse(workspace, tenancyv1alpha1.WorkspaceMigrated, tenancyv1alpha1.WorkspaceMigratedReasonCopyFailed, conditionsv1alpha1.ConditionSeverityError, "Invalid connection information on target ClusterWorkspaceShard: %v.", err)
+			return reconcileStatusStopAndRequeue, err
+		}
+
+		logger.Info("copied workspace to target shard", "ClusterWorkspaceShard", target.Name, "keys", migration.CopiedKeys)
+		workspace.Status.BaseURL = baseURL
+		location.Current = target.Name
+		location.Target = ""
+		migration.Phase = tenancyv1alpha1.ClusterWorkspaceMigrationPhaseUnfreezing
+		conditions.MarkFalse(workspace, tenancyv1alpha1.WorkspaceMigrated, tenancyv1alpha1.WorkspaceMigratedReasonMigrating, conditionsv1alpha1.ConditionSeverityInfo, "Removing the workspace from ClusterWorkspaceShard %q.", migration.Source)
+		return reconcileStatusStopAndRequeue, nil
+
+	case tenancyv1alpha1.ClusterWorkspaceMigrationPhaseUnfreezing:
+		// delete the source data while the workspace is still read-only, such that writes
+		// reaching the source shard through stale routes are rejected.
+		if workspace.Spec.ReadOnly != migration.WasReadOnly || migration.WasReadOnly {
+			if err := r.deleteSource(ctx, workspace); err != nil {
+				return reconcileStatusStopAndRequeue, err
+			}
+		}
+		if workspace.Spec.ReadOnly != migration.WasReadOnly {
+			workspace.Spec.ReadOnly = migration.WasReadOnly
+			return reconcileStatusStopAndRequeue, nil
+		}
+
+		logger.Info("finished migration of workspace", "duration", r.now().Sub(migration.StartTime.Time))
+		if migration.Source != location.Current {
+			conditions.MarkTrue(workspace, tenancyv1alpha1.WorkspaceMigrated)
+			helper.AppendHistory(&workspace.Status, tenancyv1alpha1.ClusterWorkspaceHistoryEntry{
+				Time:    metav1.NewTime(r.now()),
+				Phase:   workspace.Status.Phase,
+				Reason:  "Migrated",
+				Message: fmt.Sprintf("Migrated from ClusterWorkspaceShard %q to %q.", migration.Source, location.Current),
+			})
+		}
+		location.Migration = nil
+		return reconcileStatusStopAndRequeue, nil
+	}
+
+	return reconcileStatusContinue, nil
+}
+
+// deleteSource removes the storage of the workspace from the shard it was migrated from.
+// Nothing is deleted for aborted migrations.
+func (r *migrationReconciler) deleteSource(ctx context.Context, workspace *tenancyv1alpha1.ClusterWorkspace) error {
+	migration := workspace.Status.Location.Migration
+	if migration.Source == workspace.Status.Location.Current {
+		return nil
+	}
+
+	source, err := r.getShard(migration.Source)
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	n, err := r.deleteCluster(ctx, source.Spec.BaseURL, logicalcluster.From(workspace).Join(workspace.Name))
+	if err != nil {
+		return err
+	}
+	klog.FromContext(ctx).V(2).Info("deleted workspace from source shard", "ClusterWorkspaceShard", source.Name, "keys", n)
+	return nil
+}
+
+// workspaceBaseURL returns the URL of the workspace served through the external URL of the given shard.
+func workspaceBaseURL(shard *tenancyv1alpha1.ClusterWorkspaceShard, workspace *tenancyv1alpha1.ClusterWorkspace) (string, error) {
+	u, err := url.Parse(shard.Spec.ExternalURL)
+	if err != nil {
+		return "", err
+	}
+	u.Path = path.Join(u.Path, logicalcluster.From(workspace).Join(workspace.Name).Path())
+	return u.String(), nil
+}
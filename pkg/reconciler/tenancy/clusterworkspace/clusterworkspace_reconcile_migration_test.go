@@ -0,0 +1,225 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterworkspace
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
+)
+
+type migrationCall struct {
+	method, url string
+	cluster     logicalcluster.Name
+}
+
+func TestMigrationReconciler(t *testing.T) {
+	shards := map[string]*tenancyv1alpha1.ClusterWorkspaceShard{
+		"alpha": withURLs("https://alpha", "https://front-proxy", shard("alpha")),
+		"beta":  withURLs("https://beta", "https://front-proxy", shard("beta")),
+	}
+	start := time.Date(2022, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		workspace *tenancyv1alpha1.ClusterWorkspace
+		// abort clears status.location.target after the given number of iterations
+		abortAfter int
+		copyErr    error
+		// replicationLag is the number of lookups of the replicated workspace not observing spec.readOnly
+		replicationLag int
+
+		wantSteps    int
+		wantCalls    []migrationCall
+		wantReadOnly bool
+		wantCurrent  string
+		wantTarget   string
+		wantBaseURL  string
+		wantErr      string
+	}{
+		"migrates to the target shard": {
+			workspace: withTarget("beta", phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace()))),
+			wantSteps: 6,
+			wantCalls: []migrationCall{
+				{method: "copy", url: "https://alpha -> https://beta", cluster: logicalcluster.New("root:org:workspace")},
+				{method: "delete", url: "https://alpha", cluster: logicalcluster.New("root:org:workspace")},
+			},
+			wantCurrent: "beta",
+			wantBaseURL: "https://front-proxy/clusters/root:org:workspace",
+		},
+		"waits for the cache server to observe the freeze": {
+			workspace:      withTarget("beta", phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace()))),
+			replicationLag: 2,
+			wantSteps:      8,
+			wantCalls: []migrationCall{
+				{method: "copy", url: "https://alpha -> https://beta", cluster: logicalcluster.New("root:org:workspace")},
+				{method: "delete", url: "https://alpha", cluster: logicalcluster.New("root:org:workspace")},
+			},
+			wantCurrent: "beta",
+			wantBaseURL: "https://front-proxy/clusters/root:org:workspace",
+		},
+		"read-only workspace stays read-only": {
+			workspace: readOnly(withTarget("beta", phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace())))),
+			wantSteps: 4,
+			wantCalls: []migrationCall{
+				{method: "copy", url: "https://alpha -> https://beta", cluster: logicalcluster.New("root:org:workspace")},
+				{method: "delete", url: "https://alpha", cluster: logicalcluster.New("root:org:workspace")},
+			},
+			wantReadOnly: true,
+			wantCurrent:  "beta",
+			wantBaseURL:  "https://front-proxy/clusters/root:org:workspace",
+		},
+		"target equal to current is cleared": {
+			workspace:   withTarget("alpha", phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace()))),
+			wantCurrent: "alpha",
+			wantBaseURL: "https://front-proxy/clusters/root:org:workspace",
+		},
+		"no migration while scheduling": {
+			workspace:   withTarget("beta", phase(tenancyv1alpha1.ClusterWorkspacePhaseScheduling, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace()))),
+			wantCurrent: "alpha",
+			wantTarget:  "beta",
+			wantBaseURL: "https://front-proxy/clusters/root:org:workspace",
+		},
+		"aborted before copying": {
+			workspace:   withTarget("beta", phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace()))),
+			abortAfter:  2,
+			wantSteps:   5,
+			wantCurrent: "alpha",
+			wantBaseURL: "https://front-proxy/clusters/root:org:workspace",
+		},
+		"copy fails": {
+			workspace: withTarget("beta", phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace()))),
+			copyErr:   errors.New("connection refused"),
+			wantSteps: 3,
+			wantCalls: []migrationCall{
+				{method: "copy", url: "https://alpha -> https://beta", cluster: logicalcluster.New("root:org:workspace")},
+			},
+			wantReadOnly: true,
+			wantCurrent:  "alpha",
+			wantTarget:   "beta",
+			wantBaseURL:  "https://front-proxy/clusters/root:org:workspace",
+			wantErr:      "connection refused",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			now := start
+			var calls []migrationCall
+			r := &migrationReconciler{
+				getShard: func(name string) (*tenancyv1alpha1.ClusterWorkspaceShard, error) {
+					if s, found := shards[name]; found {
+						return s, nil
+					}
+					return nil, apierrors.NewNotFound(tenancyv1alpha1.Resource("clusterworkspaceshards"), name)
+				},
+				copyCluster: func(ctx context.Context, sourceURL, targetURL string, cluster logicalcluster.Name) (int64, error) {
+					calls = append(calls, migrationCall{method: "copy", url: sourceURL + " -> " + targetURL, cluster: cluster})
+					return 42, tc.copyErr
+				},
+				deleteCluster: func(ctx context.Context, url string, cluster logicalcluster.Name) (int64, error) {
+					calls = append(calls, migrationCall{method: "delete", url: url, cluster: cluster})
+					return 42, nil
+				},
+				requeueAfter: func(workspace *tenancyv1alpha1.ClusterWorkspace, duration time.Duration) {
+					now = now.Add(duration)
+				},
+				now: func() time.Time { return now },
+			}
+			ws := tc.workspace
+			lookups := 0
+			r.getReplicatedWorkspace = func(clusterName logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspace, error) {
+				require.Equal(t, "root:org", clusterName.String())
+				require.Equal(t, ws.Name, name)
+				if lookups++; lookups <= tc.replicationLag {
+					return nil, apierrors.NewNotFound(tenancyv1alpha1.Resource("clusterworkspaces"), name)
+				}
+				return ws.DeepCopy(), nil
+			}
+
+			ws.Annotations = map[string]string{logicalcluster.AnnotationKey: "root:org"}
+			steps := 0
+			var err error
+			for i := 0; i < 10; i++ {
+				if tc.abortAfter > 0 && steps == tc.abortAfter {
+					ws.Status.Location.Target = ""
+				}
+				var status reconcileStatus
+				status, err = r.reconcile(context.Background(), ws)
+				if err != nil || status == reconcileStatusContinue && ws.Status.Location.Migration == nil {
+					break
+				}
+				if ws.Status.Location.Migration != nil && ws.Status.Location.Migration.Phase == tenancyv1alpha1.ClusterWorkspaceMigrationPhaseFreezing {
+					require.Equal(t, start, ws.Status.Location.Migration.StartTime.Time, "start time must not change")
+				}
+				steps++
+			}
+
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+			} else {
+				require.NoError(t, err)
+				require.Nil(t, ws.Status.Location.Migration, "migration not finished")
+			}
+			require.Equal(t, tc.wantSteps, steps)
+			require.Equal(t, tc.wantCalls, calls)
+			require.Equal(t, tc.wantReadOnly, ws.Spec.ReadOnly)
+			require.Equal(t, tc.wantCurrent, ws.Status.Location.Current)
+			require.Equal(t, tc.wantTarget, ws.Status.Location.Target)
+			require.Equal(t, tc.wantBaseURL, ws.Status.BaseURL)
+		})
+	}
+}
+
+func TestMigrationReconcilerConditions(t *testing.T) {
+	ws := withTarget("gamma", phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace())))
+	r := &migrationReconciler{
+		getShard: func(name string) (*tenancyv1alpha1.ClusterWorkspaceShard, error) {
+			return nil, apierrors.NewNotFound(tenancyv1alpha1.Resource("clusterworkspaceshards"), name)
+		},
+		now: time.Now,
+	}
+
+	status, err := r.reconcile(context.Background(), ws)
+	require.NoError(t, err)
+	require.Equal(t, reconcileStatusContinue, status)
+	require.Nil(t, ws.Status.Location.Migration)
+
+	c := conditions.Get(ws, tenancyv1alpha1.WorkspaceMigrated)
+	require.NotNil(t, c)
+	require.Equal(t, corev1.ConditionFalse, c.Status)
+	require.Equal(t, tenancyv1alpha1.WorkspaceMigratedReasonTargetShardNotFound, c.Reason)
+}
+
+func withTarget(target string, ws *tenancyv1alpha1.ClusterWorkspace) *tenancyv1alpha1.ClusterWorkspace {
+	ws.Status.Location.Target = target
+	return ws
+}
+
+func readOnly(ws *tenancyv1alpha1.ClusterWorkspace) *tenancyv1alpha1.ClusterWorkspace {
+	ws.Spec.ReadOnly = true
+	return ws
+}
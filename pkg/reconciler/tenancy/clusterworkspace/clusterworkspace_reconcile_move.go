@@ -0,0 +1,311 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterworkspace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1/helper"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
+)
+
+const (
+	// moveFreezeGracePeriod is the time given to the shards to observe spec.readOnly, and to
+	// in-flight requests to finish, before the storage of a workspace is renamed.
+	moveFreezeGracePeriod = 5 * time.Second
+
+	// moveRedirectPeriod is the time requests to the previous path of a moved workspace are
+	// redirected to the new path.
+	moveRedirectPeriod = 24 * time.Hour
+)
+
+// moveReconciler moves a workspace to the path in spec.moveTo:
+//
+//  1. it records the move in status.move,
+//  2. it sets spec.readOnly to reject writes to the workspace,
+//  3. it renames the storage of the workspace and of its descendants on all shards. This
+//     moves the ClusterWorkspace object itself to the new parent, where the move continues,
+//  4. it restores spec.readOnly, clears spec.moveTo and records the previous path in the
+//     moved-from annotation, which makes the front-proxy redirect requests to the old path,
+//  5. it removes status.move, and the redirect annotations after moveRedirectPeriod.
+//
+// Every step is a separate patch of either spec or status. A move can be aborted by
+// clearing spec.moveTo before the storage is renamed.
+type moveReconciler struct {
+	getWorkspace    func(cluster logicalcluster.Name) (*tenancyv1alpha1.ClusterWorkspace, error)
+	listShards      func(selector labels.Selector) ([]*tenancyv1alpha1.ClusterWorkspaceShard, error)
+	listAPIBindings func(selector labels.Selector) ([]*apisv1alpha1.APIBinding, error)
+	renameCluster   func(ctx context.Context, url string, from, to logicalcluster.Name) (int64, error)
+	requeueAfter    func(workspace *tenancyv1alpha1.ClusterWorkspace, duration time.Duration)
+	now             func() time.Time
+}
+
+func (r *moveReconciler) reconcile(ctx context.Context, workspace *tenancyv1alpha1.ClusterWorkspace) (reconcileStatus, error) {
+	if workspace.Status.Phase != tenancyv1alpha1.ClusterWorkspacePhaseReady {
+		return reconcileStatusContinue, nil
+	}
+
+	logger := klog.FromContext(ctx)
+	current := logicalcluster.From(workspace).Join(workspace.Name)
+	move := workspace.Status.Move
+
+	if move == nil {
+		if workspace.Spec.MoveTo == nil {
+			return r.expireRedirect(workspace), nil
+		}
+		target := logicalcluster.New(workspace.Spec.MoveTo.Path)
+		if target == current {
+			workspace.Spec.MoveTo = nil
+			return reconcileStatusStopAndRequeue, nil
+		}
+		// don't interfere with a migration, both use spec.readOnly
+		if workspace.Status.Location.Migration != nil || workspace.Status.Location.Target != "" {
+			return reconcileStatusContinue, nil
+		}
+
+		if invalid, err := r.validateTarget(current, target); err != nil {
+			return reconcileStatusStopAndRequeue, err
+		} else if invalid != "" {
+			conditions.MarkFalse(workspace, tenancyv1alpha1.WorkspaceMoved, tenancyv1alpha1.WorkspaceMovedReasonTargetInvalid, conditionsv1alpha1.ConditionSeverityError, "Cannot move to %q: %s.", target, invalid)
+			return reconcileStatusContinue, nil // retry is automatic on workspace updates
+		}
+
+		logger.Info("starting move of workspace", "to", target.String())
+		workspace.Status.Move = &tenancyv1alpha1.ClusterWorkspaceMove{
+			Phase:       tenancyv1alpha1.ClusterWorkspaceMovePhaseFreezing,
+			From:        current.String(),
+			To:          target.String(),
+			StartTime:   metav1.NewTime(r.now()),
+			WasReadOnly: workspace.Spec.ReadOnly,
+		}
+		conditions.MarkFalse(workspace, tenancyv1alpha1.WorkspaceMoved, tenancyv1alpha1.WorkspaceMovedReasonMoving, conditionsv1alpha1.ConditionSeverityInfo, "Moving to %q.", target)
+		return reconcileStatusStopAndRequeue, nil
+	}
+
+	switch move.Phase {
+	case tenancyv1alpha1.ClusterWorkspaceMovePhaseFreezing:
+		if workspace.Spec.MoveTo == nil || workspace.Spec.MoveTo.Path != move.To {
+			logger.Info("aborting move of workspace")
+			move.Phase = tenancyv1alpha1.ClusterWorkspaceMovePhaseUnfreezing
+			conditions.Delete(workspace, tenancyv1alpha1.WorkspaceMoved)
+			return reconcileStatusStopAndRequeue, nil
+		}
+
+		if !workspace.Spec.ReadOnly {
+			workspace.Spec.ReadOnly = true
+			return reconcileStatusStopAndRequeue, nil
+		}
+		if remaining := move.StartTime.Add(moveFreezeGracePeriod).Sub(r.now()); remaining > 0 {
+			r.requeueAfter(workspace, remaining)
+			return reconcileStatusContinue, nil
+		}
+
+		// the target might have been taken in the meantime
+		target := logicalcluster.New(move.To)
+		if invalid, err := r.validateTarget(current, target); err != nil {
+			return reconcileStatusStopAndRequeue, err
+		} else if invalid != "" {
+			logger.Info("aborting move of workspace", "reason", invalid)
+			move.Phase = tenancyv1alpha1.ClusterWorkspaceMovePhaseUnfreezing
+			conditions.MarkFalse(workspace, tenancyv1alpha1.WorkspaceMoved, tenancyv1alpha1.WorkspaceMovedReasonTargetInvalid, conditionsv1alpha1.ConditionSeverityError, "Cannot move to %q: %s.", target, invalid)
+			return reconcileStatusStopAndRequeue, nil
+		}
+
+		move.Phase = tenancyv1alpha1.ClusterWorkspaceMovePhaseRenaming
+		conditions.MarkFalse(workspace, tenancyv1alpha1.WorkspaceMoved, tenancyv1alpha1.WorkspaceMovedReasonMoving, conditionsv1alpha1.ConditionSeverityInfo, "Renaming the storage to %q.", target)
+		return reconcileStatusStopAndRequeue, nil
+
+	case tenancyv1alpha1.ClusterWorkspaceMovePhaseRenaming:
+		// Descendants of the workspace can live on any shard. Renaming is idempotent, hence
+		// this is repeated on the moved ClusterWorkspace to finish a partial rename.
+		from, to := logicalcluster.New(move.From), logicalcluster.New(move.To)
+		shards, err := r.listShards(labels.Everything())
+		if err != nil {
+			return reconcileStatusStopAndRequeue, err
+		}
+		var renamed int64
+		for _, shard := range shards {
+			n, err := r.renameCluster(ctx, shard.Spec.BaseURL, from, to)
+			if err != nil {
+				conditions.MarkFalse(workspace, tenancyv1alpha1.WorkspaceMoved, tenancyv1alpha1.WorkspaceMovedReasonRenameFailed, conditionsv1alpha1.ConditionSeverityWarning, "Renaming the storage on ClusterWorkspaceShard %q failed: %v", shard.Name, err)
+				return reconcileStatusStopAndRequeue, err
+			}
+			renamed += n
+		}
+		logger.Info("renamed storage of workspace", "from", move.From, "to", move.To, "keys", renamed)
+
+		if current != to {
+			// This ClusterWorkspace has been moved to the new parent together with the storage,
+			// and the move continues there.
+			return reconcileStatusStopAndRequeue, nil
+		}
+		move.Phase = tenancyv1alpha1.ClusterWorkspaceMovePhaseUnfreezing
+		conditions.MarkFalse(workspace, tenancyv1alpha1.WorkspaceMoved, tenancyv1alpha1.WorkspaceMovedReasonMoving, conditionsv1alpha1.ConditionSeverityInfo, "Moved from %q.", move.From)
+		return reconcileStatusStopAndRequeue, nil
+
+	case tenancyv1alpha1.ClusterWorkspaceMovePhaseUnfreezing:
+		moved := current.String() == move.To
+		if workspace.Spec.ReadOnly != move.WasReadOnly || moved && (workspace.Spec.MoveTo != nil || workspace.Annotations[tenancyv1alpha1.ClusterWorkspaceMovedFromAnnotationKey] != move.From) {
+			workspace.Spec.ReadOnly = move.WasReadOnly
+			if moved {
+				workspace.Spec.MoveTo = nil
+				if workspace.Annotations == nil {
+					workspace.Annotations = map[string]string{}
+				}
+				workspace.Annotations[tenancyv1alpha1.ClusterWorkspaceMovedFromAnnotationKey] = move.From
+				workspace.Annotations[tenancyv1alpha1.ClusterWorkspaceMovedAtAnnotationKey] = r.now().UTC().Format(time.RFC3339)
+			}
+			return reconcileStatusStopAndRequeue, nil
+		}
+
+		logger.Info("finished move of workspace", "duration", r.now().Sub(move.StartTime.Time))
+		if moved {
+			conditions.MarkTrue(workspace, tenancyv1alpha1.WorkspaceMoved)
+			helper.AppendHistory(&workspace.Status, tenancyv1alpha1.ClusterWorkspaceHistoryEntry{
+				Time:    metav1.NewTime(r.now()),
+				Phase:   workspace.Status.Phase,
+				Reason:  "Moved",
+				Message: fmt.Sprintf("Moved from %q.", move.From),
+			})
+		}
+		workspace.Status.Move = nil
+		return reconcileStatusStopAndRequeue, nil
+	}
+
+	return reconcileStatusContinue, nil
+}
+
+// validateTarget returns why the workspace at current cannot be moved to target, or an
+// empty string if it can. The ClusterWorkspace is moved within the storage of a single
+// shard, hence the old and the new parent must be stored on the same shard. APIBindings
+// reference APIExports by path, hence APIExports in the workspace or its descendants must
+// not be bound.
+func (r *moveReconciler) validateTarget(current, target logicalcluster.Name) (string, error) {
+	if strings.HasPrefix(target.String(), current.String()+":") {
+		return "the target is inside of the workspace", nil
+	}
+
+	bindings, err := r.listAPIBindings(labels.Everything())
+	if err != nil {
+		return "", err
+	}
+	for _, binding := range bindings {
+		if path := referencedExportPath(binding); path == current || strings.HasPrefix(path.String(), current.String()+":") {
+			return fmt.Sprintf("APIExport %s|%s is bound by APIBinding %s|%s", path, exportName(binding), logicalcluster.From(binding), binding.Name), nil
+		}
+	}
+
+	currentParent, _ := current.Parent()
+	targetParent, _ := target.Parent()
+	currentShard, err := r.shardOf(currentParent)
+	if apierrors.IsNotFound(err) {
+		return fmt.Sprintf("the parent workspace %q is not stored on this shard", currentParent), nil
+	} else if err != nil {
+		return "", err
+	}
+	targetShard, err := r.shardOf(targetParent)
+	if apierrors.IsNotFound(err) {
+		return fmt.Sprintf("the parent workspace %q does not exist", targetParent), nil
+	} else if err != nil {
+		return "", err
+	}
+	if currentShard != targetShard {
+		return fmt.Sprintf("the parent workspace %q is not stored on ClusterWorkspaceShard %q", targetParent, currentShard), nil
+	}
+
+	if _, err := r.getWorkspace(target); err == nil {
+		return "a workspace with that path already exists", nil
+	} else if !apierrors.IsNotFound(err) {
+		return "", err
+	}
+	return "", nil
+}
+
+// shardOf returns the shard storing the given workspace.
+func (r *moveReconciler) shardOf(cluster logicalcluster.Name) (string, error) {
+	if cluster == tenancyv1alpha1.RootCluster {
+		return tenancyv1alpha1.RootShard, nil
+	}
+	workspace, err := r.getWorkspace(cluster)
+	if err != nil {
+		return "", err
+	}
+	if workspace.Status.Phase != tenancyv1alpha1.ClusterWorkspacePhaseReady {
+		return "", apierrors.NewNotFound(tenancyv1alpha1.Resource("clusterworkspaces"), cluster.String())
+	}
+	return workspace.Status.Location.Current, nil
+}
+
+// expireRedirect removes the redirect annotations of a moved workspace after moveRedirectPeriod.
+func (r *moveReconciler) expireRedirect(workspace *tenancyv1alpha1.ClusterWorkspace) reconcileStatus {
+	value, found := workspace.Annotations[tenancyv1alpha1.ClusterWorkspaceMovedAtAnnotationKey]
+	if _, movedFrom := workspace.Annotations[tenancyv1alpha1.ClusterWorkspaceMovedFromAnnotationKey]; !found && !movedFrom {
+		return reconcileStatusContinue
+	}
+	if movedAt, err := time.Parse(time.RFC3339, value); err == nil {
+		if remaining := movedAt.Add(moveRedirectPeriod).Sub(r.now()); remaining > 0 {
+			r.requeueAfter(workspace, remaining)
+			return reconcileStatusContinue
+		}
+	}
+
+	delete(workspace.Annotations, tenancyv1alpha1.ClusterWorkspaceMovedFromAnnotationKey)
+	delete(workspace.Annotations, tenancyv1alpha1.ClusterWorkspaceMovedAtAnnotationKey)
+	return reconcileStatusStopAndRequeue
+}
+
+// referencedExportPath returns the absolute path of the workspace of the APIExport an
+// APIBinding refers to.
+func referencedExportPath(binding *apisv1alpha1.APIBinding) logicalcluster.Name {
+	ref := binding.Spec.Reference
+	switch {
+	case ref.Selector != nil:
+		path, _, _ := apisv1alpha1.ParseSelectedExport(binding.Annotations[apisv1alpha1.AnnotationSelectedExportKey])
+		return path
+	case ref.Workspace != nil && ref.Workspace.Path != "":
+		return logicalcluster.New(ref.Workspace.Path)
+	case ref.Workspace != nil:
+		return logicalcluster.From(binding)
+	}
+	return logicalcluster.Name{}
+}
+
+// exportName returns the name of the APIExport an APIBinding refers to.
+func exportName(binding *apisv1alpha1.APIBinding) string {
+	ref := binding.Spec.Reference
+	switch {
+	case ref.Selector != nil:
+		_, name, _ := apisv1alpha1.ParseSelectedExport(binding.Annotations[apisv1alpha1.AnnotationSelectedExportKey])
+		return name
+	case ref.Workspace != nil:
+		return ref.Workspace.ExportName
+	}
+	return ""
+}
@@ -0,0 +1,313 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterworkspace
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
+)
+
+type renameCall struct {
+	url      string
+	from, to logicalcluster.Name
+}
+
+func TestMoveReconciler(t *testing.T) {
+	shards := []*tenancyv1alpha1.ClusterWorkspaceShard{
+		withURLs("https://alpha", "https://front-proxy", shard("alpha")),
+		withURLs("https://beta", "https://front-proxy", shard("beta")),
+	}
+	parents := map[logicalcluster.Name]*tenancyv1alpha1.ClusterWorkspace{
+		logicalcluster.New("root:org"):     phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:org", workspace())),
+		logicalcluster.New("root:other"):   phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:other", workspace())),
+		logicalcluster.New("root:remote"):  phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("beta", "https://front-proxy/clusters/root:remote", workspace())),
+		logicalcluster.New("root:org:foo"): phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:org:foo", workspace())),
+	}
+	start := time.Date(2022, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		workspace *tenancyv1alpha1.ClusterWorkspace
+		moveTo    string
+		// abort clears spec.moveTo after the given number of iterations
+		abortAfter int
+		renameErr  error
+		bindings   []*apisv1alpha1.APIBinding
+
+		wantSteps     int
+		wantCalls     []renameCall
+		wantReadOnly  bool
+		wantPath      string
+		wantMovedFrom string
+		wantMoveTo    string
+		wantReason    string
+		wantErr       string
+	}{
+		"moves to another parent and name": {
+			workspace: phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace())),
+			moveTo:    "root:other:renamed",
+			wantSteps: 8,
+			wantCalls: []renameCall{
+				{url: "https://alpha", from: logicalcluster.New("root:org:workspace"), to: logicalcluster.New("root:other:renamed")},
+				{url: "https://beta", from: logicalcluster.New("root:org:workspace"), to: logicalcluster.New("root:other:renamed")},
+				{url: "https://alpha", from: logicalcluster.New("root:org:workspace"), to: logicalcluster.New("root:other:renamed")},
+				{url: "https://beta", from: logicalcluster.New("root:org:workspace"), to: logicalcluster.New("root:other:renamed")},
+			},
+			wantPath:      "root:other:renamed",
+			wantMovedFrom: "root:org:workspace",
+		},
+		"read-only workspace stays read-only": {
+			workspace: readOnly(phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace()))),
+			moveTo:    "root:org:renamed",
+			wantSteps: 7,
+			wantCalls: []renameCall{
+				{url: "https://alpha", from: logicalcluster.New("root:org:workspace"), to: logicalcluster.New("root:org:renamed")},
+				{url: "https://beta", from: logicalcluster.New("root:org:workspace"), to: logicalcluster.New("root:org:renamed")},
+				{url: "https://alpha", from: logicalcluster.New("root:org:workspace"), to: logicalcluster.New("root:org:renamed")},
+				{url: "https://beta", from: logicalcluster.New("root:org:workspace"), to: logicalcluster.New("root:org:renamed")},
+			},
+			wantReadOnly:  true,
+			wantPath:      "root:org:renamed",
+			wantMovedFrom: "root:org:workspace",
+		},
+		"move to the current path is cleared": {
+			workspace: phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace())),
+			moveTo:    "root:org:workspace",
+			wantSteps: 1,
+			wantPath:  "root:org:workspace",
+		},
+		"parent on another shard": {
+			workspace:  phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace())),
+			moveTo:     "root:remote:workspace",
+			wantPath:   "root:org:workspace",
+			wantMoveTo: "root:remote:workspace",
+			wantReason: tenancyv1alpha1.WorkspaceMovedReasonTargetInvalid,
+		},
+		"parent does not exist": {
+			workspace:  phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace())),
+			moveTo:     "root:missing:workspace",
+			wantPath:   "root:org:workspace",
+			wantMoveTo: "root:missing:workspace",
+			wantReason: tenancyv1alpha1.WorkspaceMovedReasonTargetInvalid,
+		},
+		"target exists": {
+			workspace:  phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace())),
+			moveTo:     "root:org:foo",
+			wantPath:   "root:org:workspace",
+			wantMoveTo: "root:org:foo",
+			wantReason: tenancyv1alpha1.WorkspaceMovedReasonTargetInvalid,
+		},
+		"target inside the workspace": {
+			workspace:  phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace())),
+			moveTo:     "root:org:workspace:child",
+			wantPath:   "root:org:workspace",
+			wantMoveTo: "root:org:workspace:child",
+			wantReason: tenancyv1alpha1.WorkspaceMovedReasonTargetInvalid,
+		},
+		"APIExport in a descendant is bound": {
+			workspace: phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace())),
+			moveTo:    "root:other:renamed",
+			bindings: []*apisv1alpha1.APIBinding{
+				workspaceBinding("root:consumer", "root:org:workspace2", "unrelated"),
+				workspaceBinding("root:consumer", "root:org:workspace:child", "export"),
+			},
+			wantPath:   "root:org:workspace",
+			wantMoveTo: "root:other:renamed",
+			wantReason: tenancyv1alpha1.WorkspaceMovedReasonTargetInvalid,
+		},
+		"unrelated APIExports are bound": {
+			workspace: phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace())),
+			moveTo:    "root:other:renamed",
+			bindings: []*apisv1alpha1.APIBinding{
+				workspaceBinding("root:consumer", "root:org:workspace2", "unrelated"),
+			},
+			wantSteps: 8,
+			wantCalls: []renameCall{
+				{url: "https://alpha", from: logicalcluster.New("root:org:workspace"), to: logicalcluster.New("root:other:renamed")},
+				{url: "https://beta", from: logicalcluster.New("root:org:workspace"), to: logicalcluster.New("root:other:renamed")},
+				{url: "https://alpha", from: logicalcluster.New("root:org:workspace"), to: logicalcluster.New("root:other:renamed")},
+				{url: "https://beta", from: logicalcluster.New("root:org:workspace"), to: logicalcluster.New("root:other:renamed")},
+			},
+			wantPath:      "root:other:renamed",
+			wantMovedFrom: "root:org:workspace",
+		},
+		"aborted before renaming": {
+			workspace:  phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace())),
+			moveTo:     "root:other:renamed",
+			abortAfter: 2,
+			wantSteps:  5,
+			wantPath:   "root:org:workspace",
+		},
+		"rename fails": {
+			workspace: phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace())),
+			moveTo:    "root:other:renamed",
+			renameErr: errors.New("connection refused"),
+			wantSteps: 4,
+			wantCalls: []renameCall{
+				{url: "https://alpha", from: logicalcluster.New("root:org:workspace"), to: logicalcluster.New("root:other:renamed")},
+			},
+			wantReadOnly: true,
+			wantPath:     "root:org:workspace",
+			wantMoveTo:   "root:other:renamed",
+			wantReason:   tenancyv1alpha1.WorkspaceMovedReasonRenameFailed,
+			wantErr:      "connection refused",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			now := start
+			ws := tc.workspace
+			ws.Annotations = map[string]string{logicalcluster.AnnotationKey: "root:org"}
+			ws.Spec.MoveTo = &tenancyv1alpha1.ClusterWorkspaceMoveTarget{Path: tc.moveTo}
+
+			var calls []renameCall
+			r := &moveReconciler{
+				getWorkspace: func(cluster logicalcluster.Name) (*tenancyv1alpha1.ClusterWorkspace, error) {
+					if ws, found := parents[cluster]; found {
+						return ws, nil
+					}
+					return nil, apierrors.NewNotFound(tenancyv1alpha1.Resource("clusterworkspaces"), cluster.String())
+				},
+				listShards: func(selector labels.Selector) ([]*tenancyv1alpha1.ClusterWorkspaceShard, error) {
+					return shards, nil
+				},
+				listAPIBindings: func(selector labels.Selector) ([]*apisv1alpha1.APIBinding, error) {
+					return tc.bindings, nil
+				},
+				renameCluster: func(ctx context.Context, url string, from, to logicalcluster.Name) (int64, error) {
+					calls = append(calls, renameCall{url: url, from: from, to: to})
+					if tc.renameErr != nil {
+						return 0, tc.renameErr
+					}
+					// the ClusterWorkspace is renamed together with the storage
+					if logicalcluster.From(ws).Join(ws.Name) == from {
+						parent, name := to.Split()
+						ws.Annotations[logicalcluster.AnnotationKey] = parent.String()
+						ws.Name = name
+					}
+					return 42, nil
+				},
+				requeueAfter: func(workspace *tenancyv1alpha1.ClusterWorkspace, duration time.Duration) {
+					now = now.Add(duration)
+				},
+				now: func() time.Time { return now },
+			}
+
+			steps := 0
+			var err error
+			for i := 0; i < 10; i++ {
+				if tc.abortAfter > 0 && steps == tc.abortAfter {
+					ws.Spec.MoveTo = nil
+				}
+				var status reconcileStatus
+				status, err = r.reconcile(context.Background(), ws)
+				if err != nil || status == reconcileStatusContinue && ws.Status.Move == nil {
+					break
+				}
+				steps++
+			}
+
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+			} else {
+				require.NoError(t, err)
+				require.Nil(t, ws.Status.Move, "move not finished")
+			}
+			require.Equal(t, tc.wantSteps, steps)
+			require.Equal(t, tc.wantCalls, calls)
+			require.Equal(t, tc.wantReadOnly, ws.Spec.ReadOnly)
+			require.Equal(t, tc.wantPath, logicalcluster.From(ws).Join(ws.Name).String())
+			require.Equal(t, tc.wantMovedFrom, ws.Annotations[tenancyv1alpha1.ClusterWorkspaceMovedFromAnnotationKey])
+			if tc.wantMoveTo == "" {
+				require.Nil(t, ws.Spec.MoveTo)
+			} else {
+				require.Equal(t, tc.wantMoveTo, ws.Spec.MoveTo.Path)
+			}
+
+			c := conditions.Get(ws, tenancyv1alpha1.WorkspaceMoved)
+			switch {
+			case tc.wantReason != "":
+				require.NotNil(t, c)
+				require.Equal(t, corev1.ConditionFalse, c.Status)
+				require.Equal(t, tc.wantReason, c.Reason)
+			case tc.wantMovedFrom != "":
+				require.NotNil(t, c)
+				require.Equal(t, corev1.ConditionTrue, c.Status)
+			default:
+				require.Nil(t, c)
+			}
+		})
+	}
+}
+
+func TestMoveReconcilerExpiresRedirect(t *testing.T) {
+	now := time.Date(2022, 12, 1, 0, 0, 0, 0, time.UTC)
+	var requeuedAfter time.Duration
+	r := &moveReconciler{
+		requeueAfter: func(workspace *tenancyv1alpha1.ClusterWorkspace, duration time.Duration) {
+			requeuedAfter = duration
+		},
+		now: func() time.Time { return now },
+	}
+
+	ws := phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace()))
+	ws.Annotations = map[string]string{
+		tenancyv1alpha1.ClusterWorkspaceMovedFromAnnotationKey: "root:old:workspace",
+		tenancyv1alpha1.ClusterWorkspaceMovedAtAnnotationKey:   now.Add(-time.Hour).Format(time.RFC3339),
+	}
+
+	status, err := r.reconcile(context.Background(), ws)
+	require.NoError(t, err)
+	require.Equal(t, reconcileStatusContinue, status)
+	require.Equal(t, moveRedirectPeriod-time.Hour, requeuedAfter)
+	require.Equal(t, "root:old:workspace", ws.Annotations[tenancyv1alpha1.ClusterWorkspaceMovedFromAnnotationKey])
+
+	now = now.Add(moveRedirectPeriod)
+	status, err = r.reconcile(context.Background(), ws)
+	require.NoError(t, err)
+	require.Equal(t, reconcileStatusStopAndRequeue, status)
+	require.NotContains(t, ws.Annotations, tenancyv1alpha1.ClusterWorkspaceMovedFromAnnotationKey)
+	require.NotContains(t, ws.Annotations, tenancyv1alpha1.ClusterWorkspaceMovedAtAnnotationKey)
+}
+
+func workspaceBinding(cluster, exportPath, exportName string) *apisv1alpha1.APIBinding {
+	return &apisv1alpha1.APIBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        exportName,
+			Annotations: map[string]string{logicalcluster.AnnotationKey: cluster},
+		},
+		Spec: apisv1alpha1.APIBindingSpec{
+			Reference: apisv1alpha1.ExportReference{
+				Workspace: &apisv1alpha1.WorkspaceExportReference{Path: exportPath, ExportName: exportName},
+			},
+		},
+	}
+}
@@ -18,13 +18,18 @@ package clusterworkspace
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"time"
 
 	"github.com/kcp-dev/logicalcluster/v2"
 
+	authenticationv1 "k8s.io/api/authentication/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1/helper"
 	conditionsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
 	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
 )
@@ -32,12 +37,14 @@ import (
 type phaseReconciler struct {
 	getShardWithQuorum func(ctx context.Context, name string, options metav1.GetOptions) (*tenancyv1alpha1.ClusterWorkspaceShard, error)
 	getAPIBindings     func(clusterName logicalcluster.Name) ([]*apisv1alpha1.APIBinding, error)
+	now                func() time.Time
 }
 
 func (r *phaseReconciler) reconcile(ctx context.Context, workspace *tenancyv1alpha1.ClusterWorkspace) (reconcileStatus, error) {
 	switch workspace.Status.Phase {
 	case "":
 		workspace.Status.Phase = tenancyv1alpha1.ClusterWorkspacePhaseScheduling
+		r.recordTransition(workspace, "Created", "Created by %s.", workspaceOwner(workspace))
 	case tenancyv1alpha1.ClusterWorkspacePhaseScheduling:
 		// TODO(sttts): in the future this step is done by a workspace shard itself. I.e. moving to initializing is a step
 		//              of acceptance of the workspace on that shard.
@@ -52,16 +59,45 @@ func (r *phaseReconciler) reconcile(ctx context.Context, workspace *tenancyv1alp
 			}
 
 			workspace.Status.Phase = tenancyv1alpha1.ClusterWorkspacePhaseInitializing
+			r.recordTransition(workspace, "Scheduled", "Scheduled to ClusterWorkspaceShard %q.", workspace.Status.Location.Current)
 		}
 	case tenancyv1alpha1.ClusterWorkspacePhaseInitializing:
 		if len(workspace.Status.Initializers) > 0 {
 			conditions.MarkFalse(workspace, tenancyv1alpha1.WorkspaceInitialized, tenancyv1alpha1.WorkspaceInitializedInitializerExists, conditionsv1alpha1.ConditionSeverityInfo, "Initializers still exist: %v", workspace.Status.Initializers)
+			// initializers are removed one by one, hence this is recorded again whenever one of them is done
+			r.recordTransition(workspace, "WaitingForInitializers", "Waiting for initializers %v.", workspace.Status.Initializers)
 			return reconcileStatusContinue, nil
 		}
 
 		workspace.Status.Phase = tenancyv1alpha1.ClusterWorkspacePhaseReady
 		conditions.MarkTrue(workspace, tenancyv1alpha1.WorkspaceInitialized)
+		r.recordTransition(workspace, "Initialized", "Initialized on ClusterWorkspaceShard %q.", workspace.Status.Location.Current)
 	}
 
 	return reconcileStatusContinue, nil
 }
+
+// recordTransition adds the transition to the current phase to the history, unless it is
+// already the latest entry. The controller emits an Event into the parent workspace for every
+// new history entry.
+func (r *phaseReconciler) recordTransition(workspace *tenancyv1alpha1.ClusterWorkspace, reason, messageFormat string, messageArgs ...interface{}) {
+	helper.AppendHistory(&workspace.Status, tenancyv1alpha1.ClusterWorkspaceHistoryEntry{
+		Time:    metav1.NewTime(r.now()),
+		Phase:   workspace.Status.Phase,
+		Reason:  reason,
+		Message: fmt.Sprintf(messageFormat, messageArgs...),
+	})
+}
+
+// workspaceOwner returns the name of the user that created the workspace.
+func workspaceOwner(workspace *tenancyv1alpha1.ClusterWorkspace) string {
+	value, found := workspace.Annotations[tenancyv1alpha1.ExperimentalClusterWorkspaceOwnerAnnotationKey]
+	if !found {
+		return "the system"
+	}
+	var info authenticationv1.UserInfo
+	if err := json.Unmarshal([]byte(value), &info); err != nil || info.Username == "" {
+		return "an unknown user"
+	}
+	return fmt.Sprintf("user %q", info.Username)
+}
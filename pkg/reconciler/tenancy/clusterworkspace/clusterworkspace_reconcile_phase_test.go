@@ -0,0 +1,143 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterworkspace
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+func TestPhaseReconcilerHistory(t *testing.T) {
+	now := time.Date(2022, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		workspace *tenancyv1alpha1.ClusterWorkspace
+		shardErr  error
+
+		wantPhase   tenancyv1alpha1.ClusterWorkspacePhaseType
+		wantHistory []tenancyv1alpha1.ClusterWorkspaceHistoryEntry
+	}{
+		"created by user": {
+			workspace: withOwner(`{"username":"alice"}`, workspace()),
+			wantPhase: tenancyv1alpha1.ClusterWorkspacePhaseScheduling,
+			wantHistory: []tenancyv1alpha1.ClusterWorkspaceHistoryEntry{
+				{Time: metav1.NewTime(now), Phase: tenancyv1alpha1.ClusterWorkspacePhaseScheduling, Reason: "Created", Message: `Created by user "alice".`},
+			},
+		},
+		"created by the system": {
+			workspace: workspace(),
+			wantPhase: tenancyv1alpha1.ClusterWorkspacePhaseScheduling,
+			wantHistory: []tenancyv1alpha1.ClusterWorkspaceHistoryEntry{
+				{Time: metav1.NewTime(now), Phase: tenancyv1alpha1.ClusterWorkspacePhaseScheduling, Reason: "Created", Message: "Created by the system."},
+			},
+		},
+		"not yet scheduled": {
+			workspace: phase(tenancyv1alpha1.ClusterWorkspacePhaseScheduling, workspace()),
+			wantPhase: tenancyv1alpha1.ClusterWorkspacePhaseScheduling,
+		},
+		"scheduled to a vanished shard": {
+			workspace: phase(tenancyv1alpha1.ClusterWorkspacePhaseScheduling, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace())),
+			shardErr:  apierrors.NewNotFound(tenancyv1alpha1.Resource("clusterworkspaceshards"), "alpha"),
+			wantPhase: tenancyv1alpha1.ClusterWorkspacePhaseScheduling,
+		},
+		"scheduled with initializers": {
+			workspace: withInitializers(phase(tenancyv1alpha1.ClusterWorkspacePhaseScheduling, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace())), "root:org:foo"),
+			wantPhase: tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+			wantHistory: []tenancyv1alpha1.ClusterWorkspaceHistoryEntry{
+				{Time: metav1.NewTime(now), Phase: tenancyv1alpha1.ClusterWorkspacePhaseInitializing, Reason: "Scheduled", Message: `Scheduled to ClusterWorkspaceShard "alpha".`},
+			},
+		},
+		"waiting for initializers": {
+			workspace: withInitializers(phase(tenancyv1alpha1.ClusterWorkspacePhaseInitializing, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace())), "root:org:foo", "root:org:bar"),
+			wantPhase: tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+			wantHistory: []tenancyv1alpha1.ClusterWorkspaceHistoryEntry{
+				{Time: metav1.NewTime(now), Phase: tenancyv1alpha1.ClusterWorkspacePhaseInitializing, Reason: "WaitingForInitializers", Message: "Waiting for initializers [root:org:foo root:org:bar]."},
+			},
+		},
+		"still waiting for the same initializers": {
+			workspace: withHistory(withInitializers(phase(tenancyv1alpha1.ClusterWorkspacePhaseInitializing, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace())), "root:org:bar"),
+				tenancyv1alpha1.ClusterWorkspaceHistoryEntry{Time: metav1.NewTime(now.Add(-time.Minute)), Phase: tenancyv1alpha1.ClusterWorkspacePhaseInitializing, Reason: "WaitingForInitializers", Message: "Waiting for initializers [root:org:bar]."},
+			),
+			wantPhase: tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+			wantHistory: []tenancyv1alpha1.ClusterWorkspaceHistoryEntry{
+				{Time: metav1.NewTime(now.Add(-time.Minute)), Phase: tenancyv1alpha1.ClusterWorkspacePhaseInitializing, Reason: "WaitingForInitializers", Message: "Waiting for initializers [root:org:bar]."},
+			},
+		},
+		"initialized": {
+			workspace: phase(tenancyv1alpha1.ClusterWorkspacePhaseInitializing, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace())),
+			wantPhase: tenancyv1alpha1.ClusterWorkspacePhaseReady,
+			wantHistory: []tenancyv1alpha1.ClusterWorkspaceHistoryEntry{
+				{Time: metav1.NewTime(now), Phase: tenancyv1alpha1.ClusterWorkspacePhaseReady, Reason: "Initialized", Message: `Initialized on ClusterWorkspaceShard "alpha".`},
+			},
+		},
+		"ready": {
+			workspace: phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace())),
+			wantPhase: tenancyv1alpha1.ClusterWorkspacePhaseReady,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := &phaseReconciler{
+				getShardWithQuorum: func(ctx context.Context, name string, options metav1.GetOptions) (*tenancyv1alpha1.ClusterWorkspaceShard, error) {
+					if tc.shardErr != nil {
+						return nil, tc.shardErr
+					}
+					return shard(name), nil
+				},
+				getAPIBindings: func(clusterName logicalcluster.Name) ([]*apisv1alpha1.APIBinding, error) {
+					return nil, nil
+				},
+				now: func() time.Time { return now },
+			}
+			ws := tc.workspace.DeepCopy()
+			status, err := r.reconcile(context.Background(), ws)
+			require.NoError(t, err)
+			require.Equal(t, reconcileStatusContinue, status)
+			require.Equal(t, tc.wantPhase, ws.Status.Phase)
+			require.Equal(t, tc.wantHistory, ws.Status.History)
+		})
+	}
+}
+
+func withOwner(userInfo string, ws *tenancyv1alpha1.ClusterWorkspace) *tenancyv1alpha1.ClusterWorkspace {
+	if ws.Annotations == nil {
+		ws.Annotations = map[string]string{}
+	}
+	ws.Annotations[tenancyv1alpha1.ExperimentalClusterWorkspaceOwnerAnnotationKey] = userInfo
+	return ws
+}
+
+func withHistory(ws *tenancyv1alpha1.ClusterWorkspace, entries ...tenancyv1alpha1.ClusterWorkspaceHistoryEntry) *tenancyv1alpha1.ClusterWorkspace {
+	ws.Status.History = entries
+	return ws
+}
+
+func withInitializers(ws *tenancyv1alpha1.ClusterWorkspace, initializers ...tenancyv1alpha1.ClusterWorkspaceInitializer) *tenancyv1alpha1.ClusterWorkspace {
+	ws.Status.Initializers = initializers
+	return ws
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterworkspace
+
+import (
+	"context"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/migration"
+)
+
+// quotaReconciler reports the effective quota of a workspace in status.quota, i.e. the lowest
+// of its own limits and the inherited limits of its ancestors, together with the usage of the
+// workspace every measureInterval. The usage is taken from the tally of the shard storing the
+// workspace, which measures all of its workspaces in one pass. Ancestors stored on other shards
+// are not taken into account.
+type quotaReconciler struct {
+	getWorkspace    func(cluster logicalcluster.Name) (*tenancyv1alpha1.ClusterWorkspace, error)
+	getShard        func(name string) (*tenancyv1alpha1.ClusterWorkspaceShard, error)
+	measureCluster  func(ctx context.Context, url string, cluster logicalcluster.Name) (migration.ClusterUsage, error)
+	measureInterval time.Duration
+	requeueAfter    func(workspace *tenancyv1alpha1.ClusterWorkspace, duration time.Duration)
+	now             func() time.Time
+}
+
+func (r *quotaReconciler) reconcile(ctx context.Context, workspace *tenancyv1alpha1.ClusterWorkspace) (reconcileStatus, error) {
+	if workspace.Status.Phase != tenancyv1alpha1.ClusterWorkspacePhaseReady {
+		return reconcileStatusContinue, nil
+	}
+
+	hard, err := r.effectiveLimits(workspace)
+	if err != nil {
+		return reconcileStatusContinue, err
+	}
+	if len(hard) == 0 {
+		workspace.Status.Quota = nil
+		return reconcileStatusContinue, nil
+	}
+
+	// the storage is moved around, measure afterwards
+	if workspace.Status.Location.Migration != nil || workspace.Status.Move != nil {
+		return reconcileStatusContinue, nil
+	}
+
+	if quota := workspace.Status.Quota; quota != nil && quota.LastMeasureTime != nil && equality.Semantic.DeepEqual(quota.Hard, hard) {
+		if remaining := quota.LastMeasureTime.Add(r.measureInterval).Sub(r.now()); remaining > 0 {
+			r.requeueAfter(workspace, remaining)
+			return reconcileStatusContinue, nil
+		}
+	}
+
+	shard, err := r.getShard(workspace.Status.Location.Current)
+	if apierrors.IsNotFound(err) {
+		return reconcileStatusContinue, nil // the shard is gone, the workspace cannot be measured
+	} else if err != nil {
+		return reconcileStatusContinue, err
+	}
+	usage, err := r.measureCluster(ctx, shard.Spec.BaseURL, logicalcluster.From(workspace).Join(workspace.Name))
+	if err != nil {
+		return reconcileStatusContinue, err
+	}
+
+	used := corev1.ResourceList{}
+	for name := range hard {
+		switch name {
+		case tenancyv1alpha1.QuotaResourceWorkspaces:
+			used[name] = *resource.NewQuantity(usage.Workspaces, resource.DecimalSI)
+		case tenancyv1alpha1.QuotaResourceObjects:
+			used[name] = *resource.NewQuantity(usage.Objects, resource.DecimalSI)
+		case tenancyv1alpha1.QuotaResourceStorage:
+			used[name] = *resource.NewQuantity(usage.Bytes, resource.BinarySI)
+		}
+	}
+	now := metav1.NewTime(r.now())
+	workspace.Status.Quota = &tenancyv1alpha1.ClusterWorkspaceQuotaStatus{
+		Hard:            hard,
+		Used:            used,
+		LastMeasureTime: &now,
+	}
+	r.requeueAfter(workspace, r.measureInterval)
+
+	return reconcileStatusContinue, nil
+}
+
+// effectiveLimits returns the lowest limit of every resource limited by the workspace itself
+// or inherited from one of its ancestors.
+func (r *quotaReconciler) effectiveLimits(workspace *tenancyv1alpha1.ClusterWorkspace) (corev1.ResourceList, error) {
+	hard := corev1.ResourceList{}
+	merge := func(limits corev1.ResourceList) {
+		for name, limit := range limits {
+			if existing, found := hard[name]; !found || limit.Cmp(existing) < 0 {
+				hard[name] = limit.DeepCopy()
+			}
+		}
+	}
+
+	if workspace.Spec.Quota != nil {
+		merge(workspace.Spec.Quota.Hard)
+	}
+	for cluster := logicalcluster.From(workspace); ; {
+		parent, hasParent := cluster.Parent()
+		if !hasParent {
+			break
+		}
+		ancestor, err := r.getWorkspace(cluster)
+		if apierrors.IsNotFound(err) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if ancestor.Spec.Quota != nil && ancestor.Spec.Quota.Inherited {
+			merge(ancestor.Spec.Quota.Hard)
+		}
+		cluster = parent
+	}
+
+	return hard, nil
+}
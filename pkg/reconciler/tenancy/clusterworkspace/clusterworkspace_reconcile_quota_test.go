@@ -0,0 +1,188 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterworkspace
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/migration"
+)
+
+func TestQuotaReconciler(t *testing.T) {
+	now := time.Date(2022, 12, 1, 0, 0, 0, 0, time.UTC)
+	ancestors := map[logicalcluster.Name]*tenancyv1alpha1.ClusterWorkspace{
+		logicalcluster.New("root:org"):      withQuota(true, "workspaces=10,objects=1000", workspace()),
+		logicalcluster.New("root:org:team"): withQuota(false, "workspaces=1", workspace()),
+	}
+
+	tests := map[string]struct {
+		workspace *tenancyv1alpha1.ClusterWorkspace
+
+		wantMeasured bool
+		wantQuota    *tenancyv1alpha1.ClusterWorkspaceQuotaStatus
+		wantRequeue  time.Duration
+	}{
+		"own quota below the inherited one": {
+			workspace:    withQuota(false, "objects=100,storage=1Mi", ready(workspace())),
+			wantMeasured: true,
+			wantQuota: &tenancyv1alpha1.ClusterWorkspaceQuotaStatus{
+				Hard:            resourceList("workspaces=10,objects=100,storage=1Mi"),
+				Used:            resourceList("workspaces=2,objects=42,storage=4Ki"),
+				LastMeasureTime: &metav1.Time{Time: now},
+			},
+			wantRequeue: time.Minute,
+		},
+		"own quota cannot exceed the inherited one": {
+			workspace:    withQuota(false, "objects=5000", ready(workspace())),
+			wantMeasured: true,
+			wantQuota: &tenancyv1alpha1.ClusterWorkspaceQuotaStatus{
+				Hard:            resourceList("workspaces=10,objects=1000"),
+				Used:            resourceList("workspaces=2,objects=42"),
+				LastMeasureTime: &metav1.Time{Time: now},
+			},
+			wantRequeue: time.Minute,
+		},
+		"only inherited quota": {
+			workspace:    ready(workspace()),
+			wantMeasured: true,
+			wantQuota: &tenancyv1alpha1.ClusterWorkspaceQuotaStatus{
+				Hard:            resourceList("workspaces=10,objects=1000"),
+				Used:            resourceList("workspaces=2,objects=42"),
+				LastMeasureTime: &metav1.Time{Time: now},
+			},
+			wantRequeue: time.Minute,
+		},
+		"recently measured": {
+			workspace: withQuotaStatus(now.Add(-20*time.Second), "workspaces=10,objects=1000", ready(workspace())),
+			wantQuota: &tenancyv1alpha1.ClusterWorkspaceQuotaStatus{
+				Hard:            resourceList("workspaces=10,objects=1000"),
+				Used:            resourceList("workspaces=1,objects=1"),
+				LastMeasureTime: &metav1.Time{Time: now.Add(-20 * time.Second)},
+			},
+			wantRequeue: 40 * time.Second,
+		},
+		"recently measured with different limits": {
+			workspace:    withQuotaStatus(now.Add(-20*time.Second), "objects=1", ready(workspace())),
+			wantMeasured: true,
+			wantQuota: &tenancyv1alpha1.ClusterWorkspaceQuotaStatus{
+				Hard:            resourceList("workspaces=10,objects=1000"),
+				Used:            resourceList("workspaces=2,objects=42"),
+				LastMeasureTime: &metav1.Time{Time: now},
+			},
+			wantRequeue: time.Minute,
+		},
+		"not ready": {
+			workspace: withQuota(false, "objects=100", phase(tenancyv1alpha1.ClusterWorkspacePhaseInitializing, scheduled("alpha", "https://front-proxy/clusters/root:org:team:workspace", workspace()))),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ws := tt.workspace
+			ws.Annotations = map[string]string{logicalcluster.AnnotationKey: "root:org:team"}
+
+			var measured bool
+			var requeue time.Duration
+			r := &quotaReconciler{
+				getWorkspace: func(cluster logicalcluster.Name) (*tenancyv1alpha1.ClusterWorkspace, error) {
+					if ws, found := ancestors[cluster]; found {
+						return ws, nil
+					}
+					return nil, apierrors.NewNotFound(tenancyv1alpha1.Resource("clusterworkspaces"), cluster.String())
+				},
+				getShard: func(name string) (*tenancyv1alpha1.ClusterWorkspaceShard, error) {
+					require.Equal(t, "alpha", name)
+					return withURLs("https://alpha", "https://front-proxy", shard("alpha")), nil
+				},
+				measureCluster: func(ctx context.Context, url string, cluster logicalcluster.Name) (migration.ClusterUsage, error) {
+					require.Equal(t, "https://alpha", url)
+					require.Equal(t, "root:org:team:workspace", cluster.String())
+					measured = true
+					return migration.ClusterUsage{Objects: 42, Bytes: 4096, Workspaces: 2}, nil
+				},
+				measureInterval: time.Minute,
+				requeueAfter: func(_ *tenancyv1alpha1.ClusterWorkspace, duration time.Duration) {
+					requeue = duration
+				},
+				now: func() time.Time { return now },
+			}
+
+			status, err := r.reconcile(context.Background(), ws)
+			require.NoError(t, err)
+			require.Equal(t, reconcileStatusContinue, status)
+			require.Equal(t, tt.wantMeasured, measured)
+			require.Equal(t, tt.wantRequeue, requeue)
+			if tt.wantQuota == nil {
+				require.Nil(t, ws.Status.Quota)
+				return
+			}
+			require.NotNil(t, ws.Status.Quota)
+			requireResourceListEqual(t, tt.wantQuota.Hard, ws.Status.Quota.Hard)
+			requireResourceListEqual(t, tt.wantQuota.Used, ws.Status.Quota.Used)
+			require.True(t, tt.wantQuota.LastMeasureTime.Equal(ws.Status.Quota.LastMeasureTime))
+		})
+	}
+}
+
+func ready(ws *tenancyv1alpha1.ClusterWorkspace) *tenancyv1alpha1.ClusterWorkspace {
+	return phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:org:team:workspace", ws))
+}
+
+func withQuota(inherited bool, hard string, ws *tenancyv1alpha1.ClusterWorkspace) *tenancyv1alpha1.ClusterWorkspace {
+	ws.Spec.Quota = &tenancyv1alpha1.ClusterWorkspaceQuota{Hard: resourceList(hard), Inherited: inherited}
+	return ws
+}
+
+func withQuotaStatus(measured time.Time, hard string, ws *tenancyv1alpha1.ClusterWorkspace) *tenancyv1alpha1.ClusterWorkspace {
+	ws.Status.Quota = &tenancyv1alpha1.ClusterWorkspaceQuotaStatus{
+		Hard:            resourceList(hard),
+		Used:            resourceList("workspaces=1,objects=1"),
+		LastMeasureTime: &metav1.Time{Time: measured},
+	}
+	return ws
+}
+
+// resourceList parses a comma separated list of name=quantity pairs.
+func resourceList(s string) corev1.ResourceList {
+	list := corev1.ResourceList{}
+	for _, pair := range strings.Split(s, ",") {
+		name, value, _ := strings.Cut(pair, "=")
+		list[corev1.ResourceName(name)] = resource.MustParse(value)
+	}
+	return list
+}
+
+func requireResourceListEqual(t *testing.T, want, got corev1.ResourceList) {
+	t.Helper()
+	require.Len(t, got, len(want))
+	for name, quantity := range want {
+		actual, found := got[name]
+		require.True(t, found, "missing %s", name)
+		require.Zero(t, quantity.Cmp(actual), "%s: want %s, got %s", name, quantity.String(), actual.String())
+	}
+}
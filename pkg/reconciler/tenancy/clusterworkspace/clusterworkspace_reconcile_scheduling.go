@@ -19,16 +19,11 @@ package clusterworkspace
 import (
 	"context"
 	"fmt"
-	"net/url"
-	"path"
-
-	"github.com/kcp-dev/logicalcluster/v2"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
-	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/klog/v2"
 
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
@@ -40,11 +35,11 @@ import (
 type schedulingReconciler struct {
 	getShard   func(name string) (*tenancyv1alpha1.ClusterWorkspaceShard, error)
 	listShards func(selector labels.Selector) ([]*tenancyv1alpha1.ClusterWorkspaceShard, error)
+	strategy   SchedulingStrategy
 }
 
 func (r *schedulingReconciler) reconcile(ctx context.Context, workspace *tenancyv1alpha1.ClusterWorkspace) (reconcileStatus, error) {
 	logger := klog.FromContext(ctx)
-	workspaceClusterName := logicalcluster.From(workspace)
 	switch workspace.Status.Phase {
 	case tenancyv1alpha1.ClusterWorkspacePhaseScheduling:
 		// possibly de-schedule while still in scheduling phase
@@ -124,30 +119,36 @@ func (r *schedulingReconciler) reconcile(ctx context.Context, workspace *tenancy
 				reason, message string
 			}{}
 			for _, shard := range shards {
-				if valid, reason, message := isValidShard(shard); valid {
-					validShards = append(validShards, shard)
-				} else {
+				if valid, reason, message := isValidShard(shard); !valid {
 					invalidShards[shard.Name] = struct {
 						reason, message string
 					}{
 						reason:  reason,
 						message: message,
 					}
+				} else if full, message := isFullShard(shard); full {
+					invalidShards[shard.Name] = struct {
+						reason, message string
+					}{
+						reason:  "ShardFull",
+						message: message,
+					}
+				} else {
+					validShards = append(validShards, shard)
 				}
 			}
 
 			if len(validShards) > 0 {
-				targetShard := validShards[rand.Intn(len(validShards))]
+				targetShard := r.strategy.Choose(validShards)
 
-				u, err := url.Parse(targetShard.Spec.ExternalURL)
+				baseURL, err := workspaceBaseURL(targetShard, workspace)
 				if err != nil {
 					// shouldn't happen since we just checked in isValidShard
 					conditions.MarkFalse(workspace, tenancyv1alpha1.WorkspaceScheduled, tenancyv1alpha1.WorkspaceReasonReasonUnknown, conditionsv1alpha1.ConditionSeverityError, "Invalid connection information on target ClusterWorkspaceShard: %v.", err)
 					return reconcileStatusStopAndRequeue, err // requeue
 				}
-				u.Path = path.Join(u.Path, workspaceClusterName.Join(workspace.Name).Path())
 
-				workspace.Status.BaseURL = u.String()
+				workspace.Status.BaseURL = baseURL
 				workspace.Status.Location.Current = targetShard.Name
 
 				conditions.MarkTrue(workspace, tenancyv1alpha1.WorkspaceScheduled)
@@ -161,32 +162,10 @@ func (r *schedulingReconciler) reconcile(ctx context.Context, workspace *tenancy
 				logger.Error(utilerrors.NewAggregate(failures), "no valid shards found for workspace, skipping")
 			}
 		}
-	case tenancyv1alpha1.ClusterWorkspacePhaseInitializing, tenancyv1alpha1.ClusterWorkspacePhaseReady:
-		// movement can only happen after scheduling
-		if workspace.Status.Location.Target == "" {
-			break
-		}
-
-		current, target := workspace.Status.Location.Current, workspace.Status.Location.Target
-		if current == target {
-			workspace.Status.Location.Target = ""
-			break
-		}
-
-		_, err := r.getShard(target)
-		if apierrors.IsNotFound(err) {
-			logger.Info("cannot move to nonexistent shard", "ClusterWorkspaceShard", target)
-		} else if err != nil {
-			return reconcileStatusStopAndRequeue, err
-		}
-
-		logger.Info("moving workspace to shard", "ClusterWorkspaceShard", workspace.Status.Location.Target)
-		workspace.Status.Location.Current = workspace.Status.Location.Target
-		workspace.Status.Location.Target = ""
 	}
 
 	// check scheduled shard. This has no influence on the workspace baseURL or shard assignment. This might be a trigger for
-	// a movement controller in the future (or a human intervention) to move workspaces off a shard.
+	// a human intervention to migrate workspaces off a shard by setting status.location.target.
 	if workspace.Status.Location.Current != "" {
 		shard, err := r.getShard(workspace.Status.Location.Current)
 		if apierrors.IsNotFound(err) {
@@ -194,7 +173,12 @@ func (r *schedulingReconciler) reconcile(ctx context.Context, workspace *tenancy
 		} else if err != nil {
 			return reconcileStatusStopAndRequeue, err
 		} else if valid, reason, message := isValidShard(shard); !valid {
-			conditions.MarkFalse(workspace, tenancyv1alpha1.WorkspaceShardValid, reason, conditionsv1alpha1.ConditionSeverityError, message)
+			severity := conditionsv1alpha1.ConditionSeverityError
+			if reason == tenancyv1alpha1.WorkspaceShardValidReasonShardUnschedulable || reason == tenancyv1alpha1.WorkspaceShardValidReasonShardDraining {
+				// the workspace keeps working, but should be migrated eventually
+				severity = conditionsv1alpha1.ConditionSeverityWarning
+			}
+			conditions.MarkFalse(workspace, tenancyv1alpha1.WorkspaceShardValid, reason, severity, message)
 		} else {
 			conditions.MarkTrue(workspace, tenancyv1alpha1.WorkspaceShardValid)
 		}
@@ -213,7 +197,7 @@ func (r *schedulingReconciler) reconcile(ctx context.Context, workspace *tenancy
 				needsRescheduling = true
 			}
 			if needsRescheduling {
-				conditions.MarkFalse(workspace, tenancyv1alpha1.WorkspaceScheduled, tenancyv1alpha1.WorkspaceReasonUnreschedulable, conditionsv1alpha1.ConditionSeverityError, "Needs rescheduling, but movement is not supported yet")
+				conditions.MarkFalse(workspace, tenancyv1alpha1.WorkspaceScheduled, tenancyv1alpha1.WorkspaceReasonUnreschedulable, conditionsv1alpha1.ConditionSeverityError, "Needs rescheduling, set status.location.target to migrate the workspace")
 			} else {
 				conditions.MarkTrue(workspace, tenancyv1alpha1.WorkspaceScheduled)
 			}
@@ -226,5 +210,11 @@ func (r *schedulingReconciler) reconcile(ctx context.Context, workspace *tenancy
 }
 
 func isValidShard(shard *tenancyv1alpha1.ClusterWorkspaceShard) (valid bool, reason, message string) {
+	if shard.Spec.EvictAfter != nil {
+		return false, tenancyv1alpha1.WorkspaceShardValidReasonShardDraining, fmt.Sprintf("ClusterWorkspaceShard %q is being drained.", shard.Name)
+	}
+	if shard.Spec.Unschedulable {
+		return false, tenancyv1alpha1.WorkspaceShardValidReasonShardUnschedulable, fmt.Sprintf("ClusterWorkspaceShard %q is cordoned.", shard.Name)
+	}
 	return true, "", ""
 }
@@ -24,6 +24,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 
@@ -213,6 +214,81 @@ func TestSchedulingReconciler(t *testing.T) {
 			),
 			wantStatus: reconcileStatusContinue,
 		},
+		{
+			name: "spec shard selector, least loaded shard",
+			workspace: phase(tenancyv1alpha1.ClusterWorkspacePhaseScheduling,
+				constrained(tenancyv1alpha1.ShardConstraints{Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"a": "1"}},
+				}, workspace())),
+			shards: []*tenancyv1alpha1.ClusterWorkspaceShard{
+				withUsage("100", "80", withLabels(map[string]string{"a": "1"}, withURLs("https://foo", "https://front-proxy", shard("foo")))),
+				withUsage("1000", "200", withLabels(map[string]string{"a": "1"}, withURLs("https://bar", "https://front-proxy", shard("bar")))),
+			},
+			want: withConditions(phase(tenancyv1alpha1.ClusterWorkspacePhaseScheduling,
+				scheduled("bar", "https://front-proxy/clusters/workspace",
+					constrained(tenancyv1alpha1.ShardConstraints{Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"a": "1"}},
+					}, workspace()))),
+				conditionsapi.Condition{
+					Type:   tenancyv1alpha1.WorkspaceScheduled,
+					Status: corev1.ConditionTrue,
+				},
+				conditionsapi.Condition{
+					Type:   tenancyv1alpha1.WorkspaceShardValid,
+					Status: corev1.ConditionTrue,
+				},
+			),
+			wantStatus: reconcileStatusContinue,
+		},
+		{
+			name: "spec shard selector, cordoned shard skipped",
+			workspace: phase(tenancyv1alpha1.ClusterWorkspacePhaseScheduling,
+				constrained(tenancyv1alpha1.ShardConstraints{Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"a": "1"}},
+				}, workspace())),
+			shards: []*tenancyv1alpha1.ClusterWorkspaceShard{
+				cordoned(withLabels(map[string]string{"a": "1"}, withURLs("https://foo", "https://front-proxy", shard("foo")))),
+				withUsage("100", "90", withLabels(map[string]string{"a": "1"}, withURLs("https://bar", "https://front-proxy", shard("bar")))),
+			},
+			want: withConditions(phase(tenancyv1alpha1.ClusterWorkspacePhaseScheduling,
+				scheduled("bar", "https://front-proxy/clusters/workspace",
+					constrained(tenancyv1alpha1.ShardConstraints{Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"a": "1"}},
+					}, workspace()))),
+				conditionsapi.Condition{
+					Type:   tenancyv1alpha1.WorkspaceScheduled,
+					Status: corev1.ConditionTrue,
+				},
+				conditionsapi.Condition{
+					Type:   tenancyv1alpha1.WorkspaceShardValid,
+					Status: corev1.ConditionTrue,
+				},
+			),
+			wantStatus: reconcileStatusContinue,
+		},
+		{
+			name: "spec shard selector, all shards full",
+			workspace: phase(tenancyv1alpha1.ClusterWorkspacePhaseScheduling,
+				constrained(tenancyv1alpha1.ShardConstraints{Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"a": "1"}},
+				}, workspace())),
+			shards: []*tenancyv1alpha1.ClusterWorkspaceShard{
+				withUsage("100", "100", withLabels(map[string]string{"a": "1"}, withURLs("https://foo", "https://front-proxy", shard("foo")))),
+				withUsage("10", "10", withLabels(map[string]string{"b": "2"}, withURLs("https://bar", "https://front-proxy", shard("bar")))),
+			},
+			want: withConditions(phase(tenancyv1alpha1.ClusterWorkspacePhaseScheduling,
+				constrained(tenancyv1alpha1.ShardConstraints{Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"a": "1"}},
+				}, workspace())),
+				conditionsapi.Condition{
+					Type:     tenancyv1alpha1.WorkspaceScheduled,
+					Severity: conditionsapi.ConditionSeverityError,
+					Status:   corev1.ConditionFalse,
+					Reason:   tenancyv1alpha1.WorkspaceReasonUnschedulable,
+				},
+			),
+			wantStatus: reconcileStatusContinue,
+		},
 		{
 			name: "invalid spec shard selector",
 			workspace: phase(tenancyv1alpha1.ClusterWorkspacePhaseScheduling,
@@ -257,6 +333,7 @@ func TestSchedulingReconciler(t *testing.T) {
 					}
 					return shards, nil
 				},
+				strategy: newScoringStrategy(func(load float64) float64 { return -load }, func(int) int { return 0 }),
 			}
 			ws := tt.workspace.DeepCopy()
 			status, err := r.reconcile(context.Background(), ws)
@@ -328,3 +405,9 @@ func withLabels(labels map[string]string, shard *tenancyv1alpha1.ClusterWorkspac
 	shard.Labels = labels
 	return shard
 }
+
+func withUsage(capacity, usage string, shard *tenancyv1alpha1.ClusterWorkspaceShard) *tenancyv1alpha1.ClusterWorkspaceShard {
+	shard.Status.Capacity = corev1.ResourceList{tenancyv1alpha1.ShardResourceWorkspaces: resource.MustParse(capacity)}
+	shard.Status.Usage = corev1.ResourceList{tenancyv1alpha1.ShardResourceWorkspaces: resource.MustParse(usage)}
+	return shard
+}
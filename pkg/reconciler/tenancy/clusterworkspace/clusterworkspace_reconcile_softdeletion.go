@@ -0,0 +1,56 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterworkspace
+
+import (
+	"context"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+// softDeletionReconciler deletes a workspace in the Deleted phase for good when the retention
+// period in status.deletion has passed. Then the clusterworkspacedeletion controller deletes
+// its content.
+type softDeletionReconciler struct {
+	deleteWorkspace func(ctx context.Context, workspace *tenancyv1alpha1.ClusterWorkspace) error
+	requeueAfter    func(workspace *tenancyv1alpha1.ClusterWorkspace, duration time.Duration)
+	now             func() time.Time
+}
+
+func (r *softDeletionReconciler) reconcile(ctx context.Context, workspace *tenancyv1alpha1.ClusterWorkspace) (reconcileStatus, error) {
+	if workspace.Status.Phase != tenancyv1alpha1.ClusterWorkspacePhaseDeleted || workspace.Status.Deletion == nil || !workspace.DeletionTimestamp.IsZero() {
+		return reconcileStatusContinue, nil
+	}
+
+	if remaining := workspace.Status.Deletion.RetainUntil.Sub(r.now()); remaining > 0 {
+		r.requeueAfter(workspace, remaining)
+		return reconcileStatusContinue, nil
+	}
+
+	logger := klog.FromContext(ctx)
+	logger.Info("retention period of deleted workspace has passed, deleting it", "retainUntil", workspace.Status.Deletion.RetainUntil)
+	if err := r.deleteWorkspace(ctx, workspace); err != nil && !apierrors.IsNotFound(err) {
+		// a conflict means the workspace has been undeleted in the meantime
+		return reconcileStatusContinue, err
+	}
+
+	return reconcileStatusContinue, nil
+}
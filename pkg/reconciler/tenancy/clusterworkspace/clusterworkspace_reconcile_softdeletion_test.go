@@ -0,0 +1,112 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterworkspace
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+func TestSoftDeletionReconciler(t *testing.T) {
+	now := time.Date(2022, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		workspace *tenancyv1alpha1.ClusterWorkspace
+		deleteErr error
+
+		wantDeleted bool
+		wantRequeue time.Duration
+		wantErr     bool
+	}{
+		"ready workspace": {
+			workspace: phase(tenancyv1alpha1.ClusterWorkspacePhaseReady, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace())),
+		},
+		"retention period not passed": {
+			workspace:   softDeleted(now.Add(-time.Hour), now.Add(time.Hour), scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace())),
+			wantRequeue: time.Hour,
+		},
+		"retention period passed": {
+			workspace:   softDeleted(now.Add(-time.Hour), now, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace())),
+			wantDeleted: true,
+		},
+		"already deleted": {
+			workspace:   softDeleted(now.Add(-time.Hour), now, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace())),
+			deleteErr:   apierrors.NewNotFound(tenancyv1alpha1.Resource("clusterworkspaces"), "workspace"),
+			wantDeleted: true,
+		},
+		"undeleted in the meantime": {
+			workspace:   softDeleted(now.Add(-time.Hour), now, scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace())),
+			deleteErr:   apierrors.NewConflict(tenancyv1alpha1.Resource("clusterworkspaces"), "workspace", nil),
+			wantDeleted: true,
+			wantErr:     true,
+		},
+		"deletion in progress": {
+			workspace: terminating(now, softDeleted(now.Add(-2*time.Hour), now.Add(-time.Hour), scheduled("alpha", "https://front-proxy/clusters/root:org:workspace", workspace()))),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var deleted bool
+			var requeue time.Duration
+			r := &softDeletionReconciler{
+				deleteWorkspace: func(ctx context.Context, workspace *tenancyv1alpha1.ClusterWorkspace) error {
+					deleted = true
+					return tc.deleteErr
+				},
+				requeueAfter: func(_ *tenancyv1alpha1.ClusterWorkspace, duration time.Duration) {
+					requeue = duration
+				},
+				now: func() time.Time { return now },
+			}
+
+			ws := tc.workspace.DeepCopy()
+			status, err := r.reconcile(context.Background(), ws)
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, reconcileStatusContinue, status)
+			require.Equal(t, tc.wantDeleted, deleted)
+			require.Equal(t, tc.wantRequeue, requeue)
+			require.Equal(t, tc.workspace, ws)
+		})
+	}
+}
+
+func softDeleted(deletionTime, retainUntil time.Time, ws *tenancyv1alpha1.ClusterWorkspace) *tenancyv1alpha1.ClusterWorkspace {
+	ws.Status.Phase = tenancyv1alpha1.ClusterWorkspacePhaseDeleted
+	ws.Status.Deletion = &tenancyv1alpha1.ClusterWorkspaceDeletion{
+		DeletionTime: metav1.NewTime(deletionTime),
+		RetainUntil:  metav1.NewTime(retainUntil),
+	}
+	return ws
+}
+
+func terminating(deletionTimestamp time.Time, ws *tenancyv1alpha1.ClusterWorkspace) *tenancyv1alpha1.ClusterWorkspace {
+	ws.DeletionTimestamp = &metav1.Time{Time: deletionTimestamp}
+	return ws
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterworkspace
+
+import (
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+// SchedulingStrategyType is the name of a strategy to pick a shard for a new workspace.
+type SchedulingStrategyType string
+
+const (
+	// LeastLoadedSchedulingStrategy picks the shard with the lowest load, spreading
+	// workspaces evenly over the shards.
+	LeastLoadedSchedulingStrategy SchedulingStrategyType = "least-loaded"
+	// BinPackingSchedulingStrategy picks the shard with the highest load that is not
+	// full yet, filling up shards one after the other.
+	BinPackingSchedulingStrategy SchedulingStrategyType = "bin-packing"
+	// RandomSchedulingStrategy picks a random shard.
+	RandomSchedulingStrategy SchedulingStrategyType = "random"
+)
+
+// shardResources are the resources taken into account to compute the load of a shard.
+var shardResources = []corev1.ResourceName{
+	tenancyv1alpha1.ShardResourceWorkspaces,
+	tenancyv1alpha1.ShardResourceObjects,
+	tenancyv1alpha1.ShardResourceDatabaseSize,
+}
+
+// SchedulingStrategy picks the shard a workspace is scheduled onto.
+type SchedulingStrategy interface {
+	// Choose returns one of the given candidates, which is never empty.
+	Choose(candidates []*tenancyv1alpha1.ClusterWorkspaceShard) *tenancyv1alpha1.ClusterWorkspaceShard
+}
+
+// NewSchedulingStrategy returns the SchedulingStrategy of the given type.
+func NewSchedulingStrategy(strategy SchedulingStrategyType) (SchedulingStrategy, error) {
+	switch strategy {
+	case LeastLoadedSchedulingStrategy:
+		return newScoringStrategy(func(load float64) float64 { return -load }, rand.Intn), nil
+	case BinPackingSchedulingStrategy:
+		return newScoringStrategy(func(load float64) float64 { return load }, rand.Intn), nil
+	case RandomSchedulingStrategy:
+		return randomStrategy{}, nil
+	default:
+		return nil, fmt.Errorf("unknown scheduling strategy %q", strategy)
+	}
+}
+
+type randomStrategy struct{}
+
+func (randomStrategy) Choose(candidates []*tenancyv1alpha1.ClusterWorkspaceShard) *tenancyv1alpha1.ClusterWorkspaceShard {
+	return candidates[rand.Intn(len(candidates))]
+}
+
+func newScoringStrategy(score func(load float64) float64, intn func(int) int) *scoringStrategy {
+	return &scoringStrategy{
+		score:   score,
+		intn:    intn,
+		pending: map[string]pendingWorkspaces{},
+	}
+}
+
+// scoringStrategy picks the shard with the highest score computed from its load. Ties are
+// broken randomly.
+type scoringStrategy struct {
+	score func(load float64) float64
+	intn  func(int) int
+
+	lock sync.Mutex
+	// pending counts the workspaces scheduled onto a shard which are not reflected in the
+	// usage reported by the shard yet. Without it, all workspaces created between two
+	// usage reports would go to the same shard.
+	pending map[string]pendingWorkspaces
+}
+
+type pendingWorkspaces struct {
+	// reported is the workspace usage reported by the shard when the first pending
+	// workspace was scheduled. A new report resets the count.
+	reported int64
+	count    int64
+}
+
+func (s *scoringStrategy) Choose(candidates []*tenancyv1alpha1.ClusterWorkspaceShard) *tenancyv1alpha1.ClusterWorkspaceShard {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// shards without a capacity are compared relative to the most used candidate
+	maxUsage := map[corev1.ResourceName]int64{}
+	for _, shard := range candidates {
+		for _, name := range shardResources {
+			if usage := s.usage(shard, name); usage > maxUsage[name] {
+				maxUsage[name] = usage
+			}
+		}
+	}
+
+	var best []*tenancyv1alpha1.ClusterWorkspaceShard
+	var bestScore float64
+	for _, shard := range candidates {
+		score := s.score(s.load(shard, maxUsage))
+		switch {
+		case len(best) == 0 || score > bestScore:
+			best, bestScore = []*tenancyv1alpha1.ClusterWorkspaceShard{shard}, score
+		case score == bestScore:
+			best = append(best, shard)
+		}
+	}
+
+	chosen := best[s.intn(len(best))]
+
+	reported := reportedUsage(chosen, tenancyv1alpha1.ShardResourceWorkspaces)
+	p := s.pending[chosen.Name]
+	if p.reported != reported {
+		p = pendingWorkspaces{reported: reported}
+	}
+	p.count++
+	s.pending[chosen.Name] = p
+
+	return chosen
+}
+
+// load returns the highest utilization of any of the shard resources, between 0 and 1 for
+// shards that are not full.
+func (s *scoringStrategy) load(shard *tenancyv1alpha1.ClusterWorkspaceShard, maxUsage map[corev1.ResourceName]int64) float64 {
+	var load float64
+	for _, name := range shardResources {
+		usage := float64(s.usage(shard, name))
+
+		var utilization float64
+		if capacity, found := shard.Status.Capacity[name]; found && capacity.Value() > 0 {
+			utilization = usage / float64(capacity.Value())
+		} else if maxUsage[name] > 0 {
+			utilization = usage / float64(maxUsage[name])
+		}
+
+		if utilization > load {
+			load = utilization
+		}
+	}
+	return load
+}
+
+// usage returns the reported usage of the given resource, including the workspaces
+// scheduled since the last report.
+func (s *scoringStrategy) usage(shard *tenancyv1alpha1.ClusterWorkspaceShard, name corev1.ResourceName) int64 {
+	usage := reportedUsage(shard, name)
+	if name == tenancyv1alpha1.ShardResourceWorkspaces {
+		if p, found := s.pending[shard.Name]; found && p.reported == usage {
+			usage += p.count
+		}
+	}
+	return usage
+}
+
+func reportedUsage(shard *tenancyv1alpha1.ClusterWorkspaceShard, name corev1.ResourceName) int64 {
+	usage, found := shard.Status.Usage[name]
+	if !found {
+		return 0
+	}
+	return usage.Value()
+}
+
+// isFullShard returns whether the shard reported a usage reaching its capacity for any resource.
+func isFullShard(shard *tenancyv1alpha1.ClusterWorkspaceShard) (full bool, message string) {
+	for name, capacity := range shard.Status.Capacity {
+		usage, found := shard.Status.Usage[name]
+		if found && usage.Cmp(capacity) >= 0 {
+			return true, fmt.Sprintf("Usage of %s %s reached the capacity %s.", name, usage.String(), capacity.String())
+		}
+	}
+	return false, ""
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterworkspace
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+func TestScoringStrategy(t *testing.T) {
+	leastLoaded := func(load float64) float64 { return -load }
+	binPacking := func(load float64) float64 { return load }
+
+	tests := []struct {
+		name   string
+		score  func(load float64) float64
+		shards []*tenancyv1alpha1.ClusterWorkspaceShard
+		want   []string
+	}{
+		{
+			name:  "least-loaded relative to capacity",
+			score: leastLoaded,
+			shards: []*tenancyv1alpha1.ClusterWorkspaceShard{
+				withResources(corev1.ResourceList{"workspaces": resource.MustParse("100")}, corev1.ResourceList{"workspaces": resource.MustParse("50")}, shard("foo")),
+				withResources(corev1.ResourceList{"workspaces": resource.MustParse("1000")}, corev1.ResourceList{"workspaces": resource.MustParse("100")}, shard("bar")),
+			},
+			want: []string{"bar"},
+		},
+		{
+			name:  "bin-packing relative to capacity",
+			score: binPacking,
+			shards: []*tenancyv1alpha1.ClusterWorkspaceShard{
+				withResources(corev1.ResourceList{"workspaces": resource.MustParse("100")}, corev1.ResourceList{"workspaces": resource.MustParse("50")}, shard("foo")),
+				withResources(corev1.ResourceList{"workspaces": resource.MustParse("1000")}, corev1.ResourceList{"workspaces": resource.MustParse("100")}, shard("bar")),
+			},
+			want: []string{"foo"},
+		},
+		{
+			name:  "most utilized resource counts",
+			score: leastLoaded,
+			shards: []*tenancyv1alpha1.ClusterWorkspaceShard{
+				withResources(
+					corev1.ResourceList{"workspaces": resource.MustParse("100"), "database-size": resource.MustParse("8Gi")},
+					corev1.ResourceList{"workspaces": resource.MustParse("10"), "database-size": resource.MustParse("6Gi")},
+					shard("foo")),
+				withResources(
+					corev1.ResourceList{"workspaces": resource.MustParse("100"), "database-size": resource.MustParse("8Gi")},
+					corev1.ResourceList{"workspaces": resource.MustParse("50"), "database-size": resource.MustParse("1Gi")},
+					shard("bar")),
+			},
+			want: []string{"bar"},
+		},
+		{
+			name:  "without capacity relative to the other shards",
+			score: leastLoaded,
+			shards: []*tenancyv1alpha1.ClusterWorkspaceShard{
+				withResources(nil, corev1.ResourceList{"objects": resource.MustParse("5000")}, shard("foo")),
+				withResources(nil, corev1.ResourceList{"objects": resource.MustParse("2000")}, shard("bar")),
+			},
+			want: []string{"bar"},
+		},
+		{
+			name:  "pending workspaces are spread until the next report",
+			score: leastLoaded,
+			shards: []*tenancyv1alpha1.ClusterWorkspaceShard{
+				withResources(corev1.ResourceList{"workspaces": resource.MustParse("10")}, corev1.ResourceList{"workspaces": resource.MustParse("2")}, shard("foo")),
+				withResources(corev1.ResourceList{"workspaces": resource.MustParse("10")}, corev1.ResourceList{"workspaces": resource.MustParse("3")}, shard("bar")),
+			},
+			want: []string{"foo", "foo", "bar", "foo", "bar"},
+		},
+		{
+			name:  "no usage reported yet",
+			score: leastLoaded,
+			shards: []*tenancyv1alpha1.ClusterWorkspaceShard{
+				shard("foo"),
+				shard("bar"),
+			},
+			want: []string{"foo", "bar", "foo", "bar"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newScoringStrategy(tt.score, func(int) int { return 0 })
+			var got []string
+			for range tt.want {
+				got = append(got, s.Choose(tt.shards).Name)
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestScoringStrategyResetsPendingOnReport(t *testing.T) {
+	foo := withResources(nil, corev1.ResourceList{"workspaces": resource.MustParse("5")}, shard("foo"))
+	bar := withResources(nil, corev1.ResourceList{"workspaces": resource.MustParse("6")}, shard("bar"))
+
+	s := newScoringStrategy(func(load float64) float64 { return -load }, func(int) int { return 0 })
+	require.Equal(t, "foo", s.Choose([]*tenancyv1alpha1.ClusterWorkspaceShard{foo, bar}).Name)
+	require.Equal(t, "foo", s.Choose([]*tenancyv1alpha1.ClusterWorkspaceShard{foo, bar}).Name)
+	require.Equal(t, "bar", s.Choose([]*tenancyv1alpha1.ClusterWorkspaceShard{foo, bar}).Name, "foo has 7 workspaces with pending ones")
+
+	// foo reports the two new workspaces, bar did not report yet
+	foo = withResources(nil, corev1.ResourceList{"workspaces": resource.MustParse("7")}, shard("foo"))
+	require.Equal(t, "foo", s.Choose([]*tenancyv1alpha1.ClusterWorkspaceShard{foo, bar}).Name, "foo and bar both have 7 workspaces")
+	require.Equal(t, "bar", s.Choose([]*tenancyv1alpha1.ClusterWorkspaceShard{foo, bar}).Name)
+}
+
+func TestIsFullShard(t *testing.T) {
+	require.False(t, fullOnly(isFullShard(shard("foo"))))
+	require.False(t, fullOnly(isFullShard(withResources(
+		corev1.ResourceList{"database-size": resource.MustParse("8Gi")},
+		corev1.ResourceList{"database-size": resource.MustParse("7Gi"), "workspaces": resource.MustParse("100")},
+		shard("foo")))))
+	require.True(t, fullOnly(isFullShard(withResources(
+		corev1.ResourceList{"database-size": resource.MustParse("8Gi"), "workspaces": resource.MustParse("100")},
+		corev1.ResourceList{"database-size": resource.MustParse("7Gi"), "workspaces": resource.MustParse("100")},
+		shard("foo")))))
+}
+
+func fullOnly(full bool, _ string) bool {
+	return full
+}
+
+func withResources(capacity, usage corev1.ResourceList, shard *tenancyv1alpha1.ClusterWorkspaceShard) *tenancyv1alpha1.ClusterWorkspaceShard {
+	shard.Status.Capacity = capacity
+	shard.Status.Usage = usage
+	return shard
+}
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	jsonpatch "github.com/evanphx/json-patch"
@@ -41,10 +42,13 @@ import (
 
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
 	"github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1/helper"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
 	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/cluster"
 	tenancyv1alpha1informers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/tenancy/v1alpha1"
 	tenancyv1alpha1listers "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
 	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/clusterworkspace"
 	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/clusterworkspacedeletion/deletion"
 )
 
@@ -202,31 +206,95 @@ func (c *Controller) process(ctx context.Context, key string) error {
 	}
 
 	workspaceCopy := workspace.DeepCopy()
+	now := metav1.Now()
+	if !deletionRecorded(workspace) {
+		message := "Deletion requested."
+		if workspace.Status.Phase == tenancyv1alpha1.ClusterWorkspacePhaseDeleted && workspace.Status.Deletion != nil {
+			message = fmt.Sprintf("Retention period ended at %s.", workspace.Status.Deletion.RetainUntil.UTC().Format(time.RFC3339))
+		}
+		recordTransition(workspaceCopy, now, "Deleting", message)
+	}
+
+	// finalizing controllers clean up external resources before the content is deleted.
+	// They are notified by the finalizer labels, and we are requeued when they remove
+	// their finalizer.
+	if len(workspace.Status.Finalizers) > 0 {
+		finalizers := make([]string, 0, len(workspace.Status.Finalizers))
+		for _, finalizer := range workspace.Status.Finalizers {
+			finalizers = append(finalizers, string(finalizer))
+		}
+		logger.V(2).Info("waiting for ClusterWorkspace finalizers", "finalizers", finalizers)
+		conditions.MarkFalse(
+			workspaceCopy,
+			tenancyv1alpha1.WorkspaceFinalized,
+			tenancyv1alpha1.WorkspaceFinalizedFinalizerExists,
+			conditionsv1alpha1.ConditionSeverityInfo,
+			"Waiting for finalizers: %s", strings.Join(finalizers, ", "),
+		)
+		// finalizers are removed one by one, hence this is recorded again whenever one of them is done
+		recordTransition(workspaceCopy, now, "WaitingForFinalizers", fmt.Sprintf("Waiting for finalizers %s.", strings.Join(finalizers, ", ")))
+		return c.patchStatus(ctx, workspace, workspaceCopy)
+	}
+	conditions.MarkTrue(workspaceCopy, tenancyv1alpha1.WorkspaceFinalized)
+	recordTransition(workspaceCopy, now, "DeletingContent", "Finalized, deleting the content.")
 
 	logger.V(2).Info("deleting ClusterWorkspace")
 	startTime := time.Now()
 	deleteErr = c.deleter.Delete(ctx, workspaceCopy)
 	if deleteErr == nil {
 		logger.V(2).Info("finished deleting ClusterWorkspace content", "duration", time.Since(startTime))
-		return c.finalizeWorkspace(ctx, workspaceCopy)
+		if err := c.finalizeWorkspace(ctx, workspaceCopy); err != nil {
+			return err
+		}
+		// the workspace is gone, hence the last transitions are only recorded as Events
+		clusterworkspace.RecordHistoryEvents(ctx, c.kubeClusterClient, ControllerName, workspace, workspaceCopy)
+		clusterworkspace.RecordEvent(ctx, c.kubeClusterClient, ControllerName, workspaceCopy, tenancyv1alpha1.ClusterWorkspaceHistoryEntry{
+			Time:    metav1.Now(),
+			Phase:   workspaceCopy.Status.Phase,
+			Reason:  "Deleted",
+			Message: "Deleted the workspace and its content.",
+		})
+		return nil
 	}
 
-	if err := c.patchCondition(ctx, workspace, workspaceCopy); err != nil {
+	if err := c.patchStatus(ctx, workspace, workspaceCopy); err != nil {
 		return err
 	}
 
 	return deleteErr
 }
 
-func (c *Controller) patchCondition(ctx context.Context, old, new *tenancyv1alpha1.ClusterWorkspace) error {
+// recordTransition adds a deletion step to the history of the workspace, unless it is already
+// the latest entry.
+func recordTransition(workspace *tenancyv1alpha1.ClusterWorkspace, now metav1.Time, reason, message string) {
+	helper.AppendHistory(&workspace.Status, tenancyv1alpha1.ClusterWorkspaceHistoryEntry{
+		Time:    now,
+		Phase:   workspace.Status.Phase,
+		Reason:  reason,
+		Message: message,
+	})
+}
+
+// deletionRecorded returns whether the start of the deletion has been added to the history.
+func deletionRecorded(workspace *tenancyv1alpha1.ClusterWorkspace) bool {
+	for _, entry := range workspace.Status.History {
+		if entry.Reason == "Deleting" && !entry.Time.Before(workspace.DeletionTimestamp) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Controller) patchStatus(ctx context.Context, old, new *tenancyv1alpha1.ClusterWorkspace) error {
 	logger := klog.FromContext(ctx)
-	if equality.Semantic.DeepEqual(old.Status.Conditions, new.Status.Conditions) {
+	if equality.Semantic.DeepEqual(old.Status.Conditions, new.Status.Conditions) && equality.Semantic.DeepEqual(old.Status.History, new.Status.History) {
 		return nil
 	}
 
 	oldData, err := json.Marshal(tenancyv1alpha1.ClusterWorkspace{
 		Status: tenancyv1alpha1.ClusterWorkspaceStatus{
 			Conditions: old.Status.Conditions,
+			History:    old.Status.History,
 		},
 	})
 	if err != nil {
@@ -240,6 +308,7 @@ func (c *Controller) patchCondition(ctx context.Context, old, new *tenancyv1alph
 		}, // to ensure they appear in the patch as preconditions
 		Status: tenancyv1alpha1.ClusterWorkspaceStatus{
 			Conditions: new.Status.Conditions,
+			History:    new.Status.History,
 		},
 	})
 	if err != nil {
@@ -252,8 +321,11 @@ func (c *Controller) patchCondition(ctx context.Context, old, new *tenancyv1alph
 	}
 
 	logger.V(2).Info("patching ClusterWorkspace", "patch", string(patchBytes))
-	_, err = c.kcpClusterClient.Cluster(logicalcluster.From(new)).TenancyV1alpha1().ClusterWorkspaces().Patch(ctx, new.Name, types.MergePatchType, patchBytes, metav1.PatchOptions{}, "status")
-	return err
+	if _, err := c.kcpClusterClient.Cluster(logicalcluster.From(new)).TenancyV1alpha1().ClusterWorkspaces().Patch(ctx, new.Name, types.MergePatchType, patchBytes, metav1.PatchOptions{}, "status"); err != nil {
+		return err
+	}
+	clusterworkspace.RecordHistoryEvents(ctx, c.kubeClusterClient, ControllerName, old, new)
+	return nil
 }
 
 // finalizeNamespace removes the specified finalizer and finalizes the workspace
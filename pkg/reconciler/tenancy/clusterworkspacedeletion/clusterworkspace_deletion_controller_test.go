@@ -0,0 +1,54 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterworkspacedeletion
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+func TestDeletionRecorded(t *testing.T) {
+	deletion := metav1.NewTime(time.Date(2022, 12, 1, 0, 0, 0, 0, time.UTC))
+	entry := func(offset time.Duration, reason string) tenancyv1alpha1.ClusterWorkspaceHistoryEntry {
+		return tenancyv1alpha1.ClusterWorkspaceHistoryEntry{Time: metav1.NewTime(deletion.Add(offset)), Reason: reason}
+	}
+
+	tests := map[string]struct {
+		history []tenancyv1alpha1.ClusterWorkspaceHistoryEntry
+		want    bool
+	}{
+		"no history":                  {},
+		"not yet recorded":            {history: []tenancyv1alpha1.ClusterWorkspaceHistoryEntry{entry(-time.Hour, "Initialized")}},
+		"recorded":                    {history: []tenancyv1alpha1.ClusterWorkspaceHistoryEntry{entry(0, "Deleting"), entry(time.Second, "WaitingForFinalizers")}, want: true},
+		"recorded for an old request": {history: []tenancyv1alpha1.ClusterWorkspaceHistoryEntry{entry(-time.Hour, "Deleting")}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			workspace := &tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &deletion},
+				Status:     tenancyv1alpha1.ClusterWorkspaceStatus{History: tc.history},
+			}
+			require.Equal(t, tc.want, deletionRecorded(workspace))
+		})
+	}
+}
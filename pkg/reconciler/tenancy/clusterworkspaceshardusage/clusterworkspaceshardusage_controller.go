@@ -0,0 +1,175 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterworkspaceshardusage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/cluster"
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/migration"
+)
+
+const (
+	ControllerName = "kcp-clusterworkspaceshard-usage"
+)
+
+// NewController returns a controller periodically reporting the capacity and the usage of the
+// etcd of this shard in the status of its ClusterWorkspaceShard in the root workspace.
+func NewController(
+	shardName string,
+	capacity corev1.ResourceList,
+	rootKcpClient kcpclientset.ClusterInterface,
+	etcdClient *clientv3.Client,
+	tally *migration.Tally,
+) *Controller {
+	return &Controller{
+		shardName: shardName,
+		capacity:  capacity,
+		getShard: func(ctx context.Context, name string) (*tenancyv1alpha1.ClusterWorkspaceShard, error) {
+			return rootKcpClient.Cluster(tenancyv1alpha1.RootCluster).TenancyV1alpha1().ClusterWorkspaceShards().Get(ctx, name, metav1.GetOptions{})
+		},
+		patchShardStatus: func(ctx context.Context, name string, patch []byte) error {
+			_, err := rootKcpClient.Cluster(tenancyv1alpha1.RootCluster).TenancyV1alpha1().ClusterWorkspaceShards().Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{}, "status")
+			return err
+		},
+		measure: func(ctx context.Context) (corev1.ResourceList, error) {
+			return measureEtcd(ctx, etcdClient, tally)
+		},
+	}
+}
+
+// Controller reports the usage of this shard.
+type Controller struct {
+	shardName string
+	capacity  corev1.ResourceList
+
+	getShard         func(ctx context.Context, name string) (*tenancyv1alpha1.ClusterWorkspaceShard, error)
+	patchShardStatus func(ctx context.Context, name string, patch []byte) error
+	measure          func(ctx context.Context) (corev1.ResourceList, error)
+}
+
+// Start reports the usage every interval until the context is done.
+func (c *Controller) Start(ctx context.Context, interval time.Duration) {
+	defer runtime.HandleCrash()
+
+	logger := logging.WithReconciler(klog.FromContext(ctx), ControllerName).WithValues("shard", c.shardName)
+	ctx = klog.NewContext(ctx, logger)
+	logger.Info("Starting controller")
+	defer logger.Info("Shutting down controller")
+
+	wait.JitterUntilWithContext(ctx, func(ctx context.Context) {
+		if err := c.reconcile(ctx); err != nil {
+			runtime.HandleError(fmt.Errorf("%q controller failed to report usage of shard %q, err: %w", ControllerName, c.shardName, err))
+		}
+	}, interval, 0.1, true)
+}
+
+func (c *Controller) reconcile(ctx context.Context) error {
+	logger := klog.FromContext(ctx)
+
+	shard, err := c.getShard(ctx, c.shardName)
+	if apierrors.IsNotFound(err) {
+		logger.V(2).Info("ClusterWorkspaceShard does not exist yet, not reporting usage")
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	usage, err := c.measure(ctx)
+	if err != nil {
+		return err
+	}
+
+	if equality.Semantic.DeepEqual(shard.Status.Capacity, c.capacity) && equality.Semantic.DeepEqual(shard.Status.Usage, usage) {
+		return nil
+	}
+
+	oldData, err := json.Marshal(tenancyv1alpha1.ClusterWorkspaceShard{
+		Status: tenancyv1alpha1.ClusterWorkspaceShardStatus{
+			Capacity: shard.Status.Capacity,
+			Usage:    shard.Status.Usage,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to Marshal old data for workspace shard %s|%s: %w", tenancyv1alpha1.RootCluster, shard.Name, err)
+	}
+	newData, err := json.Marshal(tenancyv1alpha1.ClusterWorkspaceShard{
+		ObjectMeta: metav1.ObjectMeta{
+			UID: shard.UID,
+		}, // to ensure it appears in the patch as precondition
+		Status: tenancyv1alpha1.ClusterWorkspaceShardStatus{
+			Capacity: c.capacity,
+			Usage:    usage,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to Marshal new data for workspace shard %s|%s: %w", tenancyv1alpha1.RootCluster, shard.Name, err)
+	}
+	patchBytes, err := jsonpatch.CreateMergePatch(oldData, newData)
+	if err != nil {
+		return fmt.Errorf("failed to create patch for workspace shard %s|%s: %w", tenancyv1alpha1.RootCluster, shard.Name, err)
+	}
+
+	logger.WithValues("patch", string(patchBytes)).V(4).Info("patching ClusterWorkspaceShard usage")
+	return c.patchShardStatus(ctx, shard.Name, patchBytes)
+}
+
+// measureEtcd returns the number of workspaces and keys from the tally of the storage, and
+// the size of the etcd database.
+func measureEtcd(ctx context.Context, client *clientv3.Client, tally *migration.Tally) (corev1.ResourceList, error) {
+	clusters, keys, err := tally.Shard(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count etcd keys: %w", err)
+	}
+
+	var dbSize int64
+	for _, endpoint := range client.Endpoints() {
+		status, err := client.Status(ctx, endpoint)
+		if err != nil {
+			continue // try the next member
+		}
+		dbSize = status.DbSize
+		break
+	}
+	if dbSize == 0 {
+		return nil, fmt.Errorf("failed to get the etcd status from any of %v", client.Endpoints())
+	}
+
+	return corev1.ResourceList{
+		tenancyv1alpha1.ShardResourceWorkspaces:   *resource.NewQuantity(clusters, resource.DecimalSI),
+		tenancyv1alpha1.ShardResourceObjects:      *resource.NewQuantity(keys, resource.DecimalSI),
+		tenancyv1alpha1.ShardResourceDatabaseSize: *resource.NewQuantity(dbSize, resource.BinarySI),
+	}, nil
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterworkspaceshardusage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+func TestReconcile(t *testing.T) {
+	usage := corev1.ResourceList{
+		tenancyv1alpha1.ShardResourceWorkspaces:   resource.MustParse("10"),
+		tenancyv1alpha1.ShardResourceObjects:      resource.MustParse("1000"),
+		tenancyv1alpha1.ShardResourceDatabaseSize: resource.MustParse("1Mi"),
+	}
+	capacity := corev1.ResourceList{
+		tenancyv1alpha1.ShardResourceWorkspaces: resource.MustParse("100"),
+	}
+
+	tests := map[string]struct {
+		shard     *tenancyv1alpha1.ClusterWorkspaceShard
+		wantPatch string
+	}{
+		"shard does not exist": {},
+		"usage is reported": {
+			shard:     &tenancyv1alpha1.ClusterWorkspaceShard{ObjectMeta: metav1.ObjectMeta{Name: "alpha", UID: "uid"}},
+			wantPatch: `{"metadata":{"uid":"uid"},"status":{"capacity":{"workspaces":"100"},"usage":{"database-size":"1Mi","objects":"1k","workspaces":"10"}}}`,
+		},
+		"changed usage is reported": {
+			shard: &tenancyv1alpha1.ClusterWorkspaceShard{
+				ObjectMeta: metav1.ObjectMeta{Name: "alpha", UID: "uid"},
+				Status: tenancyv1alpha1.ClusterWorkspaceShardStatus{
+					Capacity: capacity,
+					Usage: corev1.ResourceList{
+						tenancyv1alpha1.ShardResourceWorkspaces:   resource.MustParse("9"),
+						tenancyv1alpha1.ShardResourceObjects:      resource.MustParse("1000"),
+						tenancyv1alpha1.ShardResourceDatabaseSize: resource.MustParse("1Mi"),
+					},
+				},
+			},
+			wantPatch: `{"metadata":{"uid":"uid"},"status":{"usage":{"workspaces":"10"}}}`,
+		},
+		"unchanged usage is not patched": {
+			shard: &tenancyv1alpha1.ClusterWorkspaceShard{
+				ObjectMeta: metav1.ObjectMeta{Name: "alpha", UID: "uid"},
+				Status:     tenancyv1alpha1.ClusterWorkspaceShardStatus{Capacity: capacity, Usage: usage},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var patch string
+			c := &Controller{
+				shardName: "alpha",
+				capacity:  capacity,
+				getShard: func(ctx context.Context, name string) (*tenancyv1alpha1.ClusterWorkspaceShard, error) {
+					if tc.shard == nil {
+						return nil, apierrors.NewNotFound(tenancyv1alpha1.Resource("clusterworkspaceshards"), name)
+					}
+					return tc.shard, nil
+				},
+				patchShardStatus: func(ctx context.Context, name string, p []byte) error {
+					require.Equal(t, "alpha", name)
+					patch = string(p)
+					return nil
+				},
+				measure: func(ctx context.Context) (corev1.ResourceList, error) {
+					return usage, nil
+				},
+			}
+
+			require.NoError(t, c.reconcile(context.Background()))
+			require.Equal(t, tc.wantPatch, patch)
+		})
+	}
+}
+
+func TestParseCapacity(t *testing.T) {
+	o := &Options{Capacity: map[string]string{"workspaces": "1000", "database-size": "8Gi"}}
+	capacity, err := o.ParseCapacity()
+	require.NoError(t, err)
+	require.Equal(t, corev1.ResourceList{
+		tenancyv1alpha1.ShardResourceWorkspaces:   resource.MustParse("1000"),
+		tenancyv1alpha1.ShardResourceDatabaseSize: resource.MustParse("8Gi"),
+	}, capacity)
+
+	o = &Options{Capacity: map[string]string{"workspace": "1000"}}
+	_, err = o.ParseCapacity()
+	require.EqualError(t, err, `--shard-capacity has unknown resource "workspace", must be one of [database-size objects workspaces]`)
+
+	o = &Options{Capacity: map[string]string{"objects": "many"}}
+	_, err = o.ParseCapacity()
+	require.Error(t, err)
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterworkspaceshardusage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+var capacityResources = sets.NewString(
+	string(tenancyv1alpha1.ShardResourceWorkspaces),
+	string(tenancyv1alpha1.ShardResourceObjects),
+	string(tenancyv1alpha1.ShardResourceDatabaseSize),
+)
+
+func DefaultOptions() *Options {
+	return &Options{
+		Capacity:       map[string]string{},
+		ReportInterval: time.Minute,
+	}
+}
+
+func BindOptions(o *Options, fs *pflag.FlagSet) *Options {
+	fs.StringToStringVar(&o.Capacity, "shard-capacity", o.Capacity, "Capacity of this shard reported in its ClusterWorkspaceShard status, e.g. workspaces=1000,objects=1000000,database-size=8Gi. Shards reaching their capacity are not scheduled new workspaces.")
+	fs.DurationVar(&o.ReportInterval, "shard-usage-report-interval", o.ReportInterval, "Interval in which the storage of this shard is measured, and its usage reported in its ClusterWorkspaceShard status. The measurement is shared with the quotas of the workspaces on this shard.")
+	return o
+}
+
+type Options struct {
+	Capacity       map[string]string
+	ReportInterval time.Duration
+}
+
+func (o *Options) Validate() error {
+	if _, err := o.ParseCapacity(); err != nil {
+		return err
+	}
+	if o.ReportInterval <= 0 {
+		return fmt.Errorf("--shard-usage-report-interval must be >0 (%s)", o.ReportInterval)
+	}
+	return nil
+}
+
+// ParseCapacity returns the capacity as a resource list.
+func (o *Options) ParseCapacity() (corev1.ResourceList, error) {
+	if len(o.Capacity) == 0 {
+		return nil, nil
+	}
+	capacity := make(corev1.ResourceList, len(o.Capacity))
+	for name, value := range o.Capacity {
+		if !capacityResources.Has(name) {
+			return nil, fmt.Errorf("--shard-capacity has unknown resource %q, must be one of %v", name, capacityResources.List())
+		}
+		q, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("--shard-capacity has invalid quantity %q for %q: %w", value, name, err)
+		}
+		capacity[corev1.ResourceName(name)] = q
+	}
+	return capacity, nil
+}
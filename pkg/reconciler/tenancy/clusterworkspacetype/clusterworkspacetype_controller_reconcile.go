@@ -26,11 +26,13 @@ import (
 	"k8s.io/klog/v2"
 
 	virtualworkspacesoptions "github.com/kcp-dev/kcp/cmd/virtual-workspaces/options"
+	"github.com/kcp-dev/kcp/pkg/apis/tenancy/finalization"
 	"github.com/kcp-dev/kcp/pkg/apis/tenancy/initialization"
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
 	conditionsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
 	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
 	"github.com/kcp-dev/kcp/pkg/virtual/initializingworkspaces"
+	"github.com/kcp-dev/kcp/pkg/virtual/terminatingworkspaces"
 )
 
 func (c *controller) reconcile(ctx context.Context, cwt *tenancyv1alpha1.ClusterWorkspaceType) {
@@ -72,14 +74,25 @@ func (c *controller) updateVirtualWorkspaceURLs(ctx context.Context, cwt *tenanc
 			continue
 		}
 
-		u.Path = path.Join(
+		initializingURL := *u
+		initializingURL.Path = path.Join(
 			u.Path,
 			virtualworkspacesoptions.DefaultRootPathPrefix,
 			initializingworkspaces.VirtualWorkspaceName,
 			string(initialization.InitializerForType(cwt)),
 		)
-
-		desiredURLs.Insert(u.String())
+		desiredURLs.Insert(initializingURL.String())
+
+		if cwt.Spec.Finalizer {
+			terminatingURL := *u
+			terminatingURL.Path = path.Join(
+				u.Path,
+				virtualworkspacesoptions.DefaultRootPathPrefix,
+				terminatingworkspaces.VirtualWorkspaceName,
+				string(finalization.FinalizerForType(cwt)),
+			)
+			desiredURLs.Insert(terminatingURL.String())
+		}
 	}
 
 	cwt.Status.VirtualWorkspaces = nil
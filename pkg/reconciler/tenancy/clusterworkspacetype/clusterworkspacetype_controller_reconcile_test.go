@@ -209,6 +209,50 @@ func TestReconcile(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "URLs of the terminating virtual workspace are added for finalizers",
+			shards: []*tenancyv1alpha1.ClusterWorkspaceShard{
+				{Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{ExternalURL: "https://item.com"}},
+			},
+			cwt: &tenancyv1alpha1.ClusterWorkspaceType{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "sometype",
+					Annotations: map[string]string{
+						logicalcluster.AnnotationKey: "root:org:team:ws",
+					},
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceTypeSpec{
+					Finalizer: true,
+				},
+			},
+			expected: &tenancyv1alpha1.ClusterWorkspaceType{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "sometype",
+					Annotations: map[string]string{
+						logicalcluster.AnnotationKey: "root:org:team:ws",
+					},
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceTypeSpec{
+					Finalizer: true,
+				},
+				Status: tenancyv1alpha1.ClusterWorkspaceTypeStatus{
+					VirtualWorkspaces: []tenancyv1alpha1.VirtualWorkspace{
+						{URL: "https://item.com/services/initializingworkspaces/root:org:team:ws:sometype"},
+						{URL: "https://item.com/services/terminatingworkspaces/root:org:team:ws:sometype"},
+					},
+					Conditions: conditionsv1alpha1.Conditions{
+						{
+							Type:   "Ready",
+							Status: "True",
+						},
+						{
+							Type:   "VirtualWorkspaceURLsReady",
+							Status: "True",
+						},
+					},
+				},
+			},
+		},
 	} {
 		t.Run(testCase.name, func(t *testing.T) {
 			testCase.cwts = append(testCase.cwts, testCase.cwt.DeepCopy())
@@ -0,0 +1,299 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initialization
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	kcpcache "github.com/kcp-dev/apimachinery/pkg/cache"
+	kcpdiscovery "github.com/kcp-dev/client-go/discovery"
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	kcpkubernetesclientset "github.com/kcp-dev/client-go/kubernetes"
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	admission "github.com/kcp-dev/kcp/pkg/admission/clusterworkspacetypeexists"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/cluster"
+	tenancyv1alpha1client "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/typed/tenancy/v1alpha1"
+	tenancyv1alpha1informers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/reconciler/committer"
+)
+
+const (
+	ClonerControllerName = "kcp-cloner-initializer"
+
+	// clonerRetryPeriod is the time after which a workspace is reconciled again when
+	// some objects could not be created yet because their APIs are not served yet.
+	clonerRetryPeriod = 10 * time.Second
+)
+
+// NewCloner returns a new controller which copies the content of the clone source and the
+// templates of the ClusterWorkspaceType into new ClusterWorkspaces.
+//
+// The source clients read from the shard directly, while the target clients are expected to
+// point to the initializing workspaces virtual workspace of the system:clone initializer.
+func NewCloner(
+	kcpClusterClient kcpclientset.ClusterInterface,
+	kubeClusterClient kcpkubernetesclientset.ClusterInterface,
+	sourceDiscoveryClient kcpdiscovery.DiscoveryClusterInterface,
+	sourceDynamicClient kcpdynamic.ClusterInterface,
+	targetDiscoveryClient kcpdiscovery.DiscoveryClusterInterface,
+	targetDynamicClient kcpdynamic.ClusterInterface,
+	clusterWorkspaceInformer tenancyv1alpha1informers.ClusterWorkspaceClusterInformer,
+	clusterWorkspaceTypeInformer tenancyv1alpha1informers.ClusterWorkspaceTypeClusterInformer,
+) (*Cloner, error) {
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), ClonerControllerName)
+
+	c := &Cloner{
+		queue: queue,
+
+		getClusterWorkspace: func(clusterName logicalcluster.Name) (*tenancyv1alpha1.ClusterWorkspace, error) {
+			parent, workspace := clusterName.Split()
+			return clusterWorkspaceInformer.Lister().Cluster(parent).Get(workspace)
+		},
+		getClusterWorkspaceType: func(clusterName logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspaceType, error) {
+			return clusterWorkspaceTypeInformer.Lister().Cluster(clusterName).Get(name)
+		},
+		listClusterWorkspaces: func() ([]*tenancyv1alpha1.ClusterWorkspace, error) {
+			return clusterWorkspaceInformer.Lister().List(labels.Everything())
+		},
+
+		listSourceObjects: func(ctx context.Context, clusterName logicalcluster.Name) ([]*unstructured.Unstructured, error) {
+			return listCloneSourceObjects(ctx, sourceDiscoveryClient.Cluster(clusterName), sourceDynamicClient.Cluster(clusterName))
+		},
+		getTemplate: func(ctx context.Context, clusterName logicalcluster.Name, namespace, name string) (*corev1.ConfigMap, error) {
+			return kubeClusterClient.Cluster(clusterName).CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+		},
+
+		getRESTMapper: func(clusterName logicalcluster.Name) (meta.RESTMapper, error) {
+			groupResources, err := restmapper.GetAPIGroupResources(targetDiscoveryClient.Cluster(clusterName))
+			if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+				return nil, err
+			}
+			return restmapper.NewDiscoveryRESTMapper(groupResources), nil
+		},
+		createObject: func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+			_, err := targetDynamicClient.Cluster(clusterName).Resource(gvr).Namespace(obj.GetNamespace()).Create(ctx, obj, metav1.CreateOptions{})
+			return err
+		},
+
+		requeueAfter: func(clusterWorkspace *tenancyv1alpha1.ClusterWorkspace, after time.Duration) {
+			key, err := kcpcache.MetaClusterNamespaceKeyFunc(clusterWorkspace)
+			if err != nil {
+				runtime.HandleError(err)
+				return
+			}
+			queue.AddAfter(key, after)
+		},
+
+		commit: committer.NewCommitter[*tenancyv1alpha1.ClusterWorkspace, tenancyv1alpha1client.ClusterWorkspaceInterface, *tenancyv1alpha1.ClusterWorkspaceSpec, *tenancyv1alpha1.ClusterWorkspaceStatus](kcpClusterClient.TenancyV1alpha1().ClusterWorkspaces()),
+	}
+
+	c.transitiveTypeResolver = admission.NewTransitiveTypeResolver(c.getClusterWorkspaceType)
+
+	logger := logging.WithReconciler(klog.Background(), ClonerControllerName)
+
+	clusterWorkspaceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueueClusterWorkspace(obj, logger)
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.enqueueClusterWorkspace(obj, logger)
+		},
+	})
+
+	clusterWorkspaceTypeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueueClusterWorkspaceType(obj, logger)
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			c.enqueueClusterWorkspaceType(obj, logger)
+		},
+	})
+
+	return c, nil
+}
+
+// Cloner is a controller which copies the content of the clone source and the templates of
+// the ClusterWorkspaceType into new ClusterWorkspaces.
+type Cloner struct {
+	queue workqueue.RateLimitingInterface
+
+	getClusterWorkspace     func(clusterName logicalcluster.Name) (*tenancyv1alpha1.ClusterWorkspace, error)
+	getClusterWorkspaceType func(clusterName logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspaceType, error)
+	listClusterWorkspaces   func() ([]*tenancyv1alpha1.ClusterWorkspace, error)
+
+	listSourceObjects func(ctx context.Context, clusterName logicalcluster.Name) ([]*unstructured.Unstructured, error)
+	getTemplate       func(ctx context.Context, clusterName logicalcluster.Name, namespace, name string) (*corev1.ConfigMap, error)
+
+	getRESTMapper func(clusterName logicalcluster.Name) (meta.RESTMapper, error)
+	createObject  func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error
+
+	requeueAfter func(clusterWorkspace *tenancyv1alpha1.ClusterWorkspace, after time.Duration)
+
+	transitiveTypeResolver transitiveTypeResolver
+
+	// commit creates a patch and submits it, if needed.
+	commit func(ctx context.Context, new, old *clusterWorkspaceResource) error
+}
+
+func (c *Cloner) enqueueClusterWorkspace(obj interface{}, logger logr.Logger) {
+	key, err := kcpcache.DeletionHandlingMetaClusterNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+
+	logging.WithQueueKey(logger, key).V(2).Info("queueing ClusterWorkspace")
+	c.queue.Add(key)
+}
+
+// enqueueClusterWorkspaceType enqueues all initializing clusterworkspaces whenever a clusterworkspacetype
+// with templates changes, such that a fixed template reference is picked up.
+func (c *Cloner) enqueueClusterWorkspaceType(obj interface{}, logger logr.Logger) {
+	cwt, ok := obj.(*tenancyv1alpha1.ClusterWorkspaceType)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("obj is supposed to be a ClusterWorkspaceType, but is %T", obj))
+		return
+	}
+
+	if len(cwt.Spec.Templates) == 0 {
+		return
+	}
+
+	list, err := c.listClusterWorkspaces()
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("error listing clusterworkspaces: %w", err))
+	}
+
+	for _, ws := range list {
+		logger := logging.WithObject(logger, ws)
+		c.enqueueClusterWorkspace(ws, logger)
+	}
+}
+
+func (c *Cloner) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *Cloner) Start(ctx context.Context, numThreads int) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+	logger := logging.WithReconciler(klog.FromContext(ctx), ClonerControllerName)
+	ctx = klog.NewContext(ctx, logger)
+
+	logger.Info("Starting controller")
+	defer logger.Info("Shutting down controller")
+
+	for i := 0; i < numThreads; i++ {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+	<-ctx.Done()
+}
+
+func (c *Cloner) ShutDown() {
+	c.queue.ShutDown()
+}
+
+func (c *Cloner) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	k, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	key := k.(string)
+
+	logger := logging.WithQueueKey(klog.FromContext(ctx), key)
+	ctx = klog.NewContext(ctx, logger)
+	logger.V(1).Info("processing key")
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.process(ctx, key); err != nil {
+		runtime.HandleError(fmt.Errorf("%s: failed to sync %q, err: %w", ClonerControllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *Cloner) process(ctx context.Context, key string) error {
+	logger := klog.FromContext(ctx)
+
+	parent, _, workspace, err := kcpcache.SplitMetaClusterNamespaceKey(key)
+	if err != nil {
+		logger.Error(err, "unable to decode key")
+		return nil
+	}
+
+	clusterName := parent.Join(workspace)
+
+	clusterWorkspace, err := c.getClusterWorkspace(clusterName)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			logger.Error(err, "failed to get ClusterWorkspace from lister", "parentCluster", parent, "clusterWorkspace", workspace)
+		}
+
+		return nil // nothing we can do here
+	}
+
+	old := clusterWorkspace
+	clusterWorkspace = clusterWorkspace.DeepCopy()
+
+	logger = logging.WithObject(logger, clusterWorkspace)
+	ctx = klog.NewContext(ctx, logger)
+
+	var errs []error
+	err = c.reconcile(ctx, clusterWorkspace)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	// If the object being reconciled changed as a result, update it.
+	oldResource := &clusterWorkspaceResource{ObjectMeta: old.ObjectMeta, Spec: &old.Spec, Status: &old.Status}
+	newResource := &clusterWorkspaceResource{ObjectMeta: clusterWorkspace.ObjectMeta, Spec: &clusterWorkspace.Spec, Status: &clusterWorkspace.Status}
+	if err := c.commit(ctx, oldResource, newResource); err != nil {
+		errs = append(errs, err)
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
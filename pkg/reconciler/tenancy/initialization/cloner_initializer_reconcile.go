@@ -0,0 +1,339 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initialization
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	kubeyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/tenancy/initialization"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
+	"github.com/kcp-dev/kcp/pkg/workspacecontent"
+)
+
+// cloneTier orders the copied objects such that APIs, namespaces and APIBindings are
+// created before the objects depending on them.
+type cloneTier int
+
+const (
+	cloneTierAPIs cloneTier = iota
+	cloneTierNamespaces
+	cloneTierAPIBindings
+	cloneTierObjects
+)
+
+// clusterWorkspacesResource is not copied from the clone source, i.e. child workspaces
+// are not cloned.
+var clusterWorkspacesResource = tenancyv1alpha1.SchemeGroupVersion.WithResource("clusterworkspaces").GroupResource()
+
+func (c *Cloner) reconcile(ctx context.Context, clusterWorkspace *tenancyv1alpha1.ClusterWorkspace) error {
+	logger := klog.FromContext(ctx)
+
+	clusterName := logicalcluster.From(clusterWorkspace).Join(clusterWorkspace.Name)
+	logger.V(2).Info("cloning content into workspace")
+
+	leafCWT, err := c.getClusterWorkspaceType(logicalcluster.New(clusterWorkspace.Spec.Type.Path), string(clusterWorkspace.Spec.Type.Name))
+	if err != nil {
+		logger.Error(err, "error getting ClusterWorkspaceType")
+
+		conditions.MarkFalse(
+			clusterWorkspace,
+			tenancyv1alpha1.WorkspaceContentCloned,
+			tenancyv1alpha1.WorkspaceInitializedClusterWorkspaceTypeInvalid,
+			conditionsv1alpha1.ConditionSeverityError,
+			"error getting ClusterWorkspaceType %s|%s: %v",
+			clusterWorkspace.Spec.Type.Path, clusterWorkspace.Spec.Type.Name,
+			err,
+		)
+
+		return nil
+	}
+
+	cwts, err := c.transitiveTypeResolver.Resolve(leafCWT)
+	if err != nil {
+		logger.Error(err, "error resolving transitive types")
+
+		conditions.MarkFalse(
+			clusterWorkspace,
+			tenancyv1alpha1.WorkspaceContentCloned,
+			tenancyv1alpha1.WorkspaceInitializedClusterWorkspaceTypeInvalid,
+			conditionsv1alpha1.ConditionSeverityError,
+			"error resolving transitive set of cluster workspace types: %v",
+			err,
+		)
+
+		return nil
+	}
+
+	// collect the objects to create, templates first
+	var objs []*unstructured.Unstructured
+	var errs []error
+	typeExports := map[apisv1alpha1.WorkspaceExportReference]bool{}
+	for _, cwt := range cwts {
+		for _, exportRef := range cwt.Spec.DefaultAPIBindings {
+			typeExports[apisv1alpha1.WorkspaceExportReference{Path: exportRef.Path, ExportName: exportRef.ExportName}] = true
+		}
+
+		for _, ref := range cwt.Spec.Templates {
+			cm, err := c.getTemplate(ctx, logicalcluster.From(cwt), ref.Namespace, ref.Name)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to get template %s|%s/%s: %w", logicalcluster.From(cwt), ref.Namespace, ref.Name, err))
+				continue
+			}
+			templateObjs, err := decodeTemplate(cm)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("invalid template %s|%s/%s: %w", logicalcluster.From(cwt), ref.Namespace, ref.Name, err))
+				continue
+			}
+			objs = append(objs, templateObjs...)
+		}
+	}
+
+	if clusterWorkspace.Spec.Clone != nil {
+		sourceObjs, err := c.listSourceObjects(ctx, logicalcluster.New(clusterWorkspace.Spec.Clone.Path))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read clone source %s: %w", clusterWorkspace.Spec.Clone.Path, err))
+		}
+		for _, obj := range sourceObjs {
+			// the APIBinding initializer binds the APIs of the type under a different name
+			if isAPIBindingFor(obj, typeExports) {
+				continue
+			}
+			objs = append(objs, obj)
+		}
+	}
+
+	if len(errs) > 0 {
+		logger.Error(utilerrors.NewAggregate(errs), "error reading clone sources")
+
+		conditions.MarkFalse(
+			clusterWorkspace,
+			tenancyv1alpha1.WorkspaceContentCloned,
+			tenancyv1alpha1.WorkspaceContentClonedSourceInvalid,
+			conditionsv1alpha1.ConditionSeverityError,
+			"encountered errors: %v",
+			utilerrors.NewAggregate(errs),
+		)
+
+		// Retry, as the clone source or templates might show up.
+		return utilerrors.NewAggregate(errs)
+	}
+
+	mapper, err := c.getRESTMapper(clusterName)
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(objs, func(i, j int) bool {
+		return cloneTierFor(objs[i].GroupVersionKind().GroupKind()) < cloneTierFor(objs[j].GroupVersionKind().GroupKind())
+	})
+
+	waitingOnKinds := sets.NewString()
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if meta.IsNoMatchError(err) {
+			// the API might be created or bound by an object of an earlier tier
+			waitingOnKinds.Insert(gvk.GroupKind().String())
+			continue
+		} else if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		obj = obj.DeepCopy()
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			if obj.GetNamespace() == "" {
+				obj.SetNamespace(metav1.NamespaceDefault)
+			}
+		} else {
+			obj.SetNamespace("")
+		}
+		unstructured.RemoveNestedField(obj.Object, "status")
+
+		if err := c.createObject(ctx, clusterName, mapping.Resource, obj); err != nil && !apierrors.IsAlreadyExists(err) {
+			errs = append(errs, fmt.Errorf("failed to create %s %s: %w", gvk.Kind, cloneObjectName(obj), err))
+			continue
+		}
+	}
+
+	if len(errs) > 0 {
+		logger.Error(utilerrors.NewAggregate(errs), "error cloning content")
+
+		conditions.MarkFalse(
+			clusterWorkspace,
+			tenancyv1alpha1.WorkspaceContentCloned,
+			tenancyv1alpha1.WorkspaceContentClonedErrors,
+			conditionsv1alpha1.ConditionSeverityError,
+			"encountered errors: %v",
+			utilerrors.NewAggregate(errs),
+		)
+
+		return utilerrors.NewAggregate(errs)
+	}
+
+	if waitingOnKinds.Len() > 0 {
+		conditions.MarkFalse(
+			clusterWorkspace,
+			tenancyv1alpha1.WorkspaceContentCloned,
+			tenancyv1alpha1.WorkspaceContentClonedWaitingOnAPIs,
+			conditionsv1alpha1.ConditionSeverityInfo,
+			"APIs not yet available: %s", strings.Join(waitingOnKinds.List(), ", "),
+		)
+
+		// APIs show up asynchronously, and we don't watch for them.
+		c.requeueAfter(clusterWorkspace, clonerRetryPeriod)
+		return nil
+	}
+
+	conditions.MarkTrue(clusterWorkspace, tenancyv1alpha1.WorkspaceContentCloned)
+	clusterWorkspace.Status.Initializers = initialization.EnsureInitializerAbsent(tenancyv1alpha1.ClusterWorkspaceCloneInitializer, clusterWorkspace.Status.Initializers)
+
+	return nil
+}
+
+func cloneTierFor(gk schema.GroupKind) cloneTier {
+	switch gk {
+	case schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"},
+		apisv1alpha1.Kind("APIResourceSchema"),
+		apisv1alpha1.Kind("APIExport"):
+		return cloneTierAPIs
+	case schema.GroupKind{Kind: "Namespace"}:
+		return cloneTierNamespaces
+	case apisv1alpha1.Kind("APIBinding"):
+		return cloneTierAPIBindings
+	}
+	return cloneTierObjects
+}
+
+// isAPIBindingFor returns true if the object is an APIBinding to one of the given APIExports.
+func isAPIBindingFor(obj *unstructured.Unstructured, exports map[apisv1alpha1.WorkspaceExportReference]bool) bool {
+	if obj.GroupVersionKind().GroupKind() != apisv1alpha1.Kind("APIBinding") {
+		return false
+	}
+	path, _, _ := unstructured.NestedString(obj.Object, "spec", "reference", "workspace", "path")
+	exportName, _, _ := unstructured.NestedString(obj.Object, "spec", "reference", "workspace", "exportName")
+	return exports[apisv1alpha1.WorkspaceExportReference{Path: path, ExportName: exportName}]
+}
+
+// decodeTemplate decodes the YAML documents in the data keys of the given ConfigMap,
+// in the order of the keys.
+func decodeTemplate(cm *corev1.ConfigMap) ([]*unstructured.Unstructured, error) {
+	keys := make([]string, 0, len(cm.Data))
+	for key := range cm.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var objs []*unstructured.Unstructured
+	for _, key := range keys {
+		dec := kubeyaml.NewYAMLOrJSONDecoder(bytes.NewBufferString(cm.Data[key]), 4096)
+		for i := 1; ; i++ {
+			obj := &unstructured.Unstructured{}
+			if err := dec.Decode(&obj.Object); errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("key %q, document %d: %w", key, i, err)
+			}
+			if len(obj.Object) == 0 {
+				continue
+			}
+			if obj.GetKind() == "" || obj.GetName() == "" {
+				return nil, fmt.Errorf("key %q, document %d: object with kind and name expected", key, i)
+			}
+			objs = append(objs, obj)
+		}
+	}
+	return objs, nil
+}
+
+// listCloneSourceObjects lists all objects in the clone source which can be created in
+// another workspace.
+func listCloneSourceObjects(ctx context.Context, discoveryClient discovery.DiscoveryInterface, dynamicClient dynamic.Interface) ([]*unstructured.Unstructured, error) {
+	resources, err := discoveryClient.ServerPreferredResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, err
+	}
+
+	var gvrs []schema.GroupVersionResource
+	for _, list := range resources {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, r := range list.APIResources {
+			if strings.Contains(r.Name, "/") || !sets.NewString(r.Verbs...).HasAll("list", "create") {
+				continue
+			}
+			gvr := gv.WithResource(r.Name)
+			if workspacecontent.SkippedResources[gvr.GroupResource()] || gvr.GroupResource() == clusterWorkspacesResource {
+				continue
+			}
+			gvrs = append(gvrs, gvr)
+		}
+	}
+	sort.Slice(gvrs, func(i, j int) bool {
+		return gvrs[i].String() < gvrs[j].String()
+	})
+
+	var objs []*unstructured.Unstructured
+	for _, gvr := range gvrs {
+		list, err := dynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", gvr.GroupResource(), err)
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			if workspacecontent.SkipObject(obj) {
+				continue
+			}
+			workspacecontent.Sanitize(obj)
+			objs = append(objs, obj)
+		}
+	}
+	return objs, nil
+}
+
+func cloneObjectName(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() == "" {
+		return obj.GetName()
+	}
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
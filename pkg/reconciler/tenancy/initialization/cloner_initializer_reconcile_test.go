@@ -0,0 +1,239 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initialization
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
+)
+
+func TestDecodeTemplate(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		Data: map[string]string{
+			"b.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: second\n",
+			"a.yaml": "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: first\n---\n---\napiVersion: v1\nkind: ServiceAccount\nmetadata:\n  name: bot\n  namespace: first\n",
+		},
+	}
+	objs, err := decodeTemplate(cm)
+	require.NoError(t, err)
+	var names []string
+	for _, obj := range objs {
+		names = append(names, obj.GetKind()+"/"+obj.GetName())
+	}
+	require.Equal(t, []string{"Namespace/first", "ServiceAccount/bot", "ConfigMap/second"}, names)
+
+	_, err = decodeTemplate(&corev1.ConfigMap{Data: map[string]string{"a.yaml": "apiVersion: v1\nkind: ConfigMap\n"}})
+	require.Error(t, err)
+}
+
+func TestClonerReconcile(t *testing.T) {
+	namespace := newUnstructured("v1", "Namespace", "", "preview")
+	configMap := newUnstructured("v1", "ConfigMap", "preview", "settings")
+	widget := newUnstructured("example.io/v1", "Widget", "preview", "w")
+	typeBinding := newUnstructured("apis.kcp.dev/v1alpha1", "APIBinding", "", "kubernetes-1234")
+	typeBinding.Object["spec"] = map[string]interface{}{"reference": map[string]interface{}{"workspace": map[string]interface{}{"path": "root", "exportName": "kubernetes"}}}
+
+	tests := map[string]struct {
+		clone           *tenancyv1alpha1.ClusterWorkspaceCloneSource
+		templates       []tenancyv1alpha1.ClusterWorkspaceTemplateReference
+		sourceObjects   []*unstructured.Unstructured
+		sourceErr       error
+		createErr       error
+		existing        []string
+		wantCreated     []string
+		wantInitialized bool
+		wantReason      string
+		wantRequeue     bool
+		wantErr         bool
+	}{
+		"no clone source and no templates": {
+			wantInitialized: true,
+		},
+		"clones source in tier order": {
+			clone:           &tenancyv1alpha1.ClusterWorkspaceCloneSource{Path: "root:org:template"},
+			sourceObjects:   []*unstructured.Unstructured{configMap, namespace},
+			wantCreated:     []string{"Namespace /preview", "ConfigMap preview/settings"},
+			wantInitialized: true,
+		},
+		"skips APIBindings created by the APIBinding initializer": {
+			clone:           &tenancyv1alpha1.ClusterWorkspaceCloneSource{Path: "root:org:template"},
+			sourceObjects:   []*unstructured.Unstructured{typeBinding, namespace},
+			wantCreated:     []string{"Namespace /preview"},
+			wantInitialized: true,
+		},
+		"creates templates": {
+			templates:       []tenancyv1alpha1.ClusterWorkspaceTemplateReference{{Namespace: "default", Name: "manifests"}},
+			wantCreated:     []string{"Namespace /templated", "ConfigMap default/templated"},
+			wantInitialized: true,
+		},
+		"ignores existing objects": {
+			clone:           &tenancyv1alpha1.ClusterWorkspaceCloneSource{Path: "root:org:template"},
+			sourceObjects:   []*unstructured.Unstructured{namespace, configMap},
+			existing:        []string{"Namespace /preview"},
+			wantCreated:     []string{"ConfigMap preview/settings"},
+			wantInitialized: true,
+		},
+		"waits for unknown APIs": {
+			clone:         &tenancyv1alpha1.ClusterWorkspaceCloneSource{Path: "root:org:template"},
+			sourceObjects: []*unstructured.Unstructured{namespace, widget},
+			wantCreated:   []string{"Namespace /preview"},
+			wantReason:    tenancyv1alpha1.WorkspaceContentClonedWaitingOnAPIs,
+			wantRequeue:   true,
+		},
+		"fails on unreadable source": {
+			clone:      &tenancyv1alpha1.ClusterWorkspaceCloneSource{Path: "root:org:template"},
+			sourceErr:  apierrors.NewForbidden(schema.GroupResource{}, "", nil),
+			wantReason: tenancyv1alpha1.WorkspaceContentClonedSourceInvalid,
+			wantErr:    true,
+		},
+		"fails on missing template": {
+			templates:  []tenancyv1alpha1.ClusterWorkspaceTemplateReference{{Namespace: "default", Name: "missing"}},
+			wantReason: tenancyv1alpha1.WorkspaceContentClonedSourceInvalid,
+			wantErr:    true,
+		},
+		"fails on create errors": {
+			clone:         &tenancyv1alpha1.ClusterWorkspaceCloneSource{Path: "root:org:template"},
+			sourceObjects: []*unstructured.Unstructured{namespace},
+			createErr:     apierrors.NewBadRequest("invalid"),
+			wantReason:    tenancyv1alpha1.WorkspaceContentClonedErrors,
+			wantErr:       true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cwt := &tenancyv1alpha1.ClusterWorkspaceType{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "preview",
+					Annotations: map[string]string{logicalcluster.AnnotationKey: "root:org"},
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceTypeSpec{
+					DefaultAPIBindings: []tenancyv1alpha1.APIExportReference{{Path: "root", ExportName: "kubernetes"}},
+					Templates:          tt.templates,
+				},
+			}
+			ws := &tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test",
+					Annotations: map[string]string{logicalcluster.AnnotationKey: "root:org"},
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					Type:  tenancyv1alpha1.ClusterWorkspaceTypeReference{Path: "root:org", Name: "preview"},
+					Clone: tt.clone,
+				},
+				Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase:        tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+					Initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{tenancyv1alpha1.ClusterWorkspaceCloneInitializer},
+				},
+			}
+
+			mapper := meta.NewDefaultRESTMapper(nil)
+			mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+			mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+			mapper.Add(schema.GroupVersionKind{Group: "apis.kcp.dev", Version: "v1alpha1", Kind: "APIBinding"}, meta.RESTScopeRoot)
+
+			existing := map[string]bool{}
+			for _, e := range tt.existing {
+				existing[e] = true
+			}
+			var created []string
+			requeued := false
+			c := &Cloner{
+				getClusterWorkspaceType: func(clusterName logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspaceType, error) {
+					return cwt, nil
+				},
+				listSourceObjects: func(ctx context.Context, clusterName logicalcluster.Name) ([]*unstructured.Unstructured, error) {
+					require.Equal(t, "root:org:template", clusterName.String())
+					return tt.sourceObjects, tt.sourceErr
+				},
+				getTemplate: func(ctx context.Context, clusterName logicalcluster.Name, namespace, name string) (*corev1.ConfigMap, error) {
+					require.Equal(t, "root:org", clusterName.String())
+					if name != "manifests" {
+						return nil, apierrors.NewNotFound(corev1.Resource("configmaps"), name)
+					}
+					return &corev1.ConfigMap{Data: map[string]string{
+						"manifests.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: templated\n---\napiVersion: v1\nkind: Namespace\nmetadata:\n  name: templated\n",
+					}}, nil
+				},
+				getRESTMapper: func(clusterName logicalcluster.Name) (meta.RESTMapper, error) {
+					return mapper, nil
+				},
+				createObject: func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+					require.Equal(t, "root:org:test", clusterName.String())
+					if tt.createErr != nil {
+						return tt.createErr
+					}
+					key := obj.GetKind() + " " + obj.GetNamespace() + "/" + obj.GetName()
+					if existing[key] {
+						return apierrors.NewAlreadyExists(gvr.GroupResource(), obj.GetName())
+					}
+					created = append(created, key)
+					return nil
+				},
+				requeueAfter: func(clusterWorkspace *tenancyv1alpha1.ClusterWorkspace, after time.Duration) {
+					requeued = true
+				},
+			}
+			c.transitiveTypeResolver = fakeTransitiveTypeResolver{}
+
+			err := c.reconcile(context.Background(), ws)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantCreated, created)
+			require.Equal(t, tt.wantRequeue, requeued)
+			if tt.wantInitialized {
+				require.Empty(t, ws.Status.Initializers)
+				require.True(t, conditions.IsTrue(ws, tenancyv1alpha1.WorkspaceContentCloned))
+			} else {
+				require.Equal(t, []tenancyv1alpha1.ClusterWorkspaceInitializer{tenancyv1alpha1.ClusterWorkspaceCloneInitializer}, ws.Status.Initializers)
+				require.Equal(t, tt.wantReason, conditions.GetReason(ws, tenancyv1alpha1.WorkspaceContentCloned))
+			}
+		})
+	}
+}
+
+type fakeTransitiveTypeResolver struct{}
+
+func (fakeTransitiveTypeResolver) Resolve(t *tenancyv1alpha1.ClusterWorkspaceType) ([]*tenancyv1alpha1.ClusterWorkspaceType, error) {
+	return []*tenancyv1alpha1.ClusterWorkspaceType{t}, nil
+}
+
+func newUnstructured(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
@@ -0,0 +1,307 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package partitionset
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	kcpcache "github.com/kcp-dev/apimachinery/pkg/cache"
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	topologyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/topology/v1alpha1"
+	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/cluster"
+	topologyv1alpha1client "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/typed/topology/v1alpha1"
+	tenancyv1alpha1informers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	topologyv1alpha1informers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/topology/v1alpha1"
+	topologyv1alpha1listers "github.com/kcp-dev/kcp/pkg/client/listers/topology/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/reconciler/committer"
+)
+
+const (
+	ControllerName = "kcp-partitionset"
+)
+
+// NewController returns a new controller for PartitionSets.
+// ClusterWorkspaceShards are read from the cache server.
+func NewController(
+	kcpClusterClient kcpclientset.ClusterInterface,
+	partitionSetInformer topologyv1alpha1informers.PartitionSetClusterInformer,
+	partitionInformer topologyv1alpha1informers.PartitionClusterInformer,
+	globalClusterWorkspaceShardInformer tenancyv1alpha1informers.ClusterWorkspaceShardClusterInformer,
+) (*controller, error) {
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), ControllerName)
+
+	c := &controller{
+		queue:              queue,
+		partitionSetLister: partitionSetInformer.Lister(),
+		listClusterWorkspaceShards: func(selector labels.Selector) ([]*tenancyv1alpha1.ClusterWorkspaceShard, error) {
+			return globalClusterWorkspaceShardInformer.Lister().List(selector)
+		},
+		listPartitions: func(clusterName logicalcluster.Name) ([]*topologyv1alpha1.Partition, error) {
+			return partitionInformer.Lister().Cluster(clusterName).List(labels.Everything())
+		},
+		createPartition: func(ctx context.Context, clusterName logicalcluster.Name, partition *topologyv1alpha1.Partition) error {
+			_, err := kcpClusterClient.Cluster(clusterName).TopologyV1alpha1().Partitions().Create(ctx, partition, metav1.CreateOptions{})
+			return err
+		},
+		updatePartition: func(ctx context.Context, clusterName logicalcluster.Name, partition *topologyv1alpha1.Partition) error {
+			_, err := kcpClusterClient.Cluster(clusterName).TopologyV1alpha1().Partitions().Update(ctx, partition, metav1.UpdateOptions{})
+			return err
+		},
+		deletePartition: func(ctx context.Context, clusterName logicalcluster.Name, name string) error {
+			return kcpClusterClient.Cluster(clusterName).TopologyV1alpha1().Partitions().Delete(ctx, name, metav1.DeleteOptions{})
+		},
+		commit: committer.NewCommitter[*PartitionSet, Patcher, *PartitionSetSpec, *PartitionSetStatus](kcpClusterClient.TopologyV1alpha1().PartitionSets()),
+	}
+
+	partitionSetInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueuePartitionSet(obj)
+		},
+		UpdateFunc: func(_, newObj interface{}) {
+			c.enqueuePartitionSet(newObj)
+		},
+	})
+
+	partitionInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueueFromPartition(obj)
+		},
+		UpdateFunc: func(_, newObj interface{}) {
+			c.enqueueFromPartition(newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.enqueueFromPartition(obj)
+		},
+	})
+
+	globalClusterWorkspaceShardInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueueAllPartitionSets(obj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldShard, ok := oldObj.(*tenancyv1alpha1.ClusterWorkspaceShard)
+			if !ok {
+				return
+			}
+			newShard, ok := newObj.(*tenancyv1alpha1.ClusterWorkspaceShard)
+			if !ok {
+				return
+			}
+			// Only labels matter for partitioning.
+			if labels.Equals(oldShard.Labels, newShard.Labels) {
+				return
+			}
+			c.enqueueAllPartitionSets(newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.enqueueAllPartitionSets(obj)
+		},
+	})
+
+	return c, nil
+}
+
+type PartitionSet = topologyv1alpha1.PartitionSet
+type PartitionSetSpec = topologyv1alpha1.PartitionSetSpec
+type PartitionSetStatus = topologyv1alpha1.PartitionSetStatus
+type Patcher = topologyv1alpha1client.PartitionSetInterface
+type Resource = committer.Resource[*PartitionSetSpec, *PartitionSetStatus]
+type CommitFunc = func(context.Context, *Resource, *Resource) error
+
+// controller reconciles PartitionSets. It generates one Partition per distinct combination of
+// label values for the dimensions of the PartitionSet across the selected ClusterWorkspaceShards.
+type controller struct {
+	queue workqueue.RateLimitingInterface
+
+	partitionSetLister topologyv1alpha1listers.PartitionSetClusterLister
+
+	listClusterWorkspaceShards func(selector labels.Selector) ([]*tenancyv1alpha1.ClusterWorkspaceShard, error)
+	listPartitions             func(clusterName logicalcluster.Name) ([]*topologyv1alpha1.Partition, error)
+	createPartition            func(ctx context.Context, clusterName logicalcluster.Name, partition *topologyv1alpha1.Partition) error
+	updatePartition            func(ctx context.Context, clusterName logicalcluster.Name, partition *topologyv1alpha1.Partition) error
+	deletePartition            func(ctx context.Context, clusterName logicalcluster.Name, name string) error
+
+	commit CommitFunc
+}
+
+// enqueuePartitionSet enqueues a PartitionSet.
+func (c *controller) enqueuePartitionSet(obj interface{}) {
+	key, err := kcpcache.DeletionHandlingMetaClusterNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+
+	logger := logging.WithQueueKey(logging.WithReconciler(klog.Background(), ControllerName), key)
+	logger.V(4).Info("queueing PartitionSet")
+	c.queue.Add(key)
+}
+
+// enqueueFromPartition enqueues the PartitionSet owning a Partition, e.g. when the Partition
+// got modified or deleted by somebody else.
+func (c *controller) enqueueFromPartition(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	partition, ok := obj.(*topologyv1alpha1.Partition)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("obj is supposed to be a Partition, but is %T", obj))
+		return
+	}
+
+	owner := metav1.GetControllerOf(partition)
+	if owner == nil || owner.Kind != "PartitionSet" || owner.APIVersion != topologyv1alpha1.SchemeGroupVersion.String() {
+		return
+	}
+
+	key := kcpcache.ToClusterAwareKey(logicalcluster.From(partition).String(), "", owner.Name)
+	logger := logging.WithObject(logging.WithReconciler(klog.Background(), ControllerName), partition)
+	logging.WithQueueKey(logger, key).V(2).Info("queueing PartitionSet because of Partition")
+	c.queue.Add(key)
+}
+
+// enqueueAllPartitionSets enqueues all PartitionSets when a ClusterWorkspaceShard changed,
+// as any of them could select the shard.
+func (c *controller) enqueueAllPartitionSets(clusterWorkspaceShard interface{}) {
+	list, err := c.partitionSetLister.List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+
+	if tombstone, ok := clusterWorkspaceShard.(cache.DeletedFinalStateUnknown); ok {
+		clusterWorkspaceShard = tombstone.Obj
+	}
+	shard, ok := clusterWorkspaceShard.(*tenancyv1alpha1.ClusterWorkspaceShard)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("obj is supposed to be a ClusterWorkspaceShard, but is %T", clusterWorkspaceShard))
+		return
+	}
+
+	logger := logging.WithObject(logging.WithReconciler(klog.Background(), ControllerName), shard)
+	for i := range list {
+		key, err := kcpcache.MetaClusterNamespaceKeyFunc(list[i])
+		if err != nil {
+			runtime.HandleError(err)
+			continue
+		}
+
+		logging.WithQueueKey(logger, key).V(2).Info("queuing PartitionSet because ClusterWorkspaceShard changed")
+		c.queue.Add(key)
+	}
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *controller) Start(ctx context.Context, numThreads int) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	logger := logging.WithReconciler(klog.FromContext(ctx), ControllerName)
+	ctx = klog.NewContext(ctx, logger)
+	logger.Info("Starting controller")
+	defer logger.Info("Shutting down controller")
+
+	for i := 0; i < numThreads; i++ {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	k, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	key := k.(string)
+
+	logger := logging.WithQueueKey(klog.FromContext(ctx), key)
+	ctx = klog.NewContext(ctx, logger)
+	logger.V(4).Info("processing key")
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.process(ctx, key); err != nil {
+		runtime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", ControllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *controller) process(ctx context.Context, key string) error {
+	cluster, _, name, err := kcpcache.SplitMetaClusterNamespaceKey(key)
+	if err != nil {
+		runtime.HandleError(err)
+		return nil
+	}
+
+	obj, err := c.partitionSetLister.Cluster(cluster).Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil // object deleted before we handled it, Partitions are garbage collected via owner references
+		}
+		return err
+	}
+
+	old := obj
+	obj = obj.DeepCopy()
+
+	logger := logging.WithObject(klog.FromContext(ctx), obj)
+	ctx = klog.NewContext(ctx, logger)
+
+	var errs []error
+	if err := c.reconcile(ctx, obj); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Regardless of whether reconcile returned an error or not, always try to patch status if needed. Return the
+	// reconciliation error at the end.
+
+	// If the object being reconciled changed as a result, update it.
+	oldResource := &Resource{ObjectMeta: old.ObjectMeta, Spec: &old.Spec, Status: &old.Status}
+	newResource := &Resource{ObjectMeta: obj.ObjectMeta, Spec: &obj.Spec, Status: &obj.Status}
+	if err := c.commit(ctx, oldResource, newResource); err != nil {
+		errs = append(errs, err)
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
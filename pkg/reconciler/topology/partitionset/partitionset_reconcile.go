@@ -0,0 +1,226 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package partitionset
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
+	topologyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/topology/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+)
+
+func (c *controller) reconcile(ctx context.Context, partitionSet *topologyv1alpha1.PartitionSet) error {
+	logger := klog.FromContext(ctx)
+	clusterName := logicalcluster.From(partitionSet)
+
+	selector := labels.Everything()
+	if partitionSet.Spec.Selector != nil {
+		var err error
+		selector, err = metav1.LabelSelectorAsSelector(partitionSet.Spec.Selector)
+		if err != nil {
+			conditions.MarkFalse(
+				partitionSet,
+				topologyv1alpha1.PartitionSetValid,
+				topologyv1alpha1.PartitionSetInvalidSelectorReason,
+				conditionsv1alpha1.ConditionSeverityError,
+				"Invalid shard selector: %v",
+				err,
+			)
+			return nil
+		}
+	}
+	conditions.MarkTrue(partitionSet, topologyv1alpha1.PartitionSetValid)
+
+	shards, err := c.listClusterWorkspaceShards(selector)
+	if err != nil {
+		conditions.MarkFalse(
+			partitionSet,
+			topologyv1alpha1.PartitionsReady,
+			topologyv1alpha1.ErrorGeneratingPartitionsReason,
+			conditionsv1alpha1.ConditionSeverityError,
+			"Error listing ClusterWorkspaceShards: %v",
+			err,
+		)
+		return err
+	}
+	desired := desiredPartitions(partitionSet, shards)
+
+	partitions, err := c.listPartitions(clusterName)
+	if err != nil {
+		conditions.MarkFalse(
+			partitionSet,
+			topologyv1alpha1.PartitionsReady,
+			topologyv1alpha1.ErrorGeneratingPartitionsReason,
+			conditionsv1alpha1.ConditionSeverityError,
+			"Error listing Partitions: %v",
+			err,
+		)
+		return err
+	}
+
+	var errs []error
+	existing := map[string]bool{}
+	for _, partition := range partitions {
+		if !metav1.IsControlledBy(partition, partitionSet) {
+			continue
+		}
+		logger := logging.WithObject(logger, partition)
+
+		key := partitionKey(partitionSet.Spec.Dimensions, matchLabels(partition.Spec.Selector))
+		want, found := desired[key]
+		if !found || existing[key] {
+			// Either no shard carries these dimension values anymore or this is a duplicate.
+			logger.V(2).Info("deleting Partition")
+			if err := c.deletePartition(ctx, clusterName, partition.Name); err != nil && !apierrors.IsNotFound(err) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		existing[key] = true
+
+		if !equality.Semantic.DeepEqual(partition.Spec, want.Spec) {
+			updated := partition.DeepCopy()
+			updated.Spec = want.Spec
+			logger.V(2).Info("updating Partition")
+			if err := c.updatePartition(ctx, clusterName, updated); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(desired))
+	for key := range desired {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if existing[key] {
+			continue
+		}
+		logger.V(2).Info("creating Partition", "dimensions", key)
+		if err := c.createPartition(ctx, clusterName, desired[key]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		err := utilerrors.NewAggregate(errs)
+		conditions.MarkFalse(
+			partitionSet,
+			topologyv1alpha1.PartitionsReady,
+			topologyv1alpha1.ErrorGeneratingPartitionsReason,
+			conditionsv1alpha1.ConditionSeverityError,
+			"Error reconciling Partitions: %v",
+			err,
+		)
+		return err
+	}
+
+	partitionSet.Status.Count = uint16(len(desired))
+	conditions.MarkTrue(partitionSet, topologyv1alpha1.PartitionsReady)
+
+	return nil
+}
+
+// desiredPartitions returns one Partition per distinct combination of values of the dimension labels
+// across the shards, keyed by partitionKey. Shards missing one of the dimension labels are skipped.
+func desiredPartitions(partitionSet *topologyv1alpha1.PartitionSet, shards []*tenancyv1alpha1.ClusterWorkspaceShard) map[string]*topologyv1alpha1.Partition {
+	desired := map[string]*topologyv1alpha1.Partition{}
+	for _, shard := range shards {
+		values := map[string]string{}
+		complete := true
+		for _, dimension := range partitionSet.Spec.Dimensions {
+			value, ok := shard.Labels[dimension]
+			if !ok {
+				complete = false
+				break
+			}
+			values[dimension] = value
+		}
+		if !complete {
+			continue
+		}
+
+		key := partitionKey(partitionSet.Spec.Dimensions, values)
+		if _, found := desired[key]; found {
+			continue
+		}
+		desired[key] = generatePartition(partitionSet, values)
+	}
+	return desired
+}
+
+// generatePartition creates a Partition owned by the PartitionSet whose selector combines the selector of
+// the PartitionSet with the given dimension values.
+func generatePartition(partitionSet *topologyv1alpha1.PartitionSet, values map[string]string) *topologyv1alpha1.Partition {
+	selector := &metav1.LabelSelector{}
+	if partitionSet.Spec.Selector != nil {
+		selector = partitionSet.Spec.Selector.DeepCopy()
+	}
+	if len(values) > 0 && selector.MatchLabels == nil {
+		selector.MatchLabels = map[string]string{}
+	}
+	for k, v := range values {
+		selector.MatchLabels[k] = v
+	}
+
+	return &topologyv1alpha1.Partition{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: partitionSet.Name + "-",
+			Annotations: map[string]string{
+				logicalcluster.AnnotationKey: logicalcluster.From(partitionSet).String(),
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(partitionSet, topologyv1alpha1.SchemeGroupVersion.WithKind("PartitionSet")),
+			},
+		},
+		Spec: topologyv1alpha1.PartitionSpec{
+			Selector: selector,
+		},
+	}
+}
+
+// partitionKey returns a string identifying the values of the given dimensions, in the order of the dimensions.
+func partitionKey(dimensions []string, values map[string]string) string {
+	parts := make([]string, 0, len(dimensions))
+	for _, dimension := range dimensions {
+		parts = append(parts, fmt.Sprintf("%s=%s", dimension, values[dimension]))
+	}
+	return strings.Join(parts, ",")
+}
+
+func matchLabels(selector *metav1.LabelSelector) map[string]string {
+	if selector == nil {
+		return nil
+	}
+	return selector.MatchLabels
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package partitionset
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
+	topologyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/topology/v1alpha1"
+)
+
+func TestReconcile(t *testing.T) {
+	shard := func(name string, l map[string]string) *tenancyv1alpha1.ClusterWorkspaceShard {
+		return &tenancyv1alpha1.ClusterWorkspaceShard{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{logicalcluster.AnnotationKey: "root"},
+				Name:        name,
+				Labels:      l,
+			},
+		}
+	}
+	shards := []*tenancyv1alpha1.ClusterWorkspaceShard{
+		shard("shard1", map[string]string{"region": "eu", "cloud": "aws", "tier": "prod"}),
+		shard("shard2", map[string]string{"region": "eu", "cloud": "aws", "tier": "prod"}),
+		shard("shard3", map[string]string{"region": "us", "cloud": "aws", "tier": "prod"}),
+		shard("shard4", map[string]string{"region": "us", "cloud": "gcp", "tier": "dev"}),
+		shard("shard5", map[string]string{"cloud": "gcp", "tier": "prod"}),
+	}
+
+	partitionSet := &topologyv1alpha1.PartitionSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{logicalcluster.AnnotationKey: "root:org"},
+			Name:        "regions",
+			UID:         types.UID("set-uid"),
+		},
+	}
+	ownedPartition := func(name string, matchLabels map[string]string) *topologyv1alpha1.Partition {
+		p := generatePartition(partitionSet, matchLabels)
+		p.GenerateName = ""
+		p.Name = name
+		return p
+	}
+
+	tests := map[string]struct {
+		dimensions []string
+		selector   *metav1.LabelSelector
+		existing   []*topologyv1alpha1.Partition
+
+		wantCreated         []map[string]string
+		wantUpdated         []string
+		wantDeleted         []string
+		wantCount           uint16
+		wantInvalidSelector bool
+	}{
+		"one partition per region": {
+			dimensions: []string{"region"},
+			wantCreated: []map[string]string{
+				{"region": "eu"},
+				{"region": "us"},
+			},
+			wantCount: 2,
+		},
+		"multiple dimensions": {
+			dimensions: []string{"region", "cloud"},
+			wantCreated: []map[string]string{
+				{"region": "eu", "cloud": "aws"},
+				{"region": "us", "cloud": "aws"},
+				{"region": "us", "cloud": "gcp"},
+			},
+			wantCount: 3,
+		},
+		"selector filters shards": {
+			dimensions: []string{"region"},
+			selector:   &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "dev"}},
+			wantCreated: []map[string]string{
+				{"region": "us", "tier": "dev"},
+			},
+			wantCount: 1,
+		},
+		"no dimensions result in a single partition": {
+			wantCreated: []map[string]string{nil},
+			wantCount:   1,
+		},
+		"existing partitions are kept, obsolete ones deleted": {
+			dimensions: []string{"region"},
+			existing: []*topologyv1alpha1.Partition{
+				ownedPartition("regions-eu", map[string]string{"region": "eu"}),
+				ownedPartition("regions-ap", map[string]string{"region": "ap"}),
+				{ObjectMeta: metav1.ObjectMeta{Name: "not-owned", Annotations: map[string]string{logicalcluster.AnnotationKey: "root:org"}}},
+			},
+			wantCreated: []map[string]string{
+				{"region": "us"},
+			},
+			wantDeleted: []string{"regions-ap"},
+			wantCount:   2,
+		},
+		"existing partitions are updated when the selector changed": {
+			dimensions: []string{"region"},
+			selector:   &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "dev"}},
+			existing: []*topologyv1alpha1.Partition{
+				ownedPartition("regions-us", map[string]string{"region": "us"}),
+			},
+			wantUpdated: []string{"regions-us"},
+			wantCount:   1,
+		},
+		"invalid selector": {
+			dimensions:          []string{"region"},
+			selector:            &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: "Invalid"}}},
+			wantInvalidSelector: true,
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc // to avoid t.Parallel() races
+
+		t.Run(name, func(t *testing.T) {
+			var created []map[string]string
+			var updated, deleted []string
+
+			c := &controller{
+				listClusterWorkspaceShards: func(selector labels.Selector) ([]*tenancyv1alpha1.ClusterWorkspaceShard, error) {
+					var ret []*tenancyv1alpha1.ClusterWorkspaceShard
+					for _, shard := range shards {
+						if selector.Matches(labels.Set(shard.Labels)) {
+							ret = append(ret, shard)
+						}
+					}
+					return ret, nil
+				},
+				listPartitions: func(clusterName logicalcluster.Name) ([]*topologyv1alpha1.Partition, error) {
+					require.Equal(t, "root:org", clusterName.String())
+					return tc.existing, nil
+				},
+				createPartition: func(ctx context.Context, clusterName logicalcluster.Name, partition *topologyv1alpha1.Partition) error {
+					require.Equal(t, "regions-", partition.GenerateName)
+					require.True(t, metav1.IsControlledBy(partition, partitionSet))
+					created = append(created, partition.Spec.Selector.MatchLabels)
+					return nil
+				},
+				updatePartition: func(ctx context.Context, clusterName logicalcluster.Name, partition *topologyv1alpha1.Partition) error {
+					updated = append(updated, partition.Name)
+					return nil
+				},
+				deletePartition: func(ctx context.Context, clusterName logicalcluster.Name, name string) error {
+					deleted = append(deleted, name)
+					return nil
+				},
+			}
+
+			ps := partitionSet.DeepCopy()
+			ps.Spec.Dimensions = tc.dimensions
+			ps.Spec.Selector = tc.selector
+
+			err := c.reconcile(context.Background(), ps)
+			require.NoError(t, err)
+
+			if tc.wantInvalidSelector {
+				require.True(t, conditions.IsFalse(ps, topologyv1alpha1.PartitionSetValid))
+				require.Empty(t, created)
+				return
+			}
+
+			require.True(t, conditions.IsTrue(ps, topologyv1alpha1.PartitionSetValid))
+			require.True(t, conditions.IsTrue(ps, topologyv1alpha1.PartitionsReady))
+			sort.Slice(created, func(i, j int) bool {
+				return partitionKey(tc.dimensions, created[i]) < partitionKey(tc.dimensions, created[j])
+			})
+			for i := range tc.wantCreated {
+				if tc.selector != nil {
+					for k, v := range tc.selector.MatchLabels {
+						require.Equal(t, v, created[i][k])
+					}
+				}
+				for k, v := range tc.wantCreated[i] {
+					require.Equal(t, v, created[i][k])
+				}
+			}
+			require.Len(t, created, len(tc.wantCreated))
+			require.Equal(t, tc.wantUpdated, updated)
+			require.Equal(t, tc.wantDeleted, deleted)
+			require.Equal(t, tc.wantCount, ps.Status.Count)
+		})
+	}
+}
@@ -51,6 +51,9 @@ const (
 // APIResourceSchemas of kubernetes in root:compute workspace
 var rootComputeResourceSchema = sets.NewString(
 	"deployments.apps",
+	"statefulsets.apps",
+	"daemonsets.apps",
+	"jobs.batch",
 	"services.core",
 	"ingresses.networking.k8s.io",
 )
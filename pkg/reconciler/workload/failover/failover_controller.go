@@ -0,0 +1,226 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package failover
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	kcpcache "github.com/kcp-dev/apimachinery/pkg/cache"
+	kcpcorev1informers "github.com/kcp-dev/client-go/informers/core/v1"
+	kcpkubernetesclientset "github.com/kcp-dev/client-go/kubernetes"
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/client"
+	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/cluster"
+	schedulinginformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/scheduling/v1alpha1"
+	workloadinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/workload/v1alpha1"
+	schedulingv1alpha1listers "github.com/kcp-dev/kcp/pkg/client/listers/scheduling/v1alpha1"
+	workloadv1alpha1listers "github.com/kcp-dev/kcp/pkg/client/listers/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/indexers"
+	"github.com/kcp-dev/kcp/pkg/logging"
+)
+
+const ControllerName = "kcp-workload-failover"
+
+// NewController returns a new controller which evicts SyncTargets whose syncer has not sent a heartbeat
+// for longer than the grace period, and marks their namespaces as removing such that they are rescheduled
+// to other SyncTargets of their Location.
+func NewController(
+	kcpClusterClient kcpclientset.ClusterInterface,
+	kubeClusterClient kcpkubernetesclientset.ClusterInterface,
+	syncTargetInformer workloadinformers.SyncTargetClusterInformer,
+	locationInformer schedulinginformers.LocationClusterInformer,
+	namespaceInformer kcpcorev1informers.NamespaceClusterInformer,
+	gracePeriod time.Duration,
+) (*controller, error) {
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), ControllerName)
+
+	c := &controller{
+		queue: queue,
+		enqueueAfter: func(syncTarget *workloadv1alpha1.SyncTarget, duration time.Duration) {
+			key := client.ToClusterAwareKey(logicalcluster.From(syncTarget), syncTarget.Name)
+			queue.AddAfter(key, duration)
+		},
+
+		gracePeriod: gracePeriod,
+
+		kcpClusterClient:  kcpClusterClient,
+		kubeClusterClient: kubeClusterClient,
+
+		syncTargetLister: syncTargetInformer.Lister(),
+		locationLister:   locationInformer.Lister(),
+
+		namespaceIndexer: namespaceInformer.Informer().GetIndexer(),
+	}
+
+	indexers.AddIfNotPresentOrDie(namespaceInformer.Informer().GetIndexer(), cache.Indexers{
+		indexers.ByClusterResourceStateLabelKey: indexers.IndexByClusterResourceStateLabelKey,
+	})
+
+	syncTargetInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.enqueueSyncTarget,
+		UpdateFunc: func(_, obj interface{}) { c.enqueueSyncTarget(obj) },
+	})
+
+	return c, nil
+}
+
+// controller
+type controller struct {
+	queue        workqueue.RateLimitingInterface
+	enqueueAfter func(*workloadv1alpha1.SyncTarget, time.Duration)
+
+	gracePeriod time.Duration
+
+	kcpClusterClient  kcpclientset.ClusterInterface
+	kubeClusterClient kcpkubernetesclientset.ClusterInterface
+
+	syncTargetLister workloadv1alpha1listers.SyncTargetClusterLister
+	locationLister   schedulingv1alpha1listers.LocationClusterLister
+
+	namespaceIndexer cache.Indexer
+}
+
+func (c *controller) enqueueSyncTarget(obj interface{}) {
+	key, err := kcpcache.DeletionHandlingMetaClusterNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+
+	logger := logging.WithQueueKey(logging.WithReconciler(klog.Background(), ControllerName), key)
+	logger.V(2).Info("queueing SyncTarget")
+	c.queue.Add(key)
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *controller) Start(ctx context.Context, numThreads int) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	logger := logging.WithReconciler(klog.FromContext(ctx), ControllerName)
+	ctx = klog.NewContext(ctx, logger)
+	logger.Info("Starting controller")
+	defer logger.Info("Shutting down controller")
+
+	for i := 0; i < numThreads; i++ {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	k, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	key := k.(string)
+
+	logger := logging.WithQueueKey(klog.FromContext(ctx), key)
+	ctx = klog.NewContext(ctx, logger)
+	logger.V(1).Info("processing key")
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.process(ctx, key); err != nil {
+		runtime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", ControllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *controller) process(ctx context.Context, key string) error {
+	logger := klog.FromContext(ctx)
+	clusterName, _, name, err := kcpcache.SplitMetaClusterNamespaceKey(key)
+	if err != nil {
+		logger.Error(err, "invalid key")
+		return nil
+	}
+
+	syncTarget, err := c.syncTargetLister.Cluster(clusterName).Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil // object deleted before we handled it
+		}
+		return err
+	}
+
+	logger = logging.WithObject(logger, syncTarget)
+	ctx = klog.NewContext(ctx, logger)
+
+	r := &failoverReconciler{
+		gracePeriod:     c.gracePeriod,
+		listSyncTargets: c.listSyncTargets,
+		listLocations:   c.listLocations,
+		listNamespaces:  c.listNamespaces,
+		patchSyncTarget: c.patchSyncTarget,
+		patchNamespace:  c.patchNamespace,
+		enqueueAfter:    c.enqueueAfter,
+		now:             time.Now,
+	}
+	return r.reconcile(ctx, syncTarget.DeepCopy())
+}
+
+func (c *controller) listSyncTargets(clusterName logicalcluster.Name) ([]*workloadv1alpha1.SyncTarget, error) {
+	return c.syncTargetLister.Cluster(clusterName).List(labels.Everything())
+}
+
+func (c *controller) listLocations(clusterName logicalcluster.Name) ([]*schedulingv1alpha1.Location, error) {
+	return c.locationLister.Cluster(clusterName).List(labels.Everything())
+}
+
+func (c *controller) listNamespaces(syncTargetKey string) ([]*corev1.Namespace, error) {
+	return indexers.ByIndex[*corev1.Namespace](c.namespaceIndexer, indexers.ByClusterResourceStateLabelKey, workloadv1alpha1.ClusterResourceStateLabelPrefix+syncTargetKey)
+}
+
+func (c *controller) patchSyncTarget(ctx context.Context, clusterName logicalcluster.Name, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*workloadv1alpha1.SyncTarget, error) {
+	logger := klog.FromContext(ctx)
+	logger.WithValues("patch", string(data)).V(2).Info("patching SyncTarget")
+	return c.kcpClusterClient.Cluster(clusterName).WorkloadV1alpha1().SyncTargets().Patch(ctx, name, pt, data, opts, subresources...)
+}
+
+func (c *controller) patchNamespace(ctx context.Context, clusterName logicalcluster.Name, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*corev1.Namespace, error) {
+	logger := klog.FromContext(ctx)
+	logger.WithValues("patch", string(data)).V(2).Info("patching Namespace")
+	return c.kubeClusterClient.Cluster(clusterName).CoreV1().Namespaces().Patch(ctx, name, pt, data, opts, subresources...)
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package failover
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+func DefaultOptions() *Options {
+	return &Options{
+		GracePeriod: 5 * time.Minute,
+	}
+}
+
+func BindOptions(o *Options, fs *pflag.FlagSet) *Options {
+	fs.DurationVar(&o.GracePeriod, "sync-target-failover-grace-period", o.GracePeriod, "Amount of time a SyncTarget may miss its heartbeat before it is evicted and its namespaces are rescheduled to other SyncTargets of their Location")
+	return o
+}
+
+type Options struct {
+	GracePeriod time.Duration
+}
+
+func (o *Options) Validate() error {
+	if o.GracePeriod < 0 {
+		return fmt.Errorf("--sync-target-failover-grace-period must be >=0 (%s)", o.GracePeriod)
+	}
+	return nil
+}
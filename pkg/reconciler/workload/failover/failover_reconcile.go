@@ -0,0 +1,289 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package failover
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+	locationreconciler "github.com/kcp-dev/kcp/pkg/reconciler/scheduling/location"
+)
+
+// noAlternativeRecheckInterval is the interval in which a SyncTarget which cannot fail over, because no other
+// SyncTarget of its Locations can take over its workloads, is checked again.
+const noAlternativeRecheckInterval = time.Minute
+
+// failoverReconciler evicts a SyncTarget whose HeartbeatHealthy condition has been false for longer
+// than the grace period by setting spec.evictAfter, such that the placement scheduler reschedules its
+// placements to other SyncTargets of the selected Location. It marks the namespaces scheduled to the
+// SyncTarget as removing from it. The syncer deletes the downstream resources once it comes back, and
+// the eviction is lifted when the heartbeat is healthy again. A SyncTarget is only failed over if another
+// ready, non-evicting SyncTarget of one of its Locations supports the APIs synced to it, otherwise its
+// workloads would be removed without being rescheduled.
+type failoverReconciler struct {
+	gracePeriod time.Duration
+
+	listSyncTargets func(clusterName logicalcluster.Name) ([]*workloadv1alpha1.SyncTarget, error)
+	listLocations   func(clusterName logicalcluster.Name) ([]*schedulingv1alpha1.Location, error)
+	listNamespaces  func(syncTargetKey string) ([]*corev1.Namespace, error)
+	patchSyncTarget func(ctx context.Context, clusterName logicalcluster.Name, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*workloadv1alpha1.SyncTarget, error)
+	patchNamespace  func(ctx context.Context, clusterName logicalcluster.Name, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*corev1.Namespace, error)
+
+	enqueueAfter func(*workloadv1alpha1.SyncTarget, time.Duration)
+
+	now func() time.Time
+}
+
+func (r *failoverReconciler) reconcile(ctx context.Context, syncTarget *workloadv1alpha1.SyncTarget) error {
+	logger := klog.FromContext(ctx)
+
+	heartbeat := conditions.Get(syncTarget, workloadv1alpha1.HeartbeatHealthy)
+	switch {
+	case heartbeat == nil || syncTarget.Status.LastSyncerHeartbeatTime == nil:
+		// the syncer has never been seen, hence nothing has been synced to the SyncTarget yet.
+		return nil
+	case heartbeat.Status == corev1.ConditionTrue:
+		return r.recover(ctx, syncTarget)
+	case heartbeat.Status != corev1.ConditionFalse:
+		return nil
+	}
+
+	// 1. wait for the grace period
+	failoverTime := heartbeat.LastTransitionTime.Add(r.gracePeriod)
+	if remaining := failoverTime.Sub(r.now()); remaining > 0 {
+		logger.WithValues("after", remaining).V(4).Info("heartbeat of SyncTarget is missing, waiting for the failover grace period")
+		r.enqueueAfter(syncTarget, remaining)
+		return nil
+	}
+
+	// 2. wait for another SyncTarget to take over the workloads, unless the SyncTarget has failed over already.
+	if _, failedOver := syncTarget.Annotations[workloadv1alpha1.InternalSyncTargetFailoverAnnotationKey]; !failedOver {
+		found, err := r.hasAlternative(syncTarget)
+		if err != nil {
+			return err
+		}
+		if !found {
+			logger.V(2).Info("not failing over SyncTarget because no other SyncTarget of its Locations can take over its workloads")
+			r.enqueueAfter(syncTarget, noAlternativeRecheckInterval)
+			return r.setFailedOverCondition(ctx, syncTarget, func(syncTarget *workloadv1alpha1.SyncTarget) {
+				conditions.MarkFalse(syncTarget, workloadv1alpha1.FailedOver, workloadv1alpha1.NoAlternativeSyncTargetReason, conditionsv1alpha1.ConditionSeverityWarning,
+					"No other ready SyncTarget of the Locations of the SyncTarget supports its APIs")
+			})
+		}
+	}
+
+	// 3. evict the SyncTarget, unless it is evicted already, e.g. by a drain.
+	if syncTarget.Spec.EvictAfter == nil {
+		evictAfter := metav1.NewTime(r.now().UTC().Truncate(time.Second))
+		patch := map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"resourceVersion": syncTarget.ResourceVersion,
+				"annotations": map[string]interface{}{
+					workloadv1alpha1.InternalSyncTargetFailoverAnnotationKey: evictAfter.Format(time.RFC3339),
+				},
+			},
+			"spec": map[string]interface{}{
+				"evictAfter": evictAfter,
+			},
+		}
+		logger.WithValues("lastHeartbeat", syncTarget.Status.LastSyncerHeartbeatTime.Time).V(2).Info("evicting SyncTarget because its heartbeat is missing")
+		updated, err := r.patch(ctx, syncTarget, patch)
+		if err != nil {
+			return err
+		}
+		syncTarget = updated
+	}
+	if err := r.setFailedOverCondition(ctx, syncTarget, func(syncTarget *workloadv1alpha1.SyncTarget) {
+		conditions.MarkTrue(syncTarget, workloadv1alpha1.FailedOver)
+	}); err != nil {
+		return err
+	}
+
+	// 4. mark the namespaces as removing from the SyncTarget
+	syncTargetKey := workloadv1alpha1.ToSyncTargetKey(logicalcluster.From(syncTarget), syncTarget.Name)
+	namespaces, err := r.listNamespaces(syncTargetKey)
+	if err != nil {
+		return err
+	}
+
+	deletionAnnotationKey := workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix + syncTargetKey
+	now := r.now().UTC().Format(time.RFC3339)
+	var errs []error
+	for _, ns := range namespaces {
+		if _, found := ns.Annotations[deletionAnnotationKey]; found {
+			continue
+		}
+
+		patchBytes, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": map[string]interface{}{
+					deletionAnnotationKey: now,
+				},
+			},
+		})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		logging.WithObject(logger, ns).V(3).Info("setting SyncTarget as removing for Namespace since the SyncTarget is failing over")
+		if _, err := r.patchNamespace(ctx, logicalcluster.From(ns), ns.Name, types.MergePatchType, patchBytes, metav1.PatchOptions{}); err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, err)
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// recover lifts the eviction set by the failover when the heartbeat is healthy again. An evictAfter
+// which has been changed since, e.g. by a drain, is kept.
+func (r *failoverReconciler) recover(ctx context.Context, syncTarget *workloadv1alpha1.SyncTarget) error {
+	logger := klog.FromContext(ctx)
+
+	if err := r.setFailedOverCondition(ctx, syncTarget, func(syncTarget *workloadv1alpha1.SyncTarget) {
+		conditions.Delete(syncTarget, workloadv1alpha1.FailedOver)
+	}); err != nil {
+		return err
+	}
+
+	value, found := syncTarget.Annotations[workloadv1alpha1.InternalSyncTargetFailoverAnnotationKey]
+	if !found {
+		return nil
+	}
+
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"resourceVersion": syncTarget.ResourceVersion,
+			"annotations": map[string]interface{}{
+				workloadv1alpha1.InternalSyncTargetFailoverAnnotationKey: nil,
+			},
+		},
+	}
+	if syncTarget.Spec.EvictAfter != nil && syncTarget.Spec.EvictAfter.UTC().Format(time.RFC3339) == value {
+		patch["spec"] = map[string]interface{}{
+			"evictAfter": nil,
+		}
+	}
+
+	logger.V(2).Info("lifting the eviction of SyncTarget because its heartbeat is healthy again")
+	_, err := r.patch(ctx, syncTarget, patch)
+	return err
+}
+
+// hasAlternative returns true if another ready, non-evicting SyncTarget of one of the Locations of the
+// SyncTarget supports all the APIs synced to it.
+func (r *failoverReconciler) hasAlternative(syncTarget *workloadv1alpha1.SyncTarget) (bool, error) {
+	clusterName := logicalcluster.From(syncTarget)
+	locations, err := r.listLocations(clusterName)
+	if err != nil {
+		return false, err
+	}
+	syncTargets, err := r.listSyncTargets(clusterName)
+	if err != nil {
+		return false, err
+	}
+
+	for _, location := range locations {
+		locationSyncTargets, err := locationreconciler.LocationSyncTargets(syncTargets, location)
+		if err != nil || !containsSyncTarget(locationSyncTargets, syncTarget.Name) {
+			continue
+		}
+		for _, candidate := range locationreconciler.FilterReady(locationreconciler.FilterNonEvicting(locationSyncTargets)) {
+			if candidate.Name != syncTarget.Name && supportsSyncedResources(candidate, syncTarget) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func containsSyncTarget(syncTargets []*workloadv1alpha1.SyncTarget, name string) bool {
+	for _, syncTarget := range syncTargets {
+		if syncTarget.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// supportsSyncedResources returns true if the candidate has accepted all the resources accepted by the
+// SyncTarget, with the same identity.
+func supportsSyncedResources(candidate, syncTarget *workloadv1alpha1.SyncTarget) bool {
+	accepted := map[apisv1alpha1.GroupResource]string{}
+	for _, resource := range candidate.Status.SyncedResources {
+		if resource.State == workloadv1alpha1.ResourceSchemaAcceptedState {
+			accepted[resource.GroupResource] = resource.IdentityHash
+		}
+	}
+	for _, resource := range syncTarget.Status.SyncedResources {
+		if resource.State != workloadv1alpha1.ResourceSchemaAcceptedState {
+			continue
+		}
+		if identityHash, found := accepted[resource.GroupResource]; !found || identityHash != resource.IdentityHash {
+			return false
+		}
+	}
+	return true
+}
+
+// setFailedOverCondition updates the FailedOver condition of the SyncTarget with the given function, and
+// patches the status if the condition has changed.
+func (r *failoverReconciler) setFailedOverCondition(ctx context.Context, syncTarget *workloadv1alpha1.SyncTarget, update func(*workloadv1alpha1.SyncTarget)) error {
+	updated := syncTarget.DeepCopy()
+	update(updated)
+	if equality.Semantic.DeepEqual(conditions.Get(syncTarget, workloadv1alpha1.FailedOver), conditions.Get(updated, workloadv1alpha1.FailedOver)) {
+		return nil
+	}
+
+	patchBytes, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"resourceVersion": syncTarget.ResourceVersion,
+		},
+		"status": map[string]interface{}{
+			"conditions": updated.Status.Conditions,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = r.patchSyncTarget(ctx, logicalcluster.From(syncTarget), syncTarget.Name, types.MergePatchType, patchBytes, metav1.PatchOptions{}, "status")
+	return err
+}
+
+func (r *failoverReconciler) patch(ctx context.Context, syncTarget *workloadv1alpha1.SyncTarget, patch map[string]interface{}) (*workloadv1alpha1.SyncTarget, error) {
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+	return r.patchSyncTarget(ctx, logicalcluster.From(syncTarget), syncTarget.Name, types.MergePatchType, patchBytes, metav1.PatchOptions{})
+}
@@ -0,0 +1,350 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package failover
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
+	conditionsapi "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+)
+
+func TestFailoverReconcile(t *testing.T) {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	syncTargetKey := workloadv1alpha1.ToSyncTargetKey(logicalcluster.New("root:org:ws"), "cluster1")
+	deletionAnnotationKey := workloadv1alpha1.InternalClusterDeletionTimestampAnnotationPrefix + syncTargetKey
+
+	testCases := []struct {
+		name string
+
+		syncTarget   *workloadv1alpha1.SyncTarget
+		alternatives []*workloadv1alpha1.SyncTarget
+		locations    []*schedulingv1alpha1.Location
+		namespaces   []*corev1.Namespace
+
+		wantSyncTargetPatches []string
+		wantNamespacePatches  []string
+		wantEnqueueAfter      time.Duration
+	}{
+		{
+			name:       "heartbeat healthy",
+			syncTarget: newSyncTarget(corev1.ConditionTrue, now.Add(-time.Hour)),
+			namespaces: []*corev1.Namespace{newNamespace("ns1", syncTargetKey, nil)},
+		},
+		{
+			name:       "no heartbeat seen yet",
+			syncTarget: withoutHeartbeat(newSyncTarget(corev1.ConditionFalse, now.Add(-time.Hour))),
+		},
+		{
+			name:             "heartbeat missing within the grace period",
+			syncTarget:       newSyncTarget(corev1.ConditionFalse, now.Add(-time.Minute)),
+			namespaces:       []*corev1.Namespace{newNamespace("ns1", syncTargetKey, nil)},
+			wantEnqueueAfter: 4 * time.Minute,
+		},
+		{
+			name:         "heartbeat missing for longer than the grace period",
+			syncTarget:   newSyncTarget(corev1.ConditionFalse, now.Add(-10*time.Minute)),
+			alternatives: []*workloadv1alpha1.SyncTarget{newAlternative("cluster2")},
+			namespaces: []*corev1.Namespace{
+				newNamespace("ns1", syncTargetKey, nil),
+				newNamespace("ns2", syncTargetKey, map[string]string{deletionAnnotationKey: now.Add(-time.Minute).Format(time.RFC3339)}),
+			},
+			wantSyncTargetPatches: []string{
+				`{"metadata":{"annotations":{"internal.workload.kcp.dev/failover":"2022-10-01T12:00:00Z"},"resourceVersion":"1"},"spec":{"evictAfter":"2022-10-01T12:00:00Z"}}`,
+				"status: FailedOver=True",
+			},
+			wantNamespacePatches: []string{`ns1: {"metadata":{"annotations":{"deletion.internal.workload.kcp.dev/` + syncTargetKey + `":"2022-10-01T12:00:00Z"}}}`},
+		},
+		{
+			name:                  "heartbeat missing without another SyncTarget in the Location",
+			syncTarget:            newSyncTarget(corev1.ConditionFalse, now.Add(-10*time.Minute)),
+			namespaces:            []*corev1.Namespace{newNamespace("ns1", syncTargetKey, nil)},
+			wantSyncTargetPatches: []string{"status: FailedOver=False/NoAlternativeSyncTarget"},
+			wantEnqueueAfter:      noAlternativeRecheckInterval,
+		},
+		{
+			name:             "heartbeat missing without another SyncTarget in the Location, condition set already",
+			syncTarget:       withFailedOverCondition(corev1.ConditionFalse, newSyncTarget(corev1.ConditionFalse, now.Add(-10*time.Minute))),
+			namespaces:       []*corev1.Namespace{newNamespace("ns1", syncTargetKey, nil)},
+			wantEnqueueAfter: noAlternativeRecheckInterval,
+		},
+		{
+			name:       "heartbeat missing with other SyncTargets not ready, evicting or not supporting the APIs",
+			syncTarget: withSyncedResource("deployments", "", newSyncTarget(corev1.ConditionFalse, now.Add(-10*time.Minute))),
+			alternatives: []*workloadv1alpha1.SyncTarget{
+				withReady(corev1.ConditionFalse, withSyncedResource("deployments", "", newAlternative("cluster2"))),
+				withEvictAfter(now.Add(-time.Hour), withSyncedResource("deployments", "", newAlternative("cluster3"))),
+				withSyncedResource("deployments", "other", newAlternative("cluster4")),
+				newAlternative("cluster5"),
+			},
+			namespaces:            []*corev1.Namespace{newNamespace("ns1", syncTargetKey, nil)},
+			wantSyncTargetPatches: []string{"status: FailedOver=False/NoAlternativeSyncTarget"},
+			wantEnqueueAfter:      noAlternativeRecheckInterval,
+		},
+		{
+			name:                  "heartbeat missing with another SyncTarget outside of the Locations of the SyncTarget",
+			syncTarget:            newSyncTarget(corev1.ConditionFalse, now.Add(-10*time.Minute)),
+			alternatives:          []*workloadv1alpha1.SyncTarget{newAlternative("cluster2")},
+			locations:             []*schedulingv1alpha1.Location{newLocation("us", "cluster1"), newLocation("eu", "cluster2")},
+			namespaces:            []*corev1.Namespace{newNamespace("ns1", syncTargetKey, nil)},
+			wantSyncTargetPatches: []string{"status: FailedOver=False/NoAlternativeSyncTarget"},
+			wantEnqueueAfter:      noAlternativeRecheckInterval,
+		},
+		{
+			name:         "heartbeat missing with another SyncTarget supporting the APIs",
+			syncTarget:   withSyncedResource("deployments", "", newSyncTarget(corev1.ConditionFalse, now.Add(-10*time.Minute))),
+			alternatives: []*workloadv1alpha1.SyncTarget{withSyncedResource("deployments", "", newAlternative("cluster2"))},
+			locations:    []*schedulingv1alpha1.Location{newLocation("us", "cluster1", "cluster2")},
+			namespaces:   []*corev1.Namespace{newNamespace("ns1", syncTargetKey, nil)},
+			wantSyncTargetPatches: []string{
+				`{"metadata":{"annotations":{"internal.workload.kcp.dev/failover":"2022-10-01T12:00:00Z"},"resourceVersion":"1"},"spec":{"evictAfter":"2022-10-01T12:00:00Z"}}`,
+				"status: FailedOver=True",
+			},
+			wantNamespacePatches: []string{`ns1: {"metadata":{"annotations":{"deletion.internal.workload.kcp.dev/` + syncTargetKey + `":"2022-10-01T12:00:00Z"}}}`},
+		},
+		{
+			name:                  "heartbeat missing on a drained synctarget",
+			syncTarget:            withEvictAfter(now.Add(-time.Hour), newSyncTarget(corev1.ConditionFalse, now.Add(-10*time.Minute))),
+			alternatives:          []*workloadv1alpha1.SyncTarget{newAlternative("cluster2")},
+			namespaces:            []*corev1.Namespace{newNamespace("ns1", syncTargetKey, nil)},
+			wantSyncTargetPatches: []string{"status: FailedOver=True"},
+			wantNamespacePatches:  []string{`ns1: {"metadata":{"annotations":{"deletion.internal.workload.kcp.dev/` + syncTargetKey + `":"2022-10-01T12:00:00Z"}}}`},
+		},
+		{
+			name:                 "heartbeat missing on a synctarget failed over already whose alternatives are gone",
+			syncTarget:           withFailedOverCondition(corev1.ConditionTrue, withFailover(now.Add(-time.Hour), withEvictAfter(now.Add(-time.Hour), newSyncTarget(corev1.ConditionFalse, now.Add(-10*time.Minute))))),
+			namespaces:           []*corev1.Namespace{newNamespace("ns1", syncTargetKey, nil)},
+			wantNamespacePatches: []string{`ns1: {"metadata":{"annotations":{"deletion.internal.workload.kcp.dev/` + syncTargetKey + `":"2022-10-01T12:00:00Z"}}}`},
+		},
+		{
+			name:       "heartbeat healthy again after failover",
+			syncTarget: withFailedOverCondition(corev1.ConditionTrue, withFailover(now.Add(-time.Hour), withEvictAfter(now.Add(-time.Hour), newSyncTarget(corev1.ConditionTrue, now.Add(-time.Minute))))),
+			wantSyncTargetPatches: []string{
+				"status: FailedOver removed",
+				`{"metadata":{"annotations":{"internal.workload.kcp.dev/failover":null},"resourceVersion":"1"},"spec":{"evictAfter":null}}`,
+			},
+		},
+		{
+			name:                  "heartbeat healthy again after failover and drain",
+			syncTarget:            withFailover(now.Add(-time.Hour), withEvictAfter(now.Add(-time.Minute), newSyncTarget(corev1.ConditionTrue, now.Add(-time.Minute)))),
+			wantSyncTargetPatches: []string{`{"metadata":{"annotations":{"internal.workload.kcp.dev/failover":null},"resourceVersion":"1"}}`},
+		},
+		{
+			name:                  "heartbeat healthy again without failover",
+			syncTarget:            withFailedOverCondition(corev1.ConditionFalse, newSyncTarget(corev1.ConditionTrue, now.Add(-time.Minute))),
+			wantSyncTargetPatches: []string{"status: FailedOver removed"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var syncTargetPatches []string
+			var namespacePatches []string
+			var enqueueAfter time.Duration
+
+			locations := testCase.locations
+			if locations == nil {
+				locations = []*schedulingv1alpha1.Location{newLocation("default")}
+			}
+
+			r := &failoverReconciler{
+				gracePeriod: 5 * time.Minute,
+				listSyncTargets: func(clusterName logicalcluster.Name) ([]*workloadv1alpha1.SyncTarget, error) {
+					require.Equal(t, logicalcluster.New("root:org:ws"), clusterName)
+					return append([]*workloadv1alpha1.SyncTarget{testCase.syncTarget}, testCase.alternatives...), nil
+				},
+				listLocations: func(clusterName logicalcluster.Name) ([]*schedulingv1alpha1.Location, error) {
+					require.Equal(t, logicalcluster.New("root:org:ws"), clusterName)
+					return locations, nil
+				},
+				listNamespaces: func(key string) ([]*corev1.Namespace, error) {
+					require.Equal(t, syncTargetKey, key)
+					return testCase.namespaces, nil
+				},
+				patchSyncTarget: func(ctx context.Context, clusterName logicalcluster.Name, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*workloadv1alpha1.SyncTarget, error) {
+					require.Equal(t, logicalcluster.New("root:org:ws"), clusterName)
+					require.Equal(t, "cluster1", name)
+					if len(subresources) > 0 {
+						require.Equal(t, []string{"status"}, subresources)
+						syncTargetPatches = append(syncTargetPatches, failedOverPatch(t, data))
+					} else {
+						syncTargetPatches = append(syncTargetPatches, string(data))
+					}
+					return testCase.syncTarget, nil
+				},
+				patchNamespace: func(ctx context.Context, clusterName logicalcluster.Name, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*corev1.Namespace, error) {
+					namespacePatches = append(namespacePatches, name+": "+string(data))
+					return nil, nil
+				},
+				enqueueAfter: func(_ *workloadv1alpha1.SyncTarget, duration time.Duration) {
+					enqueueAfter = duration
+				},
+				now: func() time.Time { return now },
+			}
+
+			err := r.reconcile(context.TODO(), testCase.syncTarget)
+			require.NoError(t, err)
+			require.Equal(t, testCase.wantSyncTargetPatches, syncTargetPatches)
+			require.Equal(t, testCase.wantNamespacePatches, namespacePatches)
+			require.Equal(t, testCase.wantEnqueueAfter, enqueueAfter)
+		})
+	}
+}
+
+// failedOverPatch summarizes the FailedOver condition set by a status patch.
+func failedOverPatch(t *testing.T, data []byte) string {
+	t.Helper()
+
+	var patch workloadv1alpha1.SyncTarget
+	require.NoError(t, json.Unmarshal(data, &patch))
+	require.Equal(t, "1", patch.ResourceVersion)
+	condition := conditions.Get(&patch, workloadv1alpha1.FailedOver)
+	switch {
+	case condition == nil:
+		return "status: FailedOver removed"
+	case condition.Reason != "":
+		return fmt.Sprintf("status: FailedOver=%s/%s", condition.Status, condition.Reason)
+	default:
+		return fmt.Sprintf("status: FailedOver=%s", condition.Status)
+	}
+}
+
+func newSyncTarget(heartbeat corev1.ConditionStatus, transition time.Time) *workloadv1alpha1.SyncTarget {
+	lastHeartbeat := metav1.NewTime(transition)
+	return &workloadv1alpha1.SyncTarget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "cluster1",
+			ResourceVersion: "1",
+			Labels:          map[string]string{"name": "cluster1"},
+			Annotations: map[string]string{
+				logicalcluster.AnnotationKey: "root:org:ws",
+			},
+		},
+		Status: workloadv1alpha1.SyncTargetStatus{
+			LastSyncerHeartbeatTime: &lastHeartbeat,
+			Conditions: conditionsapi.Conditions{
+				{
+					Type:               workloadv1alpha1.HeartbeatHealthy,
+					Status:             heartbeat,
+					LastTransitionTime: metav1.NewTime(transition),
+				},
+			},
+		},
+	}
+}
+
+func newAlternative(name string) *workloadv1alpha1.SyncTarget {
+	return withReady(corev1.ConditionTrue, &workloadv1alpha1.SyncTarget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{"name": name},
+			Annotations: map[string]string{
+				logicalcluster.AnnotationKey: "root:org:ws",
+			},
+		},
+	})
+}
+
+// newLocation returns a Location selecting the given SyncTargets, or all of them if none is given.
+func newLocation(name string, syncTargets ...string) *schedulingv1alpha1.Location {
+	selector := &metav1.LabelSelector{}
+	if len(syncTargets) > 0 {
+		selector.MatchExpressions = []metav1.LabelSelectorRequirement{{Key: "name", Operator: metav1.LabelSelectorOpIn, Values: syncTargets}}
+	}
+	return &schedulingv1alpha1.Location{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Annotations: map[string]string{
+				logicalcluster.AnnotationKey: "root:org:ws",
+			},
+		},
+		Spec: schedulingv1alpha1.LocationSpec{
+			InstanceSelector: selector,
+		},
+	}
+}
+
+func withReady(status corev1.ConditionStatus, syncTarget *workloadv1alpha1.SyncTarget) *workloadv1alpha1.SyncTarget {
+	conditions.Set(syncTarget, &conditionsapi.Condition{Type: conditionsapi.ReadyCondition, Status: status})
+	return syncTarget
+}
+
+func withFailedOverCondition(status corev1.ConditionStatus, syncTarget *workloadv1alpha1.SyncTarget) *workloadv1alpha1.SyncTarget {
+	condition := conditionsapi.Condition{Type: workloadv1alpha1.FailedOver, Status: status}
+	if status == corev1.ConditionFalse {
+		condition.Reason = workloadv1alpha1.NoAlternativeSyncTargetReason
+		condition.Severity = conditionsapi.ConditionSeverityWarning
+		condition.Message = "No other ready SyncTarget of the Locations of the SyncTarget supports its APIs"
+	}
+	syncTarget.Status.Conditions = append(syncTarget.Status.Conditions, condition)
+	return syncTarget
+}
+
+func withSyncedResource(resource, identityHash string, syncTarget *workloadv1alpha1.SyncTarget) *workloadv1alpha1.SyncTarget {
+	syncTarget.Status.SyncedResources = append(syncTarget.Status.SyncedResources, workloadv1alpha1.ResourceToSync{
+		GroupResource: apisv1alpha1.GroupResource{Group: "apps", Resource: resource},
+		Versions:      []string{"v1"},
+		IdentityHash:  identityHash,
+		State:         workloadv1alpha1.ResourceSchemaAcceptedState,
+	})
+	return syncTarget
+}
+
+func withoutHeartbeat(syncTarget *workloadv1alpha1.SyncTarget) *workloadv1alpha1.SyncTarget {
+	syncTarget.Status.LastSyncerHeartbeatTime = nil
+	return syncTarget
+}
+
+func withEvictAfter(evictAfter time.Time, syncTarget *workloadv1alpha1.SyncTarget) *workloadv1alpha1.SyncTarget {
+	syncTarget.Spec.EvictAfter = &metav1.Time{Time: evictAfter}
+	return syncTarget
+}
+
+func withFailover(evictAfter time.Time, syncTarget *workloadv1alpha1.SyncTarget) *workloadv1alpha1.SyncTarget {
+	syncTarget.Annotations[workloadv1alpha1.InternalSyncTargetFailoverAnnotationKey] = evictAfter.UTC().Format(time.RFC3339)
+	return syncTarget
+}
+
+func newNamespace(name, syncTargetKey string, annotations map[string]string) *corev1.Namespace {
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[logicalcluster.AnnotationKey] = "root:org:ws"
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: annotations,
+			Labels: map[string]string{
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + syncTargetKey: string(workloadv1alpha1.ResourceStateSync),
+			},
+		},
+	}
+}
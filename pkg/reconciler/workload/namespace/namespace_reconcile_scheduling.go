@@ -38,7 +38,7 @@ import (
 const removingGracePeriod = 5 * time.Second
 
 // placementSchedulingReconciler reconciles the state.workload.kcp.dev/<syncTarget> labels according the
-// selected synctarget stored in the internal.workload.kcp.dev/synctarget annotation
+// selected synctargets stored in the internal.workload.kcp.dev/synctarget annotation
 // on each placement.
 type placementSchedulingReconciler struct {
 	listPlacement func(clusterName logicalcluster.Name) ([]*schedulingv1alpha1.Placement, error)
@@ -66,14 +66,11 @@ func (r *placementSchedulingReconciler) reconcile(ctx context.Context, ns *corev
 		validPlacements = filterValidPlacements(ns, placements)
 	}
 
-	// 1. pick all synctargets in all bound placements
+	// 1. pick all synctargets in all bound placements. A placement with a spread policy
+	// schedules to several synctargets.
 	scheduledSyncTargets := sets.NewString()
 	for _, placement := range validPlacements {
-		currentScheduled, foundScheduled := placement.Annotations[workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey]
-		if !foundScheduled {
-			continue
-		}
-		scheduledSyncTargets.Insert(currentScheduled)
+		scheduledSyncTargets.Insert(workloadv1alpha1.ParseSyncTargetKeys(placement.Annotations[workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey])...)
 	}
 
 	// 2. find the scheduled synctarget to the ns, including synced, removing
@@ -98,6 +98,24 @@ func TestScheduling(t *testing.T) {
 				workloadv1alpha1.ClusterResourceStateLabelPrefix + "34sZi3721YwBLDHUuNVIOLxuYp5nEZBpsTQyDq": string(workloadv1alpha1.ResourceStateSync),
 			},
 		},
+		{
+			name: "schedule a spread placement to every synctarget",
+			annotations: map[string]string{
+				schedulingv1alpha1.PlacementAnnotationKey: "",
+			},
+			labels: map[string]string{
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster1": string(workloadv1alpha1.ResourceStateSync),
+			},
+			placement: withSyncTargetKeys(newPlacement("test-placement", "test-location", ""), "cluster1", "cluster2"),
+			wantPatch: true,
+			expectedAnnotations: map[string]string{
+				schedulingv1alpha1.PlacementAnnotationKey: "",
+			},
+			expectedLabels: map[string]string{
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster1": string(workloadv1alpha1.ResourceStateSync),
+				workloadv1alpha1.ClusterResourceStateLabelPrefix + "cluster2": string(workloadv1alpha1.ResourceStateSync),
+			},
+		},
 		{
 			name: "synctargets becomes not ready",
 			annotations: map[string]string{
@@ -362,3 +380,10 @@ func newPlacement(name, location, synctarget string) *schedulingv1alpha1.Placeme
 
 	return placement
 }
+
+func withSyncTargetKeys(placement *schedulingv1alpha1.Placement, syncTargetKeys ...string) *schedulingv1alpha1.Placement {
+	placement.Annotations = map[string]string{
+		workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey: workloadv1alpha1.FormatSyncTargetKeys(syncTargetKeys),
+	}
+	return placement
+}
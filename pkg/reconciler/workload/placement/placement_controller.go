@@ -61,6 +61,7 @@ func NewController(
 	syncTargetInformer workloadinformers.SyncTargetClusterInformer,
 	placementInformer schedulinginformers.PlacementClusterInformer,
 	apiBindingInformer apisinformers.APIBindingClusterInformer,
+	scorer Scorer,
 ) (*controller, error) {
 	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), ControllerName)
 
@@ -78,6 +79,8 @@ func NewController(
 
 		apiBindingLister: apiBindingInformer.Lister(),
 
+		scorer: scorer,
+
 		commit: committer.NewCommitter[*Placement, Patcher, *PlacementSpec, *PlacementStatus](kcpClusterClient.SchedulingV1alpha1().Placements()),
 	}
 
@@ -112,16 +115,14 @@ func NewController(
 				oldClusterCopy.ResourceVersion = "0"
 				oldClusterCopy.Status.LastSyncerHeartbeatTime = nil
 				oldClusterCopy.Status.VirtualWorkspaces = nil
-				oldClusterCopy.Status.Capacity = nil
 
 				newCluster := obj.(*workloadv1alpha1.SyncTarget)
 				newClusterCopy := *newCluster
 				newClusterCopy.ResourceVersion = "0"
 				newClusterCopy.Status.LastSyncerHeartbeatTime = nil
 				newClusterCopy.Status.VirtualWorkspaces = nil
-				newClusterCopy.Status.Capacity = nil
 
-				// compare ignoring heart-beat
+				// compare ignoring heart-beat. Allocatable and requested resources are relevant for scoring.
 				if !reflect.DeepEqual(oldClusterCopy, newClusterCopy) {
 					c.enqueueSyncTarget(obj)
 				}
@@ -167,6 +168,7 @@ type controller struct {
 	placementIndexer cache.Indexer
 
 	apiBindingLister apislisters.APIBindingClusterLister
+	scorer           Scorer
 	commit           CommitFunc
 }
 
@@ -0,0 +1,55 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+func DefaultOptions() *Options {
+	return &Options{
+		Scorers: []string{string(LeastAllocatedScorer), string(LabelAffinityScorer)},
+	}
+}
+
+func BindOptions(o *Options, fs *pflag.FlagSet) *Options {
+	fs.StringSliceVar(&o.Scorers, "sync-target-scorers", o.Scorers, fmt.Sprintf("Scorers used to rank the SyncTargets of the selected location of a Placement. The scores are added up. Any of: %s, %s, %s.", LeastAllocatedScorer, MostAllocatedScorer, LabelAffinityScorer))
+	return o
+}
+
+type Options struct {
+	Scorers []string
+}
+
+func (o *Options) Validate() error {
+	if _, err := o.NewScorer(); err != nil {
+		return fmt.Errorf("--sync-target-scorers: %w", err)
+	}
+	return nil
+}
+
+// NewScorer returns the Scorer configured by the options.
+func (o *Options) NewScorer() (Scorer, error) {
+	types := make([]ScorerType, 0, len(o.Scorers))
+	for _, s := range o.Scorers {
+		types = append(types, ScorerType(strings.TrimSpace(s)))
+	}
+	return NewScorer(types...)
+}
@@ -25,6 +25,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	utilserrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/klog/v2"
 
 	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
@@ -46,6 +47,8 @@ type reconciler interface {
 func (c *controller) reconcile(ctx context.Context, placement *schedulingv1alpha1.Placement) (bool, error) {
 	reconcilers := []reconciler{
 		&placementSchedulingReconciler{
+			scorer:                  c.scorer,
+			intn:                    rand.Intn,
 			listSyncTarget:          c.listSyncTarget,
 			getLocation:             c.getLocation,
 			patchPlacement:          c.patchPlacement,
@@ -20,15 +20,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"math/rand"
 	"strings"
 
 	"github.com/kcp-dev/logicalcluster/v2"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 
 	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
@@ -40,9 +41,14 @@ import (
 )
 
 // placementSchedulingReconciler schedules placments according to the selected locations.
-// It considers only valid SyncTargets and updates the internal.workload.kcp.dev/synctarget
-// annotation with the selected one on the placement object.
+// It considers only valid SyncTargets, ranks them with the scorer and updates the
+// internal.workload.kcp.dev/synctarget annotation with the best ones on the placement object,
+// as many as requested by the spread policy. The score and the reason of each decision are
+// recorded in the placement status.
 type placementSchedulingReconciler struct {
+	scorer Scorer
+	intn   func(int) int
+
 	listSyncTarget          func(clusterName logicalcluster.Name) ([]*workloadv1alpha1.SyncTarget, error)
 	listWorkloadAPIBindings func(clusterName logicalcluster.Name) ([]*apisv1alpha1.APIBinding, error)
 	getLocation             func(clusterName logicalcluster.Name, name string) (*schedulingv1alpha1.Location, error)
@@ -54,50 +60,251 @@ func (r *placementSchedulingReconciler) reconcile(ctx context.Context, placement
 
 	// 1. get current scheduled
 	expectedAnnotations := map[string]interface{}{} // nil means to remove the key
-	currentScheduled, foundScheduled := placement.Annotations[workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey]
+	currentScheduled := workloadv1alpha1.ParseSyncTargetKeys(placement.Annotations[workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey])
 
 	// 2. pick all valid synctargets in this placements
-	locationWorkspace, validSyncTargets, reason, message, err := r.getAllValidSyncTargetsForPlacement(ctx, clusterName, placement)
+	locationWorkspace, validSyncTargets, heartbeatLostSyncTargets, reason, message, err := r.getAllValidSyncTargetsForPlacement(ctx, clusterName, placement)
 	if err != nil {
 		return reconcileStatusStopAndRequeue, placement, err
 	}
 
+	replicas, topologyKey := spreadPolicy(placement)
+
+	// spreading across locations is not supported, reject spread policies the selected location cannot satisfy.
+	if replicas > 1 {
+		available, found, err := r.locationSpreadCapacity(placement, topologyKey)
+		if err != nil {
+			return reconcileStatusStopAndRequeue, placement, err
+		}
+		if found && replicas > available {
+			if len(currentScheduled) > 0 {
+				expectedAnnotations[workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey] = nil
+				placement, err = r.patchPlacementAnnotation(ctx, clusterName, placement, expectedAnnotations)
+				return reconcileStatusStopAndRequeue, placement, err
+			}
+			placement.Status.ScheduledSyncTargets = nil
+			conditions.MarkFalse(placement, schedulingv1alpha1.PlacementScheduled, schedulingv1alpha1.ScheduleSpreadExceedsLocationReason, conditionsv1alpha1.ConditionSeverityError,
+				"spread.replicas %d exceeds the %d %s of the selected location, spreading across locations is not supported", replicas, available, spreadUnit(topologyKey))
+			return reconcileStatusContinue, placement, nil
+		}
+	}
+
+	if topologyKey != "" {
+		heartbeatLostSyncTargets = filterTopologyKey(heartbeatLostSyncTargets, topologyKey)
+		if len(validSyncTargets) > 0 {
+			validSyncTargets = filterTopologyKey(validSyncTargets, topologyKey)
+			if len(validSyncTargets) == 0 {
+				reason, message = schedulingv1alpha1.ScheduleNoValidTargetReason, fmt.Sprintf("No valid SyncTarget has the topology label %q", topologyKey)
+			}
+		}
+	}
+
+	// SyncTargets which only miss their heartbeat stay scheduled until the failover controller evicts them.
+	keptSyncTargets := make(map[string]*workloadv1alpha1.SyncTarget, len(validSyncTargets)+len(heartbeatLostSyncTargets))
+	for _, syncTargets := range [][]*workloadv1alpha1.SyncTarget{validSyncTargets, heartbeatLostSyncTargets} {
+		for _, syncTarget := range syncTargets {
+			keptSyncTargets[workloadv1alpha1.ToSyncTargetKey(logicalcluster.From(syncTarget), syncTarget.Name)] = syncTarget
+		}
+	}
+
 	// no valid synctarget, clean the annotation.
-	if len(validSyncTargets) == 0 {
-		if foundScheduled {
+	if len(validSyncTargets) == 0 && !anyScheduled(currentScheduled, keptSyncTargets) {
+		if len(currentScheduled) > 0 {
 			expectedAnnotations[workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey] = nil
 			placement, err = r.patchPlacementAnnotation(ctx, clusterName, placement, expectedAnnotations)
 			return reconcileStatusStopAndRequeue, placement, err
 		}
+		placement.Status.ScheduledSyncTargets = nil
 		conditions.MarkFalse(placement, schedulingv1alpha1.PlacementScheduled, reason, conditionsv1alpha1.ConditionSeverityWarning, message)
 		return reconcileStatusContinue, placement, nil
 	}
 
-	// 2. do nothing if scheduled cluster is in the valid clusters
-	if foundScheduled {
-		for _, syncTarget := range validSyncTargets {
-			syncTargetKey := workloadv1alpha1.ToSyncTargetKey(logicalcluster.From(syncTarget), syncTarget.Name)
-			if syncTargetKey != currentScheduled {
-				continue
-			}
-			conditions.MarkTrue(placement, schedulingv1alpha1.PlacementScheduled)
-			return reconcileStatusContinue, placement, nil
+	// 3. keep the scheduled clusters which are still valid
+	var scheduled []*workloadv1alpha1.SyncTarget
+	usedTopologies := sets.NewString()
+	for _, key := range currentScheduled {
+		syncTarget, found := keptSyncTargets[key]
+		if !found || len(scheduled) >= replicas || (topologyKey != "" && usedTopologies.Has(syncTarget.Labels[topologyKey])) {
+			continue
 		}
+		scheduled = append(scheduled, syncTarget)
+		usedTopologies.Insert(syncTarget.Labels[topologyKey])
 	}
 
-	// 3. randomly select one as the scheduled cluster
+	// 4. add the ones with the highest score until the spread policy is satisfied
 	// TODO(qiujian16): we currently schedule each in each location independently. It cannot guarantee 1 cluster is scheduled per location
 	// when the same synctargets are in multiple locations, we need to rethink whether we need a better algorithm or we need location
 	// to be exclusive.
-	scheduledSyncTarget := validSyncTargets[rand.Intn(len(validSyncTargets))]
-	expectedAnnotations[workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey] = workloadv1alpha1.ToSyncTargetKey(locationWorkspace, scheduledSyncTarget.Name)
+	var decisions []schedulingv1alpha1.ScheduledSyncTarget
+	for len(scheduled) < replicas {
+		candidates := spreadCandidates(validSyncTargets, scheduled, topologyKey, usedTopologies)
+		if len(candidates) == 0 {
+			break
+		}
+		syncTarget, score, scoreReason := chooseSyncTarget(r.scorer, r.intn, placement, candidates)
+		scheduled = append(scheduled, syncTarget)
+		usedTopologies.Insert(syncTarget.Labels[topologyKey])
+		decisions = append(decisions, schedulingv1alpha1.ScheduledSyncTarget{
+			Path:   locationWorkspace.String(),
+			Name:   syncTarget.Name,
+			Score:  score,
+			Reason: scoreReason,
+		})
+	}
+
+	scheduledKeys := make([]string, 0, len(scheduled))
+	for _, syncTarget := range scheduled {
+		scheduledKeys = append(scheduledKeys, workloadv1alpha1.ToSyncTargetKey(locationWorkspace, syncTarget.Name))
+	}
+
+	// 5. do nothing if the scheduled clusters have not changed
+	if equality.Semantic.DeepEqual(scheduledKeys, currentScheduled) {
+		if len(scheduled) < replicas {
+			conditions.MarkFalse(placement, schedulingv1alpha1.PlacementScheduled, schedulingv1alpha1.ScheduleNotEnoughTargetsReason, conditionsv1alpha1.ConditionSeverityWarning,
+				"Only %d of %d SyncTargets could be scheduled", len(scheduled), replicas)
+		} else {
+			conditions.MarkTrue(placement, schedulingv1alpha1.PlacementScheduled)
+		}
+		return reconcileStatusContinue, placement, nil
+	}
+
+	expectedAnnotations[workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey] = workloadv1alpha1.FormatSyncTargetKeys(scheduledKeys)
 	updated, err := r.patchPlacementAnnotation(ctx, clusterName, placement, expectedAnnotations)
+	if err != nil {
+		return reconcileStatusStopAndRequeue, updated, err
+	}
+
+	// 6. record the decisions, keeping those of the clusters still scheduled. The annotation patch has
+	// bumped the resourceVersion, hence this cannot go through the committer.
+	var scheduledSyncTargets []schedulingv1alpha1.ScheduledSyncTarget
+	for _, existing := range placement.Status.ScheduledSyncTargets {
+		for _, syncTarget := range scheduled[:len(scheduled)-len(decisions)] {
+			if existing.Path == locationWorkspace.String() && existing.Name == syncTarget.Name {
+				scheduledSyncTargets = append(scheduledSyncTargets, existing)
+				break
+			}
+		}
+	}
+	scheduledSyncTargets = append(scheduledSyncTargets, decisions...)
+	updated, err = r.patchPlacementScheduledSyncTargets(ctx, clusterName, updated, scheduledSyncTargets)
 	return reconcileStatusStopAndRequeue, updated, err
 }
 
-func (r *placementSchedulingReconciler) getAllValidSyncTargetsForPlacement(ctx context.Context, clusterName logicalcluster.Name, placement *schedulingv1alpha1.Placement) (logicalcluster.Name, []*workloadv1alpha1.SyncTarget, string, string, error) {
+// spreadPolicy returns the number of SyncTargets to schedule the placement to, and the
+// SyncTarget label whose values must be distinct.
+func spreadPolicy(placement *schedulingv1alpha1.Placement) (int, string) {
+	if placement.Spec.Spread == nil || placement.Spec.Spread.Replicas < 1 {
+		return 1, ""
+	}
+	return int(placement.Spec.Spread.Replicas), placement.Spec.Spread.TopologyKey
+}
+
+// spreadUnit describes what a spread policy with the given topology key spreads across.
+func spreadUnit(topologyKey string) string {
+	if topologyKey == "" {
+		return "SyncTargets"
+	}
+	return fmt.Sprintf("distinct values of the SyncTarget label %q", topologyKey)
+}
+
+// locationSpreadCapacity returns the maximum number of SyncTargets of the selected location the placement can be
+// spread across, whatever their state, i.e. the number of its SyncTargets, or the number of distinct values of the
+// topology label if given. It returns false if no location is selected or the location is not found.
+func (r *placementSchedulingReconciler) locationSpreadCapacity(placement *schedulingv1alpha1.Placement, topologyKey string) (int, bool, error) {
+	if placement.Status.SelectedLocation == nil {
+		return 0, false, nil
+	}
+
+	locationWorkspace := logicalcluster.New(placement.Status.SelectedLocation.Path)
+	location, err := r.getLocation(locationWorkspace, placement.Status.SelectedLocation.LocationName)
+	if errors.IsNotFound(err) {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, err
+	}
+	syncTargets, err := r.listSyncTarget(locationWorkspace)
+	if err != nil {
+		return 0, false, err
+	}
+	locationSyncTargets, err := locationreconciler.LocationSyncTargets(syncTargets, location)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if topologyKey == "" {
+		return len(locationSyncTargets), true, nil
+	}
+	topologies := sets.NewString()
+	for _, syncTarget := range locationSyncTargets {
+		if value, found := syncTarget.Labels[topologyKey]; found {
+			topologies.Insert(value)
+		}
+	}
+	return topologies.Len(), true, nil
+}
+
+// anyScheduled returns true if any of the given SyncTarget keys is in syncTargets.
+func anyScheduled(keys []string, syncTargets map[string]*workloadv1alpha1.SyncTarget) bool {
+	for _, key := range keys {
+		if _, found := syncTargets[key]; found {
+			return true
+		}
+	}
+	return false
+}
+
+// filterHeartbeatLost returns the schedulable SyncTargets which are not ready only because the
+// heartbeat of their syncer is missing.
+func filterHeartbeatLost(syncTargets []*workloadv1alpha1.SyncTarget) []*workloadv1alpha1.SyncTarget {
+	var lost []*workloadv1alpha1.SyncTarget
+	for _, syncTarget := range syncTargets {
+		if syncTarget.Spec.Unschedulable || !conditions.IsFalse(syncTarget, workloadv1alpha1.HeartbeatHealthy) ||
+			conditions.IsFalse(syncTarget, workloadv1alpha1.SyncerReady) || conditions.IsFalse(syncTarget, workloadv1alpha1.APIImporterReady) {
+			continue
+		}
+		lost = append(lost, syncTarget)
+	}
+	return lost
+}
+
+// filterTopologyKey returns the SyncTargets having the topology label.
+func filterTopologyKey(syncTargets []*workloadv1alpha1.SyncTarget, topologyKey string) []*workloadv1alpha1.SyncTarget {
+	var filtered []*workloadv1alpha1.SyncTarget
+	for _, syncTarget := range syncTargets {
+		if _, found := syncTarget.Labels[topologyKey]; found {
+			filtered = append(filtered, syncTarget)
+		}
+	}
+	return filtered
+}
+
+// spreadCandidates returns the SyncTargets which are not scheduled yet and, if a topology key
+// is given, not in a topology already used.
+func spreadCandidates(syncTargets, scheduled []*workloadv1alpha1.SyncTarget, topologyKey string, usedTopologies sets.String) []*workloadv1alpha1.SyncTarget {
+	var candidates []*workloadv1alpha1.SyncTarget
+	for _, syncTarget := range syncTargets {
+		if topologyKey != "" && usedTopologies.Has(syncTarget.Labels[topologyKey]) {
+			continue
+		}
+		isScheduled := false
+		for _, s := range scheduled {
+			if s == syncTarget {
+				isScheduled = true
+				break
+			}
+		}
+		if !isScheduled {
+			candidates = append(candidates, syncTarget)
+		}
+	}
+	return candidates
+}
+
+// getAllValidSyncTargetsForPlacement returns the ready SyncTargets of the selected location of the placement, and
+// those which are not ready only because their heartbeat is missing. The latter are not evicting yet.
+func (r *placementSchedulingReconciler) getAllValidSyncTargetsForPlacement(ctx context.Context, clusterName logicalcluster.Name, placement *schedulingv1alpha1.Placement) (logicalcluster.Name, []*workloadv1alpha1.SyncTarget, []*workloadv1alpha1.SyncTarget, string, string, error) {
 	if placement.Status.Phase == schedulingv1alpha1.PlacementPending || placement.Status.SelectedLocation == nil {
-		return logicalcluster.Name{}, nil, schedulingv1alpha1.ScheduleLocationNotFound, "No selected location is scheduled", nil
+		return logicalcluster.Name{}, nil, nil, schedulingv1alpha1.ScheduleLocationNotFound, "No selected location is scheduled", nil
 	}
 
 	locationWorkspace := logicalcluster.New(placement.Status.SelectedLocation.Path)
@@ -106,36 +313,38 @@ func (r *placementSchedulingReconciler) getAllValidSyncTargetsForPlacement(ctx c
 		placement.Status.SelectedLocation.LocationName)
 	switch {
 	case errors.IsNotFound(err):
-		return locationWorkspace, nil, schedulingv1alpha1.ScheduleLocationNotFound, "Selected location is not found", nil
+		return locationWorkspace, nil, nil, schedulingv1alpha1.ScheduleLocationNotFound, "Selected location is not found", nil
 	case err != nil:
-		return locationWorkspace, nil, "", "", err
+		return locationWorkspace, nil, nil, "", "", err
 	}
 
 	// find all synctargets in the location workspace
 	syncTargets, err := r.listSyncTarget(locationWorkspace)
 	if err != nil {
-		return locationWorkspace, nil, "", "", err
+		return locationWorkspace, nil, nil, "", "", err
 	}
 
 	// filter the SyncTargets by location
 	locationSyncTargets, err := locationreconciler.LocationSyncTargets(syncTargets, location)
 	if len(locationSyncTargets) == 0 || err != nil {
-		return locationWorkspace, nil, schedulingv1alpha1.ScheduleNoValidTargetReason, "No SyncTarget in the selected Location", err
+		return locationWorkspace, nil, nil, schedulingv1alpha1.ScheduleNoValidTargetReason, "No SyncTarget in the selected Location", err
 	}
 
 	// filter the SyncTargets by APIs
 	validSyncTargets, message, err := r.filterAPICompatible(ctx, clusterName, locationSyncTargets)
 	if len(validSyncTargets) == 0 || err != nil {
-		return locationWorkspace, nil, schedulingv1alpha1.ScheduleNoValidTargetReason, message, err
+		return locationWorkspace, nil, nil, schedulingv1alpha1.ScheduleNoValidTargetReason, message, err
 	}
 
 	// filter the SyncTargets by status.
-	validSyncTargets = locationreconciler.FilterNonEvicting(locationreconciler.FilterReady(validSyncTargets))
+	nonEvicting := locationreconciler.FilterNonEvicting(validSyncTargets)
+	validSyncTargets = locationreconciler.FilterReady(nonEvicting)
+	heartbeatLost := filterHeartbeatLost(nonEvicting)
 	if len(validSyncTargets) == 0 {
-		return locationWorkspace, validSyncTargets, schedulingv1alpha1.ScheduleNoValidTargetReason, "No SyncTarget is ready or non evicting", nil
+		return locationWorkspace, validSyncTargets, heartbeatLost, schedulingv1alpha1.ScheduleNoValidTargetReason, "No SyncTarget is ready or non evicting", nil
 	}
 
-	return locationWorkspace, validSyncTargets, "", "", nil
+	return locationWorkspace, validSyncTargets, heartbeatLost, "", "", nil
 }
 
 func (r *placementSchedulingReconciler) filterAPICompatible(ctx context.Context, clusterName logicalcluster.Name, syncTargets []*workloadv1alpha1.SyncTarget) ([]*workloadv1alpha1.SyncTarget, string, error) {
@@ -199,3 +408,21 @@ func (r *placementSchedulingReconciler) patchPlacementAnnotation(ctx context.Con
 	}
 	return updated, nil
 }
+
+func (r *placementSchedulingReconciler) patchPlacementScheduledSyncTargets(ctx context.Context, clusterName logicalcluster.Name, placement *schedulingv1alpha1.Placement, scheduled []schedulingv1alpha1.ScheduledSyncTarget) (*schedulingv1alpha1.Placement, error) {
+	logger := klog.FromContext(ctx)
+	patchBytes, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{
+			"scheduledSyncTargets": scheduled,
+		},
+	})
+	if err != nil {
+		return placement, err
+	}
+	logger.WithValues("patch", string(patchBytes)).V(3).Info("patching Placement to record the scheduling decision")
+	updated, err := r.patchPlacement(ctx, clusterName, placement.Name, types.MergePatchType, patchBytes, metav1.PatchOptions{}, "status")
+	if err != nil {
+		return placement, err
+	}
+	return updated, nil
+}
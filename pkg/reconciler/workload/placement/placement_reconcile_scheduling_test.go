@@ -19,7 +19,9 @@ package placement
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
 	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/kcp-dev/logicalcluster/v2"
@@ -27,6 +29,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
@@ -47,8 +50,9 @@ func TestSchedulingReconcile(t *testing.T) {
 		syncTargets []*workloadv1alpha1.SyncTarget
 		apiBindings []*apisv1alpha1.APIBinding
 
-		wantPatch           bool
-		expectedAnnotations map[string]string
+		wantPatch                    bool
+		expectedAnnotations          map[string]string
+		expectedScheduledSyncTargets []schedulingv1alpha1.ScheduledSyncTarget
 	}{
 		{
 			name:      "no location",
@@ -68,6 +72,25 @@ func TestSchedulingReconcile(t *testing.T) {
 			expectedAnnotations: map[string]string{
 				workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey: "aQtdeEWVcqU7h7AKnYMm3KRQ96U4oU2W04yeOa",
 			},
+			expectedScheduledSyncTargets: []schedulingv1alpha1.ScheduledSyncTarget{
+				{Name: "c1", Score: 0, Reason: "least-allocated=0 (no resources reported)"},
+			},
+		},
+		{
+			name:      "schedule to the least allocated synctarget",
+			placement: newPlacement("test", "test-location", ""),
+			location:  newLocation("test-location"),
+			syncTargets: []*workloadv1alpha1.SyncTarget{
+				withResources("cpu=10,memory=10Gi", "cpu=8,memory=8Gi", newSyncTarget("c1", true)),
+				withResources("cpu=10,memory=10Gi", "cpu=2,memory=4Gi", newSyncTarget("c2", true)),
+			},
+			wantPatch: true,
+			expectedAnnotations: map[string]string{
+				workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey: "aPkhvUbGK0xoZIjMnM2pA0AuV1g7i4tBwxu5m4",
+			},
+			expectedScheduledSyncTargets: []schedulingv1alpha1.ScheduledSyncTarget{
+				{Name: "c2", Score: 70, Reason: "least-allocated=70 (cpu 80% free, memory 60% free)"},
+			},
 		},
 		{
 			name:        "synctarget scheduled",
@@ -95,6 +118,37 @@ func TestSchedulingReconcile(t *testing.T) {
 			expectedAnnotations: map[string]string{
 				workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey: "aPkhvUbGK0xoZIjMnM2pA0AuV1g7i4tBwxu5m4",
 			},
+			expectedScheduledSyncTargets: []schedulingv1alpha1.ScheduledSyncTarget{
+				{Name: "c2", Score: 0, Reason: "least-allocated=0 (no resources reported)"},
+			},
+		},
+		{
+			name:        "keep synctarget missing its heartbeat",
+			placement:   newPlacement("test", "test-location", "c1"),
+			location:    newLocation("test-location"),
+			syncTargets: []*workloadv1alpha1.SyncTarget{withHeartbeatLost(newSyncTarget("c1", false)), newSyncTarget("c2", true)},
+			expectedAnnotations: map[string]string{
+				workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey: "aQtdeEWVcqU7h7AKnYMm3KRQ96U4oU2W04yeOa",
+			},
+		},
+		{
+			name:        "reschedule synctarget missing its heartbeat after eviction",
+			placement:   newPlacement("test", "test-location", "c1"),
+			location:    newLocation("test-location"),
+			syncTargets: []*workloadv1alpha1.SyncTarget{withEvictAfter(withHeartbeatLost(newSyncTarget("c1", false))), newSyncTarget("c2", true)},
+			wantPatch:   true,
+			expectedAnnotations: map[string]string{
+				workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey: "aPkhvUbGK0xoZIjMnM2pA0AuV1g7i4tBwxu5m4",
+			},
+			expectedScheduledSyncTargets: []schedulingv1alpha1.ScheduledSyncTarget{
+				{Name: "c2", Score: 0, Reason: "least-allocated=0 (no resources reported)"},
+			},
+		},
+		{
+			name:        "do not schedule to synctarget missing its heartbeat",
+			placement:   newPlacement("test", "test-location", ""),
+			location:    newLocation("test-location"),
+			syncTargets: []*workloadv1alpha1.SyncTarget{withHeartbeatLost(newSyncTarget("c1", false))},
 		},
 		{
 			name:      "schedule to syncTarget with compatible APIs",
@@ -111,6 +165,9 @@ func TestSchedulingReconcile(t *testing.T) {
 			expectedAnnotations: map[string]string{
 				workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey: "aPkhvUbGK0xoZIjMnM2pA0AuV1g7i4tBwxu5m4",
 			},
+			expectedScheduledSyncTargets: []schedulingv1alpha1.ScheduledSyncTarget{
+				{Name: "c2", Score: 0, Reason: "least-allocated=0 (no resources reported)"},
+			},
 		},
 		{
 			name:      "no syncTarget has compatible APIs",
@@ -125,6 +182,72 @@ func TestSchedulingReconcile(t *testing.T) {
 			},
 			wantPatch: false,
 		},
+		{
+			name:      "spread over synctargets in distinct topologies",
+			placement: withSpread(2, "region", newPlacement("test", "test-location", "")),
+			location:  newLocation("test-location"),
+			syncTargets: []*workloadv1alpha1.SyncTarget{
+				withLabels(map[string]string{"region": "eu"}, newSyncTarget("c1", true)),
+				withLabels(map[string]string{"region": "eu"}, newSyncTarget("c2", true)),
+				withLabels(map[string]string{"region": "us"}, newSyncTarget("c3", true)),
+				newSyncTarget("c4", true),
+			},
+			wantPatch: true,
+			expectedAnnotations: map[string]string{
+				workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey: syncTargetKeys("c1", "c3"),
+			},
+			expectedScheduledSyncTargets: []schedulingv1alpha1.ScheduledSyncTarget{
+				{Name: "c1", Score: 0, Reason: "least-allocated=0 (no resources reported)"},
+				{Name: "c3", Score: 0, Reason: "least-allocated=0 (no resources reported)"},
+			},
+		},
+		{
+			name: "spread keeps scheduled synctargets",
+			placement: withScheduledSyncTargets(
+				[]schedulingv1alpha1.ScheduledSyncTarget{{Name: "c2", Score: 42, Reason: "earlier"}},
+				withSpread(2, "", newPlacement("test", "test-location", "c2")),
+			),
+			location:    newLocation("test-location"),
+			syncTargets: []*workloadv1alpha1.SyncTarget{newSyncTarget("c1", true), newSyncTarget("c2", true), newSyncTarget("c3", true)},
+			wantPatch:   true,
+			expectedAnnotations: map[string]string{
+				workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey: syncTargetKeys("c2", "c1"),
+			},
+			expectedScheduledSyncTargets: []schedulingv1alpha1.ScheduledSyncTarget{
+				{Name: "c2", Score: 42, Reason: "earlier"},
+				{Name: "c1", Score: 0, Reason: "least-allocated=0 (no resources reported)"},
+			},
+		},
+		{
+			name:        "spread replaces invalid synctargets",
+			placement:   withSpread(2, "", withAnnotation(syncTargetKeys("c1", "c2"), newPlacement("test", "test-location", ""))),
+			location:    newLocation("test-location"),
+			syncTargets: []*workloadv1alpha1.SyncTarget{newSyncTarget("c1", true), newSyncTarget("c2", false), newSyncTarget("c3", true)},
+			wantPatch:   true,
+			expectedAnnotations: map[string]string{
+				workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey: syncTargetKeys("c1", "c3"),
+			},
+			expectedScheduledSyncTargets: []schedulingv1alpha1.ScheduledSyncTarget{
+				{Name: "c3", Score: 0, Reason: "least-allocated=0 (no resources reported)"},
+			},
+		},
+		{
+			name:                "spread exceeding the selected location",
+			placement:           withSpread(3, "", withAnnotation(syncTargetKeys("c1", "c2"), newPlacement("test", "test-location", ""))),
+			location:            newLocation("test-location"),
+			syncTargets:         []*workloadv1alpha1.SyncTarget{newSyncTarget("c1", true), newSyncTarget("c2", true)},
+			wantPatch:           true,
+			expectedAnnotations: map[string]string{},
+		},
+		{
+			name:        "spread scheduled",
+			placement:   withSpread(2, "", withAnnotation(syncTargetKeys("c1", "c2"), newPlacement("test", "test-location", ""))),
+			location:    newLocation("test-location"),
+			syncTargets: []*workloadv1alpha1.SyncTarget{newSyncTarget("c1", true), newSyncTarget("c2", true), newSyncTarget("c3", true)},
+			expectedAnnotations: map[string]string{
+				workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey: syncTargetKeys("c1", "c2"),
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -139,9 +262,10 @@ func TestSchedulingReconcile(t *testing.T) {
 				return testCase.location, nil
 			}
 			var patched bool
+			current := testCase.placement
 			patchPlacement := func(ctx context.Context, clusterName logicalcluster.Name, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*schedulingv1alpha1.Placement, error) {
 				patched = true
-				nsData, _ := json.Marshal(testCase.placement)
+				nsData, _ := json.Marshal(current)
 				updatedData, err := jsonpatch.MergePatch(nsData, data)
 				if err != nil {
 					return nil, err
@@ -150,14 +274,19 @@ func TestSchedulingReconcile(t *testing.T) {
 				var patchedPlacement schedulingv1alpha1.Placement
 				err = json.Unmarshal(updatedData, &patchedPlacement)
 				if err != nil {
-					return testCase.placement, err
+					return current, err
 				}
+				current = &patchedPlacement
 				return &patchedPlacement, err
 			}
 			listWorkloadAPIBindings := func(clusterName logicalcluster.Name) ([]*apisv1alpha1.APIBinding, error) {
 				return testCase.apiBindings, nil
 			}
+			scorer, err := NewScorer(LeastAllocatedScorer)
+			require.NoError(t, err)
 			reconciler := &placementSchedulingReconciler{
+				scorer:                  scorer,
+				intn:                    func(int) int { return 0 },
 				listSyncTarget:          listSyncTarget,
 				getLocation:             getLocation,
 				patchPlacement:          patchPlacement,
@@ -168,6 +297,7 @@ func TestSchedulingReconcile(t *testing.T) {
 			require.NoError(t, err)
 			require.Equal(t, testCase.wantPatch, patched)
 			require.Equal(t, testCase.expectedAnnotations, updated.Annotations)
+			require.Equal(t, testCase.expectedScheduledSyncTargets, updated.Status.ScheduledSyncTargets)
 		})
 	}
 }
@@ -231,6 +361,46 @@ func TestReconcileStatusConditions(t *testing.T) {
 			wantStausReason: schedulingv1alpha1.ScheduleNoValidTargetReason,
 			wantMessage:     "SyncTarget c1 does not support APIBinding kubernetes, SyncTarget c2 does not support APIBinding kubernetes",
 		},
+		{
+			name:            "not enough synctargets to spread",
+			placement:       withSpread(3, "", withAnnotation(syncTargetKeys("c1", "c2"), newPlacement("test", "test-location", ""))),
+			location:        newLocation("test-location"),
+			syncTargets:     []*workloadv1alpha1.SyncTarget{newSyncTarget("c1", true), newSyncTarget("c2", true), newSyncTarget("c3", false)},
+			wantStatus:      corev1.ConditionFalse,
+			wantStausReason: schedulingv1alpha1.ScheduleNotEnoughTargetsReason,
+			wantMessage:     "Only 2 of 3 SyncTargets could be scheduled",
+		},
+		{
+			name:            "spread exceeding the synctargets of the location",
+			placement:       withSpread(3, "", newPlacement("test", "test-location", "")),
+			location:        newLocation("test-location"),
+			syncTargets:     []*workloadv1alpha1.SyncTarget{newSyncTarget("c1", true), newSyncTarget("c2", true)},
+			wantStatus:      corev1.ConditionFalse,
+			wantStausReason: schedulingv1alpha1.ScheduleSpreadExceedsLocationReason,
+			wantMessage:     "spread.replicas 3 exceeds the 2 SyncTargets of the selected location, spreading across locations is not supported",
+		},
+		{
+			name:      "spread exceeding the topologies of the location",
+			placement: withSpread(2, "region", newPlacement("test", "test-location", "")),
+			location:  newLocation("test-location"),
+			syncTargets: []*workloadv1alpha1.SyncTarget{
+				withLabels(map[string]string{"region": "eu"}, newSyncTarget("c1", true)),
+				withLabels(map[string]string{"region": "eu"}, newSyncTarget("c2", true)),
+				newSyncTarget("c3", true),
+			},
+			wantStatus:      corev1.ConditionFalse,
+			wantStausReason: schedulingv1alpha1.ScheduleSpreadExceedsLocationReason,
+			wantMessage:     `spread.replicas 2 exceeds the 1 distinct values of the SyncTarget label "region" of the selected location, spreading across locations is not supported`,
+		},
+		{
+			name:            "no synctarget with topology label",
+			placement:       withSpread(1, "region", newPlacement("test", "test-location", "")),
+			location:        newLocation("test-location"),
+			syncTargets:     []*workloadv1alpha1.SyncTarget{newSyncTarget("c1", true)},
+			wantStatus:      corev1.ConditionFalse,
+			wantStausReason: schedulingv1alpha1.ScheduleNoValidTargetReason,
+			wantMessage:     `No valid SyncTarget has the topology label "region"`,
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -331,6 +501,58 @@ func newSyncTarget(name string, ready bool, resources ...workloadv1alpha1.Resour
 	return syncTarget
 }
 
+func withHeartbeatLost(syncTarget *workloadv1alpha1.SyncTarget) *workloadv1alpha1.SyncTarget {
+	conditions.MarkFalse(syncTarget, conditionsapi.ReadyCondition, workloadv1alpha1.ErrorHeartbeatMissedReason, conditionsapi.ConditionSeverityWarning, "")
+	conditions.MarkFalse(syncTarget, workloadv1alpha1.HeartbeatHealthy, workloadv1alpha1.ErrorHeartbeatMissedReason, conditionsapi.ConditionSeverityWarning, "")
+	return syncTarget
+}
+
+func withEvictAfter(syncTarget *workloadv1alpha1.SyncTarget) *workloadv1alpha1.SyncTarget {
+	syncTarget.Spec.EvictAfter = &metav1.Time{Time: time.Now().Add(-time.Minute)}
+	return syncTarget
+}
+
+func withSpread(replicas int32, topologyKey string, placement *schedulingv1alpha1.Placement) *schedulingv1alpha1.Placement {
+	placement.Spec.Spread = &schedulingv1alpha1.SpreadPolicy{Replicas: replicas, TopologyKey: topologyKey}
+	return placement
+}
+
+func withAnnotation(syncTargetKeys string, placement *schedulingv1alpha1.Placement) *schedulingv1alpha1.Placement {
+	placement.Annotations = map[string]string{
+		workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey: syncTargetKeys,
+	}
+	return placement
+}
+
+func withScheduledSyncTargets(scheduled []schedulingv1alpha1.ScheduledSyncTarget, placement *schedulingv1alpha1.Placement) *schedulingv1alpha1.Placement {
+	placement.Status.ScheduledSyncTargets = scheduled
+	return placement
+}
+
+func syncTargetKeys(names ...string) string {
+	keys := make([]string, 0, len(names))
+	for _, name := range names {
+		keys = append(keys, workloadv1alpha1.ToSyncTargetKey(logicalcluster.New(""), name))
+	}
+	return workloadv1alpha1.FormatSyncTargetKeys(keys)
+}
+
+func withResources(allocatable, requested string, syncTarget *workloadv1alpha1.SyncTarget) *workloadv1alpha1.SyncTarget {
+	allocatableList, requestedList := resourceList(allocatable), resourceList(requested)
+	syncTarget.Status.Allocatable = &allocatableList
+	syncTarget.Status.Requested = &requestedList
+	return syncTarget
+}
+
+func resourceList(s string) corev1.ResourceList {
+	rl := corev1.ResourceList{}
+	for _, pair := range strings.Split(s, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		rl[corev1.ResourceName(parts[0])] = resource.MustParse(parts[1])
+	}
+	return rl
+}
+
 func newAPIBinding(name string, resources ...apisv1alpha1.BoundAPIResource) *apisv1alpha1.APIBinding {
 	return &apisv1alpha1.APIBinding{
 		ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,189 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+)
+
+// ScorerType is the name of a scorer ranking SyncTargets for a Placement.
+type ScorerType string
+
+const (
+	// LeastAllocatedScorer prefers SyncTargets with a high share of allocatable resources not
+	// requested by pods, spreading workloads over the SyncTargets of a location.
+	LeastAllocatedScorer ScorerType = "least-allocated"
+	// MostAllocatedScorer prefers SyncTargets with a low share of allocatable resources not
+	// requested by pods, filling up SyncTargets one after the other.
+	MostAllocatedScorer ScorerType = "most-allocated"
+	// LabelAffinityScorer prefers SyncTargets matching the syncTargetAffinity of the Placement.
+	LabelAffinityScorer ScorerType = "label-affinity"
+)
+
+// MaxResourceScore is the highest score given by the resource based scorers.
+const MaxResourceScore = 100
+
+// scoredResources are the resources taken into account by the resource based scorers.
+var scoredResources = []corev1.ResourceName{
+	corev1.ResourceCPU,
+	corev1.ResourceMemory,
+}
+
+// Scorer ranks the SyncTargets a Placement can be scheduled to.
+type Scorer interface {
+	// Score returns the score of the SyncTarget for the Placement, higher is better, and a human
+	// readable reason.
+	Score(placement *schedulingv1alpha1.Placement, syncTarget *workloadv1alpha1.SyncTarget) (int64, string)
+}
+
+// NewScorer returns a Scorer adding up the scores of the given scorers.
+func NewScorer(types ...ScorerType) (Scorer, error) {
+	var scorers []namedScorer
+	seen := map[ScorerType]bool{}
+	for _, t := range types {
+		if seen[t] {
+			return nil, fmt.Errorf("duplicate scorer %q", t)
+		}
+		seen[t] = true
+
+		switch t {
+		case LeastAllocatedScorer:
+			scorers = append(scorers, namedScorer{name: t, Scorer: allocationScorer{leastAllocated: true}})
+		case MostAllocatedScorer:
+			scorers = append(scorers, namedScorer{name: t, Scorer: allocationScorer{}})
+		case LabelAffinityScorer:
+			scorers = append(scorers, namedScorer{name: t, Scorer: labelAffinityScorer{}})
+		default:
+			return nil, fmt.Errorf("unknown scorer %q", t)
+		}
+	}
+	if seen[LeastAllocatedScorer] && seen[MostAllocatedScorer] {
+		return nil, fmt.Errorf("scorers %q and %q are mutually exclusive", LeastAllocatedScorer, MostAllocatedScorer)
+	}
+	return sumScorer(scorers), nil
+}
+
+type namedScorer struct {
+	Scorer
+	name ScorerType
+}
+
+// sumScorer adds up the scores of all its scorers.
+type sumScorer []namedScorer
+
+func (s sumScorer) Score(placement *schedulingv1alpha1.Placement, syncTarget *workloadv1alpha1.SyncTarget) (int64, string) {
+	var total int64
+	reasons := make([]string, 0, len(s))
+	for _, scorer := range s {
+		score, reason := scorer.Score(placement, syncTarget)
+		total += score
+		reasons = append(reasons, fmt.Sprintf("%s=%d (%s)", scorer.name, score, reason))
+	}
+	return total, strings.Join(reasons, ", ")
+}
+
+// allocationScorer scores SyncTargets by the share of their allocatable resources that is not
+// requested by pods yet, averaged over the scored resources. SyncTargets not reporting their
+// resources score zero.
+type allocationScorer struct {
+	leastAllocated bool
+}
+
+func (s allocationScorer) Score(_ *schedulingv1alpha1.Placement, syncTarget *workloadv1alpha1.SyncTarget) (int64, string) {
+	if syncTarget.Status.Allocatable == nil || syncTarget.Status.Requested == nil {
+		return 0, "no resources reported"
+	}
+
+	var sum float64
+	var count int
+	var details []string
+	for _, name := range scoredResources {
+		allocatable, found := (*syncTarget.Status.Allocatable)[name]
+		if !found || allocatable.IsZero() {
+			continue
+		}
+		free := 1.0
+		if requested, found := (*syncTarget.Status.Requested)[name]; found {
+			free = 1 - float64(requested.MilliValue())/float64(allocatable.MilliValue())
+		}
+		if free < 0 {
+			free = 0
+		} else if free > 1 {
+			free = 1
+		}
+		sum += free
+		count++
+		details = append(details, fmt.Sprintf("%s %d%% free", name, int64(free*100)))
+	}
+	if count == 0 {
+		return 0, "no resources reported"
+	}
+
+	free := sum / float64(count)
+	if !s.leastAllocated {
+		free = 1 - free
+	}
+	return int64(free * MaxResourceScore), strings.Join(details, ", ")
+}
+
+// labelAffinityScorer adds up the weights of the syncTargetAffinity terms of the Placement
+// matching the labels of the SyncTarget.
+type labelAffinityScorer struct{}
+
+func (labelAffinityScorer) Score(placement *schedulingv1alpha1.Placement, syncTarget *workloadv1alpha1.SyncTarget) (int64, string) {
+	var score int64
+	var matched int
+	for _, term := range placement.Spec.SyncTargetAffinity {
+		selector, err := metav1.LabelSelectorAsSelector(&term.Selector)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(syncTarget.Labels)) {
+			score += int64(term.Weight)
+			matched++
+		}
+	}
+	return score, fmt.Sprintf("%d of %d affinity terms matched", matched, len(placement.Spec.SyncTargetAffinity))
+}
+
+// chooseSyncTarget returns the SyncTarget with the highest score, breaking ties randomly
+// with intn. syncTargets must not be empty.
+func chooseSyncTarget(scorer Scorer, intn func(int) int, placement *schedulingv1alpha1.Placement, syncTargets []*workloadv1alpha1.SyncTarget) (*workloadv1alpha1.SyncTarget, int64, string) {
+	var best []*workloadv1alpha1.SyncTarget
+	var bestReasons []string
+	var bestScore int64
+	for _, syncTarget := range syncTargets {
+		score, reason := scorer.Score(placement, syncTarget)
+		switch {
+		case len(best) == 0 || score > bestScore:
+			best, bestReasons, bestScore = []*workloadv1alpha1.SyncTarget{syncTarget}, []string{reason}, score
+		case score == bestScore:
+			best, bestReasons = append(best, syncTarget), append(bestReasons, reason)
+		}
+	}
+
+	i := intn(len(best))
+	return best[i], bestScore, bestReasons[i]
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+)
+
+func TestScorer(t *testing.T) {
+	affinity := newPlacement("test", "test-location", "")
+	affinity.Spec.SyncTargetAffinity = []schedulingv1alpha1.WeightedSyncTargetSelector{
+		{Weight: 30, Selector: metav1.LabelSelector{MatchLabels: map[string]string{"region": "eu"}}},
+		{Weight: 20, Selector: metav1.LabelSelector{MatchLabels: map[string]string{"gpu": "true"}}},
+	}
+
+	tests := map[string]struct {
+		scorers    []ScorerType
+		placement  *schedulingv1alpha1.Placement
+		syncTarget *workloadv1alpha1.SyncTarget
+
+		wantScore  int64
+		wantReason string
+	}{
+		"least allocated": {
+			scorers:    []ScorerType{LeastAllocatedScorer},
+			placement:  newPlacement("test", "test-location", ""),
+			syncTarget: withResources("cpu=4,memory=8Gi", "cpu=3,memory=4Gi", newSyncTarget("c1", true)),
+			wantScore:  37,
+			wantReason: "least-allocated=37 (cpu 25% free, memory 50% free)",
+		},
+		"most allocated": {
+			scorers:    []ScorerType{MostAllocatedScorer},
+			placement:  newPlacement("test", "test-location", ""),
+			syncTarget: withResources("cpu=4,memory=8Gi", "cpu=3,memory=4Gi", newSyncTarget("c1", true)),
+			wantScore:  62,
+			wantReason: "most-allocated=62 (cpu 25% free, memory 50% free)",
+		},
+		"only some resources reported": {
+			scorers:    []ScorerType{LeastAllocatedScorer},
+			placement:  newPlacement("test", "test-location", ""),
+			syncTarget: withResources("cpu=4", "cpu=2", newSyncTarget("c1", true)),
+			wantScore:  50,
+			wantReason: "least-allocated=50 (cpu 50% free)",
+		},
+		"nothing reported": {
+			scorers:    []ScorerType{MostAllocatedScorer},
+			placement:  newPlacement("test", "test-location", ""),
+			syncTarget: newSyncTarget("c1", true),
+			wantReason: "most-allocated=0 (no resources reported)",
+		},
+		"label affinity": {
+			scorers:    []ScorerType{LabelAffinityScorer},
+			placement:  affinity,
+			syncTarget: withLabels(map[string]string{"region": "eu"}, newSyncTarget("c1", true)),
+			wantScore:  30,
+			wantReason: "label-affinity=30 (1 of 2 affinity terms matched)",
+		},
+		"combined": {
+			scorers:    []ScorerType{LeastAllocatedScorer, LabelAffinityScorer},
+			placement:  affinity,
+			syncTarget: withLabels(map[string]string{"region": "eu", "gpu": "true"}, withResources("cpu=4", "cpu=0", newSyncTarget("c1", true))),
+			wantScore:  150,
+			wantReason: "least-allocated=100 (cpu 100% free), label-affinity=50 (2 of 2 affinity terms matched)",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			scorer, err := NewScorer(tc.scorers...)
+			require.NoError(t, err)
+			score, reason := scorer.Score(tc.placement, tc.syncTarget)
+			require.Equal(t, tc.wantScore, score)
+			require.Equal(t, tc.wantReason, reason)
+		})
+	}
+}
+
+func TestNewScorerErrors(t *testing.T) {
+	_, err := NewScorer("unknown")
+	require.Error(t, err)
+	_, err = NewScorer(LeastAllocatedScorer, LeastAllocatedScorer)
+	require.Error(t, err)
+	_, err = NewScorer(LeastAllocatedScorer, MostAllocatedScorer)
+	require.Error(t, err)
+}
+
+func TestChooseSyncTarget(t *testing.T) {
+	scorer, err := NewScorer(LeastAllocatedScorer)
+	require.NoError(t, err)
+
+	c1 := withResources("cpu=4", "cpu=3", newSyncTarget("c1", true))
+	c2 := withResources("cpu=4", "cpu=1", newSyncTarget("c2", true))
+	c3 := withResources("cpu=8", "cpu=2", newSyncTarget("c3", true))
+
+	var candidates int
+	chosen, score, reason := chooseSyncTarget(scorer, func(n int) int { candidates = n; return n - 1 }, newPlacement("test", "test-location", ""), []*workloadv1alpha1.SyncTarget{c1, c2, c3})
+	require.Equal(t, 2, candidates, "c2 and c3 should tie")
+	require.Equal(t, "c3", chosen.Name)
+	require.Equal(t, int64(75), score)
+	require.Equal(t, "least-allocated=75 (cpu 75% free)", reason)
+}
+
+func withLabels(labels map[string]string, syncTarget *workloadv1alpha1.SyncTarget) *workloadv1alpha1.SyncTarget {
+	syncTarget.Labels = labels
+	return syncTarget
+}
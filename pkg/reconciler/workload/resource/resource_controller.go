@@ -88,9 +88,7 @@ func NewController(
 
 			expectedSyncTargetKeys := sets.String{}
 			for _, placement := range placements {
-				if val := placement.Annotations[workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey]; val != "" {
-					expectedSyncTargetKeys.Insert(val)
-				}
+				expectedSyncTargetKeys.Insert(workloadv1alpha1.ParseSyncTargetKeys(placement.Annotations[workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey])...)
 			}
 			return expectedSyncTargetKeys, err
 		},
@@ -519,12 +517,9 @@ func (c *Controller) enqueuePlacement(obj interface{}) {
 		runtime.HandleError(fmt.Errorf("expected a Placement, got a %T", obj))
 		return
 	}
-	syncTargetKey := placement.Annotations[workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey]
-	if syncTargetKey == "" {
-		return
+	for _, syncTargetKey := range workloadv1alpha1.ParseSyncTargetKeys(placement.Annotations[workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey]) {
+		c.enqueueSyncTargetKey(syncTargetKey)
 	}
-
-	c.enqueueSyncTargetKey(syncTargetKey)
 }
 
 func indexBySyncTargetKey(obj interface{}) ([]string, error) {
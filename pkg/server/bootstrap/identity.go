@@ -50,6 +50,7 @@ var (
 		"scheduling.kcp.dev":  "scheduling.kcp.dev",
 		"workload.kcp.dev":    "workload.kcp.dev",
 		"apiresource.kcp.dev": "apiresource.kcp.dev",
+		"topology.kcp.dev":    "topology.kcp.dev",
 	}
 
 	// KcpRootGroupResourceExportNames lists the APIExports in the root workspace for standard kcp group resources
@@ -101,6 +101,10 @@ type ExtraConfig struct {
 	BootstrapKcpClusterClient           kcpclientset.ClusterInterface
 	CacheDynamicClient                  kcpdynamic.ClusterInterface
 
+	// PeerShardConfig holds system:masters credentials valid on all shards, e.g. to
+	// migrate workspaces. The host must be set per shard.
+	PeerShardConfig *rest.Config
+
 	// misc
 	preHandlerChainMux   *handlerChainMuxes
 	quotaAdmissionStopCh chan struct{}
@@ -281,6 +285,17 @@ func NewConfig(opts *kcpserveroptions.CompletedOptions) (*Config, error) {
 		return nil, err
 	}
 
+	// Setup the config to talk to peer shards, e.g. to migrate workspaces
+	if len(c.Options.Extra.ShardKubeconfigFile) > 0 {
+		c.PeerShardConfig, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(&clientcmd.ClientConfigLoadingRules{ExplicitPath: c.Options.Extra.ShardKubeconfigFile}, nil).ClientConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the kubeconfig from: %s, for peer shards, err: %w", c.Options.Extra.ShardKubeconfigFile, err)
+		}
+	} else {
+		// single shard setup, the loopback credentials are good enough
+		c.PeerShardConfig = rest.CopyConfig(c.GenericConfig.LoopbackClientConfig)
+	}
+
 	if err := opts.Authorization.ApplyTo(c.GenericConfig, c.KubeSharedInformerFactory, c.KcpSharedInformerFactory); err != nil {
 		return nil, err
 	}
@@ -387,6 +402,8 @@ func NewConfig(opts *kcpserveroptions.CompletedOptions) (*Config, error) {
 
 	admissionPluginInitializers := []admission.PluginInitializer{
 		kcpadmissioninitializers.NewKcpInformersInitializer(c.KcpSharedInformerFactory),
+		kcpadmissioninitializers.NewKcpCacheInformersInitializer(c.CacheKcpSharedInformerFactory),
+		kcpadmissioninitializers.NewApiExtensionsInformersInitializer(c.ApiExtensionsSharedInformerFactory),
 		kcpadmissioninitializers.NewKubeClusterClientInitializer(c.KubeClusterClient),
 		kcpadmissioninitializers.NewKcpClusterClientInitializer(c.KcpClusterClient),
 		kcpadmissioninitializers.NewDeepSARClientInitializer(c.DeepSARClient),
@@ -394,6 +411,7 @@ func NewConfig(opts *kcpserveroptions.CompletedOptions) (*Config, error) {
 		// with the default secure port, when the config is later completed.
 		kcpadmissioninitializers.NewKubeQuotaConfigurationInitializer(quotaConfiguration),
 		kcpadmissioninitializers.NewServerShutdownInitializer(c.quotaAdmissionStopCh),
+		kcpadmissioninitializers.NewDeletionRetentionPeriodInitializer(opts.Virtual.VirtualWorkspaces.Workspaces.DeletionRetentionPeriod),
 	}
 
 	c.ShardBaseURL = func() string {
@@ -428,8 +446,8 @@ func NewConfig(opts *kcpserveroptions.CompletedOptions) (*Config, error) {
 		opts.GenericControlPlane,
 
 		// Wire in a ServiceResolver that always returns an error that ResolveEndpoint is not yet
-		// supported. The effect is that CRD webhook conversions are not supported and will always get an
-		// error.
+		// supported. The effect is that CRD webhook conversions referencing a service are not supported
+		// and will always get an error. Webhook conversions by URL, e.g. those of APIResourceSchemas, work.
 		&unimplementedServiceResolver{},
 
 		webhook.NewDefaultAuthenticationInfoResolverWrapper(
@@ -24,6 +24,7 @@ import (
 	"os"
 	"time"
 
+	kcpdiscovery "github.com/kcp-dev/client-go/discovery"
 	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
 	kcpkubernetesclientset "github.com/kcp-dev/client-go/kubernetes"
 	kcpmetadata "github.com/kcp-dev/client-go/metadata"
@@ -51,15 +52,20 @@ import (
 	bootstrappolicy "github.com/kcp-dev/kcp/pkg/authorization/bootstrap"
 	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/cluster"
 	kcpinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions"
+	tenancyv1alpha1informers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/tenancy/v1alpha1"
 	"github.com/kcp-dev/kcp/pkg/informer"
+	"github.com/kcp-dev/kcp/pkg/migration"
 	"github.com/kcp-dev/kcp/pkg/reconciler/apis/apibinding"
 	"github.com/kcp-dev/kcp/pkg/reconciler/apis/apibindingdeletion"
 	"github.com/kcp-dev/kcp/pkg/reconciler/apis/apiexport"
+	"github.com/kcp-dev/kcp/pkg/reconciler/apis/apiexportconsumers"
+	"github.com/kcp-dev/kcp/pkg/reconciler/apis/apiexportendpointslice"
 	"github.com/kcp-dev/kcp/pkg/reconciler/apis/apiresource"
 	"github.com/kcp-dev/kcp/pkg/reconciler/apis/crdcleanup"
 	"github.com/kcp-dev/kcp/pkg/reconciler/apis/extraannotationsync"
 	"github.com/kcp-dev/kcp/pkg/reconciler/apis/identitycache"
 	"github.com/kcp-dev/kcp/pkg/reconciler/apis/permissionclaimlabel"
+	"github.com/kcp-dev/kcp/pkg/reconciler/apis/storageversionmigration"
 	"github.com/kcp-dev/kcp/pkg/reconciler/cache/replication"
 	"github.com/kcp-dev/kcp/pkg/reconciler/garbagecollector"
 	"github.com/kcp-dev/kcp/pkg/reconciler/kubequota"
@@ -69,10 +75,13 @@ import (
 	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/clusterworkspace"
 	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/clusterworkspacedeletion"
 	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/clusterworkspaceshard"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/clusterworkspaceshardusage"
 	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/clusterworkspacetype"
 	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/initialization"
+	"github.com/kcp-dev/kcp/pkg/reconciler/topology/partitionset"
 	workloadsapiexport "github.com/kcp-dev/kcp/pkg/reconciler/workload/apiexport"
 	workloadsapiexportcreate "github.com/kcp-dev/kcp/pkg/reconciler/workload/apiexportcreate"
+	"github.com/kcp-dev/kcp/pkg/reconciler/workload/failover"
 	"github.com/kcp-dev/kcp/pkg/reconciler/workload/heartbeat"
 	workloadnamespace "github.com/kcp-dev/kcp/pkg/reconciler/workload/namespace"
 	workloadplacement "github.com/kcp-dev/kcp/pkg/reconciler/workload/placement"
@@ -370,16 +379,44 @@ func (s *Server) installWorkspaceScheduler(ctx context.Context, config *rest.Con
 	// NOTE: keep `config` unaltered so there isn't cross-use between controllers installed here.
 	clusterWorkspaceConfig := rest.CopyConfig(config)
 	clusterWorkspaceConfig = rest.AddUserAgent(clusterWorkspaceConfig, clusterworkspace.ControllerName)
+	kubeClusterClient, err := kcpkubernetesclientset.NewForConfig(clusterWorkspaceConfig)
+	if err != nil {
+		return err
+	}
 	kcpClusterClient, err := kcpclientset.NewForConfig(clusterWorkspaceConfig)
 	if err != nil {
 		return err
 	}
 
+	migrationClient, err := migration.NewClient(rest.AddUserAgent(rest.CopyConfig(s.PeerShardConfig), clusterworkspace.ControllerName))
+	if err != nil {
+		return err
+	}
+
+	schedulingStrategy, err := clusterworkspace.NewSchedulingStrategy(clusterworkspace.SchedulingStrategyType(s.Options.Controllers.ClusterWorkspace.SchedulingStrategy))
+	if err != nil {
+		return err
+	}
+
+	// APIExports of a workspace can be bound from any shard
+	boundAPIBindingsInformer := s.KcpSharedInformerFactory.Apis().V1alpha1().APIBindings()
+	var cachedWorkspaceInformer tenancyv1alpha1informers.ClusterWorkspaceClusterInformer
+	if s.Options.Cache.Enabled {
+		boundAPIBindingsInformer = s.CacheKcpSharedInformerFactory.Apis().V1alpha1().APIBindings()
+		cachedWorkspaceInformer = s.CacheKcpSharedInformerFactory.Tenancy().V1alpha1().ClusterWorkspaces()
+	}
+
 	workspaceController, err := clusterworkspace.NewController(
+		kubeClusterClient,
 		kcpClusterClient,
 		s.KcpSharedInformerFactory.Tenancy().V1alpha1().ClusterWorkspaces(),
 		s.KcpSharedInformerFactory.Tenancy().V1alpha1().ClusterWorkspaceShards(),
 		s.KcpSharedInformerFactory.Apis().V1alpha1().APIBindings(),
+		boundAPIBindingsInformer,
+		cachedWorkspaceInformer,
+		migrationClient,
+		schedulingStrategy,
+		s.Options.Controllers.ClusterWorkspace.QuotaMeasureInterval,
 	)
 	if err != nil {
 		return err
@@ -495,6 +532,33 @@ func (s *Server) installWorkspaceScheduler(ctx context.Context, config *rest.Con
 	})
 }
 
+func (s *Server) installClusterWorkspaceShardUsageController(ctx context.Context) error {
+	capacity, err := s.Options.Controllers.ClusterWorkspaceShardUsage.ParseCapacity()
+	if err != nil {
+		return err
+	}
+
+	c := clusterworkspaceshardusage.NewController(
+		s.Options.Extra.ShardName,
+		capacity,
+		s.RootShardKcpClusterClient,
+		s.etcdClient,
+		s.storageTally,
+	)
+
+	return s.AddPostStartHook(postStartHookName(clusterworkspaceshardusage.ControllerName), func(hookContext genericapiserver.PostStartHookContext) error {
+		logger := klog.FromContext(ctx).WithValues("postStartHook", postStartHookName(clusterworkspaceshardusage.ControllerName))
+		if err := s.waitForSync(hookContext.StopCh); err != nil {
+			logger.Error(err, "failed to finish post-start-hook")
+			return nil // don't klog.Fatal. This only happens when context is cancelled.
+		}
+
+		go c.Start(ctx, s.Options.Controllers.ClusterWorkspaceShardUsage.ReportInterval)
+
+		return nil
+	})
+}
+
 func (s *Server) installApiResourceController(ctx context.Context, config *rest.Config) error {
 	config = rest.CopyConfig(config)
 	config = rest.AddUserAgent(config, apiresource.ControllerName)
@@ -564,6 +628,43 @@ func (s *Server) installSyncTargetHeartbeatController(ctx context.Context, confi
 	})
 }
 
+func (s *Server) installSyncTargetFailoverController(ctx context.Context, config *rest.Config) error {
+	config = rest.CopyConfig(config)
+	config = rest.AddUserAgent(config, failover.ControllerName)
+	kcpClusterClient, err := kcpclientset.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	kubeClusterClient, err := kcpkubernetesclientset.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	c, err := failover.NewController(
+		kcpClusterClient,
+		kubeClusterClient,
+		s.KcpSharedInformerFactory.Workload().V1alpha1().SyncTargets(),
+		s.KcpSharedInformerFactory.Scheduling().V1alpha1().Locations(),
+		s.KubeSharedInformerFactory.Core().V1().Namespaces(),
+		s.Options.Controllers.SyncTargetFailover.GracePeriod,
+	)
+	if err != nil {
+		return err
+	}
+
+	return s.AddPostStartHook(postStartHookName(failover.ControllerName), func(hookContext genericapiserver.PostStartHookContext) error {
+		logger := klog.FromContext(ctx).WithValues("postStartHook", postStartHookName(failover.ControllerName))
+		if err := s.waitForSync(hookContext.StopCh); err != nil {
+			logger.Error(err, "failed to finish post-start-hook")
+			return nil // don't klog.Fatal. This only happens when context is cancelled.
+		}
+
+		go c.Start(goContext(hookContext), 2)
+
+		return nil
+	})
+}
+
 func (s *Server) installAPIBindingController(ctx context.Context, config *rest.Config, server *genericapiserver.GenericAPIServer, ddsif *informer.DynamicDiscoverySharedInformerFactory) error {
 	// NOTE: keep `config` unaltered so there isn't cross-use between controllers installed here.
 	apiBindingConfig := rest.CopyConfig(config)
@@ -594,6 +695,7 @@ func (s *Server) installAPIBindingController(ctx context.Context, config *rest.C
 		s.TemporaryRootShardKcpSharedInformerFactory.Apis().V1alpha1().APIExports(),
 		s.TemporaryRootShardKcpSharedInformerFactory.Apis().V1alpha1().APIResourceSchemas(),
 		s.ApiExtensionsSharedInformerFactory.Apiextensions().V1().CustomResourceDefinitions(),
+		s.KcpSharedInformerFactory.Tenancy().V1alpha1().ClusterWorkspaces(),
 	)
 	if err != nil {
 		return err
@@ -771,6 +873,82 @@ func (s *Server) installAPIBinderController(ctx context.Context, config *rest.Co
 	})
 }
 
+func (s *Server) installClonerController(ctx context.Context, config *rest.Config, server *genericapiserver.GenericAPIServer) error {
+	config = rest.CopyConfig(config)
+	config = rest.AddUserAgent(config, initialization.ClonerControllerName)
+
+	// Clients used to read clone sources and templates from the shard directly
+	kubeClusterClient, err := kcpkubernetesclientset.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	sourceDiscoveryClient, err := kcpdiscovery.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	sourceDynamicClient, err := kcpdynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	// Clients used to create objects within the initializing workspace
+	initializingConfig := rest.CopyConfig(config)
+	// TODO(ncdc): support standalone vw server when --shard-virtual-workspace-url is set
+	initializingConfig.Host += initializingworkspacesbuilder.URLFor(tenancyv1alpha1.ClusterWorkspaceCloneInitializer)
+	initializingWorkspacesKcpClusterClient, err := kcpclientset.NewForConfig(initializingConfig)
+	if err != nil {
+		return err
+	}
+	targetDiscoveryClient, err := kcpdiscovery.NewForConfig(initializingConfig)
+	if err != nil {
+		return err
+	}
+	targetDynamicClient, err := kcpdynamic.NewForConfig(initializingConfig)
+	if err != nil {
+		return err
+	}
+	informerClient, err := kcpclientset.NewForConfig(initializingConfig)
+	if err != nil {
+		return err
+	}
+
+	// This informer factory is created here because it is specifically against the initializing workspaces virtual
+	// workspace.
+	initializingWorkspacesKcpInformers := kcpinformers.NewSharedInformerFactoryWithOptions(
+		informerClient,
+		resyncPeriod,
+	)
+
+	c, err := initialization.NewCloner(
+		initializingWorkspacesKcpClusterClient,
+		kubeClusterClient,
+		sourceDiscoveryClient,
+		sourceDynamicClient,
+		targetDiscoveryClient,
+		targetDynamicClient,
+		initializingWorkspacesKcpInformers.Tenancy().V1alpha1().ClusterWorkspaces(),
+		s.KcpSharedInformerFactory.Tenancy().V1alpha1().ClusterWorkspaceTypes(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return server.AddPostStartHook(postStartHookName(initialization.ClonerControllerName), func(hookContext genericapiserver.PostStartHookContext) error {
+		logger := klog.FromContext(ctx).WithValues("postStartHook", postStartHookName(initialization.ClonerControllerName))
+
+		if err := s.waitForSync(hookContext.StopCh); err != nil {
+			logger.Error(err, "failed to finish post-start-hook")
+			return nil // don't klog.Fatal. This only happens when context is cancelled.
+		}
+
+		initializingWorkspacesKcpInformers.Start(hookContext.StopCh)
+		initializingWorkspacesKcpInformers.WaitForCacheSync(hookContext.StopCh)
+
+		go c.Start(goContext(hookContext), 2)
+		return nil
+	})
+}
+
 func (s *Server) installCRDCleanupController(ctx context.Context, config *rest.Config, server *genericapiserver.GenericAPIServer) error {
 	config = rest.CopyConfig(config)
 	config = rest.AddUserAgent(config, crdcleanup.ControllerName)
@@ -802,6 +980,78 @@ func (s *Server) installCRDCleanupController(ctx context.Context, config *rest.C
 	})
 }
 
+func (s *Server) installStorageVersionMigrationController(ctx context.Context, config *rest.Config, server *genericapiserver.GenericAPIServer) error {
+	// NOTE: keep `config` unaltered so there isn't cross-use between controllers installed here.
+	migrationConfig := rest.CopyConfig(config)
+	migrationConfig = rest.AddUserAgent(migrationConfig, storageversionmigration.ControllerName)
+
+	kcpClusterClient, err := kcpclientset.NewForConfig(migrationConfig)
+	if err != nil {
+		return err
+	}
+	dynamicClusterClient, err := kcpdynamic.NewForConfig(migrationConfig)
+	if err != nil {
+		return err
+	}
+
+	c, err := storageversionmigration.NewController(
+		kcpClusterClient,
+		dynamicClusterClient,
+		s.KcpSharedInformerFactory.Apis().V1alpha1().APIBindings(),
+		s.ApiExtensionsSharedInformerFactory.Apiextensions().V1().CustomResourceDefinitions(),
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := server.AddPostStartHook(postStartHookName(storageversionmigration.ControllerName), func(hookContext genericapiserver.PostStartHookContext) error {
+		logger := klog.FromContext(ctx).WithValues("postStartHook", postStartHookName(storageversionmigration.ControllerName))
+		if err := s.waitForSync(hookContext.StopCh); err != nil {
+			logger.Error(err, "failed to finish post-start-hook")
+			return nil // don't klog.Fatal. This only happens when context is cancelled.
+		}
+
+		go c.Start(goContext(hookContext), 2)
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	crdMigrationConfig := rest.CopyConfig(config)
+	crdMigrationConfig = rest.AddUserAgent(crdMigrationConfig, storageversionmigration.CRDControllerName)
+
+	crdClusterClient, err := kcpapiextensionsclientset.NewForConfig(crdMigrationConfig)
+	if err != nil {
+		return err
+	}
+	dynamicClusterClient, err = kcpdynamic.NewForConfig(crdMigrationConfig)
+	if err != nil {
+		return err
+	}
+
+	crdController, err := storageversionmigration.NewCRDController(
+		crdClusterClient,
+		dynamicClusterClient,
+		s.ApiExtensionsSharedInformerFactory.Apiextensions().V1().CustomResourceDefinitions(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return server.AddPostStartHook(postStartHookName(storageversionmigration.CRDControllerName), func(hookContext genericapiserver.PostStartHookContext) error {
+		logger := klog.FromContext(ctx).WithValues("postStartHook", postStartHookName(storageversionmigration.CRDControllerName))
+		if err := s.waitForSync(hookContext.StopCh); err != nil {
+			logger.Error(err, "failed to finish post-start-hook")
+			return nil // don't klog.Fatal. This only happens when context is cancelled.
+		}
+
+		go crdController.Start(goContext(hookContext), 2)
+
+		return nil
+	})
+}
+
 func (s *Server) installAPIExportController(ctx context.Context, config *rest.Config, server *genericapiserver.GenericAPIServer) error {
 	config = rest.CopyConfig(config)
 	config = rest.AddUserAgent(config, apiexport.ControllerName)
@@ -850,6 +1100,127 @@ func (s *Server) installAPIExportController(ctx context.Context, config *rest.Co
 	})
 }
 
+func (s *Server) installAPIExportEndpointSliceController(ctx context.Context, config *rest.Config, server *genericapiserver.GenericAPIServer) error {
+	if !s.Options.Cache.Enabled {
+		return nil
+	}
+
+	config = rest.CopyConfig(config)
+	config = rest.AddUserAgent(config, apiexportendpointslice.ControllerName)
+
+	kcpClusterClient, err := kcpclientset.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	c, err := apiexportendpointslice.NewController(
+		kcpClusterClient,
+		s.KcpSharedInformerFactory.Apis().V1alpha1().APIExportEndpointSlices(),
+		s.KcpSharedInformerFactory.Apis().V1alpha1().APIExports(),
+		s.KcpSharedInformerFactory.Topology().V1alpha1().Partitions(),
+		s.CacheKcpSharedInformerFactory.Apis().V1alpha1().APIExports(),
+		s.CacheKcpSharedInformerFactory.Tenancy().V1alpha1().ClusterWorkspaceShards(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return server.AddPostStartHook(postStartHookName(apiexportendpointslice.ControllerName), func(hookContext genericapiserver.PostStartHookContext) error {
+		logger := klog.FromContext(ctx).WithValues("postStartHook", postStartHookName(apiexportendpointslice.ControllerName))
+		if err := s.waitForSync(hookContext.StopCh); err != nil {
+			logger.Error(err, "failed to finish post-start-hook")
+			return nil // don't klog.Fatal. This only happens when context is cancelled.
+		}
+		if err := s.waitForOptionalSync(hookContext.StopCh); err != nil {
+			logger.Error(err, "failed to finish post-start-hook")
+			return nil // don't klog.Fatal. This only happens when context is cancelled.
+		}
+
+		go c.Start(goContext(hookContext), 2)
+
+		return nil
+	})
+}
+
+func (s *Server) installAPIExportConsumersController(ctx context.Context, config *rest.Config, server *genericapiserver.GenericAPIServer) error {
+	if !s.Options.Cache.Enabled {
+		return nil
+	}
+
+	config = rest.CopyConfig(config)
+	config = rest.AddUserAgent(config, apiexportconsumers.ControllerName)
+
+	kcpClusterClient, err := kcpclientset.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	c, err := apiexportconsumers.NewController(
+		kcpClusterClient,
+		s.KcpSharedInformerFactory.Apis().V1alpha1().APIExports(),
+		s.CacheKcpSharedInformerFactory.Apis().V1alpha1().APIBindings(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return server.AddPostStartHook(postStartHookName(apiexportconsumers.ControllerName), func(hookContext genericapiserver.PostStartHookContext) error {
+		logger := klog.FromContext(ctx).WithValues("postStartHook", postStartHookName(apiexportconsumers.ControllerName))
+		if err := s.waitForSync(hookContext.StopCh); err != nil {
+			logger.Error(err, "failed to finish post-start-hook")
+			return nil // don't klog.Fatal. This only happens when context is cancelled.
+		}
+		if err := s.waitForOptionalSync(hookContext.StopCh); err != nil {
+			logger.Error(err, "failed to finish post-start-hook")
+			return nil // don't klog.Fatal. This only happens when context is cancelled.
+		}
+
+		go c.Start(goContext(hookContext), 2)
+
+		return nil
+	})
+}
+
+func (s *Server) installPartitionSetController(ctx context.Context, config *rest.Config, server *genericapiserver.GenericAPIServer) error {
+	if !s.Options.Cache.Enabled {
+		return nil
+	}
+
+	config = rest.CopyConfig(config)
+	config = rest.AddUserAgent(config, partitionset.ControllerName)
+
+	kcpClusterClient, err := kcpclientset.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	c, err := partitionset.NewController(
+		kcpClusterClient,
+		s.KcpSharedInformerFactory.Topology().V1alpha1().PartitionSets(),
+		s.KcpSharedInformerFactory.Topology().V1alpha1().Partitions(),
+		s.CacheKcpSharedInformerFactory.Tenancy().V1alpha1().ClusterWorkspaceShards(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return server.AddPostStartHook(postStartHookName(partitionset.ControllerName), func(hookContext genericapiserver.PostStartHookContext) error {
+		logger := klog.FromContext(ctx).WithValues("postStartHook", postStartHookName(partitionset.ControllerName))
+		if err := s.waitForSync(hookContext.StopCh); err != nil {
+			logger.Error(err, "failed to finish post-start-hook")
+			return nil // don't klog.Fatal. This only happens when context is cancelled.
+		}
+		if err := s.waitForOptionalSync(hookContext.StopCh); err != nil {
+			logger.Error(err, "failed to finish post-start-hook")
+			return nil // don't klog.Fatal. This only happens when context is cancelled.
+		}
+
+		go c.Start(goContext(hookContext), 2)
+
+		return nil
+	})
+}
+
 func (s *Server) installSchedulingLocationStatusController(ctx context.Context, config *rest.Config, server *genericapiserver.GenericAPIServer) error {
 	controllerName := "kcp-scheduling-location-status-controller"
 	config = rest.CopyConfig(config)
@@ -924,12 +1295,18 @@ func (s *Server) installWorkloadPlacementScheduler(ctx context.Context, config *
 		return err
 	}
 
+	scorer, err := s.Options.Controllers.WorkloadPlacement.NewScorer()
+	if err != nil {
+		return err
+	}
+
 	c, err := workloadplacement.NewController(
 		kcpClusterClient,
 		s.KcpSharedInformerFactory.Scheduling().V1alpha1().Locations(),
 		s.KcpSharedInformerFactory.Workload().V1alpha1().SyncTargets(),
 		s.KcpSharedInformerFactory.Scheduling().V1alpha1().Placements(),
 		s.KcpSharedInformerFactory.Apis().V1alpha1().APIBindings(),
+		scorer,
 	)
 	if err != nil {
 		return err
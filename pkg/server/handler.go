@@ -474,8 +474,8 @@ func (r *inMemoryResponseWriter) String() string {
 }
 
 // unimplementedServiceResolver is a webhook.ServiceResolver that always returns an error, because
-// we have not implemented support for this yet. As a result, CRD webhook conversions are not
-// supported.
+// we have not implemented support for this yet. As a result, CRD webhook conversions referencing
+// a service are not supported.
 type unimplementedServiceResolver struct{}
 
 // ResolveEndpoint always returns an error that this is not yet supported.
@@ -66,6 +66,8 @@ const (
 	homeOwnerClusterRolePrefix     = "system:kcp:tenancy:home-owner:"
 	HomeBucketClusterWorkspaceType = "homebucket"
 	HomeClusterWorkspaceType       = "home"
+
+	movedHomeClusterWorkspacesByOwner = "movedHomeClusterWorkspacesByOwner"
 )
 
 var (
@@ -162,11 +164,14 @@ func buildExternalClientsAccess(kubeClusterClient kcpkubernetesclientset.Cluster
 }
 
 type localInformersAccess struct {
-	getClusterWorkspace   func(logicalcluster.Name) (*tenancyv1alpha1.ClusterWorkspace, error)
-	getClusterRole        func(lcluster logicalcluster.Name, name string) (*rbacv1.ClusterRole, error)
-	getClusterRoleBinding func(lcluster logicalcluster.Name, name string) (*rbacv1.ClusterRoleBinding, error)
-	getTenancyAPIBinding  func(clusterName logicalcluster.Name) (*apisv1alpha1.APIBinding, bool, error)
-	synced                func() bool
+	getClusterWorkspace func(logicalcluster.Name) (*tenancyv1alpha1.ClusterWorkspace, error)
+	// getMovedHomeClusterWorkspace returns the home workspace of the given user if it has been
+	// moved away from its consistent location, or nil.
+	getMovedHomeClusterWorkspace func(userName string) (*tenancyv1alpha1.ClusterWorkspace, error)
+	getClusterRole               func(lcluster logicalcluster.Name, name string) (*rbacv1.ClusterRole, error)
+	getClusterRoleBinding        func(lcluster logicalcluster.Name, name string) (*rbacv1.ClusterRoleBinding, error)
+	getTenancyAPIBinding         func(clusterName logicalcluster.Name) (*apisv1alpha1.APIBinding, bool, error)
+	synced                       func() bool
 }
 
 func buildLocalInformersAccess(kubeSharedInformerFactory kcpkubernetesinformers.SharedInformerFactory, kcpSharedInformerFactory kcpinformers.SharedInformerFactory) localInformersAccess {
@@ -181,12 +186,28 @@ func buildLocalInformersAccess(kubeSharedInformerFactory kcpkubernetesinformers.
 	indexers.AddIfNotPresentOrDie(apiBindingInformer.Informer().GetIndexer(), cache.Indexers{
 		indexers.APIBindingByBoundResources: indexers.IndexAPIBindingByBoundResources,
 	})
+	indexers.AddIfNotPresentOrDie(clusterWorkspaceInformer.GetIndexer(), cache.Indexers{
+		movedHomeClusterWorkspacesByOwner: indexMovedHomeClusterWorkspacesByOwner,
+	})
 
 	return localInformersAccess{
 		getClusterWorkspace: func(logicalCluster logicalcluster.Name) (*tenancyv1alpha1.ClusterWorkspace, error) {
 			parentLogicalCluster, workspaceName := logicalCluster.Split()
 			return clusterWorkspaceLister.Cluster(parentLogicalCluster).Get(workspaceName)
 		},
+		getMovedHomeClusterWorkspace: func(userName string) (*tenancyv1alpha1.ClusterWorkspace, error) {
+			workspaces, err := indexers.ByIndex[*tenancyv1alpha1.ClusterWorkspace](clusterWorkspaceInformer.GetIndexer(), movedHomeClusterWorkspacesByOwner, userName)
+			if err != nil {
+				return nil, err
+			}
+			if len(workspaces) == 0 {
+				return nil, nil
+			}
+			if len(workspaces) != 1 {
+				return nil, fmt.Errorf("expected to find at most 1 moved home workspace for user %q, got %d", userName, len(workspaces))
+			}
+			return workspaces[0], nil
+		},
 		getClusterRole: func(workspace logicalcluster.Name, name string) (*rbacv1.ClusterRole, error) {
 			return crLister.Cluster(workspace).Get(name)
 		},
@@ -341,6 +362,25 @@ func (h *homeWorkspaceHandler) ServeHTTP(rw http.ResponseWriter, req *http.Reque
 			responsewriters.InternalError(rw, req, err)
 			return
 		}
+		if homeClusterWorkspace == nil {
+			// the home workspace might have been moved by its owner. Then it lives on under its new name.
+			movedHome, err := h.movedHomeClusterWorkspace(effectiveUser.GetName())
+			if err != nil {
+				responsewriters.InternalError(rw, req, err)
+				return
+			}
+			if movedHome != nil {
+				if movedHome.Status.Phase != tenancyv1alpha1.ClusterWorkspacePhaseReady {
+					rw.Header().Set("Retry-After", fmt.Sprintf("%d", h.creationDelaySeconds))
+					http.Error(rw, "Moving the home workspace", http.StatusTooManyRequests)
+					return
+				}
+				homeWorkspace := &tenancyv1beta1.Workspace{}
+				projection.ProjectClusterWorkspaceToWorkspace(movedHome, homeWorkspace)
+				responsewriters.WriteObjectNegotiated(homeWorkspaceCodecs, negotiation.DefaultEndpointRestrictions, tenancyv1beta1.SchemeGroupVersion, rw, req, http.StatusOK, homeWorkspace)
+				return
+			}
+		}
 		if homeClusterWorkspace != nil {
 			// check for collision. Chance is super low hitting it by accident. But to protect against malicious users,
 			// we check for collision and return 403.
@@ -397,6 +437,16 @@ func (h *homeWorkspaceHandler) ServeHTTP(rw http.ResponseWriter, req *http.Reque
 			responsewriters.InternalError(rw, req, err)
 			return
 		}
+		if !foundLocally && workspaceType == HomeClusterWorkspaceType && lcluster.Name == h.getHomeLogicalClusterName(effectiveUser.GetName()) {
+			// don't recreate a home workspace that has been moved away by its owner.
+			if movedHome, err := h.movedHomeClusterWorkspace(effectiveUser.GetName()); err != nil {
+				responsewriters.InternalError(rw, req, err)
+				return
+			} else if movedHome != nil {
+				h.apiHandler.ServeHTTP(rw, req)
+				return
+			}
+		}
 		if foundLocally {
 			logger.V(4).Info("found home workspace", "retryAfter", retryAfterSeconds)
 			if retryAfterSeconds > 0 {
@@ -794,6 +844,32 @@ func workspaceUnschedulable(workspace *tenancyv1alpha1.ClusterWorkspace) bool {
 	return false
 }
 
+func (h *homeWorkspaceHandler) movedHomeClusterWorkspace(userName string) (*tenancyv1alpha1.ClusterWorkspace, error) {
+	if h.localInformers.getMovedHomeClusterWorkspace == nil {
+		return nil, nil
+	}
+	return h.localInformers.getMovedHomeClusterWorkspace(userName)
+}
+
+// indexMovedHomeClusterWorkspacesByOwner indexes home workspaces that have been moved away
+// from their consistent location by the name of their owner.
+func indexMovedHomeClusterWorkspacesByOwner(obj interface{}) ([]string, error) {
+	cw, ok := obj.(*tenancyv1alpha1.ClusterWorkspace)
+	if !ok {
+		return []string{}, fmt.Errorf("obj is supposed to be a ClusterWorkspace, but is %T", obj)
+	}
+	if cw.Spec.Type.Name != HomeClusterWorkspaceType || cw.Spec.Type.Path != tenancyv1alpha1.RootCluster.String() {
+		return []string{}, nil
+	}
+	if _, moved := cw.Annotations[tenancyv1alpha1.ClusterWorkspaceMovedFromAnnotationKey]; !moved {
+		return []string{}, nil
+	}
+	if info, _ := unmarshalOwner(cw); info != nil {
+		return []string{info.Username}, nil
+	}
+	return []string{}, nil
+}
+
 func unmarshalOwner(cw *tenancyv1alpha1.ClusterWorkspace) (*authenticationv1.UserInfo, error) {
 	raw, found := cw.Annotations[tenancyv1alpha1.ExperimentalClusterWorkspaceOwnerAnnotationKey]
 	if !found {
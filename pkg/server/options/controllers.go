@@ -30,7 +30,11 @@ import (
 	kcmoptions "k8s.io/kubernetes/cmd/kube-controller-manager/app/options"
 
 	"github.com/kcp-dev/kcp/pkg/reconciler/apis/apiresource"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/clusterworkspace"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/clusterworkspaceshardusage"
+	"github.com/kcp-dev/kcp/pkg/reconciler/workload/failover"
 	"github.com/kcp-dev/kcp/pkg/reconciler/workload/heartbeat"
+	workloadplacement "github.com/kcp-dev/kcp/pkg/reconciler/workload/placement"
 )
 
 type Controllers struct {
@@ -38,11 +42,20 @@ type Controllers struct {
 	IndividuallyEnabled []string
 	ApiResource         ApiResourceController
 	SyncTargetHeartbeat SyncTargetHeartbeatController
+	SyncTargetFailover  SyncTargetFailoverController
+	WorkloadPlacement   WorkloadPlacementController
 	SAController        kcmoptions.SAControllerOptions
+
+	ClusterWorkspace           ClusterWorkspaceController
+	ClusterWorkspaceShardUsage ClusterWorkspaceShardUsageController
 }
 
 type ApiResourceController = apiresource.Options
 type SyncTargetHeartbeatController = heartbeat.Options
+type SyncTargetFailoverController = failover.Options
+type WorkloadPlacementController = workloadplacement.Options
+type ClusterWorkspaceController = clusterworkspace.Options
+type ClusterWorkspaceShardUsageController = clusterworkspaceshardusage.Options
 
 var kcmDefaults *kcmoptions.KubeControllerManagerOptions
 
@@ -61,7 +74,12 @@ func NewControllers() *Controllers {
 
 		ApiResource:         *apiresource.DefaultOptions(),
 		SyncTargetHeartbeat: *heartbeat.DefaultOptions(),
+		SyncTargetFailover:  *failover.DefaultOptions(),
+		WorkloadPlacement:   *workloadplacement.DefaultOptions(),
 		SAController:        *kcmDefaults.SAController,
+
+		ClusterWorkspace:           *clusterworkspace.DefaultOptions(),
+		ClusterWorkspaceShardUsage: *clusterworkspaceshardusage.DefaultOptions(),
 	}
 }
 
@@ -73,6 +91,10 @@ func (c *Controllers) AddFlags(fs *pflag.FlagSet) {
 
 	apiresource.BindOptions(&c.ApiResource, fs)
 	heartbeat.BindOptions(&c.SyncTargetHeartbeat, fs)
+	failover.BindOptions(&c.SyncTargetFailover, fs)
+	workloadplacement.BindOptions(&c.WorkloadPlacement, fs)
+	clusterworkspace.BindOptions(&c.ClusterWorkspace, fs)
+	clusterworkspaceshardusage.BindOptions(&c.ClusterWorkspaceShardUsage, fs)
 
 	c.SAController.AddFlags(fs)
 }
@@ -112,6 +134,18 @@ func (c *Controllers) Validate() []error {
 	if err := c.SyncTargetHeartbeat.Validate(); err != nil {
 		errs = append(errs, err)
 	}
+	if err := c.SyncTargetFailover.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.WorkloadPlacement.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.ClusterWorkspace.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.ClusterWorkspaceShardUsage.Validate(); err != nil {
+		errs = append(errs, err)
+	}
 	if saErrs := c.SAController.Validate(); saErrs != nil {
 		errs = append(errs, saErrs...)
 	}
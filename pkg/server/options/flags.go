@@ -177,6 +177,12 @@ var (
 		"run-virtual-workspaces",                 // Run the virtual workspaces apiservers in-process
 		"unsupported-run-individual-controllers", // Run individual controllers in-process. The controller names can change at any time.
 		"sync-target-heartbeat-threshold",        // Amount of time to wait for a successful heartbeat before marking the cluster as not ready.
+		"sync-target-failover-grace-period",      // Amount of time a SyncTarget may miss its heartbeat before it is evicted and its namespaces are rescheduled to other SyncTargets of their Location.
+		"sync-target-scorers",                    // Scorers used to rank the SyncTargets of the selected location of a Placement. The scores are added up. Any of: least-allocated, most-allocated, label-affinity.
+		"workspace-scheduling-strategy",          // Strategy used to pick a ClusterWorkspaceShard for new workspaces among the shards matching their constraints. One of: least-loaded, bin-packing, random.
+		"shard-capacity",                         // Capacity of this shard reported in its ClusterWorkspaceShard status, e.g. workspaces=1000,objects=1000000,database-size=8Gi.
+		"shard-usage-report-interval",            // Interval in which the storage of this shard is measured, and its usage reported in its ClusterWorkspaceShard status. The measurement is shared with the quotas of the workspaces on this shard.
+		"workspace-quota-measure-interval",       // Interval in which the usage of workspaces with a quota is updated in their status.
 
 		// KCP Cache Server flags
 		"cache-server-kubeconfig-file", // Kubeconfig for the cache server this instance connects to (defaults to loop back configuration).
@@ -186,6 +192,7 @@ var (
 		"virtual-workspaces-workspaces.authorization-cache.jitter-factor", // Jitter factor for cache re-sync. Leave unset to use a default factor.
 		"virtual-workspaces-workspaces.authorization-cache.resync-period", // Period for cache re-sync.
 		"virtual-workspaces-workspaces.authorization-cache.sliding",       // Whether or not to take into account sync duration in period calculations.
+		"virtual-workspaces-workspaces.deletion-retention-period",         // Default period deleted workspaces are retained in the Deleted phase, during which they can be undeleted. Zero disables the retention.
 
 		// generic flags
 		"cors-allowed-origins",                 // List of allowed origins for CORS, comma separated.  An allowed origin can be a regular expression to support subdomain matching. If this list is empty CORS will not be enabled.
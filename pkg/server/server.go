@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/kcp-dev/logicalcluster/v2"
+	clientv3 "go.etcd.io/etcd/client/v3"
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -44,6 +45,7 @@ import (
 	kcpfeatures "github.com/kcp-dev/kcp/pkg/features"
 	"github.com/kcp-dev/kcp/pkg/indexers"
 	"github.com/kcp-dev/kcp/pkg/informer"
+	"github.com/kcp-dev/kcp/pkg/migration"
 )
 
 const resyncPeriod = 10 * time.Hour
@@ -53,6 +55,9 @@ type Server struct {
 
 	*genericcontrolplane.ServerChain
 
+	etcdClient   *clientv3.Client
+	storageTally *migration.Tally
+
 	syncedCh             chan struct{}
 	syncedOptionalCh     chan struct{}
 	rootPhase1FinishedCh chan struct{}
@@ -84,6 +89,14 @@ func NewServer(c CompletedConfig) (*Server, error) {
 		),
 	)
 
+	// serve the raw storage of logical clusters for workspace migrations between shards
+	s.etcdClient, err = migration.NewEtcdClient(c.Options.GenericControlPlane.Etcd.StorageConfig.Transport)
+	if err != nil {
+		return nil, err
+	}
+	s.storageTally = migration.NewTally(s.etcdClient, c.Options.GenericControlPlane.Etcd.StorageConfig.Prefix, c.Options.Controllers.ClusterWorkspaceShardUsage.ReportInterval)
+	s.MiniAggregator.GenericAPIServer.Handler.NonGoRestfulMux.Handle(migration.Path, migration.NewHandler(s.etcdClient, c.Options.GenericControlPlane.Etcd.StorageConfig.Prefix, s.storageTally))
+
 	s.DynamicDiscoverySharedInformerFactory, err = informer.NewDynamicDiscoverySharedInformerFactory(
 		s.MiniAggregator.GenericAPIServer.LoopbackClientConfig,
 		func(obj interface{}) bool { return true },
@@ -418,6 +431,9 @@ func (s *Server) Run(ctx context.Context) error {
 		if err := s.installSyncTargetHeartbeatController(ctx, controllerConfig); err != nil {
 			return err
 		}
+		if err := s.installSyncTargetFailoverController(ctx, controllerConfig); err != nil {
+			return err
+		}
 		if err := s.installSyncTargetController(ctx, controllerConfig, delegationChainHead); err != nil {
 			return err
 		}
@@ -430,6 +446,9 @@ func (s *Server) Run(ctx context.Context) error {
 		if err := s.installWorkspaceScheduler(ctx, controllerConfig); err != nil {
 			return err
 		}
+		if err := s.installClusterWorkspaceShardUsageController(ctx); err != nil {
+			return err
+		}
 		if err := s.installWorkspaceDeletionController(ctx, controllerConfig); err != nil {
 			return err
 		}
@@ -448,6 +467,9 @@ func (s *Server) Run(ctx context.Context) error {
 		if err := s.installCRDCleanupController(ctx, controllerConfig, delegationChainHead); err != nil {
 			return err
 		}
+		if err := s.installStorageVersionMigrationController(ctx, controllerConfig, delegationChainHead); err != nil {
+			return err
+		}
 		if err := s.installExtraAnnotationSyncController(ctx, controllerConfig, delegationChainHead); err != nil {
 			return err
 		}
@@ -457,6 +479,18 @@ func (s *Server) Run(ctx context.Context) error {
 		if err := s.installAPIExportController(ctx, controllerConfig, delegationChainHead); err != nil {
 			return err
 		}
+		if err := s.installAPIExportEndpointSliceController(ctx, controllerConfig, delegationChainHead); err != nil {
+			return err
+		}
+		if err := s.installAPIExportConsumersController(ctx, controllerConfig, delegationChainHead); err != nil {
+			return err
+		}
+	}
+
+	if s.Options.Controllers.EnableAll || enabled.Has("partitionset") {
+		if err := s.installPartitionSetController(ctx, controllerConfig, delegationChainHead); err != nil {
+			return err
+		}
 	}
 
 	if s.Options.Controllers.EnableAll || enabled.Has("apibinder") {
@@ -465,6 +499,12 @@ func (s *Server) Run(ctx context.Context) error {
 		}
 	}
 
+	if s.Options.Controllers.EnableAll || enabled.Has("cloner") {
+		if err := s.installClonerController(ctx, controllerConfig, delegationChainHead); err != nil {
+			return err
+		}
+	}
+
 	if kcpfeatures.DefaultFeatureGate.Enabled(kcpfeatures.LocationAPI) {
 		if s.Options.Controllers.EnableAll || enabled.Has("scheduling") {
 			if err := s.installWorkloadNamespaceScheduler(ctx, controllerConfig, delegationChainHead); err != nil {
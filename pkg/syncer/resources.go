@@ -0,0 +1,127 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"sync/atomic"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	kubernetesinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+)
+
+// resourceReporter watches the downstream nodes and pods whose resources are reported in the
+// SyncTarget status. Reporting is optional: it is started after the syncer is running, and is
+// skipped if the syncer is not allowed to list and watch nodes and pods in the whole cluster.
+type resourceReporter struct {
+	downstreamKubeClient kubernetes.Interface
+
+	nodeLister corev1listers.NodeLister
+	podLister  corev1listers.PodLister
+	synced     atomic.Bool
+}
+
+func newResourceReporter(downstreamKubeClient kubernetes.Interface) *resourceReporter {
+	return &resourceReporter{downstreamKubeClient: downstreamKubeClient}
+}
+
+// Start checks the access to nodes and pods, and starts the informers if allowed. It blocks
+// until the informers are synced or the context is done.
+func (r *resourceReporter) Start(ctx context.Context) {
+	logger := klog.FromContext(ctx)
+
+	for _, resource := range []string{"nodes", "pods"} {
+		for _, verb := range []string{"list", "watch"} {
+			allowed, err := r.checkSSAR(ctx, resource, verb)
+			if err != nil {
+				logger.Error(err, "failed to check access, not reporting downstream resources", "resource", resource, "verb", verb)
+				return
+			}
+			if !allowed {
+				logger.Info("not allowed to access downstream resources cluster-wide, not reporting them", "resource", resource, "verb", verb)
+				return
+			}
+		}
+	}
+
+	nodeInformerFactory := kubernetesinformers.NewSharedInformerFactory(r.downstreamKubeClient, resyncPeriod)
+	// Terminated pods do not request resources, leave them out of the cache.
+	podInformerFactory := kubernetesinformers.NewSharedInformerFactoryWithOptions(r.downstreamKubeClient, resyncPeriod, kubernetesinformers.WithTweakListOptions(func(o *metav1.ListOptions) {
+		o.FieldSelector = fields.AndSelectors(
+			fields.OneTermNotEqualSelector("status.phase", string(corev1.PodSucceeded)),
+			fields.OneTermNotEqualSelector("status.phase", string(corev1.PodFailed)),
+		).String()
+	}))
+	nodeInformer := nodeInformerFactory.Core().V1().Nodes()
+	podInformer := podInformerFactory.Core().V1().Pods()
+	r.nodeLister, r.podLister = nodeInformer.Lister(), podInformer.Lister()
+
+	nodeInformerFactory.Start(ctx.Done())
+	podInformerFactory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), nodeInformer.Informer().HasSynced, podInformer.Informer().HasSynced) {
+		return
+	}
+	r.synced.Store(true)
+	logger.V(2).Info("reporting downstream resources")
+}
+
+// Resources returns the resources of the downstream nodes and pods. It returns false if they
+// are not reported.
+func (r *resourceReporter) Resources() (capacity, allocatable, requested corev1.ResourceList, ok bool, err error) {
+	if !r.synced.Load() {
+		return nil, nil, nil, false, nil
+	}
+	nodes, err := r.nodeLister.List(labels.Everything())
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	pods, err := r.podLister.List(labels.Everything())
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	capacity, allocatable, requested = shared.NodeResources(nodes, pods)
+	return capacity, allocatable, requested, true, nil
+}
+
+func (r *resourceReporter) checkSSAR(ctx context.Context, resource, verb string) (bool, error) {
+	ssar := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Version:  "v1",
+				Resource: resource,
+				Verb:     verb,
+			},
+		},
+	}
+
+	sar, err := r.downstreamKubeClient.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, ssar, metav1.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+
+	return sar.Status.Allowed, nil
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestResourceReporter(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node"},
+		Status: corev1.NodeStatus{
+			Capacity:    corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")},
+			Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("3")},
+			Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+
+	tests := map[string]struct {
+		denied   string
+		wantCPU  string
+		reported bool
+	}{
+		"allowed": {
+			wantCPU:  "4",
+			reported: true,
+		},
+		"nodes forbidden": {
+			denied: "nodes",
+		},
+		"pods forbidden": {
+			denied: "pods",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			client := kubefake.NewSimpleClientset(node)
+			client.PrependReactor("create", "selfsubjectaccessreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				ssar := action.(clienttesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+				ssar.Status.Allowed = ssar.Spec.ResourceAttributes.Resource != tc.denied
+				return true, ssar, nil
+			})
+
+			r := newResourceReporter(client)
+			r.Start(ctx)
+
+			capacity, _, _, ok, err := r.Resources()
+			require.NoError(t, err)
+			require.Equal(t, tc.reported, ok)
+			if !tc.reported {
+				for _, action := range client.Actions() {
+					require.NotEqual(t, "list", action.GetVerb(), "unexpected list of %s", action.GetResource().Resource)
+				}
+				return
+			}
+			cpu := capacity[corev1.ResourceCPU]
+			require.Equal(t, tc.wantCPU, cpu.String())
+		})
+	}
+}
+
+func TestHeartbeatPatchWithoutResources(t *testing.T) {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	patch, err := heartbeatPatch("uid", now, newResourceReporter(kubefake.NewSimpleClientset()))
+	require.NoError(t, err)
+	require.JSONEq(t, `[{"op":"test","path":"/metadata/uid","value":"uid"},{"op":"replace","path":"/status/lastSyncerHeartbeatTime","value":"2022-10-01T12:00:00Z"}]`, string(patch))
+}
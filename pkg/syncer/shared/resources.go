@@ -0,0 +1,83 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shared
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NodeResources sums up the capacity and the allocatable resources of the nodes that are ready
+// and schedulable, and the resources requested by the pods running on them, as reported in the
+// SyncTarget status.
+func NodeResources(nodes []*corev1.Node, pods []*corev1.Pod) (capacity, allocatable, requested corev1.ResourceList) {
+	capacity, allocatable, requested = corev1.ResourceList{}, corev1.ResourceList{}, corev1.ResourceList{}
+	counted := map[string]bool{}
+	for _, node := range nodes {
+		if node.Spec.Unschedulable || !isNodeReady(node) {
+			continue
+		}
+		counted[node.Name] = true
+		addResources(capacity, node.Status.Capacity)
+		addResources(allocatable, node.Status.Allocatable)
+	}
+	for _, pod := range pods {
+		if !counted[pod.Spec.NodeName] || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		addResources(requested, podRequests(pod))
+	}
+	return capacity, allocatable, requested
+}
+
+// podRequests returns the resources requested by the pod, like the scheduler computes them: the
+// sum of the requests of its containers, or the highest request of an init container if larger,
+// plus the pod overhead.
+func podRequests(pod *corev1.Pod) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for _, container := range pod.Spec.Containers {
+		addResources(requests, container.Resources.Requests)
+	}
+	for _, container := range pod.Spec.InitContainers {
+		for name, quantity := range container.Resources.Requests {
+			if existing, found := requests[name]; !found || quantity.Cmp(existing) > 0 {
+				requests[name] = quantity.DeepCopy()
+			}
+		}
+	}
+	addResources(requests, pod.Spec.Overhead)
+	return requests
+}
+
+func isNodeReady(node *corev1.Node) bool {
+	for _, c := range node.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func addResources(sum, resources corev1.ResourceList) {
+	for name, quantity := range resources {
+		if existing, found := sum[name]; found {
+			existing.Add(quantity)
+			sum[name] = existing
+		} else {
+			sum[name] = quantity.DeepCopy()
+		}
+	}
+}
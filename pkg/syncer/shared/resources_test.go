@@ -0,0 +1,74 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shared
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestNodeResources(t *testing.T) {
+	node := func(ready corev1.ConditionStatus, unschedulable bool, capacity, allocatable string) *corev1.Node {
+		return &corev1.Node{
+			Spec: corev1.NodeSpec{Unschedulable: unschedulable},
+			Status: corev1.NodeStatus{
+				Capacity:    corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(capacity)},
+				Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(allocatable)},
+				Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+			},
+		}
+	}
+
+	pod := func(nodeName string, phase corev1.PodPhase, init string, containers ...string) *corev1.Pod {
+		pod := &corev1.Pod{
+			Spec:   corev1.PodSpec{NodeName: nodeName},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+		if init != "" {
+			pod.Spec.InitContainers = []corev1.Container{{Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(init)}}}}
+		}
+		for _, cpu := range containers {
+			pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}}})
+		}
+		return pod
+	}
+
+	nodes := []*corev1.Node{
+		node(corev1.ConditionTrue, false, "4", "3500m"),
+		node(corev1.ConditionTrue, false, "8", "7"),
+		node(corev1.ConditionFalse, false, "16", "16"),
+		node(corev1.ConditionTrue, true, "32", "32"),
+	}
+	nodes[0].Name, nodes[1].Name, nodes[2].Name, nodes[3].Name = "a", "b", "not-ready", "unschedulable"
+
+	capacity, allocatable, requested := NodeResources(nodes, []*corev1.Pod{
+		pod("a", corev1.PodRunning, "", "500m", "250m"),
+		pod("b", corev1.PodRunning, "2", "1"),
+		pod("b", corev1.PodPending, "", "100m"),
+		pod("b", corev1.PodSucceeded, "", "4"),
+		pod("not-ready", corev1.PodRunning, "", "4"),
+		pod("", corev1.PodPending, "", "4"),
+	})
+
+	require.Equal(t, "12", capacity.Cpu().String())
+	require.Equal(t, "10500m", allocatable.Cpu().String())
+	require.Equal(t, "2850m", requested.Cpu().String())
+}
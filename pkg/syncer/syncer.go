@@ -18,6 +18,7 @@ package syncer
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"time"
@@ -259,6 +260,10 @@ func StartSyncer(ctx context.Context, cfg *SyncerConfig, numSyncerThreads int, i
 	go statusSyncer.Start(ctx, numSyncerThreads)
 	go downstreamNamespaceController.Start(ctx, numSyncerThreads)
 
+	// resourceReporter reports the resources of the downstream nodes and pods in the SyncTarget status
+	resourceReporter := newResourceReporter(downstreamKubeClient)
+	go resourceReporter.Start(ctx)
+
 	if kcpfeatures.DefaultFeatureGate.Enabled(kcpfeatures.SyncerTunnel) {
 		go startSyncerTunnel(ctx, upstreamConfig, downstreamConfig, cfg.SyncTargetWorkspace, cfg.SyncTargetName)
 	}
@@ -271,7 +276,11 @@ func StartSyncer(ctx context.Context, cfg *SyncerConfig, numSyncerThreads int, i
 		// Attempt to heartbeat every second until successful. Errors are logged instead of being returned so the
 		// poll error can be safely ignored.
 		_ = wait.PollImmediateInfiniteWithContext(ctx, 1*time.Second, func(ctx context.Context) (bool, error) {
-			patchBytes := []byte(fmt.Sprintf(`[{"op":"test","path":"/metadata/uid","value":%q},{"op":"replace","path":"/status/lastSyncerHeartbeatTime","value":%q}]`, cfg.SyncTargetUID, time.Now().Format(time.RFC3339)))
+			patchBytes, err := heartbeatPatch(cfg.SyncTargetUID, time.Now(), resourceReporter)
+			if err != nil {
+				logger.Error(err, "failed to create heartbeat patch")
+				return false, nil //nolint:nilerr
+			}
 			syncTarget, err = kcpBootstrapClient.WorkloadV1alpha1().SyncTargets().Patch(ctx, cfg.SyncTargetName, types.JSONPatchType, patchBytes, metav1.PatchOptions{}, "status")
 			if err != nil {
 				logger.Error(err, "failed to set status.lastSyncerHeartbeatTime")
@@ -286,3 +295,26 @@ func StartSyncer(ctx context.Context, cfg *SyncerConfig, numSyncerThreads int, i
 
 	return nil
 }
+
+// heartbeatPatch returns a JSON patch setting the heartbeat time and, if they are reported, the resources
+// of the downstream nodes and pods, used to score the SyncTarget during placement.
+func heartbeatPatch(syncTargetUID string, now time.Time, resourceReporter *resourceReporter) ([]byte, error) {
+	patch := []map[string]interface{}{
+		{"op": "test", "path": "/metadata/uid", "value": syncTargetUID},
+		{"op": "replace", "path": "/status/lastSyncerHeartbeatTime", "value": now.Format(time.RFC3339)},
+	}
+
+	capacity, allocatable, requested, ok, err := resourceReporter.Resources()
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		patch = append(patch,
+			map[string]interface{}{"op": "add", "path": "/status/capacity", "value": capacity},
+			map[string]interface{}{"op": "add", "path": "/status/allocatable", "value": allocatable},
+			map[string]interface{}{"op": "add", "path": "/status/requested", "value": requested},
+		)
+	}
+
+	return json.Marshal(patch)
+}
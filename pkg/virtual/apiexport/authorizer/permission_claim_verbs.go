@@ -0,0 +1,131 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package authorizer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	apisv1alpha1informers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/apis/v1alpha1"
+	dynamiccontext "github.com/kcp-dev/kcp/pkg/virtual/framework/dynamic/context"
+)
+
+type permissionClaimVerbsAuthorizer struct {
+	getAPIExport    func(clusterName, apiExportName string) (*apisv1alpha1.APIExport, error)
+	listAPIBindings func(clusterName logicalcluster.Name) ([]*apisv1alpha1.APIBinding, error)
+	delegate        authorizer.Authorizer
+}
+
+// NewPermissionClaimVerbsAuthorizer creates an authorizer that checks the requested verb against the verbs of the
+// permission claim for the requested resource in the requested API export. The check is omitted if the requested
+// resource is not claimed by the API export.
+//
+// If the request targets a single workspace, the verb must also be granted by the claims accepted in the APIBindings
+// of that workspace to the API export. Hence, a consumer accepting a read-only claim keeps the service provider from
+// writing, even if the API export is changed to claim more verbs later on.
+//
+// If the verb is granted, the given delegate authorizer is executed to proceed the authorizer chain, else access is denied.
+func NewPermissionClaimVerbsAuthorizer(delegate authorizer.Authorizer, apiExportInformer apisv1alpha1informers.APIExportClusterInformer, apiBindingInformer apisv1alpha1informers.APIBindingClusterInformer) authorizer.Authorizer {
+	apiExportLister := apiExportInformer.Lister()
+	apiBindingLister := apiBindingInformer.Lister()
+
+	return &permissionClaimVerbsAuthorizer{
+		getAPIExport: func(clusterName, apiExportName string) (*apisv1alpha1.APIExport, error) {
+			return apiExportLister.Cluster(logicalcluster.New(clusterName)).Get(apiExportName)
+		},
+		listAPIBindings: func(clusterName logicalcluster.Name) ([]*apisv1alpha1.APIBinding, error) {
+			return apiBindingLister.Cluster(clusterName).List(labels.Everything())
+		},
+		delegate: delegate,
+	}
+}
+
+func (a *permissionClaimVerbsAuthorizer) Authorize(ctx context.Context, attr authorizer.Attributes) (authorizer.Decision, string, error) {
+	apiDomainKey := dynamiccontext.APIDomainKeyFrom(ctx)
+	parts := strings.Split(string(apiDomainKey), "/")
+	if len(parts) < 2 {
+		return authorizer.DecisionNoOpinion, "", fmt.Errorf("invalid API domain key")
+	}
+
+	apiExportCluster, apiExportName := parts[0], parts[1]
+	apiExport, err := a.getAPIExport(apiExportCluster, apiExportName)
+	if kerrors.IsNotFound(err) {
+		return authorizer.DecisionNoOpinion, "", fmt.Errorf("API export not found: %w", err)
+	}
+	if err != nil {
+		return authorizer.DecisionNoOpinion, "", err
+	}
+
+	claim, found := getPermissionClaim(apiExport, attr)
+	if !found {
+		// it's a resource in the API export, hence unclaimed
+		return a.delegate.Authorize(ctx, attr)
+	}
+
+	if !claim.AllowsVerb(attr.GetVerb()) {
+		return authorizer.DecisionDeny, fmt.Sprintf("verb %q not claimed for %s in API export: %q, workspace: %q",
+			attr.GetVerb(), claim.String(), apiExportName, apiExportCluster), nil
+	}
+
+	cluster := genericapirequest.ClusterFrom(ctx)
+	if cluster == nil || cluster.Name.Empty() || cluster.Wildcard {
+		// wildcard requests only see the objects labelled for claims accepted in the respective workspaces.
+		return a.delegate.Authorize(ctx, attr)
+	}
+
+	apiBindings, err := a.listAPIBindings(cluster.Name)
+	if err != nil {
+		return authorizer.DecisionNoOpinion, "", fmt.Errorf("error listing API bindings in workspace %q: %w", cluster.Name, err)
+	}
+
+	for _, apiBinding := range apiBindings {
+		exportClusterName, exportName, ok := apisv1alpha1.ReferencedAPIExport(apiBinding)
+		if !ok || exportClusterName.String() != apiExportCluster || exportName != apiExportName {
+			continue
+		}
+
+		for _, acceptedClaim := range apiBinding.Spec.PermissionClaims {
+			if acceptedClaim.State != apisv1alpha1.ClaimAccepted || !acceptedClaim.Equal(claim) {
+				continue
+			}
+			if !acceptedClaim.AllowsVerb(attr.GetVerb()) {
+				return authorizer.DecisionDeny, fmt.Sprintf("verb %q not accepted for %s in API binding: %q, workspace: %q",
+					attr.GetVerb(), claim.String(), apiBinding.Name, cluster.Name), nil
+			}
+		}
+	}
+
+	return a.delegate.Authorize(ctx, attr)
+}
+
+func getPermissionClaim(apiExport *apisv1alpha1.APIExport, attr authorizer.Attributes) (apisv1alpha1.PermissionClaim, bool) {
+	for _, claim := range apiExport.Spec.PermissionClaims {
+		if claim.Resource == attr.GetResource() && claim.Group == attr.GetAPIGroup() {
+			return claim, true
+		}
+	}
+	return apisv1alpha1.PermissionClaim{}, false
+}
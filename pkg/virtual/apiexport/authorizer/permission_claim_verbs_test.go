@@ -0,0 +1,207 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package authorizer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	dynamiccontext "github.com/kcp-dev/kcp/pkg/virtual/framework/dynamic/context"
+)
+
+func TestPermissionClaimVerbsAuthorizer(t *testing.T) {
+	configMapsClaim := func(verbs ...string) apisv1alpha1.PermissionClaim {
+		return apisv1alpha1.PermissionClaim{
+			GroupResource: apisv1alpha1.GroupResource{Resource: "configmaps"},
+			All:           true,
+			Verbs:         verbs,
+		}
+	}
+	newAPIExport := func(claims ...apisv1alpha1.PermissionClaim) *apisv1alpha1.APIExport {
+		return &apisv1alpha1.APIExport{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "export",
+				Annotations: map[string]string{
+					logicalcluster.AnnotationKey: "root:provider",
+				},
+			},
+			Spec: apisv1alpha1.APIExportSpec{PermissionClaims: claims},
+		}
+	}
+	newAPIBinding := func(exportName string, state apisv1alpha1.AcceptablePermissionClaimState, claim apisv1alpha1.PermissionClaim) *apisv1alpha1.APIBinding {
+		return &apisv1alpha1.APIBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "binding",
+				Annotations: map[string]string{
+					logicalcluster.AnnotationKey: "root:consumer",
+				},
+			},
+			Spec: apisv1alpha1.APIBindingSpec{
+				Reference: apisv1alpha1.ExportReference{
+					Workspace: &apisv1alpha1.WorkspaceExportReference{Path: "root:provider", ExportName: exportName},
+				},
+				PermissionClaims: []apisv1alpha1.AcceptablePermissionClaim{
+					{PermissionClaim: claim, State: state},
+				},
+			},
+		}
+	}
+
+	for _, tc := range []struct {
+		name        string
+		verb        string
+		resource    string
+		cluster     *genericapirequest.Cluster
+		apiExport   *apisv1alpha1.APIExport
+		apiBindings []*apisv1alpha1.APIBinding
+
+		expectedDecision authorizer.Decision
+		expectedReason   string
+	}{
+		{
+			name:             "unclaimed resource is delegated",
+			verb:             "create",
+			resource:         "widgets",
+			apiExport:        newAPIExport(configMapsClaim("get")),
+			expectedDecision: authorizer.DecisionAllow,
+			expectedReason:   "delegated",
+		},
+		{
+			name:             "claim without verbs grants all verbs",
+			verb:             "delete",
+			resource:         "configmaps",
+			apiExport:        newAPIExport(configMapsClaim()),
+			expectedDecision: authorizer.DecisionAllow,
+			expectedReason:   "delegated",
+		},
+		{
+			name:             "claimed verb is delegated",
+			verb:             "list",
+			resource:         "configmaps",
+			apiExport:        newAPIExport(configMapsClaim("get", "list", "watch")),
+			expectedDecision: authorizer.DecisionAllow,
+			expectedReason:   "delegated",
+		},
+		{
+			name:             "wildcard verb",
+			verb:             "update",
+			resource:         "configmaps",
+			apiExport:        newAPIExport(configMapsClaim("*")),
+			expectedDecision: authorizer.DecisionAllow,
+			expectedReason:   "delegated",
+		},
+		{
+			name:             "unclaimed verb is denied",
+			verb:             "update",
+			resource:         "configmaps",
+			apiExport:        newAPIExport(configMapsClaim("get", "list", "watch")),
+			expectedDecision: authorizer.DecisionDeny,
+			expectedReason:   `verb "update" not claimed for configmaps in API export: "export", workspace: "root:provider"`,
+		},
+		{
+			name:             "verb accepted in the workspace",
+			verb:             "update",
+			resource:         "configmaps",
+			cluster:          &genericapirequest.Cluster{Name: logicalcluster.New("root:consumer")},
+			apiExport:        newAPIExport(configMapsClaim("get", "update")),
+			apiBindings:      []*apisv1alpha1.APIBinding{newAPIBinding("export", apisv1alpha1.ClaimAccepted, configMapsClaim("get", "update"))},
+			expectedDecision: authorizer.DecisionAllow,
+			expectedReason:   "delegated",
+		},
+		{
+			name:             "verb not accepted in the workspace",
+			verb:             "update",
+			resource:         "configmaps",
+			cluster:          &genericapirequest.Cluster{Name: logicalcluster.New("root:consumer")},
+			apiExport:        newAPIExport(configMapsClaim("get", "update")),
+			apiBindings:      []*apisv1alpha1.APIBinding{newAPIBinding("export", apisv1alpha1.ClaimAccepted, configMapsClaim("get"))},
+			expectedDecision: authorizer.DecisionDeny,
+			expectedReason:   `verb "update" not accepted for configmaps in API binding: "binding", workspace: "root:consumer"`,
+		},
+		{
+			name:             "rejected claims are ignored",
+			verb:             "update",
+			resource:         "configmaps",
+			cluster:          &genericapirequest.Cluster{Name: logicalcluster.New("root:consumer")},
+			apiExport:        newAPIExport(configMapsClaim("get", "update")),
+			apiBindings:      []*apisv1alpha1.APIBinding{newAPIBinding("export", apisv1alpha1.ClaimRejected, configMapsClaim("get"))},
+			expectedDecision: authorizer.DecisionAllow,
+			expectedReason:   "delegated",
+		},
+		{
+			name:             "bindings to other exports are ignored",
+			verb:             "update",
+			resource:         "configmaps",
+			cluster:          &genericapirequest.Cluster{Name: logicalcluster.New("root:consumer")},
+			apiExport:        newAPIExport(configMapsClaim("get", "update")),
+			apiBindings:      []*apisv1alpha1.APIBinding{newAPIBinding("other", apisv1alpha1.ClaimAccepted, configMapsClaim("get"))},
+			expectedDecision: authorizer.DecisionAllow,
+			expectedReason:   "delegated",
+		},
+		{
+			name:             "wildcard requests skip the bindings",
+			verb:             "list",
+			resource:         "configmaps",
+			cluster:          &genericapirequest.Cluster{Name: logicalcluster.Wildcard, Wildcard: true},
+			apiExport:        newAPIExport(configMapsClaim("get", "list")),
+			apiBindings:      []*apisv1alpha1.APIBinding{newAPIBinding("export", apisv1alpha1.ClaimAccepted, configMapsClaim("get"))},
+			expectedDecision: authorizer.DecisionAllow,
+			expectedReason:   "delegated",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := dynamiccontext.WithAPIDomainKey(context.Background(), dynamiccontext.APIDomainKey("root:provider/export"))
+			if tc.cluster != nil {
+				ctx = genericapirequest.WithCluster(ctx, *tc.cluster)
+			}
+
+			auth := &permissionClaimVerbsAuthorizer{
+				getAPIExport: func(clusterName, apiExportName string) (*apisv1alpha1.APIExport, error) {
+					require.Equal(t, "root:provider", clusterName)
+					require.Equal(t, "export", apiExportName)
+					return tc.apiExport, nil
+				},
+				listAPIBindings: func(clusterName logicalcluster.Name) ([]*apisv1alpha1.APIBinding, error) {
+					require.Equal(t, logicalcluster.New("root:consumer"), clusterName)
+					return tc.apiBindings, nil
+				},
+				delegate: authorizer.AuthorizerFunc(func(ctx context.Context, a authorizer.Attributes) (authorizer.Decision, string, error) {
+					return authorizer.DecisionAllow, "delegated", nil
+				}),
+			}
+
+			dec, reason, err := auth.Authorize(ctx, &authorizer.AttributesRecord{
+				User:            &user.DefaultInfo{},
+				Verb:            tc.verb,
+				Resource:        tc.resource,
+				ResourceRequest: true,
+			})
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedDecision, dec)
+			require.Equal(t, tc.expectedReason, reason)
+		})
+	}
+}
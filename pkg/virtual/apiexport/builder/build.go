@@ -90,14 +90,27 @@ func BuildVirtualWorkspace(
 				kcpClusterClient,
 				wildcardKcpInformers.Apis().V1alpha1().APIResourceSchemas(),
 				wildcardKcpInformers.Apis().V1alpha1().APIExports(),
-				func(apiResourceSchema *apisv1alpha1.APIResourceSchema, version string, identityHash string, optionalLabelRequirements labels.Requirements) (apidefinition.APIDefinition, error) {
+				func(apiResourceSchema *apisv1alpha1.APIResourceSchema, version string, identityHash string, optionalClaim *apisv1alpha1.PermissionClaim, optionalLabelRequirements labels.Requirements) (apidefinition.APIDefinition, error) {
 					ctx, cancelFn := context.WithCancel(context.Background())
 
-					var wrapper forwardingregistry.StorageWrapper = nil
+					var wrappers forwardingregistry.StorageWrappers
 					if len(optionalLabelRequirements) > 0 {
-						wrapper = forwardingregistry.WithLabelSelector(func(_ context.Context) labels.Requirements {
+						wrappers = append(wrappers, forwardingregistry.WithLabelSelector(func(_ context.Context) labels.Requirements {
 							return optionalLabelRequirements
-						})
+						}))
+					}
+					if optionalClaim != nil && !optionalClaim.All {
+						// the claim labels do not cover writes: objects are labeled only after creation, and deletes
+						// are forwarded by name. Hence, check the resource selectors directly.
+						claim := *optionalClaim
+						wrappers = append(wrappers, forwardingregistry.WithObjectFilter(func(_ context.Context, namespace, name string) bool {
+							return claim.SelectsObject(namespace, name)
+						}))
+					}
+
+					var wrapper forwardingregistry.StorageWrapper
+					if len(wrappers) > 0 {
+						wrapper = &wrappers
 					}
 
 					storageBuilder := provideDelegatingRestStorage(ctx, dynamicClusterClient, identityHash, wrapper)
@@ -135,6 +148,7 @@ func BuildVirtualWorkspace(
 				for name, informer := range map[string]cache.SharedIndexInformer{
 					"apiresourceschemas": wildcardKcpInformers.Apis().V1alpha1().APIResourceSchemas().Informer(),
 					"apiexports":         wildcardKcpInformers.Apis().V1alpha1().APIExports().Informer(),
+					"apibindings":        wildcardKcpInformers.Apis().V1alpha1().APIBindings().Informer(),
 				} {
 					if !cache.WaitForNamedCacheSync(name, hookContext.StopCh, informer.HasSynced) {
 						klog.Errorf("informer not synced")
@@ -216,7 +230,10 @@ func newAuthorizer(kubeClusterClient, deepSARClient kcpkubernetesclientset.Clust
 	maximalPermissionAuth := virtualapiexportauth.NewMaximalPermissionAuthorizer(deepSARClient, kcpinformers.Apis().V1alpha1().APIExports())
 	maximalPermissionAuth = authorization.NewDecorator("virtual.apiexport.maxpermissionpolicy.authorization.kcp.dev", maximalPermissionAuth).AddAuditLogging().AddAnonymization().AddReasonAnnotation()
 
-	apiExportsContentAuth := virtualapiexportauth.NewAPIExportsContentAuthorizer(maximalPermissionAuth, kubeClusterClient)
+	permissionClaimVerbsAuth := virtualapiexportauth.NewPermissionClaimVerbsAuthorizer(maximalPermissionAuth, kcpinformers.Apis().V1alpha1().APIExports(), kcpinformers.Apis().V1alpha1().APIBindings())
+	permissionClaimVerbsAuth = authorization.NewDecorator("virtual.apiexport.permissionclaimverbs.authorization.kcp.dev", permissionClaimVerbsAuth).AddAuditLogging().AddAnonymization().AddReasonAnnotation()
+
+	apiExportsContentAuth := virtualapiexportauth.NewAPIExportsContentAuthorizer(permissionClaimVerbsAuth, kubeClusterClient)
 	apiExportsContentAuth = authorization.NewDecorator("virtual.apiexport.content.authorization.kcp.dev", apiExportsContentAuth).AddAuditLogging().AddAnonymization()
 
 	return apiExportsContentAuth
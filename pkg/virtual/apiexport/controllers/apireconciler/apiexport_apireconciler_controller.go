@@ -48,7 +48,9 @@ const (
 	ControllerName = "kcp-virtual-apiexport-api-reconciler"
 )
 
-type CreateAPIDefinitionFunc func(apiResourceSchema *apisv1alpha1.APIResourceSchema, version string, identityHash string, additionalLabelRequirements labels.Requirements) (apidefinition.APIDefinition, error)
+// CreateAPIDefinitionFunc creates the API definition for a version of an APIResourceSchema. For claimed resources,
+// the permission claim and the label requirements selecting the claimed objects are passed.
+type CreateAPIDefinitionFunc func(apiResourceSchema *apisv1alpha1.APIResourceSchema, version string, identityHash string, optionalClaim *apisv1alpha1.PermissionClaim, additionalLabelRequirements labels.Requirements) (apidefinition.APIDefinition, error)
 
 // NewAPIReconciler returns a new controller which reconciles APIResourceImport resources
 // and delegates the corresponding SyncTargetAPI management to the given SyncTargetAPIManager.
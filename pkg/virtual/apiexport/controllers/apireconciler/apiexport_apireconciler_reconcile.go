@@ -23,6 +23,7 @@ import (
 
 	"github.com/kcp-dev/logicalcluster/v2"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -179,11 +180,16 @@ func (c *APIReconciler) reconcile(ctx context.Context, apiExport *apisv1alpha1.A
 				Resource: apiResourceSchema.Spec.Names.Plural,
 			}
 
+			var claim *apisv1alpha1.PermissionClaim
+			if c, ok := claims[gvr.GroupResource()]; ok {
+				claim = &c
+			}
+
 			oldDef, found := oldSet[gvr]
 			if found {
 				oldDef := oldDef.(apiResourceSchemaApiDefinition)
-				if oldDef.UID == apiResourceSchema.UID && oldDef.IdentityHash == apiExport.Status.IdentityHash {
-					// this is the same schema and identity as before. no need to update.
+				if oldDef.UID == apiResourceSchema.UID && oldDef.IdentityHash == apiExport.Status.IdentityHash && equality.Semantic.DeepEqual(oldDef.Claim, claim) {
+					// this is the same schema, identity and claim as before. no need to update.
 					newSet[gvr] = oldDef
 					preservedGVR = append(preservedGVR, gvrString(gvr))
 					continue
@@ -191,10 +197,10 @@ func (c *APIReconciler) reconcile(ctx context.Context, apiExport *apisv1alpha1.A
 			}
 
 			var labelReqs labels.Requirements
-			if c, ok := claims[gvr.GroupResource()]; ok {
-				key, label, err := permissionclaims.ToLabelKeyAndValue(clusterName, apiExport.Name, c)
+			if claim != nil {
+				key, label, err := permissionclaims.ToLabelKeyAndValue(clusterName, apiExport.Name, *claim)
 				if err != nil {
-					return fmt.Errorf(fmt.Sprintf("failed to convert permission claim %v to label key and value: %v", c, err))
+					return fmt.Errorf(fmt.Sprintf("failed to convert permission claim %v to label key and value: %v", *claim, err))
 				}
 				claimLabels := []string{label}
 				if gvr.GroupResource() == apisv1alpha1.Resource("apibindings") {
@@ -203,13 +209,13 @@ func (c *APIReconciler) reconcile(ctx context.Context, apiExport *apisv1alpha1.A
 				}
 				req, err := labels.NewRequirement(key, selection.In, claimLabels)
 				if err != nil {
-					return fmt.Errorf(fmt.Sprintf("failed to create label requirement for permission claim %v: %v", c, err))
+					return fmt.Errorf(fmt.Sprintf("failed to create label requirement for permission claim %v: %v", *claim, err))
 				}
 				labelReqs = labels.Requirements{*req}
 			}
 
 			logger.Info("creating API definition", "gvr", gvr, "labels", labelReqs)
-			apiDefinition, err := c.createAPIDefinition(apiResourceSchema, version.Name, identities[gvr.GroupResource()], labelReqs)
+			apiDefinition, err := c.createAPIDefinition(apiResourceSchema, version.Name, identities[gvr.GroupResource()], claim, labelReqs)
 			if err != nil {
 				// TODO(ncdc): would be nice to expose some sort of user-visible error
 				logger.Error(err, "error creating api definition", "gvr", gvr)
@@ -220,6 +226,7 @@ func (c *APIReconciler) reconcile(ctx context.Context, apiExport *apisv1alpha1.A
 				APIDefinition: apiDefinition,
 				UID:           apiResourceSchema.UID,
 				IdentityHash:  apiExport.Status.IdentityHash,
+				Claim:         claim,
 			}
 			newGVRs = append(newGVRs, gvrString(gvr))
 		}
@@ -262,6 +269,7 @@ type apiResourceSchemaApiDefinition struct {
 
 	UID          types.UID
 	IdentityHash string
+	Claim        *apisv1alpha1.PermissionClaim
 }
 
 func gvrString(gvr schema.GroupVersionResource) string {
@@ -55,6 +55,12 @@ import (
 var noxusGVR = schema.GroupVersionResource{Group: "mygroup.example.com", Resource: "noxus", Version: "v1beta1"}
 
 func newStorage(t *testing.T, clusterClient kcpdynamic.ClusterInterface, apiExportIdentityHash string, patchConflictRetryBackoff *wait.Backoff) (mainStorage, statusStorage rest.Storage) {
+	return newStorageWithWrapper(t, clusterClient, apiExportIdentityHash, patchConflictRetryBackoff,
+		forwardingregistry.StorageWrapperFunc(func(_ schema.GroupResource, store *forwardingregistry.StoreFuncs) {
+		}))
+}
+
+func newStorageWithWrapper(t *testing.T, clusterClient kcpdynamic.ClusterInterface, apiExportIdentityHash string, patchConflictRetryBackoff *wait.Backoff, wrapper forwardingregistry.StorageWrapper) (mainStorage, statusStorage rest.Storage) {
 	gvr := noxusGVR
 	groupVersion := gvr.GroupVersion()
 
@@ -110,8 +116,7 @@ func newStorage(t *testing.T, clusterClient kcpdynamic.ClusterInterface, apiExpo
 		nil,
 		clusterClient,
 		patchConflictRetryBackoff,
-		forwardingregistry.StorageWrapperFunc(func(_ schema.GroupResource, store *forwardingregistry.StoreFuncs) {
-		}))
+		wrapper)
 }
 
 func createResource(namespace, name string) *unstructured.Unstructured {
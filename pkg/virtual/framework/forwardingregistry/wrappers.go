@@ -21,12 +21,15 @@ import (
 	"fmt"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/internalversion"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/watch"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
 )
 
 func WithStaticLabelSelector(labelSelector labels.Requirements) StorageWrapper {
@@ -76,3 +79,181 @@ func WithLabelSelector(labelSelectorFrom func(ctx context.Context) labels.Requir
 		}
 	})
 }
+
+// ObjectFilter returns true if the object with the given namespace and name is exposed by the storage.
+type ObjectFilter func(ctx context.Context, namespace, name string) bool
+
+// WithObjectFilter returns a StorageWrapper that hides objects not passing the given filter from the reading calls
+// (Get, List and Watch), and refuses the write calls (Create, Update, Delete and DeleteCollection) for them.
+// Paged lists are refilled up to the requested limit.
+func WithObjectFilter(filter ObjectFilter) StorageWrapper {
+	return StorageWrapperFunc(func(resource schema.GroupResource, storage *StoreFuncs) {
+		requestNamespace := func(ctx context.Context) string {
+			namespace, _ := genericapirequest.NamespaceFrom(ctx)
+			return namespace
+		}
+
+		delegateGetter := storage.GetterFunc
+		storage.GetterFunc = func(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+			if !filter(ctx, requestNamespace(ctx), name) {
+				return nil, errors.NewNotFound(resource, name)
+			}
+			return delegateGetter.Get(ctx, name, options)
+		}
+
+		// Objects are filtered after paging. Hence, further pages are requested until the limit is reached or
+		// the list is complete, to not return short or empty pages with a continue token.
+		delegateLister := storage.ListerFunc
+		storage.ListerFunc = func(ctx context.Context, options *internalversion.ListOptions) (runtime.Object, error) {
+			list, err := delegateLister.List(ctx, options)
+			if err != nil {
+				return list, err
+			}
+
+			var filtered []runtime.Object
+			appendSelected := func(page runtime.Object) error {
+				return meta.EachListItem(page, func(obj runtime.Object) error {
+					metaObj, err := meta.Accessor(obj)
+					if err != nil {
+						return err
+					}
+					if filter(ctx, metaObj.GetNamespace(), metaObj.GetName()) {
+						filtered = append(filtered, obj)
+					}
+					return nil
+				})
+			}
+			if err := appendSelected(list); err != nil {
+				return nil, err
+			}
+
+			listMeta, err := meta.ListAccessor(list)
+			if err != nil {
+				return nil, err
+			}
+			if options != nil && options.Limit > 0 {
+				for int64(len(filtered)) < options.Limit && listMeta.GetContinue() != "" {
+					// the continue token encodes the resource version of the first page
+					pageOptions := options.DeepCopy()
+					pageOptions.Continue = listMeta.GetContinue()
+					pageOptions.Limit = options.Limit - int64(len(filtered))
+					pageOptions.ResourceVersion = ""
+					pageOptions.ResourceVersionMatch = ""
+					page, err := delegateLister.List(ctx, pageOptions)
+					if err != nil {
+						return nil, err
+					}
+					if err := appendSelected(page); err != nil {
+						return nil, err
+					}
+					pageMeta, err := meta.ListAccessor(page)
+					if err != nil {
+						return nil, err
+					}
+					listMeta.SetContinue(pageMeta.GetContinue())
+				}
+				// the remaining items of the delegate include objects not passing the filter
+				listMeta.SetRemainingItemCount(nil)
+			}
+
+			if err := meta.SetList(list, filtered); err != nil {
+				return nil, err
+			}
+			return list, nil
+		}
+
+		delegateWatcher := storage.WatcherFunc
+		storage.WatcherFunc = func(ctx context.Context, options *internalversion.ListOptions) (watch.Interface, error) {
+			w, err := delegateWatcher.Watch(ctx, options)
+			if err != nil {
+				return w, err
+			}
+			return watch.Filter(w, func(event watch.Event) (watch.Event, bool) {
+				if event.Type == watch.Error || event.Type == watch.Bookmark {
+					return event, true
+				}
+				metaObj, err := meta.Accessor(event.Object)
+				if err != nil {
+					return event, true
+				}
+				return event, filter(ctx, metaObj.GetNamespace(), metaObj.GetName())
+			}), nil
+		}
+
+		delegateCreater := storage.CreaterFunc
+		storage.CreaterFunc = func(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+			metaObj, err := meta.Accessor(obj)
+			if err != nil {
+				return nil, err
+			}
+			namespace := metaObj.GetNamespace()
+			if namespace == "" {
+				namespace = requestNamespace(ctx)
+			}
+			if !filter(ctx, namespace, metaObj.GetName()) {
+				return nil, errors.NewForbidden(resource, metaObj.GetName(), fmt.Errorf("object is not selected"))
+			}
+			return delegateCreater.Create(ctx, obj, createValidation, options)
+		}
+
+		delegateUpdater := storage.UpdaterFunc
+		storage.UpdaterFunc = func(ctx context.Context, name string, objInfo rest.UpdatedObjectInfo, createValidation rest.ValidateObjectFunc, updateValidation rest.ValidateObjectUpdateFunc, forceAllowCreate bool, options *metav1.UpdateOptions) (runtime.Object, bool, error) {
+			if !filter(ctx, requestNamespace(ctx), name) {
+				if forceAllowCreate {
+					return nil, false, errors.NewForbidden(resource, name, fmt.Errorf("object is not selected"))
+				}
+				return nil, false, errors.NewNotFound(resource, name)
+			}
+			return delegateUpdater.Update(ctx, name, objInfo, createValidation, updateValidation, forceAllowCreate, options)
+		}
+
+		delegateDeleter := storage.GracefulDeleterFunc
+		storage.GracefulDeleterFunc = func(ctx context.Context, name string, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions) (runtime.Object, bool, error) {
+			if !filter(ctx, requestNamespace(ctx), name) {
+				return nil, false, errors.NewNotFound(resource, name)
+			}
+			return delegateDeleter.Delete(ctx, name, deleteValidation, options)
+		}
+
+		// DeleteCollection is forwarded as one call, which cannot be restricted to the selected objects. Hence,
+		// the selected objects are listed and deleted one by one.
+		lister := storage.ListerFunc
+		deleter := storage.GracefulDeleterFunc
+		listFactory := storage.ListFactoryFunc
+		storage.CollectionDeleterFunc = func(ctx context.Context, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions, listOptions *internalversion.ListOptions) (runtime.Object, error) {
+			list, err := lister.List(ctx, listOptions)
+			if err != nil {
+				return nil, err
+			}
+			items, err := meta.ExtractList(list)
+			if err != nil {
+				return nil, err
+			}
+
+			var deleted []runtime.Object
+			for _, item := range items {
+				metaObj, err := meta.Accessor(item)
+				if err != nil {
+					return nil, err
+				}
+				itemCtx := ctx
+				if metaObj.GetNamespace() != "" {
+					itemCtx = genericapirequest.WithNamespace(ctx, metaObj.GetNamespace())
+				}
+				if _, _, err := deleter.Delete(itemCtx, metaObj.GetName(), deleteValidation, options.DeepCopy()); err != nil {
+					if errors.IsNotFound(err) {
+						continue
+					}
+					return nil, err
+				}
+				deleted = append(deleted, item)
+			}
+
+			result := listFactory.NewList()
+			if err := meta.SetList(result, deleted); err != nil {
+				return nil, err
+			}
+			return result, nil
+		}
+	})
+}
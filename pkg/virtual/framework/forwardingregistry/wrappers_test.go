@@ -0,0 +1,200 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package forwardingregistry_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+
+	kcpfakedynamic "github.com/kcp-dev/client-go/third_party/k8s.io/client-go/dynamic/fake"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/kcp-dev/kcp/pkg/virtual/framework/forwardingregistry"
+)
+
+func selectFoo(_ context.Context, namespace, name string) bool {
+	return namespace == "default" && name == "foo"
+}
+
+func TestObjectFilterGet(t *testing.T) {
+	fakeClient := kcpfakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), createResource("default", "foo"), createResource("default", "bar"))
+	storage, _ := newStorageWithWrapper(t, fakeClient, "", nil, forwardingregistry.WithObjectFilter(selectFoo))
+	ctx := request.WithNamespace(context.Background(), "default")
+	ctx = request.WithCluster(ctx, request.Cluster{Name: logicalcluster.New("test")})
+
+	getter := storage.(rest.Getter)
+	_, err := getter.Get(ctx, "foo", &metav1.GetOptions{})
+	require.NoError(t, err)
+
+	_, err = getter.Get(ctx, "bar", &metav1.GetOptions{})
+	require.EqualError(t, err, "noxus.mygroup.example.com \"bar\" not found")
+}
+
+func TestObjectFilterList(t *testing.T) {
+	fakeClient := kcpfakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), createResource("default", "foo"), createResource("default", "bar"))
+	storage, _ := newStorageWithWrapper(t, fakeClient, "", nil, forwardingregistry.WithObjectFilter(selectFoo))
+	ctx := request.WithNamespace(context.Background(), "default")
+	ctx = request.WithCluster(ctx, request.Cluster{Name: logicalcluster.New("test")})
+
+	result, err := storage.(rest.Lister).List(ctx, &internalversion.ListOptions{})
+	require.NoError(t, err)
+	items := result.(*unstructured.UnstructuredList).Items
+	require.Len(t, items, 1)
+	require.Equal(t, "foo", items[0].GetName())
+}
+
+func TestObjectFilterListPaged(t *testing.T) {
+	names := []string{"bar", "foo", "baz", "foo2", "qux", "foo3"}
+	selectFoos := func(_ context.Context, namespace, name string) bool {
+		return strings.HasPrefix(name, "foo")
+	}
+
+	// serve pages of the requested size, with the index of the next item as continue token
+	var limits []int64
+	paging := forwardingregistry.StorageWrapperFunc(func(_ schema.GroupResource, store *forwardingregistry.StoreFuncs) {
+		store.ListerFunc = func(ctx context.Context, options *internalversion.ListOptions) (runtime.Object, error) {
+			limits = append(limits, options.Limit)
+			start := 0
+			if options.Continue != "" {
+				start, _ = strconv.Atoi(options.Continue)
+			}
+			end := len(names)
+			if options.Limit > 0 && start+int(options.Limit) < end {
+				end = start + int(options.Limit)
+			}
+			list := &unstructured.UnstructuredList{}
+			for _, name := range names[start:end] {
+				list.Items = append(list.Items, *createResource("default", name))
+			}
+			if end < len(names) {
+				list.SetContinue(strconv.Itoa(end))
+				remaining := int64(len(names) - end)
+				list.SetRemainingItemCount(&remaining)
+			}
+			return list, nil
+		}
+	})
+	fakeClient := kcpfakedynamic.NewSimpleDynamicClient(runtime.NewScheme())
+	storage, _ := newStorageWithWrapper(t, fakeClient, "", nil, &forwardingregistry.StorageWrappers{paging, forwardingregistry.WithObjectFilter(selectFoos)})
+	ctx := request.WithNamespace(context.Background(), "default")
+	ctx = request.WithCluster(ctx, request.Cluster{Name: logicalcluster.New("test")})
+
+	result, err := storage.(rest.Lister).List(ctx, &internalversion.ListOptions{Limit: 2})
+	require.NoError(t, err)
+	list := result.(*unstructured.UnstructuredList)
+	require.Len(t, list.Items, 2)
+	require.Equal(t, "foo", list.Items[0].GetName())
+	require.Equal(t, "foo2", list.Items[1].GetName())
+	require.Equal(t, "4", list.GetContinue())
+	require.Nil(t, list.GetRemainingItemCount())
+	require.Equal(t, []int64{2, 1, 1}, limits)
+
+	result, err = storage.(rest.Lister).List(ctx, &internalversion.ListOptions{Limit: 2, Continue: list.GetContinue()})
+	require.NoError(t, err)
+	list = result.(*unstructured.UnstructuredList)
+	require.Len(t, list.Items, 1)
+	require.Equal(t, "foo3", list.Items[0].GetName())
+	require.Empty(t, list.GetContinue())
+}
+
+func TestObjectFilterWatch(t *testing.T) {
+	fakeClient := kcpfakedynamic.NewSimpleDynamicClient(runtime.NewScheme())
+	storage, _ := newStorageWithWrapper(t, fakeClient, "", nil, forwardingregistry.WithObjectFilter(selectFoo))
+	ctx := request.WithNamespace(context.Background(), "default")
+	ctx = request.WithCluster(ctx, request.Cluster{Name: logicalcluster.New("test")})
+
+	checkWatchEvents(t,
+		func() {
+			_ = fakeClient.Tracker().Cluster(logicalcluster.New("test")).Add(createResource("default", "bar"))
+			_ = fakeClient.Tracker().Cluster(logicalcluster.New("test")).Add(createResource("default", "foo"))
+		},
+		func() (watch.Interface, error) {
+			return storage.(rest.Watcher).Watch(ctx, &internalversion.ListOptions{})
+		},
+		[]watch.Event{
+			{Type: watch.Added, Object: createResource("default", "foo")},
+		})
+}
+
+func TestObjectFilterCreate(t *testing.T) {
+	fakeClient := kcpfakedynamic.NewSimpleDynamicClient(runtime.NewScheme())
+	storage, _ := newStorageWithWrapper(t, fakeClient, "", nil, forwardingregistry.WithObjectFilter(selectFoo))
+	ctx := request.WithNamespace(context.Background(), "default")
+	ctx = request.WithCluster(ctx, request.Cluster{Name: logicalcluster.New("test")})
+
+	creater := storage.(rest.Creater)
+	_, err := creater.Create(ctx, createResource("default", "foo"), rest.ValidateAllObjectFunc, &metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	_, err = creater.Create(ctx, createResource("default", "bar"), rest.ValidateAllObjectFunc, &metav1.CreateOptions{})
+	require.True(t, errors.IsForbidden(err), "expected forbidden error, got: %v", err)
+}
+
+func TestObjectFilterUpdate(t *testing.T) {
+	resource := createResource("default", "bar")
+	fakeClient := kcpfakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), resource)
+	storage, _ := newStorageWithWrapper(t, fakeClient, "", nil, forwardingregistry.WithObjectFilter(selectFoo))
+	ctx := request.WithNamespace(context.Background(), "default")
+	ctx = request.WithCluster(ctx, request.Cluster{Name: logicalcluster.New("test")})
+
+	updater := storage.(rest.Updater)
+	_, _, err := updater.Update(ctx, "bar", rest.DefaultUpdatedObjectInfo(resource), rest.ValidateAllObjectFunc, rest.ValidateAllObjectUpdateFunc, false, &metav1.UpdateOptions{})
+	require.EqualError(t, err, "noxus.mygroup.example.com \"bar\" not found")
+
+	_, _, err = updater.Update(ctx, "bar", rest.DefaultUpdatedObjectInfo(resource), rest.ValidateAllObjectFunc, rest.ValidateAllObjectUpdateFunc, true, &metav1.UpdateOptions{})
+	require.True(t, errors.IsForbidden(err), "expected forbidden error, got: %v", err)
+}
+
+func TestObjectFilterDelete(t *testing.T) {
+	fakeClient := kcpfakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), createResource("default", "foo"), createResource("default", "bar"))
+
+	var deleted []string
+	recordDeletes := forwardingregistry.StorageWrapperFunc(func(_ schema.GroupResource, store *forwardingregistry.StoreFuncs) {
+		store.GracefulDeleterFunc = func(ctx context.Context, name string, _ rest.ValidateObjectFunc, _ *metav1.DeleteOptions) (runtime.Object, bool, error) {
+			namespace, _ := request.NamespaceFrom(ctx)
+			deleted = append(deleted, namespace+"/"+name)
+			return nil, true, nil
+		}
+	})
+	storage, _ := newStorageWithWrapper(t, fakeClient, "", nil, &forwardingregistry.StorageWrappers{recordDeletes, forwardingregistry.WithObjectFilter(selectFoo)})
+	ctx := request.WithNamespace(context.Background(), "default")
+	ctx = request.WithCluster(ctx, request.Cluster{Name: logicalcluster.New("test")})
+
+	_, _, err := storage.(rest.GracefulDeleter).Delete(ctx, "bar", rest.ValidateAllObjectFunc, &metav1.DeleteOptions{})
+	require.EqualError(t, err, "noxus.mygroup.example.com \"bar\" not found")
+	require.Empty(t, deleted)
+
+	result, err := storage.(rest.CollectionDeleter).DeleteCollection(ctx, rest.ValidateAllObjectFunc, &metav1.DeleteOptions{}, &internalversion.ListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"default/foo"}, deleted)
+	items := result.(*unstructured.UnstructuredList).Items
+	require.Len(t, items, 1)
+	require.Equal(t, "foo", items[0].GetName())
+}
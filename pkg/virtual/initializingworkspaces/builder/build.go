@@ -33,6 +33,7 @@ import (
 
 	authenticationv1 "k8s.io/api/authentication/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apiserver/pkg/authorization/authorizer"
 	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
@@ -60,12 +61,61 @@ import (
 	"github.com/kcp-dev/kcp/pkg/virtual/initializingworkspaces"
 )
 
+// Lifecycle describes a phase of the lifecycle of workspaces, in which workspaces are served
+// to the controllers named in their status, e.g. to initializers or finalizers. The name of
+// the controller is the API domain key of a request.
+type Lifecycle struct {
+	// VirtualWorkspaceName is the name of the virtual workspace serving the phase.
+	VirtualWorkspaceName string
+	// Controller is the kind of controller, e.g. initializer, used in error messages.
+	Controller string
+	// StatusField is the field of the ClusterWorkspace status listing the controllers,
+	// which a controller can only remove itself from.
+	StatusField string
+	// Verb is required on the ClusterWorkspaceType of the controller to access the workspaces.
+	Verb string
+	// Requirements returns the label requirements selecting the workspaces of the controller.
+	Requirements func(controller string) (labels.Requirements, error)
+	// Accessible returns true if the controller can access the content of the workspace.
+	Accessible func(clusterWorkspace *tenancyv1alpha1.ClusterWorkspace, controller string) bool
+}
+
+// Initializing serves initializing workspaces to their initializers.
+var Initializing = Lifecycle{
+	VirtualWorkspaceName: initializingworkspaces.VirtualWorkspaceName,
+	Controller:           "initializer",
+	StatusField:          "initializers",
+	Verb:                 "initialize",
+	Requirements: func(initializer string) (labels.Requirements, error) {
+		key, value := initialization.InitializerToLabel(tenancyv1alpha1.ClusterWorkspaceInitializer(initializer))
+		return labelRequirements(map[string]string{
+			tenancyv1alpha1.ClusterWorkspacePhaseLabel: string(tenancyv1alpha1.ClusterWorkspacePhaseInitializing),
+			key: value,
+		})
+	},
+	Accessible: func(clusterWorkspace *tenancyv1alpha1.ClusterWorkspace, initializer string) bool {
+		return clusterWorkspace.Status.Phase == tenancyv1alpha1.ClusterWorkspacePhaseInitializing && initialization.InitializerPresent(tenancyv1alpha1.ClusterWorkspaceInitializer(initializer), clusterWorkspace.Status.Initializers)
+	},
+}
+
 func BuildVirtualWorkspace(
 	cfg *rest.Config,
 	rootPathPrefix string,
 	dynamicClusterClient kcpdynamic.ClusterInterface,
 	kubeClusterClient kcpkubernetesclientset.ClusterInterface,
 	wildcardKcpInformers kcpinformers.SharedInformerFactory,
+) ([]rootapiserver.NamedVirtualWorkspace, error) {
+	return BuildLifecycleVirtualWorkspace(Initializing, cfg, rootPathPrefix, dynamicClusterClient, kubeClusterClient, wildcardKcpInformers)
+}
+
+// BuildLifecycleVirtualWorkspace builds the virtual workspaces serving the given lifecycle phase.
+func BuildLifecycleVirtualWorkspace(
+	lifecycle Lifecycle,
+	cfg *rest.Config,
+	rootPathPrefix string,
+	dynamicClusterClient kcpdynamic.ClusterInterface,
+	kubeClusterClient kcpkubernetesclientset.ClusterInterface,
+	wildcardKcpInformers kcpinformers.SharedInformerFactory,
 ) ([]rootapiserver.NamedVirtualWorkspace, error) {
 	if !strings.HasSuffix(rootPathPrefix, "/") {
 		rootPathPrefix += "/"
@@ -98,7 +148,7 @@ func BuildVirtualWorkspace(
 		return export.Status.IdentityHash, nil
 	}
 
-	wildcardWorkspacesName := initializingworkspaces.VirtualWorkspaceName + "-wildcard-workspaces"
+	wildcardWorkspacesName := lifecycle.VirtualWorkspaceName + "-wildcard-workspaces"
 	wildcardWorkspaces := &virtualworkspacesdynamic.DynamicVirtualWorkspace{
 		RootPathResolver: framework.RootPathResolverFunc(func(urlPath string, requestContext context.Context) (accepted bool, prefixToStrip string, completedContext context.Context) {
 			cluster, apiDomain, prefixToStrip, ok := digestUrl(urlPath, rootPathPrefix)
@@ -115,7 +165,7 @@ func BuildVirtualWorkspace(
 			completedContext = dynamiccontext.WithAPIDomainKey(completedContext, apiDomain)
 			return true, prefixToStrip, completedContext
 		}),
-		Authorizer: newAuthorizer(kubeClusterClient),
+		Authorizer: newAuthorizer(lifecycle, kubeClusterClient),
 		ReadyChecker: framework.ReadyFunc(func() error {
 			return nil
 		}),
@@ -125,12 +175,12 @@ func BuildVirtualWorkspace(
 				dynamicClusterClient: dynamicClusterClient,
 				exposeSubresources:   false,
 				resource:             &clusterWorkspaceResource,
-				storageProvider:      provideFilteredClusterWorkspacesReadOnlyRestStorage(getTenancyIdentity),
+				storageProvider:      provideFilteredClusterWorkspacesReadOnlyRestStorage(lifecycle, getTenancyIdentity),
 			}, nil
 		},
 	}
 
-	workspacesName := initializingworkspaces.VirtualWorkspaceName + "-workspaces"
+	workspacesName := lifecycle.VirtualWorkspaceName + "-workspaces"
 	workspaces := &virtualworkspacesdynamic.DynamicVirtualWorkspace{
 		RootPathResolver: framework.RootPathResolverFunc(func(urlPath string, ctx context.Context) (accepted bool, prefixToStrip string, completedContext context.Context) {
 			cluster, apiDomain, prefixToStrip, ok := digestUrl(urlPath, rootPathPrefix)
@@ -152,7 +202,7 @@ func BuildVirtualWorkspace(
 			completedContext = dynamiccontext.WithAPIDomainKey(completedContext, apiDomain)
 			return true, prefixToStrip, completedContext
 		}),
-		Authorizer: newAuthorizer(kubeClusterClient),
+		Authorizer: newAuthorizer(lifecycle, kubeClusterClient),
 		ReadyChecker: framework.ReadyFunc(func() error {
 			return nil
 		}),
@@ -162,13 +212,13 @@ func BuildVirtualWorkspace(
 				dynamicClusterClient: dynamicClusterClient,
 				exposeSubresources:   true,
 				resource:             &clusterWorkspaceResource,
-				storageProvider:      provideDelegatingClusterWorkspacesRestStorage(getTenancyIdentity),
+				storageProvider:      provideDelegatingClusterWorkspacesRestStorage(lifecycle, getTenancyIdentity),
 			}, nil
 		},
 	}
 
 	workspaceContentReadyCh := make(chan struct{})
-	workspaceContentName := initializingworkspaces.VirtualWorkspaceName + "-workspace-content"
+	workspaceContentName := lifecycle.VirtualWorkspaceName + "-workspace-content"
 	workspaceContent := &handler.VirtualWorkspace{
 		RootPathResolver: framework.RootPathResolverFunc(func(urlPath string, context context.Context) (accepted bool, prefixToStrip string, completedContext context.Context) {
 			cluster, apiDomain, prefixToStrip, ok := digestUrl(urlPath, rootPathPrefix)
@@ -194,7 +244,7 @@ func BuildVirtualWorkspace(
 			completedContext = dynamiccontext.WithAPIDomainKey(completedContext, apiDomain)
 			return true, prefixToStrip, completedContext
 		}),
-		Authorizer: newAuthorizer(kubeClusterClient),
+		Authorizer: newAuthorizer(lifecycle, kubeClusterClient),
 		ReadyChecker: framework.ReadyFunc(func() error {
 			select {
 			case <-workspaceContentReadyCh:
@@ -240,9 +290,9 @@ func BuildVirtualWorkspace(
 					return
 				}
 
-				initializer := tenancyv1alpha1.ClusterWorkspaceInitializer(dynamiccontext.APIDomainKeyFrom(request.Context()))
-				if clusterWorkspace.Status.Phase != tenancyv1alpha1.ClusterWorkspacePhaseInitializing || !initialization.InitializerPresent(initializer, clusterWorkspace.Status.Initializers) {
-					http.Error(writer, fmt.Sprintf("initializer %q cannot access this workspace", initializer), http.StatusForbidden)
+				controller := string(dynamiccontext.APIDomainKeyFrom(request.Context()))
+				if !lifecycle.Accessible(clusterWorkspace, controller) {
+					http.Error(writer, fmt.Sprintf("%s %q cannot access this workspace", lifecycle.Controller, controller), http.StatusForbidden)
 					return
 				}
 
@@ -330,13 +380,14 @@ func digestUrl(urlPath, rootPathPrefix string) (
 	//  /services/initializingworkspaces/<initializer>/clusters/<something>/apis/workload.kcp.dev/v1alpha1/synctargets
 	//                                  └───────────┐
 	// Where the withoutRootPathPrefix starts here: ┘
+	// The same holds for finalizers below /services/terminatingworkspaces.
 	parts := strings.SplitN(withoutRootPathPrefix, "/", 2)
 	if len(parts) < 2 {
 		return genericapirequest.Cluster{}, dynamiccontext.APIDomainKey(""), "", false
 	}
 
-	initializerName := parts[0]
-	if initializerName == "" {
+	controllerName := parts[0]
+	if controllerName == "" {
 		return genericapirequest.Cluster{}, dynamiccontext.APIDomainKey(""), "", false
 	}
 
@@ -358,7 +409,7 @@ func digestUrl(urlPath, rootPathPrefix string) (
 		realPath += parts[1]
 	}
 
-	return genericapirequest.Cluster{Name: clusterName, Wildcard: clusterName == logicalcluster.Wildcard}, dynamiccontext.APIDomainKey(initializerName), strings.TrimSuffix(urlPath, realPath), true
+	return genericapirequest.Cluster{Name: clusterName, Wildcard: clusterName == logicalcluster.Wildcard}, dynamiccontext.APIDomainKey(controllerName), strings.TrimSuffix(urlPath, realPath), true
 }
 
 // URLFor returns the absolute path for the specified initializer.
@@ -372,11 +423,11 @@ type singleResourceAPIDefinitionSetProvider struct {
 	dynamicClusterClient kcpdynamic.ClusterInterface
 	resource             *apisv1alpha1.APIResourceSchema
 	exposeSubresources   bool
-	storageProvider      func(ctx context.Context, clusterClient kcpdynamic.ClusterInterface, initializer tenancyv1alpha1.ClusterWorkspaceInitializer) (apiserver.RestProviderFunc, error)
+	storageProvider      func(ctx context.Context, clusterClient kcpdynamic.ClusterInterface, controller string) (apiserver.RestProviderFunc, error)
 }
 
 func (a *singleResourceAPIDefinitionSetProvider) GetAPIDefinitionSet(ctx context.Context, key dynamiccontext.APIDomainKey) (apis apidefinition.APIDefinitionSet, apisExist bool, err error) {
-	restProvider, err := a.storageProvider(ctx, a.dynamicClusterClient, tenancyv1alpha1.ClusterWorkspaceInitializer(key))
+	restProvider, err := a.storageProvider(ctx, a.dynamicClusterClient, string(key))
 	if err != nil {
 		return nil, false, err
 	}
@@ -404,12 +455,12 @@ func (a *singleResourceAPIDefinitionSetProvider) GetAPIDefinitionSet(ctx context
 
 var _ apidefinition.APIDefinitionSetGetter = &singleResourceAPIDefinitionSetProvider{}
 
-func newAuthorizer(client kcpkubernetesclientset.ClusterInterface) authorizer.AuthorizerFunc {
+func newAuthorizer(lifecycle Lifecycle, client kcpkubernetesclientset.ClusterInterface) authorizer.AuthorizerFunc {
 	return func(ctx context.Context, attr authorizer.Attributes) (authorizer.Decision, string, error) {
-		workspace, name, err := initialization.TypeFrom(tenancyv1alpha1.ClusterWorkspaceInitializer(dynamiccontext.APIDomainKeyFrom(ctx)))
+		workspace, name, err := initialization.TypeFrom(dynamiccontext.APIDomainKeyFrom(ctx))
 		if err != nil {
 			klog.V(2).Info(err)
-			return authorizer.DecisionNoOpinion, "unable to determine initializer", fmt.Errorf("access not permitted")
+			return authorizer.DecisionNoOpinion, fmt.Sprintf("unable to determine %s", lifecycle.Controller), fmt.Errorf("access not permitted")
 		}
 
 		authz, err := delegated.NewDelegatedAuthorizer(workspace, client)
@@ -421,7 +472,7 @@ func newAuthorizer(client kcpkubernetesclientset.ClusterInterface) authorizer.Au
 			APIGroup:        tenancyv1alpha1.SchemeGroupVersion.Group,
 			APIVersion:      tenancyv1alpha1.SchemeGroupVersion.Version,
 			User:            attr.GetUser(),
-			Verb:            "initialize",
+			Verb:            lifecycle.Verb,
 			Name:            name,
 			Resource:        "clusterworkspacetypes",
 			ResourceRequest: true,
@@ -36,20 +36,12 @@ import (
 	"k8s.io/klog/v2"
 	"k8s.io/kube-openapi/pkg/validation/validate"
 
-	"github.com/kcp-dev/kcp/pkg/apis/tenancy/initialization"
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
 	"github.com/kcp-dev/kcp/pkg/virtual/framework/dynamic/apiserver"
 	registry "github.com/kcp-dev/kcp/pkg/virtual/framework/forwardingregistry"
 )
 
-func initializingWorkspaceRequirements(initializer tenancyv1alpha1.ClusterWorkspaceInitializer) (labels.Requirements, error) {
-	labelSelector := map[string]string{
-		tenancyv1alpha1.ClusterWorkspacePhaseLabel: string(tenancyv1alpha1.ClusterWorkspacePhaseInitializing),
-	}
-
-	key, value := initialization.InitializerToLabel(initializer)
-	labelSelector[key] = value
-
+func labelRequirements(labelSelector map[string]string) (labels.Requirements, error) {
 	requirements, selectable := labels.SelectorFromSet(labelSelector).Requirements()
 	if !selectable {
 		return nil, fmt.Errorf("unable to create a selector from the provided labels")
@@ -58,13 +50,13 @@ func initializingWorkspaceRequirements(initializer tenancyv1alpha1.ClusterWorksp
 	return requirements, nil
 }
 
-func provideFilteredClusterWorkspacesReadOnlyRestStorage(getTenancyIdentity func() (string, error)) func(
+func provideFilteredClusterWorkspacesReadOnlyRestStorage(lifecycle Lifecycle, getTenancyIdentity func() (string, error)) func(
 	ctx context.Context,
 	clusterClient kcpdynamic.ClusterInterface,
-	initializer tenancyv1alpha1.ClusterWorkspaceInitializer,
+	controller string,
 ) (apiserver.RestProviderFunc, error) {
-	return func(ctx context.Context, clusterClient kcpdynamic.ClusterInterface, initializer tenancyv1alpha1.ClusterWorkspaceInitializer) (apiserver.RestProviderFunc, error) {
-		requirements, err := initializingWorkspaceRequirements(initializer)
+	return func(ctx context.Context, clusterClient kcpdynamic.ClusterInterface, controller string) (apiserver.RestProviderFunc, error) {
+		requirements, err := lifecycle.Requirements(controller)
 		if err != nil {
 			return nil, err
 		}
@@ -87,18 +79,18 @@ func provideFilteredClusterWorkspacesReadOnlyRestStorage(getTenancyIdentity func
 	}
 }
 
-func provideDelegatingClusterWorkspacesRestStorage(getTenancyIdentity func() (string, error)) func(
+func provideDelegatingClusterWorkspacesRestStorage(lifecycle Lifecycle, getTenancyIdentity func() (string, error)) func(
 	ctx context.Context,
 	clusterClient kcpdynamic.ClusterInterface,
-	initializer tenancyv1alpha1.ClusterWorkspaceInitializer,
+	controller string,
 ) (apiserver.RestProviderFunc, error) {
-	return func(ctx context.Context, clusterClient kcpdynamic.ClusterInterface, initializer tenancyv1alpha1.ClusterWorkspaceInitializer) (apiserver.RestProviderFunc, error) {
+	return func(ctx context.Context, clusterClient kcpdynamic.ClusterInterface, controller string) (apiserver.RestProviderFunc, error) {
 		identity, err := getTenancyIdentity()
 		if err != nil {
 			return nil, err
 		}
 
-		requirements, err := initializingWorkspaceRequirements(initializer)
+		requirements, err := lifecycle.Requirements(controller)
 		if err != nil {
 			return nil, err
 		}
@@ -148,7 +140,7 @@ func provideDelegatingClusterWorkspacesRestStorage(getTenancyIdentity func() (st
 				nil,
 				&registry.StorageWrappers{
 					registry.WithStaticLabelSelector(requirements),
-					withUpdateValidation(initializer),
+					withUpdateValidation(lifecycle, controller),
 				},
 			)
 
@@ -206,36 +198,36 @@ func provideDelegatingClusterWorkspacesRestStorage(getTenancyIdentity func() (st
 }
 
 // withUpdateValidation adds further validation to ensure that a user of this virtual workspace can only
-// remove their own initializer from the list
-func withUpdateValidation(initializer tenancyv1alpha1.ClusterWorkspaceInitializer) registry.StorageWrapper {
+// remove their own initializer or finalizer from the list
+func withUpdateValidation(lifecycle Lifecycle, controller string) registry.StorageWrapper {
 	return registry.StorageWrapperFunc(func(resource schema.GroupResource, storage *registry.StoreFuncs) {
 		delegateUpdater := storage.UpdaterFunc
 		storage.UpdaterFunc = func(ctx context.Context, name string, objInfo rest.UpdatedObjectInfo, createValidation rest.ValidateObjectFunc, updateValidation rest.ValidateObjectUpdateFunc, forceAllowCreate bool, options *metav1.UpdateOptions) (runtime.Object, bool, error) {
 			validation := rest.ValidateObjectUpdateFunc(func(ctx context.Context, obj, old runtime.Object) error {
 				logger := klog.FromContext(ctx)
-				previous, _, err := unstructured.NestedStringSlice(old.(*unstructured.Unstructured).UnstructuredContent(), "status", "initializers")
+				previous, _, err := unstructured.NestedStringSlice(old.(*unstructured.Unstructured).UnstructuredContent(), "status", lifecycle.StatusField)
 				if err != nil {
-					return errors.NewInternalError(fmt.Errorf("error accessing initializers from old object: %w", err))
+					return errors.NewInternalError(fmt.Errorf("error accessing %s from old object: %w", lifecycle.StatusField, err))
 				}
-				current, _, err := unstructured.NestedStringSlice(obj.(*unstructured.Unstructured).UnstructuredContent(), "status", "initializers")
+				current, _, err := unstructured.NestedStringSlice(obj.(*unstructured.Unstructured).UnstructuredContent(), "status", lifecycle.StatusField)
 				if err != nil {
-					logger.Error(err, "error accessing initializers from new object")
-					return errors.NewInternalError(fmt.Errorf("error accessing initializers from old object: %w", err))
+					logger.Error(err, "error accessing "+lifecycle.StatusField+" from new object")
+					return errors.NewInternalError(fmt.Errorf("error accessing %s from old object: %w", lifecycle.StatusField, err))
 				}
 				invalidUpdateErr := errors.NewInvalid(
 					tenancyv1alpha1.Kind("ClusterWorkspace"),
 					name,
 					field.ErrorList{field.Invalid(
-						field.NewPath("status", "initializers"),
+						field.NewPath("status", lifecycle.StatusField),
 						current,
-						fmt.Sprintf("only removing the %q initializer is supported", initializer),
+						fmt.Sprintf("only removing the %q %s is supported", controller, lifecycle.Controller),
 					)},
 				)
 				if len(previous)-len(current) != 1 {
 					return invalidUpdateErr
 				}
 				for _, item := range current {
-					if item == string(initializer) {
+					if item == controller {
 						return invalidUpdateErr
 					}
 				}
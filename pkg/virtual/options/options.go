@@ -29,6 +29,7 @@ import (
 	"github.com/kcp-dev/kcp/pkg/virtual/framework/rootapiserver"
 	initializingworkspacesoptions "github.com/kcp-dev/kcp/pkg/virtual/initializingworkspaces/options"
 	synceroptions "github.com/kcp-dev/kcp/pkg/virtual/syncer/options"
+	terminatingworkspacesoptions "github.com/kcp-dev/kcp/pkg/virtual/terminatingworkspaces/options"
 	workspacesoptions "github.com/kcp-dev/kcp/pkg/virtual/workspaces/options"
 )
 
@@ -39,6 +40,7 @@ type Options struct {
 	Syncer                 *synceroptions.Syncer
 	APIExport              *apiexportoptions.APIExport
 	InitializingWorkspaces *initializingworkspacesoptions.InitializingWorkspaces
+	TerminatingWorkspaces  *terminatingworkspacesoptions.TerminatingWorkspaces
 }
 
 func NewOptions() *Options {
@@ -47,6 +49,7 @@ func NewOptions() *Options {
 		Syncer:                 synceroptions.New(),
 		APIExport:              apiexportoptions.New(),
 		InitializingWorkspaces: initializingworkspacesoptions.New(),
+		TerminatingWorkspaces:  terminatingworkspacesoptions.New(),
 	}
 }
 
@@ -57,6 +60,7 @@ func (v *Options) Validate() []error {
 	errs = append(errs, v.Syncer.Validate(virtualWorkspacesFlagPrefix)...)
 	errs = append(errs, v.APIExport.Validate(virtualWorkspacesFlagPrefix)...)
 	errs = append(errs, v.InitializingWorkspaces.Validate(virtualWorkspacesFlagPrefix)...)
+	errs = append(errs, v.TerminatingWorkspaces.Validate(virtualWorkspacesFlagPrefix)...)
 
 	return errs
 }
@@ -64,6 +68,7 @@ func (v *Options) Validate() []error {
 func (v *Options) AddFlags(fs *pflag.FlagSet) {
 	v.Workspaces.AddFlags(fs, virtualWorkspacesFlagPrefix)
 	v.InitializingWorkspaces.AddFlags(fs, virtualWorkspacesFlagPrefix)
+	v.TerminatingWorkspaces.AddFlags(fs, virtualWorkspacesFlagPrefix)
 }
 
 func (o *Options) NewVirtualWorkspaces(
@@ -93,7 +98,12 @@ func (o *Options) NewVirtualWorkspaces(
 		return nil, err
 	}
 
-	all, err := merge(workspaces, syncer, apiexports, initializingworkspaces)
+	terminatingworkspaces, err := o.TerminatingWorkspaces.NewVirtualWorkspaces(rootPathPrefix, config, wildcardKcpInformers)
+	if err != nil {
+		return nil, err
+	}
+
+	all, err := merge(workspaces, syncer, apiexports, initializingworkspaces, terminatingworkspaces)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,71 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"fmt"
+	"path"
+
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	kcpkubernetesclientset "github.com/kcp-dev/client-go/kubernetes"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/rest"
+
+	"github.com/kcp-dev/kcp/pkg/apis/tenancy/finalization"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	kcpinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions"
+	"github.com/kcp-dev/kcp/pkg/virtual/framework/rootapiserver"
+	initializingworkspacesbuilder "github.com/kcp-dev/kcp/pkg/virtual/initializingworkspaces/builder"
+	"github.com/kcp-dev/kcp/pkg/virtual/terminatingworkspaces"
+)
+
+// terminating serves deleted workspaces to their finalizers. Workspaces are selected by the
+// finalizer label, which is only set while the workspace is being deleted.
+var terminating = initializingworkspacesbuilder.Lifecycle{
+	VirtualWorkspaceName: terminatingworkspaces.VirtualWorkspaceName,
+	Controller:           "finalizer",
+	StatusField:          "finalizers",
+	Verb:                 "finalize",
+	Requirements: func(finalizer string) (labels.Requirements, error) {
+		key, value := finalization.FinalizerToLabel(tenancyv1alpha1.ClusterWorkspaceFinalizer(finalizer))
+		requirements, selectable := labels.SelectorFromSet(labels.Set{key: value}).Requirements()
+		if !selectable {
+			return nil, fmt.Errorf("unable to create a selector from the provided labels")
+		}
+		return requirements, nil
+	},
+	Accessible: func(clusterWorkspace *tenancyv1alpha1.ClusterWorkspace, finalizer string) bool {
+		return !clusterWorkspace.DeletionTimestamp.IsZero() && finalization.FinalizerPresent(tenancyv1alpha1.ClusterWorkspaceFinalizer(finalizer), clusterWorkspace.Status.Finalizers)
+	},
+}
+
+func BuildVirtualWorkspace(
+	cfg *rest.Config,
+	rootPathPrefix string,
+	dynamicClusterClient kcpdynamic.ClusterInterface,
+	kubeClusterClient kcpkubernetesclientset.ClusterInterface,
+	wildcardKcpInformers kcpinformers.SharedInformerFactory,
+) ([]rootapiserver.NamedVirtualWorkspace, error) {
+	return initializingworkspacesbuilder.BuildLifecycleVirtualWorkspace(terminating, cfg, rootPathPrefix, dynamicClusterClient, kubeClusterClient, wildcardKcpInformers)
+}
+
+// URLFor returns the absolute path for the specified finalizer.
+func URLFor(finalizerName tenancyv1alpha1.ClusterWorkspaceFinalizer) string {
+	// TODO(ncdc): make /services hard-coded everywhere instead of configurable.
+	return path.Join("/services", terminatingworkspaces.VirtualWorkspaceName, string(finalizerName))
+}
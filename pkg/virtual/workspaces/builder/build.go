@@ -52,7 +52,7 @@ import (
 	"github.com/kcp-dev/kcp/pkg/virtual/workspaces/registry"
 )
 
-func BuildVirtualWorkspace(cfg *clientrest.Config, rootPathPrefix string, wildcardsClusterWorkspaces tenancyv1alpha1informers.ClusterWorkspaceClusterInformer, wildcardsRbacInformers kcprbacv1informers.ClusterInterface, kubeClusterClient kcpkubernetesclientset.ClusterInterface, kcpClusterClient kcpclientset.ClusterInterface, authorizationCacheResyncPeriod time.Duration, authorizationCacheResyncJitterFactor float64, authorizationCacheResyncSliding bool) framework.VirtualWorkspace {
+func BuildVirtualWorkspace(cfg *clientrest.Config, rootPathPrefix string, wildcardsClusterWorkspaces tenancyv1alpha1informers.ClusterWorkspaceClusterInformer, wildcardsRbacInformers kcprbacv1informers.ClusterInterface, kubeClusterClient kcpkubernetesclientset.ClusterInterface, kcpClusterClient kcpclientset.ClusterInterface, authorizationCacheResyncPeriod time.Duration, authorizationCacheResyncJitterFactor float64, authorizationCacheResyncSliding bool, deletionRetentionPeriod time.Duration) framework.VirtualWorkspace {
 	metrics.Register()
 	crbInformer := wildcardsRbacInformers.ClusterRoleBindings()
 
@@ -144,11 +144,15 @@ func BuildVirtualWorkspace(cfg *clientrest.Config, rootPathPrefix string, wildca
 						return nil, err
 					}
 
-					workspacesRest := registry.NewREST(kubeClusterClient, kcpClusterClient, globalClusterWorkspaceCache, crbInformer, orgListener.FilteredClusterWorkspaces)
+					workspacesRest := registry.NewREST(kubeClusterClient, kcpClusterClient, globalClusterWorkspaceCache, crbInformer, orgListener.FilteredClusterWorkspaces, deletionRetentionPeriod)
+					undeleteRest := registry.NewUndeleteREST(kubeClusterClient, kcpClusterClient)
 					return map[string]fixedgvs.RestStorageBuilder{
 						"workspaces": func(apiGroupAPIServerConfig genericapiserver.CompletedConfig) (rest.Storage, error) {
 							return workspacesRest, nil
 						},
+						"workspaces/undelete": func(apiGroupAPIServerConfig genericapiserver.CompletedConfig) (rest.Storage, error) {
+							return undeleteRest, nil
+						},
 					}, nil
 				},
 			},
@@ -184,7 +188,7 @@ func newAuthorizer(cfg *clientrest.Config) func(ctx context.Context, a authorize
 			return authorizer.DecisionNoOpinion, "", nil
 		}
 
-		// check for <verb> permission on the ClusterWorkspace workspace subresource for the <resourceName>
+		// check for <verb> permission on the workspaces resource, or its subresource, for the <resourceName>
 		clusterName := ctx.Value(registry.WorkspacesOrgKey).(logicalcluster.Name)
 		authz, err := delegated.NewDelegatedAuthorizer(clusterName, softlyImpersonatedSARClusterClient)
 		if err != nil {
@@ -197,6 +201,7 @@ func newAuthorizer(cfg *clientrest.Config) func(ctx context.Context, a authorize
 			APIGroup:        tenancyv1beta1.SchemeGroupVersion.Group,
 			APIVersion:      tenancyv1beta1.SchemeGroupVersion.Version,
 			Resource:        "workspaces",
+			Subresource:     a.GetSubresource(),
 			Name:            a.GetName(),
 			ResourceRequest: true,
 		}
@@ -35,6 +35,10 @@ import (
 
 type Workspaces struct {
 	AuthorizationCache AuthorizationCache
+
+	// DeletionRetentionPeriod is the default time deleted workspaces are retained
+	// before their content is deleted. ClusterWorkspaceTypes can override it.
+	DeletionRetentionPeriod time.Duration
 }
 
 // AuthorizationCache contains options for the authorization caches in the workspaces service.
@@ -62,6 +66,7 @@ func (o *Workspaces) AddFlags(flags *pflag.FlagSet, prefix string) {
 		return
 	}
 	o.AuthorizationCache.AddFlags(flags, prefix+workspacesPrefix+authorizationCachePrefix)
+	flags.DurationVar(&o.DeletionRetentionPeriod, prefix+workspacesPrefix+"deletion-retention-period", o.DeletionRetentionPeriod, "Default period deleted workspaces are retained in the Deleted phase, during which they can be undeleted. Zero disables the retention.")
 }
 
 func (o *AuthorizationCache) AddFlags(flags *pflag.FlagSet, prefix string) {
@@ -79,6 +84,9 @@ func (o *Workspaces) Validate(flagPrefix string) []error {
 	}
 	errs := []error{}
 	errs = append(errs, o.AuthorizationCache.Validate(flagPrefix+workspacesPrefix+authorizationCachePrefix)...)
+	if o.DeletionRetentionPeriod < 0 {
+		errs = append(errs, fmt.Errorf("--%sdeletion-retention-period cannot be negative", flagPrefix+workspacesPrefix))
+	}
 
 	return errs
 }
@@ -111,6 +119,6 @@ func (o *Workspaces) NewVirtualWorkspaces(
 	}
 
 	return []rootapiserver.NamedVirtualWorkspace{
-		{Name: "workspaces", VirtualWorkspace: builder.BuildVirtualWorkspace(config, path.Join(rootPathPrefix, "workspaces"), wildcardKcpInformers.Tenancy().V1alpha1().ClusterWorkspaces(), wildcardKubeInformers.Rbac().V1(), kubeClusterClient, kcpClusterClient, o.AuthorizationCache.Period, o.AuthorizationCache.JitterFactor, o.AuthorizationCache.Sliding)},
+		{Name: "workspaces", VirtualWorkspace: builder.BuildVirtualWorkspace(config, path.Join(rootPathPrefix, "workspaces"), wildcardKcpInformers.Tenancy().V1alpha1().ClusterWorkspaces(), wildcardKubeInformers.Rbac().V1(), kubeClusterClient, kcpClusterClient, o.AuthorizationCache.Period, o.AuthorizationCache.JitterFactor, o.AuthorizationCache.Sliding, o.DeletionRetentionPeriod)},
 	}, nil
 }
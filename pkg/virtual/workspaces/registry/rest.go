@@ -19,6 +19,7 @@ package registry
 import (
 	"context"
 	"fmt"
+	"time"
 
 	kcprbacv1informers "github.com/kcp-dev/client-go/informers/rbac/v1"
 	kcpkubernetesclientset "github.com/kcp-dev/client-go/kubernetes"
@@ -44,9 +45,11 @@ import (
 	clusterworkspaceadmission "github.com/kcp-dev/kcp/pkg/admission/clusterworkspace"
 	"github.com/kcp-dev/kcp/pkg/apis/tenancy/projection"
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1/helper"
 	tenancyv1beta1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1beta1"
 	"github.com/kcp-dev/kcp/pkg/authorization/delegated"
 	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/cluster"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/clusterworkspace"
 	workspaceauth "github.com/kcp-dev/kcp/pkg/virtual/workspaces/authorization"
 	workspacecache "github.com/kcp-dev/kcp/pkg/virtual/workspaces/cache"
 	workspaceprinters "github.com/kcp-dev/kcp/pkg/virtual/workspaces/printers"
@@ -68,6 +71,10 @@ const (
 	WorkspacesOrgKey WorkspacesScopeKeyType = "VirtualWorkspaceWorkspacesOrg"
 )
 
+// EventComponent is the source of the Events emitted for lifecycle transitions of workspaces
+// that are requested through the workspaces virtual workspace.
+const EventComponent = "kcp-workspaces-virtual-workspace"
+
 type REST struct {
 	// getFilteredClusterWorkspaces returns a provider for ClusterWorkspaces.
 	getFilteredClusterWorkspaces func(orgClusterName logicalcluster.Name) FilteredClusterWorkspaces
@@ -84,6 +91,10 @@ type REST struct {
 	// delegatedAuthz implements cluster-aware SubjectAccessReview
 	delegatedAuthz delegated.DelegatedAuthorizerFactory
 
+	// deletionRetentionPeriod is the server-wide default of how long deleted workspaces are
+	// retained in the Deleted phase. It is overridden by the ClusterWorkspaceType.
+	deletionRetentionPeriod time.Duration
+
 	createStrategy rest.RESTCreateStrategy
 	updateStrategy rest.RESTUpdateStrategy
 	rest.TableConvertor
@@ -103,6 +114,7 @@ func NewREST(
 	clusterWorkspaceCache *workspacecache.ClusterWorkspaceCache,
 	wildcardsCRBInformer kcprbacv1informers.ClusterRoleBindingClusterInformer,
 	getFilteredClusterWorkspaces func(orgClusterName logicalcluster.Name) FilteredClusterWorkspaces,
+	deletionRetentionPeriod time.Duration,
 ) *REST {
 	mainRest := &REST{
 		getFilteredClusterWorkspaces: getFilteredClusterWorkspaces,
@@ -111,6 +123,8 @@ func NewREST(
 		kcpClusterClient:  kcpClusterClient,
 		delegatedAuthz:    delegated.NewDelegatedAuthorizer,
 
+		deletionRetentionPeriod: deletionRetentionPeriod,
+
 		crbInformer: wildcardsCRBInformer,
 
 		clusterWorkspaceCache: clusterWorkspaceCache,
@@ -201,11 +215,17 @@ func (s *REST) List(ctx context.Context, options *metainternal.ListOptions) (run
 
 	workspaceList := &tenancyv1beta1.WorkspaceList{
 		ListMeta: clusterWorkspaceList.ListMeta,
-		Items:    make([]tenancyv1beta1.Workspace, len(clusterWorkspaceList.Items)),
+		Items:    make([]tenancyv1beta1.Workspace, 0, len(clusterWorkspaceList.Items)),
 	}
 
-	for i, cws := range clusterWorkspaceList.Items {
-		projection.ProjectClusterWorkspaceToWorkspace(&cws, &workspaceList.Items[i])
+	for i := range clusterWorkspaceList.Items {
+		// soft-deleted workspaces are hidden until they are undeleted
+		if clusterWorkspaceList.Items[i].Status.Phase == tenancyv1alpha1.ClusterWorkspacePhaseDeleted {
+			continue
+		}
+		var ws tenancyv1beta1.Workspace
+		projection.ProjectClusterWorkspaceToWorkspace(&clusterWorkspaceList.Items[i], &ws)
+		workspaceList.Items = append(workspaceList.Items, ws)
 	}
 
 	return workspaceList, nil
@@ -237,7 +257,9 @@ func (s *REST) Watch(ctx context.Context, options *metainternal.ListOptions) (wa
 
 	includeAllExistingProjects := (options != nil) && options.ResourceVersion == "0"
 
-	m := workspaceutil.MatchWorkspace(InternalListOptionsToSelectors(options))
+	labelSelector, fieldSelector := InternalListOptionsToSelectors(options)
+	fieldSelector = fields.AndSelectors(fieldSelector, fields.OneTermNotEqualSelector("status.phase", string(tenancyv1alpha1.ClusterWorkspacePhaseDeleted)))
+	m := workspaceutil.MatchWorkspace(labelSelector, fieldSelector)
 	watcher := workspaceauth.NewUserWorkspaceWatcher(userInfo, orgClusterName, s.clusterWorkspaceCache, clusterWorkspaces, includeAllExistingProjects, m)
 	clusterWorkspaces.AddWatcher(watcher)
 
@@ -269,6 +291,10 @@ var ownerRoleRules = []rbacv1.PolicyRule{
 		Verbs:     []string{"get", "delete"},
 		Resources: []string{"workspaces"},
 	},
+	{
+		Verbs:     []string{"create"},
+		Resources: []string{"workspaces/undelete"},
+	},
 	{
 		Resources: []string{"workspaces/content"},
 		Verbs:     []string{"admin", "access"},
@@ -302,7 +328,8 @@ var _ = rest.Creater(&REST{})
 // This will give the workspace creator the following permissions on the newly-created workspace:
 // - 'cluster-admin' inside the newly created workspace,
 // - 'get' permission to the workspace resource itself, so that it would appear when listing workspaces in the parent
-// - 'delete' permission so that the user can delete a workspace it has created.
+// - 'delete' permission so that the user can delete a workspace it has created,
+// - 'create' permission on the undelete subresource so that the user can restore it during the retention period.
 func (s *REST) Create(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
 	workspace, isWorkspace := obj.(*tenancyv1beta1.Workspace)
 	if !isWorkspace {
@@ -400,12 +427,34 @@ func (s *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 
 var _ = rest.GracefulDeleter(&REST{})
 
+// Delete deletes a workspace. If a deletion retention period applies to a ready workspace, the
+// workspace is only soft-deleted, i.e. it is moved into the Deleted phase and can be undeleted
+// until the retention period has passed. Deleting a soft-deleted workspace deletes it for good.
 func (s *REST) Delete(ctx context.Context, name string, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions) (runtime.Object, bool, error) {
 	orgClusterName := ctx.Value(WorkspacesOrgKey).(logicalcluster.Name)
 	logger := klog.FromContext(ctx).WithValues("parent", orgClusterName, "name", name)
 	ctx = klog.NewContext(ctx, logger)
 
-	err := s.kcpClusterClient.Cluster(orgClusterName).TenancyV1alpha1().ClusterWorkspaces().Delete(ctx, name, *options)
+	clusterWorkspaces := s.kcpClusterClient.Cluster(orgClusterName).TenancyV1alpha1().ClusterWorkspaces()
+	cws, err := clusterWorkspaces.Get(ctx, name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, false, kerrors.NewNotFound(tenancyv1beta1.Resource("workspaces"), name)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if cws.Status.Phase == tenancyv1alpha1.ClusterWorkspacePhaseReady {
+		retention, err := s.deletionRetentionPeriodOf(ctx, cws)
+		if err != nil {
+			return nil, false, err
+		}
+		if retention > 0 {
+			return s.softDelete(ctx, cws, retention, options)
+		}
+	}
+
+	err = clusterWorkspaces.Delete(ctx, name, *options)
 	if kerrors.IsNotFound(err) {
 		err = kerrors.NewNotFound(tenancyv1beta1.Resource("workspaces"), name)
 	}
@@ -413,6 +462,79 @@ func (s *REST) Delete(ctx context.Context, name string, deleteValidation rest.Va
 	return nil, false, err
 }
 
+// deletionRetentionPeriodOf returns the retention period of the ClusterWorkspaceType of the
+// workspace, or the server-wide default if the type does not specify one.
+func (s *REST) deletionRetentionPeriodOf(ctx context.Context, cws *tenancyv1alpha1.ClusterWorkspace) (time.Duration, error) {
+	if cws.Spec.Type.Path == "" || cws.Spec.Type.Name == "" {
+		return s.deletionRetentionPeriod, nil
+	}
+	cwt, err := s.kcpClusterClient.Cluster(logicalcluster.New(cws.Spec.Type.Path)).TenancyV1alpha1().ClusterWorkspaceTypes().Get(ctx, tenancyv1alpha1.ObjectName(cws.Spec.Type.Name), metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return s.deletionRetentionPeriod, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if cwt.Spec.DeletionRetentionPeriod != nil {
+		return cwt.Spec.DeletionRetentionPeriod.Duration, nil
+	}
+	return s.deletionRetentionPeriod, nil
+}
+
+// softDelete moves the workspace into the Deleted phase. The ClusterWorkspace controller deletes
+// it for good when the retention period has passed.
+func (s *REST) softDelete(ctx context.Context, cws *tenancyv1alpha1.ClusterWorkspace, retention time.Duration, options *metav1.DeleteOptions) (runtime.Object, bool, error) {
+	if cws.Status.Move != nil || cws.Status.Location.Migration != nil {
+		return nil, false, kerrors.NewConflict(tenancyv1beta1.Resource("workspaces"), cws.Name, fmt.Errorf("workspace is being moved or migrated"))
+	}
+	if preconditions := options.Preconditions; preconditions != nil {
+		if preconditions.UID != nil && *preconditions.UID != cws.UID {
+			return nil, false, kerrors.NewConflict(tenancyv1beta1.Resource("workspaces"), cws.Name, fmt.Errorf("precondition failed: UID in precondition: %v, UID in object meta: %v", *preconditions.UID, cws.UID))
+		}
+		if preconditions.ResourceVersion != nil {
+			cws.ResourceVersion = *preconditions.ResourceVersion
+		}
+	}
+
+	old := cws.DeepCopy()
+	now := metav1.Now()
+	cws.Status.Phase = tenancyv1alpha1.ClusterWorkspacePhaseDeleted
+	cws.Status.Deletion = &tenancyv1alpha1.ClusterWorkspaceDeletion{
+		DeletionTime: now,
+		RetainUntil:  metav1.NewTime(now.Add(retention)),
+	}
+	helper.AppendHistory(&cws.Status, tenancyv1alpha1.ClusterWorkspaceHistoryEntry{
+		Time:    now,
+		Phase:   tenancyv1alpha1.ClusterWorkspacePhaseDeleted,
+		Reason:  "SoftDeleted",
+		Message: fmt.Sprintf("Deleted by %s, retained until %s.", requestingUser(ctx), cws.Status.Deletion.RetainUntil.UTC().Format(time.RFC3339)),
+	})
+	updated, err := s.kcpClusterClient.Cluster(ctx.Value(WorkspacesOrgKey).(logicalcluster.Name)).TenancyV1alpha1().ClusterWorkspaces().UpdateStatus(ctx, cws, metav1.UpdateOptions{DryRun: options.DryRun})
+	if kerrors.IsNotFound(err) {
+		return nil, false, kerrors.NewNotFound(tenancyv1beta1.Resource("workspaces"), cws.Name)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	klog.FromContext(ctx).V(2).Info("soft-deleted workspace", "retainUntil", updated.Status.Deletion.RetainUntil)
+	if len(options.DryRun) == 0 {
+		clusterworkspace.RecordHistoryEvents(ctx, s.kubeClusterClient, EventComponent, old, updated)
+	}
+
+	var ws tenancyv1beta1.Workspace
+	projection.ProjectClusterWorkspaceToWorkspace(updated, &ws)
+	return &ws, false, nil
+}
+
+// requestingUser describes the user of the request for the history of a workspace.
+func requestingUser(ctx context.Context) string {
+	user, ok := apirequest.UserFrom(ctx)
+	if !ok || user.GetName() == "" {
+		return "an unknown user"
+	}
+	return fmt.Sprintf("user %q", user.GetName())
+}
+
 type withProjection struct {
 	delegate watch.Interface
 	ch       chan watch.Event
@@ -429,6 +551,13 @@ func (w withProjection) ResultChan() <-chan watch.Event {
 				continue
 			}
 			if cws, ok := ev.Object.(*tenancyv1alpha1.ClusterWorkspace); ok {
+				// soft-deleted workspaces are hidden, i.e. they disappear when they get into the Deleted phase
+				if cws.Status.Phase == tenancyv1alpha1.ClusterWorkspacePhaseDeleted {
+					if ev.Type != watch.Modified {
+						continue
+					}
+					ev.Type = watch.Deleted
+				}
 				ws := &tenancyv1beta1.Workspace{}
 				projection.ProjectClusterWorkspaceToWorkspace(cws, ws)
 				ev.Object = ws
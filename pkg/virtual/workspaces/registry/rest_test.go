@@ -22,6 +22,7 @@ import (
 	"math/rand"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	kcpkubernetesinformers "github.com/kcp-dev/client-go/informers"
@@ -32,6 +33,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	kcptesting "github.com/kcp-dev/client-go/third_party/k8s.io/client-go/testing"
+	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -70,15 +72,16 @@ func (m *mockLister) List(user kuser.Info, _ labels.Selector, _ fields.Selector)
 }
 
 type TestData struct {
-	clusterRoles           []rbacv1.ClusterRole
-	clusterRoleBindings    []rbacv1.ClusterRoleBinding
-	clusterWorkspaces      []tenancyv1alpha1.ClusterWorkspace
-	workspaceCreationError error
-	workspaceLister        *mockLister
-	user                   kuser.Info
-	reviewer               *workspaceauth.Reviewer
-	rootReviewer           *workspaceauth.Reviewer
-	orgName                logicalcluster.Name
+	clusterRoles            []rbacv1.ClusterRole
+	clusterRoleBindings     []rbacv1.ClusterRoleBinding
+	clusterWorkspaces       []tenancyv1alpha1.ClusterWorkspace
+	workspaceCreationError  error
+	workspaceLister         *mockLister
+	user                    kuser.Info
+	reviewer                *workspaceauth.Reviewer
+	rootReviewer            *workspaceauth.Reviewer
+	orgName                 logicalcluster.Name
+	deletionRetentionPeriod time.Duration
 }
 
 type TestDescription struct {
@@ -211,6 +214,7 @@ func applyTest(t *testing.T, test TestDescription) {
 			}
 			return test.reviewer, nil
 		},
+		deletionRetentionPeriod: test.deletionRetentionPeriod,
 	}
 	ctx = apirequest.WithUser(ctx, test.user)
 	ctx = apirequest.WithValue(ctx, WorkspacesOrgKey, test.orgName)
@@ -610,6 +614,12 @@ func TestCreateWorkspace(t *testing.T) {
 							Resources:     []string{"workspaces"},
 							APIGroups:     []string{"tenancy.kcp.dev"},
 						},
+						{
+							Verbs:         []string{"create"},
+							ResourceNames: []string{"foo"},
+							Resources:     []string{"workspaces/undelete"},
+							APIGroups:     []string{"tenancy.kcp.dev"},
+						},
 						{
 							Verbs:         []string{"admin", "access"},
 							ResourceNames: []string{"foo"},
@@ -697,6 +707,12 @@ func TestCreateWorkspaceWithCreateAnyPermission(t *testing.T) {
 							Resources:     []string{"workspaces"},
 							APIGroups:     []string{"tenancy.kcp.dev"},
 						},
+						{
+							Verbs:         []string{"create"},
+							ResourceNames: []string{"foo"},
+							Resources:     []string{"workspaces/undelete"},
+							APIGroups:     []string{"tenancy.kcp.dev"},
+						},
 						{
 							Verbs:         []string{"admin", "access"},
 							ResourceNames: []string{"foo"},
@@ -793,6 +809,12 @@ func TestCreateWorkspaceCustomLocalType(t *testing.T) {
 							Resources:     []string{"workspaces"},
 							APIGroups:     []string{"tenancy.kcp.dev"},
 						},
+						{
+							Verbs:         []string{"create"},
+							ResourceNames: []string{"foo"},
+							Resources:     []string{"workspaces/undelete"},
+							APIGroups:     []string{"tenancy.kcp.dev"},
+						},
 						{
 							Verbs:         []string{"admin", "access"},
 							ResourceNames: []string{"foo"},
@@ -1157,6 +1179,146 @@ func TestDeleteWorkspace(t *testing.T) {
 	applyTest(t, test)
 }
 
+func TestSoftDeleteAndUndeleteWorkspace(t *testing.T) {
+	user := &kuser.DefaultInfo{
+		Name:   "test-user",
+		UID:    "test-uid",
+		Groups: []string{"test-group"},
+	}
+	test := TestDescription{
+		TestData: TestData{
+			user:                    user,
+			orgName:                 logicalcluster.New("root:orgName"),
+			reviewer:                workspaceauth.NewReviewer(nil),
+			rootReviewer:            workspaceauth.NewReviewer(nil),
+			deletionRetentionPeriod: time.Hour,
+			clusterWorkspaces: []tenancyv1alpha1.ClusterWorkspace{
+				readyClusterWorkspace("foo"),
+			},
+		},
+		apply: func(t *testing.T, storage *REST, ctx context.Context, kubeClient *kcpfake.ClusterClientset, kcpClient *kcpfakeclient.ClusterClientset, listerCheckedUsers func() []kuser.Info, testData TestData) {
+			clusterWorkspaces := kcpClient.Cluster(testData.orgName).TenancyV1alpha1().ClusterWorkspaces()
+
+			response, deletedNow, err := storage.Delete(ctx, "foo", nil, &metav1.DeleteOptions{})
+			require.NoError(t, err)
+			assert.False(t, deletedNow)
+			assert.Equal(t, tenancyv1alpha1.ClusterWorkspacePhaseDeleted, response.(*tenancyv1beta1.Workspace).Status.Phase)
+			cws, err := clusterWorkspaces.Get(ctx, "foo", metav1.GetOptions{})
+			require.NoError(t, err)
+			require.Equal(t, tenancyv1alpha1.ClusterWorkspacePhaseDeleted, cws.Status.Phase)
+			require.NotNil(t, cws.Status.Deletion)
+			assert.Equal(t, time.Hour, cws.Status.Deletion.RetainUntil.Sub(cws.Status.Deletion.DeletionTime.Time))
+			require.Len(t, cws.Status.History, 1)
+			assert.Equal(t, "SoftDeleted", cws.Status.History[0].Reason)
+			assert.Contains(t, cws.Status.History[0].Message, `Deleted by user "test-user", retained until `)
+
+			response, err = NewUndeleteREST(kubeClient, kcpClient).Create(ctx, "foo", &tenancyv1beta1.Workspace{}, nil, &metav1.CreateOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, tenancyv1alpha1.ClusterWorkspacePhaseReady, response.(*tenancyv1beta1.Workspace).Status.Phase)
+			cws, err = clusterWorkspaces.Get(ctx, "foo", metav1.GetOptions{})
+			require.NoError(t, err)
+			require.Equal(t, tenancyv1alpha1.ClusterWorkspacePhaseReady, cws.Status.Phase)
+			require.Nil(t, cws.Status.Deletion)
+			require.Len(t, cws.Status.History, 2)
+			assert.Equal(t, "Undeleted", cws.Status.History[1].Reason)
+			assert.Equal(t, `Undeleted by user "test-user".`, cws.Status.History[1].Message)
+
+			eventList, err := kubeClient.Tracker().List(corev1.SchemeGroupVersion.WithResource("events"), corev1.SchemeGroupVersion.WithKind("Event"), metav1.NamespaceDefault)
+			require.NoError(t, err)
+			var reasons []string
+			for _, event := range eventList.(*corev1.EventList).Items {
+				reasons = append(reasons, event.Reason)
+			}
+			assert.ElementsMatch(t, []string{"SoftDeleted", "Undeleted"}, reasons)
+
+			_, err = NewUndeleteREST(kubeClient, kcpClient).Create(ctx, "foo", &tenancyv1beta1.Workspace{}, nil, &metav1.CreateOptions{})
+			require.True(t, errors.IsBadRequest(err), "expected bad request, got %v", err)
+
+			// deleting a soft-deleted workspace deletes it for good
+			_, _, err = storage.Delete(ctx, "foo", nil, &metav1.DeleteOptions{})
+			require.NoError(t, err)
+			_, _, err = storage.Delete(ctx, "foo", nil, &metav1.DeleteOptions{})
+			require.NoError(t, err)
+			_, err = clusterWorkspaces.Get(ctx, "foo", metav1.GetOptions{})
+			require.True(t, errors.IsNotFound(err), "expected not found, got %v", err)
+		},
+	}
+	applyTest(t, test)
+}
+
+func TestDeleteWorkspaceWithoutRetention(t *testing.T) {
+	user := &kuser.DefaultInfo{
+		Name:   "test-user",
+		UID:    "test-uid",
+		Groups: []string{"test-group"},
+	}
+	test := TestDescription{
+		TestData: TestData{
+			user:         user,
+			orgName:      logicalcluster.New("root:orgName"),
+			reviewer:     workspaceauth.NewReviewer(nil),
+			rootReviewer: workspaceauth.NewReviewer(nil),
+			clusterWorkspaces: []tenancyv1alpha1.ClusterWorkspace{
+				readyClusterWorkspace("foo"),
+			},
+		},
+		apply: func(t *testing.T, storage *REST, ctx context.Context, kubeClient *kcpfake.ClusterClientset, kcpClient *kcpfakeclient.ClusterClientset, listerCheckedUsers func() []kuser.Info, testData TestData) {
+			_, _, err := storage.Delete(ctx, "foo", nil, &metav1.DeleteOptions{})
+			require.NoError(t, err)
+			_, err = kcpClient.Cluster(testData.orgName).TenancyV1alpha1().ClusterWorkspaces().Get(ctx, "foo", metav1.GetOptions{})
+			require.True(t, errors.IsNotFound(err), "expected not found, got %v", err)
+		},
+	}
+	applyTest(t, test)
+}
+
+func TestListWorkspacesHidesDeleted(t *testing.T) {
+	user := &kuser.DefaultInfo{
+		Name:   "test-user",
+		UID:    "test-uid",
+		Groups: []string{},
+	}
+	deleted := readyClusterWorkspace("bar")
+	deleted.Status.Phase = tenancyv1alpha1.ClusterWorkspacePhaseDeleted
+	deleted.Status.Deletion = &tenancyv1alpha1.ClusterWorkspaceDeletion{}
+	test := TestDescription{
+		TestData: TestData{
+			user:         user,
+			orgName:      logicalcluster.New("root:orgName"),
+			reviewer:     workspaceauth.NewReviewer(nil),
+			rootReviewer: workspaceauth.NewReviewer(nil),
+			clusterWorkspaces: []tenancyv1alpha1.ClusterWorkspace{
+				readyClusterWorkspace("foo"),
+				deleted,
+			},
+		},
+		apply: func(t *testing.T, storage *REST, ctx context.Context, kubeClient *kcpfake.ClusterClientset, kcpClient *kcpfakeclient.ClusterClientset, listerCheckedUsers func() []kuser.Info, testData TestData) {
+			response, err := storage.List(ctx, nil)
+			require.NoError(t, err)
+			workspaces := response.(*tenancyv1beta1.WorkspaceList)
+			require.Len(t, workspaces.Items, 1, "workspaces.Items should have len 1")
+			assert.Equal(t, "foo", workspaces.Items[0].Name)
+		},
+	}
+	applyTest(t, test)
+}
+
+func readyClusterWorkspace(name string) tenancyv1alpha1.ClusterWorkspace {
+	return tenancyv1alpha1.ClusterWorkspace{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				logicalcluster.AnnotationKey: "root:orgName",
+			},
+			Name: name,
+		},
+		Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+			Phase:    tenancyv1alpha1.ClusterWorkspacePhaseReady,
+			Location: tenancyv1alpha1.ClusterWorkspaceLocation{Current: "root"},
+			BaseURL:  "https://kcp.bigcorp.com/clusters/root:orgName:" + name,
+		},
+	}
+}
+
 type clusterWorkspaces struct {
 	clusterWorkspaceLister *mockLister
 }
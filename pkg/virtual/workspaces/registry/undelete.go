@@ -0,0 +1,110 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"fmt"
+
+	kcpkubernetesclientset "github.com/kcp-dev/client-go/kubernetes"
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/kcp/pkg/apis/tenancy/projection"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1/helper"
+	tenancyv1beta1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1beta1"
+	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/cluster"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/clusterworkspace"
+)
+
+// UndeleteREST implements the undelete subresource of workspaces. Creating it restores a
+// soft-deleted workspace into the Ready phase.
+type UndeleteREST struct {
+	kubeClusterClient kcpkubernetesclientset.ClusterInterface
+	kcpClusterClient  kcpclientset.ClusterInterface
+}
+
+var _ rest.NamedCreater = &UndeleteREST{}
+
+// NewUndeleteREST returns a RESTStorage object for the undelete subresource of workspaces.
+func NewUndeleteREST(kubeClusterClient kcpkubernetesclientset.ClusterInterface, kcpClusterClient kcpclientset.ClusterInterface) *UndeleteREST {
+	return &UndeleteREST{
+		kubeClusterClient: kubeClusterClient,
+		kcpClusterClient:  kcpClusterClient,
+	}
+}
+
+// New returns a new Workspace
+func (r *UndeleteREST) New() runtime.Object {
+	return &tenancyv1beta1.Workspace{}
+}
+
+// Destroy implements rest.Storage
+func (r *UndeleteREST) Destroy() {
+	// Do nothing
+}
+
+// Create undeletes the named workspace if it is in the Deleted phase. The request body is ignored.
+func (r *UndeleteREST) Create(ctx context.Context, name string, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	orgClusterName := ctx.Value(WorkspacesOrgKey).(logicalcluster.Name)
+	clusterWorkspaces := r.kcpClusterClient.Cluster(orgClusterName).TenancyV1alpha1().ClusterWorkspaces()
+
+	cws, err := clusterWorkspaces.Get(ctx, name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, kerrors.NewNotFound(tenancyv1beta1.Resource("workspaces"), name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cws.Status.Phase != tenancyv1alpha1.ClusterWorkspacePhaseDeleted {
+		return nil, kerrors.NewBadRequest(fmt.Sprintf("workspace %q is not deleted", name))
+	}
+	if !cws.DeletionTimestamp.IsZero() {
+		return nil, kerrors.NewConflict(tenancyv1beta1.Resource("workspaces"), name, fmt.Errorf("the retention period of workspace %q has passed", name))
+	}
+
+	old := cws.DeepCopy()
+	cws.Status.Phase = tenancyv1alpha1.ClusterWorkspacePhaseReady
+	cws.Status.Deletion = nil
+	helper.AppendHistory(&cws.Status, tenancyv1alpha1.ClusterWorkspaceHistoryEntry{
+		Time:    metav1.Now(),
+		Phase:   tenancyv1alpha1.ClusterWorkspacePhaseReady,
+		Reason:  "Undeleted",
+		Message: fmt.Sprintf("Undeleted by %s.", requestingUser(ctx)),
+	})
+	updated, err := clusterWorkspaces.UpdateStatus(ctx, cws, metav1.UpdateOptions{DryRun: options.DryRun})
+	if kerrors.IsNotFound(err) {
+		return nil, kerrors.NewNotFound(tenancyv1beta1.Resource("workspaces"), name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	klog.FromContext(ctx).V(2).Info("undeleted workspace", "parent", orgClusterName, "name", name)
+	if len(options.DryRun) == 0 {
+		clusterworkspace.RecordHistoryEvents(ctx, r.kubeClusterClient, EventComponent, old, updated)
+	}
+
+	var ws tenancyv1beta1.Workspace
+	projection.ProjectClusterWorkspaceToWorkspace(updated, &ws)
+	return &ws, nil
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package workspacecontent selects and sanitizes the objects of a workspace which are
+// copied to another workspace, e.g. by a backup and restore, or by cloning.
+package workspacecontent
+
+import (
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/reconciler/apis/apiexport"
+)
+
+// SkippedResources are not copied because they are ephemeral, are projections of other
+// resources, or are maintained by controllers.
+var SkippedResources = map[schema.GroupResource]bool{
+	{Resource: "events"}:                                               true,
+	{Group: "events.k8s.io", Resource: "events"}:                       true,
+	{Group: "tenancy.kcp.dev", Resource: "workspaces"}:                 true,
+	{Group: "apiresource.kcp.dev", Resource: "apiresourceimports"}:     true,
+	{Group: "apiresource.kcp.dev", Resource: "negotiatedapiresources"}: true,
+	{Group: "coordination.k8s.io", Resource: "leases"}:                 true,
+}
+
+// SkipObject returns true for objects maintained by the system, or which must not be
+// shared between workspaces.
+func SkipObject(obj *unstructured.Unstructured) bool {
+	switch obj.GroupVersionKind().GroupKind() {
+	case schema.GroupKind{Kind: "ConfigMap"}:
+		return obj.GetName() == "kube-root-ca.crt"
+	case schema.GroupKind{Kind: "Secret"}:
+		// APIExport identities are secret to the workspace owning the export.
+		if obj.GetNamespace() == apiexport.DefaultIdentitySecretNamespace {
+			return true
+		}
+		t, _, _ := unstructured.NestedString(obj.Object, "type")
+		return t == string(corev1.SecretTypeServiceAccountToken)
+	}
+	return false
+}
+
+// Sanitize removes the metadata populated by the server, which is invalid or meaningless
+// in another workspace. APIExports lose their identity, such that a copy gets its own.
+func Sanitize(obj *unstructured.Unstructured) {
+	for _, field := range []string{"uid", "resourceVersion", "generation", "creationTimestamp", "deletionTimestamp", "deletionGracePeriodSeconds", "managedFields", "selfLink", "ownerReferences"} {
+		unstructured.RemoveNestedField(obj.Object, "metadata", field)
+	}
+	annotations := obj.GetAnnotations()
+	delete(annotations, logicalcluster.AnnotationKey)
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	obj.SetAnnotations(annotations)
+
+	if obj.GroupVersionKind().GroupKind() == apisv1alpha1.Kind("APIExport") {
+		unstructured.RemoveNestedField(obj.Object, "spec", "identity")
+	}
+}
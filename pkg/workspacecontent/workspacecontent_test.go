@@ -0,0 +1,122 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacecontent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSkipObject(t *testing.T) {
+	tests := map[string]struct {
+		obj  map[string]interface{}
+		want bool
+	}{
+		"root CA config map": {
+			obj:  map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "kube-root-ca.crt"}},
+			want: true,
+		},
+		"other config map": {
+			obj: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "foo"}},
+		},
+		"service account token": {
+			obj:  map[string]interface{}{"apiVersion": "v1", "kind": "Secret", "metadata": map[string]interface{}{"name": "default-token-abc"}, "type": "kubernetes.io/service-account-token"},
+			want: true,
+		},
+		"APIExport identity": {
+			obj:  map[string]interface{}{"apiVersion": "v1", "kind": "Secret", "metadata": map[string]interface{}{"name": "my-export", "namespace": "kcp-system"}, "type": "Opaque"},
+			want: true,
+		},
+		"opaque secret": {
+			obj: map[string]interface{}{"apiVersion": "v1", "kind": "Secret", "metadata": map[string]interface{}{"name": "foo", "namespace": "default"}, "type": "Opaque"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tt.want, SkipObject(&unstructured.Unstructured{Object: tt.obj}))
+		})
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	tests := map[string]struct {
+		obj  map[string]interface{}
+		want map[string]interface{}
+	}{
+		"config map": {
+			obj: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]interface{}{
+					"name":              "foo",
+					"namespace":         "default",
+					"uid":               "123",
+					"resourceVersion":   "42",
+					"creationTimestamp": "2022-12-01T00:00:00Z",
+					"managedFields":     []interface{}{map[string]interface{}{"manager": "kubectl"}},
+					"ownerReferences":   []interface{}{map[string]interface{}{"uid": "456"}},
+					"labels":            map[string]interface{}{"a": "b"},
+					"annotations": map[string]interface{}{
+						"kcp.dev/cluster": "root:org:ws",
+					},
+				},
+				"data": map[string]interface{}{"key": "value"},
+			},
+			want: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]interface{}{
+					"name":      "foo",
+					"namespace": "default",
+					"labels":    map[string]interface{}{"a": "b"},
+				},
+				"data": map[string]interface{}{"key": "value"},
+			},
+		},
+		"APIExport": {
+			obj: map[string]interface{}{
+				"apiVersion": "apis.kcp.dev/v1alpha1",
+				"kind":       "APIExport",
+				"metadata": map[string]interface{}{
+					"name":            "my-export",
+					"uid":             "1234",
+					"resourceVersion": "42",
+					"annotations": map[string]interface{}{
+						"kcp.dev/cluster": "root:org:template",
+					},
+				},
+				"spec": map[string]interface{}{"identity": map[string]interface{}{"secretRef": map[string]interface{}{"name": "my-export"}}},
+			},
+			want: map[string]interface{}{
+				"apiVersion": "apis.kcp.dev/v1alpha1",
+				"kind":       "APIExport",
+				"metadata":   map[string]interface{}{"name": "my-export"},
+				"spec":       map[string]interface{}{},
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: tt.obj}
+			Sanitize(obj)
+			require.Equal(t, tt.want, obj.Object)
+		})
+	}
+}
@@ -845,6 +845,9 @@ func NewFakeWorkloadServer(t *testing.T, server RunningServer, org logicalcluste
 	require.NoError(t, err)
 	kubefixtures.Create(t, crdClient.ApiextensionsV1().CustomResourceDefinitions(),
 		metav1.GroupResource{Group: "apps.k8s.io", Resource: "deployments"},
+		metav1.GroupResource{Group: "apps.k8s.io", Resource: "statefulsets"},
+		metav1.GroupResource{Group: "apps.k8s.io", Resource: "daemonsets"},
+		metav1.GroupResource{Group: "batch.k8s.io", Resource: "jobs"},
 		metav1.GroupResource{Group: "core.k8s.io", Resource: "services"},
 		metav1.GroupResource{Group: "core.k8s.io", Resource: "endpoints"},
 		metav1.GroupResource{Group: "networking.k8s.io", Resource: "ingresses"},
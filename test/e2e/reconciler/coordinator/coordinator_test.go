@@ -0,0 +1,233 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coordinator
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"testing"
+	"time"
+
+	kcpdynamic "github.com/kcp-dev/client-go/dynamic"
+	kcpkubernetesclientset "github.com/kcp-dev/client-go/kubernetes"
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	kcpapiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/kcp/clientset/versioned"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/restmapper"
+
+	"github.com/kcp-dev/kcp/config/helpers"
+	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/cluster"
+	"github.com/kcp-dev/kcp/test/e2e/framework"
+	"github.com/kcp-dev/kcp/test/e2e/reconciler/coordinator/workloads"
+	"github.com/kcp-dev/kcp/test/e2e/reconciler/deployment/locations"
+)
+
+// TestWorkloadCoordinator checks that the StatefulSet, DaemonSet and Job strategies of the coordinator
+// split the workloads across two SyncTargets, and sum up their downstream statuses upstream.
+func TestWorkloadCoordinator(t *testing.T) {
+	t.Parallel()
+	framework.Suite(t, "transparent-multi-cluster:requires-kind")
+
+	if len(framework.TestConfig.PClusterKubeconfig()) == 0 {
+		t.Skip("Test requires a pcluster")
+	}
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	t.Cleanup(cancelFunc)
+
+	upstreamServer := framework.SharedKcpServer(t)
+
+	upstreamConfig := upstreamServer.BaseConfig(t)
+	upstreamKubeClusterClient, err := kcpkubernetesclientset.NewForConfig(upstreamConfig)
+	require.NoError(t, err)
+
+	kcpClusterClient, err := kcpclientset.NewForConfig(upstreamConfig)
+	require.NoError(t, err)
+
+	dynamicClusterClient, err := kcpdynamic.NewForConfig(upstreamConfig)
+	require.NoError(t, err)
+
+	apiextensionsClusterClient, err := kcpapiextensionsclientset.NewForConfig(upstreamConfig)
+	require.NoError(t, err)
+
+	createResources := func(clusterName logicalcluster.Name, fs embed.FS) error {
+		mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(apiextensionsClusterClient.Cluster(clusterName).Discovery()))
+		return helpers.CreateResourcesFromFS(ctx, dynamicClusterClient.Cluster(clusterName), mapper, sets.NewString(), fs)
+	}
+
+	orgWorkspace := framework.NewOrganizationFixture(t, upstreamServer)
+	locationWorkspace := framework.NewWorkspaceFixture(t, upstreamServer, orgWorkspace, framework.WithName("synctargets"))
+	workloadWorkspace := framework.NewWorkspaceFixture(t, upstreamServer, orgWorkspace, framework.WithName("workload"))
+
+	eastSyncer := framework.NewSyncerFixture(t, upstreamServer, locationWorkspace,
+		framework.WithSyncTargetName("east"),
+		framework.WithSyncedUserWorkspaces(workloadWorkspace),
+	).Start(t)
+
+	_, err = kcpClusterClient.Cluster(locationWorkspace).WorkloadV1alpha1().SyncTargets().Patch(ctx, "east", types.JSONPatchType, []byte(`[{"op":"add","path":"/metadata/labels/region","value":"east"}]`), metav1.PatchOptions{})
+	require.NoError(t, err)
+
+	westSyncer := framework.NewSyncerFixture(t, upstreamServer, locationWorkspace,
+		framework.WithSyncTargetName("west"),
+		framework.WithSyncedUserWorkspaces(workloadWorkspace),
+	).Start(t)
+
+	_, err = kcpClusterClient.Cluster(locationWorkspace).WorkloadV1alpha1().SyncTargets().Patch(ctx, "west", types.JSONPatchType, []byte(`[{"op":"add","path":"/metadata/labels/region","value":"west"}]`), metav1.PatchOptions{})
+	require.NoError(t, err)
+
+	eastSyncer.WaitForClusterReady(t, ctx)
+	westSyncer.WaitForClusterReady(t, ctx)
+
+	t.Logf("Create 2 locations, one for each SyncTargets")
+	err = createResources(locationWorkspace, locations.FS)
+	require.NoError(t, err)
+
+	for _, region := range []string{"east", "west"} {
+		t.Logf("Bind the workload workspace to location workspace for the %s location", region)
+		framework.NewBindCompute(t, workloadWorkspace, upstreamServer,
+			framework.WithLocationWorkspaceWorkloadBindOption(locationWorkspace),
+			framework.WithLocationSelectorWorkloadBindOption(metav1.LabelSelector{
+				MatchLabels: map[string]string{"region": region},
+			}),
+		).Bind(t)
+	}
+
+	t.Logf("Get the root compute APIExport Virtual Workspace URL")
+	rootKubernetesAPIExport, err := kcpClusterClient.Cluster(logicalcluster.New("root:compute")).ApisV1alpha1().APIExports().Get(ctx, "kubernetes", metav1.GetOptions{})
+	require.NoError(t, err, "failed to retrieve Root compute kubernetes APIExport")
+	require.GreaterOrEqual(t, len(rootKubernetesAPIExport.Status.VirtualWorkspaces), 1, "Root compute kubernetes APIExport should contain at least one virtual workspace URL")
+	rootComputeKubernetesURL := rootKubernetesAPIExport.Status.VirtualWorkspaces[0].URL
+
+	t.Logf("Start the coordinator for StatefulSets, DaemonSets and Jobs")
+	artifactDir, _, err := framework.ScratchDirs(t)
+	require.NoError(t, err)
+
+	executableName := "deployment-coordinator"
+	cmd := append(framework.DirectOrGoRunCommand(executableName),
+		"--kubeconfig="+upstreamServer.KubeconfigPath(),
+		"--context=base",
+		"--server="+rootComputeKubernetesURL,
+		"--resources=statefulsets.apps,daemonsets.apps,jobs.batch",
+	)
+	coordinator := framework.NewAccessory(t, artifactDir, executableName, cmd...)
+	err = coordinator.Run(t, framework.WithLogStreaming)
+	require.NoError(t, err, "failed to start the coordinator")
+
+	t.Logf("Create a StatefulSet, a DaemonSet and a Job in workload workspace %q", workloadWorkspace)
+	framework.Eventually(t, func() (bool, string) {
+		if err := createResources(workloadWorkspace, workloads.FS); err != nil {
+			return false, err.Error()
+		}
+		return true, ""
+	}, wait.ForeverTestTimeout, time.Millisecond*100, "should create the workloads after their resources are available in workspace %q", workloadWorkspace)
+
+	// The east and west SyncTargets are synced to namespaces of the same downstream cluster.
+	downstreamKubeClient, err := kubernetes.NewForConfig(eastSyncer.DownstreamConfig)
+	require.NoError(t, err)
+	downstreamNamespaces := map[string]string{
+		"east": eastSyncer.DownstreamNamespaceFor(t, workloadWorkspace, "default"),
+		"west": westSyncer.DownstreamNamespaceFor(t, workloadWorkspace, "default"),
+	}
+	specDiffAnnotations := []string{
+		"experimental.spec-diff.workload.kcp.dev/" + eastSyncer.ToSyncTargetKey(),
+		"experimental.spec-diff.workload.kcp.dev/" + westSyncer.ToSyncTargetKey(),
+	}
+
+	t.Logf("Wait for the StatefulSet to be split across the SyncTargets and ready with 4 replicas")
+	framework.Eventually(t, func() (bool, string) {
+		statefulSet, err := upstreamKubeClusterClient.Cluster(workloadWorkspace).AppsV1().StatefulSets("default").Get(ctx, "test", metav1.GetOptions{})
+		require.NoError(t, err)
+		for _, annotation := range specDiffAnnotations {
+			if _, exists := statefulSet.Annotations[annotation]; !exists {
+				return false, fmt.Sprintf("StatefulSet should have the %s annotation of the coordinator", annotation)
+			}
+		}
+		if actual, expected := statefulSet.Status.ReadyReplicas, int32(4); actual != expected {
+			return false, fmt.Sprintf("StatefulSet had %d ready replicas, not %d", actual, expected)
+		}
+		return true, ""
+	}, wait.ForeverTestTimeout, time.Millisecond*500, "StatefulSet %s/%s was not synced", workloadWorkspace, "test")
+
+	for region, namespace := range downstreamNamespaces {
+		statefulSet, err := downstreamKubeClient.AppsV1().StatefulSets(namespace).Get(ctx, "test", metav1.GetOptions{})
+		require.NoError(t, err)
+		require.Equal(t, int32(2), statefulSet.Status.ReadyReplicas, "%s SyncTarget should have received half of the StatefulSet replicas", region)
+	}
+
+	t.Logf("Wait for the DaemonSet to be ready on the nodes of both SyncTargets")
+	framework.Eventually(t, func() (bool, string) {
+		daemonSet, err := upstreamKubeClusterClient.Cluster(workloadWorkspace).AppsV1().DaemonSets("default").Get(ctx, "test", metav1.GetOptions{})
+		require.NoError(t, err)
+
+		var desired int32
+		for region, namespace := range downstreamNamespaces {
+			downstream, err := downstreamKubeClient.AppsV1().DaemonSets(namespace).Get(ctx, "test", metav1.GetOptions{})
+			if err != nil {
+				return false, fmt.Sprintf("DaemonSet not synced to the %s SyncTarget: %v", region, err)
+			}
+			if downstream.Status.DesiredNumberScheduled == 0 {
+				return false, fmt.Sprintf("DaemonSet not scheduled on the %s SyncTarget yet", region)
+			}
+			desired += downstream.Status.DesiredNumberScheduled
+		}
+		if actual := daemonSet.Status.DesiredNumberScheduled; actual != desired {
+			return false, fmt.Sprintf("DaemonSet had %d desired pods, not the %d of both SyncTargets", actual, desired)
+		}
+		if actual := daemonSet.Status.NumberReady; actual != desired {
+			return false, fmt.Sprintf("DaemonSet had %d ready pods, not %d", actual, desired)
+		}
+		return true, ""
+	}, wait.ForeverTestTimeout, time.Millisecond*500, "DaemonSet %s/%s was not synced", workloadWorkspace, "test")
+
+	t.Logf("Wait for the Job to be split across the SyncTargets and complete with 4 completions")
+	framework.Eventually(t, func() (bool, string) {
+		job, err := upstreamKubeClusterClient.Cluster(workloadWorkspace).BatchV1().Jobs("default").Get(ctx, "test", metav1.GetOptions{})
+		require.NoError(t, err)
+		for _, annotation := range specDiffAnnotations {
+			if _, exists := job.Annotations[annotation]; !exists {
+				return false, fmt.Sprintf("Job should have the %s annotation of the coordinator", annotation)
+			}
+		}
+		if actual, expected := job.Status.Succeeded, int32(4); actual != expected {
+			return false, fmt.Sprintf("Job had %d succeeded pods, not %d", actual, expected)
+		}
+		for _, condition := range job.Status.Conditions {
+			if condition.Type == batchv1.JobComplete && condition.Status == corev1.ConditionTrue {
+				return true, ""
+			}
+		}
+		return false, "Job is not complete"
+	}, wait.ForeverTestTimeout, time.Millisecond*500, "Job %s/%s was not synced", workloadWorkspace, "test")
+
+	for region, namespace := range downstreamNamespaces {
+		job, err := downstreamKubeClient.BatchV1().Jobs(namespace).Get(ctx, "test", metav1.GetOptions{})
+		require.NoError(t, err)
+		require.NotNil(t, job.Spec.Completions)
+		require.Equal(t, int32(2), *job.Spec.Completions, "%s SyncTarget should have received half of the Job completions", region)
+		require.Equal(t, int32(2), job.Status.Succeeded, "%s SyncTarget should have run half of the Job completions", region)
+	}
+}
@@ -31,6 +31,7 @@ import (
 	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	discocache "k8s.io/client-go/discovery/cached"
 	"k8s.io/client-go/dynamic"
@@ -88,20 +89,17 @@ func TestRootComputeWorkspace(t *testing.T) {
 			return false
 		}
 
-		if len(syncTarget.Status.SyncedResources) != 3 {
+		if len(syncTarget.Status.SyncedResources) != 6 {
 			return false
 		}
 
-		if syncTarget.Status.SyncedResources[0].Resource != "services" ||
-			syncTarget.Status.SyncedResources[0].State != workloadv1alpha1.ResourceSchemaAcceptedState {
-			return false
-		}
-		if syncTarget.Status.SyncedResources[1].Resource != "ingresses" ||
-			syncTarget.Status.SyncedResources[1].State != workloadv1alpha1.ResourceSchemaAcceptedState {
-			return false
+		accepted := sets.NewString()
+		for _, syncedResource := range syncTarget.Status.SyncedResources {
+			if syncedResource.State == workloadv1alpha1.ResourceSchemaAcceptedState {
+				accepted.Insert(syncedResource.Resource)
+			}
 		}
-		if syncTarget.Status.SyncedResources[2].Resource != "deployments" ||
-			syncTarget.Status.SyncedResources[2].State != workloadv1alpha1.ResourceSchemaAcceptedState {
+		if !accepted.HasAll("services", "ingresses", "deployments", "statefulsets", "daemonsets", "jobs") {
 			return false
 		}
 
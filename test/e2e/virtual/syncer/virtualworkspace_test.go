@@ -55,7 +55,7 @@ import (
 	"github.com/kcp-dev/kcp/test/e2e/framework"
 )
 
-func deploymentsAPIResourceList(workspaceName logicalcluster.Name) *metav1.APIResourceList {
+func appsAPIResourceList(workspaceName logicalcluster.Name) *metav1.APIResourceList {
 	return &metav1.APIResourceList{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "APIResourceList",
@@ -63,6 +63,23 @@ func deploymentsAPIResourceList(workspaceName logicalcluster.Name) *metav1.APIRe
 		},
 		GroupVersion: "apps/v1",
 		APIResources: []metav1.APIResource{
+			{
+				Kind:               "DaemonSet",
+				Name:               "daemonsets",
+				SingularName:       "daemonset",
+				Namespaced:         true,
+				Verbs:              metav1.Verbs{"get", "list", "patch", "update", "watch"},
+				StorageVersionHash: discovery.StorageVersionHash(workspaceName, "apps", "v1", "DaemonSet"),
+				Categories:         []string{"all"},
+				ShortNames:         []string{"ds"},
+			},
+			{
+				Kind:               "DaemonSet",
+				Name:               "daemonsets/status",
+				Namespaced:         true,
+				Verbs:              metav1.Verbs{"get", "patch", "update"},
+				StorageVersionHash: "",
+			},
 			{
 				Kind:               "Deployment",
 				Name:               "deployments",
@@ -80,6 +97,51 @@ func deploymentsAPIResourceList(workspaceName logicalcluster.Name) *metav1.APIRe
 				Verbs:              metav1.Verbs{"get", "patch", "update"},
 				StorageVersionHash: "",
 			},
+			{
+				Kind:               "StatefulSet",
+				Name:               "statefulsets",
+				SingularName:       "statefulset",
+				Namespaced:         true,
+				Verbs:              metav1.Verbs{"get", "list", "patch", "update", "watch"},
+				StorageVersionHash: discovery.StorageVersionHash(workspaceName, "apps", "v1", "StatefulSet"),
+				Categories:         []string{"all"},
+				ShortNames:         []string{"sts"},
+			},
+			{
+				Kind:               "StatefulSet",
+				Name:               "statefulsets/status",
+				Namespaced:         true,
+				Verbs:              metav1.Verbs{"get", "patch", "update"},
+				StorageVersionHash: "",
+			},
+		},
+	}
+}
+
+func batchAPIResourceList(workspaceName logicalcluster.Name) *metav1.APIResourceList {
+	return &metav1.APIResourceList{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "APIResourceList",
+			APIVersion: "v1",
+		},
+		GroupVersion: "batch/v1",
+		APIResources: []metav1.APIResource{
+			{
+				Kind:               "Job",
+				Name:               "jobs",
+				SingularName:       "job",
+				Namespaced:         true,
+				Verbs:              metav1.Verbs{"get", "list", "patch", "update", "watch"},
+				StorageVersionHash: discovery.StorageVersionHash(workspaceName, "batch", "v1", "Job"),
+				Categories:         []string{"all"},
+			},
+			{
+				Kind:               "Job",
+				Name:               "jobs/status",
+				Namespaced:         true,
+				Verbs:              metav1.Verbs{"get", "patch", "update"},
+				StorageVersionHash: "",
+			},
 		},
 	}
 }
@@ -159,7 +221,8 @@ func withRootComputeAPIResourceList(workspaceName logicalcluster.Name) []*metav1
 	)
 
 	return []*metav1.APIResourceList{
-		deploymentsAPIResourceList(rootcompute.RootComputeWorkspace),
+		appsAPIResourceList(rootcompute.RootComputeWorkspace),
+		batchAPIResourceList(rootcompute.RootComputeWorkspace),
 		{
 			TypeMeta: metav1.TypeMeta{
 				Kind:       "APIResourceList",
@@ -18,20 +18,25 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	kubernetesinformers "github.com/kcp-dev/client-go/informers"
 	kubernetesclient "github.com/kcp-dev/client-go/kubernetes"
 	"github.com/spf13/cobra"
 
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	genericapiserver "k8s.io/apiserver/pkg/server"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	api "k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/component-base/version"
 
+	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/cluster"
+	kcpinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions"
+	workloadinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/workload/v1alpha1"
 	kcpfeatures "github.com/kcp-dev/kcp/pkg/features"
-	"github.com/kcp-dev/kcp/pkg/reconciler/coordination/deployment"
+	"github.com/kcp-dev/kcp/pkg/reconciler/coordination/coordinator"
 	options "github.com/kcp-dev/kcp/tmc/cmd/deployment-coordinator/options"
 )
 
@@ -43,7 +48,7 @@ func NewDeploymentCoordinatorCommand() *cobra.Command {
 	options := options.NewOptions()
 	command := &cobra.Command{
 		Use:   "deployment-coordinator",
-		Short: "Coordination controller for deployments. Spreads replicas across locations",
+		Short: "Coordination controllers for workloads. Spreads replicas across locations and summarizes their status",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := options.Logs.ValidateAndApply(kcpfeatures.DefaultFeatureGate); err != nil {
 				return err
@@ -79,16 +84,7 @@ func NewDeploymentCoordinatorCommand() *cobra.Command {
 }
 
 func Run(ctx context.Context, options *options.Options) error {
-	defaultLoadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
-	defaultLoadingRules.ExplicitPath = options.Kubeconfig
-	r, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-		defaultLoadingRules,
-		&clientcmd.ConfigOverrides{
-			CurrentContext: options.Context,
-			ClusterInfo: api.Cluster{
-				Server: options.Server,
-			},
-		}).ClientConfig()
+	r, err := clientConfig(options, options.Server)
 	if err != nil {
 		return err
 	}
@@ -102,14 +98,57 @@ func Run(ctx context.Context, options *options.Options) error {
 
 	kubeInformerFactory := kubernetesinformers.NewSharedInformerFactoryWithOptions(kcpCluterClient, resyncPeriod)
 
-	controller, err := deployment.NewController(ctx, kcpCluterClient, kubeInformerFactory.Apps().V1().Deployments())
-	if err != nil {
-		return err
+	var syncTargetInformer workloadinformers.SyncTargetClusterInformer
+	var kcpInformerFactory kcpinformers.SharedInformerFactory
+	if coordinator.SplitStrategy(options.SplitStrategy) == coordinator.WeightedSplitStrategy {
+		syncTargetConfig, err := clientConfig(options, options.SyncTargetServer)
+		if err != nil {
+			return err
+		}
+		kcpClusterClient, err := kcpclientset.NewForConfig(rest.AddUserAgent(rest.CopyConfig(syncTargetConfig), "kcp#deployment-coordinator/"+kcpVersion))
+		if err != nil {
+			return err
+		}
+		kcpInformerFactory = kcpinformers.NewSharedInformerFactoryWithOptions(kcpClusterClient, resyncPeriod)
+		syncTargetInformer = kcpInformerFactory.Workload().V1alpha1().SyncTargets()
+	}
+
+	var controllers []coordinator.Runner
+	for _, resource := range options.Resources {
+		registration, found := coordinator.Lookup(schema.ParseGroupResource(resource))
+		if !found {
+			return fmt.Errorf("no coordination controller for %s", resource)
+		}
+		controller, err := registration.NewController(ctx, kcpCluterClient, kubeInformerFactory, syncTargetInformer, coordinator.SplitStrategy(options.SplitStrategy), options.WeightLabel)
+		if err != nil {
+			return err
+		}
+		controllers = append(controllers, controller)
 	}
 	kubeInformerFactory.Start(ctx.Done())
 	kubeInformerFactory.WaitForCacheSync(ctx.Done())
+	if kcpInformerFactory != nil {
+		kcpInformerFactory.Start(ctx.Done())
+		kcpInformerFactory.WaitForCacheSync(ctx.Done())
+	}
 
-	controller.Start(ctx, numThreads)
+	for _, controller := range controllers {
+		go controller.Start(ctx, numThreads)
+	}
 
 	return nil
 }
+
+// clientConfig returns the client config of the Kubeconfig file, using the given APIServer URL if not empty.
+func clientConfig(options *options.Options, server string) (*rest.Config, error) {
+	defaultLoadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	defaultLoadingRules.ExplicitPath = options.Kubeconfig
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		defaultLoadingRules,
+		&clientcmd.ConfigOverrides{
+			CurrentContext: options.Context,
+			ClusterInfo: api.Cluster{
+				Server: server,
+			},
+		}).ClientConfig()
+}
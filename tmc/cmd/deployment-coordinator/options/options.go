@@ -17,10 +17,16 @@ limitations under the License.
 package options
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/spf13/pflag"
 
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/component-base/config"
 	"k8s.io/component-base/logs"
+
+	"github.com/kcp-dev/kcp/pkg/reconciler/coordination/coordinator"
 )
 
 type Options struct {
@@ -28,6 +34,11 @@ type Options struct {
 	Context    string
 	Server     string
 	Logs       *logs.Options
+
+	Resources        []string
+	SplitStrategy    string
+	WeightLabel      string
+	SyncTargetServer string
 }
 
 func NewOptions() *Options {
@@ -37,6 +48,10 @@ func NewOptions() *Options {
 
 	return &Options{
 		Logs: logs,
+
+		Resources:     []string{"deployments.apps"},
+		SplitStrategy: string(coordinator.EvenSplitStrategy),
+		WeightLabel:   coordinator.DefaultWeightLabel,
 	}
 }
 
@@ -44,6 +59,10 @@ func (options *Options) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&options.Kubeconfig, "kubeconfig", options.Kubeconfig, "Kubeconfig file.")
 	fs.StringVar(&options.Context, "context", options.Context, "Context to use in the Kubeconfig file, instead of the current context.")
 	fs.StringVar(&options.Server, "server", options.Server, "APIServer URL to use in the Kubeconfig file, instead of the one in the current context.")
+	fs.StringSliceVar(&options.Resources, "resources", options.Resources, fmt.Sprintf("Resources to coordinate across the SyncTargets they are synced to. Any of: %s.", strings.Join(coordinator.RegisteredResources(), ", ")))
+	fs.StringVar(&options.SplitStrategy, "split-strategy", options.SplitStrategy, fmt.Sprintf("Strategy used to divide the replicas of a resource across the SyncTargets it is synced to. One of: %s, %s.", coordinator.EvenSplitStrategy, coordinator.WeightedSplitStrategy))
+	fs.StringVar(&options.WeightLabel, "weight-label", options.WeightLabel, "SyncTarget label holding the integer weight of the SyncTarget for the weighted split strategy. SyncTargets without it have a weight of 1.")
+	fs.StringVar(&options.SyncTargetServer, "sync-target-server", options.SyncTargetServer, "APIServer URL serving the SyncTargets of all workspaces, used with the Kubeconfig file to read the SyncTarget weights. Required by the weighted split strategy.")
 	options.Logs.AddFlags(fs)
 }
 
@@ -52,5 +71,26 @@ func (options *Options) Complete() error {
 }
 
 func (options *Options) Validate() error {
+	if len(options.Resources) == 0 {
+		return fmt.Errorf("--resources is required")
+	}
+	for _, resource := range options.Resources {
+		if _, found := coordinator.Lookup(schema.ParseGroupResource(resource)); !found {
+			return fmt.Errorf("--resources must be any of %s (%s)", strings.Join(coordinator.RegisteredResources(), ", "), resource)
+		}
+	}
+
+	switch coordinator.SplitStrategy(options.SplitStrategy) {
+	case coordinator.EvenSplitStrategy:
+	case coordinator.WeightedSplitStrategy:
+		if options.WeightLabel == "" {
+			return fmt.Errorf("--weight-label is required by the %s split strategy", options.SplitStrategy)
+		}
+		if options.SyncTargetServer == "" {
+			return fmt.Errorf("--sync-target-server is required by the %s split strategy", options.SplitStrategy)
+		}
+	default:
+		return fmt.Errorf("--split-strategy must be one of %s, %s (%s)", coordinator.EvenSplitStrategy, coordinator.WeightedSplitStrategy, options.SplitStrategy)
+	}
 	return nil
 }